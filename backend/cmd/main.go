@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 
 	"maicivy/internal/api"
@@ -78,6 +81,10 @@ func main() {
 	// 5. Compression
 	app.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed,
+		// Ne pas compresser les flux SSE (la compression bufferise les événements)
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/stream")
+		},
 	}))
 
 	// 6. Tracking visiteurs
@@ -98,17 +105,55 @@ func main() {
 
 	// AI Config et services
 	aiConfig := config.LoadAIConfig()
-	aiService, err := services.NewAIService(aiConfig, &services.DefaultMetricsRecorder{})
+	// Registre des dépenses IA (table ai_usage) : métriques persistées et budgets par provider
+	aiUsageLedger := services.NewAIUsageLedger(db, aiConfig)
+	aiService, err := services.NewAIService(aiConfig, aiUsageLedger)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to initialize AI service - letters generation will be unavailable")
+	} else {
+		aiService.SetSpendTracker(aiUsageLedger)
 	}
 
 	// Scraper services
 	scraperConfig := config.LoadScraperConfig()
 	scraper := services.NewCompanyScraper(scraperConfig, redisClient)
+	// Entreprises canoniques (résolution des noms) et fiches curées, prioritaires sur les données scrapées
+	companyService := services.NewCompanyService(db)
+	if rebuilt, err := companyService.RebuildAliasKeys(context.Background()); err != nil {
+		log.Warn().Err(err).Msg("Failed to rebuild company alias keys")
+	} else {
+		log.Info().Int("aliases", rebuilt).Msg("Company alias keys rebuilt")
+	}
+	scraper.SetCompanyDirectory(companyService)
+	// Domaines choisis par chaque visiteur (le domaine canonique reste confirmé par l'administrateur)
+	companyDomainChoices := services.NewCompanyDomainChoiceService(redisClient, companyService)
 
 	// Letter queue service
+	letterWorkerConfig := config.LoadLetterWorkerConfig()
 	letterQueueService := services.NewLetterQueueService(redisClient)
+	letterQueueService.SetDB(db)
+	letterQueueService.SetVisibilityTimeout(letterWorkerConfig.VisibilityTimeout)
+
+	// Webhooks signés (job.queued, job.completed, job.failed, letter.downloaded)
+	webhookConfig := config.LoadWebhookConfig()
+	webhookService := services.NewWebhookService(db)
+	webhookService.SetMaxAttempts(webhookConfig.MaxAttempts)
+	webhookService.SetRetryBackoff(webhookConfig.RetryBackoff)
+	webhookService.SetTimeout(webhookConfig.Timeout)
+	letterQueueService.SetWebhookPublisher(webhookService)
+
+	// Jobs non terminés perdus par Redis (redémarrage sans persistance) : remise en file
+	if restored, err := letterQueueService.RestoreJobs(); err != nil {
+		log.Warn().Err(err).Msg("Failed to restore letter jobs from database")
+	} else if restored > 0 {
+		log.Info().Int("jobs", restored).Msg("Restored letter jobs from database")
+	}
+
+	// Letter stream service (diffusion token par token via SSE/WebSocket)
+	letterStreamService := services.NewLetterStreamService(redisClient)
+
+	// Letter revision service (révisions et historique de versions)
+	letterRevisionService := services.NewLetterRevisionService(db, aiService)
 
 	// Profile builder service
 	profileBuilder := services.NewProfileBuilder(db)
@@ -116,17 +161,27 @@ func main() {
 	// Build user profile from database
 	userProfile := profileBuilder.BuildProfile(context.Background())
 
+	// Renderer PDF partagé (navigateur headless longue durée, onglets en pool)
+	// et rendu Go natif utilisé sans navigateur (ou via ?renderer=native)
+	pdfConfig := config.LoadPDFConfig()
+	pdfRenderer := services.NewPDFRenderer(pdfConfig)
+	nativePDFRenderer := services.NewNativePDFRenderer(pdfConfig)
+
 	// PDF letter service
 	pdfLetterService, err := services.NewPDFLetterService("templates/letters")
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to initialize PDF letter service - PDF generation will be unavailable")
 		pdfLetterService = nil
+	} else {
+		pdfLetterService.SetRenderer(pdfRenderer)
+		pdfLetterService.SetNativeRenderer(nativePDFRenderer)
 	}
 
 	// Letter generator service (combines AI, scraper, PDF)
 	var letterGenerator *services.LetterGenerator
 	if aiService != nil && scraper != nil {
 		letterGenerator = services.NewLetterGenerator(aiService, scraper, pdfLetterService, userProfile)
+		letterGenerator.SetMaxRegenerations(aiConfig.LetterMaxRegenerations)
 		log.Info().Msg("Letter generator service initialized")
 	} else {
 		log.Warn().Msg("Letter generator service not initialized - AI or scraper missing")
@@ -144,24 +199,73 @@ func main() {
 	uaParser := services.NewUserAgentParser()
 	profileDetector := services.NewProfileDetectorService(db, redisClient, clearbitClient, uaParser)
 
-	// PDF service for CV export (separate from letter PDF service)
-	_ = services.NewPDFService()
+	// PDF service for CV export (same renderer as letter PDF service)
+	pdfService := services.NewPDFService()
+	pdfService.SetRenderer(pdfRenderer)
+	pdfService.SetNativeRenderer(nativePDFRenderer)
+
+	// Letter generation worker pool (démarré avec les background jobs)
+	var letterWorker *workers.LetterWorker
+	if letterGenerator != nil {
+		letterWorker = workers.NewLetterWorker(db, letterQueueService, aiService, scraper, letterGenerator, profileBuilder, letterStreamService, letterRevisionService)
+		letterWorker.SetConcurrency(letterWorkerConfig.Concurrency)
+		letterWorker.SetBatchConcurrency(letterWorkerConfig.BatchConcurrency)
+		letterWorker.SetShutdownTimeout(letterWorkerConfig.ShutdownTimeout)
+	}
 
 	// 8. Initialiser handlers
 	healthHandler := api.NewHealthHandler(db, redisClient)
+	if aiService != nil {
+		healthHandler.SetAIStatus(aiService)
+	}
+	if letterWorker != nil {
+		healthHandler.SetLetterWorkers(letterWorker)
+	}
 	cvHandler := api.NewCVHandler(cvService)
+	cvHandler.SetPDFService(pdfService)
+	cvHandler.SetPDFCache(services.NewCVPDFCache(redisClient, pdfService))
 	analyticsHandler := api.NewAnalyticsHandler(analyticsService)
-	lettersHandler := api.NewLettersHandler(db, redisClient, letterQueueService)
+	lettersHandler := api.NewLettersHandler(db, redisClient, letterQueueService, letterStreamService)
+	if pdfLetterService != nil {
+		lettersHandler.SetPDFService(pdfLetterService)
+	}
+	// Déduplication des requêtes de génération (Idempotency-Key, double clic, retry client)
+	lettersHandler.SetIdempotencyService(services.NewLetterIdempotencyService(redisClient, letterQueueService))
+	lettersHandler.SetWebhookPublisher(webhookService)
+	lettersHandler.SetCompanyResolver(companyService)
+	lettersHandler.SetCompanyDomains(companyDomainChoices)
+	letterVersionsHandler := api.NewLetterVersionsHandler(db, letterQueueService, letterRevisionService)
 	githubHandler := api.NewGitHubHandler(githubOAuthService, githubSyncService)
 	timelineHandler := api.NewTimelineHandler(db)
 	profileHandler := api.NewProfileHandler(db, redisClient, profileDetector)
 	swaggerHandler := api.NewSwaggerHandler()
 	visitorHandler := api.NewVisitorHandler(db, redisClient)
+	adminAIHandler := api.NewAdminAIHandler(aiUsageLedger)
+	adminLetterJobsHandler := api.NewAdminLetterJobsHandler(letterQueueService)
+	adminWebhooksHandler := api.NewAdminWebhooksHandler(webhookService)
+	adminCompaniesHandler := api.NewAdminCompaniesHandler(companyService, scraper)
+	companiesHandler := api.NewCompaniesHandler(companyService)
+	companiesHandler.SetDomainChooser(companyDomainChoices)
+	// Offres ouvertes relevées sur les sites carrières (à proposer en job_posting_url)
+	companiesHandler.SetOpeningsProvider(services.NewCompanyOpeningsService(db, companyService, scraper))
+
+	// Campagnes de lettres (propriétaire) : quota journalier propre, hors rate limit visiteurs
+	letterBatchConfig := config.LoadLetterBatchConfig()
+	letterBatchService := services.NewLetterBatchService(db, redisClient, letterQueueService)
+	letterBatchService.SetDailyQuota(letterBatchConfig.OwnerDailyQuota)
+	letterBatchService.SetMaxItems(letterBatchConfig.MaxItems)
+	letterBatchesHandler := api.NewLetterBatchesHandler(letterBatchService)
+	if pdfLetterService != nil {
+		letterBatchesHandler.SetPDFService(pdfLetterService)
+	}
 
 	// 9. Routes
 	app.Get("/health", healthHandler.Health)
 	app.Get("/health/deep", healthHandler.HealthDeep)
 
+	// Métriques Prometheus (dont l'état des circuit breakers IA)
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
 	// Groupes API (prêts pour Phase 2+)
 	apiV1 := app.Group("/api/v1")
 	apiV1.Get("/", func(c *fiber.Ctx) error {
@@ -174,6 +278,12 @@ func main() {
 	// Routes CV (Phase 2 - IMPLEMENTED)
 	cvHandler.RegisterRoutes(app)
 
+	// Routes Admin (clé ADMIN_API_KEY)
+	adminAuthMW := middleware.AdminAuth(cfg.AdminAPIKey)
+
+	// Routes campagnes de lettres (clé ADMIN_API_KEY du propriétaire, avant le catch-all /letters/:id)
+	letterBatchesHandler.RegisterRoutes(apiV1, adminAuthMW)
+
 	// Routes Letters avec rate limiting AI (Phase 3 - IMPLEMENTED)
 	lettersGroup := apiV1.Group("/letters")
 	// Rate limit AI uniquement sur /generate (utilise le bon middleware avec incrémentation après succès)
@@ -182,13 +292,23 @@ func main() {
 		MaxPerDay:        5,
 		CooldownDuration: 2 * time.Minute,
 	})
-	lettersGroup.Post("/generate", aiRateLimitMW, lettersHandler.GenerateLetter)
+	// Les requêtes rejouées sont servies avant le rate limit : quota et cooldown inchangés
+	lettersGroup.Post("/generate", lettersHandler.DeduplicateGeneration, aiRateLimitMW, lettersHandler.GenerateLetter)
 	lettersGroup.Get("/job/:jobId", lettersHandler.GetJobStatus)
+	lettersGroup.Get("/job/:jobId/stream", lettersHandler.StreamJob)
+	lettersGroup.Delete("/job/:jobId", lettersHandler.CancelJob)
+	lettersGroup.Get("/types", lettersHandler.ListLetterTypes)
 	lettersGroup.Get("/pair", lettersHandler.GetLetterPair) // ?company=Google
 	lettersGroup.Get("/history", lettersHandler.GetHistory)
 	lettersGroup.Get("/access/status", lettersHandler.GetAccessStatus)
 	lettersGroup.Get("/ratelimit/status", lettersHandler.GetRateLimitStatus)
-	lettersGroup.Get("/:id/pdf", lettersHandler.DownloadPDF)
+	lettersGroup.Get("/:id/pdf", lettersHandler.DownloadPDF) // ?renderer=auto|browser|native
+	lettersGroup.Get("/:id/export", lettersHandler.ExportLetter) // ?format=docx|odt|md|txt
+	// Révisions : plafonnées par lettre (MaxLetterVersions) plutôt que par le rate limit de génération
+	lettersGroup.Post("/:id/revise", letterVersionsHandler.ReviseLetter)
+	lettersGroup.Get("/:id/versions", letterVersionsHandler.ListVersions)
+	lettersGroup.Get("/:id/versions/diff", letterVersionsHandler.DiffVersions) // ?from=1&to=2
+	lettersGroup.Post("/:id/versions/:version/select", letterVersionsHandler.SelectVersion)
 	lettersGroup.Get("/:id", lettersHandler.GetLetter) // Must be last (catch-all)
 
 	// Routes Analytics (Phase 4 - IMPLEMENTED)
@@ -198,6 +318,10 @@ func main() {
 	wsHandler := websocket.NewAnalyticsWSHandler(analyticsService, redisClient)
 	wsHandler.RegisterRoutes(app)
 
+	// WebSocket for letter generation streaming
+	letterWSHandler := websocket.NewLetterStreamWSHandler(letterQueueService, letterStreamService)
+	letterWSHandler.RegisterRoutes(app)
+
 	// Routes GitHub (Phase 5 - IMPLEMENTED)
 	githubHandler.RegisterRoutes(apiV1)
 
@@ -210,10 +334,19 @@ func main() {
 	apiV1.Get("/profile/detect", profileHandler.GetDetect)
 	apiV1.Get("/profile/current", profileHandler.GetCurrentProfile)
 
+	// Routes résolution des entreprises (nom canonique, confirmation du domaine)
+	companiesHandler.RegisterRoutes(apiV1)
+
 	// Routes Visitor (Tracking & Access Gate)
 	apiV1.Get("/visitors/check", visitorHandler.CheckVisitorStatus)
 	apiV1.Get("/visitor/status", visitorHandler.GetVisitorStatus)
 
+	// Routes Admin (clé ADMIN_API_KEY)
+	adminAIHandler.RegisterRoutes(apiV1, adminAuthMW)
+	adminLetterJobsHandler.RegisterRoutes(apiV1, adminAuthMW)
+	adminWebhooksHandler.RegisterRoutes(apiV1, adminAuthMW)
+	adminCompaniesHandler.RegisterRoutes(apiV1, adminAuthMW)
+
 	// Routes Swagger (Documentation API)
 	swaggerHandler.RegisterRoutes(app)
 
@@ -237,11 +370,20 @@ func main() {
 		log.Info().Msg("GitHub auto-sync job started")
 	}
 
-	// Job 3: Letter generation worker (processes letter queue)
-	if letterGenerator != nil {
-		letterWorker := workers.NewLetterWorker(db, letterQueueService, aiService, scraper, letterGenerator, profileBuilder)
-		go letterWorker.Start()
-		log.Info().Msg("Letter generation worker started")
+	// Job 3: Letter queue reaper (re-queues jobs of workers that stopped heartbeating)
+	letterQueueReaperJob := jobs.NewLetterQueueReaperJob(letterQueueService)
+	go letterQueueReaperJob.Start(ctx)
+	log.Info().Msg("Letter queue reaper started")
+
+	// Job 4: Webhook dispatcher (deliveries and retries with backoff)
+	webhookDispatcherJob := jobs.NewWebhookDispatcherJob(webhookService, webhookConfig.PollInterval)
+	go webhookDispatcherJob.Start(ctx)
+	log.Info().Msg("Webhook dispatcher started")
+
+	// Job 5: Letter generation worker pool (processes letter queue)
+	if letterWorker != nil {
+		letterWorker.Start(ctx)
+		log.Info().Int("concurrency", letterWorkerConfig.Concurrency).Msg("Letter generation workers started")
 	} else {
 		log.Warn().Msg("Letter generation worker not started - dependencies missing")
 	}
@@ -268,14 +410,24 @@ func main() {
 
 	// Arrêter les background jobs
 	log.Info().Msg("Stopping background jobs...")
-	cancel() // Arrêter analytics cleanup job
+	cancel() // Arrêter analytics cleanup, letter queue reaper et webhook dispatcher
 	githubAutoSyncJob.Stop() // Arrêter GitHub auto-sync job
 
+	// Terminer les générations en cours ; celles qui dépassent le délai sont remises en file
+	if letterWorker != nil {
+		if err := letterWorker.Stop(); err != nil {
+			log.Warn().Err(err).Msg("Letter workers stopped before all jobs finished")
+		}
+	}
+
 	// Arrêter le serveur HTTP
 	if err := app.ShutdownWithTimeout(30 * time.Second); err != nil {
 		log.Error().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Fermer le navigateur headless une fois les requêtes terminées
+	pdfRenderer.Close()
+
 	log.Info().Msg("Server stopped gracefully")
 }
 
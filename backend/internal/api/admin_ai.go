@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/services"
+)
+
+// maxUsageReportDays borne la période d'un rapport de dépenses
+const maxUsageReportDays = 366
+
+// AIUsageReporter source du rapport de dépenses IA (services.AIUsageLedger)
+type AIUsageReporter interface {
+	Report(ctx context.Context, from, to time.Time) (*services.AIUsageReport, error)
+}
+
+// AdminAIHandler endpoints d'administration de la consommation IA
+type AdminAIHandler struct {
+	reporter AIUsageReporter
+}
+
+// NewAdminAIHandler crée une nouvelle instance du handler
+func NewAdminAIHandler(reporter AIUsageReporter) *AdminAIHandler {
+	return &AdminAIHandler{
+		reporter: reporter,
+	}
+}
+
+// RegisterRoutes enregistre les routes d'administration IA derrière le middleware admin
+func (h *AdminAIHandler) RegisterRoutes(router fiber.Router, adminAuth fiber.Handler) {
+	admin := router.Group("/admin/ai", adminAuth)
+	admin.Get("/usage", h.GetUsage)
+}
+
+// GetUsage rapport de dépenses par jour, provider, modèle et type de lettre, avec l'état des budgets
+// GET /api/v1/admin/ai/usage?from=2026-10-01&to=2026-10-16 (dates UTC incluses, 30 derniers jours par défaut)
+func (h *AdminAIHandler) GetUsage(c *fiber.Ctx) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -29)
+	to := today
+
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid date",
+				"code":    "INVALID_DATE",
+				"details": param.name + " must be formatted as YYYY-MM-DD",
+			})
+		}
+		*param.target = date
+	}
+
+	// La date de fin est incluse
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) || to.Sub(from) > maxUsageReportDays*24*time.Hour {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid period",
+			"code":    "INVALID_PERIOD",
+			"details": "from must not be after to, and the period is limited to 366 days",
+		})
+	}
+
+	report, err := h.reporter.Report(c.Context(), from, to)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build AI usage report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to build AI usage report",
+			"code":    "DB_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(report)
+}
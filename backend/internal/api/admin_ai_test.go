@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/middleware"
+	"maicivy/internal/services"
+)
+
+// stubUsageReporter mémorise la période demandée
+type stubUsageReporter struct {
+	from, to time.Time
+}
+
+func (s *stubUsageReporter) Report(ctx context.Context, from, to time.Time) (*services.AIUsageReport, error) {
+	s.from, s.to = from, to
+	return &services.AIUsageReport{
+		From:       from,
+		To:         to,
+		Total:      services.AIUsageBucket{Key: "total", Requests: 3, Cost: 0.42},
+		ByProvider: []services.AIUsageBucket{{Key: "claude", Requests: 3, Cost: 0.42}},
+	}, nil
+}
+
+func newAdminAITestApp(apiKey string) (*fiber.App, *stubUsageReporter) {
+	app := fiber.New()
+	reporter := &stubUsageReporter{}
+	NewAdminAIHandler(reporter).RegisterRoutes(app.Group("/api/v1"), middleware.AdminAuth(apiKey))
+	return app, reporter
+}
+
+// Test GET /api/v1/admin/ai/usage - Authentification admin
+func TestAdminAIUsage_Auth(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apiKey         string
+		header         string
+		value          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"Admin disabled", "", middleware.AdminHeader, "anything", 403, "ADMIN_DISABLED"},
+		{"Missing key", "secret", "", "", 401, "ADMIN_UNAUTHORIZED"},
+		{"Wrong key", "secret", middleware.AdminHeader, "guess", 401, "ADMIN_UNAUTHORIZED"},
+		{"Header key", "secret", middleware.AdminHeader, "secret", 200, ""},
+		{"Bearer key", "secret", "Authorization", "Bearer secret", 200, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, _ := newAdminAITestApp(tc.apiKey)
+			req := httptest.NewRequest("GET", "/api/v1/admin/ai/usage", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedCode != "" {
+				var body map[string]interface{}
+				json.NewDecoder(resp.Body).Decode(&body)
+				assert.Equal(t, tc.expectedCode, body["code"])
+			}
+		})
+	}
+}
+
+// Test GET /api/v1/admin/ai/usage - Période du rapport
+func TestAdminAIUsage_Period(t *testing.T) {
+	app, reporter := newAdminAITestApp("secret")
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/ai/usage?from=2026-10-01&to=2026-10-16", nil)
+	req.Header.Set(middleware.AdminHeader, "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// La date de fin est incluse
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), reporter.from)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), reporter.to)
+
+	var report services.AIUsageReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, int64(3), report.Total.Requests)
+	assert.Equal(t, "claude", report.ByProvider[0].Key)
+
+	// Par défaut : les 30 derniers jours, aujourd'hui inclus
+	req = httptest.NewRequest("GET", "/api/v1/admin/ai/usage", nil)
+	req.Header.Set(middleware.AdminHeader, "secret")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 30*24*time.Hour, reporter.to.Sub(reporter.from))
+	assert.True(t, reporter.to.After(time.Now()))
+}
+
+// Test GET /api/v1/admin/ai/usage - Paramètres invalides
+func TestAdminAIUsage_InvalidParams(t *testing.T) {
+	app, _ := newAdminAITestApp("secret")
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode string
+	}{
+		{"Invalid from", "?from=01/10/2026", "INVALID_DATE"},
+		{"Invalid to", "?to=yesterday", "INVALID_DATE"},
+		{"Reversed period", "?from=2026-10-16&to=2026-10-01", "INVALID_PERIOD"},
+		{"Period too long", "?from=2024-01-01&to=2026-10-01", "INVALID_PERIOD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/admin/ai/usage"+tc.query, nil)
+			req.Header.Set(middleware.AdminHeader, "secret")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode)
+
+			var body map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&body)
+			assert.Equal(t, tc.expectedCode, body["code"])
+		})
+	}
+}
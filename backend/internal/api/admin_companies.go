@@ -0,0 +1,327 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+// CompanyAdmin gestion des fiches entreprises curées (services.CompanyService)
+type CompanyAdmin interface {
+	CreateCompany(ctx context.Context, input services.CompanyInput) (*models.Company, error)
+	ListCompanies(ctx context.Context) ([]models.Company, error)
+	GetCompany(ctx context.Context, id uuid.UUID) (*models.Company, error)
+	FindByName(ctx context.Context, name string) (*models.Company, error)
+	UpdateCompany(ctx context.Context, id uuid.UUID, update services.CompanyUpdate) (*models.Company, error)
+	DeleteCompany(ctx context.Context, id uuid.UUID) error
+	PromoteCompanyInfo(ctx context.Context, info *models.CompanyInfo) (*models.Company, error)
+	ConfirmCompanyDomain(ctx context.Context, name, domain string) (*models.Company, error)
+}
+
+// CompanyInfoProvider données entreprise scrapées (services.CompanyScraper)
+type CompanyInfoProvider interface {
+	GetCompanyInfo(ctx context.Context, companyName string) (*models.CompanyInfo, error)
+	InvalidateCompanyInfo(ctx context.Context, companyID uuid.UUID) error
+}
+
+// AdminCompaniesHandler endpoints d'administration de la base de connaissances entreprises
+type AdminCompaniesHandler struct {
+	companies CompanyAdmin
+	scraper   CompanyInfoProvider
+}
+
+// NewAdminCompaniesHandler crée une nouvelle instance du handler
+func NewAdminCompaniesHandler(companies CompanyAdmin, scraper CompanyInfoProvider) *AdminCompaniesHandler {
+	return &AdminCompaniesHandler{
+		companies: companies,
+		scraper:   scraper,
+	}
+}
+
+// RegisterRoutes enregistre les routes des fiches entreprises derrière le middleware admin
+func (h *AdminCompaniesHandler) RegisterRoutes(router fiber.Router, adminAuth fiber.Handler) {
+	admin := router.Group("/admin/companies", adminAuth)
+	admin.Post("", h.CreateCompany)
+	admin.Get("", h.ListCompanies)
+	admin.Post("/promote", h.PromoteCompany)
+	admin.Post("/confirm-domain", h.ConfirmDomain)
+	admin.Get("/:companyId", h.GetCompany)
+	admin.Patch("/:companyId", h.UpdateCompany)
+	admin.Delete("/:companyId", h.DeleteCompany)
+}
+
+// CreateCompany crée une fiche entreprise curée
+// POST /api/v1/admin/companies
+func (h *AdminCompaniesHandler) CreateCompany(c *fiber.Ctx) error {
+	var req dto.CreateCompanyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return companyValidationError(c, err)
+	}
+
+	company, err := h.companies.CreateCompany(c.UserContext(), services.CompanyInput{
+		Name:         req.Name,
+		Domain:       req.Domain,
+		Description:  req.Description,
+		Industry:     req.Industry,
+		Size:         req.Size,
+		Technologies: req.Technologies,
+		Values:       req.Values,
+		Culture:      req.Culture,
+		Notes:        req.Notes,
+		Aliases:      req.Aliases,
+	})
+	if err != nil {
+		return companyWriteError(c, "Failed to create company", err)
+	}
+
+	log.Info().Str("company_id", company.ID.String()).Str("name", company.Name).Msg("Curated company created by admin")
+	return c.Status(fiber.StatusCreated).JSON(company)
+}
+
+// ListCompanies liste les fiches entreprises
+// GET /api/v1/admin/companies
+func (h *AdminCompaniesHandler) ListCompanies(c *fiber.Ctx) error {
+	companies, err := h.companies.ListCompanies(c.UserContext())
+	if err != nil {
+		return companyError(c, "Failed to list companies", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"companies": companies,
+		"total":     len(companies),
+	})
+}
+
+// GetCompany retourne une fiche entreprise
+// GET /api/v1/admin/companies/:companyId
+func (h *AdminCompaniesHandler) GetCompany(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("companyId"))
+	if err != nil {
+		return invalidCompanyID(c)
+	}
+
+	company, err := h.companies.GetCompany(c.UserContext(), id)
+	if errors.Is(err, services.ErrCompanyNotFound) {
+		return companyNotFound(c)
+	}
+	if err != nil {
+		return companyError(c, "Failed to get company", err)
+	}
+	return c.JSON(company)
+}
+
+// UpdateCompany modifie une fiche entreprise
+// PATCH /api/v1/admin/companies/:companyId
+func (h *AdminCompaniesHandler) UpdateCompany(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("companyId"))
+	if err != nil {
+		return invalidCompanyID(c)
+	}
+
+	var req dto.UpdateCompanyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return companyValidationError(c, err)
+	}
+
+	company, err := h.companies.UpdateCompany(c.UserContext(), id, services.CompanyUpdate{
+		Name:         req.Name,
+		Domain:       req.Domain,
+		Description:  req.Description,
+		Industry:     req.Industry,
+		Size:         req.Size,
+		Technologies: req.Technologies,
+		Values:       req.Values,
+		Culture:      req.Culture,
+		Notes:        req.Notes,
+		Aliases:      req.Aliases,
+	})
+	if err != nil {
+		return companyWriteError(c, "Failed to update company", err)
+	}
+
+	h.invalidateScrapedInfo(c, company.ID)
+	log.Info().Str("company_id", company.ID.String()).Str("name", company.Name).Msg("Curated company updated by admin")
+	return c.JSON(company)
+}
+
+// DeleteCompany supprime une fiche entreprise
+// DELETE /api/v1/admin/companies/:companyId
+func (h *AdminCompaniesHandler) DeleteCompany(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("companyId"))
+	if err != nil {
+		return invalidCompanyID(c)
+	}
+
+	err = h.companies.DeleteCompany(c.UserContext(), id)
+	if errors.Is(err, services.ErrCompanyNotFound) {
+		return companyNotFound(c)
+	}
+	if err != nil {
+		return companyError(c, "Failed to delete company", err)
+	}
+
+	h.invalidateScrapedInfo(c, id)
+	log.Info().Str("company_id", id.String()).Msg("Company deleted by admin")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PromoteCompany crée une fiche curée à partir des données scrapées d'une entreprise, pour édition
+// Une entreprise connue mais non curée (résolution des noms) est complétée.
+// POST /api/v1/admin/companies/promote
+func (h *AdminCompaniesHandler) PromoteCompany(c *fiber.Ctx) error {
+	var req dto.PromoteCompanyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return companyValidationError(c, err)
+	}
+
+	existing, err := h.companies.FindByName(c.UserContext(), req.Name)
+	if err == nil && existing.Curated {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Company already curated",
+			"code":    "COMPANY_EXISTS",
+			"details": existing.ID.String(),
+		})
+	}
+	if err != nil && !errors.Is(err, services.ErrCompanyNotFound) {
+		return companyError(c, "Failed to find company", err)
+	}
+
+	info, err := h.scraper.GetCompanyInfo(c.UserContext(), req.Name)
+	if err != nil {
+		log.Error().Err(err).Str("company", req.Name).Msg("Failed to get company info for promotion")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   "Failed to get company info",
+			"code":    "COMPANY_INFO_UNAVAILABLE",
+			"details": err.Error(),
+		})
+	}
+
+	company, err := h.companies.PromoteCompanyInfo(c.UserContext(), info)
+	if err != nil {
+		return companyWriteError(c, "Failed to promote company", err)
+	}
+
+	log.Info().Str("company_id", company.ID.String()).Str("name", company.Name).Msg("Scraped company info promoted by admin")
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"company":    company,
+		"provenance": info.Provenance,
+	})
+}
+
+// ConfirmDomain fixe le domaine canonique d'une entreprise, utilisé pour tous les visiteurs
+// POST /api/v1/admin/companies/confirm-domain
+func (h *AdminCompaniesHandler) ConfirmDomain(c *fiber.Ctx) error {
+	var req dto.ConfirmCompanyDomainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return companyValidationError(c, err)
+	}
+
+	company, err := h.companies.ConfirmCompanyDomain(c.UserContext(), req.Name, req.Domain)
+	if errors.Is(err, services.ErrCompanyDomainConfirmed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Company domain already confirmed",
+			"code":    "DOMAIN_ALREADY_CONFIRMED",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return companyWriteError(c, "Failed to confirm company domain", err)
+	}
+
+	h.invalidateScrapedInfo(c, company.ID)
+	log.Info().Str("company_id", company.ID.String()).Str("domain", company.Domain).Msg("Company domain confirmed by admin")
+	return c.JSON(company)
+}
+
+// invalidateScrapedInfo vide le cache scrapé de l'entreprise (le domaine curé a pu changer)
+func (h *AdminCompaniesHandler) invalidateScrapedInfo(c *fiber.Ctx, companyID uuid.UUID) {
+	if h.scraper == nil {
+		return
+	}
+	if err := h.scraper.InvalidateCompanyInfo(c.UserContext(), companyID); err != nil {
+		log.Warn().Err(err).Str("company_id", companyID.String()).Msg("Failed to invalidate cached company info")
+	}
+}
+
+func invalidCompanyID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid company ID",
+		"code":  "INVALID_ID",
+	})
+}
+
+func companyNotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": "Company not found",
+		"code":  "COMPANY_NOT_FOUND",
+	})
+}
+
+func companyValidationError(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":   "Validation failed",
+		"code":    "VALIDATION_ERROR",
+		"details": err.Error(),
+	})
+}
+
+// companyWriteError erreurs de création/modification (conflit de nom, fiche invalide ou inconnue)
+func companyWriteError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrCompanyNotFound):
+		return companyNotFound(c)
+	case errors.Is(err, services.ErrCompanyExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Company name or alias already in use",
+			"code":    "COMPANY_EXISTS",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidCompany):
+		return companyValidationError(c, err)
+	}
+	return companyError(c, message, err)
+}
+
+func companyError(c *fiber.Ctx, message string, err error) error {
+	log.Error().Err(err).Msg(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"code":    "DATABASE_ERROR",
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/middleware"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+type fakeCompanyInfoProvider struct {
+	info        *models.CompanyInfo
+	invalidated []uuid.UUID
+}
+
+func (f *fakeCompanyInfoProvider) GetCompanyInfo(_ context.Context, companyName string) (*models.CompanyInfo, error) {
+	info := *f.info
+	info.Name = companyName
+	return &info, nil
+}
+
+func (f *fakeCompanyInfoProvider) InvalidateCompanyInfo(_ context.Context, companyID uuid.UUID) error {
+	f.invalidated = append(f.invalidated, companyID)
+	return nil
+}
+
+func newAdminCompaniesTestApp(t *testing.T) (*fiber.App, *fakeCompanyInfoProvider, *services.CompanyService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Company{}, &models.CompanyAlias{}))
+
+	scraper := &fakeCompanyInfoProvider{info: &models.CompanyInfo{
+		Domain:      "acme.com",
+		Description: "Acme builds rockets.",
+		Provenance: map[string]models.FieldProvenance{
+			services.CompanyFieldDescription: {Source: services.CompanySourceWikipedia, Confidence: 0.8},
+		},
+	}}
+	companies := services.NewCompanyService(db)
+	app := fiber.New()
+	NewAdminCompaniesHandler(companies, scraper).RegisterRoutes(app.Group("/api/v1"), middleware.AdminAuth("secret"))
+	return app, scraper, companies
+}
+
+func TestAdminCompanies_CRUD(t *testing.T) {
+	app, scraper, _ := newAdminCompaniesTestApp(t)
+
+	status, body := adminRequest(t, app, "POST", "/api/v1/admin/companies", `{"name":"BNP Paribas","domain":"group.bnpparibas","technologies":["Java"],"aliases":["BNP"],"notes":"Banque"}`)
+	require.Equal(t, 201, status, body)
+	var created models.Company
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	assert.Equal(t, []string{"BNP"}, []string(created.Aliases))
+	assert.True(t, created.Curated)
+	assert.True(t, created.DomainConfirmed)
+
+	path := "/api/v1/admin/companies/" + created.ID.String()
+	status, body = adminRequest(t, app, "PATCH", path, `{"name":"BNP Paribas SA","aliases":[]}`)
+	require.Equal(t, 200, status, body)
+	var updated models.Company
+	require.NoError(t, json.Unmarshal([]byte(body), &updated))
+	assert.Equal(t, "BNP Paribas SA", updated.Name)
+	assert.Equal(t, "group.bnpparibas", updated.Domain)
+	assert.Empty(t, updated.Aliases)
+	assert.Equal(t, []uuid.UUID{created.ID}, scraper.invalidated)
+
+	testCases := []struct {
+		method, path, body string
+		expectedStatus     int
+	}{
+		{"POST", "/api/v1/admin/companies", `{"name":"X"}`, 400},
+		{"POST", "/api/v1/admin/companies", `{"name":"Acme","domain":"not a domain"}`, 400},
+		{"POST", "/api/v1/admin/companies", `{"name":"Other","aliases":["bnp paribas sa"]}`, 409},
+		{"PATCH", path, `{"domain":"bad domain"}`, 400},
+		{"PATCH", path, `{"domain":""}`, 200},
+		{"GET", "/api/v1/admin/companies", "", 200},
+		{"GET", path, "", 200},
+		{"GET", "/api/v1/admin/companies/not-a-uuid", "", 400},
+		{"GET", "/api/v1/admin/companies/00000000-0000-0000-0000-000000000000", "", 404},
+		{"PATCH", "/api/v1/admin/companies/00000000-0000-0000-0000-000000000000", `{}`, 404},
+		{"POST", "/api/v1/admin/companies/promote", `{"name":"bnp paribas sa"}`, 409},
+		{"DELETE", path, "", 204},
+		{"DELETE", path, "", 404},
+	}
+
+	for _, tc := range testCases {
+		status, body := adminRequest(t, app, tc.method, tc.path, tc.body)
+		assert.Equal(t, tc.expectedStatus, status, tc.method+" "+tc.path+" "+body)
+	}
+
+	// Sans clé admin
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api/v1/admin/companies", nil))
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestAdminCompanies_Promote(t *testing.T) {
+	app, _, companies := newAdminCompaniesTestApp(t)
+
+	// Entreprise déjà résolue depuis une lettre : la promotion la complète
+	known, err := companies.ResolveOrCreateCompany(context.Background(), "ACME Inc.")
+	require.NoError(t, err)
+
+	status, body := adminRequest(t, app, "POST", "/api/v1/admin/companies/promote", `{"name":"Acme"}`)
+	require.Equal(t, 201, status, body)
+
+	var result struct {
+		Company    models.Company                    `json:"company"`
+		Provenance map[string]models.FieldProvenance `json:"provenance"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+	assert.Equal(t, known.ID, result.Company.ID)
+	assert.Equal(t, "ACME Inc.", result.Company.Name)
+	assert.True(t, result.Company.Curated)
+	assert.False(t, result.Company.DomainConfirmed)
+	assert.Equal(t, "Acme builds rockets.", result.Company.Description)
+	assert.Contains(t, result.Company.Notes, "description : wikipedia")
+	assert.Equal(t, services.CompanySourceWikipedia, result.Provenance["description"].Source)
+
+	status, _ = adminRequest(t, app, "POST", "/api/v1/admin/companies/promote", `{"name":"acme"}`)
+	assert.Equal(t, 409, status)
+}
+
+func TestAdminCompanies_ConfirmDomain(t *testing.T) {
+	app, scraper, _ := newAdminCompaniesTestApp(t)
+
+	status, body := adminRequest(t, app, "POST", "/api/v1/admin/companies/confirm-domain", `{"name":"Orange","domain":"Orange.FR"}`)
+	require.Equal(t, 200, status, body)
+	var company models.Company
+	require.NoError(t, json.Unmarshal([]byte(body), &company))
+	assert.Equal(t, "orange.fr", company.Domain)
+	assert.True(t, company.DomainConfirmed)
+	assert.Equal(t, []uuid.UUID{company.ID}, scraper.invalidated)
+
+	testCases := []struct {
+		body           string
+		expectedStatus int
+	}{
+		{`{"name":"orange sa","domain":"orange.com"}`, 409},
+		{`{"name":"Globex","domain":"intranet.globex.internal"}`, 400},
+		{`{"name":"Globex"}`, 400},
+	}
+	for _, tc := range testCases {
+		status, body := adminRequest(t, app, "POST", "/api/v1/admin/companies/confirm-domain", tc.body)
+		assert.Equal(t, tc.expectedStatus, status, tc.body+" "+body)
+	}
+
+	// Réservé à l'administrateur
+	req := httptest.NewRequest("POST", "/api/v1/admin/companies/confirm-domain", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/services"
+)
+
+// Bornes du listing des jobs
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 500
+)
+
+// LetterJobAdmin opérations d'administration de la queue de lettres (services.LetterQueueService)
+type LetterJobAdmin interface {
+	Stats() (*services.LetterQueueStats, error)
+	ListJobs(state services.JobListState, limit int) ([]services.LetterJob, error)
+	RequeueJob(jobID string) (*services.LetterJob, error)
+	PurgeJob(jobID string) error
+	PurgeDeadJobs() (int, error)
+}
+
+// AdminLetterJobsHandler endpoints d'administration des jobs de génération de lettres
+type AdminLetterJobsHandler struct {
+	queue LetterJobAdmin
+}
+
+// NewAdminLetterJobsHandler crée une nouvelle instance du handler
+func NewAdminLetterJobsHandler(queue LetterJobAdmin) *AdminLetterJobsHandler {
+	return &AdminLetterJobsHandler{
+		queue: queue,
+	}
+}
+
+// RegisterRoutes enregistre les routes d'administration de la queue derrière le middleware admin
+func (h *AdminLetterJobsHandler) RegisterRoutes(router fiber.Router, adminAuth fiber.Handler) {
+	admin := router.Group("/admin/letters", adminAuth)
+	admin.Get("/jobs", h.ListJobs)
+	admin.Delete("/jobs", h.PurgeJobs)
+	admin.Post("/jobs/:jobId/requeue", h.RequeueJob)
+	admin.Delete("/jobs/:jobId", h.PurgeJob)
+}
+
+// ListJobs liste les jobs d'une catégorie avec l'état de la queue
+// GET /api/v1/admin/letters/jobs?state=queued|processing|failed|dead&limit=50
+func (h *AdminLetterJobsHandler) ListJobs(c *fiber.Ctx) error {
+	state, err := services.ParseJobListState(c.Query("state", string(services.JobListQueued)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid state",
+			"code":    "INVALID_STATE",
+			"details": err.Error(),
+		})
+	}
+
+	limit := defaultJobListLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxJobListLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid limit",
+				"code":    "INVALID_LIMIT",
+				"details": "limit must be between 1 and 500",
+			})
+		}
+	}
+
+	jobs, err := h.queue.ListJobs(state, limit)
+	if err != nil {
+		return queueError(c, "Failed to list jobs", err)
+	}
+	stats, err := h.queue.Stats()
+	if err != nil {
+		return queueError(c, "Failed to read queue stats", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"state": state,
+		"jobs":  jobs,
+		"stats": stats,
+	})
+}
+
+// RequeueJob remet en file un job échoué, dead-letter ou annulé
+// POST /api/v1/admin/letters/jobs/:jobId/requeue
+func (h *AdminLetterJobsHandler) RequeueJob(c *fiber.Ctx) error {
+	job, err := h.queue.RequeueJob(c.Params("jobId"))
+	switch {
+	case errors.Is(err, services.ErrJobActive), errors.Is(err, services.ErrJobFinished):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Job cannot be requeued",
+			"code":    "JOB_NOT_REQUEUEABLE",
+			"details": "job is " + string(job.Status),
+		})
+	case errors.Is(err, services.ErrJobNotFound):
+		return jobNotFound(c)
+	case err != nil:
+		return queueError(c, "Failed to requeue job", err)
+	}
+
+	log.Info().Str("job_id", job.JobID).Msg("Letter job requeued by admin")
+	return c.JSON(job)
+}
+
+// PurgeJob supprime un job (files, Redis et historique Postgres)
+// DELETE /api/v1/admin/letters/jobs/:jobId
+func (h *AdminLetterJobsHandler) PurgeJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+
+	err := h.queue.PurgeJob(jobID)
+	if errors.Is(err, services.ErrJobNotFound) {
+		return jobNotFound(c)
+	}
+	if err != nil {
+		return queueError(c, "Failed to purge job", err)
+	}
+
+	log.Info().Str("job_id", jobID).Msg("Letter job purged by admin")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PurgeJobs vide la dead-letter queue
+// DELETE /api/v1/admin/letters/jobs?state=dead
+func (h *AdminLetterJobsHandler) PurgeJobs(c *fiber.Ctx) error {
+	if c.Query("state") != string(services.JobListDead) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid state",
+			"code":    "INVALID_STATE",
+			"details": "only the dead-letter queue can be purged (state=dead)",
+		})
+	}
+
+	purged, err := h.queue.PurgeDeadJobs()
+	if err != nil {
+		return queueError(c, "Failed to purge dead-letter queue", err)
+	}
+
+	log.Info().Int("jobs", purged).Msg("Dead-letter queue purged by admin")
+	return c.JSON(fiber.Map{
+		"purged": purged,
+	})
+}
+
+func jobNotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": "Job not found",
+		"code":  "JOB_NOT_FOUND",
+	})
+}
+
+func queueError(c *fiber.Ctx, message string, err error) error {
+	log.Error().Err(err).Msg(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"code":    "QUEUE_ERROR",
+		"details": err.Error(),
+	})
+}
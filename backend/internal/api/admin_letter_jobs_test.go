@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/middleware"
+	"maicivy/internal/services"
+)
+
+// stubLetterJobAdmin queue en mémoire pour les endpoints d'administration
+type stubLetterJobAdmin struct {
+	jobs   map[string]*services.LetterJob
+	state  services.JobListState
+	limit  int
+	purged []string
+}
+
+func (s *stubLetterJobAdmin) Stats() (*services.LetterQueueStats, error) {
+	return &services.LetterQueueStats{Queued: map[services.JobPriority]int64{services.JobPriorityHigh: 1}, Dead: 2}, nil
+}
+
+func (s *stubLetterJobAdmin) ListJobs(state services.JobListState, limit int) ([]services.LetterJob, error) {
+	s.state, s.limit = state, limit
+	return []services.LetterJob{{JobID: "job-1", Status: services.JobStatusFailed}}, nil
+}
+
+func (s *stubLetterJobAdmin) RequeueJob(jobID string) (*services.LetterJob, error) {
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, services.ErrJobNotFound
+	}
+	if job.Status == services.JobStatusProcessing {
+		return job, services.ErrJobActive
+	}
+	job.Status = services.JobStatusQueued
+	return job, nil
+}
+
+func (s *stubLetterJobAdmin) PurgeJob(jobID string) error {
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return services.ErrJobNotFound
+	}
+	s.purged = append(s.purged, job.JobID)
+	return nil
+}
+
+func (s *stubLetterJobAdmin) PurgeDeadJobs() (int, error) {
+	return 2, nil
+}
+
+func newAdminLetterJobsTestApp() (*fiber.App, *stubLetterJobAdmin) {
+	app := fiber.New()
+	queue := &stubLetterJobAdmin{jobs: map[string]*services.LetterJob{
+		"job-dead":    {JobID: "job-dead", Status: services.JobStatusFailed},
+		"job-running": {JobID: "job-running", Status: services.JobStatusProcessing},
+	}}
+	NewAdminLetterJobsHandler(queue).RegisterRoutes(app.Group("/api/v1"), middleware.AdminAuth("secret"))
+	return app, queue
+}
+
+func TestAdminLetterJobs_List(t *testing.T) {
+	app, queue := newAdminLetterJobsTestApp()
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/letters/jobs?state=dead&limit=10", nil)
+	req.Header.Set(middleware.AdminHeader, "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, services.JobListDead, queue.state)
+	assert.Equal(t, 10, queue.limit)
+
+	var result struct {
+		Jobs  []services.LetterJob      `json:"jobs"`
+		Stats services.LetterQueueStats `json:"stats"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Jobs, 1)
+	assert.Equal(t, int64(2), result.Stats.Dead)
+
+	for _, query := range []string{"state=stuck", "limit=0", "limit=1000"} {
+		req := httptest.NewRequest("GET", "/api/v1/admin/letters/jobs?"+query, nil)
+		req.Header.Set(middleware.AdminHeader, "secret")
+		resp, _ := app.Test(req)
+		assert.Equal(t, 400, resp.StatusCode, query)
+	}
+
+	// Sans clé admin
+	resp, _ = app.Test(httptest.NewRequest("GET", "/api/v1/admin/letters/jobs", nil))
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestAdminLetterJobs_RequeueAndPurge(t *testing.T) {
+	app, queue := newAdminLetterJobsTestApp()
+
+	testCases := []struct {
+		method, path   string
+		expectedStatus int
+	}{
+		{"POST", "/api/v1/admin/letters/jobs/job-dead/requeue", 200},
+		{"POST", "/api/v1/admin/letters/jobs/job-running/requeue", 409},
+		{"POST", "/api/v1/admin/letters/jobs/job-unknown/requeue", 404},
+		{"DELETE", "/api/v1/admin/letters/jobs/job-dead", 204},
+		{"DELETE", "/api/v1/admin/letters/jobs/job-unknown", 404},
+		{"DELETE", "/api/v1/admin/letters/jobs?state=dead", 200},
+		{"DELETE", "/api/v1/admin/letters/jobs?state=failed", 400},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set(middleware.AdminHeader, "secret")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedStatus, resp.StatusCode, tc.method+" "+tc.path)
+	}
+
+	assert.Equal(t, services.JobStatusQueued, queue.jobs["job-dead"].Status)
+	assert.Equal(t, []string{"job-dead"}, queue.purged)
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+// Bornes du journal des livraisons
+const (
+	defaultDeliveryListLimit = 50
+	maxDeliveryListLimit     = 500
+)
+
+// WebhookAdmin gestion des abonnements webhook (services.WebhookService)
+type WebhookAdmin interface {
+	CreateSubscription(ctx context.Context, req services.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id uuid.UUID, update services.WebhookSubscriptionUpdate) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+}
+
+// AdminWebhooksHandler endpoints d'administration des webhooks
+type AdminWebhooksHandler struct {
+	webhooks WebhookAdmin
+}
+
+// NewAdminWebhooksHandler crée une nouvelle instance du handler
+func NewAdminWebhooksHandler(webhooks WebhookAdmin) *AdminWebhooksHandler {
+	return &AdminWebhooksHandler{
+		webhooks: webhooks,
+	}
+}
+
+// RegisterRoutes enregistre les routes des webhooks derrière le middleware admin
+func (h *AdminWebhooksHandler) RegisterRoutes(router fiber.Router, adminAuth fiber.Handler) {
+	admin := router.Group("/admin/webhooks", adminAuth)
+	admin.Post("", h.CreateWebhook)
+	admin.Get("", h.ListWebhooks)
+	admin.Post("/deliveries/:deliveryId/redeliver", h.Redeliver)
+	admin.Get("/:webhookId", h.GetWebhook)
+	admin.Patch("/:webhookId", h.UpdateWebhook)
+	admin.Delete("/:webhookId", h.DeleteWebhook)
+	admin.Get("/:webhookId/deliveries", h.ListDeliveries)
+}
+
+// CreateWebhook crée un abonnement ; le secret de signature n'est retourné qu'à la création
+// POST /api/v1/admin/webhooks
+func (h *AdminWebhooksHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req dto.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return webhookValidationError(c, err)
+	}
+
+	events, err := services.ParseWebhookEvents(req.Events)
+	if err != nil {
+		return webhookValidationError(c, err)
+	}
+
+	subscription, err := h.webhooks.CreateSubscription(c.UserContext(), services.WebhookSubscriptionRequest{
+		URL:         req.URL,
+		Events:      events,
+		Description: req.Description,
+		Secret:      req.Secret,
+	})
+	if errors.Is(err, services.ErrInvalidWebhook) {
+		return webhookValidationError(c, err)
+	}
+	if err != nil {
+		return webhookError(c, "Failed to create webhook", err)
+	}
+
+	log.Info().Str("webhook_id", subscription.ID.String()).Str("url", subscription.URL).Msg("Webhook subscription created by admin")
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"webhook": subscription,
+		"secret":  subscription.Secret,
+		"events":  services.WebhookEvents,
+	})
+}
+
+// ListWebhooks liste les abonnements
+// GET /api/v1/admin/webhooks
+func (h *AdminWebhooksHandler) ListWebhooks(c *fiber.Ctx) error {
+	subscriptions, err := h.webhooks.ListSubscriptions(c.UserContext())
+	if err != nil {
+		return webhookError(c, "Failed to list webhooks", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"webhooks": subscriptions,
+		"events":   services.WebhookEvents,
+	})
+}
+
+// GetWebhook retourne un abonnement
+// GET /api/v1/admin/webhooks/:webhookId
+func (h *AdminWebhooksHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	subscription, err := h.webhooks.GetSubscription(c.UserContext(), id)
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		return webhookNotFound(c)
+	}
+	if err != nil {
+		return webhookError(c, "Failed to get webhook", err)
+	}
+	return c.JSON(subscription)
+}
+
+// UpdateWebhook modifie l'URL, les événements, la description ou l'activation d'un abonnement
+// PATCH /api/v1/admin/webhooks/:webhookId
+func (h *AdminWebhooksHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return webhookValidationError(c, err)
+	}
+
+	update := services.WebhookSubscriptionUpdate{
+		URL:         req.URL,
+		Description: req.Description,
+		Active:      req.Active,
+	}
+	if req.Events != nil {
+		events, err := services.ParseWebhookEvents(*req.Events)
+		if err != nil {
+			return webhookValidationError(c, err)
+		}
+		update.Events = &events
+	}
+
+	subscription, err := h.webhooks.UpdateSubscription(c.UserContext(), id, update)
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return webhookNotFound(c)
+	case errors.Is(err, services.ErrInvalidWebhook):
+		return webhookValidationError(c, err)
+	case err != nil:
+		return webhookError(c, "Failed to update webhook", err)
+	}
+
+	log.Info().Str("webhook_id", subscription.ID.String()).Bool("active", subscription.Active).Msg("Webhook subscription updated by admin")
+	return c.JSON(subscription)
+}
+
+// DeleteWebhook supprime un abonnement et son journal de livraisons
+// DELETE /api/v1/admin/webhooks/:webhookId
+func (h *AdminWebhooksHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	err = h.webhooks.DeleteSubscription(c.UserContext(), id)
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		return webhookNotFound(c)
+	}
+	if err != nil {
+		return webhookError(c, "Failed to delete webhook", err)
+	}
+
+	log.Info().Str("webhook_id", id.String()).Msg("Webhook subscription deleted by admin")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListDeliveries journal des livraisons d'un abonnement (plus récentes d'abord)
+// GET /api/v1/admin/webhooks/:webhookId/deliveries?limit=50
+func (h *AdminWebhooksHandler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("webhookId"))
+	if err != nil {
+		return invalidWebhookID(c)
+	}
+
+	limit := defaultDeliveryListLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveryListLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid limit",
+				"code":    "INVALID_LIMIT",
+				"details": "limit must be between 1 and 500",
+			})
+		}
+	}
+
+	if _, err := h.webhooks.GetSubscription(c.UserContext(), id); err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			return webhookNotFound(c)
+		}
+		return webhookError(c, "Failed to get webhook", err)
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.UserContext(), id, limit)
+	if err != nil {
+		return webhookError(c, "Failed to list deliveries", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"deliveries": deliveries,
+	})
+}
+
+// Redeliver renvoie un événement à son abonné (nouvelle livraison, même corps)
+// POST /api/v1/admin/webhooks/deliveries/:deliveryId/redeliver
+func (h *AdminWebhooksHandler) Redeliver(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid delivery ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	delivery, err := h.webhooks.Redeliver(c.UserContext(), id)
+	switch {
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Delivery not found",
+			"code":  "DELIVERY_NOT_FOUND",
+		})
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return webhookNotFound(c)
+	case err != nil:
+		return webhookError(c, "Failed to redeliver webhook", err)
+	}
+
+	log.Info().Str("delivery_id", id.String()).Str("redelivery_id", delivery.ID.String()).Msg("Webhook redelivery scheduled by admin")
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+func invalidWebhookID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid webhook ID",
+		"code":  "INVALID_ID",
+	})
+}
+
+func webhookNotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": "Webhook not found",
+		"code":  "WEBHOOK_NOT_FOUND",
+	})
+}
+
+func webhookValidationError(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":   "Validation failed",
+		"code":    "VALIDATION_ERROR",
+		"details": err.Error(),
+	})
+}
+
+func webhookError(c *fiber.Ctx, message string, err error) error {
+	log.Error().Err(err).Msg(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"code":    "DATABASE_ERROR",
+		"details": err.Error(),
+	})
+}
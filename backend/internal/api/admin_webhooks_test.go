@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/middleware"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+func newAdminWebhooksTestApp(t *testing.T) (*fiber.App, *services.WebhookService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+
+	webhooks := services.NewWebhookService(db)
+	app := fiber.New()
+	NewAdminWebhooksHandler(webhooks).RegisterRoutes(app.Group("/api/v1"), middleware.AdminAuth("secret"))
+	return app, webhooks
+}
+
+func adminRequest(t *testing.T, app *fiber.App, method, path, body string) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.AdminHeader, "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	content, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(content)
+}
+
+func TestAdminWebhooks_Subscriptions(t *testing.T) {
+	app, _ := newAdminWebhooksTestApp(t)
+
+	status, body := adminRequest(t, app, "POST", "/api/v1/admin/webhooks", `{"url":"https://ats.example.com/hooks","events":["job.completed","job.failed"]}`)
+	require.Equal(t, 201, status, body)
+
+	var created struct {
+		Webhook models.WebhookSubscription `json:"webhook"`
+		Secret  string                     `json:"secret"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.Equal(t, "job.completed,job.failed", created.Webhook.Events)
+	assert.True(t, created.Webhook.Active)
+
+	// Le secret n'est retourné qu'à la création
+	status, body = adminRequest(t, app, "GET", "/api/v1/admin/webhooks", "")
+	assert.Equal(t, 200, status)
+	assert.NotContains(t, body, created.Secret)
+	assert.Contains(t, body, created.Webhook.ID.String())
+
+	path := "/api/v1/admin/webhooks/" + created.Webhook.ID.String()
+	status, body = adminRequest(t, app, "PATCH", path, `{"active":false,"events":[]}`)
+	require.Equal(t, 200, status, body)
+	var updated models.WebhookSubscription
+	require.NoError(t, json.Unmarshal([]byte(body), &updated))
+	assert.False(t, updated.Active)
+	assert.Empty(t, updated.Events)
+
+	testCases := []struct {
+		method, path, body string
+		expectedStatus     int
+	}{
+		{"POST", "/api/v1/admin/webhooks", `{"url":"not a url"}`, 400},
+		{"POST", "/api/v1/admin/webhooks", `{"url":"ftp://example.com/hooks"}`, 400},
+		{"POST", "/api/v1/admin/webhooks", `{"url":"https://example.com","events":["job.started"]}`, 400},
+		{"POST", "/api/v1/admin/webhooks", `{"url":"https://example.com","secret":"short"}`, 400},
+		{"GET", path + "/deliveries", "", 200},
+		{"GET", path + "/deliveries?limit=0", "", 400},
+		{"GET", "/api/v1/admin/webhooks/not-a-uuid", "", 400},
+		{"GET", "/api/v1/admin/webhooks/00000000-0000-0000-0000-000000000000", "", 404},
+		{"POST", "/api/v1/admin/webhooks/deliveries/00000000-0000-0000-0000-000000000000/redeliver", "", 404},
+		{"DELETE", path, "", 204},
+		{"DELETE", path, "", 404},
+	}
+
+	for _, tc := range testCases {
+		status, body := adminRequest(t, app, tc.method, tc.path, tc.body)
+		assert.Equal(t, tc.expectedStatus, status, tc.method+" "+tc.path+" "+body)
+	}
+
+	// Sans clé admin
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api/v1/admin/webhooks", nil))
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestAdminWebhooks_Redeliver(t *testing.T) {
+	app, webhooks := newAdminWebhooksTestApp(t)
+
+	subscription, err := webhooks.CreateSubscription(t.Context(), services.WebhookSubscriptionRequest{URL: "https://ats.example.com/hooks"})
+	require.NoError(t, err)
+	require.NoError(t, webhooks.Publish(t.Context(), services.WebhookEventJobCompleted, map[string]string{"job_id": "job-1"}))
+
+	status, body := adminRequest(t, app, "GET", "/api/v1/admin/webhooks/"+subscription.ID.String()+"/deliveries", "")
+	require.Equal(t, 200, status)
+	var result struct {
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+	require.Len(t, result.Deliveries, 1)
+	assert.Equal(t, "job.completed", result.Deliveries[0].Event)
+
+	status, body = adminRequest(t, app, "POST", "/api/v1/admin/webhooks/deliveries/"+result.Deliveries[0].ID.String()+"/redeliver", "")
+	require.Equal(t, 202, status, body)
+	var redelivery models.WebhookDelivery
+	require.NoError(t, json.Unmarshal([]byte(body), &redelivery))
+	assert.Equal(t, result.Deliveries[0].EventID, redelivery.EventID)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivery.Status)
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/services"
+)
+
+// CompanyResolver résolution des noms d'entreprise (services.CompanyService)
+type CompanyResolver interface {
+	ResolveCompany(ctx context.Context, name string) (*services.CompanyResolution, error)
+}
+
+// CompanyDomainChooser domaines choisis par chaque visiteur (services.CompanyDomainChoiceService)
+type CompanyDomainChooser interface {
+	OfferDomains(ctx context.Context, sessionID string, resolution *services.CompanyResolution) error
+	ChooseDomain(ctx context.Context, sessionID, name, domain string) (*services.CompanyDomainChoice, error)
+	RevokeDomain(ctx context.Context, sessionID, name string) error
+}
+
+// CompanyOpeningsProvider offres ouvertes des entreprises (services.CompanyOpeningsService)
+type CompanyOpeningsProvider interface {
+	GetCompanyOpenings(ctx context.Context, name string, refresh bool) (*services.CompanyOpenings, error)
+}
+
+// CompaniesHandler endpoints publics de résolution des noms d'entreprise
+type CompaniesHandler struct {
+	resolver CompanyResolver
+	domains  CompanyDomainChooser
+	openings CompanyOpeningsProvider
+}
+
+// NewCompaniesHandler crée une nouvelle instance du handler
+func NewCompaniesHandler(resolver CompanyResolver) *CompaniesHandler {
+	return &CompaniesHandler{
+		resolver: resolver,
+	}
+}
+
+// SetDomainChooser active le choix du domaine par le visiteur
+func (h *CompaniesHandler) SetDomainChooser(domains CompanyDomainChooser) {
+	h.domains = domains
+}
+
+// SetOpeningsProvider active l'endpoint des offres ouvertes
+func (h *CompaniesHandler) SetOpeningsProvider(openings CompanyOpeningsProvider) {
+	h.openings = openings
+}
+
+// RegisterRoutes enregistre les routes de résolution des entreprises
+func (h *CompaniesHandler) RegisterRoutes(router fiber.Router) {
+	companies := router.Group("/companies")
+	companies.Get("/resolve", h.ResolveCompany) // ?name=BNP
+	companies.Post("/resolve/confirm", h.ConfirmDomain)
+	companies.Delete("/resolve/confirm", h.RevokeDomain) // ?name=BNP
+	companies.Get("/:name/openings", h.GetOpenings)      // ?refresh=true
+}
+
+// ResolveCompany rattache un nom à une entreprise connue et propose des domaines à confirmer
+// Les domaines proposés sont retenus pour la session : seuls eux pourront être choisis.
+// GET /api/v1/companies/resolve?name=BNP
+func (h *CompaniesHandler) ResolveCompany(c *fiber.Ctx) error {
+	name := c.Query("name")
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company name required",
+			"code":  "MISSING_COMPANY",
+		})
+	}
+
+	resolution, err := h.resolver.ResolveCompany(c.UserContext(), name)
+	if errors.Is(err, services.ErrInvalidCompany) {
+		return companyValidationError(c, err)
+	}
+	if err != nil {
+		return companyError(c, "Failed to resolve company", err)
+	}
+
+	if sessionID := c.Cookies("maicivy_session"); sessionID != "" && h.domains != nil {
+		if err := h.domains.OfferDomains(c.UserContext(), sessionID, resolution); err != nil {
+			log.Warn().Err(err).Str("company", name).Msg("Failed to store offered company domains")
+		}
+	}
+	return c.JSON(resolution)
+}
+
+// ConfirmDomain enregistre le domaine choisi par le visiteur parmi les candidats proposés
+// Le choix ne vaut que pour les lettres de cette session ; le domaine canonique
+// de l'entreprise n'est confirmé que par l'administrateur.
+// POST /api/v1/companies/resolve/confirm
+func (h *CompaniesHandler) ConfirmDomain(c *fiber.Ctx) error {
+	if h.domains == nil {
+		return domainChoiceDisabled(c)
+	}
+	sessionID := c.Cookies("maicivy_session")
+	if sessionID == "" {
+		return sessionRequired(c)
+	}
+
+	var req dto.ConfirmCompanyDomainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return companyValidationError(c, err)
+	}
+
+	choice, err := h.domains.ChooseDomain(c.UserContext(), sessionID, req.Name, req.Domain)
+	switch {
+	case errors.Is(err, services.ErrDomainNotOffered):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Domain was not offered for this company",
+			"code":    "DOMAIN_NOT_OFFERED",
+			"details": err.Error(),
+		})
+	case err != nil:
+		return companyWriteError(c, "Failed to confirm company domain", err)
+	}
+
+	log.Info().Str("company_id", choice.Company.ID.String()).Str("domain", choice.Domain).Msg("Company domain chosen by visitor")
+	return c.JSON(choice)
+}
+
+// RevokeDomain oublie le domaine choisi par le visiteur pour une entreprise
+// DELETE /api/v1/companies/resolve/confirm?name=BNP
+func (h *CompaniesHandler) RevokeDomain(c *fiber.Ctx) error {
+	if h.domains == nil {
+		return domainChoiceDisabled(c)
+	}
+	sessionID := c.Cookies("maicivy_session")
+	if sessionID == "" {
+		return sessionRequired(c)
+	}
+
+	name := c.Query("name")
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company name required",
+			"code":  "MISSING_COMPANY",
+		})
+	}
+
+	err := h.domains.RevokeDomain(c.UserContext(), sessionID, name)
+	if errors.Is(err, services.ErrInvalidCompany) {
+		return companyValidationError(c, err)
+	}
+	if err != nil {
+		return companyError(c, "Failed to revoke company domain", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetOpenings offres ouvertes relevées sur le site carrières de l'entreprise
+// Seules les entreprises déjà connues sont parcourues (404 sinon).
+// Chaque URL d'offre peut être passée en job_posting_url lors de la génération d'une lettre.
+// GET /api/v1/companies/:name/openings
+func (h *CompaniesHandler) GetOpenings(c *fiber.Ctx) error {
+	if h.openings == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Company openings not available",
+			"code":  "OPENINGS_DISABLED",
+		})
+	}
+
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil || name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company name required",
+			"code":  "MISSING_COMPANY",
+		})
+	}
+
+	openings, err := h.openings.GetCompanyOpenings(c.UserContext(), name, c.QueryBool("refresh"))
+	switch {
+	case errors.Is(err, services.ErrInvalidCompany):
+		return companyValidationError(c, err)
+	case errors.Is(err, services.ErrCompanyNotFound):
+		return companyNotFound(c)
+	case errors.Is(err, services.ErrOpeningsUnavailable):
+		log.Warn().Err(err).Str("company", name).Msg("Company openings unavailable")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   "Company careers site unreachable",
+			"code":    "OPENINGS_UNAVAILABLE",
+			"details": err.Error(),
+		})
+	case err != nil:
+		return companyError(c, "Failed to get company openings", err)
+	}
+	return c.JSON(openings)
+}
+
+func sessionRequired(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "Session requise",
+		"code":  "SESSION_REQUIRED",
+	})
+}
+
+func domainChoiceDisabled(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": "Company domain choice not available",
+		"code":  "DOMAIN_CHOICE_DISABLED",
+	})
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+func newCompaniesTestApp(t *testing.T) (*fiber.App, *services.CompanyService, *services.CompanyDomainChoiceService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Company{}, &models.CompanyAlias{}))
+
+	mr := miniredis.RunT(t)
+	companies := services.NewCompanyService(db)
+	choices := services.NewCompanyDomainChoiceService(redis.NewClient(&redis.Options{Addr: mr.Addr()}), companies)
+	handler := NewCompaniesHandler(companies)
+	handler.SetDomainChooser(choices)
+	app := fiber.New()
+	handler.RegisterRoutes(app.Group("/api/v1"))
+	return app, companies, choices
+}
+
+func companiesRequest(t *testing.T, app *fiber.App, method, path, body string) (int, string) {
+	return companiesSessionRequest(t, app, "test-session", method, path, body)
+}
+
+func companiesSessionRequest(t *testing.T, app *fiber.App, sessionID, method, path, body string) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set("Cookie", "maicivy_session="+sessionID)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestCompanies_Resolve(t *testing.T) {
+	app, companies, _ := newCompaniesTestApp(t)
+	bnp, err := companies.CreateCompany(t.Context(), services.CompanyInput{Name: "BNP Paribas", Aliases: []string{"BNP"}})
+	require.NoError(t, err)
+
+	status, body := companiesRequest(t, app, "GET", "/api/v1/companies/resolve?name=bnp%20paribas%20sa", "")
+	require.Equal(t, 200, status, body)
+	var resolution services.CompanyResolution
+	require.NoError(t, json.Unmarshal([]byte(body), &resolution))
+	assert.Equal(t, services.CompanyMatchExact, resolution.Match)
+	assert.Equal(t, bnp.ID, resolution.Company.ID)
+
+	status, body = companiesRequest(t, app, "GET", "/api/v1/companies/resolve?name=Orange", "")
+	require.Equal(t, 200, status, body)
+	resolution = services.CompanyResolution{}
+	require.NoError(t, json.Unmarshal([]byte(body), &resolution))
+	assert.Equal(t, services.CompanyMatchNone, resolution.Match)
+	assert.Contains(t, resolution.DomainCandidates, "orange.fr")
+
+	status, _ = companiesRequest(t, app, "GET", "/api/v1/companies/resolve", "")
+	assert.Equal(t, 400, status)
+	status, _ = companiesRequest(t, app, "GET", "/api/v1/companies/resolve?name=%2E%2E", "")
+	assert.Equal(t, 400, status)
+}
+
+func TestCompanies_ConfirmDomain(t *testing.T) {
+	app, companies, choices := newCompaniesTestApp(t)
+	confirm := "/api/v1/companies/resolve/confirm"
+
+	// Domaine jamais proposé à cette session
+	status, body := companiesRequest(t, app, "POST", confirm, `{"name":"Orange","domain":"orange.fr"}`)
+	require.Equal(t, 400, status, body)
+	assert.Contains(t, body, "DOMAIN_NOT_OFFERED")
+
+	status, body = companiesRequest(t, app, "GET", "/api/v1/companies/resolve?name=Orange", "")
+	require.Equal(t, 200, status, body)
+
+	status, body = companiesRequest(t, app, "POST", confirm, `{"name":"Orange","domain":"orange.fr"}`)
+	require.Equal(t, 200, status, body)
+	var choice services.CompanyDomainChoice
+	require.NoError(t, json.Unmarshal([]byte(body), &choice))
+	assert.Equal(t, "orange.fr", choice.Domain)
+
+	// Le domaine canonique partagé n'est pas modifié par le visiteur
+	company, err := companies.GetCompany(t.Context(), choice.Company.ID)
+	require.NoError(t, err)
+	assert.False(t, company.DomainConfirmed)
+	domain, err := choices.SessionDomain(t.Context(), "test-session", "Orange")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.fr", domain)
+
+	testCases := []struct {
+		session        string
+		body           string
+		expectedStatus int
+	}{
+		{"test-session", `{"name":"Orange","domain":"evil.com"}`, 400},
+		{"test-session", `{"name":"Orange","domain":"metadata.internal"}`, 400},
+		{"test-session", `{"name":"Orange","domain":"not a domain"}`, 400},
+		{"test-session", `{"name":"Orange"}`, 400},
+		{"test-session", `not json`, 400},
+		{"other-session", `{"name":"Orange","domain":"orange.fr"}`, 400},
+		{"", `{"name":"Orange","domain":"orange.fr"}`, 401},
+	}
+	for _, tc := range testCases {
+		status, body := companiesSessionRequest(t, app, tc.session, "POST", confirm, tc.body)
+		assert.Equal(t, tc.expectedStatus, status, tc.body+" "+body)
+	}
+
+	// Révocation du choix
+	status, body = companiesRequest(t, app, "DELETE", confirm+"?name=Orange", "")
+	require.Equal(t, 204, status, body)
+	domain, err = choices.SessionDomain(t.Context(), "test-session", "Orange")
+	require.NoError(t, err)
+	assert.Empty(t, domain)
+
+	status, _ = companiesRequest(t, app, "DELETE", confirm, "")
+	assert.Equal(t, 400, status)
+	status, _ = companiesSessionRequest(t, app, "", "DELETE", confirm+"?name=Orange", "")
+	assert.Equal(t, 401, status)
+}
+
+// fakeOpeningsProvider offres renvoyées (ou erreur) et derniers paramètres reçus
+type fakeOpeningsProvider struct {
+	openings *services.CompanyOpenings
+	err      error
+	name     string
+	refresh  bool
+}
+
+func (f *fakeOpeningsProvider) GetCompanyOpenings(ctx context.Context, name string, refresh bool) (*services.CompanyOpenings, error) {
+	f.name, f.refresh = name, refresh
+	return f.openings, f.err
+}
+
+func TestCompanies_GetOpenings(t *testing.T) {
+	app, _, _ := newCompaniesTestApp(t)
+
+	// Endpoint non configuré
+	status, _ := companiesRequest(t, app, "GET", "/api/v1/companies/Acme/openings", "")
+	assert.Equal(t, 503, status)
+
+	openings := &fakeOpeningsProvider{openings: &services.CompanyOpenings{
+		Domain: "acme.com",
+		Openings: []models.CompanyOpening{
+			{Title: "Backend Engineer", URL: "https://acme.com/jobs/2", Source: models.OpeningSourceJSONLD},
+		},
+	}}
+	handler := NewCompaniesHandler(nil)
+	handler.SetOpeningsProvider(openings)
+	app = fiber.New()
+	handler.RegisterRoutes(app.Group("/api/v1"))
+
+	status, body := companiesRequest(t, app, "GET", "/api/v1/companies/BNP%20Paribas/openings?refresh=true", "")
+	require.Equal(t, 200, status, body)
+	assert.Equal(t, "BNP Paribas", openings.name)
+	assert.True(t, openings.refresh)
+	var result services.CompanyOpenings
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+	require.Len(t, result.Openings, 1)
+	assert.Equal(t, "https://acme.com/jobs/2", result.Openings[0].URL)
+
+	testCases := []struct {
+		err            error
+		expectedStatus int
+	}{
+		{services.ErrOpeningsUnavailable, 502},
+		{services.ErrInvalidCompany, 400},
+		{services.ErrCompanyNotFound, 404},
+		{errors.New("database down"), 500},
+	}
+	for _, tc := range testCases {
+		openings.err = tc.err
+		status, body := companiesRequest(t, app, "GET", "/api/v1/companies/Acme/openings", "")
+		assert.Equal(t, tc.expectedStatus, status, body)
+	}
+}
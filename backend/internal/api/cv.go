@@ -1,23 +1,40 @@
 package api
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 
+	"maicivy/internal/models"
 	"maicivy/internal/services"
 )
 
 // CVHandler gère les endpoints liés au CV
 type CVHandler struct {
-	cvService services.CVServiceInterface
+	cvService  services.CVServiceInterface
+	pdfService *services.PDFService
+	pdfCache   *services.CVPDFCache
 }
 
 // NewCVHandler crée un nouveau handler
 func NewCVHandler(cvService services.CVServiceInterface) *CVHandler {
 	return &CVHandler{
-		cvService: cvService,
+		cvService:  cvService,
+		pdfService: services.NewPDFService(),
 	}
 }
 
+// SetPDFService remplace le service d'export PDF (renderer partagé)
+func (h *CVHandler) SetPDFService(pdfService *services.PDFService) {
+	h.pdfService = pdfService
+}
+
+// SetPDFCache active le cache des PDF rendus (rendu à chaque téléchargement sinon)
+func (h *CVHandler) SetPDFCache(pdfCache *services.CVPDFCache) {
+	h.pdfCache = pdfCache
+}
+
 // RegisterRoutes enregistre les routes CV
 func (h *CVHandler) RegisterRoutes(app *fiber.App) {
 	api := app.Group("/api/v1")
@@ -35,12 +52,14 @@ func (h *CVHandler) RegisterRoutes(app *fiber.App) {
 // @Description Returns CV adapted to specified theme
 // @Tags CV
 // @Param theme query string false "Theme ID (backend, cpp, artistique, fullstack, devops)"
+// @Param lang query string false "Language (fr, en, de, es), overrides Accept-Language"
 // @Success 200 {object} services.AdaptiveCVResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/cv [get]
 func (h *CVHandler) GetAdaptiveCV(c *fiber.Ctx) error {
 	themeID := c.Query("theme", "fullstack") // Default: fullstack
+	lang := negotiateLanguage(c)
 
 	cv, err := h.cvService.GetAdaptiveCV(c.Context(), themeID)
 	if err != nil {
@@ -50,7 +69,7 @@ func (h *CVHandler) GetAdaptiveCV(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(cv)
+	return c.JSON(cv.Localize(lang))
 }
 
 // GetThemes retourne la liste des thèmes disponibles
@@ -75,6 +94,8 @@ func (h *CVHandler) GetThemes(c *fiber.Ctx) error {
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/experiences [get]
 func (h *CVHandler) GetExperiences(c *fiber.Ctx) error {
+	lang := negotiateLanguage(c)
+
 	experiences, err := h.cvService.GetAllExperiences(c.Context())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -82,6 +103,10 @@ func (h *CVHandler) GetExperiences(c *fiber.Ctx) error {
 		})
 	}
 
+	for i := range experiences {
+		experiences[i] = experiences[i].Localize(lang)
+	}
+
 	return c.JSON(fiber.Map{
 		"experiences": experiences,
 		"count":       len(experiences),
@@ -96,6 +121,8 @@ func (h *CVHandler) GetExperiences(c *fiber.Ctx) error {
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/skills [get]
 func (h *CVHandler) GetSkills(c *fiber.Ctx) error {
+	lang := negotiateLanguage(c)
+
 	skills, err := h.cvService.GetAllSkills(c.Context())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -103,6 +130,10 @@ func (h *CVHandler) GetSkills(c *fiber.Ctx) error {
 		})
 	}
 
+	for i := range skills {
+		skills[i] = skills[i].Localize(lang)
+	}
+
 	return c.JSON(fiber.Map{
 		"skills": skills,
 		"count":  len(skills),
@@ -117,6 +148,8 @@ func (h *CVHandler) GetSkills(c *fiber.Ctx) error {
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/projects [get]
 func (h *CVHandler) GetProjects(c *fiber.Ctx) error {
+	lang := negotiateLanguage(c)
+
 	projects, err := h.cvService.GetAllProjects(c.Context())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -124,6 +157,10 @@ func (h *CVHandler) GetProjects(c *fiber.Ctx) error {
 		})
 	}
 
+	for i := range projects {
+		projects[i] = projects[i].Localize(lang)
+	}
+
 	return c.JSON(fiber.Map{
 		"projects": projects,
 		"count":    len(projects),
@@ -135,14 +172,20 @@ func (h *CVHandler) GetProjects(c *fiber.Ctx) error {
 // @Description Generates and downloads CV as PDF for specified theme
 // @Tags CV
 // @Param theme query string false "Theme ID"
+// @Param lang query string false "Language (fr, en, de, es), overrides Accept-Language"
 // @Param format query string false "Export format (pdf)" default(pdf)
+// @Param renderer query string false "PDF renderer (auto, browser, native)" default(auto)
+// @Param If-None-Match header string false "ETag of a previously downloaded PDF"
 // @Success 200 {file} application/pdf
+// @Success 304 "PDF unchanged"
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/cv/export [get]
 func (h *CVHandler) ExportPDF(c *fiber.Ctx) error {
 	themeID := c.Query("theme", "fullstack")
 	format := c.Query("format", "pdf")
+	lang := negotiateLanguage(c)
 
 	if format != "pdf" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -150,6 +193,15 @@ func (h *CVHandler) ExportPDF(c *fiber.Ctx) error {
 		})
 	}
 
+	engine, err := services.ParsePDFEngine(c.Query("renderer"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid PDF renderer",
+			"code":    "INVALID_RENDERER",
+			"details": err.Error(),
+		})
+	}
+
 	// Récupérer CV adaptatif
 	cv, err := h.cvService.GetAdaptiveCV(c.Context(), themeID)
 	if err != nil {
@@ -158,9 +210,29 @@ func (h *CVHandler) ExportPDF(c *fiber.Ctx) error {
 		})
 	}
 
-	// Générer PDF
-	pdfService := services.NewPDFService()
-	pdfBytes, err := pdfService.GenerateCVPDF(cv)
+	// PDF inchangé depuis le dernier téléchargement : pas de rendu
+	localized := cv.Localize(lang)
+	etag := services.CVPDFETag(localized, engine)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "public, no-cache")
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	// Générer PDF (ou le reprendre du cache)
+	var pdfBytes []byte
+	if h.pdfCache != nil {
+		var artifact *services.CVPDFArtifact
+		if artifact, err = h.pdfCache.Get(c.UserContext(), localized, engine); err == nil {
+			pdfBytes = artifact.PDF
+			c.Set("X-Cache", cacheStatus(artifact.Cached))
+		}
+	} else {
+		pdfBytes, err = h.pdfService.GenerateCVPDFWithEngine(c.UserContext(), localized, engine)
+	}
+	if isPDFUnavailable(err) {
+		return respondPDFUnavailable(c, err)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate PDF",
@@ -169,10 +241,68 @@ func (h *CVHandler) ExportPDF(c *fiber.Ctx) error {
 
 	// Retourner PDF
 	c.Set("Content-Type", "application/pdf")
-	c.Set("Content-Disposition", "attachment; filename=cv_"+themeID+".pdf")
+	c.Set("Content-Disposition", "attachment; filename=cv_"+themeID+"_"+string(lang)+".pdf")
 	return c.Send(pdfBytes)
 }
 
+// etagMatches compare un en-tête If-None-Match à un ETag (comparaison faible, liste ou "*")
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheStatus valeur de l'en-tête X-Cache
+func cacheStatus(hit bool) string {
+	if hit {
+		return "HIT"
+	}
+	return "MISS"
+}
+
+// isPDFUnavailable indique une indisponibilité temporaire du rendu PDF (réponse 503)
+func isPDFUnavailable(err error) bool {
+	return errors.Is(err, services.ErrPDFRendererOverloaded) || errors.Is(err, services.ErrPDFBrowserUnavailable)
+}
+
+// respondPDFUnavailable répond 503 : renderer saturé (Retry-After) ou navigateur absent
+// (renderer=browser explicite ; le rendu natif reste disponible)
+func respondPDFUnavailable(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrPDFBrowserUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error":   "Headless browser unavailable, use renderer=native",
+			"code":    "PDF_BROWSER_UNAVAILABLE",
+			"details": err.Error(),
+		})
+	}
+
+	c.Set("Retry-After", "5")
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": "PDF renderer busy, retry later",
+		"code":  "PDF_RENDERER_BUSY",
+	})
+}
+
+// negotiateLanguage détermine la langue du contenu CV : paramètre ?lang= s'il est supporté,
+// sinon header Accept-Language (français par défaut). Positionne Content-Language et Vary.
+func negotiateLanguage(c *fiber.Ctx) models.Language {
+	lang, ok := models.ParseLanguage(c.Query("lang"))
+	if !ok {
+		lang = models.NegotiateLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	}
+
+	c.Set(fiber.HeaderContentLanguage, string(lang))
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return lang
+}
+
 // ErrorResponse structure pour documentation API
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -73,7 +73,7 @@ func TestGetCV_DefaultTheme(t *testing.T) {
 			Name:        "Full-Stack Developer",
 			Description: "Full-stack development",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{
 				Title:        "Full-Stack Dev",
 				Company:      "TechCorp",
@@ -81,14 +81,14 @@ func TestGetCV_DefaultTheme(t *testing.T) {
 				Technologies: pq.StringArray{"go", "react"},
 				StartDate:    now.AddDate(-2, 0, 0),
 			},
-		},
-		Skills: []models.Skill{
+		}),
+		Skills: scoredSkills([]models.Skill{
 			{Name: "Go", Level: models.SkillLevelExpert},
 			{Name: "React", Level: models.SkillLevelAdvanced},
-		},
-		Projects: []models.Project{
+		}),
+		Projects: scoredProjects([]models.Project{
 			{Title: "maicivy", Technologies: pq.StringArray{"go", "react"}},
-		},
+		}),
 		GeneratedAt: now,
 	}
 
@@ -134,7 +134,7 @@ func TestGetCV_BackendTheme(t *testing.T) {
 				"postgresql": 0.9,
 			},
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{
 				Title:        "Backend Dev",
 				Company:      "BackendCorp",
@@ -143,12 +143,12 @@ func TestGetCV_BackendTheme(t *testing.T) {
 				Category:     "backend",
 				StartDate:    now.AddDate(-3, 0, 0),
 			},
-		},
-		Skills: []models.Skill{
+		}),
+		Skills: scoredSkills([]models.Skill{
 			{Name: "Go", Level: models.SkillLevelExpert, Category: "backend"},
 			{Name: "PostgreSQL", Level: models.SkillLevelAdvanced, Category: "database"},
-		},
-		Projects:    []models.Project{},
+		}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: now,
 	}
 
@@ -374,11 +374,11 @@ func BenchmarkGetCV(b *testing.B) {
 			ID:   "backend",
 			Name: "Backend",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{Title: "Backend Dev", Company: "Test", StartDate: time.Now()},
-		},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -390,3 +390,30 @@ func BenchmarkGetCV(b *testing.B) {
 		app.Test(req)
 	}
 }
+
+// scoredExperiences enveloppe des expériences sans score (réponse du CV adaptatif)
+func scoredExperiences(experiences []models.Experience) []services.ScoredExperienceResponse {
+	scored := make([]services.ScoredExperienceResponse, 0, len(experiences))
+	for _, experience := range experiences {
+		scored = append(scored, services.ScoredExperienceResponse{Experience: experience})
+	}
+	return scored
+}
+
+// scoredSkills enveloppe des compétences sans score
+func scoredSkills(skills []models.Skill) []services.ScoredSkillResponse {
+	scored := make([]services.ScoredSkillResponse, 0, len(skills))
+	for _, skill := range skills {
+		scored = append(scored, services.ScoredSkillResponse{Skill: skill})
+	}
+	return scored
+}
+
+// scoredProjects enveloppe des projets sans score
+func scoredProjects(projects []models.Project) []services.ScoredProjectResponse {
+	scored := make([]services.ScoredProjectResponse, 0, len(projects))
+	for _, project := range projects {
+		scored = append(scored, services.ScoredProjectResponse{Project: project})
+	}
+	return scored
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// --- REQUESTS ---
+
+// CreateCompanyRequest requête de création d'une fiche entreprise curée
+type CreateCompanyRequest struct {
+	Name         string   `json:"name" validate:"required,min=2,max=255"`
+	Domain       string   `json:"domain,omitempty" validate:"omitempty,fqdn,max=255"`
+	Description  string   `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Industry     string   `json:"industry,omitempty" validate:"omitempty,max=255"`
+	Size         string   `json:"size,omitempty" validate:"omitempty,max=100"`
+	Technologies []string `json:"technologies,omitempty" validate:"omitempty,max=50,dive,required,max=100"`
+	Values       []string `json:"values,omitempty" validate:"omitempty,max=20,dive,required,max=255"`
+	Culture      string   `json:"culture,omitempty" validate:"omitempty,max=5000"`
+	Notes        string   `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Aliases      []string `json:"aliases,omitempty" validate:"omitempty,max=20,dive,required,max=255"`
+}
+
+// Validate valide la requête
+func (r *CreateCompanyRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// UpdateCompanyRequest requête de modification d'une fiche (champs absents inchangés)
+type UpdateCompanyRequest struct {
+	Name         *string   `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Domain       *string   `json:"domain,omitempty" validate:"omitempty,max=255"` // Vide = effacé
+	Description  *string   `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Industry     *string   `json:"industry,omitempty" validate:"omitempty,max=255"`
+	Size         *string   `json:"size,omitempty" validate:"omitempty,max=100"`
+	Technologies *[]string `json:"technologies,omitempty" validate:"omitempty,max=50,dive,required,max=100"`
+	Values       *[]string `json:"values,omitempty" validate:"omitempty,max=20,dive,required,max=255"`
+	Culture      *string   `json:"culture,omitempty" validate:"omitempty,max=5000"`
+	Notes        *string   `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Aliases      *[]string `json:"aliases,omitempty" validate:"omitempty,max=20,dive,required,max=255"`
+}
+
+// Validate valide la requête
+func (r *UpdateCompanyRequest) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+	if r.Domain != nil && *r.Domain != "" {
+		return validate.Var(*r.Domain, "fqdn")
+	}
+	return nil
+}
+
+// PromoteCompanyRequest requête de conversion des données scrapées d'une entreprise en fiche curée
+type PromoteCompanyRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+}
+
+// Validate valide la requête
+func (r *PromoteCompanyRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// ConfirmCompanyDomainRequest requête de confirmation du domaine d'une entreprise (visiteur ou administrateur)
+type ConfirmCompanyDomainRequest struct {
+	Name   string `json:"name" validate:"required,min=2,max=255"`
+	Domain string `json:"domain" validate:"required,fqdn,max=255"`
+}
+
+// Validate valide la requête
+func (r *ConfirmCompanyDomainRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// --- RESPONSES ---
+
+// LetterBatchResponse campagne de génération et son avancement
+type LetterBatchResponse struct {
+	BatchID  string `json:"batch_id"`
+	Name     string `json:"name,omitempty"`
+	Status   string `json:"status"`   // "queued", "processing", "completed"
+	Progress int    `json:"progress"` // 0-100
+	Total    int    `json:"total"`    // Entreprises de la campagne
+
+	// Jobs par statut ("queued", "processing", "completed", "failed", "cancelled")
+	Counts map[string]int `json:"counts"`
+
+	Items []LetterBatchItemResponse `json:"items,omitempty"`
+
+	// Archive ZIP des lettres générées (partielle tant que la campagne n'est pas terminée)
+	ArchiveURL string `json:"archive_url"`
+
+	// Quota journalier restant du propriétaire (à la création)
+	QuotaRemaining *int `json:"quota_remaining,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LetterBatchItemResponse entreprise d'une campagne et l'état de son job
+type LetterBatchItemResponse struct {
+	Position      int    `json:"position"`
+	CompanyName   string `json:"company_name"`
+	JobTitle      string `json:"job_title,omitempty"`
+	Theme         string `json:"theme,omitempty"`
+	JobPostingURL string `json:"job_posting_url,omitempty"`
+	JobID         string `json:"job_id"`
+	Status        string `json:"status"` // Statut du job ("failed" s'il a expiré)
+	Progress      int    `json:"progress"`
+
+	// Si completed : ID de la lettre créée pour chaque type demandé
+	Letters map[string]string `json:"letters,omitempty"`
+
+	// Si failed
+	Error *string `json:"error,omitempty"`
+}
+
+// LetterBatchSummary campagne listée (sans avancement)
+type LetterBatchSummary struct {
+	BatchID   string    `json:"batch_id"`
+	Name      string    `json:"name,omitempty"`
+	Total     int       `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -11,6 +11,15 @@ type GenerateLetterRequest struct {
 	CompanyName string `json:"company_name" validate:"required,min=2,max=200"`
 	JobTitle    string `json:"job_title,omitempty" validate:"omitempty,min=2,max=200"` // Optionnel
 	Theme       string `json:"theme,omitempty" validate:"omitempty,oneof=backend frontend fullstack devops data ai"`
+	Language    string `json:"language,omitempty" validate:"omitempty,oneof=fr en de es"` // Défaut: fr
+
+	// Offre d'emploi ciblée (optionnel) : URL à récupérer ou description collée
+	JobPostingURL  string `json:"job_posting_url,omitempty" validate:"omitempty,url,max=2000"`
+	JobPostingText string `json:"job_posting_text,omitempty" validate:"omitempty,min=50,max=20000"`
+
+	// Types de lettres à générer (optionnel, défaut: motivation + anti_motivation)
+	// Les valeurs sont contrôlées par le handler d'après le registre des types.
+	LetterTypes []string `json:"letter_types,omitempty" validate:"omitempty,max=6,dive,required"`
 }
 
 // Validate valide la requête
@@ -19,28 +28,62 @@ func (r *GenerateLetterRequest) Validate() error {
 	return validate.Struct(r)
 }
 
+// ReviseLetterRequest requête de révision d'une lettre à partir de retours libres
+type ReviseLetterRequest struct {
+	Feedback string `json:"feedback" validate:"required,min=3,max=1000"`
+}
+
+// Validate valide la requête
+func (r *ReviseLetterRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
 // --- RESPONSES ---
 
 // LetterGenerationResponse réponse après enqueue du job de génération
 type LetterGenerationResponse struct {
 	JobID   string `json:"job_id"`
-	Status  string `json:"status"` // "queued"
+	Status  string `json:"status"` // "queued" (statut courant si rejouée)
 	Message string `json:"message"`
 
+	// URL du flux SSE de génération (GET)
+	StreamURL string `json:"stream_url,omitempty"`
+
 	// Informations rate limiting
 	RateLimitRemaining int `json:"rate_limit_remaining"`
+
+	// Requête déjà reçue : job d'origine retourné, quota inchangé
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// LetterJobCancelResponse réponse à l'annulation d'un job
+type LetterJobCancelResponse struct {
+	JobID   string `json:"job_id"`
+	Status  string `json:"status"` // "cancelled"
+	Message string `json:"message"`
 }
 
 // LetterJobStatus status d'un job de génération
 type LetterJobStatus struct {
 	JobID   string `json:"job_id"`
-	Status  string `json:"status"`  // "queued", "processing", "completed", "failed"
+	Status  string `json:"status"`  // "queued", "processing", "completed", "failed", "cancelled"
 	Progress int   `json:"progress"` // 0-100
 
+	// Types demandés
+	LetterTypes []string `json:"letter_types,omitempty"`
+
 	// Si completed
 	LetterMotivationID     *string `json:"letter_motivation_id,omitempty"` // UUID as string
 	LetterAntiMotivationID *string `json:"letter_anti_motivation_id,omitempty"` // UUID as string
 
+	// Si completed : ID de la lettre créée pour chaque type demandé
+	Letters map[string]string `json:"letters,omitempty"`
+
+	// Si completed (révision)
+	LetterID      *string `json:"letter_id,omitempty"`
+	LetterVersion int     `json:"letter_version,omitempty"`
+
 	// Si failed
 	Error *string `json:"error,omitempty"`
 
@@ -52,7 +95,8 @@ type LetterJobStatus struct {
 type LetterDetailResponse struct {
 	ID          string `json:"id"` // UUID as string
 	CompanyName string `json:"company_name"`
-	LetterType  string `json:"letter_type"` // "motivation" ou "anti_motivation"
+	LetterType  string `json:"letter_type"` // "motivation", "anti_motivation", "follow_up_email"...
+	Language    string `json:"language"`
 	Content     string `json:"content"`
 	CreatedAt   string `json:"created_at"`
 
@@ -62,10 +106,59 @@ type LetterDetailResponse struct {
 	GenerationMS int     `json:"generation_ms"`
 	Cost         float64 `json:"cost"` // Coût estimé en USD
 
+	// Contrôle qualité
+	QualityScore    int                    `json:"quality_score"`
+	QualityFindings []LetterQualityFinding `json:"quality_findings"`
+	Regenerations   int                    `json:"regenerations"`
+
 	// URL de téléchargement PDF
 	PDFURL string `json:"pdf_url,omitempty"`
 }
 
+// LetterQualityFinding point relevé par le contrôle qualité d'une lettre
+type LetterQualityFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"` // "error" ou "warning"
+	Message  string `json:"message"`
+}
+
+// LetterRevisionResponse réponse après enqueue d'un job de révision
+type LetterRevisionResponse struct {
+	JobID     string `json:"job_id"`
+	Status    string `json:"status"` // "queued"
+	LetterID  string `json:"letter_id"`
+	StreamURL string `json:"stream_url"`
+}
+
+// LetterVersionResponse version d'une lettre
+type LetterVersionResponse struct {
+	Version      int    `json:"version"`
+	Content      string `json:"content"`
+	Feedback     string `json:"feedback,omitempty"` // Retours ayant produit la version
+	Current      bool   `json:"current"`
+	CreatedAt    string `json:"created_at"`
+	AIModel      string `json:"ai_model"`
+	TokensUsed   int    `json:"tokens_used"`
+	GenerationMS int    `json:"generation_ms"`
+}
+
+// LetterVersionsResponse historique des versions d'une lettre
+type LetterVersionsResponse struct {
+	LetterID       string                  `json:"letter_id"`
+	CurrentVersion int                     `json:"current_version"`
+	MaxVersions    int                     `json:"max_versions"`
+	Versions       []LetterVersionResponse `json:"versions"`
+}
+
+// LetterTypeResponse type de lettre disponible à la génération
+type LetterTypeResponse struct {
+	Type     string `json:"type"`
+	Format   string `json:"format"` // "letter", "email", "message" ou "pitch"
+	MinWords int    `json:"min_words"`
+	MaxWords int    `json:"max_words"`
+	Default  bool   `json:"default"` // Généré quand la requête ne précise aucun type
+}
+
 // LetterPairResponse paire de lettres (motivation + anti-motivation)
 type LetterPairResponse struct {
 	MotivationLetter     *LetterDetailResponse `json:"motivation_letter"`
@@ -84,7 +177,8 @@ type LetterHistoryResponse struct {
 
 // LetterHistoryItem item d'historique de lettre
 type LetterHistoryItem struct {
-	ID          string `json:"id"` // UUID as string
+	ID          string `json:"id"`                   // UUID as string
+	CompanyID   string `json:"company_id,omitempty"` // Entreprise canonique
 	CompanyName string `json:"company_name"`
 	LetterType  string `json:"letter_type"`
 	CreatedAt   string `json:"created_at"`
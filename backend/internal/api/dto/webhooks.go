@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// --- REQUESTS ---
+
+// CreateWebhookRequest requête de création d'un abonnement webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2000"`
+	Events      []string `json:"events,omitempty" validate:"omitempty,max=10,dive,required"` // Vide = tous
+	Description string   `json:"description,omitempty" validate:"omitempty,max=255"`
+	Secret      string   `json:"secret,omitempty" validate:"omitempty,min=16,max=100"` // Vide = généré
+}
+
+// Validate valide la requête
+func (r *CreateWebhookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// UpdateWebhookRequest requête de modification d'un abonnement (champs absents inchangés)
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty" validate:"omitempty,url,max=2000"`
+	Events      *[]string `json:"events,omitempty" validate:"omitempty,max=10,dive,required"`
+	Description *string   `json:"description,omitempty" validate:"omitempty,max=255"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// Validate valide la requête
+func (r *UpdateWebhookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
@@ -7,11 +7,26 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+
+	"maicivy/internal/services"
+	"maicivy/internal/workers"
 )
 
+// AICircuitSource état des circuit breakers des providers IA (services.AIService)
+type AICircuitSource interface {
+	CircuitStates() map[string]services.CircuitState
+}
+
+// LetterWorkerSource état du pool de workers de génération de lettres (workers.LetterWorker)
+type LetterWorkerSource interface {
+	Status() workers.Status
+}
+
 type HealthHandler struct {
-	db    *gorm.DB
-	redis *redis.Client
+	db      *gorm.DB
+	redis   *redis.Client
+	ai      AICircuitSource
+	workers LetterWorkerSource
 }
 
 func NewHealthHandler(db *gorm.DB, redisClient *redis.Client) *HealthHandler {
@@ -21,9 +36,21 @@ func NewHealthHandler(db *gorm.DB, redisClient *redis.Client) *HealthHandler {
 	}
 }
 
+// SetAIStatus ajoute l'état des providers IA au deep health check
+func (h *HealthHandler) SetAIStatus(source AICircuitSource) {
+	h.ai = source
+}
+
+// SetLetterWorkers ajoute l'état des workers de génération de lettres au deep health check
+func (h *HealthHandler) SetLetterWorkers(source LetterWorkerSource) {
+	h.workers = source
+}
+
 type HealthResponse struct {
-	Status   string            `json:"status"`
-	Services map[string]string `json:"services"`
+	Status        string            `json:"status"`
+	Services      map[string]string `json:"services"`
+	AI            map[string]string `json:"ai_providers,omitempty"`   // Provider → état du circuit breaker
+	LetterWorkers *workers.Status   `json:"letter_workers,omitempty"` // Pool de workers et heartbeats
 }
 
 // Health - Shallow health check (rapide)
@@ -71,13 +98,52 @@ func (h *HealthHandler) HealthDeep(c *fiber.Ctx) error {
 
 	services["api"] = "up"
 
+	aiProviders, aiDown := h.aiStatus()
+	if aiDown {
+		status = "degraded"
+	}
+
+	// Workers arrêtés, en cours d'arrêt ou sans heartbeat récent
+	var letterWorkers *workers.Status
+	if h.workers != nil {
+		workerStatus := h.workers.Status()
+		letterWorkers = &workerStatus
+		if workerStatus.Healthy {
+			services["letter_workers"] = "up"
+		} else {
+			services["letter_workers"] = "down"
+			status = "degraded"
+		}
+	}
+
 	httpStatus := fiber.StatusOK
 	if status == "degraded" {
 		httpStatus = fiber.StatusServiceUnavailable
 	}
 
 	return c.Status(httpStatus).JSON(HealthResponse{
-		Status:   status,
-		Services: services,
+		Status:        status,
+		Services:      services,
+		AI:            aiProviders,
+		LetterWorkers: letterWorkers,
 	})
 }
+
+// aiStatus état des providers IA
+// Dégradé seulement si tous les circuits sont ouverts (plus aucun fallback possible).
+func (h *HealthHandler) aiStatus() (map[string]string, bool) {
+	if h.ai == nil {
+		return nil, false
+	}
+
+	states := h.ai.CircuitStates()
+	providers := make(map[string]string, len(states))
+	open := 0
+	for provider, state := range states {
+		providers[provider] = string(state)
+		if state == services.CircuitOpen {
+			open++
+		}
+	}
+	return providers, len(states) > 0 && open == len(states)
+}
@@ -7,12 +7,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"maicivy/internal/services"
+	"maicivy/internal/workers"
 )
 
 // HealthHandlerTestSuite regroupe les tests du HealthHandler
@@ -263,3 +267,59 @@ func TestHealthDeep_RedisDown(t *testing.T) {
 	assert.Equal(t, "degraded", result.Status)
 	assert.Equal(t, "down", result.Services["redis"])
 }
+
+// stubCircuitSource états fixes des circuit breakers IA
+type stubCircuitSource map[string]services.CircuitState
+
+func (s stubCircuitSource) CircuitStates() map[string]services.CircuitState { return s }
+
+// Test état des providers IA dans le deep health check
+func TestHealthAIStatus(t *testing.T) {
+	handler := &HealthHandler{}
+	providers, down := handler.aiStatus()
+	assert.Nil(t, providers)
+	assert.False(t, down)
+
+	// Un provider ouvert avec fallback disponible : pas de dégradation
+	handler.SetAIStatus(stubCircuitSource{"claude": services.CircuitOpen, "openai": services.CircuitClosed})
+	providers, down = handler.aiStatus()
+	assert.Equal(t, map[string]string{"claude": "open", "openai": "closed"}, providers)
+	assert.False(t, down)
+
+	// Tous les circuits ouverts : dégradé
+	handler.SetAIStatus(stubCircuitSource{"claude": services.CircuitOpen, "openai": services.CircuitOpen})
+	_, down = handler.aiStatus()
+	assert.True(t, down)
+}
+
+type stubWorkerSource workers.Status
+
+func (s stubWorkerSource) Status() workers.Status { return workers.Status(s) }
+
+// Test état des workers de génération dans le deep health check
+func TestHealthDeep_LetterWorkers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	handler := NewHealthHandler(db, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	app := fiber.New()
+	app.Get("/health/deep", handler.HealthDeep)
+
+	handler.SetLetterWorkers(stubWorkerSource{ID: "api-1", State: workers.StateRunning, Concurrency: 2, Active: 1, Healthy: true})
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/deep", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var result HealthResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, "up", result.Services["letter_workers"])
+	if assert.NotNil(t, result.LetterWorkers) {
+		assert.Equal(t, "api-1", result.LetterWorkers.ID)
+		assert.Equal(t, 1, result.LetterWorkers.Active)
+	}
+
+	// Arrêt en cours : dégradé (plus de nouveaux jobs)
+	handler.SetLetterWorkers(stubWorkerSource{ID: "api-1", State: workers.StateDraining})
+	resp, _ = app.Test(httptest.NewRequest("GET", "/health/deep", nil))
+	assert.Equal(t, 503, resp.StatusCode)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	app := fiber.New()
+	app.Get("/lang", func(c *fiber.Ctx) error {
+		return c.SendString(string(negotiateLanguage(c)))
+	})
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		expected       string
+	}{
+		{"Default", "/lang", "", "fr"},
+		{"Accept-Language", "/lang", "de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"Query overrides header", "/lang?lang=es", "en-GB", "es"},
+		{"Unsupported query ignored", "/lang?lang=it", "en-GB", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.expected, string(body))
+			assert.Equal(t, tt.expected, resp.Header.Get("Content-Language"))
+			assert.Contains(t, resp.Header.Get("Vary"), "Accept-Language")
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+// Bornes du listing des campagnes
+const (
+	defaultBatchListLimit = 20
+	maxBatchListLimit     = 100
+)
+
+// LetterBatchesHandler endpoints des campagnes de génération de lettres (propriétaire du site)
+// Les campagnes ne passent pas par le rate limit IA des visiteurs : elles consomment
+// le quota journalier du propriétaire.
+type LetterBatchesHandler struct {
+	batches    *services.LetterBatchService
+	pdfService *services.PDFLetterService
+}
+
+// NewLetterBatchesHandler crée une nouvelle instance du handler
+func NewLetterBatchesHandler(batches *services.LetterBatchService) *LetterBatchesHandler {
+	return &LetterBatchesHandler{
+		batches: batches,
+	}
+}
+
+// SetPDFService active le rendu PDF des archives (texte brut sinon)
+func (h *LetterBatchesHandler) SetPDFService(pdfService *services.PDFLetterService) {
+	h.pdfService = pdfService
+}
+
+// RegisterRoutes enregistre les routes des campagnes derrière l'authentification du propriétaire
+// À enregistrer avant GET /letters/:id (catch-all).
+func (h *LetterBatchesHandler) RegisterRoutes(router fiber.Router, ownerAuth fiber.Handler) {
+	batches := router.Group("/letters/batches", ownerAuth)
+	batches.Post("", h.CreateBatch)
+	batches.Get("", h.ListBatches)
+	batches.Get("/:batchId", h.GetBatch)
+	batches.Get("/:batchId/archive", h.DownloadArchive)
+}
+
+// CreateBatch crée une campagne à partir d'une liste d'entreprises (CSV ou JSON)
+// POST /api/v1/letters/batches?name=...&language=fr&letter_types=motivation,linkedin_message
+// Corps : fichier multipart "file" (.csv / .json), ou liste brute (text/csv, application/json).
+// Colonnes : company, job_title, theme, posting_url.
+func (h *LetterBatchesHandler) CreateBatch(c *fiber.Ctx) error {
+	items, err := parseBatchUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid company list",
+			"code":    "INVALID_BATCH",
+			"details": err.Error(),
+		})
+	}
+
+	if len(items) > h.batches.MaxItems() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Too many companies",
+			"code":    "BATCH_TOO_LARGE",
+			"details": fmt.Sprintf("%d companies (max %d)", len(items), h.batches.MaxItems()),
+		})
+	}
+
+	language := c.Query("language")
+	var letterTypeValues []string
+	if value := c.Query("letter_types"); value != "" {
+		letterTypeValues = strings.Split(value, ",")
+	}
+	letterTypes, err := services.ParseLetterTypes(letterTypeValues)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"code":    "VALIDATION_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	// Mêmes règles qu'une génération unitaire, rapportées par ligne
+	var invalid []string
+	for i, item := range items {
+		req := dto.GenerateLetterRequest{
+			CompanyName:   item.CompanyName,
+			JobTitle:      item.JobTitle,
+			Theme:         item.Theme,
+			Language:      language,
+			JobPostingURL: item.JobPostingURL,
+		}
+		if err := req.Validate(); err != nil {
+			invalid = append(invalid, fmt.Sprintf("item %d: %v", i+1, err))
+		}
+	}
+	if len(invalid) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"code":    "VALIDATION_ERROR",
+			"details": invalid,
+		})
+	}
+
+	batch, err := h.batches.CreateBatch(c.UserContext(), services.LetterBatchRequest{
+		Name:        strings.TrimSpace(c.Query("name")),
+		Language:    language,
+		LetterTypes: letterTypes,
+		Items:       items,
+	})
+	var quotaErr *services.OwnerQuotaError
+	if errors.As(err, &quotaErr) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Quota journalier atteint",
+			"code":  "OWNER_QUOTA_EXCEEDED",
+			"details": fiber.Map{
+				"daily_quota": quotaErr.Limit,
+				"used":        quotaErr.Used,
+				"requested":   quotaErr.Requested,
+			},
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create batch",
+			"code":    "QUEUE_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	progress, err := h.batches.GetBatch(c.UserContext(), batch.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load batch",
+			"code":    "DATABASE_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	response := letterBatchResponse(progress)
+	if used, limit, err := h.batches.QuotaStatus(c.UserContext()); err == nil {
+		remaining := max(limit-used, 0)
+		response.QuotaRemaining = &remaining
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response)
+}
+
+// parseBatchUpload lit la liste d'entreprises (fichier multipart ou corps brut)
+func parseBatchUpload(c *fiber.Ctx) ([]services.LetterBatchItemRequest, error) {
+	if file, err := c.FormFile("file"); err == nil {
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		if format != "json" {
+			format = "csv"
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open upload: %w", err)
+		}
+		defer content.Close()
+		return services.ParseLetterBatch(format, content)
+	}
+
+	format := "csv"
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		format = "json"
+	}
+	return services.ParseLetterBatch(format, bytes.NewReader(c.Body()))
+}
+
+// ListBatches liste les campagnes les plus récentes
+// GET /api/v1/letters/batches?limit=20
+func (h *LetterBatchesHandler) ListBatches(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultBatchListLimit)))
+	if err != nil || limit < 1 || limit > maxBatchListLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid limit",
+			"code":    "INVALID_LIMIT",
+			"details": fmt.Sprintf("limit must be between 1 and %d", maxBatchListLimit),
+		})
+	}
+
+	batches, err := h.batches.ListBatches(c.UserContext(), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list batches",
+			"code":    "DATABASE_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	summaries := make([]dto.LetterBatchSummary, 0, len(batches))
+	for _, batch := range batches {
+		summaries = append(summaries, dto.LetterBatchSummary{
+			BatchID:   batch.ID.String(),
+			Name:      batch.Name,
+			Total:     batch.ItemCount,
+			CreatedAt: batch.CreatedAt,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"batches": summaries,
+	})
+}
+
+// GetBatch retourne l'avancement d'une campagne
+// GET /api/v1/letters/batches/:batchId
+func (h *LetterBatchesHandler) GetBatch(c *fiber.Ctx) error {
+	progress, ok, err := h.loadBatch(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(letterBatchResponse(progress))
+}
+
+// DownloadArchive télécharge les lettres générées d'une campagne (ZIP de PDF + manifest.csv)
+// GET /api/v1/letters/batches/:batchId/archive?renderer=auto|browser|native
+// L'archive d'une campagne en cours ne contient que les lettres déjà générées.
+func (h *LetterBatchesHandler) DownloadArchive(c *fiber.Ctx) error {
+	engine, err := services.ParsePDFEngine(c.Query("renderer"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Moteur de rendu PDF invalide",
+			"code":    "INVALID_RENDERER",
+			"details": err.Error(),
+		})
+	}
+
+	progress, ok, err := h.loadBatch(c)
+	if !ok {
+		return err
+	}
+
+	letters, err := h.batches.BatchLetters(c.UserContext(), progress)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load batch letters",
+			"code":    "DATABASE_ERROR",
+			"details": err.Error(),
+		})
+	}
+	if len(letters) == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Aucune lettre générée pour l'instant",
+			"code":    "BATCH_NOT_READY",
+			"details": fmt.Sprintf("batch is %s", progress.Status),
+		})
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := make(map[int][]string)
+
+	for _, batchLetter := range letters {
+		letter := batchLetter.Letter
+		extension := "pdf"
+		if h.pdfService == nil {
+			extension = "txt"
+		}
+		name := fmt.Sprintf("%02d_%s", batchLetter.Position, letterFilename(&letter, extension))
+
+		file, err := archive.Create(name)
+		if err == nil {
+			if h.pdfService == nil {
+				_, err = io.WriteString(file, letter.Content)
+			} else {
+				err = h.pdfService.GeneratePDFWithEngine(c.UserContext(), services.LetterResponseFromModel(&letter), engine, file)
+			}
+		}
+		if isPDFUnavailable(err) {
+			return respondPDFUnavailable(c, err)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Échec de la génération de l'archive",
+				"code":    "PDF_GENERATION_FAILED",
+				"details": err.Error(),
+			})
+		}
+		files[batchLetter.Position] = append(files[batchLetter.Position], name)
+	}
+
+	if err := writeBatchManifest(archive, progress, files); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Échec de la génération de l'archive",
+			"code":    "ARCHIVE_FAILED",
+			"details": err.Error(),
+		})
+	}
+	if err := archive.Close(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Échec de la génération de l'archive",
+			"code":    "ARCHIVE_FAILED",
+			"details": err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, batchArchiveFilename(&progress.Batch)))
+	c.Set("X-Batch-Status", string(progress.Status))
+	return c.Send(buf.Bytes())
+}
+
+// writeBatchManifest ajoute à l'archive l'état de chaque entreprise et ses fichiers
+func writeBatchManifest(archive *zip.Writer, progress *services.LetterBatchProgress, files map[int][]string) error {
+	file, err := archive.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+
+	manifest := csv.NewWriter(file)
+	_ = manifest.Write([]string{"position", "company", "job_title", "theme", "status", "error", "files"})
+	for _, item := range itemResponses(progress) {
+		errorMsg := ""
+		if item.Error != nil {
+			errorMsg = *item.Error
+		}
+		_ = manifest.Write([]string{
+			strconv.Itoa(item.Position),
+			item.CompanyName,
+			item.JobTitle,
+			item.Theme,
+			item.Status,
+			errorMsg,
+			strings.Join(files[item.Position], " "),
+		})
+	}
+	manifest.Flush()
+	return manifest.Error()
+}
+
+// batchArchiveFilename nom de l'archive : lettres_<campagne>.zip
+func batchArchiveFilename(batch *models.LetterBatch) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(batch.Name, "_"), "_")
+	if name == "" {
+		name = batch.ID.String()[:8]
+	}
+	return fmt.Sprintf("lettres_%s.zip", name)
+}
+
+// loadBatch charge la campagne de la route (ok = false : réponse d'erreur envoyée)
+func (h *LetterBatchesHandler) loadBatch(c *fiber.Ctx) (*services.LetterBatchProgress, bool, error) {
+	batchID, err := uuid.Parse(c.Params("batchId"))
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid batch ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	progress, err := h.batches.GetBatch(c.UserContext(), batchID)
+	if errors.Is(err, services.ErrBatchNotFound) {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Campagne non trouvée",
+			"code":  "BATCH_NOT_FOUND",
+		})
+	}
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load batch",
+			"code":    "DATABASE_ERROR",
+			"details": err.Error(),
+		})
+	}
+	return progress, true, nil
+}
+
+// letterBatchResponse convertit l'avancement d'une campagne en réponse API
+func letterBatchResponse(progress *services.LetterBatchProgress) dto.LetterBatchResponse {
+	counts := make(map[string]int, len(progress.Counts))
+	for status, count := range progress.Counts {
+		counts[string(status)] = count
+	}
+
+	return dto.LetterBatchResponse{
+		BatchID:    progress.Batch.ID.String(),
+		Name:       progress.Batch.Name,
+		Status:     string(progress.Status),
+		Progress:   progress.Progress,
+		Total:      progress.Batch.ItemCount,
+		Counts:     counts,
+		Items:      itemResponses(progress),
+		ArchiveURL: fmt.Sprintf("/api/v1/letters/batches/%s/archive", progress.Batch.ID),
+		CreatedAt:  progress.Batch.CreatedAt,
+	}
+}
+
+// itemResponses état de chaque entreprise d'une campagne
+func itemResponses(progress *services.LetterBatchProgress) []dto.LetterBatchItemResponse {
+	items := make([]dto.LetterBatchItemResponse, 0, len(progress.Items))
+	for _, itemProgress := range progress.Items {
+		item := dto.LetterBatchItemResponse{
+			Position:      itemProgress.Item.Position,
+			CompanyName:   itemProgress.Item.CompanyName,
+			JobTitle:      itemProgress.Item.JobTitle,
+			Theme:         itemProgress.Item.Theme,
+			JobPostingURL: itemProgress.Item.JobPostingURL,
+			JobID:         itemProgress.Item.JobID,
+		}
+
+		if job := itemProgress.Job; job != nil {
+			item.Status = string(job.Status)
+			item.Progress = job.Progress
+			item.Error = job.Error
+			for letterType, id := range services.LetterIDStrings(job.Letters) {
+				if item.Letters == nil {
+					item.Letters = make(map[string]string, len(job.Letters))
+				}
+				item.Letters[string(letterType)] = id
+			}
+		} else {
+			expired := "job expired"
+			item.Status = string(services.JobStatusFailed)
+			item.Error = &expired
+		}
+
+		items = append(items, item)
+	}
+	return items
+}
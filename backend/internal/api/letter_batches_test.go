@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/middleware"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+func newLetterBatchesTestApp(t *testing.T) (*fiber.App, *services.LetterBatchService) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.LetterBatch{}, &models.LetterBatchItem{}))
+
+	batches := services.NewLetterBatchService(db, redisClient, services.NewLetterQueueService(redisClient))
+	app := fiber.New()
+	NewLetterBatchesHandler(batches).RegisterRoutes(app.Group("/api/v1"), middleware.AdminAuth("secret"))
+	return app, batches
+}
+
+func TestLetterBatches_Create(t *testing.T) {
+	app, batches := newLetterBatchesTestApp(t)
+	batches.SetDailyQuota(3)
+
+	post := func(body, contentType string) (int, string) {
+		req := httptest.NewRequest("POST", "/api/v1/letters/batches?name=Q4&letter_types=motivation", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(middleware.AdminHeader, "secret")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		content, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(content)
+	}
+
+	status, body := post("company,theme\nGoogle,backend\nMeta,devops\n", "text/csv")
+	require.Equal(t, 202, status, body)
+
+	var created dto.LetterBatchResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	assert.Equal(t, "Q4", created.Name)
+	assert.Equal(t, "queued", created.Status)
+	assert.Equal(t, 2, created.Total)
+	assert.Equal(t, 2, created.Counts["queued"])
+	require.Len(t, created.Items, 2)
+	assert.Equal(t, "Meta", created.Items[1].CompanyName)
+	require.NotNil(t, created.QuotaRemaining)
+	assert.Equal(t, 1, *created.QuotaRemaining)
+	assert.Equal(t, "/api/v1/letters/batches/"+created.BatchID+"/archive", created.ArchiveURL)
+
+	// Lignes invalides rapportées par position, rien n'est créé
+	status, body = post(`[{"company_name":"Apple"},{"company_name":"X"},{"company_name":"Amazon","theme":"cobol"}]`, "application/json")
+	assert.Equal(t, 400, status)
+	var validation struct {
+		Code    string   `json:"code"`
+		Details []string `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &validation))
+	assert.Equal(t, "VALIDATION_ERROR", validation.Code)
+	require.Len(t, validation.Details, 2)
+	assert.True(t, strings.HasPrefix(validation.Details[0], "item 2:"))
+
+	status, body = post("Apple\nAmazon\n", "text/csv")
+	assert.Equal(t, 429, status)
+	assert.Contains(t, body, "OWNER_QUOTA_EXCEEDED")
+
+	status, body = post("company\n", "text/csv")
+	assert.Equal(t, 400, status)
+	assert.Contains(t, body, "INVALID_BATCH")
+}
+
+func TestLetterBatches_GetAndArchive(t *testing.T) {
+	app, batches := newLetterBatchesTestApp(t)
+
+	batch, err := batches.CreateBatch(t.Context(), services.LetterBatchRequest{
+		Items: []services.LetterBatchItemRequest{{CompanyName: "Google"}},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/api/v1/letters/batches", 200},
+		{"/api/v1/letters/batches/" + batch.ID.String(), 200},
+		{"/api/v1/letters/batches/" + batch.ID.String() + "/archive", 409}, // Aucune lettre générée
+		{"/api/v1/letters/batches/not-a-uuid", 400},
+		{"/api/v1/letters/batches/00000000-0000-0000-0000-000000000000", 404},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set(middleware.AdminHeader, "secret")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedStatus, resp.StatusCode, tc.path)
+	}
+
+	// Réservé au propriétaire
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api/v1/letters/batches", nil))
+	assert.Equal(t, 401, resp.StatusCode)
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"maicivy/internal/api/dto"
+	"maicivy/internal/models"
+	"maicivy/internal/services"
+)
+
+// LetterVersionsHandler handler pour la révision des lettres et leur historique de versions
+type LetterVersionsHandler struct {
+	db              *gorm.DB
+	queueService    services.LetterQueueServiceInterface
+	revisionService *services.LetterRevisionService
+}
+
+// NewLetterVersionsHandler crée une nouvelle instance du handler
+func NewLetterVersionsHandler(db *gorm.DB, queueService services.LetterQueueServiceInterface, revisionService *services.LetterRevisionService) *LetterVersionsHandler {
+	return &LetterVersionsHandler{
+		db:              db,
+		queueService:    queueService,
+		revisionService: revisionService,
+	}
+}
+
+// ReviseLetter révise une lettre à partir de retours libres, de façon asynchrone
+// Le résultat est stocké comme nouvelle version et devient la version courante.
+// POST /api/v1/letters/:id/revise
+func (h *LetterVersionsHandler) ReviseLetter(c *fiber.Ctx) error {
+	letterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid letter ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	var req dto.ReviseLetterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"code":    "INVALID_REQUEST",
+			"details": err.Error(),
+		})
+	}
+	req.Feedback = strings.TrimSpace(req.Feedback)
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"code":    "VALIDATION_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	sessionID, letter, err := h.ownedLetter(c, letterID)
+	if letter == nil {
+		return err
+	}
+
+	if err := h.revisionService.CheckRevisionLimit(c.Context(), letter); err != nil {
+		return revisionError(c, err)
+	}
+
+	jobID, err := h.queueService.EnqueueRevision(services.LetterRevisionRequest{
+		VisitorID: sessionID,
+		LetterID:  letter.ID,
+		Feedback:  req.Feedback,
+		Priority:  jobPriority(c),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to enqueue revision job",
+			"code":    "QUEUE_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.LetterRevisionResponse{
+		JobID:     jobID,
+		Status:    "queued",
+		LetterID:  letter.ID.String(),
+		StreamURL: fmt.Sprintf("/api/v1/letters/job/%s/stream", jobID),
+	})
+}
+
+// ListVersions liste les versions d'une lettre
+// GET /api/v1/letters/:id/versions
+func (h *LetterVersionsHandler) ListVersions(c *fiber.Ctx) error {
+	letterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid letter ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	_, letter, err := h.ownedLetter(c, letterID)
+	if letter == nil {
+		return err
+	}
+
+	versions, err := h.revisionService.ListVersions(c.Context(), letter)
+	if err != nil {
+		return revisionError(c, err)
+	}
+
+	items := make([]dto.LetterVersionResponse, len(versions))
+	for i, version := range versions {
+		items[i] = toLetterVersionResponse(letter, &version)
+	}
+
+	return c.JSON(dto.LetterVersionsResponse{
+		LetterID:       letter.ID.String(),
+		CurrentVersion: currentVersion(letter),
+		MaxVersions:    services.MaxLetterVersions,
+		Versions:       items,
+	})
+}
+
+// DiffVersions compare deux versions d'une lettre (mot à mot)
+// GET /api/v1/letters/:id/versions/diff?from=1&to=2
+func (h *LetterVersionsHandler) DiffVersions(c *fiber.Ctx) error {
+	letterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid letter ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Paramètres from et to requis (numéros de version)",
+			"code":  "INVALID_VERSION",
+		})
+	}
+
+	_, letter, err := h.ownedLetter(c, letterID)
+	if letter == nil {
+		return err
+	}
+
+	diff, err := h.revisionService.DiffVersions(c.Context(), letter, from, to)
+	if err != nil {
+		return revisionError(c, err)
+	}
+
+	return c.JSON(diff)
+}
+
+// SelectVersion choisit la version courante, utilisée notamment pour le téléchargement PDF
+// POST /api/v1/letters/:id/versions/:version/select
+func (h *LetterVersionsHandler) SelectVersion(c *fiber.Ctx) error {
+	letterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid letter ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	number, err := strconv.Atoi(c.Params("version"))
+	if err != nil || number < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid version",
+			"code":  "INVALID_VERSION",
+		})
+	}
+
+	_, letter, err := h.ownedLetter(c, letterID)
+	if letter == nil {
+		return err
+	}
+
+	version, err := h.revisionService.SelectVersion(c.Context(), letter, number)
+	if err != nil {
+		return revisionError(c, err)
+	}
+
+	return c.JSON(toLetterVersionResponse(letter, version))
+}
+
+// ownedLetter récupère une lettre appartenant au visiteur de la session
+// Si la lettre est nil, la réponse d'erreur a déjà été écrite et err est le résultat de l'écriture.
+func (h *LetterVersionsHandler) ownedLetter(c *fiber.Ctx, letterID uuid.UUID) (string, *models.GeneratedLetter, error) {
+	sessionID, ok := c.Locals("session_id").(string)
+	if !ok || sessionID == "" {
+		sessionID = c.Cookies("maicivy_session")
+	}
+	if sessionID == "" {
+		return "", nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Session requise",
+			"code":  "SESSION_REQUIRED",
+		})
+	}
+
+	var visitor models.Visitor
+	if err := h.db.Where("session_id = ?", sessionID).First(&visitor).Error; err != nil {
+		return "", nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Visiteur non trouvé",
+			"code":  "VISITOR_NOT_FOUND",
+		})
+	}
+
+	var letter models.GeneratedLetter
+	result := h.db.Where("id = ? AND visitor_id = ?", letterID, visitor.ID).First(&letter)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return "", nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Lettre non trouvée",
+				"code":  "LETTER_NOT_FOUND",
+			})
+		}
+		return "", nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Database error",
+			"code":    "DB_ERROR",
+			"details": result.Error.Error(),
+		})
+	}
+
+	return sessionID, &letter, nil
+}
+
+// revisionError convertit une erreur du service de révision en réponse HTTP
+func revisionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrRevisionLimitReached):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Nombre maximum de versions atteint pour cette lettre",
+			"code":    "REVISION_LIMIT_REACHED",
+			"details": fmt.Sprintf("max %d versions", services.MaxLetterVersions),
+		})
+	case errors.Is(err, services.ErrLetterVersionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Version non trouvée",
+			"code":  "VERSION_NOT_FOUND",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   "Database error",
+		"code":    "DB_ERROR",
+		"details": err.Error(),
+	})
+}
+
+// currentVersion retourne la version courante (1 pour les lettres antérieures à l'historique)
+func currentVersion(letter *models.GeneratedLetter) int {
+	if letter.CurrentVersion < 1 {
+		return 1
+	}
+	return letter.CurrentVersion
+}
+
+// toLetterVersionResponse mappe une version vers son DTO
+func toLetterVersionResponse(letter *models.GeneratedLetter, version *models.LetterVersion) dto.LetterVersionResponse {
+	return dto.LetterVersionResponse{
+		Version:      version.Version,
+		Content:      version.Content,
+		Feedback:     version.Feedback,
+		Current:      version.Version == currentVersion(letter),
+		CreatedAt:    version.CreatedAt.Format("2006-01-02 15:04:05"),
+		AIModel:      version.AIModel,
+		TokensUsed:   version.TokensUsed,
+		GenerationMS: version.GenerationMS,
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"maicivy/internal/api/dto"
+)
+
+func newLetterVersionsTestApp(withSession bool) *fiber.App {
+	app := fiber.New()
+	handler := &LetterVersionsHandler{queueService: new(MockLetterQueueService)}
+
+	app.Use(func(c *fiber.Ctx) error {
+		if withSession {
+			c.Locals("session_id", "test-session")
+		}
+		return c.Next()
+	})
+	app.Post("/api/v1/letters/:id/revise", handler.ReviseLetter)
+	app.Get("/api/v1/letters/:id/versions/diff", handler.DiffVersions)
+	app.Post("/api/v1/letters/:id/versions/:version/select", handler.SelectVersion)
+
+	return app
+}
+
+// Test POST /api/v1/letters/:id/revise - Validation Errors
+func TestReviseLetter_ValidationErrors(t *testing.T) {
+	app := newLetterVersionsTestApp(true)
+	letterID := uuid.New().String()
+
+	testCases := []struct {
+		name         string
+		letterID     string
+		feedback     string
+		expectedCode string
+	}{
+		{"Invalid letter ID", "not-a-uuid", "Plus concis", "INVALID_ID"},
+		{"Empty feedback", letterID, "", "VALIDATION_ERROR"},
+		{"Whitespace feedback", letterID, "   \n ", "VALIDATION_ERROR"},
+		{"Feedback too long", letterID, strings.Repeat("a", 1001), "VALIDATION_ERROR"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(dto.ReviseLetterRequest{Feedback: tc.feedback})
+			req := httptest.NewRequest("POST", "/api/v1/letters/"+tc.letterID+"/revise", bytes.NewReader(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode)
+
+			var errorResp map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&errorResp)
+			assert.Equal(t, tc.expectedCode, errorResp["code"])
+		})
+	}
+}
+
+// Test POST /api/v1/letters/:id/revise - Missing Session
+func TestReviseLetter_MissingSession(t *testing.T) {
+	app := newLetterVersionsTestApp(false)
+
+	jsonBody, _ := json.Marshal(dto.ReviseLetterRequest{Feedback: "Mentionner Kubernetes"})
+	req := httptest.NewRequest("POST", "/api/v1/letters/"+uuid.New().String()+"/revise", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	var errorResp map[string]string
+	json.NewDecoder(resp.Body).Decode(&errorResp)
+	assert.Equal(t, "SESSION_REQUIRED", errorResp["code"])
+}
+
+// Test des paramètres de version invalides (diff et sélection)
+func TestLetterVersions_InvalidVersion(t *testing.T) {
+	app := newLetterVersionsTestApp(true)
+	letterID := uuid.New().String()
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"Diff without params", "GET", "/api/v1/letters/" + letterID + "/versions/diff"},
+		{"Diff with zero version", "GET", "/api/v1/letters/" + letterID + "/versions/diff?from=0&to=2"},
+		{"Diff with non numeric version", "GET", "/api/v1/letters/" + letterID + "/versions/diff?from=1&to=last"},
+		{"Select non numeric version", "POST", "/api/v1/letters/" + letterID + "/versions/latest/select"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tc.method, tc.url, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode)
+
+			var errorResp map[string]string
+			json.NewDecoder(resp.Body).Decode(&errorResp)
+			assert.Equal(t, "INVALID_VERSION", errorResp["code"])
+		})
+	}
+}
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 
 	"maicivy/internal/api/dto"
@@ -19,21 +26,62 @@ import (
 
 // LettersHandler handler pour les endpoints de génération de lettres
 type LettersHandler struct {
-	db           *gorm.DB
-	redis        *redis.Client
-	queueService services.LetterQueueServiceInterface
+	db            *gorm.DB
+	redis         *redis.Client
+	queueService  services.LetterQueueServiceInterface
+	streamService *services.LetterStreamService
+	pdfService    *services.PDFLetterService
+	idempotency   *services.LetterIdempotencyService
+	webhooks      services.WebhookPublisher
+	companies     CompanyResolver
+	domains       CompanySessionDomains
 }
 
+// CompanySessionDomains domaine d'entreprise choisi par le visiteur (services.CompanyDomainChoiceService)
+type CompanySessionDomains interface {
+	SessionDomain(ctx context.Context, sessionID, name string) (string, error)
+}
+
+// aiDailyGenerationLimit générations IA par session et par jour (AIRateLimitConfig.MaxPerDay)
+const aiDailyGenerationLimit = 5
+
 // NewLettersHandler crée une nouvelle instance du handler
-func NewLettersHandler(db *gorm.DB, redis *redis.Client, queueService services.LetterQueueServiceInterface) *LettersHandler {
+func NewLettersHandler(db *gorm.DB, redis *redis.Client, queueService services.LetterQueueServiceInterface, streamService *services.LetterStreamService) *LettersHandler {
 	return &LettersHandler{
-		db:           db,
-		redis:        redis,
-		queueService: queueService,
+		db:            db,
+		redis:         redis,
+		queueService:  queueService,
+		streamService: streamService,
 	}
 }
 
-// GenerateLetter génère une paire de lettres (motivation + anti-motivation) de façon asynchrone
+// SetPDFService active le rendu PDF des lettres (texte brut sinon)
+func (h *LettersHandler) SetPDFService(pdfService *services.PDFLetterService) {
+	h.pdfService = pdfService
+}
+
+// SetIdempotencyService active la déduplication des requêtes de génération
+func (h *LettersHandler) SetIdempotencyService(idempotency *services.LetterIdempotencyService) {
+	h.idempotency = idempotency
+}
+
+// SetWebhookPublisher active l'événement letter.downloaded
+func (h *LettersHandler) SetWebhookPublisher(publisher services.WebhookPublisher) {
+	h.webhooks = publisher
+}
+
+// SetCompanyResolver retrouve les lettres par entreprise canonique ("BNP" = "BNP Paribas SA")
+func (h *LettersHandler) SetCompanyResolver(companies CompanyResolver) {
+	h.companies = companies
+}
+
+// SetCompanyDomains utilise pour les lettres du visiteur le domaine qu'il a choisi
+func (h *LettersHandler) SetCompanyDomains(domains CompanySessionDomains) {
+	h.domains = domains
+}
+
+// GenerateLetter génère de façon asynchrone les lettres des types demandés
+// (letter_types, défaut: motivation + anti-motivation)
 // POST /api/v1/letters/generate
 func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 	// Parser request
@@ -55,6 +103,15 @@ func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 		})
 	}
 
+	letterTypes, err := services.ParseLetterTypes(req.LetterTypes)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"code":    "VALIDATION_ERROR",
+			"details": err.Error(),
+		})
+	}
+
 	// Récupérer session ID depuis les locals (mis par tracking middleware)
 	sessionID, ok := c.Locals("session_id").(string)
 	if !ok || sessionID == "" {
@@ -68,8 +125,29 @@ func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 		})
 	}
 
+	// Domaine choisi par le visiteur pour cette entreprise (à défaut : domaine connu ou deviné)
+	var companyDomain string
+	if h.domains != nil {
+		domain, err := h.domains.SessionDomain(c.UserContext(), sessionID, req.CompanyName)
+		if err != nil {
+			log.Warn().Err(err).Str("company", req.CompanyName).Msg("Failed to get chosen company domain")
+		}
+		companyDomain = domain
+	}
+
 	// Enqueue job
-	jobID, err := h.queueService.EnqueueJob(sessionID, req.CompanyName, req.JobTitle, req.Theme)
+	jobID, err := h.queueService.EnqueueJob(services.LetterJobRequest{
+		VisitorID:      sessionID,
+		CompanyName:    req.CompanyName,
+		CompanyDomain:  companyDomain,
+		JobTitle:       req.JobTitle,
+		Theme:          req.Theme,
+		Language:       req.Language,
+		JobPostingURL:  req.JobPostingURL,
+		JobPostingText: req.JobPostingText,
+		LetterTypes:    letterTypes,
+		Priority:       jobPriority(c),
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to enqueue generation job",
@@ -78,6 +156,9 @@ func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 		})
 	}
 
+	// Job créé : associé à la requête par DeduplicateGeneration
+	c.Locals("letter_job_id", jobID)
+
 	// Incrémenter rate limit (APRÈS enqueue success)
 	cooldownDuration := 2 * time.Minute
 	if err := middleware.IncrementAIRateLimit(c, h.redis, cooldownDuration); err != nil {
@@ -91,10 +172,11 @@ func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 		remaining = 0
 	}
 
-	// Retourner job ID pour polling
+	// Retourner job ID pour polling / streaming
 	return c.Status(fiber.StatusAccepted).JSON(dto.LetterGenerationResponse{
-		JobID:  jobID,
-		Status: "queued",
+		JobID:     jobID,
+		Status:    "queued",
+		StreamURL: fmt.Sprintf("/api/v1/letters/job/%s/stream", jobID),
 		Message: fmt.Sprintf(
 			"Génération en cours. Encore %d génération(s) disponible(s) aujourd'hui.",
 			remaining,
@@ -103,6 +185,198 @@ func (h *LettersHandler) GenerateLetter(c *fiber.Ctx) error {
 	})
 }
 
+// DeduplicateGeneration rejoue les requêtes de génération déjà acceptées (monté AVANT le rate limit IA)
+// Header Idempotency-Key : une même clé retourne toujours le job d'origine (422 si la requête diffère).
+// Sans clé, une requête identique de la session dans la fenêtre de déduplication retourne le job
+// en cours ou terminé. Une requête rejouée ne crée pas de job et ne touche pas aux compteurs du quota.
+func (h *LettersHandler) DeduplicateGeneration(c *fiber.Ctx) error {
+	if h.idempotency == nil {
+		return c.Next()
+	}
+
+	idempotencyKey := strings.TrimSpace(c.Get("Idempotency-Key"))
+	if len(idempotencyKey) > 255 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid Idempotency-Key header",
+			"code":    "INVALID_IDEMPOTENCY_KEY",
+			"details": "key must not exceed 255 characters",
+		})
+	}
+
+	// Requêtes invalides : erreurs retournées par GenerateLetter
+	var req dto.GenerateLetterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Next()
+	}
+	letterTypes, err := services.ParseLetterTypes(req.LetterTypes)
+	if err != nil {
+		return c.Next()
+	}
+
+	sessionID, ok := c.Locals("session_id").(string)
+	if !ok || sessionID == "" {
+		sessionID = c.Cookies("maicivy_session")
+	}
+	if sessionID == "" {
+		return c.Next()
+	}
+
+	job, reservation, err := h.idempotency.Reserve(c.Context(), sessionID, idempotencyKey, services.LetterJobRequest{
+		CompanyName:    req.CompanyName,
+		JobTitle:       req.JobTitle,
+		Theme:          req.Theme,
+		Language:       req.Language,
+		JobPostingURL:  req.JobPostingURL,
+		JobPostingText: req.JobPostingText,
+		LetterTypes:    letterTypes,
+	})
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Idempotency-Key already used",
+			"code":    "IDEMPOTENCY_KEY_REUSED",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrIdempotencyInProgress):
+		c.Set("Retry-After", "1")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Requête identique en cours",
+			"code":    "REQUEST_IN_PROGRESS",
+			"details": err.Error(),
+		})
+	case err != nil:
+		// Redis indisponible : pas de déduplication
+		log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to deduplicate letter generation request")
+		return c.Next()
+	case job != nil:
+		return h.replayGeneration(c, sessionID, job)
+	case reservation == nil:
+		return c.Next()
+	}
+
+	err = c.Next()
+
+	// Contexte indépendant de la requête (fasthttp le recycle après la réponse)
+	ctx := context.Background()
+	if jobID, ok := c.Locals("letter_job_id").(string); ok && jobID != "" {
+		if completeErr := h.idempotency.Complete(ctx, reservation, jobID); completeErr != nil {
+			log.Warn().Err(completeErr).Str("job_id", jobID).Msg("Failed to record letter generation request")
+		}
+	} else if releaseErr := h.idempotency.Release(ctx, reservation); releaseErr != nil {
+		log.Warn().Err(releaseErr).Str("session_id", sessionID).Msg("Failed to release letter generation request")
+	}
+	return err
+}
+
+// replayGeneration réponse d'une requête déjà acceptée (quota inchangé)
+func (h *LettersHandler) replayGeneration(c *fiber.Ctx, sessionID string, job *services.LetterJob) error {
+	remaining := aiDailyGenerationLimit
+	if h.redis != nil {
+		used, _ := h.redis.Get(c.Context(), fmt.Sprintf("ratelimit:ai:%s:daily", sessionID)).Int()
+		remaining = max(aiDailyGenerationLimit-used, 0)
+	}
+
+	c.Set("Idempotent-Replayed", "true")
+	return c.JSON(dto.LetterGenerationResponse{
+		JobID:              job.JobID,
+		Status:             string(job.Status),
+		StreamURL:          fmt.Sprintf("/api/v1/letters/job/%s/stream", job.JobID),
+		Message:            "Requête déjà reçue : génération d'origine retournée.",
+		RateLimitRemaining: remaining,
+		Replayed:           true,
+	})
+}
+
+// jobPriority file de priorité du job selon le profil détecté du visiteur (tracking middleware)
+// Les profils cibles (recruteur, CTO...) passent devant les visiteurs anonymes ; vide = normale.
+func jobPriority(c *fiber.Ctx) services.JobPriority {
+	profile, _ := c.Locals("profile_detected").(string)
+	if priority := services.JobPriorityForProfile(models.ProfileType(profile)); priority == services.JobPriorityHigh {
+		return priority
+	}
+	return ""
+}
+
+// CancelJob annule un job en attente ou en cours (les appels IA en cours sont interrompus)
+// DELETE /api/v1/letters/job/:jobId
+func (h *LettersHandler) CancelJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+
+	sessionID, ok := c.Locals("session_id").(string)
+	if !ok || sessionID == "" {
+		sessionID = c.Cookies("maicivy_session")
+	}
+	if sessionID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Session requise",
+			"code":  "SESSION_REQUIRED",
+		})
+	}
+
+	// Seul le visiteur ayant lancé le job peut l'annuler
+	job, err := h.queueService.GetJobStatus(jobID)
+	if err != nil || job.VisitorID != sessionID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job non trouvé",
+			"code":  "JOB_NOT_FOUND",
+		})
+	}
+
+	job, err = h.queueService.CancelJob(jobID)
+	if errors.Is(err, services.ErrJobFinished) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Job déjà terminé",
+			"code":    "JOB_FINISHED",
+			"details": fmt.Sprintf("job is %s", job.Status),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to cancel job",
+			"code":    "QUEUE_ERROR",
+			"details": err.Error(),
+		})
+	}
+
+	// Clôturer les flux SSE/WebSocket ouverts
+	if h.streamService != nil {
+		if err := h.streamService.Publish(c.Context(), jobID, services.LetterStreamEvent{Type: services.StreamEventCancelled}); err != nil {
+			log.Warn().Err(err).Str("job_id", jobID).Msg("Failed to publish job cancellation")
+		}
+	}
+
+	return c.JSON(dto.LetterJobCancelResponse{
+		JobID:   jobID,
+		Status:  string(job.Status),
+		Message: "Génération annulée",
+	})
+}
+
+// ListLetterTypes liste les types de lettres disponibles à la génération
+// GET /api/v1/letters/types
+func (h *LettersHandler) ListLetterTypes(c *fiber.Ctx) error {
+	defaults := make(map[models.LetterType]bool, len(services.DefaultLetterTypes))
+	for _, letterType := range services.DefaultLetterTypes {
+		defaults[letterType] = true
+	}
+
+	types := make([]dto.LetterTypeResponse, 0)
+	for _, letterType := range services.LetterTypes() {
+		spec, _ := services.LetterTypeSpecFor(letterType)
+		types = append(types, dto.LetterTypeResponse{
+			Type:     string(spec.Type),
+			Format:   string(spec.Format),
+			MinWords: spec.MinWords,
+			MaxWords: spec.MaxWords,
+			Default:  defaults[spec.Type],
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"types": types,
+	})
+}
+
 // GetJobStatus récupère le status d'un job de génération
 // GET /api/v1/letters/jobs/:jobId
 func (h *LettersHandler) GetJobStatus(c *fiber.Ctx) error {
@@ -124,28 +398,141 @@ func (h *LettersHandler) GetJobStatus(c *fiber.Ctx) error {
 		estimatedTime = &remaining
 	}
 
-	// Convert uint IDs to string if present
+	// Convert UUIDs to string if present
 	var motivationIDStr, antiMotivationIDStr *string
 	if job.LetterMotivationID != nil {
-		idStr := fmt.Sprintf("%d", *job.LetterMotivationID)
+		idStr := job.LetterMotivationID.String()
 		motivationIDStr = &idStr
 	}
 	if job.LetterAntiMotivationID != nil {
-		idStr := fmt.Sprintf("%d", *job.LetterAntiMotivationID)
+		idStr := job.LetterAntiMotivationID.String()
 		antiMotivationIDStr = &idStr
 	}
 
+	var letterTypes []string
+	var letters map[string]string
+	if !job.IsRevision() {
+		for _, letterType := range job.RequestedLetterTypes() {
+			letterTypes = append(letterTypes, string(letterType))
+		}
+		for letterType, id := range services.LetterIDStrings(job.Letters) {
+			if letters == nil {
+				letters = make(map[string]string, len(job.Letters))
+			}
+			letters[string(letterType)] = id
+		}
+	}
+
+	var letterIDStr *string
+	if job.LetterID != nil {
+		idStr := job.LetterID.String()
+		letterIDStr = &idStr
+	}
+
 	return c.JSON(dto.LetterJobStatus{
 		JobID:                  job.JobID,
 		Status:                 string(job.Status),
 		Progress:               job.Progress,
+		LetterTypes:            letterTypes,
+		Letters:                letters,
 		LetterMotivationID:     motivationIDStr,
 		LetterAntiMotivationID: antiMotivationIDStr,
+		LetterID:               letterIDStr,
+		LetterVersion:          job.LetterVersion,
 		Error:                  job.Error,
 		EstimatedTime:          estimatedTime,
 	})
 }
 
+// StreamJob diffuse la génération d'un job en Server-Sent Events
+// Rejoue les fragments déjà produits puis transmet les nouveaux jusqu'à l'événement "completed" ou "failed"
+// GET /api/v1/letters/job/:jobId/stream
+func (h *LettersHandler) StreamJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+
+	job, err := h.queueService.GetJobStatus(jobID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Job non trouvé",
+			"code":    "JOB_NOT_FOUND",
+			"details": err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Désactiver le buffering nginx
+
+	// Job déjà terminé : envoyer directement l'événement final
+	if final := job.TerminalStreamEvent(); final != nil {
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			writeSSEEvent(w, *final)
+			w.Flush()
+		})
+		return nil
+	}
+
+	if h.streamService == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Streaming indisponible",
+			"code":  "STREAM_UNAVAILABLE",
+		})
+	}
+
+	conn := c.Context().Conn()
+	lastID := c.Get("Last-Event-ID", "0")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx := context.Background()
+		deadline := time.Now().Add(sseMaxDuration)
+
+		for time.Now().Before(deadline) {
+			events, err := h.streamService.Read(ctx, jobID, lastID, sseReadBlock)
+			if err != nil {
+				return
+			}
+
+			// Le WriteTimeout du serveur s'applique à toute la réponse : le repousser à chaque écriture
+			conn.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+
+			if len(events) == 0 {
+				// Le flux a pu expirer alors que le job s'est terminé entre-temps
+				if job, err := h.queueService.GetJobStatus(jobID); err == nil {
+					if final := job.TerminalStreamEvent(); final != nil {
+						writeSSEEvent(w, *final)
+						w.Flush()
+						return
+					}
+				}
+
+				// Heartbeat pour garder la connexion ouverte à travers les proxies
+				fmt.Fprint(w, ": ping\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+				continue
+			}
+
+			for _, event := range events {
+				writeSSEEvent(w, event)
+				lastID = event.ID
+				if event.IsTerminal() {
+					w.Flush()
+					return
+				}
+			}
+
+			if err := w.Flush(); err != nil {
+				// Client déconnecté
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
 // GetLetter récupère une lettre générée par ID
 // GET /api/v1/letters/:id
 func (h *LettersHandler) GetLetter(c *fiber.Ctx) error {
@@ -205,6 +592,7 @@ func (h *LettersHandler) GetLetter(c *fiber.Ctx) error {
 		ID:           letter.ID.String(),
 		CompanyName:  letter.CompanyName,
 		LetterType:   string(letter.LetterType),
+		Language:     string(letter.Language),
 		Content:      letter.Content,
 		CreatedAt:    letter.CreatedAt.Format("2006-01-02 15:04:05"),
 		AIModel:      letter.AIModel,
@@ -212,6 +600,10 @@ func (h *LettersHandler) GetLetter(c *fiber.Ctx) error {
 		GenerationMS: letter.GenerationMS,
 		Cost:         letter.EstimatedCost(),
 		PDFURL:       pdfURL,
+
+		QualityScore:    letter.QualityScore,
+		QualityFindings: toQualityFindings(letter.QualityFindings),
+		Regenerations:   letter.Regenerations,
 	})
 }
 
@@ -244,9 +636,17 @@ func (h *LettersHandler) GetLetterPair(c *fiber.Ctx) error {
 		})
 	}
 
-	// Récupérer les deux lettres
+	// Récupérer les deux lettres (les autres types générés pour l'entreprise sont ignorés)
+	// Avec la résolution des noms, toutes les variantes du nom de l'entreprise correspondent
+	query := h.db.Where("visitor_id = ? AND letter_type IN ?", visitor.ID, services.DefaultLetterTypes)
+	if company := h.resolveCompany(c, companyName); company != nil {
+		query = query.Where("(company_id = ? OR company_name = ?)", company.ID, companyName)
+	} else {
+		query = query.Where("company_name = ?", companyName)
+	}
+
 	var letters []models.GeneratedLetter
-	result = h.db.Where("visitor_id = ? AND company_name = ?", visitor.ID, companyName).
+	result = query.
 		Order("created_at DESC").
 		Limit(2).
 		Find(&letters)
@@ -276,6 +676,7 @@ func (h *LettersHandler) GetLetterPair(c *fiber.Ctx) error {
 			ID:           letter.ID.String(),
 			CompanyName:  letter.CompanyName,
 			LetterType:   string(letter.LetterType),
+			Language:     string(letter.Language),
 			Content:      letter.Content,
 			CreatedAt:    letter.CreatedAt.Format("2006-01-02 15:04:05"),
 			AIModel:      letter.AIModel,
@@ -283,11 +684,16 @@ func (h *LettersHandler) GetLetterPair(c *fiber.Ctx) error {
 			GenerationMS: letter.GenerationMS,
 			Cost:         letter.EstimatedCost(),
 			PDFURL:       pdfURL,
+
+			QualityScore:    letter.QualityScore,
+			QualityFindings: toQualityFindings(letter.QualityFindings),
+			Regenerations:   letter.Regenerations,
 		}
 
-		if letter.LetterType == models.LetterTypeMotivation {
+		switch letter.LetterType {
+		case models.LetterTypeMotivation:
 			motivationLetter = letterDetail
-		} else {
+		case models.LetterTypeAntiMotivation:
 			antiMotivationLetter = letterDetail
 		}
 	}
@@ -299,6 +705,19 @@ func (h *LettersHandler) GetLetterPair(c *fiber.Ctx) error {
 	})
 }
 
+// resolveCompany entreprise canonique d'un nom (nil si inconnue ou sans résolution)
+func (h *LettersHandler) resolveCompany(c *fiber.Ctx, companyName string) *models.Company {
+	if h.companies == nil {
+		return nil
+	}
+	resolution, err := h.companies.ResolveCompany(c.UserContext(), companyName)
+	if err != nil {
+		log.Warn().Err(err).Str("company", companyName).Msg("Company resolution failed")
+		return nil
+	}
+	return resolution.Company
+}
+
 // GetHistory récupère l'historique des lettres générées
 // GET /api/v1/letters/history?page=1&per_page=10
 func (h *LettersHandler) GetHistory(c *fiber.Ctx) error {
@@ -364,6 +783,9 @@ func (h *LettersHandler) GetHistory(c *fiber.Ctx) error {
 			CreatedAt:   letter.CreatedAt.Format("2006-01-02 15:04:05"),
 			Downloaded:  letter.Downloaded,
 		}
+		if letter.CompanyID != nil {
+			items[i].CompanyID = letter.CompanyID.String()
+		}
 	}
 
 	return c.JSON(dto.LetterHistoryResponse{
@@ -375,7 +797,7 @@ func (h *LettersHandler) GetHistory(c *fiber.Ctx) error {
 }
 
 // DownloadPDF télécharge le PDF d'une lettre
-// GET /api/v1/letters/:id/pdf
+// GET /api/v1/letters/:id/pdf?renderer=auto|browser|native
 func (h *LettersHandler) DownloadPDF(c *fiber.Ctx) error {
 	letterID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -385,6 +807,15 @@ func (h *LettersHandler) DownloadPDF(c *fiber.Ctx) error {
 		})
 	}
 
+	engine, err := services.ParsePDFEngine(c.Query("renderer"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Moteur de rendu PDF invalide",
+			"code":    "INVALID_RENDERER",
+			"details": err.Error(),
+		})
+	}
+
 	sessionID, ok := c.Locals("session_id").(string)
 	if !ok || sessionID == "" {
 		sessionID = c.Cookies("maicivy_session")
@@ -416,20 +847,147 @@ func (h *LettersHandler) DownloadPDF(c *fiber.Ctx) error {
 		})
 	}
 
-	// Marquer comme téléchargée
+	// Service PDF indisponible (templates absents) : texte brut
+	if h.pdfService == nil {
+		h.publishDownloaded(c, &letter, "txt")
+		h.markDownloaded(&letter)
+
+		c.Set("Content-Type", "text/plain")
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, letterFilename(&letter, "txt")))
+		return c.SendString(letter.Content)
+	}
+
+	var buf bytes.Buffer
+	err = h.pdfService.GeneratePDFWithEngine(c.UserContext(), services.LetterResponseFromModel(&letter), engine, &buf)
+	if isPDFUnavailable(err) {
+		return respondPDFUnavailable(c, err)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Échec de la génération du PDF",
+			"code":    "PDF_GENERATION_FAILED",
+			"details": err.Error(),
+		})
+	}
+
+	h.publishDownloaded(c, &letter, "pdf")
+	h.markDownloaded(&letter)
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, letterFilename(&letter, "pdf")))
+
+	return c.Send(buf.Bytes())
+}
+
+// publishDownloaded diffuse l'événement letter.downloaded (avant markDownloaded : premier téléchargement)
+func (h *LettersHandler) publishDownloaded(c *fiber.Ctx, letter *models.GeneratedLetter, format string) {
+	if h.webhooks == nil {
+		return
+	}
+
+	data := services.WebhookLetterData{
+		LetterID:     letter.ID.String(),
+		LetterType:   letter.LetterType,
+		CompanyName:  letter.CompanyName,
+		Format:       format,
+		FirstTime:    !letter.Downloaded,
+		DownloadedAt: time.Now().UTC(),
+	}
+	if err := h.webhooks.Publish(c.UserContext(), services.WebhookEventLetterDownloaded, data); err != nil {
+		log.Warn().Err(err).Str("letter_id", data.LetterID).Msg("Failed to publish letter download webhook")
+	}
+}
+
+// markDownloaded marque une lettre comme téléchargée
+func (h *LettersHandler) markDownloaded(letter *models.GeneratedLetter) {
 	if !letter.Downloaded {
 		letter.Downloaded = true
-		h.db.Save(&letter)
+		h.db.Save(letter)
 	}
+}
 
-	// TODO: Générer ou servir le PDF (sera implémenté dans Doc 08)
-	// Pour l'instant, retourner le texte en tant que "PDF"
-	filename := fmt.Sprintf("lettre_%s_%s.txt", letter.LetterType, letter.CompanyName)
+// ExportLetter exporte une lettre dans un format éditable (sans navigateur headless)
+// GET /api/v1/letters/:id/export?format=docx|odt|md|txt
+func (h *LettersHandler) ExportLetter(c *fiber.Ctx) error {
+	letterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid letter ID",
+			"code":  "INVALID_ID",
+		})
+	}
+
+	format, err := services.ParseExportFormat(c.Query("format", string(services.ExportFormatDOCX)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Format d'export invalide",
+			"code":    "INVALID_FORMAT",
+			"details": err.Error(),
+		})
+	}
+
+	sessionID, ok := c.Locals("session_id").(string)
+	if !ok || sessionID == "" {
+		sessionID = c.Cookies("maicivy_session")
+	}
+	if sessionID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Session requise",
+			"code":  "SESSION_REQUIRED",
+		})
+	}
+
+	var visitor models.Visitor
+	result := h.db.Where("session_id = ?", sessionID).First(&visitor)
+	if result.Error != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Visiteur non trouvé",
+			"code":  "VISITOR_NOT_FOUND",
+		})
+	}
+
+	var letter models.GeneratedLetter
+	result = h.db.Where("id = ? AND visitor_id = ?", letterID, visitor.ID).First(&letter)
+	if result.Error != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Lettre non trouvée",
+			"code":  "LETTER_NOT_FOUND",
+		})
+	}
 
-	c.Set("Content-Type", "text/plain")
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	var buf bytes.Buffer
+	if err := services.ExportLetter(services.LetterResponseFromModel(&letter), format, &buf); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Échec de l'export",
+			"code":    "EXPORT_FAILED",
+			"details": err.Error(),
+		})
+	}
 
-	return c.SendString(letter.Content)
+	h.publishDownloaded(c, &letter, string(format))
+	h.markDownloaded(&letter)
+
+	c.Set("Content-Type", format.ContentType())
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFilename(&letter, format)))
+
+	return c.Send(buf.Bytes())
+}
+
+// unsafeFilenameChars caractères remplacés dans les noms de fichiers exportés
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// exportFilename nom du fichier exporté : lettre_<type>_<entreprise>.<ext>
+func exportFilename(letter *models.GeneratedLetter, format services.ExportFormat) string {
+	return letterFilename(letter, format.Extension())
+}
+
+// letterFilename nom de fichier d'une lettre avec l'extension donnée
+func letterFilename(letter *models.GeneratedLetter, extension string) string {
+	company := strings.Trim(unsafeFilenameChars.ReplaceAllString(letter.CompanyName, "_"), "_")
+	if company == "" {
+		company = "entreprise"
+	}
+	return fmt.Sprintf("lettre_%s_%s.%s", letter.LetterType, company, extension)
 }
 
 // GetAccessStatus récupère le status d'accès IA du visiteur
@@ -517,7 +1075,7 @@ func (h *LettersHandler) GetRateLimitStatus(c *fiber.Ctx) error {
 		fmt.Sscanf(dailyUsedStr, "%d", &dailyUsed)
 	}
 
-	dailyLimit := 5
+	dailyLimit := aiDailyGenerationLimit
 	dailyRemaining := dailyLimit - dailyUsed
 	if dailyRemaining < 0 {
 		dailyRemaining = 0
@@ -545,3 +1103,34 @@ func (h *LettersHandler) GetRateLimitStatus(c *fiber.Ctx) error {
 		CooldownRemaining: cooldownRemaining,
 	})
 }
+
+// toQualityFindings mappe les points du contrôle qualité vers le DTO
+func toQualityFindings(findings models.LetterFindings) []dto.LetterQualityFinding {
+	items := make([]dto.LetterQualityFinding, len(findings))
+	for i, finding := range findings {
+		items[i] = dto.LetterQualityFinding{
+			Check:    finding.Check,
+			Severity: string(finding.Severity),
+			Message:  finding.Message,
+		}
+	}
+	return items
+}
+
+const (
+	sseReadBlock    = 15 * time.Second // Attente max avant heartbeat
+	sseWriteTimeout = 30 * time.Second
+	sseMaxDuration  = 5 * time.Minute
+)
+
+// writeSSEEvent écrit un événement au format Server-Sent Events
+func writeSSEEvent(w *bufio.Writer, event services.LetterStreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if event.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
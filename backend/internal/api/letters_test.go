@@ -3,15 +3,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"maicivy/internal/api/dto"
+	"maicivy/internal/middleware"
+	"maicivy/internal/models"
 	"maicivy/internal/services"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,8 +30,13 @@ type MockLetterQueueService struct {
 	mock.Mock
 }
 
-func (m *MockLetterQueueService) EnqueueJob(visitorID, companyName, jobTitle, theme string) (string, error) {
-	args := m.Called(visitorID, companyName, jobTitle, theme)
+func (m *MockLetterQueueService) EnqueueJob(req services.LetterJobRequest) (string, error) {
+	args := m.Called(req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockLetterQueueService) EnqueueRevision(req services.LetterRevisionRequest) (string, error) {
+	args := m.Called(req)
 	return args.String(0), args.Error(1)
 }
 
@@ -39,8 +53,8 @@ func (m *MockLetterQueueService) UpdateJobStatus(jobID string, status services.J
 	return args.Error(0)
 }
 
-func (m *MockLetterQueueService) CompleteJob(jobID string, motivationID, antiMotivationID uuid.UUID) error {
-	args := m.Called(jobID, motivationID, antiMotivationID)
+func (m *MockLetterQueueService) CompleteJob(jobID string, letters map[models.LetterType]uuid.UUID) error {
+	args := m.Called(jobID, letters)
 	return args.Error(0)
 }
 
@@ -54,11 +68,29 @@ func (m *MockLetterQueueService) RetryJob(jobID string) error {
 	return args.Error(0)
 }
 
-func (m *MockLetterQueueService) PopJob() (string, error) {
-	args := m.Called()
+func (m *MockLetterQueueService) DeadLetterJob(jobID string, errorMsg string) error {
+	args := m.Called(jobID, errorMsg)
+	return args.Error(0)
+}
+
+func (m *MockLetterQueueService) ClaimJob(workerID string) (string, error) {
+	args := m.Called(workerID)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockLetterQueueService) AckJob(workerID, jobID string) error {
+	args := m.Called(workerID, jobID)
+	return args.Error(0)
+}
+
+func (m *MockLetterQueueService) CancelJob(jobID string) (*services.LetterJob, error) {
+	args := m.Called(jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LetterJob), args.Error(1)
+}
+
 func (m *MockLetterQueueService) GetQueueLength() (int64, error) {
 	args := m.Called()
 	return args.Get(0).(int64), args.Error(1)
@@ -89,7 +121,7 @@ func TestGenerateLetters_Success(t *testing.T) {
 	})
 
 	// Mock queue success
-	mockQueue.On("EnqueueJob", "test-session-123", "Google", "", "backend").
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "test-session-123", CompanyName: "Google", Theme: "backend"}).
 		Return("job-abc-123", nil)
 
 	// Request body
@@ -222,7 +254,7 @@ func TestGenerateLetters_QueueError(t *testing.T) {
 	})
 
 	// Mock queue error
-	mockQueue.On("EnqueueJob", "test-session", "Google", "", "backend").
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "test-session", CompanyName: "Google", Theme: "backend"}).
 		Return("", assert.AnError)
 
 	// Request
@@ -265,7 +297,7 @@ func TestGenerateLetters_WithJobTitle(t *testing.T) {
 	})
 
 	// Mock avec job title
-	mockQueue.On("EnqueueJob", "test-session", "Microsoft", "Senior Backend Engineer", "backend").
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "test-session", CompanyName: "Microsoft", JobTitle: "Senior Backend Engineer", Theme: "backend"}).
 		Return("job-xyz-456", nil)
 
 	// Request avec job_title
@@ -293,6 +325,233 @@ func TestGenerateLetters_WithJobTitle(t *testing.T) {
 	mockQueue.AssertExpectations(t)
 }
 
+// Test génération d'un sous-ensemble de types de lettres
+func TestGenerateLetters_LetterTypes(t *testing.T) {
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+
+	handler := &LettersHandler{
+		queueService: mockQueue,
+	}
+
+	app.Post("/api/v1/letters/generate", func(c *fiber.Ctx) error {
+		c.Locals("session_id", "test-session")
+		c.Locals("rate_limit_remaining", 3)
+		return handler.GenerateLetter(c)
+	})
+
+	// Doublons ignorés, ordre conservé
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{
+		VisitorID:   "test-session",
+		CompanyName: "Microsoft",
+		LetterTypes: []models.LetterType{models.LetterTypeLinkedIn, models.LetterTypeThankYouNote},
+	}).Return("job-types", nil)
+
+	post := func(types []string) int {
+		jsonBody, _ := json.Marshal(dto.GenerateLetterRequest{CompanyName: "Microsoft", LetterTypes: types})
+		req := httptest.NewRequest("POST", "/api/v1/letters/generate", bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 202, post([]string{"linkedin_message", "thank_you_note", "linkedin_message"}))
+	assert.Equal(t, 400, post([]string{"motivation", "haiku"}))
+	assert.Equal(t, 400, post([]string{""}))
+
+	mockQueue.AssertExpectations(t)
+}
+
+// Test du statut d'un job multi-types : IDs par type
+func TestGetJobStatus_Letters(t *testing.T) {
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+	handler := &LettersHandler{queueService: mockQueue}
+	app.Get("/api/v1/letters/jobs/:jobId", handler.GetJobStatus)
+
+	motivationID, pitchID := uuid.New(), uuid.New()
+	mockQueue.On("GetJobStatus", "job-done").Return(&services.LetterJob{
+		JobID:       "job-done",
+		Status:      services.JobStatusCompleted,
+		Progress:    100,
+		LetterTypes: []models.LetterType{models.LetterTypeMotivation, models.LetterTypeElevatorPitch},
+		Letters: map[models.LetterType]uuid.UUID{
+			models.LetterTypeMotivation:    motivationID,
+			models.LetterTypeElevatorPitch: pitchID,
+		},
+		LetterMotivationID: &motivationID,
+	}, nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/letters/jobs/job-done", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var result dto.LetterJobStatus
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	assert.Equal(t, []string{"motivation", "elevator_pitch"}, result.LetterTypes)
+	assert.Equal(t, pitchID.String(), result.Letters["elevator_pitch"])
+	assert.Equal(t, motivationID.String(), *result.LetterMotivationID)
+	assert.Nil(t, result.LetterAntiMotivationID)
+}
+
+// Test DELETE /api/v1/letters/job/:jobId - annulation réservée au visiteur du job
+func TestCancelJob(t *testing.T) {
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+	handler := &LettersHandler{queueService: mockQueue}
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("session_id", "visitor-1")
+		return c.Next()
+	})
+	app.Delete("/api/v1/letters/job/:jobId", handler.CancelJob)
+
+	mockQueue.On("GetJobStatus", "job-running").Return(&services.LetterJob{JobID: "job-running", VisitorID: "visitor-1", Status: services.JobStatusProcessing}, nil)
+	mockQueue.On("CancelJob", "job-running").Return(&services.LetterJob{JobID: "job-running", VisitorID: "visitor-1", Status: services.JobStatusCancelled}, nil)
+	mockQueue.On("GetJobStatus", "job-other").Return(&services.LetterJob{JobID: "job-other", VisitorID: "visitor-2", Status: services.JobStatusQueued}, nil)
+	mockQueue.On("GetJobStatus", "job-done").Return(&services.LetterJob{JobID: "job-done", VisitorID: "visitor-1", Status: services.JobStatusCompleted}, nil)
+	mockQueue.On("CancelJob", "job-done").Return(&services.LetterJob{JobID: "job-done", Status: services.JobStatusCompleted}, services.ErrJobFinished)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/v1/letters/job/job-running", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var result dto.LetterJobCancelResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, "cancelled", result.Status)
+
+	// Job d'un autre visiteur : introuvable
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/api/v1/letters/job/job-other", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+	mockQueue.AssertNotCalled(t, "CancelJob", "job-other")
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/api/v1/letters/job/job-done", nil))
+	assert.Equal(t, 409, resp.StatusCode)
+}
+
+// Test POST /api/v1/letters/generate - requêtes rejouées servies sans toucher au quota
+func TestGenerateLetters_Idempotency(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockQueue := new(MockLetterQueueService)
+
+	handler := &LettersHandler{redis: redisClient, queueService: mockQueue}
+	handler.SetIdempotencyService(services.NewLetterIdempotencyService(redisClient, mockQueue))
+
+	app := fiber.New()
+	app.Post("/api/v1/letters/generate", handler.DeduplicateGeneration, middleware.AIRateLimit(middleware.AIRateLimitConfig{
+		Redis:            redisClient,
+		MaxPerDay:        5,
+		CooldownDuration: 2 * time.Minute,
+	}), handler.GenerateLetter)
+
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google", Theme: "backend"}).Return("job-google", nil).Once()
+	mockQueue.On("GetJobStatus", "job-google").Return(&services.LetterJob{JobID: "job-google", VisitorID: "visitor-1", Status: services.JobStatusProcessing}, nil)
+
+	post := func(body dto.GenerateLetterRequest, idempotencyKey string) (*http.Response, dto.LetterGenerationResponse) {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/v1/letters/generate", bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "maicivy_session", Value: "visitor-1"})
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		var result dto.LetterGenerationResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp, result
+	}
+
+	resp, result := post(dto.GenerateLetterRequest{CompanyName: "Google", Theme: "backend"}, "key-1")
+	assert.Equal(t, 202, resp.StatusCode)
+	assert.Equal(t, "job-google", result.JobID)
+
+	// Retry client avec la même clé : malgré le cooldown, job d'origine
+	resp, result = post(dto.GenerateLetterRequest{CompanyName: "Google", Theme: "backend"}, "key-1")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, "job-google", result.JobID)
+	assert.Equal(t, "processing", result.Status)
+	assert.True(t, result.Replayed)
+	assert.Equal(t, 4, result.RateLimitRemaining)
+
+	resp, _ = post(dto.GenerateLetterRequest{CompanyName: "Meta", Theme: "backend"}, "key-1")
+	assert.Equal(t, 422, resp.StatusCode)
+
+	// Sans clé, une requête jamais vue reste soumise au cooldown
+	resp, _ = post(dto.GenerateLetterRequest{CompanyName: "google", Theme: "backend"}, "")
+	assert.Equal(t, 429, resp.StatusCode)
+
+	// Double clic sans clé : requête identique (casse et espaces ignorés) dédupliquée
+	mr.Del("ratelimit:ai:visitor-1:cooldown")
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "visitor-1", CompanyName: "google", Theme: "backend"}).Return("job-google", nil).Once()
+	resp, _ = post(dto.GenerateLetterRequest{CompanyName: "google", Theme: "backend"}, "")
+	assert.Equal(t, 202, resp.StatusCode)
+	resp, result = post(dto.GenerateLetterRequest{CompanyName: "Google ", Theme: "backend"}, "")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "job-google", result.JobID)
+
+	// Compteurs inchangés par les requêtes rejouées
+	daily, _ := redisClient.Get(context.Background(), "ratelimit:ai:visitor-1:daily").Int()
+	assert.Equal(t, 2, daily)
+	mockQueue.AssertExpectations(t)
+}
+
+// Test export : format et ID validés avant tout accès à la base
+func TestExportLetter_InvalidRequest(t *testing.T) {
+	app := fiber.New()
+	handler := &LettersHandler{}
+	app.Get("/api/v1/letters/:id/export", handler.ExportLetter)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/letters/"+uuid.NewString()+"/export?format=rtf", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, "INVALID_FORMAT", result["code"])
+	assert.Contains(t, result["details"], `"rtf"`)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/letters/not-a-uuid/export?format=md", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	// Format valide mais sans session
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/letters/"+uuid.NewString()+"/export?format=odt", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// Test PDF : moteur de rendu validé avant tout accès à la base
+func TestDownloadPDF_InvalidRenderer(t *testing.T) {
+	app := fiber.New()
+	handler := &LettersHandler{}
+	app.Get("/api/v1/letters/:id/pdf", handler.DownloadPDF)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/letters/"+uuid.NewString()+"/pdf?renderer=wkhtmltopdf", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, "INVALID_RENDERER", result["code"])
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/letters/"+uuid.NewString()+"/pdf?renderer=native", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestExportFilename(t *testing.T) {
+	letter := &models.GeneratedLetter{CompanyName: "Société Générale / Paris", LetterType: models.LetterTypeMotivation}
+	assert.Equal(t, "lettre_motivation_Soci_t_G_n_rale_Paris.docx", exportFilename(letter, services.ExportFormatDOCX))
+
+	letter.CompanyName = "***"
+	assert.Equal(t, "lettre_motivation_entreprise.md", exportFilename(letter, services.ExportFormatMarkdown))
+}
+
 // Test validation job_title trop court
 func TestGenerateLetters_JobTitleTooShort(t *testing.T) {
 	app := fiber.New()
@@ -341,7 +600,7 @@ func TestGenerateLetters_ValidThemes(t *testing.T) {
 
 	for _, theme := range validThemes {
 		t.Run(theme, func(t *testing.T) {
-			mockQueue.On("EnqueueJob", "test-session", "TestCorp", "", theme).
+			mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "test-session", CompanyName: "TestCorp", Theme: theme}).
 				Return("job-123", nil).Once()
 
 			reqBody := dto.GenerateLetterRequest{
@@ -363,6 +622,106 @@ func TestGenerateLetters_ValidThemes(t *testing.T) {
 	mockQueue.AssertExpectations(t)
 }
 
+// Test GET /api/v1/letters/job/:jobId/stream - job déjà terminé
+func TestStreamJob_CompletedJob(t *testing.T) {
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+	handler := &LettersHandler{queueService: mockQueue}
+
+	app.Get("/api/v1/letters/job/:jobId/stream", handler.StreamJob)
+
+	motivationID := uuid.New()
+	antiMotivationID := uuid.New()
+	mockQueue.On("GetJobStatus", "job-done").Return(&services.LetterJob{
+		JobID:                  "job-done",
+		Status:                 services.JobStatusCompleted,
+		Progress:               100,
+		LetterMotivationID:     &motivationID,
+		LetterAntiMotivationID: &antiMotivationID,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/letters/job/job-done/stream", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "event: completed")
+	assert.Contains(t, string(body), motivationID.String())
+	assert.Contains(t, string(body), antiMotivationID.String())
+
+	mockQueue.AssertExpectations(t)
+}
+
+// Test GET /api/v1/letters/job/:jobId/stream - rejoue les fragments jusqu'à la fin du job
+func TestStreamJob_ReplaysDeltas(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	streamService := services.NewLetterStreamService(redisClient)
+
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+	handler := &LettersHandler{queueService: mockQueue, streamService: streamService}
+
+	app.Get("/api/v1/letters/job/:jobId/stream", handler.StreamJob)
+
+	mockQueue.On("GetJobStatus", "job-live").Return(&services.LetterJob{
+		JobID:  "job-live",
+		Status: services.JobStatusProcessing,
+	}, nil)
+
+	ctx := context.Background()
+	streamService.Publish(ctx, "job-live", services.LetterStreamEvent{
+		Type:       services.StreamEventDelta,
+		LetterType: models.LetterTypeMotivation,
+		Delta:      "Madame, Monsieur,",
+	})
+	streamService.Publish(ctx, "job-live", services.LetterStreamEvent{
+		Type:       services.StreamEventDelta,
+		LetterType: models.LetterTypeAntiMotivation,
+		Delta:      "Chers futurs ex-collègues,",
+	})
+	streamService.Publish(ctx, "job-live", services.LetterStreamEvent{
+		Type:                   services.StreamEventCompleted,
+		LetterMotivationID:     "m-1",
+		LetterAntiMotivationID: "a-1",
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/letters/job/job-live/stream", nil)
+	resp, err := app.Test(req, 5000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	content := string(body)
+	assert.Equal(t, 2, strings.Count(content, "event: delta"))
+	assert.Contains(t, content, "Madame, Monsieur,")
+	assert.Contains(t, content, "Chers futurs ex-collègues,")
+	assert.Contains(t, content, "event: completed")
+	assert.True(t, strings.Index(content, "event: delta") < strings.Index(content, "event: completed"))
+}
+
+// Test GET /api/v1/letters/job/:jobId/stream - job inconnu
+func TestStreamJob_NotFound(t *testing.T) {
+	app := fiber.New()
+	mockQueue := new(MockLetterQueueService)
+	handler := &LettersHandler{queueService: mockQueue}
+
+	app.Get("/api/v1/letters/job/:jobId/stream", handler.StreamJob)
+
+	mockQueue.On("GetJobStatus", "unknown").Return(nil, assert.AnError)
+
+	req := httptest.NewRequest("GET", "/api/v1/letters/job/unknown/stream", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
 // Benchmark génération de lettre
 func BenchmarkGenerateLetters(b *testing.B) {
 	app := fiber.New()
@@ -376,7 +735,7 @@ func BenchmarkGenerateLetters(b *testing.B) {
 		return handler.GenerateLetter(c)
 	})
 
-	mockQueue.On("EnqueueJob", "bench-session", "BenchCorp", "", "backend").
+	mockQueue.On("EnqueueJob", services.LetterJobRequest{VisitorID: "bench-session", CompanyName: "BenchCorp", Theme: "backend"}).
 		Return("job-bench", nil)
 
 	reqBody := dto.GenerateLetterRequest{
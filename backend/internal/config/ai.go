@@ -3,18 +3,33 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Providers LLM supportés
+const (
+	ProviderClaude = "claude"
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local" // Endpoint compatible OpenAI (Ollama, llama.cpp server, vLLM)
+	ProviderFake   = "fake"  // Provider déterministe pour CI / développement
+)
+
 type AIConfig struct {
 	// Providers
 	AnthropicAPIKey string
 	OpenAIAPIKey    string
-	PrimaryProvider string // "claude" ou "openai"
+	PrimaryProvider string   // "claude", "openai", "local" ou "fake"
+	FallbackChain   []string // Providers tentés dans l'ordre après le primaire
+
+	// Endpoint compatible OpenAI (provider "local")
+	LocalBaseURL string
+	LocalAPIKey  string
 
 	// Models
 	ClaudeModel string
 	OpenAIModel string
+	LocalModel  string
 
 	// Rate Limiting
 	MaxRequestsPerMinute int
@@ -25,24 +40,171 @@ type AIConfig struct {
 	RetryBaseDelay time.Duration
 	RequestTimeout time.Duration
 
+	// Circuit breaker par provider : ouverture après N échecs consécutifs, pendant OpenDuration
+	CircuitFailureThreshold int
+	CircuitOpenDuration     time.Duration
+
 	// Cost Tracking
 	EnableCostTracking bool
+	Pricing            map[string]ModelPrice // Tarifs par modèle (ou par provider en repli)
+	Budgets            map[string]AIBudget   // Plafonds de dépense par provider
+
+	// Contrôle qualité des lettres : régénérations max d'une lettre rejetée
+	LetterMaxRegenerations int
 }
 
 func LoadAIConfig() *AIConfig {
 	return &AIConfig{
 		AnthropicAPIKey:      os.Getenv("ANTHROPIC_API_KEY"),
 		OpenAIAPIKey:         os.Getenv("OPENAI_API_KEY"),
-		PrimaryProvider:      getEnvOrDefault("AI_PRIMARY_PROVIDER", "claude"),
+		PrimaryProvider:      getEnvOrDefault("AI_PRIMARY_PROVIDER", ProviderClaude),
+		FallbackChain:        getEnvAsList("AI_FALLBACK_PROVIDERS"),
+		LocalBaseURL:         os.Getenv("AI_LOCAL_BASE_URL"),
+		LocalAPIKey:          os.Getenv("AI_LOCAL_API_KEY"),
 		ClaudeModel:          getEnvOrDefault("CLAUDE_MODEL", "claude-sonnet-4-20250514"),
 		OpenAIModel:          getEnvOrDefault("OPENAI_MODEL", "gpt-4o"),
+		LocalModel:           getEnvOrDefault("AI_LOCAL_MODEL", "llama3.1"),
 		MaxRequestsPerMinute: getEnvAsIntOrDefault("AI_MAX_REQUESTS_PER_MIN", 10),
 		MaxTokensPerRequest:  getEnvAsIntOrDefault("AI_MAX_TOKENS", 4000),
 		MaxRetries:           getEnvAsIntOrDefault("AI_MAX_RETRIES", 3),
 		RetryBaseDelay:       time.Second,
 		RequestTimeout:       30 * time.Second,
 		EnableCostTracking:   getEnvAsBool("AI_ENABLE_COST_TRACKING", true),
+		Pricing:              loadPricing(),
+		Budgets:              loadBudgets(),
+
+		CircuitFailureThreshold: getEnvAsIntOrDefault("AI_CIRCUIT_FAILURE_THRESHOLD", 5),
+		CircuitOpenDuration:     time.Duration(getEnvAsIntOrDefault("AI_CIRCUIT_OPEN_SECONDS", 30)) * time.Second,
+
+		LetterMaxRegenerations: getEnvAsIntOrDefault("AI_LETTER_MAX_REGENERATIONS", 2),
+	}
+}
+
+// ProviderChain retourne la liste ordonnée des providers à tenter
+// Sans AI_FALLBACK_PROVIDERS explicite, on conserve le comportement historique :
+// Claude puis OpenAI (OpenAI seul si primaire), aucun fallback pour local/fake.
+// Un provider primaire inconnu retombe sur Claude.
+func (c *AIConfig) ProviderChain() []string {
+	primary := strings.ToLower(strings.TrimSpace(c.PrimaryProvider))
+	switch primary {
+	case ProviderClaude, ProviderOpenAI, ProviderLocal, ProviderFake:
+	default:
+		primary = ProviderClaude
+	}
+
+	fallbacks := c.FallbackChain
+	if fallbacks == nil && primary == ProviderClaude {
+		fallbacks = []string{ProviderOpenAI}
+	}
+
+	chain := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, name := range fallbacks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		chain = append(chain, name)
+	}
+
+	return chain
+}
+
+// ModelPrice : tarif d'un modèle en USD par million de tokens
+type ModelPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// Cost calcule le coût d'un appel
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)/1_000_000*p.InputPerMTok + float64(outputTokens)/1_000_000*p.OutputPerMTok
+}
+
+// DefaultPricing : tarifs publics connus, surchargeables via AI_PRICING
+// Les entrées au nom d'un provider servent de repli pour un modèle absent de la table.
+func DefaultPricing() map[string]ModelPrice {
+	return map[string]ModelPrice{
+		ProviderClaude: {InputPerMTok: 3.0, OutputPerMTok: 15.0},
+		ProviderOpenAI: {InputPerMTok: 10.0, OutputPerMTok: 30.0},
+
+		"claude-sonnet-4-20250514": {InputPerMTok: 3.0, OutputPerMTok: 15.0},
+		"claude-3-5-sonnet-latest": {InputPerMTok: 3.0, OutputPerMTok: 15.0},
+		"claude-3-5-haiku-latest":  {InputPerMTok: 0.8, OutputPerMTok: 4.0},
+		"gpt-4o":                   {InputPerMTok: 2.5, OutputPerMTok: 10.0},
+		"gpt-4o-mini":              {InputPerMTok: 0.15, OutputPerMTok: 0.6},
+		"gpt-4-turbo":              {InputPerMTok: 10.0, OutputPerMTok: 30.0},
+	}
+}
+
+// PriceFor retourne le tarif d'un modèle (repli sur le tarif du provider)
+// local et fake sont gratuits sauf tarif explicite.
+func (c *AIConfig) PriceFor(provider, model string) ModelPrice {
+	pricing := c.Pricing
+	if pricing == nil {
+		pricing = DefaultPricing()
+	}
+	if price, ok := pricing[strings.ToLower(model)]; ok {
+		return price
+	}
+	return pricing[provider]
+}
+
+// AIBudget : plafonds de dépense (USD) d'un provider, 0 = illimité
+// Une fois un plafond atteint, le provider bascule sur DowngradeModel s'il est défini,
+// sinon il est refusé (et le provider suivant de la chaîne est tenté).
+type AIBudget struct {
+	Daily          float64
+	Monthly        float64
+	DowngradeModel string
+}
+
+// BudgetFor retourne le budget d'un provider (zéro = illimité)
+func (c *AIConfig) BudgetFor(provider string) AIBudget {
+	return c.Budgets[provider]
+}
+
+// loadPricing : tarifs par défaut complétés par AI_PRICING ("modele=entree/sortie,...")
+func loadPricing() map[string]ModelPrice {
+	pricing := DefaultPricing()
+	for model, value := range getEnvAsMap("AI_PRICING") {
+		input, output, ok := strings.Cut(value, "/")
+		if !ok {
+			continue
+		}
+		inputPrice, errIn := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		outputPrice, errOut := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		if errIn != nil || errOut != nil {
+			continue
+		}
+		pricing[model] = ModelPrice{InputPerMTok: inputPrice, OutputPerMTok: outputPrice}
+	}
+	return pricing
+}
+
+// loadBudgets : plafonds par provider ("claude=5,openai=3")
+func loadBudgets() map[string]AIBudget {
+	budgets := map[string]AIBudget{}
+	update := func(key string, apply func(b *AIBudget, value string)) {
+		for provider, value := range getEnvAsMap(key) {
+			budget := budgets[provider]
+			apply(&budget, value)
+			budgets[provider] = budget
+		}
 	}
+
+	update("AI_BUDGET_DAILY_USD", func(b *AIBudget, value string) {
+		b.Daily, _ = strconv.ParseFloat(value, 64)
+	})
+	update("AI_BUDGET_MONTHLY_USD", func(b *AIBudget, value string) {
+		b.Monthly, _ = strconv.ParseFloat(value, 64)
+	})
+	update("AI_BUDGET_DOWNGRADE_MODELS", func(b *AIBudget, value string) {
+		b.DowngradeModel = value
+	})
+
+	return budgets
 }
 
 type ScraperConfig struct {
@@ -51,6 +213,12 @@ type ScraperConfig struct {
 	UserAgent      string
 	Timeout        time.Duration
 	CacheTTL       time.Duration // 7 jours par défaut
+
+	// Réglages des sources d'informations entreprise (clé : nom de la source)
+	Sources map[string]CompanySourceSettings
+
+	// Règles de détection des technologies des sites entreprise (JSON façon Wappalyzer, vide = désactivée)
+	TechFingerprintsPath string
 }
 
 func LoadScraperConfig() *ScraperConfig {
@@ -60,9 +228,57 @@ func LoadScraperConfig() *ScraperConfig {
 		UserAgent:      "maicivy-bot/1.0 (+https://maicivy.example.com/bot)",
 		Timeout:        15 * time.Second,
 		CacheTTL:       7 * 24 * time.Hour, // 7 jours
+		Sources:        loadCompanySources(),
+
+		TechFingerprintsPath: getEnvOrDefault("TECH_FINGERPRINTS_FILE", "data/tech_fingerprints.json"),
 	}
 }
 
+// CompanySourceSettings : surcharge des réglages par défaut d'une source entreprise
+// Les valeurs nulles conservent le réglage de la source.
+type CompanySourceSettings struct {
+	Disabled   bool
+	Priority   int           // 1 = préférée à confiance égale
+	Timeout    time.Duration // Délai max de la source
+	Confidence float64       // Fiabilité de la source (0-1)
+}
+
+// SourceSettings retourne les surcharges d'une source (zéro = défauts)
+func (c *ScraperConfig) SourceSettings(name string) CompanySourceSettings {
+	return c.Sources[name]
+}
+
+// loadCompanySources : COMPANY_SOURCES_DISABLED ("news,duckduckgo") et réglages par source ("wikipedia=2,...")
+func loadCompanySources() map[string]CompanySourceSettings {
+	sources := map[string]CompanySourceSettings{}
+	update := func(key string, apply func(s *CompanySourceSettings, value string)) {
+		for name, value := range getEnvAsMap(key) {
+			settings := sources[name]
+			apply(&settings, value)
+			sources[name] = settings
+		}
+	}
+
+	for _, name := range getEnvAsList("COMPANY_SOURCES_DISABLED") {
+		name = strings.ToLower(name)
+		settings := sources[name]
+		settings.Disabled = true
+		sources[name] = settings
+	}
+	update("COMPANY_SOURCE_PRIORITIES", func(s *CompanySourceSettings, value string) {
+		s.Priority, _ = strconv.Atoi(value)
+	})
+	update("COMPANY_SOURCE_TIMEOUTS_SECONDS", func(s *CompanySourceSettings, value string) {
+		seconds, _ := strconv.Atoi(value)
+		s.Timeout = time.Duration(seconds) * time.Second
+	})
+	update("COMPANY_SOURCE_CONFIDENCE", func(s *CompanySourceSettings, value string) {
+		s.Confidence, _ = strconv.ParseFloat(value, 64)
+	})
+
+	return sources
+}
+
 // Helper functions
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -79,6 +295,37 @@ func getEnvAsIntOrDefault(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsList lit une liste séparée par des virgules (nil si absente)
+func getEnvAsList(key string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return nil
+	}
+
+	var values []string
+	for _, v := range strings.Split(valueStr, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap lit des paires "clé=valeur" séparées par des virgules
+// Les clés sont normalisées en minuscules, les paires invalides ignorées.
+func getEnvAsMap(key string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range getEnvAsList(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.ToLower(strings.TrimSpace(k)), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		values[k] = v
+	}
+	return values
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
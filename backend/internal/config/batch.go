@@ -0,0 +1,14 @@
+package config
+
+// LetterBatchConfig configuration des campagnes de génération de lettres
+type LetterBatchConfig struct {
+	OwnerDailyQuota int // Générations de campagne par jour, une par entreprise (quota du propriétaire)
+	MaxItems        int // Entreprises par campagne
+}
+
+func LoadLetterBatchConfig() *LetterBatchConfig {
+	return &LetterBatchConfig{
+		OwnerDailyQuota: getEnvAsIntOrDefault("LETTER_BATCH_DAILY_QUOTA", 100),
+		MaxItems:        getEnvAsIntOrDefault("LETTER_BATCH_MAX_ITEMS", 100),
+	}
+}
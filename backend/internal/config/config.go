@@ -35,6 +35,9 @@ type Config struct {
 	// API Keys (pour Phase 3)
 	ClaudeAPIKey string
 	OpenAIAPIKey string
+
+	// Administration (header X-Admin-Key), routes admin désactivées si vide
+	AdminAPIKey string
 }
 
 func Load() (*Config, error) {
@@ -67,6 +70,9 @@ func Load() (*Config, error) {
 		// API Keys
 		ClaudeAPIKey: getEnv("CLAUDE_API_KEY", ""),
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
+
+		// Administration
+		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
 	}
 
 	if err := cfg.Validate(); err != nil {
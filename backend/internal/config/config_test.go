@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -38,3 +39,75 @@ func TestGetEnv(t *testing.T) {
 		t.Errorf("Expected 'default', got '%s'", result)
 	}
 }
+
+func TestPriceFor(t *testing.T) {
+	cfg := &AIConfig{Pricing: DefaultPricing()}
+
+	if price := cfg.PriceFor(ProviderOpenAI, "gpt-4o-mini"); price.InputPerMTok != 0.15 {
+		t.Errorf("Expected gpt-4o-mini pricing, got %+v", price)
+	}
+	if price := cfg.PriceFor(ProviderClaude, "claude-unknown"); price.OutputPerMTok != 15.0 {
+		t.Errorf("Expected provider fallback pricing, got %+v", price)
+	}
+	if cost := cfg.PriceFor(ProviderLocal, "llama3.1").Cost(1000, 1000); cost != 0 {
+		t.Errorf("Expected free local model, got %f", cost)
+	}
+}
+
+func TestLoadBudgetsAndPricing(t *testing.T) {
+	os.Setenv("AI_BUDGET_DAILY_USD", "claude=5, openai=3")
+	os.Setenv("AI_BUDGET_MONTHLY_USD", "claude=50,invalid")
+	os.Setenv("AI_BUDGET_DOWNGRADE_MODELS", "claude=claude-3-5-haiku-latest")
+	os.Setenv("AI_PRICING", "My-Model=1.5/6,broken=1")
+	defer func() {
+		for _, key := range []string{"AI_BUDGET_DAILY_USD", "AI_BUDGET_MONTHLY_USD", "AI_BUDGET_DOWNGRADE_MODELS", "AI_PRICING"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	cfg := LoadAIConfig()
+
+	claude := cfg.BudgetFor(ProviderClaude)
+	if claude.Daily != 5 || claude.Monthly != 50 || claude.DowngradeModel != "claude-3-5-haiku-latest" {
+		t.Errorf("Unexpected claude budget: %+v", claude)
+	}
+	if openai := cfg.BudgetFor(ProviderOpenAI); openai.Daily != 3 || openai.Monthly != 0 {
+		t.Errorf("Unexpected openai budget: %+v", openai)
+	}
+	if price := cfg.PriceFor(ProviderLocal, "my-model"); price.InputPerMTok != 1.5 || price.OutputPerMTok != 6 {
+		t.Errorf("Expected AI_PRICING override, got %+v", price)
+	}
+	if _, ok := cfg.Pricing["broken"]; ok {
+		t.Error("Expected invalid pricing entry to be ignored")
+	}
+}
+
+func TestLoadCompanySources(t *testing.T) {
+	os.Setenv("COMPANY_SOURCES_DISABLED", "News, duckduckgo")
+	os.Setenv("COMPANY_SOURCE_PRIORITIES", "wikipedia=1")
+	os.Setenv("COMPANY_SOURCE_TIMEOUTS_SECONDS", "website=4")
+	os.Setenv("COMPANY_SOURCE_CONFIDENCE", "clearbit=0.95")
+	defer func() {
+		for _, key := range []string{"COMPANY_SOURCES_DISABLED", "COMPANY_SOURCE_PRIORITIES", "COMPANY_SOURCE_TIMEOUTS_SECONDS", "COMPANY_SOURCE_CONFIDENCE"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	cfg := LoadScraperConfig()
+
+	if !cfg.SourceSettings("news").Disabled || !cfg.SourceSettings("duckduckgo").Disabled {
+		t.Errorf("Expected news and duckduckgo to be disabled: %+v", cfg.Sources)
+	}
+	if settings := cfg.SourceSettings("wikipedia"); settings.Priority != 1 || settings.Disabled {
+		t.Errorf("Unexpected wikipedia settings: %+v", settings)
+	}
+	if timeout := cfg.SourceSettings("website").Timeout; timeout != 4*time.Second {
+		t.Errorf("Expected website timeout of 4s, got %s", timeout)
+	}
+	if confidence := cfg.SourceSettings("clearbit").Confidence; confidence != 0.95 {
+		t.Errorf("Expected clearbit confidence of 0.95, got %f", confidence)
+	}
+	if settings := cfg.SourceSettings("github"); settings != (CompanySourceSettings{}) {
+		t.Errorf("Expected no override for github, got %+v", settings)
+	}
+}
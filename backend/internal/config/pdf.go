@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// PDFConfig configuration du rendu PDF (navigateur headless partagé)
+type PDFConfig struct {
+	ChromePath string // Binaire Chrome/Chromium (détection automatique si vide)
+
+	MaxTabs       int           // Onglets de rendu simultanés
+	QueueSize     int           // Rendus en attente d'un onglet au-delà desquels on refuse (503)
+	QueueTimeout  time.Duration // Attente maximale d'un onglet libre
+	RenderTimeout time.Duration // Durée maximale d'un rendu
+
+	// Polices TrueType du rendu natif (sans navigateur) ; détection automatique si vides
+	FontRegular string
+	FontBold    string
+}
+
+func LoadPDFConfig() *PDFConfig {
+	return &PDFConfig{
+		ChromePath:    os.Getenv("CHROME_PATH"),
+		MaxTabs:       getEnvAsIntOrDefault("PDF_MAX_TABS", 4),
+		QueueSize:     getEnvAsIntOrDefault("PDF_QUEUE_SIZE", 16),
+		QueueTimeout:  time.Duration(getEnvAsIntOrDefault("PDF_QUEUE_TIMEOUT_SECONDS", 10)) * time.Second,
+		RenderTimeout: time.Duration(getEnvAsIntOrDefault("PDF_RENDER_TIMEOUT_SECONDS", 30)) * time.Second,
+		FontRegular:   os.Getenv("PDF_FONT_REGULAR"),
+		FontBold:      os.Getenv("PDF_FONT_BOLD"),
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// WebhookConfig configuration de la livraison des webhooks
+type WebhookConfig struct {
+	MaxAttempts  int           // Tentatives par livraison avant abandon
+	RetryBackoff time.Duration // Délai avant la 2e tentative (doublé à chaque échec)
+	Timeout      time.Duration // Délai de réponse d'un abonné
+	PollInterval time.Duration // Fréquence de recherche des livraisons à (re)tenter
+}
+
+func LoadWebhookConfig() *WebhookConfig {
+	return &WebhookConfig{
+		MaxAttempts:  getEnvAsIntOrDefault("WEBHOOK_MAX_ATTEMPTS", 8),
+		RetryBackoff: time.Duration(getEnvAsIntOrDefault("WEBHOOK_RETRY_BACKOFF_SECONDS", 30)) * time.Second,
+		Timeout:      time.Duration(getEnvAsIntOrDefault("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+		PollInterval: time.Duration(getEnvAsIntOrDefault("WEBHOOK_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+	}
+}
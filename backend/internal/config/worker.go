@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// LetterWorkerConfig configuration du pool de workers de génération de lettres
+type LetterWorkerConfig struct {
+	Concurrency       int           // Jobs traités simultanément par instance
+	BatchConcurrency  int           // Jobs de campagne traités simultanément (en plus de Concurrency)
+	ShutdownTimeout   time.Duration // Délai laissé aux jobs en cours à l'arrêt (au-delà : remis en file)
+	VisibilityTimeout time.Duration // Délai sans heartbeat avant reprise des jobs d'un worker
+}
+
+func LoadLetterWorkerConfig() *LetterWorkerConfig {
+	return &LetterWorkerConfig{
+		Concurrency:       getEnvAsIntOrDefault("LETTER_WORKER_CONCURRENCY", 2),
+		BatchConcurrency:  getEnvAsIntOrDefault("LETTER_BATCH_CONCURRENCY", 1),
+		ShutdownTimeout:   time.Duration(getEnvAsIntOrDefault("LETTER_WORKER_SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
+		VisibilityTimeout: time.Duration(getEnvAsIntOrDefault("LETTER_JOB_VISIBILITY_TIMEOUT_SECONDS", 90)) * time.Second,
+	}
+}
@@ -54,8 +54,19 @@ func RunAutoMigrations(db *gorm.DB) error {
 	}{
 		{&models.Skill{}, "skills"},
 		{&models.Visitor{}, "visitors"},
+		{&models.JobPosting{}, "job_postings"},
 		{&models.GeneratedLetter{}, "generated_letters"},
+		{&models.LetterVersion{}, "letter_versions"},
+		{&models.LetterJobRecord{}, "letter_jobs"},
+		{&models.LetterBatch{}, "letter_batches"},
+		{&models.LetterBatchItem{}, "letter_batch_items"},
+		{&models.WebhookSubscription{}, "webhook_subscriptions"},
+		{&models.WebhookDelivery{}, "webhook_deliveries"},
+		{&models.Company{}, "companies"},
+		{&models.CompanyAlias{}, "company_aliases"},
+		{&models.CompanyOpening{}, "company_openings"},
 		{&models.AnalyticsEvent{}, "analytics_events"},
+		{&models.AIUsage{}, "ai_usage"},
 		{&models.GitHubProfile{}, "github_profiles"},
 		{&models.GitHubRepository{}, "github_repositories"},
 	}
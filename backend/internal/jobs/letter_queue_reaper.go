@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/services"
+)
+
+// letterQueueCleanupInterval fréquence du nettoyage des jobs expirés
+const letterQueueCleanupInterval = 1 * time.Hour
+
+// LetterQueueReaperJob reprend les jobs des workers arrêtés et nettoie la queue de lettres
+// Les workers de toutes les instances partagent la queue : chaque instance peut exécuter
+// ce job, un job n'est repris qu'une fois (déplacement atomique).
+type LetterQueueReaperJob struct {
+	queue *services.LetterQueueService
+}
+
+// NewLetterQueueReaperJob crée le job de reprise de la queue
+func NewLetterQueueReaperJob(queue *services.LetterQueueService) *LetterQueueReaperJob {
+	return &LetterQueueReaperJob{queue: queue}
+}
+
+// Run reprend les jobs des workers sans heartbeat
+func (j *LetterQueueReaperJob) Run(ctx context.Context) error {
+	reaped, err := j.queue.ReapStalledJobs()
+	if err != nil {
+		return err
+	}
+	if reaped > 0 {
+		log.Warn().Int("jobs", reaped).Msg("Reaped letter jobs from unresponsive workers")
+	}
+	return nil
+}
+
+// Start exécute la reprise toutes les demi-périodes de visibilité, et le nettoyage toutes les heures
+func (j *LetterQueueReaperJob) Start(ctx context.Context) {
+	interval := j.queue.VisibilityTimeout() / 2
+	log.Info().
+		Dur("interval", interval).
+		Msg("Starting letter queue reaper")
+
+	reapTicker := time.NewTicker(interval)
+	defer reapTicker.Stop()
+	cleanupTicker := time.NewTicker(letterQueueCleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-reapTicker.C:
+			if err := j.Run(ctx); err != nil {
+				log.Error().Err(err).Msg("Letter queue reaper failed")
+			}
+
+		case <-cleanupTicker.C:
+			if err := j.queue.CleanupOldJobs(); err != nil {
+				log.Error().Err(err).Msg("Letter queue cleanup failed")
+			}
+
+		case <-ctx.Done():
+			log.Info().Msg("Letter queue reaper stopped (context cancelled)")
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/services"
+)
+
+// webhookCleanupInterval fréquence de la purge du journal des livraisons
+const webhookCleanupInterval = 24 * time.Hour
+
+// WebhookDispatcherJob envoie les livraisons webhook en attente et retente les échecs
+// Réveillé dès qu'un événement est publié, et à intervalle régulier pour les tentatives planifiées.
+type WebhookDispatcherJob struct {
+	webhooks *services.WebhookService
+	interval time.Duration
+}
+
+// NewWebhookDispatcherJob crée le job de livraison des webhooks
+func NewWebhookDispatcherJob(webhooks *services.WebhookService, interval time.Duration) *WebhookDispatcherJob {
+	return &WebhookDispatcherJob{webhooks: webhooks, interval: interval}
+}
+
+// Run envoie les livraisons dues
+func (j *WebhookDispatcherJob) Run(ctx context.Context) error {
+	delivered, err := j.webhooks.DeliverDue(ctx)
+	if err != nil {
+		return err
+	}
+	if delivered > 0 {
+		log.Debug().Int("deliveries", delivered).Msg("Webhooks delivered")
+	}
+	return nil
+}
+
+// Start exécute les livraisons à chaque publication et toutes les intervalles, la purge chaque jour
+func (j *WebhookDispatcherJob) Start(ctx context.Context) {
+	log.Info().
+		Dur("interval", j.interval).
+		Msg("Starting webhook dispatcher")
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	cleanupTicker := time.NewTicker(webhookCleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-j.webhooks.Wake():
+
+		case <-cleanupTicker.C:
+			deleted, err := j.webhooks.CleanupDeliveries(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Webhook delivery cleanup failed")
+			} else if deleted > 0 {
+				log.Info().Int64("deleted", deleted).Msg("Deleted old webhook deliveries")
+			}
+			continue
+
+		case <-ctx.Done():
+			log.Info().Msg("Webhook dispatcher stopped (context cancelled)")
+			return
+		}
+
+		if err := j.Run(ctx); err != nil {
+			log.Error().Err(err).Msg("Webhook dispatcher failed")
+		}
+	}
+}
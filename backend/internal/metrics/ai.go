@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AI provider metrics
+var (
+	// AICircuitState tracks the circuit breaker state per provider (0=closed, 1=half_open, 2=open)
+	AICircuitState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ai_circuit_breaker_state",
+			Help: "AI provider circuit breaker state (0=closed, 1=half_open, 2=open)",
+		},
+		[]string{"provider"},
+	)
+
+	// AIProviderErrorsTotal counts AI provider errors by kind
+	AIProviderErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_provider_errors_total",
+			Help: "Total AI provider errors by kind (rate_limited, unavailable, timeout, auth, ...)",
+		},
+		[]string{"provider", "kind"},
+	)
+)
+
+// SetAICircuitState records the circuit breaker state of a provider
+func SetAICircuitState(provider string, state float64) {
+	AICircuitState.WithLabelValues(provider).Set(state)
+}
+
+// IncrementAIProviderError counts a provider error
+func IncrementAIProviderError(provider, kind string) {
+	AIProviderErrorsTotal.WithLabelValues(provider, kind).Inc()
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// PDF renderer metrics
+var (
+	// PDFRenderDuration tracks PDF render latencies (waiting for a tab excluded)
+	// renderer is "browser" (headless Chrome) or "native" (pure-Go layout engine)
+	PDFRenderDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pdf_render_duration_seconds",
+			Help:    "PDF render duration in seconds",
+			Buckets: []float64{0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0},
+		},
+		[]string{"renderer", "document", "status"},
+	)
+
+	// PDFRenderQueueWait tracks the time spent waiting for a free tab
+	PDFRenderQueueWait = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "pdf_render_queue_wait_seconds",
+			Help:    "Time spent waiting for a free browser tab",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1.0, 2.5, 5.0, 10.0},
+		},
+	)
+
+	// PDFRenderRejectedTotal counts renders refused because the renderer is overloaded
+	PDFRenderRejectedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "pdf_render_rejected_total",
+			Help: "Total PDF renders rejected (queue full or wait timeout)",
+		},
+	)
+
+	// PDFRenderInFlight tracks renders in progress and renders waiting for a tab
+	PDFRenderInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pdf_render_in_flight",
+			Help: "PDF renders in progress (state=active) or waiting for a tab (state=queued)",
+		},
+		[]string{"state"},
+	)
+
+	// PDFBrowserStartsTotal counts browser (re)starts
+	PDFBrowserStartsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pdf_browser_starts_total",
+			Help: "Total headless browser starts (reason=initial or crash)",
+		},
+		[]string{"reason"},
+	)
+)
+
+// ObservePDFRender records the duration of a PDF render
+func ObservePDFRender(renderer, document, status string, duration float64) {
+	PDFRenderDuration.WithLabelValues(renderer, document, status).Observe(duration)
+}
+
+// ObservePDFQueueWait records the time spent waiting for a tab
+func ObservePDFQueueWait(duration float64) {
+	PDFRenderQueueWait.Observe(duration)
+}
+
+// IncrementPDFRejected counts a rejected render
+func IncrementPDFRejected() {
+	PDFRenderRejectedTotal.Inc()
+}
+
+// SetPDFInFlight records the number of active and queued renders
+func SetPDFInFlight(active, queued int) {
+	PDFRenderInFlight.WithLabelValues("active").Set(float64(active))
+	PDFRenderInFlight.WithLabelValues("queued").Set(float64(queued))
+}
+
+// IncrementPDFBrowserStart counts a browser start
+func IncrementPDFBrowserStart(reason string) {
+	PDFBrowserStartsTotal.WithLabelValues(reason).Inc()
+}
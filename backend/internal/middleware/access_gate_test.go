@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -15,7 +16,7 @@ import (
 	"maicivy/internal/models"
 )
 
-func setupTestDB() *gorm.DB {
+func setupAccessGateDB() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	db.AutoMigrate(&models.Visitor{})
 	return db
@@ -31,7 +32,7 @@ func TestAccessGate_InsufficientVisits(t *testing.T) {
 	})
 
 	// Setup DB
-	db := setupTestDB()
+	db := setupAccessGateDB()
 
 	// Créer visiteur avec 2 visites
 	visitor := models.Visitor{
@@ -70,7 +71,7 @@ func TestAccessGate_SufficientVisits(t *testing.T) {
 		Addr: mr.Addr(),
 	})
 
-	db := setupTestDB()
+	db := setupAccessGateDB()
 
 	// Créer visiteur avec 3 visites
 	visitor := models.Visitor{
@@ -107,7 +108,7 @@ func TestAccessGate_ProfileBypass(t *testing.T) {
 		Addr: mr.Addr(),
 	})
 
-	db := setupTestDB()
+	db := setupAccessGateDB()
 
 	// Créer visiteur avec 0 visites mais profil recruiter
 	visitor := models.Visitor{
@@ -131,7 +132,7 @@ func TestAccessGate_ProfileBypass(t *testing.T) {
 	})
 
 	// Simuler profil dans Redis
-	redisClient.Set(mr.Ctx(), "visitor:recruiter-session:profile", "recruiter", 0)
+	redisClient.Set(context.Background(), "visitor:recruiter-session:profile", "recruiter", 0)
 
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.AddCookie(&http.Cookie{Name: "maicivy_session", Value: "recruiter-session"})
@@ -149,7 +150,7 @@ func TestAccessGate_NoSession(t *testing.T) {
 		Addr: mr.Addr(),
 	})
 
-	db := setupTestDB()
+	db := setupAccessGateDB()
 
 	app := fiber.New()
 
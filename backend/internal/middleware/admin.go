@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHeader header portant la clé d'administration
+const AdminHeader = "X-Admin-Key"
+
+// AdminAuth protège les routes d'administration par une clé partagée (ADMIN_API_KEY)
+// Sans clé configurée, les routes sont désactivées plutôt qu'ouvertes.
+func AdminAuth(apiKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if apiKey == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Administration désactivée (ADMIN_API_KEY non configurée)",
+				"code":  "ADMIN_DISABLED",
+			})
+		}
+
+		provided := c.Get(AdminHeader)
+		if provided == "" {
+			provided = strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Clé d'administration invalide",
+				"code":  "ADMIN_UNAUTHORIZED",
+			})
+		}
+
+		return c.Next()
+	}
+}
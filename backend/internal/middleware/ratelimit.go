@@ -122,9 +122,6 @@ func (rlm *RateLimitMiddleware) AI() fiber.Handler {
 			})
 		}
 
-		// NOTE: On n'incrémente PAS ici - c'est fait dans le handler APRÈS succès
-		// via IncrementAIRateLimit()
-
 		// Stocker remaining pour le handler
 		c.Locals("rate_limit_remaining", int(AIGenerationsLimit-count))
 
@@ -132,6 +129,27 @@ func (rlm *RateLimitMiddleware) AI() fiber.Handler {
 		c.Set("X-RateLimit-AI-Limit", strconv.Itoa(AIGenerationsLimit))
 		c.Set("X-RateLimit-AI-Remaining", strconv.Itoa(int(AIGenerationsLimit-count)))
 
-		return c.Next()
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		// 3. Compter la génération une fois le handler réussi (une erreur ne consomme pas de quota)
+		if c.Response().StatusCode() < fiber.StatusBadRequest {
+			rlm.recordAIGeneration(ctx, dailyKey, cooldownKey)
+		}
+		return nil
+	}
+}
+
+// recordAIGeneration incrémente le compteur journalier et démarre le cooldown
+func (rlm *RateLimitMiddleware) recordAIGeneration(ctx context.Context, dailyKey, cooldownKey string) {
+	count, err := rlm.redis.Incr(ctx, dailyKey).Result()
+	if err != nil {
+		log.Error().Err(err).Msg("Redis incr failed for AI daily limit")
+		return
+	}
+	if count == 1 {
+		rlm.redis.Expire(ctx, dailyKey, AIGenerationsWindow)
 	}
+	rlm.redis.Set(ctx, cooldownKey, 1, AICooldown)
 }
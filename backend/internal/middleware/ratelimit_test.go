@@ -5,15 +5,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRateLimit_AI_DailyLimit(t *testing.T) {
 	// Setup
-	redisClient := setupTestRedis(t)
-	rlm := NewRateLimit(redisClient)
+	mr := miniredis.RunT(t)
+	rlm := NewRateLimit(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -32,8 +34,8 @@ func TestRateLimit_AI_DailyLimit(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 200, resp.StatusCode)
 
-		// Wait for cooldown
-		time.Sleep(2 * time.Minute)
+		// Fin du cooldown (horloge miniredis)
+		mr.FastForward(2 * time.Minute)
 	}
 
 	// Test: 6ème génération bloquée
@@ -45,8 +47,8 @@ func TestRateLimit_AI_DailyLimit(t *testing.T) {
 
 func TestRateLimit_AI_Cooldown(t *testing.T) {
 	// Setup
-	redisClient := setupTestRedis(t)
-	rlm := NewRateLimit(redisClient)
+	mr := miniredis.RunT(t)
+	rlm := NewRateLimit(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -69,7 +71,7 @@ func TestRateLimit_AI_Cooldown(t *testing.T) {
 	assert.Equal(t, 429, resp2.StatusCode)
 
 	// Après 2min, ok
-	time.Sleep(2 * time.Minute)
+	mr.FastForward(2 * time.Minute)
 	req3 := httptest.NewRequest("POST", "/generate", nil)
 	resp3, _ := app.Test(req3)
 	assert.Equal(t, 200, resp3.StatusCode)
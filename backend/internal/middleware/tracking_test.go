@@ -8,9 +8,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
-	"maicivy/internal/database"
-	"maicivy/internal/models"
+	"github.com/valyala/fasthttp"
 )
 
 func TestTrackingMiddleware_NewVisitor(t *testing.T) {
@@ -77,7 +75,7 @@ func TestDetectProfile_LinkedIn(t *testing.T) {
 	tm := &TrackingMiddleware{}
 
 	app := fiber.New()
-	c := app.AcquireCtx(&fiber.Ctx{})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
 	defer app.ReleaseCtx(c)
 
 	c.Request().Header.Set("User-Agent", "LinkedInBot/1.0")
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/models"
+)
+
+// minVisitsForAI nombre de visites donnant accès aux fonctionnalités IA
+const minVisitsForAI = 3
+
+// VisitorStore stockage du compteur de visites et du profil (Redis)
+type VisitorStore interface {
+	Incr(key string) (int64, error)
+	Get(key string) (string, error)
+	Set(key string, value interface{}, expiration int) error
+}
+
+// redisVisitorStore adapte un client Redis à VisitorStore (expiration en secondes)
+type redisVisitorStore struct {
+	client *redis.Client
+}
+
+// NewRedisVisitorStore crée un VisitorStore adossé à Redis
+func NewRedisVisitorStore(client *redis.Client) VisitorStore {
+	return &redisVisitorStore{client: client}
+}
+
+func (s *redisVisitorStore) Incr(key string) (int64, error) {
+	ctx := context.Background()
+	count, err := s.client.Incr(ctx, key).Result()
+	if err == nil && count == 1 {
+		s.client.Expire(ctx, key, SessionTTL)
+	}
+	return count, err
+}
+
+func (s *redisVisitorStore) Get(key string) (string, error) {
+	value, err := s.client.Get(context.Background(), key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return value, err
+}
+
+func (s *redisVisitorStore) Set(key string, value interface{}, expiration int) error {
+	return s.client.Set(context.Background(), key, value, time.Duration(expiration)*time.Second).Err()
+}
+
+// VisitorTracking suivi léger des visiteurs (Redis uniquement, sans PostgreSQL)
+// Compte les visites par session, retient le profil détecté et expose l'accès IA
+// (3 visites ou profil cible) aux handlers via c.Locals.
+type VisitorTracking struct {
+	store     VisitorStore
+	rateLimit int64 // Visites maximales par session (0 = illimité)
+}
+
+// NewVisitorTracking crée le middleware de suivi des visiteurs
+func NewVisitorTracking(store VisitorStore) *VisitorTracking {
+	return &VisitorTracking{store: store}
+}
+
+// WithRateLimit limite le nombre de requêtes par session
+func (vt *VisitorTracking) WithRateLimit(limit int64) *VisitorTracking {
+	vt.rateLimit = limit
+	return vt
+}
+
+// HashIP hash l'IP pour respecter RGPD/privacy
+func (vt *VisitorTracking) HashIP(ip string) string {
+	return hashIP(ip)
+}
+
+// Track middleware Fiber : compteur de visites, profil et accès IA
+func (vt *VisitorTracking) Track(c *fiber.Ctx) error {
+	// Déjà suivi par ce middleware (enregistré plusieurs fois)
+	if c.Locals("visit_count") != nil {
+		return c.Next()
+	}
+
+	// 1. Récupérer ou créer session ID
+	sessionID := c.Cookies(SessionCookieName)
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+		c.Cookie(&fiber.Cookie{
+			Name:     SessionCookieName,
+			Value:    sessionID,
+			Expires:  time.Now().Add(SessionTTL),
+			HTTPOnly: true,
+			Secure:   true,
+			SameSite: "Lax",
+		})
+	}
+
+	// 2. Compteur de visites
+	visitCount, err := vt.store.Incr(fmt.Sprintf("%s%s:count", VisitorKeyPrefix, sessionID))
+	if err != nil {
+		log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to increment visit count")
+		visitCount = 1
+	}
+
+	if vt.rateLimit > 0 && visitCount > vt.rateLimit {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many requests for this session",
+			"code":  "VISITOR_RATE_LIMITED",
+		})
+	}
+
+	// 3. Profil : détecté sur cette requête, sinon celui retenu lors d'une visite précédente
+	profileKey := fmt.Sprintf("%s%s:profile", VisitorKeyPrefix, sessionID)
+	profile := vt.detectProfile(c)
+	switch {
+	case profile != models.ProfileTypeUnknown:
+		vt.storeProfile(profileKey, profile)
+	case visitCount == 1:
+		vt.storeProfile(profileKey, models.ProfileTypeUnknown)
+	case visitCount < minVisitsForAI:
+		// Le profil ne change l'accès qu'avant le seuil de visites
+		stored, err := vt.store.Get(profileKey)
+		if err != nil {
+			log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to get visitor profile")
+		} else if stored != "" {
+			profile = models.ProfileType(stored)
+		}
+	}
+
+	visitor := models.Visitor{VisitCount: int(visitCount), ProfileDetected: profile}
+
+	c.Locals("session_id", sessionID)
+	c.Locals("visit_count", int(visitCount))
+	c.Locals("visitor_profile", string(profile))
+	c.Locals("has_ai_access", visitor.HasAccessToAI())
+
+	return c.Next()
+}
+
+func (vt *VisitorTracking) storeProfile(key string, profile models.ProfileType) {
+	if err := vt.store.Set(key, string(profile), int(SessionTTL.Seconds())); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to store visitor profile")
+	}
+}
+
+// detectProfile profil fourni par ProfileEnrichmentMiddleware, sinon déduit du User-Agent / Referer
+func (vt *VisitorTracking) detectProfile(c *fiber.Ctx) models.ProfileType {
+	if detected := GetDetectedProfile(c); detected != nil && detected.ProfileType != "" {
+		return models.ProfileType(detected.ProfileType)
+	}
+
+	source := strings.ToLower(c.Get("User-Agent") + " " + c.Get("Referer"))
+	for _, pattern := range []string{"linkedin", "recruiter", "talent", "hiring"} {
+		if strings.Contains(source, pattern) {
+			return models.ProfileTypeRecruiter
+		}
+	}
+	return models.ProfileTypeUnknown
+}
@@ -4,6 +4,7 @@ import "time"
 
 // CompanyInfo : informations sur l'entreprise cible
 type CompanyInfo struct {
+	CompanyID    string   `json:"company_id,omitempty"` // Entreprise canonique (models.Company)
 	Name         string   `json:"name"`
 	Domain       string   `json:"domain"`
 	Description  string   `json:"description"`
@@ -13,13 +14,43 @@ type CompanyInfo struct {
 	Culture      string   `json:"culture,omitempty"`
 	Values       []string `json:"values,omitempty"`
 	RecentNews   string   `json:"recent_news,omitempty"`
+
+	OpenSourceProjects string `json:"open_source_projects,omitempty"` // Dépôts publics populaires (GitHub)
+
+	// Technologies détectées sur le site (empreintes), dont sont tirées les Technologies
+	TechStack []DetectedTechnology `json:"tech_stack,omitempty"`
+
+	// Origine de chaque champ renseigné, indexée par nom JSON du champ ("description", "industry"...)
+	Provenance map[string]FieldProvenance `json:"provenance,omitempty"`
+}
+
+// DetectedTechnology : technologie identifiée sur le site de l'entreprise
+type DetectedTechnology struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories,omitempty"` // "JavaScript frameworks", "Web servers"...
+	Version    string   `json:"version,omitempty"`
+	Confidence float64  `json:"confidence"`         // 0-1, cumul des indices (plafonné à 1)
+	Evidence   []string `json:"evidence,omitempty"` // Indices relevés ("script: /static/react.min.js")
+}
+
+// FieldProvenance : origine d'une information sur l'entreprise
+type FieldProvenance struct {
+	Source     string    `json:"source"`
+	URL        string    `json:"url,omitempty"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	Confidence float64   `json:"confidence"` // 0 (repli) à 1 (source de référence)
 }
 
 // LetterRequest : requête de génération de lettre
 type LetterRequest struct {
-	CompanyName string      `json:"company_name" validate:"required,min=2"`
-	LetterType  LetterType  `json:"letter_type" validate:"required,oneof=motivation anti_motivation"`
-	UserProfile UserProfile `json:"user_profile,omitempty"`
+	CompanyName   string      `json:"company_name" validate:"required,min=2"`
+	CompanyDomain string      `json:"-"` // Domaine choisi par le visiteur (vide = domaine connu ou deviné)
+	LetterType    LetterType  `json:"letter_type" validate:"required,oneof=motivation anti_motivation"`
+	JobTitle      string      `json:"job_title,omitempty"` // Poste visé (vide = candidature spontanée)
+	Theme         string      `json:"theme,omitempty"`     // Thème CV pour prioriser le parcours
+	JobPosting    *JobPosting `json:"job_posting,omitempty"`
+	Language      Language    `json:"language,omitempty"` // Langue de rédaction (fr par défaut)
+	UserProfile   UserProfile `json:"user_profile,omitempty"`
 }
 
 // ExperienceDetail : détail d'une expérience professionnelle pour les prompts
@@ -50,11 +81,17 @@ type UserProfile struct {
 type LetterResponse struct {
 	Content       string      `json:"content"`
 	Type          LetterType  `json:"type"`
+	Language      Language    `json:"language"`
 	CompanyInfo   CompanyInfo `json:"company_info"`
 	GeneratedAt   time.Time   `json:"generated_at"`
 	Provider      string      `json:"provider"` // "claude" ou "openai"
 	TokensUsed    int         `json:"tokens_used"`
 	EstimatedCost float64     `json:"estimated_cost"`
+
+	// Contrôle qualité de la version retenue
+	QualityScore    int            `json:"quality_score"`
+	QualityFindings LetterFindings `json:"quality_findings,omitempty"`
+	Regenerations   int            `json:"regenerations"`
 }
 
 // AIMetrics : métriques de coût et usage
@@ -68,4 +105,5 @@ type AIMetrics struct {
 	ResponseTimeMs int64
 	Success        bool
 	ErrorMessage   string
+	LetterType     string // Type de lettre à l'origine de l'appel (vide hors lettres)
 }
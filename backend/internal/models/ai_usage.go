@@ -0,0 +1,41 @@
+package models
+
+// AIUsage représente un appel à un provider IA (registre des dépenses)
+// Chaque AIMetrics enregistré produit une ligne, y compris les appels en échec.
+type AIUsage struct {
+	BaseModel
+
+	Provider   string `gorm:"type:varchar(20);not null;index" json:"provider"`
+	Model      string `gorm:"type:varchar(100);not null" json:"model"`
+	LetterType string `gorm:"type:varchar(50);index" json:"letter_type,omitempty"`
+
+	TokensInput  int     `gorm:"default:0" json:"tokens_input"`
+	TokensOutput int     `gorm:"default:0" json:"tokens_output"`
+	TotalTokens  int     `gorm:"default:0" json:"total_tokens"`
+	Cost         float64 `gorm:"type:numeric(12,6);default:0" json:"cost"` // USD
+
+	ResponseTimeMs int64  `gorm:"default:0" json:"response_time_ms"`
+	Success        bool   `gorm:"default:true" json:"success"`
+	ErrorMessage   string `gorm:"type:text" json:"error_message,omitempty"`
+}
+
+// TableName override le nom de table par défaut
+func (AIUsage) TableName() string {
+	return "ai_usage"
+}
+
+// NewAIUsage crée une ligne du registre à partir des métriques d'un appel
+func NewAIUsage(metrics AIMetrics) *AIUsage {
+	return &AIUsage{
+		Provider:       metrics.Provider,
+		Model:          metrics.Model,
+		LetterType:     metrics.LetterType,
+		TokensInput:    metrics.TokensInput,
+		TokensOutput:   metrics.TokensOutput,
+		TotalTokens:    metrics.TotalTokens,
+		Cost:           metrics.EstimatedCost,
+		ResponseTimeMs: metrics.ResponseTimeMs,
+		Success:        metrics.Success,
+		ErrorMessage:   metrics.ErrorMessage,
+	}
+}
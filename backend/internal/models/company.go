@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Company entreprise canonique : cible des lettres, clé du cache des données scrapées
+// Créée par la résolution des noms, ou curée par l'administrateur : les champs renseignés
+// d'une fiche curée priment alors sur les données scrapées (CompanyInfo).
+type Company struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
+	Curated         bool           `gorm:"not null;default:false" json:"curated"`
+	Domain          string         `gorm:"type:varchar(255)" json:"domain,omitempty"`
+	DomainConfirmed bool           `gorm:"not null;default:false" json:"domain_confirmed"` // Choisi par l'utilisateur ou l'administrateur
+	Description     string         `gorm:"type:text" json:"description,omitempty"`
+	Industry        string         `gorm:"type:varchar(255)" json:"industry,omitempty"`
+	Size            string         `gorm:"type:varchar(100)" json:"size,omitempty"`
+	Technologies    pq.StringArray `gorm:"type:text[]" json:"technologies"` // Stack technique
+	Values          pq.StringArray `gorm:"type:text[]" json:"values"`
+	Culture         string         `gorm:"type:text" json:"culture,omitempty"`
+	Notes           string         `gorm:"type:text" json:"notes,omitempty"` // Notes internes, jamais transmises au LLM
+	Aliases         pq.StringArray `gorm:"type:text[]" json:"aliases"`       // Autres noms ("BNP" pour "BNP Paribas")
+
+	OpeningsCrawledAt *time.Time `json:"openings_crawled_at,omitempty"` // Dernier parcours de la page carrières
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName override le nom de table par défaut
+func (Company) TableName() string {
+	return "companies"
+}
+
+// CompanyAlias clé de recherche normalisée (nom ou alias) d'une fiche entreprise
+// Reconstruite à chaque enregistrement de la fiche ; la clé primaire garantit l'unicité.
+type CompanyAlias struct {
+	Alias     string    `gorm:"type:varchar(255);primaryKey" json:"alias"`
+	CompanyID uuid.UUID `gorm:"type:uuid;not null;index" json:"company_id"`
+}
+
+// TableName override le nom de table par défaut
+func (CompanyAlias) TableName() string {
+	return "company_aliases"
+}
+
+// Sources d'une offre ouverte
+const (
+	OpeningSourceJSONLD     = "json_ld"               // Données structurées schema.org JobPosting
+	OpeningSourceLever      = "lever"                 // API publique Lever
+	OpeningSourceGreenhouse = "greenhouse"            // API publique Greenhouse
+	OpeningSourceWTTJ       = "welcome_to_the_jungle" // Liens vers les offres Welcome to the Jungle
+)
+
+// CompanyOpening offre ouverte trouvée sur le site carrières d'une entreprise
+// Remplacées à chaque parcours : une offre absente du dernier parcours est supprimée.
+type CompanyOpening struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CompanyID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_company_openings_company_url" json:"company_id"`
+	Title          string     `gorm:"type:varchar(255);not null" json:"title"`
+	Location       string     `gorm:"type:varchar(255)" json:"location,omitempty"`
+	URL            string     `gorm:"type:varchar(2000);not null;uniqueIndex:idx_company_openings_company_url" json:"url"` // À passer en job_posting_url
+	Source         string     `gorm:"type:varchar(50);not null" json:"source"`
+	Department     string     `gorm:"type:varchar(255)" json:"department,omitempty"`
+	EmploymentType string     `gorm:"type:varchar(100)" json:"employment_type,omitempty"`
+	PostedAt       *time.Time `json:"posted_at,omitempty"`
+
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null" json:"last_seen_at"`
+}
+
+// TableName override le nom de table par défaut
+func (CompanyOpening) TableName() string {
+	return "company_openings"
+}
@@ -73,6 +73,9 @@ type Experience struct {
 	Tags         pq.StringArray `gorm:"type:text[]" json:"tags"`
 	Category     string         `gorm:"type:varchar(100);index" json:"category" validate:"required,oneof=backend frontend fullstack devops data ai mobile other"`
 
+	// Translations of the text fields (title, description, catchphrase...) by language
+	Translations TranslationsJSON `gorm:"type:jsonb" json:"translations,omitempty"`
+
 	// Metadata
 	Featured bool `gorm:"default:false" json:"featured"` // For highlighting
 }
@@ -87,6 +90,19 @@ func (e *Experience) IsCurrentJob() bool {
 	return e.EndDate == nil
 }
 
+// Localize retourne une copie de l'expérience dans la langue demandée
+// Les champs non traduits conservent la valeur source ; la copie ne porte plus les traductions.
+func (e Experience) Localize(lang Language) Experience {
+	t := e.Translations
+	e.Title = t.Get(lang, "title", e.Title)
+	e.Description = t.Get(lang, "description", e.Description)
+	e.Catchphrase = t.Get(lang, "catchphrase", e.Catchphrase)
+	e.FunctionalDescription = t.Get(lang, "functionalDescription", e.FunctionalDescription)
+	e.TechnicalDescription = t.Get(lang, "technicalDescription", e.TechnicalDescription)
+	e.Translations = nil
+	return e
+}
+
 // Duration calcule la durée de l'expérience
 func (e *Experience) Duration() time.Duration {
 	endDate := time.Now()
@@ -10,6 +10,10 @@ type LetterType string
 const (
 	LetterTypeMotivation     LetterType = "motivation"
 	LetterTypeAntiMotivation LetterType = "anti_motivation"
+	LetterTypeFollowUpEmail  LetterType = "follow_up_email"  // E-mail de relance après candidature
+	LetterTypeThankYouNote   LetterType = "thank_you_note"   // Remerciement après entretien
+	LetterTypeLinkedIn       LetterType = "linkedin_message" // Message d'approche LinkedIn
+	LetterTypeElevatorPitch  LetterType = "elevator_pitch"   // Présentation orale de 30 secondes
 )
 
 // GeneratedLetter représente une lettre générée par IA
@@ -21,9 +25,11 @@ type GeneratedLetter struct {
 	Visitor   *Visitor  `gorm:"foreignKey:VisitorID" json:"visitor,omitempty"`
 
 	// Informations lettre
+	CompanyID   *uuid.UUID `gorm:"type:uuid;index" json:"company_id,omitempty"` // Entreprise canonique (résolution des noms)
 	CompanyName string     `gorm:"type:varchar(255);not null" json:"company_name" validate:"required,min=2,max=255"`
-	LetterType  LetterType `gorm:"type:varchar(20);not null" json:"letter_type" validate:"required,oneof=motivation anti_motivation"`
+	LetterType  LetterType `gorm:"type:varchar(20);not null" json:"letter_type" validate:"required,oneof=motivation anti_motivation follow_up_email thank_you_note linkedin_message elevator_pitch"`
 	Content     string     `gorm:"type:text;not null" json:"content" validate:"required"`
+	Language    Language   `gorm:"type:varchar(5);not null;default:'fr'" json:"language"`
 
 	// Métadonnées génération
 	AIModel      string `gorm:"type:varchar(50)" json:"ai_model"`       // "claude-3" ou "gpt-4"
@@ -31,6 +37,18 @@ type GeneratedLetter struct {
 	GenerationMS int    `gorm:"default:0" json:"generation_ms"`         // Temps de génération en ms
 	CompanyInfo  string `gorm:"type:jsonb" json:"company_info"`         // Données entreprise scrapées (JSON)
 
+	// Offre d'emploi ciblée (optionnel)
+	JobPostingID *uuid.UUID  `gorm:"type:uuid;index" json:"job_posting_id,omitempty"`
+	JobPosting   *JobPosting `gorm:"foreignKey:JobPostingID" json:"job_posting,omitempty"`
+
+	// Contrôle qualité (score 0-100, points relevés et régénérations effectuées)
+	QualityScore    int            `gorm:"default:0" json:"quality_score"`
+	QualityFindings LetterFindings `gorm:"type:jsonb" json:"quality_findings,omitempty"`
+	Regenerations   int            `gorm:"default:0" json:"regenerations"`
+
+	// Version servie (Content reflète toujours cette version)
+	CurrentVersion int `gorm:"default:1" json:"current_version"`
+
 	// Flags
 	Downloaded bool `gorm:"default:false" json:"downloaded"` // Tracking PDF téléchargé
 }
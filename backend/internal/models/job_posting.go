@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/lib/pq"
+)
+
+// JobPostingSource origine d'une offre d'emploi
+type JobPostingSource string
+
+const (
+	JobPostingSourceURL  JobPostingSource = "url"  // Récupérée depuis une URL
+	JobPostingSourceText JobPostingSource = "text" // Collée par le visiteur
+)
+
+// JobPosting représente une offre d'emploi structurée utilisée pour cibler une lettre
+type JobPosting struct {
+	BaseModel
+
+	Source    JobPostingSource `gorm:"type:varchar(10);not null" json:"source"`
+	SourceURL string           `gorm:"type:varchar(2000)" json:"source_url,omitempty"`
+
+	// Informations extraites
+	Title       string `gorm:"type:varchar(255)" json:"title,omitempty"`
+	CompanyName string `gorm:"type:varchar(255)" json:"company_name,omitempty"`
+	RawText     string `gorm:"type:text" json:"raw_text"`
+
+	Requirements     pq.StringArray `gorm:"type:text[]" json:"requirements"`
+	Responsibilities pq.StringArray `gorm:"type:text[]" json:"responsibilities"`
+	TechKeywords     pq.StringArray `gorm:"type:text[]" json:"tech_keywords"`
+}
+
+// TableName override le nom de table par défaut
+func (JobPosting) TableName() string {
+	return "job_postings"
+}
+
+// IsEmpty indique si aucune information exploitable n'a été extraite
+func (jp *JobPosting) IsEmpty() bool {
+	return len(jp.Requirements) == 0 && len(jp.Responsibilities) == 0 && len(jp.TechKeywords) == 0
+}
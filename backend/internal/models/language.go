@@ -0,0 +1,90 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language code ISO 639-1 d'une langue supportée pour les lettres et le CV
+type Language string
+
+const (
+	LanguageFrench  Language = "fr"
+	LanguageEnglish Language = "en"
+	LanguageGerman  Language = "de"
+	LanguageSpanish Language = "es"
+
+	// DefaultLanguage langue source du contenu du CV
+	DefaultLanguage = LanguageFrench
+)
+
+// SupportedLanguages langues disponibles, langue par défaut en premier
+var SupportedLanguages = []Language{LanguageFrench, LanguageEnglish, LanguageGerman, LanguageSpanish}
+
+// ParseLanguage normalise un code de langue ("EN", "en-US", "de_CH"...)
+// Retourne false si la langue n'est pas supportée.
+func ParseLanguage(code string) (Language, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	for _, lang := range SupportedLanguages {
+		if Language(code) == lang {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// OrDefault retourne la langue si elle est supportée, la langue par défaut sinon
+func (l Language) OrDefault() Language {
+	if lang, ok := ParseLanguage(string(l)); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// NegotiateLanguage choisit la langue supportée préférée d'après un header Accept-Language
+// Ex: "de-CH,de;q=0.9,en;q=0.8" => de. Sans correspondance, retourne la langue par défaut.
+func NegotiateLanguage(acceptLanguage string) Language {
+	type candidate struct {
+		lang    Language
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		lang, ok := ParseLanguage(fields[0])
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				quality = q
+			}
+		}
+
+		if quality > 0 {
+			candidates = append(candidates, candidate{lang: lang, quality: quality})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLanguage
+	}
+
+	// Qualité décroissante, ordre du header en cas d'égalité
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected Language
+		ok       bool
+	}{
+		{"fr", LanguageFrench, true},
+		{"EN", LanguageEnglish, true},
+		{"de-CH", LanguageGerman, true},
+		{" es_MX ", LanguageSpanish, true},
+		{"it", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			lang, ok := ParseLanguage(tt.code)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, lang)
+		})
+	}
+
+	assert.Equal(t, LanguageFrench, Language("it").OrDefault())
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected Language
+	}{
+		{"Empty header", "", LanguageFrench},
+		{"First supported wins", "de-CH,de;q=0.9,en;q=0.8", LanguageGerman},
+		{"Quality ordering", "en;q=0.5,es;q=0.9", LanguageSpanish},
+		{"Unsupported languages skipped", "it-IT,pt;q=0.9,en;q=0.3", LanguageEnglish},
+		{"Zero quality excluded", "en;q=0,de;q=0.1", LanguageGerman},
+		{"Wildcard only", "*", LanguageFrench},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NegotiateLanguage(tt.header))
+		})
+	}
+}
+
+func TestExperience_Localize(t *testing.T) {
+	exp := Experience{
+		Title:       "Développeur Backend",
+		Company:     "Acme",
+		Description: "Conception d'APIs",
+		Catchphrase: "APIs à fort trafic",
+		Translations: TranslationsJSON{
+			LanguageEnglish: {"title": "Backend Developer", "description": "API design"},
+		},
+	}
+
+	en := exp.Localize(LanguageEnglish)
+	assert.Equal(t, "Backend Developer", en.Title)
+	assert.Equal(t, "API design", en.Description)
+	assert.Equal(t, "APIs à fort trafic", en.Catchphrase) // Non traduit : valeur source
+	assert.Equal(t, "Acme", en.Company)
+	assert.Nil(t, en.Translations)
+
+	// Langue sans traduction : contenu source
+	de := exp.Localize(LanguageGerman)
+	assert.Equal(t, "Développeur Backend", de.Title)
+
+	// L'original n'est pas modifié
+	assert.Equal(t, "Développeur Backend", exp.Title)
+	assert.NotNil(t, exp.Translations)
+}
+
+func TestTranslationsJSON_Scan(t *testing.T) {
+	var translations TranslationsJSON
+	err := translations.Scan([]byte(`{"es": {"name": "Gestión de proyectos"}}`))
+	assert.NoError(t, err)
+
+	skill := Skill{Name: "Gestion de projet", Translations: translations}
+	assert.Equal(t, "Gestión de proyectos", skill.Localize(LanguageSpanish).Name)
+	assert.Equal(t, "Gestion de projet", skill.Localize(LanguageEnglish).Name)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LetterBatch campagne de génération de lettres (liste d'entreprises cibles du propriétaire)
+// Chaque entreprise donne lieu à un job de la queue (file des campagnes) ; l'avancement
+// est lu sur les jobs.
+type LetterBatch struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(200)" json:"name,omitempty"`
+	Language    string    `gorm:"type:varchar(5)" json:"language,omitempty"`
+	LetterTypes string    `gorm:"type:varchar(200)" json:"letter_types,omitempty"` // Types séparés par des virgules (vide = défaut)
+	ItemCount   int       `gorm:"not null" json:"item_count"`
+
+	Items []LetterBatchItem `gorm:"foreignKey:BatchID" json:"items,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName override le nom de table par défaut
+func (LetterBatch) TableName() string {
+	return "letter_batches"
+}
+
+// LetterBatchItem entreprise cible d'une campagne
+type LetterBatchItem struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	BatchID       uuid.UUID `gorm:"type:uuid;not null;index" json:"batch_id"`
+	Position      int       `gorm:"not null" json:"position"` // Ligne d'origine (1 = première entreprise)
+	CompanyName   string    `gorm:"type:varchar(255);not null" json:"company_name"`
+	JobTitle      string    `gorm:"type:varchar(255)" json:"job_title,omitempty"`
+	Theme         string    `gorm:"type:varchar(50)" json:"theme,omitempty"`
+	JobPostingURL string    `gorm:"type:text" json:"job_posting_url,omitempty"`
+	JobID         string    `gorm:"type:uuid;index" json:"job_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName override le nom de table par défaut
+func (LetterBatchItem) TableName() string {
+	return "letter_batch_items"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// LetterJobRecord copie persistante d'un job de la queue de génération de lettres
+// Redis reste la source de vérité des jobs en cours ; la copie Postgres conserve
+// l'historique au-delà du TTL des clés et d'un redémarrage de Redis.
+type LetterJobRecord struct {
+	JobID       string `gorm:"type:uuid;primaryKey" json:"job_id"`
+	Kind        string `gorm:"type:varchar(20);not null" json:"kind"`
+	SessionID   string `gorm:"type:varchar(255);index" json:"session_id"`
+	CompanyName string `gorm:"type:varchar(255)" json:"company_name,omitempty"`
+	Status      string `gorm:"type:varchar(20);not null;index" json:"status"`
+	Progress    int    `gorm:"default:0" json:"progress"`
+	WorkerID    string `gorm:"type:varchar(255)" json:"worker_id,omitempty"`
+	Error       string `gorm:"type:text" json:"error,omitempty"`
+
+	RetryCount int  `gorm:"default:0" json:"retry_count"`
+	MaxRetries int  `gorm:"default:0" json:"max_retries"`
+	DeadLetter bool `gorm:"default:false" json:"dead_letter"` // Abandonné après épuisement des tentatives
+
+	Payload string `gorm:"type:jsonb;not null" json:"payload"` // Job complet (JSON)
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName override le nom de table par défaut
+func (LetterJobRecord) TableName() string {
+	return "letter_jobs"
+}
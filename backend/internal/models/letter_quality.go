@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// FindingSeverity gravité d'un point relevé par le contrôle qualité des lettres
+type FindingSeverity string
+
+const (
+	FindingError   FindingSeverity = "error"   // Lettre rejetée (régénération)
+	FindingWarning FindingSeverity = "warning" // Pénalise le score sans rejeter la lettre
+)
+
+// LetterFinding point relevé par un validateur sur une lettre générée
+type LetterFinding struct {
+	Check    string          `json:"check"` // Nom du validateur (ex: "word_count")
+	Severity FindingSeverity `json:"severity"`
+	Message  string          `json:"message"`
+}
+
+// LetterFindings liste des points relevés (JSONB)
+type LetterFindings []LetterFinding
+
+// Value implements driver.Valuer for database storage
+func (f LetterFindings) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner for database retrieval
+func (f *LetterFindings) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+// GormDataType returns the GORM data type for LetterFindings
+func (LetterFindings) GormDataType() string {
+	return "jsonb"
+}
+
+// HasErrors indique si au moins un point bloquant a été relevé
+func (f LetterFindings) HasErrors() bool {
+	for _, finding := range f {
+		if finding.Severity == FindingError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// LetterVersion représente une version du contenu d'une lettre générée
+// La version 1 est le texte initial, les suivantes sont des révisions à partir des retours du visiteur.
+type LetterVersion struct {
+	BaseModel
+
+	LetterID uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_letter_versions_letter_version" json:"letter_id"`
+	Letter   *GeneratedLetter `gorm:"foreignKey:LetterID" json:"-"`
+	Version  int              `gorm:"not null;uniqueIndex:idx_letter_versions_letter_version" json:"version"`
+
+	Content  string `gorm:"type:text;not null" json:"content"`
+	Feedback string `gorm:"type:text" json:"feedback,omitempty"` // Retours ayant produit la version (vide pour la v1)
+
+	// Métadonnées génération
+	AIModel      string `gorm:"type:varchar(50)" json:"ai_model"`
+	TokensUsed   int    `gorm:"default:0" json:"tokens_used"`
+	GenerationMS int    `gorm:"default:0" json:"generation_ms"`
+}
+
+// TableName override le nom de table par défaut
+func (LetterVersion) TableName() string {
+	return "letter_versions"
+}
@@ -39,6 +39,9 @@ type Project struct {
 	GithubForks    int    `gorm:"default:0" json:"githubForks"`
 	GithubLanguage string `gorm:"type:varchar(50)" json:"githubLanguage"`
 
+	// Translations of the text fields (title, description, catchphrase...) by language
+	Translations TranslationsJSON `gorm:"type:jsonb" json:"translations,omitempty"`
+
 	// Flags
 	Featured   bool `gorm:"default:false" json:"featured"`
 	InProgress bool `gorm:"default:false" json:"inProgress"`
@@ -49,6 +52,19 @@ func (Project) TableName() string {
 	return "projects"
 }
 
+// Localize retourne une copie du projet dans la langue demandée
+// Les champs non traduits conservent la valeur source ; la copie ne porte plus les traductions.
+func (p Project) Localize(lang Language) Project {
+	t := p.Translations
+	p.Title = t.Get(lang, "title", p.Title)
+	p.Description = t.Get(lang, "description", p.Description)
+	p.Catchphrase = t.Get(lang, "catchphrase", p.Catchphrase)
+	p.FunctionalDescription = t.Get(lang, "functionalDescription", p.FunctionalDescription)
+	p.TechnicalDescription = t.Get(lang, "technicalDescription", p.TechnicalDescription)
+	p.Translations = nil
+	return p
+}
+
 // HasGithub vérifie si le projet a un repo GitHub
 func (p *Project) HasGithub() bool {
 	return p.GithubURL != ""
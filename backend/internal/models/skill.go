@@ -29,6 +29,9 @@ type Skill struct {
 	Description string `gorm:"type:text" json:"description" validate:"max=500"`
 	Featured    bool   `gorm:"default:false" json:"featured"`
 	Icon        string `gorm:"type:varchar(100)" json:"icon"` // Icon name (ex: "golang", "react")
+
+	// Traductions (name, description) par langue
+	Translations TranslationsJSON `gorm:"type:jsonb" json:"translations,omitempty"`
 }
 
 // TableName override le nom de table par défaut
@@ -36,6 +39,16 @@ func (Skill) TableName() string {
 	return "skills"
 }
 
+// Localize retourne une copie de la compétence dans la langue demandée
+// Le nom n'est traduit que s'il est explicitement fourni (ex: "Gestion de projet").
+func (s Skill) Localize(lang Language) Skill {
+	t := s.Translations
+	s.Name = t.Get(lang, "name", s.Name)
+	s.Description = t.Get(lang, "description", s.Description)
+	s.Translations = nil
+	return s
+}
+
 // LevelScore retourne un score numérique pour le niveau
 func (s *Skill) LevelScore() int {
 	switch s.Level {
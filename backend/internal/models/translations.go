@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// TranslatedFields valeurs traduites d'un contenu, indexées par nom JSON du champ
+// Ex: {"title": "Backend Engineer", "description": "..."}
+type TranslatedFields map[string]string
+
+// TranslationsJSON traductions d'un contenu par langue (JSONB)
+// La langue source (DefaultLanguage) est portée par les colonnes elles-mêmes.
+type TranslationsJSON map[Language]TranslatedFields
+
+// Value implements driver.Valuer for database storage
+func (t TranslationsJSON) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner for database retrieval
+func (t *TranslationsJSON) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+// GormDataType returns the GORM data type for TranslationsJSON
+func (TranslationsJSON) GormDataType() string {
+	return "jsonb"
+}
+
+// Get retourne la traduction d'un champ, ou fallback si elle est absente ou vide
+func (t TranslationsJSON) Get(lang Language, field, fallback string) string {
+	if value := t[lang][field]; value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription abonnement d'une intégration (synchronisation ATS, bot...) aux événements
+// des jobs de lettres. Les livraisons sont signées (HMAC-SHA256) avec Secret.
+type WebhookSubscription struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	URL         string    `gorm:"type:text;not null" json:"url"`
+	Secret      string    `gorm:"type:varchar(100);not null" json:"-"`
+	Events      string    `gorm:"type:varchar(255)" json:"events"` // Événements séparés par des virgules (vide = tous)
+	Description string    `gorm:"type:varchar(255)" json:"description,omitempty"`
+	Active      bool      `gorm:"not null" json:"active"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName override le nom de table par défaut
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventList retourne les événements filtrés (vide = tous)
+func (s *WebhookSubscription) EventList() []string {
+	if s.Events == "" {
+		return nil
+	}
+	return strings.Split(s.Events, ",")
+}
+
+// Subscribes indique si l'abonnement reçoit l'événement
+func (s *WebhookSubscription) Subscribes(event string) bool {
+	events := s.EventList()
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus état d'une livraison
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // En attente d'une (nouvelle) tentative
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // Réponse 2xx reçue
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Tentatives épuisées ou abonnement désactivé
+)
+
+// WebhookDelivery livraison d'un événement à un abonnement (journal des tentatives)
+// Une re-livraison manuelle crée une nouvelle livraison du même événement (EventID).
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	SubscriptionID uuid.UUID             `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	EventID        uuid.UUID             `gorm:"type:uuid;not null;index" json:"event_id"`
+	Event          string                `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string                `gorm:"type:jsonb;not null" json:"payload"` // Corps signé envoyé tel quel
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts       int                   `gorm:"default:0" json:"attempts"`
+
+	// Dernière tentative
+	ResponseStatus int    `gorm:"default:0" json:"response_status,omitempty"`
+	ResponseBody   string `gorm:"type:text" json:"response_body,omitempty"` // Tronquée
+	LastError      string `gorm:"type:text" json:"last_error,omitempty"`
+
+	RedeliveryOf  *uuid.UUID `gorm:"type:uuid" json:"redelivery_of,omitempty"` // Livraison d'origine (re-livraison manuelle)
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName override le nom de table par défaut
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,516 @@
+// Package pdf génère des documents PDF en Go pur (sans navigateur headless)
+// Mise en page en flux : paragraphes avec retour à la ligne automatique,
+// sauts de page, filets et polices standard ou TrueType embarquées.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Font police utilisable dans un document (Helvetica, HelveticaBold ou TrueTypeFont)
+type Font interface {
+	Name() string
+	Width(text string, size float64) float64 // Largeur du texte en points
+}
+
+// Color couleur RVB
+type Color struct{ R, G, B uint8 }
+
+// Hex convertit une couleur "#3b82f6" (noir si invalide)
+func Hex(value string) Color {
+	v, err := strconv.ParseUint(strings.TrimPrefix(value, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(value, "#")) != 6 {
+		return Color{}
+	}
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%s %s %s", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// Align alignement horizontal d'un paragraphe
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+	AlignJustify // La dernière ligne de chaque paragraphe reste alignée à gauche
+)
+
+// Style mise en forme d'un paragraphe (tailles et espacements en points)
+type Style struct {
+	Font        Font
+	Size        float64
+	Color       Color
+	Align       Align
+	LineHeight  float64 // Multiple de Size (1.2 par défaut)
+	SpaceBefore float64
+	SpaceAfter  float64
+	Indent      float64 // Retrait à gauche
+}
+
+func (s Style) withDefaults() Style {
+	if s.Font == nil {
+		s.Font = Helvetica
+	}
+	if s.Size <= 0 {
+		s.Size = 11
+	}
+	if s.LineHeight <= 0 {
+		s.LineHeight = 1.2
+	}
+	return s
+}
+
+// PageSize dimensions d'une page en points (1/72 pouce)
+type PageSize struct{ Width, Height float64 }
+
+// A4 format A4 portrait
+var A4 = PageSize{Width: 595.28, Height: 841.89}
+
+// Document document PDF en cours de composition
+type Document struct {
+	size    PageSize
+	margin  float64
+	title   string
+	lang    string
+	created time.Time
+
+	pages []*bytes.Buffer // Flux de contenu des pages
+	y     float64         // Position verticale depuis le haut de la page courante
+
+	fonts []Font
+	runes map[Font]map[rune]struct{} // Caractères utilisés par police (TrueType)
+}
+
+// New crée un document vide avec des marges identiques sur les quatre côtés
+func New(size PageSize, margin float64) *Document {
+	return &Document{
+		size:    size,
+		margin:  margin,
+		created: time.Now(),
+		runes:   make(map[Font]map[rune]struct{}),
+	}
+}
+
+// SetInfo renseigne le titre et la langue (code ISO 639-1) du document
+func (d *Document) SetInfo(title, lang string) {
+	d.title = title
+	d.lang = lang
+}
+
+// PageCount nombre de pages composées
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// ContentWidth largeur utile entre les marges
+func (d *Document) ContentWidth() float64 {
+	return d.size.Width - 2*d.margin
+}
+
+// newPage commence une nouvelle page
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = d.margin
+}
+
+// page flux de la page courante (créée au premier contenu)
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.newPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// bottom limite basse de la zone de contenu
+func (d *Document) bottom() float64 {
+	return d.size.Height - d.margin
+}
+
+// EnsureSpace passe à la page suivante s'il reste moins de height points
+// (évite un titre de section isolé en bas de page)
+func (d *Document) EnsureSpace(height float64) {
+	d.page()
+	if d.y+height > d.bottom() {
+		d.newPage()
+	}
+}
+
+// Space ajoute un espace vertical (sans effet en haut de page)
+func (d *Document) Space(height float64) {
+	d.page()
+	if d.y == d.margin {
+		return
+	}
+	d.y += height
+	if d.y > d.bottom() {
+		d.newPage()
+	}
+}
+
+// Rule trace un filet horizontal sur toute la largeur utile
+func (d *Document) Rule(thickness float64, color Color) {
+	d.EnsureSpace(thickness)
+	y := d.size.Height - d.y - thickness/2
+	fmt.Fprintf(d.page(), "q %s RG %s w %s %s m %s %s l S Q\n",
+		color.operands(), num(thickness), num(d.margin), num(y), num(d.size.Width-d.margin), num(y))
+	d.y += thickness
+}
+
+// Paragraph compose un paragraphe ; "\n" force un retour à la ligne
+func (d *Document) Paragraph(text string, style Style) {
+	style = style.withDefaults()
+	d.Space(style.SpaceBefore)
+
+	width := d.ContentWidth() - style.Indent
+	lineHeight := style.Size * style.LineHeight
+
+	for _, line := range wrapText(text, style.Font, style.Size, width) {
+		d.EnsureSpace(lineHeight)
+		// Ligne de base : texte centré verticalement dans l'interligne
+		baseline := d.y + (lineHeight-style.Size)/2 + style.Size*0.8
+		d.drawLine(line, style, width, d.size.Height-baseline)
+		d.y += lineHeight
+	}
+
+	d.Space(style.SpaceAfter)
+}
+
+// textLine ligne composée : mots et indicateur de fin de paragraphe (non justifiée)
+type textLine struct {
+	words []string
+	last  bool
+}
+
+// wrapText découpe un texte en lignes tenant dans width (mots trop longs coupés)
+func wrapText(text string, font Font, size, width float64) []textLine {
+	var lines []textLine
+	space := font.Width(" ", size)
+
+	for _, hardLine := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		var (
+			current []string
+			used    float64
+		)
+		for _, word := range strings.Fields(hardLine) {
+			for _, part := range splitWord(word, font, size, width) {
+				w := font.Width(part, size)
+				if len(current) > 0 && used+space+w > width {
+					lines = append(lines, textLine{words: current})
+					current, used = nil, 0
+				}
+				if len(current) > 0 {
+					used += space
+				}
+				current = append(current, part)
+				used += w
+			}
+		}
+		lines = append(lines, textLine{words: current, last: true})
+	}
+	return lines
+}
+
+// splitWord coupe un mot plus large que la ligne
+func splitWord(word string, font Font, size, width float64) []string {
+	if font.Width(word, size) <= width {
+		return []string{word}
+	}
+
+	var (
+		parts   []string
+		current []rune
+	)
+	for _, r := range word {
+		if len(current) > 0 && font.Width(string(append(current, r)), size) > width {
+			parts = append(parts, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	return append(parts, string(current))
+}
+
+// drawLine écrit une ligne à la position verticale y (repère PDF)
+func (d *Document) drawLine(line textLine, style Style, width, y float64) {
+	if len(line.words) == 0 {
+		return
+	}
+	x := d.margin + style.Indent
+
+	if style.Align == AlignJustify && !line.last && len(line.words) > 1 {
+		total := 0.0
+		for _, word := range line.words {
+			total += style.Font.Width(word, style.Size)
+		}
+		gap := (width - total) / float64(len(line.words)-1)
+		for _, word := range line.words {
+			d.drawText(word, style, x, y)
+			x += style.Font.Width(word, style.Size) + gap
+		}
+		return
+	}
+
+	text := strings.Join(line.words, " ")
+	switch style.Align {
+	case AlignCenter:
+		x += (width - style.Font.Width(text, style.Size)) / 2
+	case AlignRight:
+		x += width - style.Font.Width(text, style.Size)
+	}
+	d.drawText(text, style, x, y)
+}
+
+// drawText écrit un texte à une position (repère PDF)
+func (d *Document) drawText(text string, style Style, x, y float64) {
+	fmt.Fprintf(d.page(), "BT %s %s Tf %s rg %s %s Td %s Tj ET\n",
+		d.fontResource(style.Font), num(style.Size), style.Color.operands(), num(x), num(y), d.encode(style.Font, text))
+}
+
+// fontResource nom de ressource (/F1, /F2...) d'une police
+func (d *Document) fontResource(font Font) string {
+	for i, f := range d.fonts {
+		if f == font {
+			return fmt.Sprintf("/F%d", i+1)
+		}
+	}
+	d.fonts = append(d.fonts, font)
+	return fmt.Sprintf("/F%d", len(d.fonts))
+}
+
+// encode opérande de Tj : chaîne WinAnsi ou identifiants de glyphes TrueType
+func (d *Document) encode(font Font, text string) string {
+	switch f := font.(type) {
+	case *standardFont:
+		return f.encode(text)
+	case *TrueTypeFont:
+		used := d.runes[font]
+		if used == nil {
+			used = make(map[rune]struct{})
+			d.runes[font] = used
+		}
+		var b strings.Builder
+		b.WriteByte('<')
+		for _, r := range text {
+			used[r] = struct{}{}
+			fmt.Fprintf(&b, "%04X", f.glyph(r))
+		}
+		b.WriteByte('>')
+		return b.String()
+	}
+	panic(fmt.Sprintf("pdf: unsupported font type %T", font))
+}
+
+// ============================================
+// Sérialisation
+// ============================================
+
+// objectWriter écrit les objets numérotés et retient leur position (table xref)
+type objectWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// alloc réserve un numéro d'objet
+func (o *objectWriter) alloc() int {
+	o.offsets = append(o.offsets, 0)
+	return len(o.offsets)
+}
+
+// object écrit un objet dictionnaire
+func (o *objectWriter) object(n int, body string) {
+	o.offsets[n-1] = o.buf.Len()
+	fmt.Fprintf(&o.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream écrit un flux compressé (FlateDecode)
+func (o *objectWriter) stream(n int, dict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if dict != "" {
+		dict += " "
+	}
+	o.offsets[n-1] = o.buf.Len()
+	fmt.Fprintf(&o.buf, "%d 0 obj\n<< %s/Filter /FlateDecode /Length %d >>\nstream\n", n, dict, compressed.Len())
+	o.buf.Write(compressed.Bytes())
+	o.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// WriteTo sérialise le document
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	d.page()
+
+	o := &objectWriter{}
+	o.buf.WriteString("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+
+	catalog, pages, info := o.alloc(), o.alloc(), o.alloc()
+
+	// Polices
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	for i, font := range d.fonts {
+		ref, err := d.writeFont(o, font)
+		if err != nil {
+			return 0, err
+		}
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, ref)
+	}
+	resources.WriteString(" >> >>")
+
+	// Pages
+	kids := make([]string, len(d.pages))
+	for i, content := range d.pages {
+		page, stream := o.alloc(), o.alloc()
+		o.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pages, num(d.size.Width), num(d.size.Height), resources.String(), stream))
+		if err := o.stream(stream, "", content.Bytes()); err != nil {
+			return 0, err
+		}
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	o.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+
+	catalogDict := fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R", pages)
+	if d.lang != "" {
+		catalogDict += " /Lang " + textString(d.lang)
+	}
+	o.object(catalog, catalogDict+" >>")
+
+	infoDict := fmt.Sprintf("<< /Producer (maicivy) /CreationDate (D:%s)", d.created.UTC().Format("20060102150405Z"))
+	if d.title != "" {
+		infoDict += " /Title " + textString(d.title)
+	}
+	o.object(info, infoDict+" >>")
+
+	// Table des références croisées
+	xref := o.buf.Len()
+	fmt.Fprintf(&o.buf, "xref\n0 %d\n0000000000 65535 f \n", len(o.offsets)+1)
+	for _, offset := range o.offsets {
+		fmt.Fprintf(&o.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&o.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(o.offsets)+1, catalog, info, xref)
+
+	n, err := w.Write(o.buf.Bytes())
+	return int64(n), err
+}
+
+// writeFont écrit les objets d'une police et retourne la référence du dictionnaire
+func (d *Document) writeFont(o *objectWriter, font Font) (int, error) {
+	switch f := font.(type) {
+	case *standardFont:
+		ref := o.alloc()
+		o.object(ref, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.name))
+		return ref, nil
+	case *TrueTypeFont:
+		return d.writeTrueType(o, f, d.runes[font])
+	}
+	return 0, fmt.Errorf("pdf: unsupported font type %T", font)
+}
+
+// writeTrueType police composite (Type0 / CIDFontType2) avec le fichier embarqué
+func (d *Document) writeTrueType(o *objectWriter, f *TrueTypeFont, used map[rune]struct{}) (int, error) {
+	type0, cidFont, descriptor, file, toUnicode := o.alloc(), o.alloc(), o.alloc(), o.alloc(), o.alloc()
+
+	// Glyphes utilisés, triés : largeurs (/W) et correspondance Unicode
+	glyphRunes := make(map[uint16]rune, len(used))
+	for r := range used {
+		if glyph := f.glyph(r); glyph != 0 {
+			if existing, ok := glyphRunes[glyph]; !ok || r < existing {
+				glyphRunes[glyph] = r
+			}
+		}
+	}
+	glyphs := make([]uint16, 0, len(glyphRunes))
+	for glyph := range glyphRunes {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	var widths strings.Builder
+	for _, glyph := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", glyph, f.scale(int(f.advances[glyph])))
+	}
+
+	o.object(type0, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cidFont, toUnicode))
+	o.object(cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %d /W [%s] /CIDToGIDMap /Identity >>",
+		f.name, descriptor, f.scale(int(f.advances[0])), strings.TrimSpace(widths.String())))
+	o.object(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.capHeight), file))
+
+	if err := o.stream(file, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return 0, err
+	}
+	if err := o.stream(toUnicode, "", toUnicodeCMap(glyphs, glyphRunes)); err != nil {
+		return 0, err
+	}
+	return type0, nil
+}
+
+// toUnicodeCMap table glyphe -> Unicode (copier-coller et recherche dans le PDF)
+func toUnicodeCMap(glyphs []uint16, glyphRunes map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// 100 entrées maximum par bloc beginbfchar
+	for start := 0; start < len(glyphs); start += 100 {
+		end := min(start+100, len(glyphs))
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, glyph := range glyphs[start:end] {
+			fmt.Fprintf(&b, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{glyphRunes[glyph]}) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// textString chaîne de texte PDF en UTF-16BE (métadonnées accentuées)
+func textString(value string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(value)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// num formate un nombre pour un flux de contenu (2 décimales au plus)
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, doc *Document) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	return buf.Bytes()
+}
+
+// contentStreams décompresse les flux de contenu des pages
+func contentStreams(t *testing.T, data []byte) []string {
+	t.Helper()
+	var streams []string
+	re := regexp.MustCompile(`(?s)<< /Filter /FlateDecode /Length (\d+) >>\nstream\n`)
+	for _, loc := range re.FindAllSubmatchIndex(data, -1) {
+		length, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		require.NoError(t, err)
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+length]))
+		require.NoError(t, err)
+		raw, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		streams = append(streams, string(raw))
+	}
+	return streams
+}
+
+func TestDocument_Structure(t *testing.T) {
+	doc := New(A4, 50)
+	doc.SetInfo("Lettre - Société", "fr")
+	doc.Paragraph("Bonjour", Style{Font: HelveticaBold, Size: 14})
+
+	data := render(t, doc)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.7\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), "/BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding")
+	assert.Contains(t, string(data), "/Lang <FEFF00660072>")
+
+	// startxref pointe sur la table, chaque entrée sur son objet
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, m)
+	xref, _ := strconv.Atoi(string(m[1]))
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "objet %d", i+1)
+	}
+}
+
+func TestDocument_EncodesAccents(t *testing.T) {
+	doc := New(A4, 50)
+	doc.Paragraph("Été (à) 5€", Style{})
+
+	streams := contentStreams(t, render(t, doc))
+	require.Len(t, streams, 1)
+	assert.Contains(t, streams[0], `(\311t\351 \(\340\) 5\200) Tj`)
+}
+
+func TestDocument_MultiPageFlow(t *testing.T) {
+	doc := New(A4, 50)
+	text := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 20)
+	for i := 0; i < 20; i++ {
+		doc.Paragraph(text, Style{Align: AlignJustify, SpaceAfter: 8})
+	}
+
+	assert.Greater(t, doc.PageCount(), 1)
+	data := render(t, doc)
+	assert.Contains(t, string(data), "/Count "+strconv.Itoa(doc.PageCount()))
+	assert.Len(t, contentStreams(t, data), doc.PageCount())
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("un deux trois\nquatre", Helvetica, 10, Helvetica.Width("un deux", 10))
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"un", "deux"}, lines[0].words)
+	assert.False(t, lines[0].last)
+	assert.Equal(t, []string{"trois"}, lines[1].words)
+	assert.True(t, lines[1].last)
+	assert.Equal(t, []string{"quatre"}, lines[2].words)
+
+	// Mot plus large que la ligne : coupé
+	width := Helvetica.Width("abcd", 10)
+	lines = wrapText("abcdefghij", Helvetica, 10, width)
+	require.Greater(t, len(lines), 1)
+	assert.Equal(t, "abcd", lines[0].words[0])
+	var joined string
+	for _, line := range lines {
+		require.Len(t, line.words, 1)
+		assert.LessOrEqual(t, Helvetica.Width(line.words[0], 10), width)
+		joined += line.words[0]
+	}
+	assert.Equal(t, "abcdefghij", joined)
+}
+
+func TestHex(t *testing.T) {
+	assert.Equal(t, Color{R: 0x3b, G: 0x82, B: 0xf6}, Hex("#3b82f6"))
+	assert.Equal(t, Color{}, Hex("bleu"))
+}
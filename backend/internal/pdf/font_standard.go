@@ -0,0 +1,117 @@
+package pdf
+
+import "strings"
+
+// standardFont police Type 1 standard (non embarquée) en encodage WinAnsi
+// Toujours disponible dans les lecteurs PDF, elle couvre les accents du français,
+// de l'allemand et de l'espagnol ; les autres caractères sont remplacés par "?".
+type standardFont struct {
+	name   string
+	widths *[224]int // Largeurs (1/1000 em) des codes 32 à 255
+}
+
+var (
+	// Helvetica police standard sans empattement
+	Helvetica Font = &standardFont{name: "Helvetica", widths: &helveticaWidths}
+
+	// HelveticaBold variante grasse de Helvetica
+	HelveticaBold Font = &standardFont{name: "Helvetica-Bold", widths: &helveticaBoldWidths}
+)
+
+func (f *standardFont) Name() string { return f.name }
+
+func (f *standardFont) Width(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		total += f.widths[winAnsiCode(r)-32]
+	}
+	return float64(total) * size / 1000
+}
+
+// encode chaîne littérale WinAnsi (octets non ASCII en octal)
+func (f *standardFont) encode(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		writeLiteralByte(&b, winAnsiCode(r))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// winAnsiCode code WinAnsi (cp1252) d'un caractère, "?" s'il n'est pas représentable
+func winAnsiCode(r rune) byte {
+	switch {
+	case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+		return byte(r)
+	case r == '\t':
+		return ' '
+	}
+	if code, ok := winAnsiExtra[r]; ok {
+		return code
+	}
+	return '?'
+}
+
+// winAnsiExtra caractères de la plage 0x80-0x9F de cp1252
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+	'\u202f': 0xA0, // Espace fine insécable (ponctuation française)
+	'\u2009': ' ',  // Espace fine
+	'\u2011': '-',  // Trait d'union insécable
+}
+
+// writeLiteralByte écrit un octet dans une chaîne littérale PDF
+func writeLiteralByte(b *strings.Builder, c byte) {
+	switch {
+	case c == '(' || c == ')' || c == '\\':
+		b.WriteByte('\\')
+		b.WriteByte(c)
+	case c < 0x20 || c > 0x7E:
+		b.WriteByte('\\')
+		b.WriteByte('0' + c>>6)
+		b.WriteByte('0' + (c>>3)&7)
+		b.WriteByte('0' + c&7)
+	default:
+		b.WriteByte(c)
+	}
+}
+
+// Métriques Adobe (AFM) de Helvetica, codes WinAnsi 32 à 255
+var helveticaWidths = [224]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // 32
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 48
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // 64
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // 80
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // 96
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, 350, // 112
+	556, 350, 222, 556, 333, 1000, 556, 556, 333, 1000, 667, 333, 1000, 350, 611, 350, // 128
+	350, 222, 222, 333, 333, 350, 556, 1000, 333, 1000, 500, 333, 944, 350, 500, 667, // 144
+	278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333, // 160
+	400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611, // 176
+	667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278, // 192
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611, // 208
+	556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278, // 224
+	556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500, // 240
+}
+
+// Métriques Adobe (AFM) de Helvetica-Bold, codes WinAnsi 32 à 255
+var helveticaBoldWidths = [224]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // 32
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611, // 48
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778, // 64
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556, // 80
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611, // 96
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584, 350, // 112
+	556, 350, 278, 556, 500, 1000, 556, 556, 333, 1000, 667, 333, 1000, 350, 611, 350, // 128
+	350, 278, 278, 500, 500, 350, 556, 1000, 333, 1000, 556, 333, 944, 350, 500, 667, // 144
+	278, 333, 556, 556, 556, 556, 280, 556, 333, 737, 370, 556, 584, 333, 737, 333, // 160
+	400, 584, 333, 333, 333, 611, 556, 278, 333, 333, 365, 556, 834, 834, 834, 611, // 176
+	722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278, // 192
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611, // 208
+	556, 556, 556, 556, 556, 556, 889, 556, 556, 556, 556, 556, 278, 278, 278, 278, // 224
+	611, 611, 611, 611, 611, 611, 611, 584, 611, 611, 611, 611, 611, 556, 611, 556, // 240
+}
@@ -0,0 +1,310 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// TrueTypeFont police TrueType embarquée dans le document (Unicode complet)
+// Le fichier est embarqué tel quel (FontFile2) ; le texte est encodé en identifiants
+// de glyphes (Identity-H) avec une table ToUnicode pour la copie et la recherche.
+type TrueTypeFont struct {
+	name       string
+	data       []byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	capHeight  int
+	advances   []uint16        // Avance horizontale par glyphe (unités de la police)
+	glyphs     map[rune]uint16 // Table cmap
+}
+
+// LoadTrueTypeFont charge une police TrueType (.ttf) depuis un fichier
+func LoadTrueTypeFont(path string) (*TrueTypeFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrueTypeFont(data)
+}
+
+// ParseTrueTypeFont lit les tables de métriques d'une police TrueType
+func ParseTrueTypeFont(data []byte) (*TrueTypeFont, error) {
+	tables, err := readTableDirectory(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("truetype: missing %s table", tag)
+		}
+	}
+
+	f := &TrueTypeFont{data: data}
+
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, errors.New("truetype: invalid head table")
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("truetype: invalid unitsPerEm")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, errors.New("truetype: invalid hhea table")
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	maxp := tables["maxp"]
+	if len(maxp) < 6 {
+		return nil, errors.New("truetype: invalid maxp table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+
+	hmtx := tables["hmtx"]
+	if numHMetrics == 0 || numHMetrics > numGlyphs || len(hmtx) < 4*numHMetrics {
+		return nil, errors.New("truetype: invalid hmtx table")
+	}
+	f.advances = make([]uint16, numGlyphs)
+	for i := range f.advances {
+		if i < numHMetrics {
+			f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+		} else {
+			f.advances[i] = f.advances[numHMetrics-1]
+		}
+	}
+
+	if f.glyphs, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+
+	f.capHeight = f.ascent
+	if os2 := tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		f.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+
+	f.name = postScriptName(tables["name"])
+	if f.name == "" {
+		f.name = "EmbeddedFont"
+	}
+
+	return f, nil
+}
+
+// readTableDirectory index des tables du fichier
+func readTableDirectory(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("truetype: file too short")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // TrueType ("\0\1\0\0" ou "true")
+	default:
+		return nil, errors.New("truetype: unsupported font format (TrueType outlines required)")
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, errors.New("truetype: truncated table directory")
+	}
+
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		record := data[12+16*i:]
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("truetype: table %q out of bounds", record[:4])
+		}
+		tables[string(record[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// parseCmap lit la table Unicode (format 12 de préférence, sinon format 4)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("truetype: invalid cmap table")
+	}
+
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables && 4+8*i+8 <= len(cmap); i++ {
+		record := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(record), binary.BigEndian.Uint16(record[2:])
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if offset+4 > len(cmap) {
+			continue
+		}
+		isUnicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !isUnicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseCmapFormat12(format12)
+	case format4 != nil:
+		return parseCmapFormat4(format4)
+	}
+	return nil, errors.New("truetype: no Unicode cmap subtable")
+}
+
+func parseCmapFormat4(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 14 {
+		return nil, errors.New("truetype: invalid cmap format 4")
+	}
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if len(sub) < idRangeOffsets+2*segCount {
+		return nil, errors.New("truetype: truncated cmap format 4")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(sub[endCodes+2*i:]))
+		start := int(binary.BigEndian.Uint16(sub[startCodes+2*i:]))
+		delta := binary.BigEndian.Uint16(sub[idDeltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(sub[idRangeOffsets+2*i:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph uint16
+			if rangeOffset == 0 {
+				glyph = uint16(c) + delta
+			} else {
+				pos := idRangeOffsets + 2*i + rangeOffset + 2*(c-start)
+				if pos+2 > len(sub) {
+					continue
+				}
+				if glyph = binary.BigEndian.Uint16(sub[pos:]); glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 {
+				glyphs[rune(c)] = glyph
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+func parseCmapFormat12(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 16 {
+		return nil, errors.New("truetype: invalid cmap format 12")
+	}
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	if len(sub) < 16+12*numGroups {
+		return nil, errors.New("truetype: truncated cmap format 12")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		group := sub[16+12*i:]
+		start, end := binary.BigEndian.Uint32(group), binary.BigEndian.Uint32(group[4:])
+		glyph := binary.BigEndian.Uint32(group[8:])
+		for c := start; c <= end && c <= 0x10FFFF; c++ {
+			glyphs[rune(c)] = uint16(glyph + c - start)
+		}
+	}
+	return glyphs, nil
+}
+
+// postScriptName nom PostScript (nameID 6) de la police
+func postScriptName(name []byte) string {
+	if len(name) < 6 {
+		return ""
+	}
+	count := int(binary.BigEndian.Uint16(name[2:]))
+	storage := int(binary.BigEndian.Uint16(name[4:]))
+
+	for i := 0; i < count && 6+12*i+12 <= len(name); i++ {
+		record := name[6+12*i:]
+		platform := binary.BigEndian.Uint16(record)
+		if binary.BigEndian.Uint16(record[6:]) != 6 {
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(record[8:]))
+		offset := storage + int(binary.BigEndian.Uint16(record[10:]))
+		if offset+length > len(name) {
+			continue
+		}
+		raw := name[offset : offset+length]
+
+		value := string(raw)
+		if platform == 0 || platform == 3 { // UTF-16BE
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			value = string(utf16.Decode(units))
+		}
+		return sanitizeFontName(value)
+	}
+	return ""
+}
+
+// sanitizeFontName nom utilisable comme nom PDF (/BaseFont)
+func sanitizeFontName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+func (f *TrueTypeFont) Name() string { return f.name }
+
+// HasGlyph indique si la police contient un caractère
+func (f *TrueTypeFont) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+func (f *TrueTypeFont) Width(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		total += int(f.advances[f.glyph(r)])
+	}
+	return float64(total) * size / float64(f.unitsPerEm)
+}
+
+// glyph identifiant du glyphe d'un caractère (0 = glyphe manquant)
+// Les espaces absents de la police (tabulation, espaces fines) prennent le glyphe de l'espace.
+func (f *TrueTypeFont) glyph(r rune) uint16 {
+	if glyph, ok := f.glyphs[r]; ok && int(glyph) < len(f.advances) {
+		return glyph
+	}
+	if unicode.IsSpace(r) && r != ' ' {
+		return f.glyph(' ')
+	}
+	return 0
+}
+
+// scale convertit des unités de la police en millièmes d'em (arrondi)
+func (f *TrueTypeFont) scale(units int) int {
+	return int(math.Round(float64(units) * 1000 / float64(f.unitsPerEm)))
+}
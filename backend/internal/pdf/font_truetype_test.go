@@ -0,0 +1,119 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestFont police TrueType minimale : .notdef, espace, "A" et "é" (2048 unités/em)
+func buildTestFont() []byte {
+	be := func(values ...any) []byte {
+		var b bytes.Buffer
+		for _, v := range values {
+			_ = binary.Write(&b, binary.BigEndian, v)
+		}
+		return b.Bytes()
+	}
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 2048)
+	copy(head[36:], be(int16(-100), int16(-400), int16(1800), int16(1900)))
+
+	hhea := make([]byte, 36)
+	copy(hhea[4:], be(int16(1600), int16(-400)))
+	binary.BigEndian.PutUint16(hhea[34:], 4)
+
+	maxp := be(uint32(0x00005000), uint16(4))
+	hmtx := be(uint16(1024), int16(0), uint16(512), int16(0), uint16(1366), int16(0), uint16(1140), int16(0))
+
+	// cmap format 4 : un segment par caractère + segment terminal 0xFFFF
+	codes := []uint16{0x20, 0x41, 0xE9, 0xFFFF}
+	glyphs := []uint16{1, 2, 3, 0}
+	segCount := len(codes)
+	var sub bytes.Buffer
+	sub.Write(be(uint16(4), uint16(16+8*segCount), uint16(0), uint16(2*segCount), uint16(0), uint16(0), uint16(0)))
+	for _, c := range codes {
+		sub.Write(be(c))
+	}
+	sub.Write(be(uint16(0)))
+	for _, c := range codes {
+		sub.Write(be(c))
+	}
+	for i, c := range codes {
+		delta := glyphs[i] - c
+		if c == 0xFFFF {
+			delta = 1
+		}
+		sub.Write(be(delta))
+	}
+	for range codes {
+		sub.Write(be(uint16(0)))
+	}
+	cmap := append(be(uint16(0), uint16(1), uint16(3), uint16(1), uint32(12)), sub.Bytes()...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}, {"maxp", maxp}}
+
+	var font bytes.Buffer
+	font.Write(be(uint32(0x00010000), uint16(len(tables)), uint16(0), uint16(0), uint16(0)))
+	offset := 12 + 16*len(tables)
+	for _, table := range tables {
+		font.WriteString(table.tag)
+		font.Write(be(uint32(0), uint32(offset), uint32(len(table.data))))
+		offset += len(table.data)
+	}
+	for _, table := range tables {
+		font.Write(table.data)
+	}
+	return font.Bytes()
+}
+
+func TestParseTrueTypeFont(t *testing.T) {
+	font, err := ParseTrueTypeFont(buildTestFont())
+	require.NoError(t, err)
+
+	assert.Equal(t, "EmbeddedFont", font.Name())
+	assert.True(t, font.HasGlyph('é'))
+	assert.False(t, font.HasGlyph('z'))
+	assert.Equal(t, uint16(3), font.glyph('é'))
+	assert.Equal(t, uint16(1), font.glyph(' '), "espace fine rendue par l'espace")
+	assert.Equal(t, uint16(0), font.glyph('z'))
+
+	// A (1366) + espace (512) + é (1140) à 10 pt
+	assert.InDelta(t, 3018.0*10/2048, font.Width("A é", 10), 0.001)
+	assert.Equal(t, 781, font.scale(1600))
+}
+
+func TestParseTrueTypeFont_Invalid(t *testing.T) {
+	_, err := ParseTrueTypeFont([]byte("OTTO0000000000000000"))
+	assert.ErrorContains(t, err, "unsupported font format")
+
+	data := buildTestFont()
+	_, err = ParseTrueTypeFont(data[:40])
+	assert.Error(t, err)
+}
+
+func TestDocument_EmbedsTrueTypeFont(t *testing.T) {
+	font, err := ParseTrueTypeFont(buildTestFont())
+	require.NoError(t, err)
+
+	doc := New(A4, 50)
+	doc.Paragraph("A é", Style{Font: font, Size: 12})
+	data := string(render(t, doc))
+
+	assert.Contains(t, data, "/Subtype /Type0 /BaseFont /EmbeddedFont /Encoding /Identity-H")
+	assert.Contains(t, data, "/CIDToGIDMap /Identity")
+	assert.Contains(t, data, "/W [1 [250] 2 [667] 3 [557]]")
+	assert.Contains(t, data, "/Length1 ")
+
+	streams := contentStreams(t, []byte(data))
+	require.Len(t, streams, 2, "ToUnicode et contenu de page")
+	assert.Contains(t, streams[1], "<000200010003> Tj")
+	assert.Contains(t, streams[0], "3 beginbfchar\n<0001> <0020>\n<0002> <0041>\n<0003> <00E9>\nendbfchar")
+}
@@ -3,12 +3,9 @@ package services
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/anthropics/anthropic-sdk-go"
-	"github.com/anthropics/anthropic-sdk-go/option"
-	"github.com/sashabaranov/go-openai"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/time/rate"
 
@@ -18,10 +15,13 @@ import (
 
 type AIService struct {
 	config          *config.AIConfig
-	claudeClient    *anthropic.Client
-	openaiClient    *openai.Client
+	providers       []TextGenerator // Chaîne ordonnée : primaire puis fallbacks
 	rateLimiter     *rate.Limiter
 	metricsRecorder MetricsRecorder
+	spendTracker    SpendTracker // Optionnel : active les plafonds de dépense
+
+	breakersMu sync.Mutex
+	breakers   map[string]*CircuitBreaker // Un circuit breaker par provider
 }
 
 type MetricsRecorder interface {
@@ -43,107 +43,169 @@ func (d *DefaultMetricsRecorder) RecordAIMetrics(metrics models.AIMetrics) {
 }
 
 func NewAIService(cfg *config.AIConfig, metrics MetricsRecorder) (*AIService, error) {
-	// Validate API keys
-	if cfg.AnthropicAPIKey == "" && cfg.OpenAIAPIKey == "" {
-		return nil, fmt.Errorf("at least one AI provider API key required")
+	// Construire la chaîne de providers (ceux mal configurés sont ignorés)
+	var providers []TextGenerator
+	for _, name := range cfg.ProviderChain() {
+		provider, err := newTextGenerator(name, cfg)
+		if err != nil {
+			log.Warn().Err(err).Str("provider", name).Msg("AI provider skipped")
+			continue
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one AI provider API key required (or AI_PRIMARY_PROVIDER=local|fake)")
 	}
 
 	if metrics == nil {
 		metrics = &DefaultMetricsRecorder{}
 	}
 
-	svc := &AIService{
+	return &AIService{
 		config:          cfg,
+		providers:       providers,
 		rateLimiter:     rate.NewLimiter(rate.Limit(cfg.MaxRequestsPerMinute)/60, 1),
 		metricsRecorder: metrics,
-	}
+	}, nil
+}
 
-	// Initialize Claude client
-	if cfg.AnthropicAPIKey != "" {
-		client := anthropic.NewClient(
-			option.WithAPIKey(cfg.AnthropicAPIKey),
-		)
-		svc.claudeClient = &client
+// Providers retourne les noms des providers actifs, dans l'ordre de fallback
+func (s *AIService) Providers() []string {
+	names := make([]string, len(s.providers))
+	for i, p := range s.providers {
+		names[i] = p.Name()
 	}
+	return names
+}
 
-	// Initialize OpenAI client
-	if cfg.OpenAIAPIKey != "" {
-		svc.openaiClient = openai.NewClient(cfg.OpenAIAPIKey)
-	}
+// StreamHandler : callback appelé pour chaque fragment de texte reçu en streaming
+type StreamHandler func(delta string)
+
+// GenerateText : génère du texte avec fallback automatique sur la chaîne de providers
+func (s *AIService) GenerateText(ctx context.Context, prompt string) (string, *models.AIMetrics, error) {
+	return s.generate(ctx, prompt, nil)
+}
 
-	return svc, nil
+// GenerateTextStream : génère du texte en streaming avec fallback automatique
+// onDelta est appelé pour chaque fragment reçu. Le fallback vers le provider suivant
+// n'est tenté que si aucun fragment n'a été émis (sinon le client recevrait un texte
+// mélangé entre deux providers).
+func (s *AIService) GenerateTextStream(ctx context.Context, prompt string, onDelta StreamHandler) (string, *models.AIMetrics, error) {
+	return s.generate(ctx, prompt, onDelta)
 }
 
-// GenerateText : génère du texte avec fallback automatique
-func (s *AIService) GenerateText(ctx context.Context, prompt string) (string, *models.AIMetrics, error) {
+// generate parcourt la chaîne de providers (streaming si onDelta non nil)
+func (s *AIService) generate(ctx context.Context, prompt string, onDelta StreamHandler) (string, *models.AIMetrics, error) {
 	// Rate limiting
 	if err := s.rateLimiter.Wait(ctx); err != nil {
 		return "", nil, fmt.Errorf("rate limit: %w", err)
 	}
 
-	var (
-		text    string
-		metrics *models.AIMetrics
-		err     error
-	)
-
-	// Tenter provider primaire
-	if s.config.PrimaryProvider == "claude" && s.claudeClient != nil {
-		text, metrics, err = s.generateWithClaude(ctx, prompt)
-		if err == nil {
-			return text, metrics, nil
+	// Suivre si des fragments ont déjà été transmis
+	emitted := false
+	var tracked StreamHandler
+	if onDelta != nil {
+		tracked = func(delta string) {
+			emitted = true
+			onDelta(delta)
 		}
-		log.Warn().Err(err).Msg("Claude generation failed, trying OpenAI fallback")
 	}
 
-	// Fallback OpenAI (ou primaire si PrimaryProvider == "openai")
-	if s.openaiClient != nil {
-		text, metrics, err = s.generateWithOpenAI(ctx, prompt)
+	var lastErr error
+	for i, provider := range s.providers {
+		// Circuit ouvert : bascule immédiate sur le provider suivant
+		breaker := s.breakerFor(provider.Name())
+		if !breaker.Allow() {
+			lastErr = fmt.Errorf("%s: %w", provider.Name(), ErrCircuitOpen)
+			log.Warn().Str("provider", provider.Name()).Msg("AI provider skipped: circuit breaker open")
+			continue
+		}
+
+		provider, err := s.withinBudget(ctx, provider)
+		if err != nil {
+			breaker.Release()
+			lastErr = err
+			log.Warn().Err(err).Str("provider", s.providers[i].Name()).Msg("AI provider skipped: budget exceeded")
+			continue
+		}
+
+		text, metrics, err := s.generateWith(ctx, provider, prompt, tracked, &emitted)
 		if err == nil {
+			breaker.Success()
 			return text, metrics, nil
 		}
+		if perr := classifyProviderError(provider.Name(), err, 0); perr.CountsAsFailure() {
+			breaker.Failure()
+		} else {
+			breaker.Release()
+		}
+		if emitted || ctx.Err() != nil {
+			return "", metrics, err
+		}
+
+		lastErr = err
+		if i < len(s.providers)-1 {
+			log.Warn().Err(err).
+				Str("provider", provider.Name()).
+				Str("next_provider", s.providers[i+1].Name()).
+				Msg("AI generation failed, trying next provider")
+		}
 	}
 
-	return "", nil, fmt.Errorf("all AI providers failed")
+	return "", nil, fmt.Errorf("all AI providers failed: %w", lastErr)
 }
 
-// generateWithClaude : génération via Claude
-func (s *AIService) generateWithClaude(ctx context.Context, prompt string) (string, *models.AIMetrics, error) {
+// generateWith : appel d'un provider avec timeout, retry et métriques
+func (s *AIService) generateWith(ctx context.Context, provider TextGenerator, prompt string, onDelta StreamHandler, emitted *bool) (string, *models.AIMetrics, error) {
 	start := time.Now()
 	metrics := &models.AIMetrics{
-		Provider: "claude",
-		Model:    s.config.ClaudeModel,
+		Provider:   provider.Name(),
+		Model:      provider.Model(),
+		LetterType: usageLetterType(ctx),
 	}
 
 	// Context avec timeout
-	ctx, cancel := context.WithTimeout(ctx, s.config.RequestTimeout)
-	defer cancel()
-
-	// Appel API avec retry
-	var resp *anthropic.Message
-	var err error
-
-	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt-1))
-			log.Info().Int("attempt", attempt).Dur("backoff", backoff).Msg("Retrying Claude request")
-			time.Sleep(backoff)
-		}
+	if s.config.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.RequestTimeout)
+		defer cancel()
+	}
 
-		resp, err = s.claudeClient.Messages.New(ctx, anthropic.MessageNewParams{
-			Model:     anthropic.Model(s.config.ClaudeModel),
-			MaxTokens: int64(s.config.MaxTokensPerRequest),
-			Messages: []anthropic.MessageParam{
-				anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
-			},
-		})
+	var (
+		result *GenerationResult
+		err    error
+	)
 
+	for attempt := 0; ; attempt++ {
+		callCtx, retryAfter := captureRetryAfter(ctx)
+		if onDelta != nil {
+			result, err = provider.GenerateStream(callCtx, prompt, onDelta)
+		} else {
+			result, err = provider.Generate(callCtx, prompt)
+		}
 		if err == nil {
 			break
 		}
 
-		// Si erreur non-retryable, arrêter
-		if !isRetryableError(err) {
+		perr := classifyProviderError(provider.Name(), err, retryAfter.get())
+		recordProviderError(perr)
+		err = perr
+
+		// Impossible de relancer une fois des fragments émis
+		if *emitted || !perr.Retryable() || attempt >= s.config.MaxRetries {
+			break
+		}
+
+		// Un Retry-After trop long : mieux vaut basculer sur le provider suivant
+		delay := s.retryDelay(attempt, perr)
+		if delay > maxRetryAfter {
+			log.Warn().Str("provider", provider.Name()).Dur("retry_after", delay).Msg("Retry-After too long, giving up on provider")
+			break
+		}
+
+		log.Info().Str("provider", provider.Name()).Int("attempt", attempt+1).Dur("backoff", delay).Str("kind", string(perr.Kind)).Msg("Retrying AI request")
+		if !sleepContext(ctx, delay) {
 			break
 		}
 	}
@@ -154,111 +216,71 @@ func (s *AIService) generateWithClaude(ctx context.Context, prompt string) (stri
 		metrics.Success = false
 		metrics.ErrorMessage = err.Error()
 		s.recordMetrics(metrics)
-		return "", metrics, fmt.Errorf("claude API error: %w", err)
-	}
-
-	// Parse response
-	if len(resp.Content) == 0 {
-		return "", metrics, fmt.Errorf("empty response from Claude")
+		log.Error().Err(err).Str("provider", provider.Name()).Str("model", provider.Model()).Msg("AI provider call failed")
+		return "", metrics, err
 	}
 
-	text := resp.Content[0].Text
-
-	// Metrics
-	metrics.TokensInput = int(resp.Usage.InputTokens)
-	metrics.TokensOutput = int(resp.Usage.OutputTokens)
-	metrics.TotalTokens = metrics.TokensInput + metrics.TokensOutput
-	metrics.EstimatedCost = s.calculateClaudeCost(metrics.TokensInput, metrics.TokensOutput)
+	metrics.TokensInput = result.TokensInput
+	metrics.TokensOutput = result.TokensOutput
+	metrics.TotalTokens = result.TokensInput + result.TokensOutput
+	metrics.EstimatedCost = s.estimateCost(provider.Name(), provider.Model(), metrics.TokensInput, metrics.TokensOutput)
 	metrics.Success = true
 
 	s.recordMetrics(metrics)
 
-	return text, metrics, nil
+	return result.Text, metrics, nil
 }
 
-// generateWithOpenAI : génération via GPT-4
-func (s *AIService) generateWithOpenAI(ctx context.Context, prompt string) (string, *models.AIMetrics, error) {
-	start := time.Now()
-	metrics := &models.AIMetrics{
-		Provider: "openai",
-		Model:    s.config.OpenAIModel,
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, s.config.RequestTimeout)
-	defer cancel()
-
-	var resp openai.ChatCompletionResponse
-	var err error
+// maxRetryAfter : délai d'attente maximal avant un nouvel essai sur le même provider
+const maxRetryAfter = 20 * time.Second
 
-	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt-1))
-			time.Sleep(backoff)
-		}
+// retryDelay : backoff exponentiel, ou Retry-After s'il est plus long
+func (s *AIService) retryDelay(attempt int, perr *ProviderError) time.Duration {
+	delay := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt))
+	return max(delay, perr.RetryAfter)
+}
 
-		resp, err = s.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model: s.config.OpenAIModel,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: s.config.MaxTokensPerRequest,
-		})
+// sleepContext attend d (false si le contexte est annulé avant)
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-		if err == nil {
-			break
-		}
-
-		if !isRetryableError(err) {
-			break
-		}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
+}
 
-	metrics.ResponseTimeMs = time.Since(start).Milliseconds()
+// breakerFor retourne le circuit breaker d'un provider (créé au premier appel)
+func (s *AIService) breakerFor(provider string) *CircuitBreaker {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
 
-	if err != nil {
-		metrics.Success = false
-		metrics.ErrorMessage = err.Error()
-		s.recordMetrics(metrics)
-		log.Error().Err(err).Str("model", s.config.OpenAIModel).Msg("OpenAI API call failed")
-		return "", metrics, fmt.Errorf("openai API error: %w", err)
+	if s.breakers == nil {
+		s.breakers = make(map[string]*CircuitBreaker)
 	}
-
-	if len(resp.Choices) == 0 {
-		return "", metrics, fmt.Errorf("empty response from OpenAI")
+	breaker, ok := s.breakers[provider]
+	if !ok {
+		breaker = NewCircuitBreaker(provider, s.config.CircuitFailureThreshold, s.config.CircuitOpenDuration)
+		s.breakers[provider] = breaker
 	}
-
-	text := resp.Choices[0].Message.Content
-
-	metrics.TokensInput = resp.Usage.PromptTokens
-	metrics.TokensOutput = resp.Usage.CompletionTokens
-	metrics.TotalTokens = resp.Usage.TotalTokens
-	metrics.EstimatedCost = s.calculateOpenAICost(metrics.TokensInput, metrics.TokensOutput)
-	metrics.Success = true
-
-	s.recordMetrics(metrics)
-
-	return text, metrics, nil
+	return breaker
 }
 
-// calculateClaudeCost : estime coût Claude
-func (s *AIService) calculateClaudeCost(inputTokens, outputTokens int) float64 {
-	// Claude 3.5 Sonnet pricing (Dec 2024)
-	// Input: $3/MTok, Output: $15/MTok
-	inputCost := float64(inputTokens) / 1_000_000 * 3.0
-	outputCost := float64(outputTokens) / 1_000_000 * 15.0
-	return inputCost + outputCost
+// CircuitStates retourne l'état du circuit breaker de chaque provider de la chaîne
+func (s *AIService) CircuitStates() map[string]CircuitState {
+	states := make(map[string]CircuitState, len(s.providers))
+	for _, provider := range s.providers {
+		states[provider.Name()] = s.breakerFor(provider.Name()).State()
+	}
+	return states
 }
 
-// calculateOpenAICost : estime coût OpenAI
-func (s *AIService) calculateOpenAICost(inputTokens, outputTokens int) float64 {
-	// GPT-4 Turbo pricing
-	// Input: $10/MTok, Output: $30/MTok
-	inputCost := float64(inputTokens) / 1_000_000 * 10.0
-	outputCost := float64(outputTokens) / 1_000_000 * 30.0
-	return inputCost + outputCost
+// estimateCost : coût estimé selon la table de tarifs (gratuit pour local et fake)
+func (s *AIService) estimateCost(provider, model string, inputTokens, outputTokens int) float64 {
+	return s.config.PriceFor(provider, model).Cost(inputTokens, outputTokens)
 }
 
 // recordMetrics : enregistre métriques
@@ -276,28 +298,16 @@ func (s *AIService) recordMetrics(m *models.AIMetrics) {
 		Msg("AI generation completed")
 }
 
-// isRetryableError : détermine si erreur est retryable
-func isRetryableError(err error) bool {
-	// Rate limits, timeouts, 5xx errors → retry
-	// 4xx (sauf 429) → ne pas retry
-	errStr := strings.ToLower(err.Error())
-
-	retryablePatterns := []string{
-		"rate_limit",
-		"rate limit",
-		"timeout",
-		"503",
-		"502",
-		"500",
-		"429",
-		"too many requests",
-	}
+// usageLetterTypeKey : clé de contexte du type de lettre à l'origine d'un appel IA
+type usageLetterTypeKey struct{}
 
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(errStr, pattern) {
-			return true
-		}
-	}
+// WithUsageLetterType attache le type de lettre au contexte pour le registre des dépenses
+func WithUsageLetterType(ctx context.Context, letterType models.LetterType) context.Context {
+	return context.WithValue(ctx, usageLetterTypeKey{}, string(letterType))
+}
 
-	return false
+// usageLetterType retourne le type de lettre attaché au contexte (vide si absent)
+func usageLetterType(ctx context.Context) string {
+	letterType, _ := ctx.Value(usageLetterTypeKey{}).(string)
+	return letterType
 }
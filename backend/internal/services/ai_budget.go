@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/config"
+)
+
+// ErrAIBudgetExceeded : plafond de dépense atteint sans modèle de repli
+var ErrAIBudgetExceeded = errors.New("AI budget exceeded")
+
+// SpendTracker fournit les dépenses IA cumulées (USD) d'un provider depuis une date
+type SpendTracker interface {
+	ProviderSpend(ctx context.Context, provider string, since time.Time) (float64, error)
+}
+
+// AIBudgetStatus état du budget d'un provider sur la journée et le mois en cours (UTC)
+type AIBudgetStatus struct {
+	Provider       string  `json:"provider"`
+	DailyLimit     float64 `json:"daily_limit"`
+	DailySpent     float64 `json:"daily_spent"`
+	MonthlyLimit   float64 `json:"monthly_limit"`
+	MonthlySpent   float64 `json:"monthly_spent"`
+	DowngradeModel string  `json:"downgrade_model,omitempty"`
+	Exceeded       bool    `json:"exceeded"`
+}
+
+// SetSpendTracker active l'application des budgets configurés (AI_BUDGET_*)
+func (s *AIService) SetSpendTracker(tracker SpendTracker) {
+	s.spendTracker = tracker
+}
+
+// withinBudget retourne le provider à utiliser compte tenu de son budget
+// Plafond atteint : bascule sur le modèle de repli s'il est configuré, sinon ErrAIBudgetExceeded.
+// En cas d'erreur de lecture des dépenses, l'appel est autorisé (le registre ne doit pas bloquer les lettres).
+func (s *AIService) withinBudget(ctx context.Context, provider TextGenerator) (TextGenerator, error) {
+	budget := s.config.BudgetFor(provider.Name())
+	if s.spendTracker == nil || (budget.Daily <= 0 && budget.Monthly <= 0) {
+		return provider, nil
+	}
+
+	status, err := budgetStatus(ctx, s.spendTracker, provider.Name(), budget, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider.Name()).Msg("Failed to read AI spend, budget not enforced")
+		return provider, nil
+	}
+	if !status.Exceeded {
+		return provider, nil
+	}
+
+	if switcher, ok := provider.(modelSwitcher); ok && budget.DowngradeModel != "" {
+		log.Info().
+			Str("provider", provider.Name()).
+			Str("model", provider.Model()).
+			Str("downgrade_model", budget.DowngradeModel).
+			Float64("daily_spent", status.DailySpent).
+			Float64("monthly_spent", status.MonthlySpent).
+			Msg("AI budget exceeded, downgrading model")
+		return switcher.WithModel(budget.DowngradeModel), nil
+	}
+
+	return nil, fmt.Errorf("%w for %s (daily %.2f/%.2f USD, monthly %.2f/%.2f USD)", ErrAIBudgetExceeded,
+		provider.Name(), status.DailySpent, status.DailyLimit, status.MonthlySpent, status.MonthlyLimit)
+}
+
+// budgetStatus calcule l'état du budget d'un provider à l'instant now
+func budgetStatus(ctx context.Context, tracker SpendTracker, provider string, budget config.AIBudget, now time.Time) (AIBudgetStatus, error) {
+	status := AIBudgetStatus{
+		Provider:       provider,
+		DailyLimit:     budget.Daily,
+		MonthlyLimit:   budget.Monthly,
+		DowngradeModel: budget.DowngradeModel,
+	}
+
+	dayStart, monthStart := periodStarts(now)
+
+	var err error
+	if status.MonthlySpent, err = tracker.ProviderSpend(ctx, provider, monthStart); err != nil {
+		return status, err
+	}
+	if status.DailySpent, err = tracker.ProviderSpend(ctx, provider, dayStart); err != nil {
+		return status, err
+	}
+
+	status.Exceeded = (budget.Daily > 0 && status.DailySpent >= budget.Daily) ||
+		(budget.Monthly > 0 && status.MonthlySpent >= budget.Monthly)
+	return status, nil
+}
+
+// periodStarts retourne le début du jour et du mois en cours (UTC)
+func periodStarts(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
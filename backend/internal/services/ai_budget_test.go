@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+// stubSpendTracker dépenses fixes par provider, identiques quelle que soit la période
+type stubSpendTracker struct {
+	spent map[string]float64
+	err   error
+}
+
+func (s *stubSpendTracker) ProviderSpend(ctx context.Context, provider string, since time.Time) (float64, error) {
+	return s.spent[provider], s.err
+}
+
+// recordingMetrics conserve les métriques enregistrées
+type recordingMetrics struct {
+	metrics []models.AIMetrics
+}
+
+func (r *recordingMetrics) RecordAIMetrics(metrics models.AIMetrics) {
+	r.metrics = append(r.metrics, metrics)
+}
+
+func newBudgetTestService(budgets map[string]config.AIBudget, tracker SpendTracker, providers ...TextGenerator) (*AIService, *recordingMetrics) {
+	recorder := &recordingMetrics{}
+	return &AIService{
+		config: &config.AIConfig{
+			MaxRequestsPerMinute: 6000,
+			EnableCostTracking:   true,
+			Pricing:              config.DefaultPricing(),
+			Budgets:              budgets,
+		},
+		providers:       providers,
+		rateLimiter:     newTestRateLimiter(),
+		metricsRecorder: recorder,
+		spendTracker:    tracker,
+	}, recorder
+}
+
+func TestAIService_BudgetRefusesProviderAndFallsBack(t *testing.T) {
+	expensive := &stubProvider{name: "first", text: "Cher"}
+	cheap := &stubProvider{name: "second", text: "Bon marché"}
+	svc, _ := newBudgetTestService(
+		map[string]config.AIBudget{"first": {Daily: 5}},
+		&stubSpendTracker{spent: map[string]float64{"first": 5.2}},
+		expensive, cheap,
+	)
+
+	text, metrics, err := svc.GenerateText(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "Bon marché", text)
+	assert.Equal(t, "second", metrics.Provider)
+	assert.Zero(t, expensive.calls)
+
+	// Plus aucun provider disponible : erreur typée
+	svc.providers = []TextGenerator{expensive}
+	_, _, err = svc.GenerateText(context.Background(), "prompt")
+	assert.ErrorIs(t, err, ErrAIBudgetExceeded)
+	assert.Zero(t, expensive.calls)
+}
+
+func TestAIService_BudgetDowngradesModel(t *testing.T) {
+	provider := &openAIProvider{name: config.ProviderOpenAI, model: "gpt-4o"}
+	svc, _ := newBudgetTestService(
+		map[string]config.AIBudget{config.ProviderOpenAI: {Monthly: 30, DowngradeModel: "gpt-4o-mini"}},
+		&stubSpendTracker{spent: map[string]float64{config.ProviderOpenAI: 31}},
+		provider,
+	)
+
+	selected, err := svc.withinBudget(context.Background(), provider)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o-mini", selected.Model())
+	assert.Equal(t, "gpt-4o", provider.Model(), "le provider configuré reste inchangé")
+}
+
+func TestAIService_BudgetNotEnforced(t *testing.T) {
+	provider := &stubProvider{name: "first", text: "Bonjour"}
+
+	testCases := []struct {
+		name    string
+		budgets map[string]config.AIBudget
+		tracker SpendTracker
+	}{
+		{"Under budget", map[string]config.AIBudget{"first": {Daily: 5, Monthly: 50}}, &stubSpendTracker{spent: map[string]float64{"first": 4.9}}},
+		{"No budget", nil, &stubSpendTracker{spent: map[string]float64{"first": 1000}}},
+		{"No tracker", map[string]config.AIBudget{"first": {Daily: 5}}, nil},
+		{"Tracker error", map[string]config.AIBudget{"first": {Daily: 5}}, &stubSpendTracker{err: errors.New("db down")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _ := newBudgetTestService(tc.budgets, tc.tracker, provider)
+			selected, err := svc.withinBudget(context.Background(), provider)
+			require.NoError(t, err)
+			assert.Same(t, provider, selected)
+		})
+	}
+}
+
+func TestAIService_MetricsPricingAndLetterType(t *testing.T) {
+	provider := &stubProvider{name: config.ProviderOpenAI, text: "Bonjour"}
+	svc, recorder := newBudgetTestService(nil, nil, provider)
+	svc.config.Pricing["openai-model"] = config.ModelPrice{InputPerMTok: 1_000_000, OutputPerMTok: 2_000_000}
+
+	ctx := WithUsageLetterType(context.Background(), models.LetterTypeAntiMotivation)
+	_, metrics, err := svc.GenerateText(ctx, "prompt")
+	require.NoError(t, err)
+
+	// 10 tokens en entrée, 20 en sortie au tarif du modèle
+	assert.InDelta(t, 50.0, metrics.EstimatedCost, 1e-9)
+	require.Len(t, recorder.metrics, 1)
+	assert.Equal(t, "anti_motivation", recorder.metrics[0].LetterType)
+	assert.Equal(t, "openai-model", recorder.metrics[0].Model)
+}
+
+func TestBudgetStatus_Periods(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	dayStart, monthStart := periodStarts(now)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), dayStart)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), monthStart)
+
+	status, err := budgetStatus(context.Background(), &stubSpendTracker{spent: map[string]float64{"claude": 12}}, "claude",
+		config.AIBudget{Daily: 20, Monthly: 10}, now)
+	require.NoError(t, err)
+	assert.True(t, status.Exceeded)
+	assert.Equal(t, 12.0, status.MonthlySpent)
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/sashabaranov/go-openai"
+
+	"maicivy/internal/metrics"
+)
+
+// ProviderErrorKind catégorie d'erreur d'un provider LLM
+type ProviderErrorKind string
+
+const (
+	ProviderErrRateLimited    ProviderErrorKind = "rate_limited"    // 429
+	ProviderErrUnavailable    ProviderErrorKind = "unavailable"     // 5xx, 529 (overloaded), erreur réseau
+	ProviderErrTimeout        ProviderErrorKind = "timeout"         // Délai dépassé
+	ProviderErrAuth           ProviderErrorKind = "auth"            // 401, 403
+	ProviderErrInvalidRequest ProviderErrorKind = "invalid_request" // Autres 4xx : inutile de réessayer
+	ProviderErrCanceled       ProviderErrorKind = "canceled"        // Contexte annulé par l'appelant
+	ProviderErrUnknown        ProviderErrorKind = "unknown"
+)
+
+// ProviderError erreur typée d'un provider, classée à partir du code HTTP
+type ProviderError struct {
+	Provider   string
+	Kind       ProviderErrorKind
+	StatusCode int           // 0 si aucune réponse HTTP
+	RetryAfter time.Duration // Délai demandé par le header Retry-After (0 si absent)
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("%s %s (HTTP %d): %v", e.Provider, e.Kind, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Provider, e.Kind, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// Retryable indique si l'appel peut être relancé sur le même provider
+func (e *ProviderError) Retryable() bool {
+	switch e.Kind {
+	case ProviderErrRateLimited, ProviderErrUnavailable, ProviderErrTimeout:
+		return true
+	}
+	return false
+}
+
+// CountsAsFailure indique si l'erreur révèle un provider en mauvaise santé (circuit breaker)
+// Une requête invalide ou une annulation par l'appelant ne dit rien de l'état du provider.
+func (e *ProviderError) CountsAsFailure() bool {
+	return e.Kind != ProviderErrInvalidRequest && e.Kind != ProviderErrCanceled
+}
+
+// classifyProviderError convertit une erreur de SDK en ProviderError
+// retryAfter est le délai capturé sur la réponse HTTP (go-openai n'expose pas les headers).
+func classifyProviderError(provider string, err error, retryAfter time.Duration) *ProviderError {
+	var perr *ProviderError
+	if errors.As(err, &perr) {
+		return perr
+	}
+
+	perr = &ProviderError{Provider: provider, Kind: ProviderErrUnknown, RetryAfter: retryAfter, Err: err}
+
+	var (
+		anthropicErr *anthropic.Error
+		openaiErr    *openai.APIError
+		requestErr   *openai.RequestError
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &anthropicErr):
+		perr.StatusCode = anthropicErr.StatusCode
+		if anthropicErr.Response != nil && perr.RetryAfter == 0 {
+			perr.RetryAfter = parseRetryAfter(anthropicErr.Response.Header.Get("Retry-After"), time.Now())
+		}
+	case errors.As(err, &openaiErr):
+		perr.StatusCode = openaiErr.HTTPStatusCode
+	case errors.As(err, &requestErr):
+		perr.StatusCode = requestErr.HTTPStatusCode
+	case errors.Is(err, context.Canceled):
+		perr.Kind = ProviderErrCanceled
+		return perr
+	case errors.Is(err, context.DeadlineExceeded):
+		perr.Kind = ProviderErrTimeout
+		return perr
+	case errors.As(err, &netErr):
+		perr.Kind = ProviderErrUnavailable
+		if netErr.Timeout() {
+			perr.Kind = ProviderErrTimeout
+		}
+		return perr
+	}
+
+	perr.Kind = kindForStatus(perr.StatusCode)
+	return perr
+}
+
+// recordProviderError comptabilise une erreur de provider (Prometheus)
+func recordProviderError(perr *ProviderError) {
+	metrics.IncrementAIProviderError(perr.Provider, string(perr.Kind))
+}
+
+// kindForStatus catégorie d'erreur associée à un code HTTP
+func kindForStatus(status int) ProviderErrorKind {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ProviderErrRateLimited
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ProviderErrTimeout
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ProviderErrAuth
+	case status >= 500: // 529 = API Anthropic surchargée
+		return ProviderErrUnavailable
+	case status >= 400:
+		return ProviderErrInvalidRequest
+	}
+	return ProviderErrUnknown
+}
+
+// parseRetryAfter lit un header Retry-After (secondes ou date HTTP)
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// ============================================
+// Capture du Retry-After (clients sans accès aux headers)
+// ============================================
+
+// retryAfterKey : clé de contexte du délai Retry-After capturé
+type retryAfterKey struct{}
+
+// retryAfterHint délai Retry-After de la dernière réponse d'un appel
+type retryAfterHint struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (h *retryAfterHint) get() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay
+}
+
+// captureRetryAfter attache au contexte un emplacement rempli par retryAfterTransport
+func captureRetryAfter(ctx context.Context) (context.Context, *retryAfterHint) {
+	hint := &retryAfterHint{}
+	return context.WithValue(ctx, retryAfterKey{}, hint), hint
+}
+
+// retryAfterTransport relève le header Retry-After des réponses 429 et 5xx
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) {
+		return resp, err
+	}
+
+	if hint, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHint); ok {
+		hint.mu.Lock()
+		hint.delay = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		hint.mu.Unlock()
+	}
+	return resp, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+)
+
+func TestClassifyProviderError(t *testing.T) {
+	anthropicErr := func(status int, retryAfter string) error {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return fmt.Errorf("claude API error: %w", &anthropic.Error{
+			StatusCode: status,
+			Request:    &http.Request{Method: "POST", URL: &url.URL{Path: "/v1/messages"}},
+			Response:   resp,
+		})
+	}
+
+	testCases := []struct {
+		name       string
+		err        error
+		kind       ProviderErrorKind
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{"Anthropic rate limit", anthropicErr(429, "7"), ProviderErrRateLimited, true, 7 * time.Second},
+		{"Anthropic overloaded", anthropicErr(529, ""), ProviderErrUnavailable, true, 0},
+		{"Anthropic bad request", anthropicErr(400, ""), ProviderErrInvalidRequest, false, 0},
+		{"Anthropic auth", anthropicErr(401, ""), ProviderErrAuth, false, 0},
+		{"OpenAI server error", &openai.APIError{HTTPStatusCode: 503, Message: "down"}, ProviderErrUnavailable, true, 0},
+		{"OpenAI request error", &openai.RequestError{HTTPStatusCode: 404, Err: errors.New("not found")}, ProviderErrInvalidRequest, false, 0},
+		{"Deadline", fmt.Errorf("openai stream error: %w", context.DeadlineExceeded), ProviderErrTimeout, true, 0},
+		{"Canceled", context.Canceled, ProviderErrCanceled, false, 0},
+		// Un message contenant "500" ou "timeout" ne suffit plus à déclencher un retry
+		{"Untyped error", errors.New("invalid prompt: timeout must be < 500"), ProviderErrUnknown, false, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perr := classifyProviderError("claude", tc.err, 0)
+			assert.Equal(t, tc.kind, perr.Kind)
+			assert.Equal(t, tc.retryable, perr.Retryable())
+			assert.Equal(t, tc.retryAfter, perr.RetryAfter)
+			assert.ErrorIs(t, perr, tc.err)
+
+			// Déjà classée : inchangée
+			assert.Same(t, perr, classifyProviderError("other", fmt.Errorf("wrapped: %w", perr), 0))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 1500*time.Millisecond, parseRetryAfter("1.5", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Fri, 16 Oct 2026 12:01:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("Fri, 16 Oct 2026 11:00:00 GMT", now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
+
+func TestLocalProvider_RetryAfterCaptured(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error": {"message": "slow down", "type": "rate_limit"}}`))
+	}))
+	defer server.Close()
+
+	svc, err := NewAIService(&config.AIConfig{
+		PrimaryProvider:      "local",
+		LocalBaseURL:         server.URL + "/v1",
+		MaxRequestsPerMinute: 6000,
+		MaxRetries:           3,
+		RetryBaseDelay:       time.Millisecond,
+	}, nil)
+	require.NoError(t, err)
+
+	_, _, err = svc.GenerateText(context.Background(), "prompt")
+	require.Error(t, err)
+
+	var perr *ProviderError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, ProviderErrRateLimited, perr.Kind)
+	assert.Equal(t, 2*time.Minute, perr.RetryAfter)
+	// Retry-After au-delà du maximum : pas de nouvel essai sur ce provider
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestAIService_RetryHonoursRetryAfterAndContext(t *testing.T) {
+	rateLimited := &ProviderError{Provider: "first", Kind: ProviderErrRateLimited, RetryAfter: 30 * time.Millisecond, Err: errors.New("429")}
+
+	provider := &stubProvider{name: "first", err: rateLimited}
+	svc := &AIService{
+		config:          &config.AIConfig{MaxRequestsPerMinute: 6000, MaxRetries: 2, RetryBaseDelay: time.Millisecond},
+		providers:       []TextGenerator{provider},
+		rateLimiter:     newTestRateLimiter(),
+		metricsRecorder: &DefaultMetricsRecorder{},
+	}
+
+	start := time.Now()
+	_, _, err := svc.GenerateText(context.Background(), "prompt")
+	require.ErrorIs(t, err, rateLimited)
+	assert.Equal(t, 3, provider.calls)
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond, "Retry-After respecté entre les essais")
+
+	// L'attente entre deux essais s'interrompt dès l'annulation du contexte
+	provider.calls = 0
+	rateLimited.RetryAfter = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	_, _, err = svc.GenerateText(ctx, "prompt")
+	require.Error(t, err)
+	assert.Equal(t, 1, provider.calls)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestAIService_CircuitBreakerFailover(t *testing.T) {
+	unavailable := &ProviderError{Provider: "primary", Kind: ProviderErrUnavailable, StatusCode: 503, Err: errors.New("503")}
+	primary := &stubProvider{name: "primary", err: unavailable}
+	secondary := &stubProvider{name: "secondary", text: "Bonjour"}
+
+	svc := &AIService{
+		config:          &config.AIConfig{MaxRequestsPerMinute: 6000, CircuitFailureThreshold: 2, CircuitOpenDuration: time.Hour},
+		providers:       []TextGenerator{primary, secondary},
+		rateLimiter:     newTestRateLimiter(),
+		metricsRecorder: &DefaultMetricsRecorder{},
+	}
+
+	for i := 0; i < 4; i++ {
+		text, _, err := svc.GenerateText(context.Background(), "prompt")
+		require.NoError(t, err)
+		assert.Equal(t, "Bonjour", text)
+	}
+
+	// Après 2 échecs le primaire n'est plus appelé
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, 4, secondary.calls)
+	assert.Equal(t, map[string]CircuitState{"primary": CircuitOpen, "secondary": CircuitClosed}, svc.CircuitStates())
+
+	// Une requête invalide ne compte pas comme une panne
+	invalid := &stubProvider{name: "invalid", err: &ProviderError{Provider: "invalid", Kind: ProviderErrInvalidRequest, Err: errors.New("400")}}
+	svc.providers = []TextGenerator{invalid}
+	for i := 0; i < 3; i++ {
+		_, _, _ = svc.GenerateText(context.Background(), "prompt")
+	}
+	assert.Equal(t, 3, invalid.calls)
+	assert.Equal(t, CircuitClosed, svc.CircuitStates()["invalid"])
+
+	// Tous les circuits ouverts : erreur typée
+	svc.providers = []TextGenerator{primary}
+	_, _, err := svc.GenerateText(context.Background(), "prompt")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
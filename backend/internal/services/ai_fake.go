@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+// fakeProvider : provider déterministe sans appel réseau (AI_PRIMARY_PROVIDER=fake)
+// Permet de faire tourner tout le pipeline de lettres en CI ou en local sans clé API.
+// Un même prompt produit toujours le même texte.
+type fakeProvider struct{}
+
+var (
+	// Nom de l'entreprise tel qu'écrit par le PromptBuilder (toutes langues)
+	fakeCompanyPattern = regexp.MustCompile(`(?:ENTREPRISE CIBLE|TARGET COMPANY|ZIELUNTERNEHMEN|EMPRESA OBJETIVO):\s*\n- (?:Nom|Name|Nombre): ([^\n]+)`)
+	// Nom du candidat : première ligne "- Nom:" du prompt (section profil)
+	fakeCandidatePattern = regexp.MustCompile(`(?m)^- (?:Nom|Name|Nombre): ([^\n]+)`)
+	// Longueur demandée (toutes langues), qui identifie le type de lettre
+	fakeLengthPattern = regexp.MustCompile(`(\d+)-(\d+) (?:mots|words|Wörter|palabras)`)
+	// Ligne d'objet demandée, avec son préfixe ("Objet : ...") ou seule (allemand)
+	fakeSubjectPattern = regexp.MustCompile(`"((?:Objet|Subject|Betreff|Asunto) ?: [^"\n]+)"|"Betreff": "([^"\n]+)"`)
+)
+
+var fakeParagraphs = []string{
+	"Votre approche produit et la qualité de votre ingénierie correspondent exactement à ce que je recherche pour la suite de mon parcours.",
+	"Au cours de mes dernières expériences, j'ai conçu et maintenu des services backend robustes, du design d'API jusqu'à la mise en production.",
+	"Je suis particulièrement attentif à la lisibilité du code, aux tests automatisés et à l'observabilité des systèmes que je livre.",
+	"Travailler en équipe resserrée, partager mes connaissances et apprendre des autres fait partie de ce qui me motive au quotidien.",
+	"Je serais ravi de contribuer à vos projets et de mettre mon expérience au service de vos ambitions techniques.",
+}
+
+func (p *fakeProvider) Name() string  { return config.ProviderFake }
+func (p *fakeProvider) Model() string { return "fake-deterministic" }
+
+func (p *fakeProvider) Generate(ctx context.Context, prompt string) (*GenerationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	text := fakeLetter(prompt)
+	return &GenerationResult{
+		Text:         text,
+		TokensInput:  len(strings.Fields(prompt)),
+		TokensOutput: len(strings.Fields(text)),
+	}, nil
+}
+
+func (p *fakeProvider) GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error) {
+	result, err := p.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Émettre mot par mot (en conservant les espaces) pour simuler un flux
+	remaining := result.Text
+	for remaining != "" {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		next := strings.IndexAny(remaining[1:], " \n")
+		chunk := remaining
+		if next >= 0 {
+			chunk = remaining[:next+1]
+		}
+		onDelta(chunk)
+		remaining = remaining[len(chunk):]
+	}
+
+	return result, nil
+}
+
+// fakeLetter construit une lettre stable à partir du hash du prompt
+// Le type de lettre est reconnu à la longueur demandée dans le prompt : le texte en
+// reprend le format (en-tête, objet, signature) et vise le milieu de sa plage de mots,
+// de sorte qu'il passe le contrôle qualité sans régénération.
+func fakeLetter(prompt string) string {
+	company := "votre entreprise"
+	if m := fakeCompanyPattern.FindStringSubmatch(prompt); m != nil {
+		company = strings.TrimSpace(m[1])
+	}
+	candidate := ""
+	if m := fakeCandidatePattern.FindStringSubmatch(prompt); m != nil {
+		candidate = strings.TrimSpace(m[1])
+	}
+	spec := fakeLetterSpec(prompt)
+
+	h := fnv.New32a()
+	h.Write([]byte(prompt))
+	seed := int(h.Sum32() % uint32(len(fakeParagraphs)))
+
+	var sb strings.Builder
+	if spec.HasHeader() && candidate != "" {
+		sb.WriteString(candidate + "\n\n" + company + "\n\n")
+	}
+	if m := fakeSubjectPattern.FindStringSubmatch(prompt); m != nil {
+		sb.WriteString(m[1] + m[2] + "\n\n")
+	}
+
+	// Corps : formules fixes complétées par les paragraphes jusqu'à la longueur visée
+	greeting := "Madame, Monsieur,"
+	intro := fmt.Sprintf("Je souhaite vous faire part de mon intérêt pour rejoindre %s.", company)
+	closing := "Je vous prie d'agréer, Madame, Monsieur, l'expression de mes salutations distinguées."
+	if spec.HasSignature() && candidate != "" {
+		closing += "\n\n" + candidate
+	}
+	remaining := (spec.MinWords+spec.MaxWords)/2 - wordCount(greeting, intro, closing)
+
+	sb.WriteString(greeting + "\n\n" + intro + "\n\n")
+	for i := 0; remaining > 0; i++ {
+		words := strings.Fields(fakeParagraphs[(seed+i)%len(fakeParagraphs)])
+		if len(words) > remaining {
+			words = words[:remaining]
+			words[remaining-1] += "…"
+		}
+		sb.WriteString(strings.Join(words, " ") + "\n\n")
+		remaining -= len(words)
+	}
+	sb.WriteString(closing)
+
+	return sb.String()
+}
+
+// fakeLetterSpec type de lettre dont la plage de mots est celle demandée dans le prompt
+// (lettre de motivation si le prompt n'en précise pas, par exemple pour une révision)
+func fakeLetterSpec(prompt string) *LetterTypeSpec {
+	if m := fakeLengthPattern.FindStringSubmatch(prompt); m != nil {
+		minWords, _ := strconv.Atoi(m[1])
+		maxWords, _ := strconv.Atoi(m[2])
+		for _, spec := range letterTypeRegistry {
+			if spec.MinWords == minWords && spec.MaxWords == maxWords {
+				return spec
+			}
+		}
+	}
+	return letterTypeSpecs[models.LetterTypeMotivation]
+}
+
+func wordCount(texts ...string) int {
+	n := 0
+	for _, text := range texts {
+		n += len(strings.Fields(text))
+	}
+	return n
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/rs/zerolog/log"
+	"github.com/sashabaranov/go-openai"
+
+	"maicivy/internal/config"
+)
+
+// TextGenerator : interface commune aux providers LLM
+// Un provider effectue un seul appel : retry, fallback, timeout et métriques
+// sont gérés par AIService.
+type TextGenerator interface {
+	Name() string
+	Model() string
+	Generate(ctx context.Context, prompt string) (*GenerationResult, error)
+	GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error)
+}
+
+// modelSwitcher : provider capable de servir un autre modèle (bascule budgétaire)
+type modelSwitcher interface {
+	WithModel(model string) TextGenerator
+}
+
+// GenerationResult : texte produit et consommation de tokens
+type GenerationResult struct {
+	Text         string
+	TokensInput  int
+	TokensOutput int
+}
+
+// newTextGenerator instancie un provider à partir de son nom
+func newTextGenerator(name string, cfg *config.AIConfig) (TextGenerator, error) {
+	switch name {
+	case config.ProviderClaude:
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY not set")
+		}
+		return newClaudeProvider(cfg), nil
+
+	case config.ProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY not set")
+		}
+		return &openAIProvider{
+			name:      config.ProviderOpenAI,
+			client:    openai.NewClientWithConfig(withRetryAfterCapture(openai.DefaultConfig(cfg.OpenAIAPIKey))),
+			model:     cfg.OpenAIModel,
+			maxTokens: cfg.MaxTokensPerRequest,
+		}, nil
+
+	case config.ProviderLocal:
+		if cfg.LocalBaseURL == "" {
+			return nil, fmt.Errorf("AI_LOCAL_BASE_URL not set")
+		}
+		return newLocalProvider(cfg), nil
+
+	case config.ProviderFake:
+		return &fakeProvider{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown AI provider %q", name)
+}
+
+// ============================================
+// Claude (Anthropic)
+// ============================================
+
+type claudeProvider struct {
+	client    anthropic.Client
+	model     string
+	maxTokens int
+}
+
+func newClaudeProvider(cfg *config.AIConfig) *claudeProvider {
+	return &claudeProvider{
+		// Retries gérés par AIService (backoff annulable, Retry-After, circuit breaker)
+		client:    anthropic.NewClient(option.WithAPIKey(cfg.AnthropicAPIKey), option.WithMaxRetries(0)),
+		model:     cfg.ClaudeModel,
+		maxTokens: cfg.MaxTokensPerRequest,
+	}
+}
+
+func (p *claudeProvider) Name() string  { return config.ProviderClaude }
+func (p *claudeProvider) Model() string { return p.model }
+
+func (p *claudeProvider) WithModel(model string) TextGenerator {
+	clone := *p
+	clone.model = model
+	return &clone
+}
+
+func (p *claudeProvider) params(prompt string) anthropic.MessageNewParams {
+	return anthropic.MessageNewParams{
+		Model:     anthropic.Model(p.model),
+		MaxTokens: int64(p.maxTokens),
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+		},
+	}
+}
+
+func (p *claudeProvider) Generate(ctx context.Context, prompt string) (*GenerationResult, error) {
+	resp, err := p.client.Messages.New(ctx, p.params(prompt))
+	if err != nil {
+		return nil, fmt.Errorf("claude API error: %w", err)
+	}
+
+	if len(resp.Content) == 0 {
+		return nil, fmt.Errorf("empty response from Claude")
+	}
+
+	return &GenerationResult{
+		Text:         resp.Content[0].Text,
+		TokensInput:  int(resp.Usage.InputTokens),
+		TokensOutput: int(resp.Usage.OutputTokens),
+	}, nil
+}
+
+func (p *claudeProvider) GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error) {
+	var (
+		message anthropic.Message
+		text    strings.Builder
+	)
+
+	stream := p.client.Messages.NewStreaming(ctx, p.params(prompt))
+	defer stream.Close()
+
+	for stream.Next() {
+		event := stream.Current()
+		if accErr := message.Accumulate(event); accErr != nil {
+			log.Debug().Err(accErr).Msg("Failed to accumulate Claude stream event")
+		}
+
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok && delta.Delta.Text != "" {
+			text.WriteString(delta.Delta.Text)
+			onDelta(delta.Delta.Text)
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("claude stream error: %w", err)
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response from Claude")
+	}
+
+	return &GenerationResult{
+		Text:         text.String(),
+		TokensInput:  int(message.Usage.InputTokens),
+		TokensOutput: int(message.Usage.OutputTokens),
+	}, nil
+}
+
+// ============================================
+// OpenAI et endpoints compatibles (Ollama, llama.cpp server, vLLM)
+// ============================================
+
+type openAIProvider struct {
+	name      string
+	client    *openai.Client
+	model     string
+	maxTokens int
+}
+
+// newLocalProvider : client OpenAI pointé sur un endpoint compatible
+func newLocalProvider(cfg *config.AIConfig) *openAIProvider {
+	// La plupart des serveurs locaux ignorent la clé mais le header reste requis
+	apiKey := cfg.LocalAPIKey
+	if apiKey == "" {
+		apiKey = "local"
+	}
+
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = strings.TrimRight(cfg.LocalBaseURL, "/")
+
+	return &openAIProvider{
+		name:      config.ProviderLocal,
+		client:    openai.NewClientWithConfig(withRetryAfterCapture(clientConfig)),
+		model:     cfg.LocalModel,
+		maxTokens: cfg.MaxTokensPerRequest,
+	}
+}
+
+// withRetryAfterCapture installe le transport qui relève le header Retry-After
+// (go-openai n'expose pas les headers des réponses en erreur)
+func withRetryAfterCapture(clientConfig openai.ClientConfig) openai.ClientConfig {
+	clientConfig.HTTPClient = &http.Client{Transport: &retryAfterTransport{}}
+	return clientConfig
+}
+
+func (p *openAIProvider) Name() string  { return p.name }
+func (p *openAIProvider) Model() string { return p.model }
+
+func (p *openAIProvider) WithModel(model string) TextGenerator {
+	clone := *p
+	clone.model = model
+	return &clone
+}
+
+func (p *openAIProvider) request(prompt string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: p.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		MaxTokens: p.maxTokens,
+	}
+}
+
+func (p *openAIProvider) Generate(ctx context.Context, prompt string) (*GenerationResult, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.request(prompt))
+	if err != nil {
+		return nil, fmt.Errorf("%s API error: %w", p.name, err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from %s", p.name)
+	}
+
+	return &GenerationResult{
+		Text:         resp.Choices[0].Message.Content,
+		TokensInput:  resp.Usage.PromptTokens,
+		TokensOutput: resp.Usage.CompletionTokens,
+	}, nil
+}
+
+func (p *openAIProvider) GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error) {
+	req := p.request(prompt)
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := p.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s stream error: %w", p.name, err)
+	}
+	defer stream.Close()
+
+	result := &GenerationResult{}
+	var text strings.Builder
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		if recvErr != nil {
+			return nil, fmt.Errorf("%s stream error: %w", p.name, recvErr)
+		}
+
+		if chunk.Usage != nil {
+			result.TokensInput = chunk.Usage.PromptTokens
+			result.TokensOutput = chunk.Usage.CompletionTokens
+		}
+
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			delta := chunk.Choices[0].Delta.Content
+			text.WriteString(delta)
+			onDelta(delta)
+		}
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response from %s", p.name)
+	}
+
+	result.Text = text.String()
+	return result, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+// stubProvider provider de test qui échoue ou répond un texte fixe
+type stubProvider struct {
+	name  string
+	text  string
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string  { return p.name }
+func (p *stubProvider) Model() string { return p.name + "-model" }
+
+func (p *stubProvider) Generate(ctx context.Context, prompt string) (*GenerationResult, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &GenerationResult{Text: p.text, TokensInput: 10, TokensOutput: 20}, nil
+}
+
+func (p *stubProvider) GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error) {
+	result, err := p.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	onDelta(result.Text)
+	return result, nil
+}
+
+func TestNewAIService_FakeProviderWithoutKeys(t *testing.T) {
+	cfg := &config.AIConfig{
+		PrimaryProvider:      "fake",
+		MaxRequestsPerMinute: 600,
+	}
+
+	svc, err := NewAIService(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fake"}, svc.Providers())
+}
+
+func TestFakeProvider_Deterministic(t *testing.T) {
+	svc, err := NewAIService(&config.AIConfig{PrimaryProvider: "fake", MaxRequestsPerMinute: 6000}, nil)
+	require.NoError(t, err)
+
+	prompt := "ENTREPRISE CIBLE:\n- Nom: Acme Corp\n- Secteur: Tech"
+
+	first, metrics, err := svc.GenerateText(context.Background(), prompt)
+	require.NoError(t, err)
+	assert.Contains(t, first, "Acme Corp")
+	assert.Equal(t, "fake", metrics.Provider)
+	assert.Zero(t, metrics.EstimatedCost)
+	assert.True(t, metrics.Success)
+
+	second, _, err := svc.GenerateText(context.Background(), prompt)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	// Le streaming reconstitue exactement le même texte
+	var streamed strings.Builder
+	text, _, err := svc.GenerateTextStream(context.Background(), prompt, func(delta string) {
+		streamed.WriteString(delta)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first, text)
+	assert.Equal(t, first, streamed.String())
+}
+
+func TestFakeProvider_CompanyInAnyLanguage(t *testing.T) {
+	text := fakeLetter("TARGET COMPANY:\n- Name: Globex\n- Industry: Energy")
+	assert.Contains(t, text, "Globex")
+}
+
+func TestFakeProvider_PassesQualityGate(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Globex"}
+	validator := NewLetterValidationPipeline(DefaultLetterValidators()...)
+
+	for _, lang := range models.SupportedLanguages {
+		for _, spec := range letterTypeRegistry {
+			opts := PromptOptions{JobTitle: "SRE", Language: lang}
+			text := fakeLetter(pb.BuildLetterPrompt(spec, company, opts))
+
+			report := validator.Validate(&LetterValidationInput{
+				Content:     text,
+				LetterType:  spec.Type,
+				Language:    lang,
+				CompanyName: company.Name,
+				Subject:     spec.Subject(opts),
+				Profile:     pb.userProfile,
+				Company:     company,
+			})
+			assert.True(t, report.Passed, "%s/%s: %v", lang, spec.Type, report.Findings)
+			assert.Empty(t, report.Findings, "%s/%s", lang, spec.Type)
+		}
+	}
+}
+
+func TestAIService_FallbackChainOrder(t *testing.T) {
+	failing := &stubProvider{name: "first", err: errors.New("invalid request")}
+	working := &stubProvider{name: "second", text: "Bonjour"}
+	unused := &stubProvider{name: "third", text: "Jamais"}
+
+	svc := &AIService{
+		config:          &config.AIConfig{MaxRequestsPerMinute: 6000},
+		providers:       []TextGenerator{failing, working, unused},
+		rateLimiter:     newTestRateLimiter(),
+		metricsRecorder: &DefaultMetricsRecorder{},
+	}
+
+	text, metrics, err := svc.GenerateText(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "Bonjour", text)
+	assert.Equal(t, "second", metrics.Provider)
+	assert.Equal(t, 30, metrics.TotalTokens)
+	assert.Equal(t, 1, failing.calls)
+	assert.Equal(t, 0, unused.calls)
+}
+
+func TestAIService_AllProvidersFail(t *testing.T) {
+	svc := &AIService{
+		config: &config.AIConfig{MaxRequestsPerMinute: 6000},
+		providers: []TextGenerator{
+			&stubProvider{name: "first", err: errors.New("bad request")},
+			&stubProvider{name: "second", err: errors.New("unauthorized")},
+		},
+		rateLimiter:     newTestRateLimiter(),
+		metricsRecorder: &DefaultMetricsRecorder{},
+	}
+
+	_, _, err := svc.GenerateText(context.Background(), "prompt")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all AI providers failed")
+	assert.Contains(t, err.Error(), "unauthorized")
+}
+
+func TestAIConfig_ProviderChain(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      config.AIConfig
+		expected []string
+	}{
+		{"Default", config.AIConfig{}, []string{"claude", "openai"}},
+		{"Fake without fallback", config.AIConfig{PrimaryProvider: "fake"}, []string{"fake"}},
+		{
+			"Explicit order deduplicated",
+			config.AIConfig{PrimaryProvider: "local", FallbackChain: []string{"claude", "local", " OpenAI "}},
+			[]string{"local", "claude", "openai"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.cfg.ProviderChain())
+		})
+	}
+}
+
+func TestLocalProvider_OpenAICompatibleEndpoint(t *testing.T) {
+	var receivedModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		receivedModel, _ = body["model"].(string)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": "local-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Lettre locale"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15}
+		}`))
+	}))
+	defer server.Close()
+
+	svc, err := NewAIService(&config.AIConfig{
+		PrimaryProvider:      "local",
+		LocalBaseURL:         server.URL + "/v1/",
+		LocalModel:           "llama3.1",
+		MaxRequestsPerMinute: 6000,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local"}, svc.Providers())
+
+	text, metrics, err := svc.GenerateText(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "Lettre locale", text)
+	assert.Equal(t, "llama3.1", receivedModel)
+	assert.Equal(t, "local", metrics.Provider)
+	assert.Equal(t, 15, metrics.TotalTokens)
+	assert.Zero(t, metrics.EstimatedCost)
+}
+
+func newTestRateLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Inf, 1)
+}
@@ -36,8 +36,7 @@ func TestNewAIService_ValidConfig(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, svc)
-	assert.NotNil(t, svc.claudeClient)
-	assert.NotNil(t, svc.openaiClient)
+	assert.Equal(t, []string{"claude", "openai"}, svc.Providers())
 	assert.Equal(t, "claude", svc.config.PrimaryProvider)
 }
 
@@ -68,8 +67,7 @@ func TestNewAIService_OnlyClaude(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, svc)
-	assert.NotNil(t, svc.claudeClient)
-	assert.Nil(t, svc.openaiClient)
+	assert.Equal(t, []string{"claude"}, svc.Providers())
 }
 
 // Test création service avec seulement OpenAI
@@ -85,8 +83,7 @@ func TestNewAIService_OnlyOpenAI(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, svc)
-	assert.Nil(t, svc.claudeClient)
-	assert.NotNil(t, svc.openaiClient)
+	assert.Equal(t, []string{"openai"}, svc.Providers())
 }
 
 // Test metrics recorder par défaut
@@ -188,11 +185,11 @@ func TestAIService_PrimaryProvider(t *testing.T) {
 	testCases := []struct {
 		name            string
 		primaryProvider string
-		expectClaude    bool
+		expectedChain   []string
 	}{
-		{"Claude primary", "claude", true},
-		{"OpenAI primary", "openai", false},
-		{"Invalid defaults to claude", "invalid", true},
+		{"Claude primary", "claude", []string{"claude", "openai"}},
+		{"OpenAI primary", "openai", []string{"openai"}},
+		{"Invalid defaults to claude", "invalid", []string{"claude", "openai"}},
 	}
 
 	for _, tc := range testCases {
@@ -208,6 +205,7 @@ func TestAIService_PrimaryProvider(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.Equal(t, tc.primaryProvider, svc.config.PrimaryProvider)
+			assert.Equal(t, tc.expectedChain, svc.Providers())
 		})
 	}
 }
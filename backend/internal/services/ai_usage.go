@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+// AIUsageLedger enregistre chaque appel IA dans la table ai_usage
+// Il sert de MetricsRecorder pour AIService, de SpendTracker pour les budgets
+// et alimente le rapport de dépenses de l'administration.
+type AIUsageLedger struct {
+	db     *gorm.DB
+	config *config.AIConfig
+}
+
+// NewAIUsageLedger crée une nouvelle instance du registre
+func NewAIUsageLedger(db *gorm.DB, cfg *config.AIConfig) *AIUsageLedger {
+	return &AIUsageLedger{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// RecordAIMetrics insère une ligne dans le registre (l'échec est loggé sans interrompre la génération)
+func (l *AIUsageLedger) RecordAIMetrics(metrics models.AIMetrics) {
+	if err := l.db.Create(models.NewAIUsage(metrics)).Error; err != nil {
+		log.Error().Err(err).
+			Str("provider", metrics.Provider).
+			Str("model", metrics.Model).
+			Float64("cost", metrics.EstimatedCost).
+			Msg("Failed to record AI usage")
+	}
+}
+
+// ProviderSpend retourne les dépenses d'un provider depuis since (USD)
+func (l *AIUsageLedger) ProviderSpend(ctx context.Context, provider string, since time.Time) (float64, error) {
+	var spent float64
+	err := l.db.WithContext(ctx).Model(&models.AIUsage{}).
+		Where("provider = ? AND created_at >= ?", provider, since).
+		Select("COALESCE(SUM(cost), 0)").
+		Scan(&spent).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum AI spend: %w", err)
+	}
+	return spent, nil
+}
+
+// AIUsageBucket agrégat de consommation pour une clé (jour, provider, modèle ou type de lettre)
+type AIUsageBucket struct {
+	Key         string  `json:"key"`
+	Requests    int64   `json:"requests"`
+	Failures    int64   `json:"failures"`
+	TotalTokens int64   `json:"total_tokens"`
+	Cost        float64 `json:"cost"`
+}
+
+// AIUsageReport rapport de dépenses IA sur une période [From, To)
+type AIUsageReport struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Total        AIUsageBucket    `json:"total"`
+	ByDay        []AIUsageBucket  `json:"by_day"`
+	ByProvider   []AIUsageBucket  `json:"by_provider"`
+	ByModel      []AIUsageBucket  `json:"by_model"`
+	ByLetterType []AIUsageBucket  `json:"by_letter_type"`
+	Budgets      []AIBudgetStatus `json:"budgets"`
+}
+
+// usageGroupings expressions SQL de regroupement du rapport
+var usageGroupings = map[string]string{
+	"day":         "TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')",
+	"provider":    "provider",
+	"model":       "model",
+	"letter_type": "COALESCE(NULLIF(letter_type, ''), 'other')",
+}
+
+// Report agrège les dépenses de la période et l'état des budgets configurés
+func (l *AIUsageLedger) Report(ctx context.Context, from, to time.Time) (*AIUsageReport, error) {
+	report := &AIUsageReport{From: from, To: to}
+
+	totals, err := l.aggregate(ctx, "'total'", from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Total = AIUsageBucket{Key: "total"}
+	if len(totals) > 0 {
+		report.Total = totals[0]
+	}
+
+	groups := []struct {
+		name   string
+		target *[]AIUsageBucket
+	}{
+		{"day", &report.ByDay},
+		{"provider", &report.ByProvider},
+		{"model", &report.ByModel},
+		{"letter_type", &report.ByLetterType},
+	}
+	for _, group := range groups {
+		buckets, err := l.aggregate(ctx, usageGroupings[group.name], from, to)
+		if err != nil {
+			return nil, err
+		}
+		*group.target = buckets
+	}
+
+	// Jours en ordre chronologique, autres regroupements par coût décroissant
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Key < report.ByDay[j].Key })
+
+	report.Budgets, err = l.BudgetStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// BudgetStatuses retourne l'état des budgets configurés, par provider
+func (l *AIUsageLedger) BudgetStatuses(ctx context.Context) ([]AIBudgetStatus, error) {
+	providers := make([]string, 0, len(l.config.Budgets))
+	for provider := range l.config.Budgets {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	statuses := make([]AIBudgetStatus, 0, len(providers))
+	now := time.Now()
+	for _, provider := range providers {
+		status, err := budgetStatus(ctx, l, provider, l.config.BudgetFor(provider), now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// aggregate regroupe la consommation de la période selon une expression SQL
+func (l *AIUsageLedger) aggregate(ctx context.Context, keyExpr string, from, to time.Time) ([]AIUsageBucket, error) {
+	buckets := []AIUsageBucket{}
+	err := l.db.WithContext(ctx).Model(&models.AIUsage{}).
+		Select(keyExpr+` AS key,
+			COUNT(*) AS requests,
+			COALESCE(SUM(CASE WHEN success THEN 0 ELSE 1 END), 0) AS failures,
+			COALESCE(SUM(total_tokens), 0) AS total_tokens,
+			COALESCE(SUM(cost), 0) AS cost`).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("1").
+		Order("cost DESC").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate AI usage: %w", err)
+	}
+	return buckets, nil
+}
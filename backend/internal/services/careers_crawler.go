@@ -0,0 +1,532 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/models"
+)
+
+// Parcours borné des pages carrières d'un site
+const (
+	careersCrawlTimeout   = 30 * time.Second // Durée max d'un parcours complet
+	careersRequestTimeout = 10 * time.Second
+	maxCareersPages       = 12      // Pages du site consultées (accueil, sitemap et pages carrières inclus)
+	maxCareersDepth       = 2       // Page carrières (1) puis pages d'offre liées (2)
+	maxCareersBoards      = 3       // Appels aux API des ATS (Lever, Greenhouse)
+	maxCareersOpenings    = 100     // Offres conservées par entreprise
+	maxCareersBodySize    = 2 << 20 // Octets lus par réponse
+	maxSitemapCareersURLs = 5       // Pages carrières reprises du sitemap
+)
+
+// careersPaths chemins usuels des pages carrières, essayés après les liens trouvés
+var careersPaths = []string{"/careers", "/jobs", "/carrieres", "/recrutement", "/join-us", "/en/careers", "/fr/carrieres"}
+
+// API publiques des ATS (remplacées dans les tests)
+var (
+	leverAPIBaseURL      = "https://api.lever.co"
+	greenhouseAPIBaseURL = "https://boards-api.greenhouse.io"
+)
+
+var (
+	leverBoardPattern      = regexp.MustCompile(`(?i)(?:jobs\.(?:eu\.)?lever\.co/|api\.lever\.co/v0/postings/)([a-z0-9][a-z0-9_-]*)`)
+	greenhouseBoardPattern = regexp.MustCompile(`(?i)(?:job-)?boards(?:-api)?(?:\.eu)?\.greenhouse\.io/(?:embed/job_board(?:/js)?\?for=|v1/boards/)?([a-z0-9][a-z0-9_-]*)`)
+	wttjJobPattern         = regexp.MustCompile(`(?i)^https?://(?:www\.)?welcometothejungle\.com/[a-z]{2}/companies/[a-z0-9_-]+/jobs/[a-z0-9_-]+`)
+)
+
+// CareersCrawl résultat du parcours des pages carrières
+type CareersCrawl struct {
+	SiteURL  string
+	Openings []models.CompanyOpening // CompanyID non renseigné
+	Pages    []string                // Pages du site où des offres ont été trouvées
+	Requests int
+}
+
+// careersCrawler état d'un parcours (non partagé entre goroutines)
+type careersCrawler struct {
+	scraper *CompanyScraper
+	client  *http.Client
+	host    string // Hôte du site après redirections
+
+	requests int
+	queued   map[string]bool
+	queue    []careersPage
+	boards   map[string]bool // "lever:acme", "greenhouse:acme"
+	openings map[string]models.CompanyOpening
+	order    []string // URLs des offres dans l'ordre de découverte
+	pages    []string
+}
+
+type careersPage struct {
+	url   string
+	depth int
+}
+
+// CrawlCareers parcourt les pages carrières d'un domaine et en extrait les offres ouvertes
+// Découverte : liens de l'accueil, sitemap.xml, chemins usuels ; extraction : JSON-LD
+// JobPosting, API publiques Lever/Greenhouse, liens d'offres Welcome to the Jungle.
+func (s *CompanyScraper) CrawlCareers(ctx context.Context, domain string) (*CareersCrawl, error) {
+	ctx, cancel := context.WithTimeout(ctx, careersCrawlTimeout)
+	defer cancel()
+
+	var lastErr error
+	for _, siteURL := range []string{"https://www." + domain, "https://" + domain} {
+		crawl, err := s.crawlCareersSite(ctx, siteURL)
+		if err == nil {
+			return crawl, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// crawlCareersSite parcours depuis la page d'accueil siteURL
+func (s *CompanyScraper) crawlCareersSite(ctx context.Context, siteURL string) (*CareersCrawl, error) {
+	c := &careersCrawler{
+		scraper:  s,
+		queued:   map[string]bool{},
+		boards:   map[string]bool{},
+		openings: map[string]models.CompanyOpening{},
+	}
+	c.client = &http.Client{
+		Timeout:       min(s.config.Timeout, careersRequestTimeout),
+		CheckRedirect: s.checkPublicRedirect,
+	}
+
+	homepage, finalURL, err := c.fetchSitePage(ctx, siteURL)
+	if err != nil {
+		return nil, err
+	}
+	c.host = finalURL.Host
+	c.extractPage(finalURL, homepage, 0)
+
+	// Pages carrières du sitemap puis chemins usuels (les liens de l'accueil passent en premier)
+	for _, pageURL := range c.sitemapCareersURLs(ctx, finalURL) {
+		c.enqueue(pageURL, 1)
+	}
+	for _, path := range careersPaths {
+		c.enqueue(finalURL.ResolveReference(&url.URL{Path: path}).String(), 1)
+	}
+
+	for len(c.queue) > 0 && c.requests < maxCareersPages && ctx.Err() == nil {
+		page := c.queue[0]
+		c.queue = c.queue[1:]
+		body, pageURL, err := c.fetchSitePage(ctx, page.url)
+		if err != nil {
+			log.Debug().Err(err).Str("url", page.url).Msg("Careers page not crawled")
+			continue
+		}
+		c.extractPage(pageURL, body, page.depth)
+	}
+
+	c.fetchBoards(ctx)
+
+	crawl := &CareersCrawl{SiteURL: finalURL.String(), Pages: c.pages, Requests: c.requests}
+	for _, openingURL := range c.order {
+		crawl.Openings = append(crawl.Openings, c.openings[openingURL])
+	}
+
+	log.Info().
+		Str("site", crawl.SiteURL).
+		Int("openings", len(crawl.Openings)).
+		Int("requests", crawl.Requests).
+		Msg("Careers pages crawled")
+	return crawl, nil
+}
+
+// enqueue ajoute une page du site à parcourir (même hôte, une seule fois)
+func (c *careersCrawler) enqueue(rawURL string, depth int) {
+	if depth > maxCareersDepth {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != c.host || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	u.Fragment = ""
+	key := strings.TrimSuffix(u.Host+u.Path, "/") + "?" + u.RawQuery
+	if c.queued[key] {
+		return
+	}
+	c.queued[key] = true
+	c.queue = append(c.queue, careersPage{url: u.String(), depth: depth})
+}
+
+// extractPage relève les offres, les ATS et les liens vers d'autres pages carrières
+func (c *careersCrawler) extractPage(pageURL *url.URL, body []byte, depth int) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	found := len(c.order)
+
+	// Données structurées schema.org
+	doc.Find("script[type='application/ld+json']").Each(func(_ int, script *goquery.Selection) {
+		for _, ld := range findJSONLDJobPostings([]byte(script.Text())) {
+			c.addOpening(jsonLDOpening(ld, pageURL))
+		}
+	})
+
+	// Tableaux d'offres des ATS intégrés à la page (liens, scripts, iframes)
+	page := string(body)
+	for _, match := range leverBoardPattern.FindAllStringSubmatch(page, -1) {
+		c.boards[models.OpeningSourceLever+":"+strings.ToLower(match[1])] = true
+	}
+	for _, match := range greenhouseBoardPattern.FindAllStringSubmatch(page, -1) {
+		if token := strings.ToLower(match[1]); token != "embed" {
+			c.boards[models.OpeningSourceGreenhouse+":"+token] = true
+		}
+	}
+
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, err := pageURL.Parse(strings.TrimSpace(a.AttrOr("href", "")))
+		if err != nil {
+			return
+		}
+		text := strings.Join(strings.Fields(a.Text()), " ")
+
+		if wttjJobPattern.MatchString(href.String()) && text != "" {
+			c.addOpening(models.CompanyOpening{Title: text, URL: href.String(), Source: models.OpeningSourceWTTJ})
+			return
+		}
+		if href.Host != c.host {
+			return
+		}
+		switch {
+		case depth == 0 && (careersLinkPattern.MatchString(href.Path) || careersLinkPattern.MatchString(text)):
+			c.enqueue(href.String(), 1)
+		case depth >= 1 && strings.HasPrefix(href.Path, strings.TrimSuffix(pageURL.Path, "/")+"/"):
+			// Page d'offre sous la page carrières ("/careers/backend-engineer")
+			c.enqueue(href.String(), depth+1)
+		}
+	})
+
+	if len(c.order) > found {
+		c.pages = append(c.pages, pageURL.String())
+	}
+}
+
+// addOpening enregistre une offre (dédupliquée par URL, premières données conservées)
+func (c *careersCrawler) addOpening(opening models.CompanyOpening) {
+	opening.Title = truncateRunes(strings.TrimSpace(opening.Title), 255)
+	opening.Location = truncateRunes(strings.TrimSpace(opening.Location), 255)
+	opening.Department = truncateRunes(strings.TrimSpace(opening.Department), 255)
+	opening.EmploymentType = truncateRunes(strings.TrimSpace(opening.EmploymentType), 100)
+	if opening.Title == "" || opening.URL == "" || len(opening.URL) > 2000 || len(c.order) >= maxCareersOpenings {
+		return
+	}
+	if _, exists := c.openings[opening.URL]; exists {
+		return
+	}
+	c.openings[opening.URL] = opening
+	c.order = append(c.order, opening.URL)
+}
+
+// sitemapCareersURLs pages carrières listées dans sitemap.xml (index de sitemaps : premier niveau)
+func (c *careersCrawler) sitemapCareersURLs(ctx context.Context, siteURL *url.URL) []string {
+	body, _, err := c.fetchSitePage(ctx, siteURL.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String())
+	if err != nil {
+		return nil
+	}
+
+	var sitemap struct {
+		URLs []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(body, &sitemap); err != nil {
+		return nil
+	}
+
+	var careers []string
+	for _, entry := range sitemap.URLs {
+		loc, err := url.Parse(strings.TrimSpace(entry.Loc))
+		if err != nil || !careersLinkPattern.MatchString(loc.Path) {
+			continue
+		}
+		careers = append(careers, loc.String())
+		if len(careers) >= maxSitemapCareersURLs {
+			break
+		}
+	}
+	// Sitemap dédié aux offres ("sitemap-jobs.xml") : ses pages sont des pages d'offre
+	for _, entry := range sitemap.Sitemaps {
+		loc, err := url.Parse(strings.TrimSpace(entry.Loc))
+		if err == nil && careersLinkPattern.MatchString(loc.Path) {
+			careers = append(careers, c.sitemapCareersURLs(ctx, loc)...)
+			break
+		}
+	}
+	return careers
+}
+
+// fetchSitePage récupère une page du site (hôte public, 200, taille bornée) et son URL finale
+func (c *careersCrawler) fetchSitePage(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	if c.requests >= maxCareersPages {
+		return nil, nil, fmt.Errorf("careers crawl budget exhausted")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.scraper.checkPublicURL(u); err != nil {
+		return nil, nil, err
+	}
+	c.requests++
+	return c.get(ctx, u.String())
+}
+
+// fetchBoards interroge les API publiques des ATS détectés
+func (c *careersCrawler) fetchBoards(ctx context.Context) {
+	fetched := 0
+	for board := range c.boards {
+		if fetched >= maxCareersBoards || ctx.Err() != nil {
+			return
+		}
+		fetched++
+
+		source, token, _ := strings.Cut(board, ":")
+		var err error
+		switch source {
+		case models.OpeningSourceLever:
+			err = c.fetchLeverBoard(ctx, token)
+		case models.OpeningSourceGreenhouse:
+			err = c.fetchGreenhouseBoard(ctx, token)
+		}
+		if err != nil {
+			log.Debug().Err(err).Str("board", board).Msg("ATS job board not fetched")
+		}
+	}
+}
+
+func (c *careersCrawler) fetchLeverBoard(ctx context.Context, company string) error {
+	body, _, err := c.get(ctx, fmt.Sprintf("%s/v0/postings/%s?mode=json", leverAPIBaseURL, url.PathEscape(company)))
+	if err != nil {
+		return err
+	}
+
+	var postings []struct {
+		Text       string `json:"text"`
+		HostedURL  string `json:"hostedUrl"`
+		CreatedAt  int64  `json:"createdAt"` // Millisecondes
+		Categories struct {
+			Location   string `json:"location"`
+			Team       string `json:"team"`
+			Commitment string `json:"commitment"`
+		} `json:"categories"`
+	}
+	if err := json.Unmarshal(body, &postings); err != nil {
+		return fmt.Errorf("invalid lever response: %w", err)
+	}
+
+	for _, posting := range postings {
+		opening := models.CompanyOpening{
+			Title:          posting.Text,
+			URL:            posting.HostedURL,
+			Location:       posting.Categories.Location,
+			Department:     posting.Categories.Team,
+			EmploymentType: posting.Categories.Commitment,
+			Source:         models.OpeningSourceLever,
+		}
+		if posting.CreatedAt > 0 {
+			postedAt := time.UnixMilli(posting.CreatedAt).UTC()
+			opening.PostedAt = &postedAt
+		}
+		c.addOpening(opening)
+	}
+	return nil
+}
+
+func (c *careersCrawler) fetchGreenhouseBoard(ctx context.Context, token string) error {
+	body, _, err := c.get(ctx, fmt.Sprintf("%s/v1/boards/%s/jobs", greenhouseAPIBaseURL, url.PathEscape(token)))
+	if err != nil {
+		return err
+	}
+
+	var board struct {
+		Jobs []struct {
+			Title       string `json:"title"`
+			AbsoluteURL string `json:"absolute_url"`
+			UpdatedAt   string `json:"updated_at"`
+			Location    struct {
+				Name string `json:"name"`
+			} `json:"location"`
+		} `json:"jobs"`
+	}
+	if err := json.Unmarshal(body, &board); err != nil {
+		return fmt.Errorf("invalid greenhouse response: %w", err)
+	}
+
+	for _, job := range board.Jobs {
+		c.addOpening(models.CompanyOpening{
+			Title:    job.Title,
+			URL:      job.AbsoluteURL,
+			Location: job.Location.Name,
+			Source:   models.OpeningSourceGreenhouse,
+			PostedAt: parseOpeningDate(job.UpdatedAt),
+		})
+	}
+	return nil
+}
+
+// get requête GET bornée en taille ; seules les réponses 200 sont exploitées
+func (c *careersCrawler) get(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", c.scraper.config.UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s returned %d", rawURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCareersBodySize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Request.URL, nil
+}
+
+// jsonLDOpening offre à partir d'un JobPosting schema.org (URL de la page à défaut)
+func jsonLDOpening(ld *jsonLDJobPosting, pageURL *url.URL) models.CompanyOpening {
+	opening := models.CompanyOpening{
+		Title:          html.UnescapeString(htmlToText(ld.Title)), // Entités courantes dans les titres JSON-LD
+		URL:            pageURL.String(),
+		Location:       jsonLDLocation(ld.JobLocation),
+		EmploymentType: strings.Join(jsonLDStrings(ld.EmploymentType), ", "),
+		Source:         models.OpeningSourceJSONLD,
+		PostedAt:       parseOpeningDate(ld.DatePosted),
+	}
+	if ld.URL != "" {
+		if u, err := pageURL.Parse(ld.URL); err == nil {
+			opening.URL = u.String()
+		}
+	}
+	if opening.Location == "" && strings.EqualFold(ld.JobLocationType, "TELECOMMUTE") {
+		opening.Location = "Remote"
+	}
+	return opening
+}
+
+// jsonLDLocation "Paris, FR" à partir d'un Place (ou d'une liste de Place)
+func jsonLDLocation(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	type place struct {
+		Name    string          `json:"name"`
+		Address json.RawMessage `json:"address"`
+	}
+	var places []place
+	if err := json.Unmarshal(raw, &places); err != nil {
+		var single place
+		if err := json.Unmarshal(raw, &single); err != nil {
+			return ""
+		}
+		places = []place{single}
+	}
+
+	var locations []string
+	for _, p := range places {
+		var address struct {
+			Locality json.RawMessage `json:"addressLocality"`
+			Region   json.RawMessage `json:"addressRegion"`
+			Country  json.RawMessage `json:"addressCountry"`
+		}
+		location := ""
+		var text string
+		if json.Unmarshal(p.Address, &text) == nil {
+			location = text
+		} else if json.Unmarshal(p.Address, &address) == nil {
+			var parts []string
+			for _, part := range []json.RawMessage{address.Locality, address.Country} {
+				if value := jsonLDName(part); value != "" {
+					parts = append(parts, value)
+				}
+			}
+			if len(parts) < 2 {
+				if region := jsonLDName(address.Region); region != "" {
+					parts = append(parts, region)
+				}
+			}
+			location = strings.Join(parts, ", ")
+		}
+		if location == "" {
+			location = p.Name
+		}
+		if location != "" {
+			locations = append(locations, location)
+		}
+	}
+	return strings.Join(uniqueStrings(locations), " / ")
+}
+
+// jsonLDName valeur textuelle ou objet nommé ({"@type": "Country", "name": "FR"})
+func jsonLDName(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var named struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &named) == nil {
+		return strings.TrimSpace(named.Name)
+	}
+	return ""
+}
+
+// jsonLDStrings chaîne ou liste de chaînes
+func jsonLDStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// parseOpeningDate date ISO 8601 (date seule ou horodatage), nil si absente ou invalide
+func parseOpeningDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
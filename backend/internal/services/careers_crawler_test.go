@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+// newCareersSite site d'entreprise avec page carrières, sitemap et tableaux Lever/Greenhouse
+func newCareersSite(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var site *httptest.Server
+
+	mux.HandleFunc("/v0/postings/acme", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "json", r.URL.Query().Get("mode"))
+		fmt.Fprint(w, `[{"text":"Product Designer","hostedUrl":"https://jobs.lever.co/acme/11","createdAt":1760000000000,
+			"categories":{"location":"Lyon","team":"Design","commitment":"CDI"}}]`)
+	})
+	mux.HandleFunc("/v1/boards/acme/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jobs":[{"title":"Site Reliability Engineer","absolute_url":"https://boards.greenhouse.io/acme/jobs/42",
+			"updated_at":"2026-10-01T09:00:00-04:00","location":{"name":"Remote - Europe"}}]}`)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc>%[1]s/produit</loc></url><url><loc>%[1]s/jobs/data-engineer</loc></url></urlset>`, site.URL)
+	})
+	mux.HandleFunc("/carrieres", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+			{"@type":"Organization","name":"Acme"},
+			{"@type":"JobPosting","title":"Head of Sales","url":"/carrieres/head-of-sales","datePosted":"2026-09-15",
+			 "employmentType":["FULL_TIME","CONTRACTOR"],"jobLocation":{"@type":"Place","address":"Bordeaux"}}
+		]}</script></head><body>
+			<a href="/carrieres/backend-engineer">Backend Engineer</a>
+			<a href="/carrieres/head-of-sales">Head of Sales</a>
+			<a href="https://jobs.lever.co/acme">Toutes nos offres</a>
+			<a href="https://www.welcometothejungle.com/fr/companies/acme/jobs/office-manager_paris">Office Manager</a>
+			<a href="/blog">Blog</a>
+		</body></html>`)
+	})
+	mux.HandleFunc("/carrieres/backend-engineer", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><script type="application/ld+json">{"@type":"JobPosting","title":"Backend Engineer &amp; Go",
+			"jobLocation":{"@type":"Place","address":{"addressLocality":"Paris","addressCountry":{"@type":"Country","name":"FR"}}}}
+		</script></head></html>`)
+	})
+	mux.HandleFunc("/jobs/data-engineer", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script type="application/ld+json">[{"@type":"JobPosting","title":"Data Engineer","jobLocationType":"TELECOMMUTE"}]</script>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<html><body><a href="/carrieres">Nous rejoindre</a><a href="/produit">Produit</a>
+			<script src="https://boards.greenhouse.io/embed/job_board/js?for=acme"></script></body></html>`)
+	})
+
+	site = httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	previousLever, previousGreenhouse := leverAPIBaseURL, greenhouseAPIBaseURL
+	leverAPIBaseURL, greenhouseAPIBaseURL = site.URL, site.URL
+	t.Cleanup(func() { leverAPIBaseURL, greenhouseAPIBaseURL = previousLever, previousGreenhouse })
+	return site
+}
+
+func TestCrawlCareersSite_ExtractsOpenings(t *testing.T) {
+	site := newCareersSite(t)
+	scraper := newTestJobPostingScraper()
+
+	crawl, err := scraper.crawlCareersSite(t.Context(), site.URL)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, crawl.Requests, maxCareersPages)
+
+	byTitle := make(map[string]models.CompanyOpening)
+	for _, opening := range crawl.Openings {
+		byTitle[opening.Title] = opening
+	}
+	require.Len(t, byTitle, 6, "%+v", crawl.Openings)
+
+	sales := byTitle["Head of Sales"]
+	assert.Equal(t, site.URL+"/carrieres/head-of-sales", sales.URL)
+	assert.Equal(t, "Bordeaux", sales.Location)
+	assert.Equal(t, "FULL_TIME, CONTRACTOR", sales.EmploymentType)
+	assert.Equal(t, models.OpeningSourceJSONLD, sales.Source)
+	require.NotNil(t, sales.PostedAt)
+	assert.Equal(t, "2026-09-15", sales.PostedAt.Format("2006-01-02"))
+
+	backend := byTitle["Backend Engineer & Go"]
+	assert.Equal(t, site.URL+"/carrieres/backend-engineer", backend.URL, "page URL when the posting has none")
+	assert.Equal(t, "Paris, FR", backend.Location)
+
+	assert.Equal(t, "Remote", byTitle["Data Engineer"].Location)
+
+	designer := byTitle["Product Designer"]
+	assert.Equal(t, models.OpeningSourceLever, designer.Source)
+	assert.Equal(t, "Lyon", designer.Location)
+	assert.Equal(t, "Design", designer.Department)
+	assert.Equal(t, "CDI", designer.EmploymentType)
+
+	sre := byTitle["Site Reliability Engineer"]
+	assert.Equal(t, models.OpeningSourceGreenhouse, sre.Source)
+	assert.Equal(t, "https://boards.greenhouse.io/acme/jobs/42", sre.URL)
+
+	office := byTitle["Office Manager"]
+	assert.Equal(t, models.OpeningSourceWTTJ, office.Source)
+
+	assert.Contains(t, crawl.Pages, site.URL+"/carrieres")
+	assert.NotContains(t, crawl.Pages, site.URL+"/")
+}
+
+func TestCrawlCareersSite_PrivateHostsRejected(t *testing.T) {
+	site := newCareersSite(t)
+	scraper := newTestJobPostingScraper()
+	scraper.allowPrivateHosts = false
+
+	_, err := scraper.crawlCareersSite(t.Context(), site.URL)
+	assert.Error(t, err)
+}
+
+func TestJSONLDLocation(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected string
+	}{
+		{`{"address":{"addressLocality":"Paris","addressCountry":"FR"}}`, "Paris, FR"},
+		{`[{"address":{"addressLocality":"Paris"}},{"address":{"addressLocality":"Nantes","addressRegion":"Pays de la Loire"}}]`, "Paris / Nantes, Pays de la Loire"},
+		{`{"name":"Siège"}`, "Siège"},
+		{`"not a place"`, ""},
+		{``, ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, jsonLDLocation([]byte(tc.raw)), tc.raw)
+	}
+}
+
+// fakeCareersCrawler parcours simulé (offres ou erreur fixées par le test)
+type fakeCareersCrawler struct {
+	openings []models.CompanyOpening
+	err      error
+	calls    atomic.Int32
+	domain   string
+	release  chan struct{} // Si défini, le parcours attend sa fermeture
+}
+
+func (f *fakeCareersCrawler) CrawlCareers(ctx context.Context, domain string) (*CareersCrawl, error) {
+	f.calls.Add(1)
+	f.domain = domain
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &CareersCrawl{Openings: f.openings}, nil
+}
+
+func newTestOpeningsService(t *testing.T) (*CompanyOpeningsService, *fakeCareersCrawler) {
+	companies := newTestCompanyService(t)
+	require.NoError(t, companies.db.AutoMigrate(&models.CompanyOpening{}))
+	crawler := &fakeCareersCrawler{}
+	return NewCompanyOpeningsService(companies.db, companies, crawler), crawler
+}
+
+func TestCompanyOpeningsService_GetCompanyOpenings(t *testing.T) {
+	service, crawler := newTestOpeningsService(t)
+	ctx := t.Context()
+
+	// Entreprise inconnue : ni création, ni parcours
+	_, err := service.GetCompanyOpenings(ctx, "Acme SAS", false)
+	assert.ErrorIs(t, err, ErrCompanyNotFound)
+	assert.Equal(t, int32(0), crawler.calls.Load())
+	resolution, err := service.companies.ResolveCompany(ctx, "Acme SAS")
+	require.NoError(t, err)
+	assert.Nil(t, resolution.Company)
+
+	_, err = service.companies.ResolveOrCreateCompany(ctx, "Acme SAS")
+	require.NoError(t, err)
+	crawler.openings = []models.CompanyOpening{
+		{Title: "Data Engineer", URL: "https://acme.com/jobs/1", Source: models.OpeningSourceJSONLD},
+		{Title: "Backend Engineer", URL: "https://acme.com/jobs/2", Source: models.OpeningSourceJSONLD},
+	}
+	result, err := service.GetCompanyOpenings(ctx, "Acme SAS", false)
+	require.NoError(t, err)
+	assert.Equal(t, "acme.com", crawler.domain)
+	require.Len(t, result.Openings, 2)
+	assert.Equal(t, "Backend Engineer", result.Openings[0].Title)
+	require.NotNil(t, result.CrawledAt)
+	firstSeen := result.Openings[1].FirstSeenAt
+
+	// Offres fraîches : pas de nouveau parcours, même forcé
+	_, err = service.GetCompanyOpenings(ctx, "acme", true)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), crawler.calls.Load())
+
+	// Parcours périmé : offre disparue supprimée, offre connue conservée
+	require.NoError(t, service.db.Model(&models.Company{}).Where("id = ?", result.Company.ID).
+		Update("openings_crawled_at", time.Now().Add(-2*companyOpeningsTTL)).Error)
+	crawler.openings = []models.CompanyOpening{
+		{Title: "Data Engineer", URL: "https://acme.com/jobs/1", Source: models.OpeningSourceJSONLD},
+		{Title: "Data Engineer", URL: "https://acme.com/jobs/1", Source: models.OpeningSourceJSONLD},
+	}
+	result, err = service.GetCompanyOpenings(ctx, "Acme", false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), crawler.calls.Load())
+	require.Len(t, result.Openings, 1)
+	assert.WithinDuration(t, firstSeen, result.Openings[0].FirstSeenAt, time.Millisecond)
+	assert.True(t, result.Openings[0].LastSeenAt.After(firstSeen))
+	assert.False(t, result.Stale)
+
+	// Échec du parcours : offres précédentes servies
+	require.NoError(t, service.db.Model(&models.Company{}).Where("id = ?", result.Company.ID).
+		Update("openings_crawled_at", time.Now().Add(-2*companyOpeningsTTL)).Error)
+	crawler.err = errors.New("connection refused")
+	result, err = service.GetCompanyOpenings(ctx, "Acme", false)
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.Len(t, result.Openings, 1)
+
+	// Aucun parcours réussi
+	_, err = service.companies.ResolveOrCreateCompany(ctx, "Globex")
+	require.NoError(t, err)
+	_, err = service.GetCompanyOpenings(ctx, "Globex", false)
+	assert.ErrorIs(t, err, ErrOpeningsUnavailable)
+}
+
+func TestCompanyOpeningsService_CrawlOutlivesCancelledRequest(t *testing.T) {
+	service, crawler := newTestOpeningsService(t)
+	_, err := service.companies.ResolveOrCreateCompany(t.Context(), "Acme")
+	require.NoError(t, err)
+
+	crawler.openings = []models.CompanyOpening{
+		{Title: "Data Engineer", URL: "https://acme.com/jobs/1", Source: models.OpeningSourceJSONLD},
+	}
+	crawler.release = make(chan struct{})
+
+	// La requête qui lance le parcours est annulée avant sa fin
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		_, err := service.GetCompanyOpenings(ctx, "Acme", false)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return crawler.calls.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// Le parcours partagé se poursuit et profite aux demandes suivantes
+	close(crawler.release)
+	require.Eventually(t, func() bool {
+		result, err := service.GetCompanyOpenings(t.Context(), "Acme", false)
+		return err == nil && len(result.Openings) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), crawler.calls.Load())
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/metrics"
+)
+
+// CircuitState état d'un circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Appels autorisés
+	CircuitOpen     CircuitState = "open"      // Appels refusés jusqu'à la fin du délai d'ouverture
+	CircuitHalfOpen CircuitState = "half_open" // Un appel d'essai autorisé
+)
+
+// ErrCircuitOpen : provider ignoré car son circuit breaker est ouvert
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Valeurs par défaut du circuit breaker des providers
+const (
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenDuration     = 30 * time.Second
+)
+
+// gaugeValue valeur Prometheus de l'état (0=closed, 1=half_open, 2=open)
+func (s CircuitState) gaugeValue() float64 {
+	switch s {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	}
+	return 0
+}
+
+// CircuitBreaker coupe un provider après des échecs consécutifs
+// Ouvert, le provider est ignoré (bascule immédiate sur le suivant de la chaîne) ;
+// après openDuration, un seul appel d'essai décide de la fermeture ou de la réouverture.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	name         string
+	threshold    int
+	openDuration time.Duration
+	now          func() time.Time
+
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool // Appel d'essai en cours (half-open)
+}
+
+// NewCircuitBreaker crée un circuit breaker fermé (valeurs par défaut si threshold/openDuration <= 0)
+func NewCircuitBreaker(name string, threshold int, openDuration time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultCircuitFailureThreshold
+	}
+	if openDuration <= 0 {
+		openDuration = DefaultCircuitOpenDuration
+	}
+
+	cb := &CircuitBreaker{
+		name:         name,
+		threshold:    threshold,
+		openDuration: openDuration,
+		now:          time.Now,
+		state:        CircuitClosed,
+	}
+	metrics.SetAICircuitState(name, CircuitClosed.gaugeValue())
+	return cb
+}
+
+// Allow indique si un appel peut être tenté
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openDuration {
+			return false
+		}
+		cb.transition(CircuitHalfOpen)
+		cb.probing = true
+		return true
+	case CircuitHalfOpen:
+		// Un seul appel d'essai à la fois
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	}
+	return true
+}
+
+// Success enregistre un appel réussi (ferme le circuit)
+func (cb *CircuitBreaker) Success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.probing = false
+	if cb.state != CircuitClosed {
+		cb.transition(CircuitClosed)
+	}
+}
+
+// Failure enregistre un échec (ouvre le circuit au seuil, ou immédiatement en half-open)
+func (cb *CircuitBreaker) Failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.openedAt = cb.now()
+		if cb.state != CircuitOpen {
+			cb.transition(CircuitOpen)
+		}
+	}
+}
+
+// Release libère l'appel d'essai sans conclure (erreur sans lien avec la santé du provider)
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+// State retourne l'état courant (un circuit ouvert dont le délai est écoulé est signalé half-open)
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.openDuration {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+// transition change d'état (mutex détenu)
+func (cb *CircuitBreaker) transition(state CircuitState) {
+	log.Warn().
+		Str("provider", cb.name).
+		Str("from", string(cb.state)).
+		Str("to", string(state)).
+		Int("failures", cb.failures).
+		Msg("AI provider circuit breaker state changed")
+
+	cb.state = state
+	metrics.SetAICircuitState(cb.name, state.gaugeValue())
+}
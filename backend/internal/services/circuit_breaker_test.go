@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker("test", 3, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	// Fermé : les échecs sous le seuil n'ouvrent pas, un succès remet à zéro
+	cb.Failure()
+	cb.Failure()
+	cb.Success()
+	cb.Failure()
+	cb.Failure()
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+
+	// Seuil atteint : ouvert
+	cb.Failure()
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// Délai écoulé : un seul appel d'essai
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.True(t, cb.Allow())
+	assert.False(t, cb.Allow())
+
+	// Essai en échec : réouverture immédiate pour un nouveau délai
+	cb.Failure()
+	assert.Equal(t, CircuitOpen, cb.State())
+	now = now.Add(30 * time.Second)
+	assert.False(t, cb.Allow())
+
+	// Essai réussi : fermé
+	now = now.Add(30 * time.Second)
+	assert.True(t, cb.Allow())
+	cb.Success()
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+}
+
+func TestCircuitBreaker_ReleaseProbe(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker("test-release", 1, time.Second)
+	cb.now = func() time.Time { return now }
+
+	cb.Failure()
+	now = now.Add(time.Second)
+	assert.True(t, cb.Allow())
+
+	// Essai sans conclusion (requête invalide) : un nouvel essai est permis
+	cb.Release()
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.True(t, cb.Allow())
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	cb := NewCircuitBreaker("test-defaults", 0, 0)
+	assert.Equal(t, DefaultCircuitFailureThreshold, cb.threshold)
+	assert.Equal(t, DefaultCircuitOpenDuration, cb.openDuration)
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+// Provenances issues de la base entreprises : fiche curée, domaine confirmé par l'utilisateur
+const (
+	CompanySourceCurated   = "curated"
+	CompanySourceConfirmed = "confirmed"
+)
+
+// ErrCompanyNotFound fiche entreprise inconnue
+var ErrCompanyNotFound = errors.New("company not found")
+
+// ErrCompanyExists nom ou alias déjà utilisé par une autre fiche
+var ErrCompanyExists = errors.New("company name or alias already in use")
+
+// ErrInvalidCompany fiche entreprise invalide
+var ErrInvalidCompany = errors.New("invalid company")
+
+// CompanyInput contenu d'une fiche entreprise
+type CompanyInput struct {
+	Name         string
+	Domain       string
+	Description  string
+	Industry     string
+	Size         string
+	Technologies []string
+	Values       []string
+	Culture      string
+	Notes        string
+	Aliases      []string
+}
+
+// CompanyUpdate modification partielle d'une fiche (nil = inchangé)
+type CompanyUpdate struct {
+	Name         *string
+	Domain       *string
+	Description  *string
+	Industry     *string
+	Size         *string
+	Technologies *[]string
+	Values       *[]string
+	Culture      *string
+	Notes        *string
+	Aliases      *[]string
+}
+
+// CompanyService base des entreprises : résolution des noms et fiches curées par l'administrateur
+type CompanyService struct {
+	db *gorm.DB
+}
+
+// NewCompanyService crée le service des fiches entreprises
+func NewCompanyService(db *gorm.DB) *CompanyService {
+	return &CompanyService{db: db}
+}
+
+// CreateCompany crée une fiche curée ; le nom et les alias ne doivent appartenir à aucune autre entreprise
+func (s *CompanyService) CreateCompany(ctx context.Context, input CompanyInput) (*models.Company, error) {
+	company := &models.Company{ID: uuid.New(), Curated: true}
+	applyCompanyInput(company, input)
+	company.DomainConfirmed = company.Domain != ""
+	if company.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCompany)
+	}
+
+	if err := s.saveCompany(ctx, company, true); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// ListCompanies liste les fiches par nom
+func (s *CompanyService) ListCompanies(ctx context.Context) ([]models.Company, error) {
+	var companies []models.Company
+	if err := s.db.WithContext(ctx).Order("name ASC").Find(&companies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list companies: %w", err)
+	}
+	return companies, nil
+}
+
+// GetCompany retourne une fiche
+func (s *CompanyService) GetCompany(ctx context.Context, id uuid.UUID) (*models.Company, error) {
+	var company models.Company
+	if err := s.db.WithContext(ctx).First(&company, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return &company, nil
+}
+
+// FindByName retourne l'entreprise dont le nom ou un alias correspond (après normalisation)
+func (s *CompanyService) FindByName(ctx context.Context, name string) (*models.Company, error) {
+	key := NormalizeCompanyName(name)
+	if key == "" {
+		return nil, ErrCompanyNotFound
+	}
+
+	var alias models.CompanyAlias
+	if err := s.db.WithContext(ctx).First(&alias, "alias = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("failed to find company: %w", err)
+	}
+	return s.GetCompany(ctx, alias.CompanyID)
+}
+
+// UpdateCompany modifie une fiche, qui devient curée (les alias sont reconstruits)
+// Un domaine renseigné par l'administrateur est considéré comme confirmé.
+func (s *CompanyService) UpdateCompany(ctx context.Context, id uuid.UUID, update CompanyUpdate) (*models.Company, error) {
+	company, err := s.GetCompany(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	input := companyInputFrom(company)
+	setIfPresent(&input.Name, update.Name)
+	setIfPresent(&input.Domain, update.Domain)
+	setIfPresent(&input.Description, update.Description)
+	setIfPresent(&input.Industry, update.Industry)
+	setIfPresent(&input.Size, update.Size)
+	setIfPresent(&input.Technologies, update.Technologies)
+	setIfPresent(&input.Values, update.Values)
+	setIfPresent(&input.Culture, update.Culture)
+	setIfPresent(&input.Notes, update.Notes)
+	setIfPresent(&input.Aliases, update.Aliases)
+
+	applyCompanyInput(company, input)
+	company.Curated = true
+	if update.Domain != nil {
+		company.DomainConfirmed = company.Domain != ""
+	}
+	if company.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCompany)
+	}
+
+	if err := s.saveCompany(ctx, company, false); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// DeleteCompany supprime une fiche et ses alias
+func (s *CompanyService) DeleteCompany(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("company_id = ?", id).Delete(&models.CompanyAlias{}).Error; err != nil {
+			return fmt.Errorf("failed to delete company aliases: %w", err)
+		}
+		result := tx.Delete(&models.Company{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete company: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrCompanyNotFound
+		}
+		return nil
+	})
+}
+
+// PromoteCompanyInfo crée une fiche curée à partir de données scrapées, à relire et corriger ensuite
+// Une entreprise déjà résolue (non curée) est complétée en conservant ses alias et son domaine
+// confirmé. Les valeurs de repli (description générique) ne sont pas reprises ; l'origine de
+// chaque champ est résumée dans les notes.
+func (s *CompanyService) PromoteCompanyInfo(ctx context.Context, info *models.CompanyInfo) (*models.Company, error) {
+	existing, err := s.FindByName(ctx, info.Name)
+	switch {
+	case err == nil && existing.Curated:
+		return nil, fmt.Errorf("%w: %q", ErrCompanyExists, info.Name)
+	case err != nil && !errors.Is(err, ErrCompanyNotFound):
+		return nil, err
+	}
+
+	input := CompanyInput{
+		Name:         info.Name,
+		Domain:       info.Domain,
+		Industry:     info.Industry,
+		Size:         info.Size,
+		Technologies: info.Technologies,
+		Values:       info.Values,
+		Culture:      info.Culture,
+	}
+	if info.Provenance[CompanyFieldDescription].Source != CompanySourceFallback {
+		input.Description = info.Description
+	}
+
+	var origins []string
+	for field, provenance := range info.Provenance {
+		if provenance.Source == CompanySourceFallback {
+			continue
+		}
+		origins = append(origins, fmt.Sprintf("%s : %s", field, provenance.Source))
+	}
+	sort.Strings(origins)
+	input.Notes = fmt.Sprintf("Importée des données scrapées le %s.", time.Now().Format("02/01/2006"))
+	if len(origins) > 0 {
+		input.Notes += " Sources : " + strings.Join(origins, ", ") + "."
+	}
+
+	// Le domaine scrapé (souvent deviné) reste à confirmer par l'administrateur
+	company := existing
+	if company == nil {
+		company = &models.Company{ID: uuid.New()}
+	} else {
+		input.Name = company.Name
+		input.Aliases = company.Aliases
+		if company.DomainConfirmed {
+			input.Domain = company.Domain
+		}
+	}
+	applyCompanyInput(company, input)
+	company.Curated = true
+
+	if err := s.saveCompany(ctx, company, existing == nil); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// ApplyCompanyRecord rattache info à son entreprise canonique (identifiant, nom)
+// Les champs renseignés d'une fiche curée remplacent ceux de info ; pour une entreprise
+// non curée, seul un domaine confirmé est repris.
+func ApplyCompanyRecord(info *models.CompanyInfo, company *models.Company) {
+	if info.Provenance == nil {
+		info.Provenance = map[string]models.FieldProvenance{}
+	}
+	info.CompanyID = company.ID.String()
+	info.Name = company.Name
+
+	if !company.Curated {
+		if company.DomainConfirmed {
+			info.Domain = company.Domain
+			info.Provenance[CompanyFieldDomain] = models.FieldProvenance{
+				Source:     CompanySourceConfirmed,
+				FetchedAt:  company.UpdatedAt,
+				Confidence: 1,
+			}
+		}
+		return
+	}
+
+	curated := models.FieldProvenance{
+		Source:     CompanySourceCurated,
+		FetchedAt:  company.UpdatedAt,
+		Confidence: 1,
+	}
+	set := func(field string, apply func()) {
+		apply()
+		info.Provenance[field] = curated
+	}
+
+	if company.Domain != "" {
+		set(CompanyFieldDomain, func() { info.Domain = company.Domain })
+	}
+	if company.Description != "" {
+		set(CompanyFieldDescription, func() { info.Description = company.Description })
+	}
+	if company.Industry != "" {
+		set(CompanyFieldIndustry, func() { info.Industry = company.Industry })
+	}
+	if company.Size != "" {
+		set(CompanyFieldSize, func() { info.Size = company.Size })
+	}
+	if len(company.Technologies) > 0 {
+		set(CompanyFieldTechnologies, func() { info.Technologies = company.Technologies })
+	}
+	if len(company.Values) > 0 {
+		set(CompanyFieldValues, func() { info.Values = company.Values })
+	}
+	if company.Culture != "" {
+		set(CompanyFieldCulture, func() { info.Culture = company.Culture })
+	}
+}
+
+// saveCompany enregistre une entreprise et ses clés de recherche dans une transaction
+func (s *CompanyService) saveCompany(ctx context.Context, company *models.Company, create bool) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if create {
+			if err := tx.Create(company).Error; err != nil {
+				return fmt.Errorf("failed to create company: %w", err)
+			}
+		} else if err := tx.Save(company).Error; err != nil {
+			return fmt.Errorf("failed to update company: %w", err)
+		}
+		return saveCompanyAliases(tx, company)
+	})
+}
+
+// saveCompanyAliases reconstruit les clés de recherche (nom + alias) d'une fiche
+func saveCompanyAliases(tx *gorm.DB, company *models.Company) error {
+	keys := companyAliasKeys(company)
+
+	var taken []models.CompanyAlias
+	if err := tx.Where("alias IN ? AND company_id <> ?", keys, company.ID).Find(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check company aliases: %w", err)
+	}
+	if len(taken) > 0 {
+		return fmt.Errorf("%w: %q", ErrCompanyExists, taken[0].Alias)
+	}
+
+	if err := tx.Where("company_id = ?", company.ID).Delete(&models.CompanyAlias{}).Error; err != nil {
+		return fmt.Errorf("failed to delete company aliases: %w", err)
+	}
+	aliases := make([]models.CompanyAlias, 0, len(keys))
+	for _, key := range keys {
+		aliases = append(aliases, models.CompanyAlias{Alias: key, CompanyID: company.ID})
+	}
+	if err := tx.Create(&aliases).Error; err != nil {
+		return fmt.Errorf("failed to save company aliases: %w", err)
+	}
+	return nil
+}
+
+// companyAliasKeys clés normalisées et dédoublonnées du nom et des alias
+func companyAliasKeys(company *models.Company) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, name := range append([]string{company.Name}, company.Aliases...) {
+		key := NormalizeCompanyName(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func applyCompanyInput(company *models.Company, input CompanyInput) {
+	company.Name = strings.TrimSpace(input.Name)
+	company.Domain = strings.ToLower(strings.TrimSpace(input.Domain))
+	company.Description = strings.TrimSpace(input.Description)
+	company.Industry = strings.TrimSpace(input.Industry)
+	company.Size = strings.TrimSpace(input.Size)
+	company.Technologies = cleanStringList(input.Technologies)
+	company.Values = cleanStringList(input.Values)
+	company.Culture = strings.TrimSpace(input.Culture)
+	company.Notes = strings.TrimSpace(input.Notes)
+	company.Aliases = cleanStringList(input.Aliases)
+}
+
+func companyInputFrom(company *models.Company) CompanyInput {
+	return CompanyInput{
+		Name:         company.Name,
+		Domain:       company.Domain,
+		Description:  company.Description,
+		Industry:     company.Industry,
+		Size:         company.Size,
+		Technologies: company.Technologies,
+		Values:       company.Values,
+		Culture:      company.Culture,
+		Notes:        company.Notes,
+		Aliases:      company.Aliases,
+	}
+}
+
+// cleanStringList supprime les entrées vides et les doublons (ordre conservé)
+func cleanStringList(values []string) []string {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		seen[strings.ToLower(value)] = true
+		cleaned = append(cleaned, value)
+	}
+	return cleaned
+}
+
+func setIfPresent[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+func newTestCompanyService(t *testing.T) *CompanyService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Company{}, &models.CompanyAlias{}))
+	return NewCompanyService(db)
+}
+
+func TestNormalizeCompanyName(t *testing.T) {
+	assert.Equal(t, "bnp paribas", NormalizeCompanyName("  BNP   Paribas "))
+	assert.Equal(t, "", NormalizeCompanyName("   "))
+}
+
+func TestCompanyService_CRUDAndAliases(t *testing.T) {
+	companies := newTestCompanyService(t)
+	ctx := t.Context()
+
+	company, err := companies.CreateCompany(ctx, CompanyInput{
+		Name:         " BNP Paribas ",
+		Domain:       "Group.BNPParibas",
+		Technologies: []string{"Java", " ", "java", "Kafka"},
+		Aliases:      []string{"BNP", "bnp paribas"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "BNP Paribas", company.Name)
+	assert.Equal(t, "group.bnpparibas", company.Domain)
+	assert.Equal(t, []string{"Java", "Kafka"}, []string(company.Technologies))
+
+	found, err := companies.FindByName(ctx, "bnp")
+	require.NoError(t, err)
+	assert.Equal(t, company.ID, found.ID)
+	assert.Equal(t, []string{"BNP", "bnp paribas"}, []string(found.Aliases))
+
+	// Un nom ou alias ne peut appartenir qu'à une fiche
+	_, err = companies.CreateCompany(ctx, CompanyInput{Name: "Banque", Aliases: []string{"  bnp "}})
+	assert.ErrorIs(t, err, ErrCompanyExists)
+	_, err = companies.CreateCompany(ctx, CompanyInput{Name: "  "})
+	assert.ErrorIs(t, err, ErrInvalidCompany)
+
+	// Les alias sont reconstruits à la modification
+	aliases := []string{"Paribas"}
+	description := "Banque européenne."
+	updated, err := companies.UpdateCompany(ctx, company.ID, CompanyUpdate{Aliases: &aliases, Description: &description})
+	require.NoError(t, err)
+	assert.Equal(t, "Banque européenne.", updated.Description)
+	assert.Equal(t, []string{"Java", "Kafka"}, []string(updated.Technologies))
+
+	_, err = companies.FindByName(ctx, "BNP")
+	assert.ErrorIs(t, err, ErrCompanyNotFound)
+	_, err = companies.FindByName(ctx, "paribas")
+	require.NoError(t, err)
+
+	list, err := companies.ListCompanies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, companies.DeleteCompany(ctx, company.ID))
+	assert.ErrorIs(t, companies.DeleteCompany(ctx, company.ID), ErrCompanyNotFound)
+	_, err = companies.FindByName(ctx, "paribas")
+	assert.ErrorIs(t, err, ErrCompanyNotFound)
+}
+
+func TestCompanyService_PromoteCompanyInfo(t *testing.T) {
+	companies := newTestCompanyService(t)
+
+	company, err := companies.PromoteCompanyInfo(t.Context(), &models.CompanyInfo{
+		Name:         "Acme",
+		Domain:       "acme.com",
+		Description:  "Acme est une entreprise.",
+		Industry:     "Aerospace",
+		Technologies: []string{"Go"},
+		Provenance: map[string]models.FieldProvenance{
+			CompanyFieldDomain:       {Source: CompanySourceDomainGuess},
+			CompanyFieldDescription:  {Source: CompanySourceFallback},
+			CompanyFieldIndustry:     {Source: CompanySourceWikipedia},
+			CompanyFieldTechnologies: {Source: CompanySourceWebsite},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Acme", company.Name)
+	assert.Empty(t, company.Description, "fallback description is not promoted")
+	assert.Equal(t, "Aerospace", company.Industry)
+	assert.Contains(t, company.Notes, "industry : wikipedia")
+	assert.Contains(t, company.Notes, "technologies : website")
+	assert.NotContains(t, company.Notes, "fallback")
+}
+
+func TestGetCompanyInfo_CuratedOverridesScraping(t *testing.T) {
+	scraper, redisClient := newTestSourcesScraper(t, nil)
+	companies := newTestCompanyService(t)
+	scraper.SetCompanyDirectory(companies)
+
+	source := &fakeCompanySource{name: CompanySourceWikipedia, result: &CompanySourceResult{
+		URL: "https://en.wikipedia.org/wiki/Orange",
+		Info: models.CompanyInfo{
+			Description: "The orange is the fruit of the citrus species.",
+			Industry:    "Agriculture",
+			RecentNews:  "Actualités récentes:\n• 5G",
+		},
+	}}
+	scraper.RegisterSource(source, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 0.8})
+
+	curated, err := companies.CreateCompany(t.Context(), CompanyInput{
+		Name:        "Orange",
+		Domain:      "orange.fr",
+		Description: "Opérateur de télécommunications.",
+		Values:      []string{"Engagement"},
+		Notes:       "Ne pas confondre avec le fruit",
+		Aliases:     []string{"Orange SA"},
+	})
+	require.NoError(t, err)
+
+	info, err := scraper.GetCompanyInfo(t.Context(), "orange sa")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Orange", info.Name)
+	assert.Equal(t, "orange.fr", source.query.Domain, "curated domain is scraped instead of the guess")
+	assert.Equal(t, "Opérateur de télécommunications.", info.Description)
+	assert.Equal(t, []string{"Engagement"}, info.Values)
+	assert.Equal(t, "Agriculture", info.Industry, "fields missing from the record keep scraped values")
+	assert.Contains(t, info.RecentNews, "5G")
+	assert.NotContains(t, info.Description+info.Culture, "fruit")
+
+	provenance := info.Provenance[CompanyFieldDescription]
+	assert.Equal(t, CompanySourceCurated, provenance.Source)
+	assert.Equal(t, 1.0, provenance.Confidence)
+	assert.WithinDuration(t, curated.UpdatedAt, provenance.FetchedAt, time.Second)
+	assert.Equal(t, CompanySourceWikipedia, info.Provenance[CompanyFieldIndustry].Source)
+
+	// Seules les données scrapées sont en cache : la fiche s'applique aussi aux lectures en cache
+	cached, err := redisClient.Get(t.Context(), "company_info:"+curated.ID.String()).Result()
+	require.NoError(t, err)
+	assert.NotContains(t, cached, "Opérateur")
+
+	description := "Groupe de télécommunications."
+	_, err = companies.UpdateCompany(t.Context(), curated.ID, CompanyUpdate{Description: &description})
+	require.NoError(t, err)
+
+	info, err = scraper.GetCompanyInfo(t.Context(), "Orange SA")
+	require.NoError(t, err)
+	assert.Equal(t, "Groupe de télécommunications.", info.Description)
+	assert.Equal(t, int32(1), source.calls.Load())
+
+	require.NoError(t, scraper.InvalidateCompanyInfo(t.Context(), curated.ID))
+	_, err = scraper.GetCompanyInfo(t.Context(), "Orange SA")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), source.calls.Load())
+}
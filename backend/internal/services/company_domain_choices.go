@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"maicivy/internal/models"
+)
+
+const (
+	// companyDomainOfferTTL durée pendant laquelle les domaines proposés peuvent être choisis
+	companyDomainOfferTTL = time.Hour
+	// companyDomainChoiceTTL durée de conservation du domaine choisi par un visiteur
+	companyDomainChoiceTTL = 30 * 24 * time.Hour
+)
+
+// ErrDomainNotOffered domaine absent des candidats proposés au visiteur pour cette entreprise
+var ErrDomainNotOffered = errors.New("domain was not offered for this company")
+
+// CompanyDomainChoice domaine retenu par un visiteur pour une entreprise
+type CompanyDomainChoice struct {
+	Company   *models.Company `json:"company"`
+	Domain    string          `json:"domain"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// CompanyDomainChoiceService domaines d'entreprise choisis par chaque visiteur
+// Seuls les domaines proposés par la résolution à la même session peuvent être choisis ;
+// le choix ne s'applique qu'aux lettres de cette session et reste révocable.
+// Le domaine canonique (partagé) n'est confirmé que par l'administrateur.
+type CompanyDomainChoiceService struct {
+	redis     *redis.Client
+	companies *CompanyService
+}
+
+// NewCompanyDomainChoiceService crée le service des domaines choisis par les visiteurs
+func NewCompanyDomainChoiceService(redisClient *redis.Client, companies *CompanyService) *CompanyDomainChoiceService {
+	return &CompanyDomainChoiceService{
+		redis:     redisClient,
+		companies: companies,
+	}
+}
+
+func companyDomainOfferKey(sessionID, normalized string) string {
+	return fmt.Sprintf("company_domains:offered:%s:%s", sessionID, normalized)
+}
+
+func companyDomainChoiceKey(sessionID, companyID string) string {
+	return fmt.Sprintf("company_domains:chosen:%s:%s", sessionID, companyID)
+}
+
+// OfferDomains retient les domaines proposés à une session pour un nom d'entreprise
+func (s *CompanyDomainChoiceService) OfferDomains(ctx context.Context, sessionID string, resolution *CompanyResolution) error {
+	if sessionID == "" || len(resolution.DomainCandidates) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(resolution.DomainCandidates))
+	for i, domain := range resolution.DomainCandidates {
+		members[i] = domain
+	}
+
+	key := companyDomainOfferKey(sessionID, resolution.Normalized)
+	pipe := s.redis.TxPipeline()
+	pipe.SAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, companyDomainOfferTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store offered domains: %w", err)
+	}
+	return nil
+}
+
+// ChooseDomain enregistre le domaine choisi par une session parmi ceux qui lui ont été proposés
+func (s *CompanyDomainChoiceService) ChooseDomain(ctx context.Context, sessionID, name, domain string) (*CompanyDomainChoice, error) {
+	domain = normalizeCompanyDomain(domain)
+	if err := validatePublicDomain(domain); err != nil {
+		return nil, err
+	}
+
+	offered, err := s.redis.SIsMember(ctx, companyDomainOfferKey(sessionID, NormalizeCompanyName(name)), domain).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check offered domains: %w", err)
+	}
+	if !offered {
+		return nil, ErrDomainNotOffered
+	}
+
+	company, err := s.companies.ResolveOrCreateCompany(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redis.Set(ctx, companyDomainChoiceKey(sessionID, company.ID.String()), domain, companyDomainChoiceTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store chosen domain: %w", err)
+	}
+	return &CompanyDomainChoice{
+		Company:   company,
+		Domain:    domain,
+		ExpiresAt: time.Now().Add(companyDomainChoiceTTL),
+	}, nil
+}
+
+// RevokeDomain oublie le domaine choisi par une session pour une entreprise
+func (s *CompanyDomainChoiceService) RevokeDomain(ctx context.Context, sessionID, name string) error {
+	company, err := s.knownCompany(ctx, name)
+	if err != nil || company == nil {
+		return err
+	}
+	if err := s.redis.Del(ctx, companyDomainChoiceKey(sessionID, company.ID.String())).Err(); err != nil {
+		return fmt.Errorf("failed to revoke chosen domain: %w", err)
+	}
+	return nil
+}
+
+// SessionDomain domaine choisi par une session pour une entreprise (vide si aucun)
+func (s *CompanyDomainChoiceService) SessionDomain(ctx context.Context, sessionID, name string) (string, error) {
+	if sessionID == "" {
+		return "", nil
+	}
+	company, err := s.knownCompany(ctx, name)
+	if err != nil || company == nil {
+		return "", err
+	}
+
+	domain, err := s.redis.Get(ctx, companyDomainChoiceKey(sessionID, company.ID.String())).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get chosen domain: %w", err)
+	}
+	return domain, nil
+}
+
+// knownCompany entreprise résolue à partir du nom, sans création (nil si inconnue)
+func (s *CompanyDomainChoiceService) knownCompany(ctx context.Context, name string) (*models.Company, error) {
+	resolution, err := s.companies.ResolveCompany(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return resolution.Company, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDomainChoiceService(t *testing.T) (*CompanyDomainChoiceService, *CompanyService) {
+	mr := miniredis.RunT(t)
+	companies := newTestCompanyService(t)
+	return NewCompanyDomainChoiceService(redis.NewClient(&redis.Options{Addr: mr.Addr()}), companies), companies
+}
+
+func TestCompanyDomainChoiceService_ChooseDomain(t *testing.T) {
+	choices, companies := newTestDomainChoiceService(t)
+	ctx := t.Context()
+
+	// Aucun domaine proposé à la session
+	_, err := choices.ChooseDomain(ctx, "session-a", "Orange", "orange.fr")
+	assert.ErrorIs(t, err, ErrDomainNotOffered)
+
+	resolution, err := companies.ResolveCompany(ctx, "Orange")
+	require.NoError(t, err)
+	require.NoError(t, choices.OfferDomains(ctx, "session-a", resolution))
+
+	choice, err := choices.ChooseDomain(ctx, "session-a", "orange sa", " Orange.FR. ")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.fr", choice.Domain)
+
+	// Le choix du visiteur ne modifie pas le domaine canonique
+	company, err := companies.GetCompany(ctx, choice.Company.ID)
+	require.NoError(t, err)
+	assert.Empty(t, company.Domain)
+	assert.False(t, company.DomainConfirmed)
+
+	domain, err := choices.SessionDomain(ctx, "session-a", "Orange")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.fr", domain)
+
+	// Ni les autres sessions, ni les domaines non proposés
+	domain, err = choices.SessionDomain(ctx, "session-b", "Orange")
+	require.NoError(t, err)
+	assert.Empty(t, domain)
+	_, err = choices.ChooseDomain(ctx, "session-b", "Orange", "orange.fr")
+	assert.ErrorIs(t, err, ErrDomainNotOffered)
+	_, err = choices.ChooseDomain(ctx, "session-a", "Orange", "evil.example.org")
+	assert.ErrorIs(t, err, ErrDomainNotOffered)
+
+	// Noms internes ou réservés refusés avant toute vérification
+	for _, reserved := range []string{"intranet.corp", "metadata.internal", "printer.local", "10.0.0.1", "localhost"} {
+		_, err = choices.ChooseDomain(ctx, "session-a", "Orange", reserved)
+		assert.ErrorIs(t, err, ErrInvalidCompany, reserved)
+	}
+
+	// Révocation
+	require.NoError(t, choices.RevokeDomain(ctx, "session-a", "Orange"))
+	domain, err = choices.SessionDomain(ctx, "session-a", "Orange")
+	require.NoError(t, err)
+	assert.Empty(t, domain)
+	require.NoError(t, choices.RevokeDomain(ctx, "session-a", "Globex"))
+}
+
+func TestCompanyDomainCandidates_SkipsReservedDomains(t *testing.T) {
+	assert.Equal(t, []string{"acme.com", "acme.fr"}, companyDomainCandidates(nil, "Acme"))
+
+	for _, domain := range []string{"orange.fr", "group.bnpparibas"} {
+		assert.NoError(t, validatePublicDomain(domain), domain)
+	}
+	for _, domain := range []string{"", "localhost", "intranet", "api.internal", "nas.home.arpa", "acme.test", "::1", "192.168.1.1"} {
+		assert.ErrorIs(t, validatePublicDomain(domain), ErrInvalidCompany, domain)
+	}
+}
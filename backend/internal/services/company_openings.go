@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+const (
+	// companyOpeningsTTL durée pendant laquelle les offres enregistrées sont servies sans nouveau parcours
+	companyOpeningsTTL = 24 * time.Hour
+	// minOpeningsRefreshInterval délai minimal entre deux parcours forcés (?refresh=true)
+	minOpeningsRefreshInterval = 15 * time.Minute
+)
+
+// ErrOpeningsUnavailable site carrières injoignable et aucune offre enregistrée
+var ErrOpeningsUnavailable = errors.New("company openings unavailable")
+
+// CareersCrawler parcours des pages carrières (CompanyScraper)
+type CareersCrawler interface {
+	CrawlCareers(ctx context.Context, domain string) (*CareersCrawl, error)
+}
+
+// CompanyOpenings offres ouvertes d'une entreprise
+type CompanyOpenings struct {
+	Company   *models.Company         `json:"company"`
+	Domain    string                  `json:"domain"`
+	Openings  []models.CompanyOpening `json:"openings"`
+	CrawledAt *time.Time              `json:"crawled_at,omitempty"`
+	Stale     bool                    `json:"stale"` // Dernier parcours en échec : offres du parcours précédent
+}
+
+// CompanyOpeningsService offres ouvertes des entreprises, relevées sur leur site carrières
+// Les offres sont enregistrées par entreprise et rafraîchies au plus une fois par TTL ;
+// les demandes simultanées pour une même entreprise ne déclenchent qu'un parcours.
+type CompanyOpeningsService struct {
+	db        *gorm.DB
+	companies *CompanyService
+	crawler   CareersCrawler
+	ttl       time.Duration
+	group     singleflight.Group
+}
+
+// NewCompanyOpeningsService crée le service des offres ouvertes
+func NewCompanyOpeningsService(db *gorm.DB, companies *CompanyService, crawler CareersCrawler) *CompanyOpeningsService {
+	return &CompanyOpeningsService{
+		db:        db,
+		companies: companies,
+		crawler:   crawler,
+		ttl:       companyOpeningsTTL,
+	}
+}
+
+// GetCompanyOpenings offres ouvertes d'une entreprise connue (résolue à partir du nom, sans création)
+// Le site est parcouru si les offres enregistrées sont périmées, ou sur demande (refresh)
+// au plus une fois par minOpeningsRefreshInterval.
+func (s *CompanyOpeningsService) GetCompanyOpenings(ctx context.Context, name string, refresh bool) (*CompanyOpenings, error) {
+	resolution, err := s.companies.ResolveCompany(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	company := resolution.Company
+	if company == nil {
+		return nil, ErrCompanyNotFound
+	}
+
+	domain := company.Domain
+	if domain == "" {
+		domain = guessCompanyDomain(company.Name)
+	}
+	result := &CompanyOpenings{Company: company, Domain: domain}
+
+	if s.needsCrawl(company, refresh) {
+		if err := s.sharedCrawl(ctx, company.ID, domain); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if company.OpeningsCrawledAt == nil {
+				return nil, fmt.Errorf("%w: %v", ErrOpeningsUnavailable, err)
+			}
+			log.Warn().Err(err).Str("company_id", company.ID.String()).Msg("Careers crawl failed, serving stored openings")
+			result.Stale = true
+		}
+	}
+
+	if err := s.db.WithContext(ctx).First(company, "id = ?", company.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	result.CrawledAt = company.OpeningsCrawledAt
+
+	if err := s.db.WithContext(ctx).
+		Where("company_id = ?", company.ID).
+		Order("title ASC, url ASC").
+		Find(&result.Openings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list company openings: %w", err)
+	}
+	return result, nil
+}
+
+func (s *CompanyOpeningsService) needsCrawl(company *models.Company, refresh bool) bool {
+	if company.OpeningsCrawledAt == nil {
+		return true
+	}
+	age := time.Since(*company.OpeningsCrawledAt)
+	if refresh {
+		return age >= minOpeningsRefreshInterval
+	}
+	return age >= s.ttl
+}
+
+// sharedCrawl un seul parcours par entreprise pour les demandes simultanées
+// Le parcours ne dépend pas de la requête qui l'a lancé : il a son propre délai
+// et se termine même si cette requête est annulée ; chaque appelant n'attend
+// que dans la limite de son propre contexte.
+func (s *CompanyOpeningsService) sharedCrawl(ctx context.Context, companyID uuid.UUID, domain string) error {
+	results := s.group.DoChan(companyID.String(), func() (interface{}, error) {
+		crawlCtx, cancel := context.WithTimeout(context.Background(), careersCrawlTimeout)
+		defer cancel()
+		return nil, s.crawlOpenings(crawlCtx, companyID, domain)
+	})
+
+	select {
+	case result := <-results:
+		return result.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *CompanyOpeningsService) crawlOpenings(ctx context.Context, companyID uuid.UUID, domain string) error {
+	crawl, err := s.crawler.CrawlCareers(ctx, domain)
+	if err != nil {
+		return err
+	}
+	return s.ReplaceCompanyOpenings(ctx, companyID, crawl.Openings)
+}
+
+// ReplaceCompanyOpenings enregistre le résultat d'un parcours
+// Les offres déjà connues (même URL) conservent leur date de première apparition ;
+// celles absentes du parcours sont supprimées.
+func (s *CompanyOpeningsService) ReplaceCompanyOpenings(ctx context.Context, companyID uuid.UUID, openings []models.CompanyOpening) error {
+	now := time.Now()
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []models.CompanyOpening
+		if err := tx.Where("company_id = ?", companyID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to load company openings: %w", err)
+		}
+		known := make(map[string]models.CompanyOpening, len(existing))
+		for _, opening := range existing {
+			known[opening.URL] = opening
+		}
+
+		seen := make(map[string]bool, len(openings))
+		for _, opening := range openings {
+			if seen[opening.URL] {
+				continue
+			}
+			seen[opening.URL] = true
+
+			opening.CompanyID = companyID
+			opening.LastSeenAt = now
+			if previous, ok := known[opening.URL]; ok {
+				opening.ID = previous.ID
+				opening.FirstSeenAt = previous.FirstSeenAt
+			} else {
+				opening.ID = uuid.New()
+				opening.FirstSeenAt = now
+			}
+			if err := tx.Save(&opening).Error; err != nil {
+				return fmt.Errorf("failed to save company opening: %w", err)
+			}
+		}
+
+		for url, opening := range known {
+			if seen[url] {
+				continue
+			}
+			if err := tx.Delete(&models.CompanyOpening{}, "id = ?", opening.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete company opening: %w", err)
+			}
+		}
+
+		if err := tx.Model(&models.Company{}).
+			Where("id = ?", companyID).
+			Update("openings_crawled_at", now).Error; err != nil {
+			return fmt.Errorf("failed to update company: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+// CompanyMatch nature de la correspondance trouvée pour un nom d'entreprise
+type CompanyMatch string
+
+const (
+	CompanyMatchExact CompanyMatch = "exact" // Nom ou alias connu (après normalisation)
+	CompanyMatchFuzzy CompanyMatch = "fuzzy" // Nom proche d'une entreprise connue
+	CompanyMatchNone  CompanyMatch = "none"  // Entreprise inconnue
+)
+
+// Seuils de similarité trigramme (0-1, mêmes calculs que pg_trgm)
+const (
+	companyAutoMatchSimilarity = 0.6 // Rattachement automatique à une entreprise connue
+	companyCandidateSimilarity = 0.3 // Proposée à l'utilisateur (seuil par défaut de pg_trgm)
+	maxCompanyCandidates       = 5
+)
+
+// ErrCompanyDomainConfirmed domaine déjà confirmé (seul l'administrateur peut le changer)
+var ErrCompanyDomainConfirmed = errors.New("company domain already confirmed")
+
+// reservedDomainSuffixes noms réservés ou internes (RFC 2606, 6761, 6762, 8375), jamais acceptés comme domaine
+var reservedDomainSuffixes = []string{
+	"localhost", "localdomain", "local", "internal", "intranet", "lan", "home", "corp",
+	"home.arpa", "arpa", "test", "example", "invalid", "onion",
+}
+
+// companyLegalSuffixes formes juridiques retirées en fin de nom ("BNP Paribas SA" -> "bnp paribas")
+var companyLegalSuffixes = map[string]bool{
+	"sa": true, "sas": true, "sasu": true, "sarl": true, "eurl": true, "sca": true, "snc": true, "se": true,
+	"inc": true, "incorporated": true, "corp": true, "corporation": true,
+	"ltd": true, "limited": true, "llc": true, "llp": true, "plc": true, "gmbh": true, "ag": true,
+	"kg": true, "bv": true, "nv": true, "spa": true, "srl": true, "ab": true, "oy": true,
+}
+
+// CompanyCandidate entreprise connue proche du nom recherché
+type CompanyCandidate struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Domain          string    `json:"domain,omitempty"`
+	DomainConfirmed bool      `json:"domain_confirmed"`
+	MatchedAlias    string    `json:"matched_alias"`
+	Score           float64   `json:"score"`
+}
+
+// CompanyResolution résultat de la résolution d'un nom d'entreprise
+type CompanyResolution struct {
+	Query      string          `json:"query"`
+	Normalized string          `json:"normalized"`
+	Match      CompanyMatch    `json:"match"`
+	Company    *models.Company `json:"company,omitempty"` // Entreprise retenue (exact ou fuzzy)
+
+	Candidates       []CompanyCandidate `json:"candidates"`        // Autres entreprises proches
+	DomainCandidates []string           `json:"domain_candidates"` // Domaines à faire confirmer
+}
+
+// NormalizeCompanyName clé de recherche d'un nom d'entreprise
+// Casse, accents, ponctuation et forme juridique finale sont ignorés.
+func NormalizeCompanyName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '.', r == '\'', r == '’':
+			// Accents et abréviations ("S.A.", "L'Oréal")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	for len(words) > 1 && companyLegalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// ResolveCompany rattache un nom à une entreprise connue, sans rien enregistrer
+// Correspondance exacte sur le nom ou un alias normalisé, sinon par similarité trigramme.
+func (s *CompanyService) ResolveCompany(ctx context.Context, name string) (*CompanyResolution, error) {
+	resolution := &CompanyResolution{
+		Query:      strings.TrimSpace(name),
+		Normalized: NormalizeCompanyName(name),
+		Match:      CompanyMatchNone,
+		Candidates: []CompanyCandidate{},
+	}
+	if resolution.Normalized == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCompany)
+	}
+
+	company, err := s.FindByName(ctx, name)
+	switch {
+	case err == nil:
+		resolution.Match = CompanyMatchExact
+		resolution.Company = company
+	case !errors.Is(err, ErrCompanyNotFound):
+		return nil, err
+	}
+
+	candidates, err := s.similarCompanies(ctx, resolution.Normalized)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if resolution.Company == nil && candidate.Score >= companyAutoMatchSimilarity {
+			company, err := s.GetCompany(ctx, candidate.ID)
+			if err != nil {
+				return nil, err
+			}
+			resolution.Match = CompanyMatchFuzzy
+			resolution.Company = company
+			continue
+		}
+		if resolution.Company != nil && candidate.ID == resolution.Company.ID {
+			continue
+		}
+		resolution.Candidates = append(resolution.Candidates, candidate)
+	}
+
+	resolution.DomainCandidates = companyDomainCandidates(resolution.Company, resolution.Query)
+	return resolution, nil
+}
+
+// ResolveOrCreateCompany retourne l'entreprise canonique d'un nom, créée au besoin
+// Une entreprise créée ainsi n'est pas curée : seuls son nom et ses alias servent à la résolution.
+func (s *CompanyService) ResolveOrCreateCompany(ctx context.Context, name string) (*models.Company, error) {
+	resolution, err := s.ResolveCompany(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if resolution.Company != nil {
+		return resolution.Company, nil
+	}
+
+	company := &models.Company{ID: uuid.New()}
+	applyCompanyInput(company, CompanyInput{Name: name})
+	err = s.saveCompany(ctx, company, true)
+	if errors.Is(err, ErrCompanyExists) {
+		// Créée entre-temps par une requête concurrente
+		return s.FindByName(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// ConfirmCompanyDomain fixe le domaine canonique d'une entreprise (administrateur)
+// Un domaine déjà confirmé (ou curé) ne se modifie ensuite que par la mise à jour de la fiche.
+func (s *CompanyService) ConfirmCompanyDomain(ctx context.Context, name, domain string) (*models.Company, error) {
+	domain = normalizeCompanyDomain(domain)
+	if err := validatePublicDomain(domain); err != nil {
+		return nil, err
+	}
+
+	company, err := s.ResolveOrCreateCompany(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if company.Domain == domain && company.DomainConfirmed {
+		return company, nil
+	}
+	if company.DomainConfirmed || (company.Curated && company.Domain != "") {
+		return nil, ErrCompanyDomainConfirmed
+	}
+
+	company.Domain = domain
+	company.DomainConfirmed = true
+	if err := s.db.WithContext(ctx).Save(company).Error; err != nil {
+		return nil, fmt.Errorf("failed to confirm company domain: %w", err)
+	}
+	return company, nil
+}
+
+// RebuildAliasKeys recalcule les clés de recherche de toutes les entreprises
+// À lancer après un changement de NormalizeCompanyName ; une clé revendiquée par
+// plusieurs entreprises reste à la première (par date de création).
+func (s *CompanyService) RebuildAliasKeys(ctx context.Context) (int, error) {
+	var companies []models.Company
+	if err := s.db.WithContext(ctx).Order("created_at ASC").Find(&companies).Error; err != nil {
+		return 0, fmt.Errorf("failed to list companies: %w", err)
+	}
+
+	var aliases []models.CompanyAlias
+	seen := map[string]bool{}
+	for i := range companies {
+		for _, key := range companyAliasKeys(&companies[i]) {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			aliases = append(aliases, models.CompanyAlias{Alias: key, CompanyID: companies[i].ID})
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.CompanyAlias{}).Error; err != nil {
+			return fmt.Errorf("failed to delete company aliases: %w", err)
+		}
+		if len(aliases) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&aliases, 500).Error; err != nil {
+			return fmt.Errorf("failed to save company aliases: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(aliases), nil
+}
+
+// similarCompanies entreprises dont un nom ou alias est proche (meilleur score par entreprise)
+// PostgreSQL calcule la similarité via pg_trgm (index GIN) ; les autres bases en Go.
+func (s *CompanyService) similarCompanies(ctx context.Context, key string) ([]CompanyCandidate, error) {
+	type aliasScore struct {
+		CompanyID uuid.UUID
+		Alias     string
+		Score     float64
+	}
+	var scores []aliasScore
+
+	db := s.db.WithContext(ctx)
+	if db.Dialector.Name() == "postgres" {
+		err := db.Raw(
+			`SELECT company_id, alias, similarity(alias, ?) AS score FROM company_aliases
+			 WHERE similarity(alias, ?) >= ? ORDER BY score DESC LIMIT ?`,
+			key, key, companyCandidateSimilarity, maxCompanyCandidates*3,
+		).Scan(&scores).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to search similar companies: %w", err)
+		}
+	} else {
+		var aliases []models.CompanyAlias
+		if err := db.Find(&aliases).Error; err != nil {
+			return nil, fmt.Errorf("failed to search similar companies: %w", err)
+		}
+		for _, alias := range aliases {
+			if score := trigramSimilarity(key, alias.Alias); score >= companyCandidateSimilarity {
+				scores = append(scores, aliasScore{CompanyID: alias.CompanyID, Alias: alias.Alias, Score: score})
+			}
+		}
+	}
+
+	best := map[uuid.UUID]aliasScore{}
+	for _, score := range scores {
+		if current, ok := best[score.CompanyID]; !ok || score.Score > current.Score {
+			best[score.CompanyID] = score
+		}
+	}
+	if len(best) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(best))
+	for id := range best {
+		ids = append(ids, id)
+	}
+	var companies []models.Company
+	if err := db.Where("id IN ?", ids).Find(&companies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load similar companies: %w", err)
+	}
+
+	candidates := make([]CompanyCandidate, 0, len(companies))
+	for _, company := range companies {
+		score := best[company.ID]
+		candidates = append(candidates, CompanyCandidate{
+			ID:              company.ID,
+			Name:            company.Name,
+			Domain:          company.Domain,
+			DomainConfirmed: company.DomainConfirmed,
+			MatchedAlias:    score.Alias,
+			Score:           math.Round(score.Score*100) / 100,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	if len(candidates) > maxCompanyCandidates {
+		candidates = candidates[:maxCompanyCandidates]
+	}
+	return candidates, nil
+}
+
+// trigramSimilarity similarité de deux chaînes selon l'algorithme de pg_trgm
+// Chaque mot est entouré d'espaces ("  mot "), score = trigrammes communs / trigrammes distincts.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// companyDomainCandidates domaines proposés à la confirmation (domaine connu d'abord)
+func companyDomainCandidates(company *models.Company, query string) []string {
+	name := query
+	var candidates []string
+	if company != nil {
+		name = company.Name
+		if company.Domain != "" {
+			candidates = append(candidates, company.Domain)
+		}
+	}
+
+	base := strings.ReplaceAll(NormalizeCompanyName(name), " ", "")
+	candidates = append(candidates, guessCompanyDomain(name), base+".com", base+".fr")
+
+	public := []string{}
+	for _, domain := range uniqueStrings(candidates) {
+		if validatePublicDomain(domain) == nil {
+			public = append(public, domain)
+		}
+	}
+	return public
+}
+
+// normalizeCompanyDomain forme canonique d'un domaine saisi ("Orange.FR." -> "orange.fr")
+func normalizeCompanyDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// validatePublicDomain refuse les adresses IP et les noms réservés ou internes
+func validatePublicDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("%w: domain is required", ErrInvalidCompany)
+	}
+	if net.ParseIP(strings.Trim(domain, "[]")) != nil {
+		return fmt.Errorf("%w: domain must be a hostname, not an IP address", ErrInvalidCompany)
+	}
+	if !strings.Contains(domain, ".") {
+		return fmt.Errorf("%w: domain %q is not a public hostname", ErrInvalidCompany, domain)
+	}
+	for _, suffix := range reservedDomainSuffixes {
+		if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+			return fmt.Errorf("%w: domain %q is reserved", ErrInvalidCompany, domain)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func TestNormalizeCompanyName_SuffixesAndAccents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"BNP Paribas SA", "bnp paribas"},
+		{"bnp paribas s.a.", "bnp paribas"},
+		{"Société Générale", "societe generale"},
+		{"L'Oréal", "loreal"},
+		{"Acme, Inc.", "acme"},
+		{"Siemens AG", "siemens"},
+		{"Crédit Agricole S.A.S.", "credit agricole"},
+		{"SA", "sa"}, // Un nom réduit à sa forme juridique est conservé
+		{"My Company", "my company"},
+		{"Dassault-Systèmes", "dassault systemes"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, NormalizeCompanyName(tc.name), tc.name)
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, trigramSimilarity("bnp paribas", "bnp paribas"))
+	assert.Equal(t, 0.0, trigramSimilarity("orange", "microsoft"))
+	assert.Equal(t, 0.0, trigramSimilarity("", "orange"))
+
+	// Même calcul que pg_trgm : similarity('word', 'two words') = 0.363636
+	assert.InDelta(t, 0.363636, trigramSimilarity("word", "two words"), 0.0001)
+	assert.Greater(t, trigramSimilarity("bnp paribas", "bnp parisbas"), companyAutoMatchSimilarity)
+}
+
+func TestCompanyService_ResolveCompany(t *testing.T) {
+	companies := newTestCompanyService(t)
+	ctx := t.Context()
+
+	bnp, err := companies.CreateCompany(ctx, CompanyInput{Name: "BNP Paribas", Domain: "group.bnpparibas", Aliases: []string{"BNP"}})
+	require.NoError(t, err)
+	_, err = companies.CreateCompany(ctx, CompanyInput{Name: "BNP Paribas Cardif"})
+	require.NoError(t, err)
+
+	// Exact : nom, alias ou variante normalisée
+	resolution, err := companies.ResolveCompany(ctx, "bnp paribas s.a.")
+	require.NoError(t, err)
+	assert.Equal(t, CompanyMatchExact, resolution.Match)
+	assert.Equal(t, bnp.ID, resolution.Company.ID)
+	assert.Equal(t, "bnp paribas", resolution.Normalized)
+	require.Len(t, resolution.Candidates, 1)
+	assert.Equal(t, "BNP Paribas Cardif", resolution.Candidates[0].Name)
+	assert.Equal(t, "group.bnpparibas", resolution.DomainCandidates[0], "known domain first")
+
+	// Fuzzy : faute de frappe
+	resolution, err = companies.ResolveCompany(ctx, "BNP Parisbas")
+	require.NoError(t, err)
+	assert.Equal(t, CompanyMatchFuzzy, resolution.Match)
+	assert.Equal(t, bnp.ID, resolution.Company.ID)
+
+	// Aucune : entreprise inconnue, domaines devinés à confirmer
+	resolution, err = companies.ResolveCompany(ctx, "Orange")
+	require.NoError(t, err)
+	assert.Equal(t, CompanyMatchNone, resolution.Match)
+	assert.Nil(t, resolution.Company)
+	assert.Empty(t, resolution.Candidates)
+	assert.Equal(t, []string{"orange.com", "orange.fr"}, resolution.DomainCandidates)
+
+	_, err = companies.ResolveCompany(ctx, " , ")
+	assert.ErrorIs(t, err, ErrInvalidCompany)
+
+	// La résolution n'enregistre rien
+	list, err := companies.ListCompanies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestCompanyService_ResolveOrCreateCompany(t *testing.T) {
+	companies := newTestCompanyService(t)
+	ctx := t.Context()
+
+	created, err := companies.ResolveOrCreateCompany(ctx, "Orange SA")
+	require.NoError(t, err)
+	assert.Equal(t, "Orange SA", created.Name)
+	assert.False(t, created.Curated)
+	assert.False(t, created.DomainConfirmed)
+
+	for _, name := range []string{"orange", "ORANGE S.A.", "Orangee"} {
+		company, err := companies.ResolveOrCreateCompany(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, company.ID, name)
+	}
+
+	list, err := companies.ListCompanies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestCompanyService_ConfirmCompanyDomain(t *testing.T) {
+	companies := newTestCompanyService(t)
+	ctx := t.Context()
+
+	company, err := companies.ConfirmCompanyDomain(ctx, "Orange", " Orange.FR ")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.fr", company.Domain)
+	assert.True(t, company.DomainConfirmed)
+
+	// Reconfirmer le même domaine est sans effet, en changer est refusé
+	_, err = companies.ConfirmCompanyDomain(ctx, "orange sa", "orange.fr")
+	require.NoError(t, err)
+	_, err = companies.ConfirmCompanyDomain(ctx, "orange sa", "orange.com")
+	assert.ErrorIs(t, err, ErrCompanyDomainConfirmed)
+
+	// Le domaine d'une fiche curée n'est pas modifiable par cette voie
+	_, err = companies.CreateCompany(ctx, CompanyInput{Name: "Thales", Domain: "thalesgroup.com"})
+	require.NoError(t, err)
+	_, err = companies.ConfirmCompanyDomain(ctx, "Thales", "thales.com")
+	assert.ErrorIs(t, err, ErrCompanyDomainConfirmed)
+
+	_, err = companies.ConfirmCompanyDomain(ctx, "Orange", " ")
+	assert.ErrorIs(t, err, ErrInvalidCompany)
+	_, err = companies.ConfirmCompanyDomain(ctx, "Globex", "intranet.globex.internal")
+	assert.ErrorIs(t, err, ErrInvalidCompany)
+}
+
+func TestCompanyService_RebuildAliasKeys(t *testing.T) {
+	companies := newTestCompanyService(t)
+	ctx := t.Context()
+
+	company, err := companies.CreateCompany(ctx, CompanyInput{Name: "Société Générale", Aliases: []string{"SocGen"}})
+	require.NoError(t, err)
+
+	// Clés calculées par une ancienne normalisation
+	require.NoError(t, companies.db.Where("1 = 1").Delete(&models.CompanyAlias{}).Error)
+	require.NoError(t, companies.db.Create(&models.CompanyAlias{Alias: "société générale", CompanyID: company.ID}).Error)
+
+	rebuilt, err := companies.RebuildAliasKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rebuilt)
+
+	found, err := companies.FindByName(ctx, "societe generale SA")
+	require.NoError(t, err)
+	assert.Equal(t, company.ID, found.ID)
+	_, err = companies.FindByName(ctx, "socgen")
+	require.NoError(t, err)
+}
+
+func TestGetCompanyInfo_CanonicalCacheKey(t *testing.T) {
+	scraper, redisClient := newTestSourcesScraper(t, nil)
+	companies := newTestCompanyService(t)
+	scraper.SetCompanyDirectory(companies)
+
+	source := &fakeCompanySource{name: CompanySourceWikipedia, result: &CompanySourceResult{
+		Info: models.CompanyInfo{Description: "Banque française."},
+	}}
+	scraper.RegisterSource(source, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 0.8})
+
+	info, err := scraper.GetCompanyInfo(t.Context(), "BNP Paribas")
+	require.NoError(t, err)
+	require.NotEmpty(t, info.CompanyID)
+	assert.Equal(t, "bnpparibas.com", source.query.Domain)
+
+	// Variantes du même nom : une seule entrée de cache, un seul scraping
+	for _, name := range []string{"bnp paribas sa", "BNP  Paribas S.A."} {
+		variant, err := scraper.GetCompanyInfo(t.Context(), name)
+		require.NoError(t, err)
+		assert.Equal(t, info.CompanyID, variant.CompanyID, name)
+	}
+	assert.Equal(t, int32(1), source.calls.Load())
+	assert.Equal(t, int64(1), redisClient.Exists(t.Context(), "company_info:"+info.CompanyID).Val())
+
+	// Le domaine confirmé remplace le domaine deviné au prochain scraping
+	company, err := companies.ConfirmCompanyDomain(t.Context(), "BNP Paribas", "group.bnpparibas")
+	require.NoError(t, err)
+	require.NoError(t, scraper.InvalidateCompanyInfo(t.Context(), company.ID))
+
+	info, err = scraper.GetCompanyInfo(t.Context(), "bnp paribas")
+	require.NoError(t, err)
+	assert.Equal(t, "group.bnpparibas", source.query.Domain)
+	assert.Equal(t, "group.bnpparibas", info.Domain)
+	assert.Equal(t, CompanySourceConfirmed, info.Provenance[CompanyFieldDomain].Source)
+	assert.Equal(t, "BNP Paribas", info.Name)
+}
+
+func TestGetCompanyInfoForDomain_VisitorDomain(t *testing.T) {
+	scraper, redisClient := newTestSourcesScraper(t, nil)
+	companies := newTestCompanyService(t)
+	scraper.SetCompanyDirectory(companies)
+
+	source := &fakeCompanySource{name: CompanySourceWikipedia, result: &CompanySourceResult{
+		Info: models.CompanyInfo{Description: "Opérateur télécom."},
+	}}
+	scraper.RegisterSource(source, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 0.8})
+
+	// Le domaine choisi remplace le domaine deviné, dans une entrée de cache distincte
+	info, err := scraper.GetCompanyInfoForDomain(t.Context(), "Orange", "orange.fr")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.fr", source.query.Domain)
+	assert.Equal(t, "orange.fr", info.Domain)
+	assert.Equal(t, int64(1), redisClient.Exists(t.Context(), "company_info:"+info.CompanyID+":orange.fr").Val())
+
+	_, err = scraper.GetCompanyInfo(t.Context(), "Orange")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.com", source.query.Domain)
+	assert.Equal(t, int32(2), source.calls.Load())
+
+	// Le domaine confirmé par l'administrateur prime sur le choix du visiteur
+	_, err = companies.ConfirmCompanyDomain(t.Context(), "Orange", "orange.com")
+	require.NoError(t, err)
+	info, err = scraper.GetCompanyInfoForDomain(t.Context(), "Orange", "orange.fr")
+	require.NoError(t, err)
+	assert.Equal(t, "orange.com", info.Domain)
+}
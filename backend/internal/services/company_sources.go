@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/models"
+)
+
+// Noms des sources d'informations entreprise
+const (
+	CompanySourceClearbit   = "clearbit"
+	CompanySourceWikipedia  = "wikipedia"
+	CompanySourceWebsite    = "website"
+	CompanySourceGitHub     = "github"
+	CompanySourceDuckDuckGo = "duckduckgo"
+	CompanySourceNews       = "news"
+
+	// Provenances internes : domaine deviné depuis le nom, description générique
+	CompanySourceDomainGuess = "domain_guess"
+	CompanySourceFallback    = "fallback"
+)
+
+// Champs de CompanyInfo suivis par la provenance (noms JSON)
+const (
+	CompanyFieldDomain             = "domain"
+	CompanyFieldDescription        = "description"
+	CompanyFieldIndustry           = "industry"
+	CompanyFieldSize               = "size"
+	CompanyFieldTechnologies       = "technologies"
+	CompanyFieldCulture            = "culture"
+	CompanyFieldValues             = "values"
+	CompanyFieldRecentNews         = "recent_news"
+	CompanyFieldOpenSourceProjects = "open_source_projects"
+)
+
+// Confiance attribuée aux valeurs de repli
+const domainGuessConfidence = 0.3
+
+// companySourceGracePeriod marge laissée aux sources au-delà de leur timeout avant abandon
+const companySourceGracePeriod = 2 * time.Second
+
+// CompanyQuery : entreprise recherchée
+type CompanyQuery struct {
+	Name   string
+	Domain string // Domaine présumé du site officiel
+}
+
+// CompanySource : source d'informations sur une entreprise (API, scraping...)
+// Fetch doit respecter l'annulation du contexte, qui porte le timeout de la source.
+type CompanySource interface {
+	Name() string
+	Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error)
+}
+
+// CompanySourceResult : informations trouvées par une source
+type CompanySourceResult struct {
+	Info models.CompanyInfo
+	URL  string // Page ou endpoint consulté
+
+	// Fiabilité relative d'un champ (0-1, 1 par défaut), ex. taille déduite d'un texte libre
+	FieldConfidence map[string]float64
+}
+
+// CompanySourceOptions : réglages d'une source enregistrée
+type CompanySourceOptions struct {
+	Enabled    bool
+	Priority   int           // 1 = préférée à confiance égale
+	Timeout    time.Duration // Délai max de la source
+	Confidence float64       // Fiabilité de la source (0-1)
+}
+
+// CompanySourceStatus : état d'une source enregistrée
+type CompanySourceStatus struct {
+	Name string `json:"name"`
+	CompanySourceOptions
+}
+
+type companySourceEntry struct {
+	source  CompanySource
+	options CompanySourceOptions
+}
+
+// companySourceFetch : résultat d'une source avec sa date de collecte
+type companySourceFetch struct {
+	entry     companySourceEntry
+	result    *CompanySourceResult
+	fetchedAt time.Time
+}
+
+// RegisterSource ajoute une source (ou remplace celle du même nom)
+// Les réglages de configuration (COMPANY_SOURCE_*) priment sur options.
+func (s *CompanyScraper) RegisterSource(source CompanySource, options CompanySourceOptions) {
+	name := source.Name()
+	if s.config != nil {
+		override := s.config.SourceSettings(name)
+		if override.Disabled {
+			options.Enabled = false
+		}
+		if override.Priority > 0 {
+			options.Priority = override.Priority
+		}
+		if override.Timeout > 0 {
+			options.Timeout = override.Timeout
+		}
+		if override.Confidence > 0 {
+			options.Confidence = override.Confidence
+		}
+	}
+	if options.Timeout <= 0 && s.config != nil {
+		options.Timeout = s.config.Timeout
+	}
+	switch {
+	case options.Confidence <= 0:
+		options.Confidence = 0.5
+	case options.Confidence > 1:
+		options.Confidence = 1
+	}
+
+	entry := companySourceEntry{source: source, options: options}
+	for i, existing := range s.sources {
+		if existing.source.Name() == name {
+			s.sources[i] = entry
+			s.sortSources()
+			return
+		}
+	}
+	s.sources = append(s.sources, entry)
+	s.sortSources()
+}
+
+// Sources retourne les sources enregistrées, par priorité
+func (s *CompanyScraper) Sources() []CompanySourceStatus {
+	statuses := make([]CompanySourceStatus, 0, len(s.sources))
+	for _, entry := range s.sources {
+		statuses = append(statuses, CompanySourceStatus{Name: entry.source.Name(), CompanySourceOptions: entry.options})
+	}
+	return statuses
+}
+
+func (s *CompanyScraper) sortSources() {
+	sort.SliceStable(s.sources, func(i, j int) bool {
+		return s.sources[i].options.Priority < s.sources[j].options.Priority
+	})
+}
+
+// registerDefaultSources enregistre les sources historiques du scraper
+func (s *CompanyScraper) registerDefaultSources() {
+	s.RegisterSource(clearbitSource{s}, CompanySourceOptions{
+		Enabled: s.config.ClearbitAPIKey != "", Priority: 1, Timeout: 8 * time.Second, Confidence: 0.9,
+	})
+	s.RegisterSource(wikipediaSource{s}, CompanySourceOptions{
+		Enabled: true, Priority: 2, Timeout: 10 * time.Second, Confidence: 0.8,
+	})
+	s.RegisterSource(websiteSource{s}, CompanySourceOptions{
+		Enabled: true, Priority: 3, Timeout: 10 * time.Second, Confidence: 0.7,
+	})
+	s.RegisterSource(githubSource{s}, CompanySourceOptions{
+		Enabled: true, Priority: 4, Timeout: 8 * time.Second, Confidence: 0.8,
+	})
+	s.RegisterSource(duckDuckGoSource{s}, CompanySourceOptions{
+		Enabled: true, Priority: 5, Timeout: 8 * time.Second, Confidence: 0.6,
+	})
+	s.RegisterSource(newsSource{s}, CompanySourceOptions{
+		Enabled: true, Priority: 6, Timeout: 10 * time.Second, Confidence: 0.5,
+	})
+}
+
+// collectCompanyInfo interroge les sources actives en parallèle puis fusionne leurs résultats
+// Une source qui ignore son contexte est abandonnée après le plus long timeout (+ marge).
+func (s *CompanyScraper) collectCompanyInfo(ctx context.Context, query CompanyQuery) *models.CompanyInfo {
+	var active []companySourceEntry
+	var wait time.Duration
+	for _, entry := range s.sources {
+		if !entry.options.Enabled {
+			continue
+		}
+		active = append(active, entry)
+		wait = max(wait, entry.options.Timeout)
+	}
+
+	results := make(chan companySourceFetch, len(active))
+	for _, entry := range active {
+		go func() {
+			sourceCtx, cancel := context.WithTimeout(ctx, entry.options.Timeout)
+			defer cancel()
+
+			result, err := entry.source.Fetch(sourceCtx, query)
+			if err != nil || result == nil {
+				log.Debug().Err(err).Str("company", query.Name).Str("source", entry.source.Name()).Msg("Company source fetch failed")
+				results <- companySourceFetch{entry: entry}
+				return
+			}
+			results <- companySourceFetch{entry: entry, result: result, fetchedAt: time.Now()}
+		}()
+	}
+
+	deadline := time.NewTimer(wait + companySourceGracePeriod)
+	defer deadline.Stop()
+
+	var fetched []companySourceFetch
+collect:
+	for range active {
+		select {
+		case fetch := <-results:
+			if fetch.result != nil {
+				log.Info().Str("company", query.Name).Str("source", fetch.entry.source.Name()).Msg("Got company data")
+				fetched = append(fetched, fetch)
+			}
+		case <-deadline.C:
+			log.Warn().Str("company", query.Name).Msg("Timeout waiting for all company sources")
+			break collect
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	return mergeCompanyInfo(query, fetched)
+}
+
+// companyFields champs fusionnables de CompanyInfo
+var companyFields = []struct {
+	name  string
+	empty func(info *models.CompanyInfo) bool
+	copy  func(dst, src *models.CompanyInfo)
+}{
+	{CompanyFieldDomain, func(i *models.CompanyInfo) bool { return i.Domain == "" }, func(d, s *models.CompanyInfo) { d.Domain = s.Domain }},
+	{CompanyFieldDescription, func(i *models.CompanyInfo) bool { return i.Description == "" }, func(d, s *models.CompanyInfo) { d.Description = s.Description }},
+	{CompanyFieldIndustry, func(i *models.CompanyInfo) bool { return i.Industry == "" }, func(d, s *models.CompanyInfo) { d.Industry = s.Industry }},
+	{CompanyFieldSize, func(i *models.CompanyInfo) bool { return i.Size == "" }, func(d, s *models.CompanyInfo) { d.Size = s.Size }},
+	{CompanyFieldTechnologies, func(i *models.CompanyInfo) bool { return len(i.Technologies) == 0 }, func(d, s *models.CompanyInfo) { d.Technologies, d.TechStack = s.Technologies, s.TechStack }},
+	{CompanyFieldCulture, func(i *models.CompanyInfo) bool { return i.Culture == "" }, func(d, s *models.CompanyInfo) { d.Culture = s.Culture }},
+	{CompanyFieldValues, func(i *models.CompanyInfo) bool { return len(i.Values) == 0 }, func(d, s *models.CompanyInfo) { d.Values = s.Values }},
+	{CompanyFieldRecentNews, func(i *models.CompanyInfo) bool { return i.RecentNews == "" }, func(d, s *models.CompanyInfo) { d.RecentNews = s.RecentNews }},
+	{CompanyFieldOpenSourceProjects, func(i *models.CompanyInfo) bool { return i.OpenSourceProjects == "" }, func(d, s *models.CompanyInfo) { d.OpenSourceProjects = s.OpenSourceProjects }},
+}
+
+// mergeCompanyInfo retient, champ par champ, la valeur de confiance la plus élevée
+// À confiance égale, la source de meilleure priorité l'emporte. Chaque champ retenu
+// est tracé dans Provenance ; le domaine deviné et la description générique servent de repli.
+func mergeCompanyInfo(query CompanyQuery, fetched []companySourceFetch) *models.CompanyInfo {
+	info := &models.CompanyInfo{
+		Name:       query.Name,
+		Provenance: map[string]models.FieldProvenance{},
+	}
+	priorities := map[string]int{}
+
+	for _, fetch := range fetched {
+		for _, field := range companyFields {
+			if field.empty(&fetch.result.Info) {
+				continue
+			}
+
+			confidence := fetch.entry.options.Confidence
+			if weight, ok := fetch.result.FieldConfidence[field.name]; ok {
+				confidence *= weight
+			}
+			confidence = math.Round(confidence*100) / 100
+
+			if current, ok := info.Provenance[field.name]; ok {
+				if current.Confidence > confidence ||
+					(current.Confidence == confidence && priorities[field.name] <= fetch.entry.options.Priority) {
+					continue
+				}
+			}
+
+			field.copy(info, &fetch.result.Info)
+			info.Provenance[field.name] = models.FieldProvenance{
+				Source:     fetch.entry.source.Name(),
+				URL:        fetch.result.URL,
+				FetchedAt:  fetch.fetchedAt,
+				Confidence: confidence,
+			}
+			priorities[field.name] = fetch.entry.options.Priority
+		}
+	}
+
+	now := time.Now()
+	if info.Domain == "" && query.Domain != "" {
+		info.Domain = query.Domain
+		info.Provenance[CompanyFieldDomain] = models.FieldProvenance{
+			Source:     CompanySourceDomainGuess,
+			FetchedAt:  now,
+			Confidence: domainGuessConfidence,
+		}
+	}
+	if info.Description == "" {
+		info.Description = fmt.Sprintf("%s est une entreprise.", query.Name)
+		info.Provenance[CompanyFieldDescription] = models.FieldProvenance{
+			Source:    CompanySourceFallback,
+			FetchedAt: now,
+		}
+	}
+
+	return info
+}
+
+// --- Sources intégrées ---
+
+type clearbitSource struct{ scraper *CompanyScraper }
+
+func (clearbitSource) Name() string { return CompanySourceClearbit }
+
+func (s clearbitSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	info, apiURL, err := s.scraper.fetchFromClearbit(ctx, query.Domain)
+	if err != nil {
+		return nil, err
+	}
+	return &CompanySourceResult{Info: *info, URL: apiURL}, nil
+}
+
+type wikipediaSource struct{ scraper *CompanyScraper }
+
+func (wikipediaSource) Name() string { return CompanySourceWikipedia }
+
+func (s wikipediaSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	info, pageURL, err := s.scraper.fetchFromWikipedia(ctx, query.Name)
+	if err != nil {
+		return nil, err
+	}
+	// Secteur et taille sont déduits par mots-clés du résumé
+	return &CompanySourceResult{
+		Info: *info,
+		URL:  pageURL,
+		FieldConfidence: map[string]float64{
+			CompanyFieldIndustry: 0.6,
+			CompanyFieldSize:     0.5,
+		},
+	}, nil
+}
+
+type websiteSource struct{ scraper *CompanyScraper }
+
+func (websiteSource) Name() string { return CompanySourceWebsite }
+
+func (s websiteSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	info, siteURL, err := s.scraper.scrapeCompanyWebsite(ctx, query.Domain)
+	if err != nil {
+		return nil, err
+	}
+	// Technologies : fiabilité moyenne des empreintes retenues (accueil et page carrières)
+	_, techConfidence := techStackSummary(info.TechStack)
+	return &CompanySourceResult{
+		Info:            *info,
+		URL:             siteURL,
+		FieldConfidence: map[string]float64{CompanyFieldTechnologies: techConfidence},
+	}, nil
+}
+
+type githubSource struct{ scraper *CompanyScraper }
+
+func (githubSource) Name() string { return CompanySourceGitHub }
+
+func (s githubSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	projects, orgURL, err := s.scraper.fetchFromGitHub(ctx, query.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &CompanySourceResult{Info: models.CompanyInfo{OpenSourceProjects: projects}, URL: orgURL}, nil
+}
+
+type duckDuckGoSource struct{ scraper *CompanyScraper }
+
+func (duckDuckGoSource) Name() string { return CompanySourceDuckDuckGo }
+
+func (s duckDuckGoSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	description, abstractURL, err := s.scraper.fetchFromDuckDuckGo(ctx, query.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &CompanySourceResult{Info: models.CompanyInfo{Description: description}, URL: abstractURL}, nil
+}
+
+type newsSource struct{ scraper *CompanyScraper }
+
+func (newsSource) Name() string { return CompanySourceNews }
+
+func (s newsSource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	news, newsURL, err := s.scraper.fetchRecentNews(ctx, query.Domain)
+	if err != nil {
+		return nil, err
+	}
+	return &CompanySourceResult{Info: models.CompanyInfo{RecentNews: news}, URL: newsURL}, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+type fakeCompanySource struct {
+	name   string
+	result *CompanySourceResult
+	delay  time.Duration
+	calls  atomic.Int32
+	query  CompanyQuery
+}
+
+func (f *fakeCompanySource) Name() string { return f.name }
+
+func (f *fakeCompanySource) Fetch(ctx context.Context, query CompanyQuery) (*CompanySourceResult, error) {
+	f.calls.Add(1)
+	f.query = query
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.result == nil {
+		return nil, errors.New("nothing found")
+	}
+	return f.result, nil
+}
+
+// newTestSourcesScraper scraper sans les sources réseau par défaut
+func newTestSourcesScraper(t *testing.T, cfg *config.ScraperConfig) (*CompanyScraper, *redis.Client) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	if cfg == nil {
+		cfg = &config.ScraperConfig{Timeout: time.Second, CacheTTL: time.Hour}
+	}
+	scraper := NewCompanyScraper(cfg, redisClient)
+	scraper.sources = nil
+	return scraper, redisClient
+}
+
+func TestNewCompanyScraper_DefaultSources(t *testing.T) {
+	scraper := NewCompanyScraper(&config.ScraperConfig{
+		Timeout: 5 * time.Second,
+		Sources: map[string]config.CompanySourceSettings{
+			CompanySourceNews:      {Disabled: true},
+			CompanySourceWikipedia: {Priority: 9, Timeout: 3 * time.Second, Confidence: 0.95},
+		},
+	}, nil)
+
+	statuses := map[string]CompanySourceStatus{}
+	var order []string
+	for _, status := range scraper.Sources() {
+		statuses[status.Name] = status
+		order = append(order, status.Name)
+	}
+
+	assert.Equal(t, []string{CompanySourceClearbit, CompanySourceWebsite, CompanySourceGitHub, CompanySourceDuckDuckGo, CompanySourceNews, CompanySourceWikipedia}, order)
+	assert.False(t, statuses[CompanySourceClearbit].Enabled, "Clearbit requires an API key")
+	assert.False(t, statuses[CompanySourceNews].Enabled)
+	assert.True(t, statuses[CompanySourceGitHub].Enabled)
+	assert.Equal(t, 3*time.Second, statuses[CompanySourceWikipedia].Timeout)
+	assert.Equal(t, 0.95, statuses[CompanySourceWikipedia].Confidence)
+}
+
+func TestCollectCompanyInfo_MergeByConfidence(t *testing.T) {
+	scraper, _ := newTestSourcesScraper(t, nil)
+
+	encyclopedia := &fakeCompanySource{name: "encyclopedia", result: &CompanySourceResult{
+		URL: "https://wiki.example.com/Acme",
+		Info: models.CompanyInfo{
+			Description: "Acme builds rockets.",
+			Industry:    "Aerospace",
+			Size:        "10,000+ employees",
+		},
+		FieldConfidence: map[string]float64{CompanyFieldSize: 0.5},
+	}}
+	registry := &fakeCompanySource{name: "registry", result: &CompanySourceResult{
+		URL: "https://registry.example.com/acme",
+		Info: models.CompanyInfo{
+			Domain:      "acme.io",
+			Description: "Acme SAS",
+			Size:        "5,000 employees",
+		},
+	}}
+	blog := &fakeCompanySource{name: "blog", result: &CompanySourceResult{
+		URL:  "https://acme.io/blog",
+		Info: models.CompanyInfo{RecentNews: "Launch", Industry: "Rockets"},
+	}}
+
+	scraper.RegisterSource(encyclopedia, CompanySourceOptions{Enabled: true, Priority: 2, Confidence: 0.8})
+	scraper.RegisterSource(registry, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 0.6})
+	scraper.RegisterSource(blog, CompanySourceOptions{Enabled: true, Priority: 3, Confidence: 0.8})
+
+	info := scraper.collectCompanyInfo(t.Context(), CompanyQuery{Name: "Acme", Domain: "acme.com"})
+
+	assert.Equal(t, "Acme", info.Name)
+	assert.Equal(t, "Acme builds rockets.", info.Description, "highest confidence wins")
+	assert.Equal(t, "5,000 employees", info.Size, "field weight lowers the encyclopedia size")
+	assert.Equal(t, "acme.io", info.Domain, "a source beats the guessed domain")
+	assert.Equal(t, "Aerospace", info.Industry, "priority breaks confidence ties")
+	assert.Equal(t, "Launch", info.RecentNews)
+	assert.Equal(t, CompanyQuery{Name: "Acme", Domain: "acme.com"}, registry.query)
+
+	description := info.Provenance[CompanyFieldDescription]
+	assert.Equal(t, "encyclopedia", description.Source)
+	assert.Equal(t, "https://wiki.example.com/Acme", description.URL)
+	assert.Equal(t, 0.8, description.Confidence)
+	assert.False(t, description.FetchedAt.IsZero())
+
+	assert.Equal(t, "registry", info.Provenance[CompanyFieldSize].Source)
+	assert.Equal(t, 0.6, info.Provenance[CompanyFieldSize].Confidence)
+	assert.Equal(t, "encyclopedia", info.Provenance[CompanyFieldIndustry].Source)
+	assert.Equal(t, "blog", info.Provenance[CompanyFieldRecentNews].Source)
+	assert.NotContains(t, info.Provenance, CompanyFieldTechnologies)
+}
+
+func TestCollectCompanyInfo_DisabledSlowAndFailingSources(t *testing.T) {
+	scraper, _ := newTestSourcesScraper(t, &config.ScraperConfig{
+		Timeout: time.Second,
+		Sources: map[string]config.CompanySourceSettings{"paid": {Disabled: true}},
+	})
+
+	paid := &fakeCompanySource{name: "paid", result: &CompanySourceResult{Info: models.CompanyInfo{Description: "paid"}}}
+	slow := &fakeCompanySource{name: "slow", delay: time.Second, result: &CompanySourceResult{Info: models.CompanyInfo{Description: "late"}}}
+	failing := &fakeCompanySource{name: "failing"}
+
+	scraper.RegisterSource(paid, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 1})
+	scraper.RegisterSource(slow, CompanySourceOptions{Enabled: true, Priority: 2, Timeout: 20 * time.Millisecond})
+	scraper.RegisterSource(failing, CompanySourceOptions{Enabled: true, Priority: 3})
+
+	start := time.Now()
+	info := scraper.collectCompanyInfo(t.Context(), CompanyQuery{Name: "Acme", Domain: "acme.com"})
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	assert.Zero(t, paid.calls.Load(), "disabled source must not be called")
+	assert.Equal(t, int32(1), slow.calls.Load())
+	assert.Equal(t, int32(1), failing.calls.Load())
+
+	// Repli : description générique et domaine deviné, tracés comme tels
+	assert.Equal(t, "Acme est une entreprise.", info.Description)
+	assert.Equal(t, CompanySourceFallback, info.Provenance[CompanyFieldDescription].Source)
+	assert.Zero(t, info.Provenance[CompanyFieldDescription].Confidence)
+	assert.Equal(t, "acme.com", info.Domain)
+	assert.Equal(t, CompanySourceDomainGuess, info.Provenance[CompanyFieldDomain].Source)
+}
+
+func TestGetCompanyInfo_CachesProvenance(t *testing.T) {
+	scraper, redisClient := newTestSourcesScraper(t, nil)
+
+	source := &fakeCompanySource{name: CompanySourceGitHub, result: &CompanySourceResult{
+		URL:  "https://github.com/acme",
+		Info: models.CompanyInfo{OpenSourceProjects: "Projets open-source actifs:\n• rocket"},
+	}}
+	scraper.RegisterSource(source, CompanySourceOptions{Enabled: true, Priority: 1, Confidence: 0.8})
+
+	info, err := scraper.GetCompanyInfo(t.Context(), "Acme")
+	require.NoError(t, err)
+	assert.Empty(t, info.RecentNews, "open-source projects are not news")
+	assert.Contains(t, info.OpenSourceProjects, "rocket")
+
+	cached, err := redisClient.Get(t.Context(), "company_info:acme").Result()
+	require.NoError(t, err)
+	var stored models.CompanyInfo
+	require.NoError(t, json.Unmarshal([]byte(cached), &stored))
+	assert.Equal(t, "https://github.com/acme", stored.Provenance[CompanyFieldOpenSourceProjects].URL)
+
+	// Deuxième appel servi par le cache
+	_, err = scraper.GetCompanyInfo(t.Context(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), source.calls.Load())
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+
+	"maicivy/internal/metrics"
+	"maicivy/internal/models"
+)
+
+// cvPDFCacheTTL durée de conservation d'un PDF de CV rendu
+const cvPDFCacheTTL = 24 * time.Hour
+
+// CVPDFArtifact PDF de CV prêt à servir
+type CVPDFArtifact struct {
+	PDF    []byte
+	ETag   string // Validateur faible : même contenu, rendu équivalent
+	Cached bool   // Servi depuis le cache (pas de rendu)
+}
+
+// CVPDFCache cache Redis des PDF de CV, par thème, langue, moteur et empreinte du contenu
+// Un contenu modifié change l'empreinte (donc la clé) ; InvalidateCache supprime les PDF du thème.
+// Les téléchargements simultanés d'un même PDF ne déclenchent qu'un rendu.
+type CVPDFCache struct {
+	redis      *redis.Client
+	pdfService *PDFService
+	ttl        time.Duration
+	group      singleflight.Group
+}
+
+// NewCVPDFCache crée le cache des PDF de CV
+func NewCVPDFCache(redisClient *redis.Client, pdfService *PDFService) *CVPDFCache {
+	return &CVPDFCache{
+		redis:      redisClient,
+		pdfService: pdfService,
+		ttl:        cvPDFCacheTTL,
+	}
+}
+
+// CVContentHash empreinte du contenu rendu d'un CV (date de génération exclue)
+func CVContentHash(cv *AdaptiveCVResponse, engine PDFEngine) string {
+	content, _ := json.Marshal(struct {
+		Theme       any
+		Experiences any
+		Skills      any
+		Projects    any
+		Language    models.Language
+		Engine      PDFEngine
+	}{cv.Theme, cv.Experiences, cv.Skills, cv.Projects, cv.Language, engine})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16])
+}
+
+// CVPDFETag validateur HTTP d'un PDF de CV (connu sans rendu : réponses 304)
+func CVPDFETag(cv *AdaptiveCVResponse, engine PDFEngine) string {
+	return cvPDFETag(CVContentHash(cv, engine))
+}
+
+func cvPDFETag(hash string) string {
+	return fmt.Sprintf(`W/"%s"`, hash)
+}
+
+// cvPDFKey clé Redis d'un PDF de CV
+func cvPDFKey(themeID, hash string) string {
+	return fmt.Sprintf("cv:pdf:%s:%s", themeID, hash)
+}
+
+// Get retourne le PDF du CV (localisé) depuis le cache, ou le rend et le met en cache
+func (c *CVPDFCache) Get(ctx context.Context, cv *AdaptiveCVResponse, engine PDFEngine) (*CVPDFArtifact, error) {
+	hash := CVContentHash(cv, engine)
+	key := cvPDFKey(cv.Theme.ID, hash)
+	etag := cvPDFETag(hash)
+
+	start := time.Now()
+	if cached, err := c.redis.Get(ctx, key).Bytes(); err == nil && len(cached) > 0 {
+		metrics.RecordCacheHit("cv_pdf", "cv:pdf", time.Since(start).Seconds())
+		return &CVPDFArtifact{PDF: cached, ETag: etag, Cached: true}, nil
+	}
+	metrics.RecordCacheMiss("cv_pdf", "cv:pdf", time.Since(start).Seconds())
+
+	// Un seul rendu par clé : le rendu partagé ne dépend pas de l'annulation du premier appelant
+	renderCtx := context.WithoutCancel(ctx)
+	result := c.group.DoChan(key, func() (any, error) {
+		pdfBytes, err := c.pdfService.GenerateCVPDFWithEngine(renderCtx, cv, engine)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.redis.Set(renderCtx, key, pdfBytes, c.ttl).Err(); err != nil {
+			log.Warn().Err(err).Str("theme", cv.Theme.ID).Msg("Failed to cache CV PDF")
+		}
+		return pdfBytes, nil
+	})
+
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return &CVPDFArtifact{PDF: res.Val.([]byte), ETag: etag}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate supprime les PDF en cache d'un thème (ou de tous si themeID vide)
+func (c *CVPDFCache) Invalidate(ctx context.Context, themeID string) error {
+	return deleteCVPDFs(ctx, c.redis, themeID)
+}
+
+// deleteCVPDFs supprime les PDF de CV en cache (toutes langues, moteurs et empreintes)
+func deleteCVPDFs(ctx context.Context, redisClient *redis.Client, themeID string) error {
+	pattern := "cv:pdf:*"
+	if themeID != "" {
+		pattern = cvPDFKey(themeID, "*")
+	}
+
+	iter := redisClient.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := redisClient.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"html/template"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+func newTestCVPDFCache(t *testing.T, cfg config.PDFConfig, setup func(b *fakeBrowser)) (*CVPDFCache, *redis.Client, *[]*fakeBrowser) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	renderer, browsers := newFakeRenderer(cfg, setup)
+	t.Cleanup(renderer.Close)
+
+	pdfService := &PDFService{templates: template.Must(template.New("cv_base.html").Parse("<h1>{{.Theme.Name}}</h1>"))}
+	pdfService.SetRenderer(renderer)
+	return NewCVPDFCache(redisClient, pdfService), redisClient, browsers
+}
+
+func testCV() *AdaptiveCVResponse {
+	return &AdaptiveCVResponse{
+		Theme:       config.CVTheme{ID: "backend", Name: "Backend"},
+		Skills:      []ScoredSkillResponse{{Skill: models.Skill{Name: "Go", Level: "expert"}, Score: 0.9}},
+		Language:    models.LanguageFrench,
+		GeneratedAt: time.Now(),
+	}
+}
+
+func TestCVContentHash(t *testing.T) {
+	cv := testCV()
+	hash := CVContentHash(cv, PDFEngineAuto)
+
+	regenerated := *cv
+	regenerated.GeneratedAt = cv.GeneratedAt.Add(time.Hour)
+	assert.Equal(t, hash, CVContentHash(&regenerated, PDFEngineAuto), "la date de génération n'entre pas dans l'empreinte")
+
+	assert.NotEqual(t, hash, CVContentHash(cv, PDFEngineNative))
+	assert.NotEqual(t, hash, CVContentHash(cv.Localize(models.LanguageEnglish), PDFEngineAuto))
+
+	changed := *cv
+	changed.Skills = []ScoredSkillResponse{{Skill: models.Skill{Name: "Rust", Level: "expert"}, Score: 0.9}}
+	assert.NotEqual(t, hash, CVContentHash(&changed, PDFEngineAuto))
+
+	assert.Equal(t, `W/"`+hash+`"`, CVPDFETag(cv, PDFEngineAuto))
+}
+
+func TestCVPDFCache_CachesRender(t *testing.T) {
+	cache, _, browsers := newTestCVPDFCache(t, config.PDFConfig{MaxTabs: 1}, nil)
+	ctx := context.Background()
+
+	first, err := cache.Get(ctx, testCV(), PDFEngineBrowser)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := cache.Get(ctx, testCV(), PDFEngineBrowser)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.PDF, second.PDF)
+	assert.Equal(t, first.ETag, second.ETag)
+	assert.Equal(t, 1, (*browsers)[0].renders())
+}
+
+func TestCVPDFCache_CoalescesConcurrentRenders(t *testing.T) {
+	block := make(chan struct{})
+	// Un seul onglet et pas de file : un second rendu serait refusé
+	cache, _, browsers := newTestCVPDFCache(t, config.PDFConfig{MaxTabs: 1, QueueSize: 0}, func(b *fakeBrowser) { b.block = block })
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Get(context.Background(), testCV(), PDFEngineBrowser)
+			errs <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(block)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, (*browsers)[0].renders())
+}
+
+func TestCVService_InvalidateCacheRemovesPDFs(t *testing.T) {
+	cache, redisClient, _ := newTestCVPDFCache(t, config.PDFConfig{MaxTabs: 1}, nil)
+	ctx := context.Background()
+
+	_, err := cache.Get(ctx, testCV(), PDFEngineBrowser)
+	require.NoError(t, err)
+	other := testCV()
+	other.Theme.ID = "devops"
+	_, err = cache.Get(ctx, other, PDFEngineBrowser)
+	require.NoError(t, err)
+
+	service := NewCVService(nil, redisClient)
+	require.NoError(t, service.InvalidateCache(ctx, "backend"))
+
+	keys, _ := redisClient.Keys(ctx, "cv:pdf:*").Result()
+	require.Len(t, keys, 1)
+	assert.Contains(t, keys[0], "cv:pdf:devops:")
+
+	require.NoError(t, service.InvalidateCache(ctx, ""))
+	keys, _ = redisClient.Keys(ctx, "cv:pdf:*").Result()
+	assert.Empty(t, keys)
+}
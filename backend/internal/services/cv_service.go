@@ -54,9 +54,34 @@ type AdaptiveCVResponse struct {
 	Experiences []ScoredExperienceResponse `json:"experiences"`
 	Skills      []ScoredSkillResponse      `json:"skills"`
 	Projects    []ScoredProjectResponse    `json:"projects"`
+	Language    models.Language            `json:"language,omitempty"`
 	GeneratedAt time.Time                  `json:"generatedAt"`
 }
 
+// Localize retourne une copie du CV dont les expériences, compétences et projets
+// sont traduits dans la langue demandée (le cache Redis conserve toutes les traductions)
+func (r *AdaptiveCVResponse) Localize(lang models.Language) *AdaptiveCVResponse {
+	localized := *r
+	localized.Language = lang
+
+	localized.Experiences = make([]ScoredExperienceResponse, len(r.Experiences))
+	for i, exp := range r.Experiences {
+		localized.Experiences[i] = ScoredExperienceResponse{Experience: exp.Experience.Localize(lang), Score: exp.Score}
+	}
+
+	localized.Skills = make([]ScoredSkillResponse, len(r.Skills))
+	for i, skill := range r.Skills {
+		localized.Skills[i] = ScoredSkillResponse{Skill: skill.Skill.Localize(lang), Score: skill.Score}
+	}
+
+	localized.Projects = make([]ScoredProjectResponse, len(r.Projects))
+	for i, project := range r.Projects {
+		localized.Projects[i] = ScoredProjectResponse{Project: project.Project.Localize(lang), Score: project.Score}
+	}
+
+	return &localized
+}
+
 // GetAdaptiveCV retourne le CV adapté au thème demandé
 func (s *CVService) GetAdaptiveCV(ctx context.Context, themeID string) (*AdaptiveCVResponse, error) {
 	// 1. Vérifier si thème existe
@@ -183,7 +208,7 @@ func (s *CVService) GetAvailableThemes() []config.CVTheme {
 	return result
 }
 
-// InvalidateCache invalide le cache pour un thème (ou tous si themeID vide)
+// InvalidateCache invalide le cache pour un thème (ou tous si themeID vide), PDF rendus compris
 func (s *CVService) InvalidateCache(ctx context.Context, themeID string) error {
 	if themeID == "" {
 		// Invalider tous les thèmes
@@ -197,5 +222,5 @@ func (s *CVService) InvalidateCache(ctx context.Context, themeID string) error {
 		key := fmt.Sprintf("cv:theme:%s", themeID)
 		s.redis.Del(ctx, key)
 	}
-	return nil
+	return deleteCVPDFs(ctx, s.redis, themeID)
 }
@@ -0,0 +1,536 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/models"
+)
+
+const (
+	maxJobPostingTextLength = 20000
+	maxJobPostingItems      = 15
+	maxJobPostingItemLength = 300
+)
+
+// Titres de sections reconnus dans une offre (FR/EN, en minuscules)
+var (
+	responsibilityHeadings = []string{
+		"mission", "responsabilit", "responsibilit", "what you'll do", "what you will do",
+		"ce que vous ferez", "ce que tu feras", "votre rôle", "ton rôle", "your role",
+		"le poste", "the role", "tâches", "au quotidien", "day to day", "day-to-day",
+	}
+	requirementHeadings = []string{
+		"profil", "requirement", "qualification", "compétences", "prérequis", "pré-requis",
+		"what you'll need", "what you will need", "what we're looking for", "what we are looking for",
+		"who you are", "vous êtes", "tu es", "must have", "nice to have", "skills",
+		"expérience requise", "about you", "your profile",
+	}
+	otherHeadings = []string{
+		"avantages", "benefits", "perks", "about us", "à propos", "qui sommes-nous", "qui sommes nous",
+		"why join", "pourquoi nous rejoindre", "processus", "process", "salaire", "salary", "rémunération",
+	}
+
+	// Indices d'une exigence pour les offres sans sections identifiables
+	requirementCues = []string{
+		"expérience", "experience", "maîtrise", "connaissance", "knowledge", "years", " ans",
+		"diplôme", "degree", "proficien", "familiar", "bac+", "master", "requis", "required",
+	}
+
+	bulletPrefix = regexp.MustCompile(`^\s*(?:[-*•·▪◦‣–—]|\d+[.)])\s*`)
+)
+
+// techKeyword mot-clé technique détecté dans une offre
+type techKeyword struct {
+	name          string
+	patterns      []string
+	caseSensitive bool
+}
+
+var techKeywords = []techKeyword{
+	{name: "Go", patterns: []string{"Go", "Golang", "golang"}, caseSensitive: true},
+	{name: "Python", patterns: []string{"python"}},
+	{name: "Java", patterns: []string{"java"}},
+	{name: "Kotlin", patterns: []string{"kotlin"}},
+	{name: "Scala", patterns: []string{"scala"}},
+	{name: "Rust", patterns: []string{"Rust"}, caseSensitive: true},
+	{name: "C++", patterns: []string{"c++", "cpp"}},
+	{name: "C#", patterns: []string{"c#"}},
+	{name: ".NET", patterns: []string{".net", "dotnet"}},
+	{name: "PHP", patterns: []string{"php"}},
+	{name: "Ruby", patterns: []string{"ruby", "rails"}},
+	{name: "JavaScript", patterns: []string{"javascript"}},
+	{name: "TypeScript", patterns: []string{"typescript"}},
+	{name: "Node.js", patterns: []string{"node.js", "nodejs"}},
+	{name: "React", patterns: []string{"React", "React.js", "ReactJS"}, caseSensitive: true},
+	{name: "Next.js", patterns: []string{"next.js", "nextjs"}},
+	{name: "Vue.js", patterns: []string{"Vue", "Vue.js", "VueJS"}, caseSensitive: true},
+	{name: "Angular", patterns: []string{"angular"}},
+	{name: "PostgreSQL", patterns: []string{"postgresql", "postgres"}},
+	{name: "MySQL", patterns: []string{"mysql"}},
+	{name: "MongoDB", patterns: []string{"mongodb"}},
+	{name: "Redis", patterns: []string{"redis"}},
+	{name: "Elasticsearch", patterns: []string{"elasticsearch"}},
+	{name: "Kafka", patterns: []string{"kafka"}},
+	{name: "RabbitMQ", patterns: []string{"rabbitmq"}},
+	{name: "GraphQL", patterns: []string{"graphql"}},
+	{name: "gRPC", patterns: []string{"grpc"}},
+	{name: "REST", patterns: []string{"REST", "RESTful"}, caseSensitive: true},
+	{name: "Docker", patterns: []string{"docker"}},
+	{name: "Kubernetes", patterns: []string{"kubernetes", "k8s"}},
+	{name: "Terraform", patterns: []string{"terraform"}},
+	{name: "Ansible", patterns: []string{"ansible"}},
+	{name: "AWS", patterns: []string{"aws", "amazon web services"}},
+	{name: "GCP", patterns: []string{"gcp", "google cloud"}},
+	{name: "Azure", patterns: []string{"azure"}},
+	{name: "Linux", patterns: []string{"linux"}},
+	{name: "CI/CD", patterns: []string{"ci/cd", "gitlab ci", "github actions", "jenkins"}},
+	{name: "Microservices", patterns: []string{"microservices", "micro-services"}},
+	{name: "Prometheus", patterns: []string{"prometheus"}},
+	{name: "Grafana", patterns: []string{"grafana"}},
+	{name: "SQL", patterns: []string{"sql"}},
+	{name: "Spark", patterns: []string{"Spark"}, caseSensitive: true},
+	{name: "Airflow", patterns: []string{"airflow"}},
+	{name: "Machine Learning", patterns: []string{"machine learning", "deep learning"}},
+	{name: "LLM", patterns: []string{"llm", "llms"}},
+	{name: "Swift", patterns: []string{"Swift"}, caseSensitive: true},
+	{name: "Flutter", patterns: []string{"flutter"}},
+	{name: "Qt", patterns: []string{"Qt"}, caseSensitive: true},
+}
+
+// techKeywordPatterns regex compilées (frontières adaptées à c++, c#, .net...)
+var techKeywordPatterns = compileTechKeywords()
+
+func compileTechKeywords() map[string][]*regexp.Regexp {
+	compiled := make(map[string][]*regexp.Regexp, len(techKeywords))
+	for _, kw := range techKeywords {
+		for _, p := range kw.patterns {
+			expr := `(^|[^\w+#.])` + regexp.QuoteMeta(p) + `($|[^\w+#])`
+			if !kw.caseSensitive {
+				expr = "(?i)" + expr
+			}
+			compiled[kw.name] = append(compiled[kw.name], regexp.MustCompile(expr))
+		}
+	}
+	return compiled
+}
+
+// ParseJobPosting extrait missions, exigences et mots-clés techniques d'une offre en texte brut
+func ParseJobPosting(text string) *models.JobPosting {
+	text = truncateRunes(strings.TrimSpace(text), maxJobPostingTextLength)
+
+	posting := &models.JobPosting{
+		Source:  models.JobPostingSourceText,
+		RawText: text,
+	}
+
+	const (
+		sectionNone = iota
+		sectionResponsibilities
+		sectionRequirements
+		sectionOther
+	)
+
+	section := sectionNone
+	foundHeading := false
+	var unclassified []string
+
+	for _, rawLine := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+
+		// Changement de section (ligne courte ou terminée par ":", hors puces)
+		if isHeadingLine(line) {
+			heading := strings.ToLower(strings.TrimRight(line, " :"))
+			switch {
+			case matchesAny(heading, responsibilityHeadings):
+				section, foundHeading = sectionResponsibilities, true
+				continue
+			case matchesAny(heading, requirementHeadings):
+				section, foundHeading = sectionRequirements, true
+				continue
+			case matchesAny(heading, otherHeadings):
+				section, foundHeading = sectionOther, true
+				continue
+			}
+		}
+
+		item := strings.TrimSpace(bulletPrefix.ReplaceAllString(line, ""))
+		if len(item) < 3 || len(item) > maxJobPostingItemLength {
+			continue
+		}
+
+		switch section {
+		case sectionResponsibilities:
+			posting.Responsibilities = appendItem(posting.Responsibilities, item)
+		case sectionRequirements:
+			posting.Requirements = appendItem(posting.Requirements, item)
+		case sectionNone:
+			if bulletPrefix.MatchString(line) {
+				unclassified = append(unclassified, item)
+			}
+		}
+	}
+
+	// Offre sans titres de sections : classer les puces par indices
+	if !foundHeading {
+		for _, item := range unclassified {
+			if matchesAny(strings.ToLower(item), requirementCues) {
+				posting.Requirements = appendItem(posting.Requirements, item)
+			} else {
+				posting.Responsibilities = appendItem(posting.Responsibilities, item)
+			}
+		}
+	}
+
+	posting.TechKeywords = extractTechKeywords(text)
+
+	return posting
+}
+
+// extractTechKeywords retourne les technologies citées, dans l'ordre d'apparition
+func extractTechKeywords(text string) []string {
+	type found struct {
+		name string
+		pos  int
+	}
+
+	var matches []found
+	for _, kw := range techKeywords {
+		pos := -1
+		for _, re := range techKeywordPatterns[kw.name] {
+			if loc := re.FindStringIndex(text); loc != nil && (pos < 0 || loc[0] < pos) {
+				pos = loc[0]
+			}
+		}
+		if pos >= 0 {
+			matches = append(matches, found{kw.name, pos})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].pos < matches[j].pos })
+
+	keywords := make([]string, len(matches))
+	for i, m := range matches {
+		keywords[i] = m.name
+	}
+	return keywords
+}
+
+// FetchJobPosting récupère une offre d'emploi depuis son URL puis l'analyse
+// Les données JSON-LD schema.org/JobPosting sont privilégiées, sinon le texte principal de la page.
+func (s *CompanyScraper) FetchJobPosting(ctx context.Context, postingURL string) (*models.JobPosting, error) {
+	parsed, err := url.Parse(postingURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job posting URL: %w", err)
+	}
+	if err := s.checkPublicURL(parsed); err != nil {
+		return nil, err
+	}
+
+	var (
+		title       string
+		companyName string
+		ldText      string
+		pageText    string
+	)
+
+	c := colly.NewCollector(
+		colly.UserAgent(s.config.UserAgent),
+		colly.MaxDepth(1),
+	)
+	c.SetRequestTimeout(s.config.Timeout)
+
+	// Chaque redirection est revérifiée (pas de rebond vers le réseau interne)
+	c.SetRedirectHandler(func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("too many redirects")
+		}
+		return s.checkPublicURL(req.URL)
+	})
+
+	c.OnRequest(func(r *colly.Request) {
+		if ctx.Err() != nil {
+			r.Abort()
+		}
+	})
+
+	// Données structurées schema.org
+	c.OnHTML("script[type='application/ld+json']", func(e *colly.HTMLElement) {
+		if ldText != "" {
+			return
+		}
+		if ld := findJSONLDJobPosting([]byte(e.Text)); ld != nil {
+			title = ld.Title
+			companyName = ld.HiringOrganization.Name
+			ldText = ld.text()
+		}
+	})
+
+	c.OnHTML("meta[property='og:title']", func(e *colly.HTMLElement) {
+		if title == "" {
+			title = strings.TrimSpace(e.Attr("content"))
+		}
+	})
+
+	c.OnHTML("h1", func(e *colly.HTMLElement) {
+		if title == "" {
+			title = strings.TrimSpace(e.Text)
+		}
+	})
+
+	// Texte principal (fallback sans JSON-LD)
+	c.OnHTML("body", func(e *colly.HTMLElement) {
+		body := e.DOM.Clone()
+		body.Find("script, style, noscript, nav, header, footer, form").Remove()
+
+		main := body.Find("main, article, [role=main]").First()
+		if main.Length() == 0 {
+			main = body
+		}
+		pageText = selectionText(main)
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		log.Debug().Err(err).Str("url", r.Request.URL.String()).Msg("Job posting scraping error")
+	})
+
+	if err := c.Visit(parsed.String()); err != nil {
+		return nil, fmt.Errorf("failed to fetch job posting: %w", err)
+	}
+
+	text := ldText
+	if text == "" {
+		text = pageText
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("no content found at %s", parsed.Host)
+	}
+
+	posting := ParseJobPosting(text)
+	posting.Source = models.JobPostingSourceURL
+	posting.SourceURL = parsed.String()
+	posting.Title = truncateRunes(title, 255)
+	posting.CompanyName = truncateRunes(companyName, 255)
+
+	log.Info().
+		Str("url", posting.SourceURL).
+		Str("title", posting.Title).
+		Bool("json_ld", ldText != "").
+		Int("requirements", len(posting.Requirements)).
+		Int("responsibilities", len(posting.Responsibilities)).
+		Int("tech_keywords", len(posting.TechKeywords)).
+		Msg("Job posting fetched")
+
+	return posting, nil
+}
+
+// lookupIP résolution DNS des hôtes vérifiés par checkPublicURL (remplacée dans les tests)
+var lookupIP = net.LookupIP
+
+// checkPublicURL refuse les schémas non HTTP et les hôtes internes (SSRF)
+func (s *CompanyScraper) checkPublicURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("missing URL host")
+	}
+	if s.allowPrivateHosts {
+		return nil
+	}
+
+	ips, err := lookupIP(u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return fmt.Errorf("host %s resolves to a non-public address", u.Hostname())
+		}
+	}
+	return nil
+}
+
+// checkPublicRedirect politique de redirection des clients HTTP du scraper
+// Chaque redirection est revérifiée (pas de rebond vers le réseau interne).
+func (s *CompanyScraper) checkPublicRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 5 {
+		return fmt.Errorf("too many redirects")
+	}
+	return s.checkPublicURL(req.URL)
+}
+
+// jsonLDJobPosting sous-ensemble de schema.org/JobPosting
+type jsonLDJobPosting struct {
+	Type               interface{} `json:"@type"`
+	Title              string      `json:"title"`
+	Description        string      `json:"description"`
+	Responsibilities   string      `json:"responsibilities"`
+	Qualifications     string      `json:"qualifications"`
+	Skills             string      `json:"skills"`
+	ExperienceReqs     string      `json:"experienceRequirements"`
+	HiringOrganization struct {
+		Name string `json:"name"`
+	} `json:"hiringOrganization"`
+
+	// Champs de liste des offres (parcours des pages carrières)
+	URL             string          `json:"url"`
+	DatePosted      string          `json:"datePosted"`
+	EmploymentType  interface{}     `json:"employmentType"` // Chaîne ou liste
+	JobLocation     json.RawMessage `json:"jobLocation"`    // Place ou liste de Place
+	JobLocationType string          `json:"jobLocationType"`
+}
+
+// text reconstitue un texte à sections exploitable par ParseJobPosting
+func (ld *jsonLDJobPosting) text() string {
+	var sb strings.Builder
+	sb.WriteString(htmlToText(ld.Description))
+	if ld.Responsibilities != "" {
+		sb.WriteString("\n\nResponsibilities:\n")
+		sb.WriteString(htmlToText(ld.Responsibilities))
+	}
+	if ld.Qualifications != "" || ld.Skills != "" || ld.ExperienceReqs != "" {
+		sb.WriteString("\n\nRequirements:\n")
+		for _, part := range []string{ld.Qualifications, ld.Skills, ld.ExperienceReqs} {
+			if part != "" {
+				sb.WriteString(htmlToText(part))
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+func (ld *jsonLDJobPosting) isJobPosting() bool {
+	switch t := ld.Type.(type) {
+	case string:
+		return t == "JobPosting"
+	case []interface{}:
+		for _, v := range t {
+			if v == "JobPosting" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findJSONLDJobPosting cherche un JobPosting dans un bloc JSON-LD (objet, tableau ou @graph)
+func findJSONLDJobPosting(data []byte) *jsonLDJobPosting {
+	if postings := findJSONLDJobPostings(data); len(postings) > 0 {
+		return postings[0]
+	}
+	return nil
+}
+
+// findJSONLDJobPostings tous les JobPosting d'un bloc JSON-LD (page listant plusieurs offres)
+func findJSONLDJobPostings(data []byte) []*jsonLDJobPosting {
+	var single jsonLDJobPosting
+	if err := json.Unmarshal(data, &single); err == nil && single.isJobPosting() {
+		return []*jsonLDJobPosting{&single}
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		var graph struct {
+			Graph []json.RawMessage `json:"@graph"`
+		}
+		if err := json.Unmarshal(data, &graph); err != nil {
+			return nil
+		}
+		list = graph.Graph
+	}
+
+	var postings []*jsonLDJobPosting
+	for _, raw := range list {
+		postings = append(postings, findJSONLDJobPostings(raw)...)
+	}
+	return postings
+}
+
+// htmlToText convertit un fragment HTML en texte en conservant les retours à la ligne
+func htmlToText(fragment string) string {
+	if !strings.Contains(fragment, "<") {
+		return strings.TrimSpace(fragment)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return strings.TrimSpace(fragment)
+	}
+	return selectionText(doc.Selection)
+}
+
+// selectionText extrait le texte d'un noeud HTML, une ligne par bloc et "- " devant les puces
+func selectionText(sel *goquery.Selection) string {
+	sel.Find("br").ReplaceWithHtml("\n")
+	sel.Find("li").Each(func(_ int, li *goquery.Selection) {
+		li.PrependHtml("\n- ")
+	})
+	sel.Find("p, div, h1, h2, h3, h4, h5, h6, ul, ol, section, tr").Each(func(_ int, block *goquery.Selection) {
+		block.AppendHtml("\n")
+	})
+
+	var lines []string
+	for _, line := range strings.Split(sel.Text(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isHeadingLine indique si une ligne peut être un titre de section
+func isHeadingLine(line string) bool {
+	if bulletPrefix.MatchString(line) {
+		return false
+	}
+	return len([]rune(line)) <= 40 || (strings.HasSuffix(line, ":") && len([]rune(line)) <= 80)
+}
+
+// matchesAny indique si s contient l'un des motifs
+func matchesAny(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendItem ajoute un élément sans doublon, dans la limite de maxJobPostingItems
+func appendItem(items []string, item string) []string {
+	if len(items) >= maxJobPostingItems {
+		return items
+	}
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// truncateRunes tronque une chaîne à max caractères
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+const frenchPosting = `Développeur Backend Go (H/F)
+
+Acme recrute un développeur pour renforcer son équipe plateforme.
+
+Vos missions :
+- Concevoir et maintenir des APIs REST en Go
+- Participer aux revues de code
+- Améliorer l'observabilité avec Prometheus et Grafana
+
+Profil recherché :
+- 3 ans d'expérience minimum en développement backend
+- Maîtrise de PostgreSQL et Redis
+- Connaissance de Kubernetes appréciée
+
+Avantages :
+- Télétravail partiel
+- Tickets restaurant`
+
+func TestParseJobPosting_Sections(t *testing.T) {
+	posting := ParseJobPosting(frenchPosting)
+
+	assert.Equal(t, models.JobPostingSourceText, posting.Source)
+	assert.Equal(t, []string{
+		"Concevoir et maintenir des APIs REST en Go",
+		"Participer aux revues de code",
+		"Améliorer l'observabilité avec Prometheus et Grafana",
+	}, []string(posting.Responsibilities))
+	assert.Equal(t, []string{
+		"3 ans d'expérience minimum en développement backend",
+		"Maîtrise de PostgreSQL et Redis",
+		"Connaissance de Kubernetes appréciée",
+	}, []string(posting.Requirements))
+
+	// Les avantages ne sont ni des missions ni des exigences
+	assert.NotContains(t, posting.Requirements, "Télétravail partiel")
+	assert.Equal(t, []string{"Go", "REST", "Prometheus", "Grafana", "PostgreSQL", "Redis", "Kubernetes"}, []string(posting.TechKeywords))
+	assert.False(t, posting.IsEmpty())
+}
+
+func TestParseJobPosting_WithoutHeadings(t *testing.T) {
+	posting := ParseJobPosting(`We are hiring!
+* Build data pipelines on AWS
+* 5+ years of experience with Python
+* Let's go to production together`)
+
+	assert.Equal(t, []string{"Build data pipelines on AWS", "Let's go to production together"}, []string(posting.Responsibilities))
+	assert.Equal(t, []string{"5+ years of experience with Python"}, []string(posting.Requirements))
+
+	// "go" en minuscules n'est pas le langage Go
+	assert.Equal(t, []string{"AWS", "Python"}, []string(posting.TechKeywords))
+}
+
+func TestExtractTechKeywords_Boundaries(t *testing.T) {
+	keywords := extractTechKeywords("Stack: C++, C#, .NET, Node.js et JavaScript (pas de Java pur)")
+	assert.Equal(t, []string{"C++", "C#", ".NET", "Node.js", "JavaScript", "Java"}, keywords)
+}
+
+func newTestJobPostingScraper() *CompanyScraper {
+	scraper := NewCompanyScraper(&config.ScraperConfig{
+		UserAgent: "maicivy-test",
+		Timeout:   5 * time.Second,
+	}, nil)
+	scraper.allowPrivateHosts = true
+	return scraper
+}
+
+func TestFetchJobPosting_JSONLD(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "Organization", "name": "Acme"},
+  {"@type": "JobPosting", "title": "Site Reliability Engineer",
+   "hiringOrganization": {"@type": "Organization", "name": "Acme"},
+   "description": "<p>Join our SRE team.</p><h3>Responsibilities</h3><ul><li>Run Kubernetes clusters</li><li>Automate with Terraform</li></ul>",
+   "qualifications": "<ul><li>Strong Linux knowledge</li></ul>"}
+]}
+</script></head><body><h1>Ignored title</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	posting, err := newTestJobPostingScraper().FetchJobPosting(context.Background(), server.URL+"/jobs/sre")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.JobPostingSourceURL, posting.Source)
+	assert.Equal(t, server.URL+"/jobs/sre", posting.SourceURL)
+	assert.Equal(t, "Site Reliability Engineer", posting.Title)
+	assert.Equal(t, "Acme", posting.CompanyName)
+	assert.Equal(t, []string{"Run Kubernetes clusters", "Automate with Terraform"}, []string(posting.Responsibilities))
+	assert.Equal(t, []string{"Strong Linux knowledge"}, []string(posting.Requirements))
+	assert.Equal(t, []string{"Kubernetes", "Terraform", "Linux"}, []string(posting.TechKeywords))
+}
+
+func TestFetchJobPosting_HTMLFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>
+<nav>Menu Docker</nav>
+<main>
+  <h1>Ingénieur Data</h1>
+  <h2>Vos missions</h2>
+  <ul><li>Industrialiser les pipelines Airflow</li></ul>
+  <h2>Profil</h2>
+  <ul><li>Expérience avec Spark</li></ul>
+</main>
+<script>var docker = true;</script>
+</body></html>`))
+	}))
+	defer server.Close()
+
+	posting, err := newTestJobPostingScraper().FetchJobPosting(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Ingénieur Data", posting.Title)
+	assert.Equal(t, []string{"Industrialiser les pipelines Airflow"}, []string(posting.Responsibilities))
+	assert.Equal(t, []string{"Expérience avec Spark"}, []string(posting.Requirements))
+	// Navigation et scripts ignorés
+	assert.Equal(t, []string{"Airflow", "Spark"}, []string(posting.TechKeywords))
+}
+
+func TestCheckPublicURL(t *testing.T) {
+	scraper := NewCompanyScraper(&config.ScraperConfig{}, nil)
+
+	testCases := []struct {
+		name   string
+		rawURL string
+	}{
+		{"File scheme", "file:///etc/passwd"},
+		{"Loopback", "http://127.0.0.1:8080/admin"},
+		{"Private network", "http://10.0.0.1/"},
+		{"Localhost", "http://localhost/"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.rawURL)
+			require.NoError(t, err)
+			assert.Error(t, scraper.checkPublicURL(u))
+		})
+	}
+}
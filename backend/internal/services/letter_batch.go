@@ -0,0 +1,475 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+const (
+	// LetterBatchOwnerSession pseudo-session des lettres de campagne (propriétaire du site)
+	LetterBatchOwnerSession = "owner"
+	// DefaultOwnerDailyQuota générations de campagne par jour (une par entreprise)
+	DefaultOwnerDailyQuota = 100
+	// DefaultLetterBatchMaxItems entreprises par campagne
+	DefaultLetterBatchMaxItems = 100
+
+	// ownerQuotaKey compteur journalier du propriétaire (remplace le rate limit par session)
+	ownerQuotaKey = "ratelimit:ai:owner:daily"
+)
+
+// ErrBatchNotFound campagne inconnue
+var ErrBatchNotFound = errors.New("batch not found")
+
+// ErrEmptyBatch aucune entreprise dans la liste
+var ErrEmptyBatch = errors.New("batch has no company")
+
+// OwnerQuotaError quota journalier du propriétaire insuffisant pour la campagne
+type OwnerQuotaError struct {
+	Limit     int
+	Used      int
+	Requested int
+}
+
+func (e *OwnerQuotaError) Error() string {
+	return fmt.Sprintf("owner daily quota exceeded: %d requested, %d of %d left", e.Requested, max(e.Limit-e.Used, 0), e.Limit)
+}
+
+// LetterBatchItemRequest entreprise cible d'une campagne
+type LetterBatchItemRequest struct {
+	CompanyName   string `json:"company_name"`
+	JobTitle      string `json:"job_title,omitempty"`
+	Theme         string `json:"theme,omitempty"`
+	JobPostingURL string `json:"job_posting_url,omitempty"`
+}
+
+// LetterBatchRequest paramètres d'une nouvelle campagne
+type LetterBatchRequest struct {
+	Name        string
+	Language    string
+	LetterTypes []models.LetterType // Vide = DefaultLetterTypes
+	Items       []LetterBatchItemRequest
+}
+
+// letterBatchColumns colonnes reconnues des fichiers CSV (en-têtes, insensibles à la casse)
+var letterBatchColumns = map[string]string{
+	"company":         "company_name",
+	"company_name":    "company_name",
+	"entreprise":      "company_name",
+	"job_title":       "job_title",
+	"title":           "job_title",
+	"poste":           "job_title",
+	"theme":           "theme",
+	"posting_url":     "job_posting_url",
+	"job_posting_url": "job_posting_url",
+	"url":             "job_posting_url",
+}
+
+// letterBatchDefaultColumns ordre des colonnes d'un CSV sans en-tête
+var letterBatchDefaultColumns = []string{"company_name", "job_title", "theme", "job_posting_url"}
+
+// ParseLetterBatch lit une liste d'entreprises selon son format ("csv" ou "json")
+func ParseLetterBatch(format string, data io.Reader) ([]LetterBatchItemRequest, error) {
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+
+	switch format {
+	case "csv":
+		return ParseLetterBatchCSV(content)
+	case "json":
+		return ParseLetterBatchJSON(content)
+	}
+	return nil, fmt.Errorf("unknown batch format %q (available: csv, json)", format)
+}
+
+// ParseLetterBatchCSV lit une liste d'entreprises au format CSV
+// Colonnes : company, job_title, theme, posting_url (en-tête optionnel, séparateur "," ou ";").
+func ParseLetterBatchCSV(data []byte) ([]LetterBatchItemRequest, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM des exports tableur
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	columns := letterBatchDefaultColumns
+	if header := records[0]; letterBatchColumns[strings.ToLower(strings.TrimSpace(header[0]))] != "" {
+		columns = make([]string, len(header))
+		for i, name := range header {
+			columns[i] = letterBatchColumns[strings.ToLower(strings.TrimSpace(name))]
+		}
+		records = records[1:]
+	}
+
+	items := make([]LetterBatchItemRequest, 0, len(records))
+	for _, record := range records {
+		var item LetterBatchItemRequest
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "company_name":
+				item.CompanyName = value
+			case "job_title":
+				item.JobTitle = value
+			case "theme":
+				item.Theme = value
+			case "job_posting_url":
+				item.JobPostingURL = value
+			}
+		}
+		if item == (LetterBatchItemRequest{}) {
+			continue // Ligne vide
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	return items, nil
+}
+
+// ParseLetterBatchJSON lit une liste d'entreprises au format JSON (tableau ou {"items": [...]})
+func ParseLetterBatchJSON(data []byte) ([]LetterBatchItemRequest, error) {
+	var items []LetterBatchItemRequest
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapped struct {
+			Items []LetterBatchItemRequest `json:"items"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		items = wrapped.Items
+	}
+
+	for i := range items {
+		items[i].CompanyName = strings.TrimSpace(items[i].CompanyName)
+		items[i].JobTitle = strings.TrimSpace(items[i].JobTitle)
+		items[i].Theme = strings.TrimSpace(items[i].Theme)
+		items[i].JobPostingURL = strings.TrimSpace(items[i].JobPostingURL)
+	}
+
+	if len(items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	return items, nil
+}
+
+// LetterBatchStatus état d'avancement d'une campagne
+type LetterBatchStatus string
+
+const (
+	LetterBatchQueued     LetterBatchStatus = "queued"     // Aucun job démarré
+	LetterBatchProcessing LetterBatchStatus = "processing" // Jobs en attente ou en cours
+	LetterBatchCompleted  LetterBatchStatus = "completed"  // Tous les jobs terminés (complétés, échoués ou annulés)
+)
+
+// LetterBatchItemProgress entreprise d'une campagne et son job (nil si expiré)
+type LetterBatchItemProgress struct {
+	Item models.LetterBatchItem
+	Job  *LetterJob
+}
+
+// LetterBatchProgress avancement d'une campagne, lu sur ses jobs
+type LetterBatchProgress struct {
+	Batch    models.LetterBatch
+	Status   LetterBatchStatus
+	Counts   map[JobStatus]int
+	Progress int // 0-100 (moyenne des jobs)
+	Items    []LetterBatchItemProgress
+}
+
+// LetterBatchLetter lettre générée pour une entreprise d'une campagne
+type LetterBatchLetter struct {
+	Position int
+	Letter   models.GeneratedLetter
+}
+
+// LetterBatchService campagnes de génération de lettres
+// Les lettres sont générées par les slots de campagne des workers (file dédiée) ;
+// un quota journalier propre au propriétaire remplace le rate limit par session.
+type LetterBatchService struct {
+	db         *gorm.DB
+	redis      *redis.Client
+	queue      *LetterQueueService
+	dailyQuota int
+	maxItems   int
+}
+
+// NewLetterBatchService crée le service de campagnes
+func NewLetterBatchService(db *gorm.DB, redisClient *redis.Client, queue *LetterQueueService) *LetterBatchService {
+	return &LetterBatchService{
+		db:         db,
+		redis:      redisClient,
+		queue:      queue,
+		dailyQuota: DefaultOwnerDailyQuota,
+		maxItems:   DefaultLetterBatchMaxItems,
+	}
+}
+
+// SetDailyQuota définit le nombre de générations de campagne par jour (une par entreprise)
+func (s *LetterBatchService) SetDailyQuota(quota int) {
+	if quota > 0 {
+		s.dailyQuota = quota
+	}
+}
+
+// SetMaxItems définit le nombre maximal d'entreprises par campagne
+func (s *LetterBatchService) SetMaxItems(maxItems int) {
+	if maxItems > 0 {
+		s.maxItems = maxItems
+	}
+}
+
+// MaxItems retourne le nombre maximal d'entreprises par campagne
+func (s *LetterBatchService) MaxItems() int {
+	return s.maxItems
+}
+
+// QuotaStatus retourne le quota journalier du propriétaire et sa consommation
+func (s *LetterBatchService) QuotaStatus(ctx context.Context) (used, limit int, err error) {
+	used, err = s.redis.Get(ctx, ownerQuotaKey).Int()
+	if err == redis.Nil {
+		return 0, s.dailyQuota, nil
+	}
+	if err != nil {
+		return 0, s.dailyQuota, fmt.Errorf("failed to read owner quota: %w", err)
+	}
+	return used, s.dailyQuota, nil
+}
+
+// reserveQuota consomme count unités du quota journalier (remis à zéro à minuit)
+func (s *LetterBatchService) reserveQuota(ctx context.Context, count int) error {
+	used, err := s.redis.IncrBy(ctx, ownerQuotaKey, int64(count)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to reserve owner quota: %w", err)
+	}
+
+	if used == int64(count) {
+		now := time.Now()
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		if err := s.redis.Expire(ctx, ownerQuotaKey, midnight.Sub(now)).Err(); err != nil {
+			return fmt.Errorf("failed to set owner quota expiration: %w", err)
+		}
+	}
+
+	if used > int64(s.dailyQuota) {
+		s.refundQuota(ctx, count)
+		return &OwnerQuotaError{Limit: s.dailyQuota, Used: int(used) - count, Requested: count}
+	}
+	return nil
+}
+
+// refundQuota rend des unités réservées mais non consommées
+func (s *LetterBatchService) refundQuota(ctx context.Context, count int) {
+	if err := s.redis.DecrBy(ctx, ownerQuotaKey, int64(count)).Err(); err != nil {
+		log.Warn().Err(err).Int("count", count).Msg("Failed to refund owner quota")
+	}
+}
+
+// CreateBatch enregistre une campagne et met en file un job par entreprise
+// Les entreprises doivent avoir été validées. Le quota est consommé à la mise en file ;
+// en cas d'échec, les jobs déjà créés sont annulés et le quota rendu.
+func (s *LetterBatchService) CreateBatch(ctx context.Context, req LetterBatchRequest) (*models.LetterBatch, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(req.Items) > s.maxItems {
+		return nil, fmt.Errorf("batch has %d companies (max %d)", len(req.Items), s.maxItems)
+	}
+
+	if err := s.reserveQuota(ctx, len(req.Items)); err != nil {
+		return nil, err
+	}
+
+	letterTypes := make([]string, 0, len(req.LetterTypes))
+	for _, letterType := range req.LetterTypes {
+		letterTypes = append(letterTypes, string(letterType))
+	}
+
+	batch := &models.LetterBatch{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Language:    req.Language,
+		LetterTypes: strings.Join(letterTypes, ","),
+		ItemCount:   len(req.Items),
+	}
+
+	var jobIDs []string
+	rollback := func(err error) (*models.LetterBatch, error) {
+		for _, jobID := range jobIDs {
+			if _, cancelErr := s.queue.CancelJob(jobID); cancelErr != nil {
+				log.Warn().Err(cancelErr).Str("job_id", jobID).Msg("Failed to cancel job of aborted batch")
+			}
+		}
+		s.refundQuota(ctx, len(req.Items))
+		return nil, err
+	}
+
+	for i, itemReq := range req.Items {
+		jobID, err := s.queue.EnqueueJob(LetterJobRequest{
+			VisitorID:     LetterBatchOwnerSession,
+			CompanyName:   itemReq.CompanyName,
+			JobTitle:      itemReq.JobTitle,
+			Theme:         itemReq.Theme,
+			Language:      req.Language,
+			JobPostingURL: itemReq.JobPostingURL,
+			LetterTypes:   req.LetterTypes,
+			Priority:      JobPriorityBatch,
+			BatchID:       batch.ID.String(),
+		})
+		if err != nil {
+			return rollback(err)
+		}
+		jobIDs = append(jobIDs, jobID)
+
+		batch.Items = append(batch.Items, models.LetterBatchItem{
+			ID:            uuid.New(),
+			BatchID:       batch.ID,
+			Position:      i + 1,
+			CompanyName:   itemReq.CompanyName,
+			JobTitle:      itemReq.JobTitle,
+			Theme:         itemReq.Theme,
+			JobPostingURL: itemReq.JobPostingURL,
+			JobID:         jobID,
+		})
+	}
+
+	if err := s.db.WithContext(ctx).Create(batch).Error; err != nil {
+		return rollback(fmt.Errorf("failed to save batch: %w", err))
+	}
+
+	return batch, nil
+}
+
+// ListBatches liste les campagnes les plus récentes (sans leurs entreprises)
+func (s *LetterBatchService) ListBatches(ctx context.Context, limit int) ([]models.LetterBatch, error) {
+	var batches []models.LetterBatch
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list batches: %w", err)
+	}
+	return batches, nil
+}
+
+// GetBatch retourne une campagne et l'avancement de ses jobs
+func (s *LetterBatchService) GetBatch(ctx context.Context, batchID uuid.UUID) (*LetterBatchProgress, error) {
+	var batch models.LetterBatch
+	err := s.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&batch, "id = ?", batchID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load batch: %w", err)
+	}
+
+	progress := &LetterBatchProgress{
+		Batch:  batch,
+		Counts: make(map[JobStatus]int),
+		Items:  make([]LetterBatchItemProgress, 0, len(batch.Items)),
+	}
+
+	started, totalProgress := false, 0
+	for _, item := range batch.Items {
+		job, err := s.queue.GetJobStatus(item.JobID)
+		if err != nil {
+			// Job expiré sans copie Postgres : compté comme échoué
+			progress.Counts[JobStatusFailed]++
+			totalProgress += 100
+			progress.Items = append(progress.Items, LetterBatchItemProgress{Item: item})
+			continue
+		}
+
+		progress.Counts[job.Status]++
+		if job.IsTerminal() {
+			totalProgress += 100
+		} else {
+			totalProgress += job.Progress
+		}
+		if job.Status != JobStatusQueued {
+			started = true
+		}
+		progress.Items = append(progress.Items, LetterBatchItemProgress{Item: item, Job: job})
+	}
+
+	if len(batch.Items) > 0 {
+		progress.Progress = totalProgress / len(batch.Items)
+	}
+
+	switch pending := progress.Counts[JobStatusQueued] + progress.Counts[JobStatusProcessing]; {
+	case pending == 0:
+		progress.Status = LetterBatchCompleted
+	case started:
+		progress.Status = LetterBatchProcessing
+	default:
+		progress.Status = LetterBatchQueued
+	}
+
+	return progress, nil
+}
+
+// BatchLetters retourne les lettres générées d'une campagne, dans l'ordre de la liste
+func (s *LetterBatchService) BatchLetters(ctx context.Context, progress *LetterBatchProgress) ([]LetterBatchLetter, error) {
+	positions := make(map[uuid.UUID]int)
+	for _, item := range progress.Items {
+		if item.Job == nil || item.Job.Status != JobStatusCompleted {
+			continue
+		}
+		for _, letterType := range item.Job.RequestedLetterTypes() {
+			if letterID, ok := item.Job.Letters[letterType]; ok {
+				positions[letterID] = item.Item.Position
+			}
+		}
+	}
+	if len(positions) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(positions))
+	for id := range positions {
+		ids = append(ids, id)
+	}
+
+	var letters []models.GeneratedLetter
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Order("company_name ASC, letter_type ASC").Find(&letters).Error; err != nil {
+		return nil, fmt.Errorf("failed to load batch letters: %w", err)
+	}
+
+	result := make([]LetterBatchLetter, 0, len(letters))
+	for _, letter := range letters {
+		result = append(result, LetterBatchLetter{Position: positions[letter.ID], Letter: letter})
+	}
+	// Ordre de la liste d'origine
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Position < result[j].Position })
+	return result, nil
+}
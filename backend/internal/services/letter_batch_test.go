@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+func newTestLetterBatchService(t *testing.T) (*LetterBatchService, *LetterQueueService) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.LetterBatch{}, &models.LetterBatchItem{}))
+
+	queue := NewLetterQueueService(redisClient)
+	return NewLetterBatchService(db, redisClient, queue), queue
+}
+
+func TestParseLetterBatchCSV(t *testing.T) {
+	// Export tableur : BOM, séparateur ";", en-têtes dans le désordre, ligne vide
+	items, err := ParseLetterBatchCSV([]byte("\xef\xbb\xbfTheme;Company;Job_Title\nbackend; Google ;Backend Engineer\n;;\ndevops;Datadog;\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []LetterBatchItemRequest{
+		{CompanyName: "Google", JobTitle: "Backend Engineer", Theme: "backend"},
+		{CompanyName: "Datadog", Theme: "devops"},
+	}, items)
+
+	// Sans en-tête : company, job_title, theme, posting_url
+	items, err = ParseLetterBatchCSV([]byte("Meta,SRE,devops,https://meta.com/jobs/1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []LetterBatchItemRequest{{CompanyName: "Meta", JobTitle: "SRE", Theme: "devops", JobPostingURL: "https://meta.com/jobs/1"}}, items)
+
+	_, err = ParseLetterBatchCSV([]byte("company,theme\n"))
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+}
+
+func TestParseLetterBatchJSON(t *testing.T) {
+	items, err := ParseLetterBatchJSON([]byte(`[{"company_name":" Google ","theme":"backend"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []LetterBatchItemRequest{{CompanyName: "Google", Theme: "backend"}}, items)
+
+	items, err = ParseLetterBatchJSON([]byte(`{"items":[{"company_name":"Meta"},{"company_name":"Apple"}]}`))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	_, err = ParseLetterBatchJSON([]byte(`[]`))
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+	_, err = ParseLetterBatch("xml", strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestLetterBatchService_CreateBatch(t *testing.T) {
+	service, queue := newTestLetterBatchService(t)
+	service.SetDailyQuota(3)
+	ctx := context.Background()
+
+	batch, err := service.CreateBatch(ctx, LetterBatchRequest{
+		Name:        "Campagne Q4",
+		LetterTypes: []models.LetterType{models.LetterTypeMotivation},
+		Items:       []LetterBatchItemRequest{{CompanyName: "Google", Theme: "backend"}, {CompanyName: "Meta"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.Items, 2)
+
+	job, err := queue.GetJobStatus(batch.Items[0].JobID)
+	require.NoError(t, err)
+	assert.Equal(t, batch.ID.String(), job.BatchID)
+	assert.Equal(t, JobPriorityBatch, job.Priority)
+	assert.Equal(t, LetterBatchOwnerSession, job.VisitorID)
+
+	// File dédiée : invisible des slots interactifs
+	jobID, err := queue.ClaimJob("worker-1")
+	require.NoError(t, err)
+	assert.Empty(t, jobID)
+	jobID, err = queue.ClaimBatchJob("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, batch.Items[0].JobID, jobID)
+
+	used, limit, _ := service.QuotaStatus(ctx)
+	assert.Equal(t, 2, used)
+	assert.Equal(t, 3, limit)
+
+	// Quota insuffisant : rien n'est mis en file ni consommé
+	_, err = service.CreateBatch(ctx, LetterBatchRequest{Items: []LetterBatchItemRequest{{CompanyName: "Apple"}, {CompanyName: "Amazon"}}})
+	var quotaErr *OwnerQuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, 2, quotaErr.Used)
+	assert.Equal(t, 2, quotaErr.Requested)
+
+	used, _, _ = service.QuotaStatus(ctx)
+	assert.Equal(t, 2, used)
+	length, _ := queue.GetQueueLength()
+	assert.Equal(t, int64(1), length)
+
+	_, err = service.CreateBatch(ctx, LetterBatchRequest{})
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+}
+
+func TestLetterBatchService_Progress(t *testing.T) {
+	service, queue := newTestLetterBatchService(t)
+	ctx := context.Background()
+
+	batch, err := service.CreateBatch(ctx, LetterBatchRequest{Items: []LetterBatchItemRequest{
+		{CompanyName: "Google"}, {CompanyName: "Meta"}, {CompanyName: "Apple"},
+	}})
+	require.NoError(t, err)
+
+	progress, err := service.GetBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, LetterBatchQueued, progress.Status)
+	assert.Equal(t, 3, progress.Counts[JobStatusQueued])
+
+	// Meta terminée, Google en échec, Apple en cours
+	letters := map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: uuid.New()}
+	require.NoError(t, queue.CompleteJob(batch.Items[1].JobID, letters))
+	require.NoError(t, queue.FailJob(batch.Items[0].JobID, "AI provider unavailable"))
+	require.NoError(t, queue.UpdateJobStatus(batch.Items[2].JobID, JobStatusProcessing, 40))
+
+	progress, err = service.GetBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, LetterBatchProcessing, progress.Status)
+	assert.Equal(t, 80, progress.Progress) // (100 + 100 + 40) / 3
+	assert.Equal(t, 1, progress.Counts[JobStatusCompleted])
+	assert.Equal(t, 1, progress.Counts[JobStatusFailed])
+	assert.Equal(t, "Google", progress.Items[0].Item.CompanyName)
+
+	assert.Equal(t, letters, progress.Items[1].Job.Letters)
+	require.NotNil(t, progress.Items[0].Job.Error)
+
+	require.NoError(t, queue.CompleteJob(batch.Items[2].JobID, nil))
+	progress, _ = service.GetBatch(ctx, batch.ID)
+	assert.Equal(t, LetterBatchCompleted, progress.Status)
+
+	_, err = service.GetBatch(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+}
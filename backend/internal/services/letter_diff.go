@@ -0,0 +1,125 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DiffOpType type d'opération d'un diff de lettres
+type DiffOpType string
+
+const (
+	DiffEqual  DiffOpType = "equal"
+	DiffInsert DiffOpType = "insert"
+	DiffDelete DiffOpType = "delete"
+)
+
+// DiffOp segment de texte commun, ajouté ou supprimé
+type DiffOp struct {
+	Type DiffOpType `json:"type"`
+	Text string     `json:"text"`
+}
+
+// LetterDiff différences mot à mot entre deux versions d'une lettre
+// La concaténation des segments equal + insert redonne la version cible.
+type LetterDiff struct {
+	From         int      `json:"from"`
+	To           int      `json:"to"`
+	Ops          []DiffOp `json:"ops"`
+	WordsAdded   int      `json:"words_added"`
+	WordsRemoved int      `json:"words_removed"`
+}
+
+// maxDiffCells borne la table LCS (mots source x mots cible) pour éviter
+// une consommation mémoire excessive sur des textes anormalement longs
+const maxDiffCells = 4_000_000
+
+// diffTokenPattern : un mot suivi de ses espaces, ou des espaces en début de texte
+var diffTokenPattern = regexp.MustCompile(`\S+\s*|\s+`)
+
+// DiffLetters calcule le diff mot à mot (plus longue sous-séquence commune) entre deux textes
+func DiffLetters(from, to string) LetterDiff {
+	a := diffTokenPattern.FindAllString(from, -1)
+	b := diffTokenPattern.FindAllString(to, -1)
+
+	// Préfixe et suffixe communs : les révisions ne touchent souvent qu'une partie de la lettre
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	diff := LetterDiff{Ops: []DiffOp{}}
+	diff.add(DiffEqual, a[:prefix]...)
+	diff.addMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	diff.add(DiffEqual, a[len(a)-suffix:]...)
+
+	return diff
+}
+
+// addMiddle ajoute les opérations de la partie centrale (hors préfixe et suffixe communs)
+func (d *LetterDiff) addMiddle(a, b []string) {
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxDiffCells {
+		d.add(DiffDelete, a...)
+		d.add(DiffInsert, b...)
+		return
+	}
+
+	// lcs[i][j] = longueur de la plus longue sous-séquence commune de a[i:] et b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			d.add(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			d.add(DiffDelete, a[i])
+			i++
+		default:
+			d.add(DiffInsert, b[j])
+			j++
+		}
+	}
+	d.add(DiffDelete, a[i:]...)
+	d.add(DiffInsert, b[j:]...)
+}
+
+// add ajoute des mots au diff en fusionnant les opérations consécutives de même type
+func (d *LetterDiff) add(opType DiffOpType, tokens ...string) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	text := strings.Join(tokens, "")
+	words := len(strings.Fields(text))
+	switch opType {
+	case DiffInsert:
+		d.WordsAdded += words
+	case DiffDelete:
+		d.WordsRemoved += words
+	}
+
+	if n := len(d.Ops); n > 0 && d.Ops[n-1].Type == opType {
+		d.Ops[n-1].Text += text
+		return
+	}
+	d.Ops = append(d.Ops, DiffOp{Type: opType, Text: text})
+}
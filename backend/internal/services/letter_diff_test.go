@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// applyDiff reconstruit les deux textes à partir des opérations
+func applyDiff(diff LetterDiff) (string, string) {
+	var from, to strings.Builder
+	for _, op := range diff.Ops {
+		if op.Type != DiffInsert {
+			from.WriteString(op.Text)
+		}
+		if op.Type != DiffDelete {
+			to.WriteString(op.Text)
+		}
+	}
+	return from.String(), to.String()
+}
+
+func TestDiffLetters_WordChanges(t *testing.T) {
+	from := "Je suis motivé par votre entreprise.\n\nCordialement,"
+	to := "Je suis très motivé par votre startup.\n\nCordialement,"
+
+	diff := DiffLetters(from, to)
+
+	assert.Equal(t, []DiffOp{
+		{Type: DiffEqual, Text: "Je suis "},
+		{Type: DiffInsert, Text: "très "},
+		{Type: DiffEqual, Text: "motivé par votre "},
+		{Type: DiffDelete, Text: "entreprise.\n\n"},
+		{Type: DiffInsert, Text: "startup.\n\n"},
+		{Type: DiffEqual, Text: "Cordialement,"},
+	}, diff.Ops)
+	assert.Equal(t, 2, diff.WordsAdded)
+	assert.Equal(t, 1, diff.WordsRemoved)
+}
+
+func TestDiffLetters_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"Identical", "Madame, Monsieur,", "Madame, Monsieur,"},
+		{"Empty source", "", "Nouvelle lettre"},
+		{"Empty target", "Ancienne lettre", ""},
+		{"Leading whitespace", "  Bonjour à tous", "Bonjour à vous"},
+		{"Rewritten", "Un deux trois quatre", "cinq trois six"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to := applyDiff(DiffLetters(tc.from, tc.to))
+			assert.Equal(t, tc.from, from)
+			assert.Equal(t, tc.to, to)
+		})
+	}
+}
+
+func TestDiffLetters_Identical(t *testing.T) {
+	diff := DiffLetters("Madame, Monsieur,", "Madame, Monsieur,")
+
+	assert.Equal(t, []DiffOp{{Type: DiffEqual, Text: "Madame, Monsieur,"}}, diff.Ops)
+	assert.Zero(t, diff.WordsAdded)
+	assert.Zero(t, diff.WordsRemoved)
+
+	// Deux textes vides : aucune opération (et non nil en JSON)
+	assert.Equal(t, []DiffOp{}, DiffLetters("", "").Ops)
+}
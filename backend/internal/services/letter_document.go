@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"maicivy/internal/models"
+)
+
+// LetterDocument structure commune des documents exportés (PDF, DOCX, ODT, Markdown, texte)
+// Elle reprend l'en-tête, le corps et le pied de page des templates PDF.
+type LetterDocument struct {
+	Title      string
+	Warning    string // Avertissement "second degré" (anti-motivation)
+	Date       string
+	Attention  string // "À l'attention de ..." (vide pour l'anti-motivation)
+	Paragraphs []LetterParagraph
+	Footer     []string
+}
+
+// LetterParagraph paragraphe du corps ; chaque ligne est séparée par un retour à la ligne simple
+// (les lignes de l'en-tête d'une lettre forment un seul paragraphe)
+type LetterParagraph []string
+
+// paragraphSeparator : ligne vide (éventuellement avec des espaces) entre deux paragraphes
+var paragraphSeparator = regexp.MustCompile(`\n[ \t]*\n`)
+
+// NewLetterDocument construit le document d'une lettre dans sa langue
+func NewLetterDocument(letter models.LetterResponse) *LetterDocument {
+	locale := localeFor(letter.Language)
+	labels := locale.pdf
+
+	doc := &LetterDocument{
+		Title:      pdfTitle(labels, letter.Type),
+		Date:       locale.formatDate(letter.GeneratedAt),
+		Paragraphs: splitParagraphs(letter.Content),
+	}
+
+	if letter.Type == models.LetterTypeAntiMotivation {
+		doc.Warning = labels.AntiWarning
+		doc.Footer = []string{labels.AntiFooter, labels.AntiFooterTagline}
+	} else {
+		doc.Attention = fmt.Sprintf(labels.Attention, letter.CompanyInfo.Name)
+		doc.Footer = []string{labels.Footer}
+	}
+
+	return doc
+}
+
+// LetterResponseFromModel reconstruit la réponse d'une lettre enregistrée (exports)
+// Sans informations entreprise exploitables, seul le nom de l'entreprise est repris.
+func LetterResponseFromModel(letter *models.GeneratedLetter) models.LetterResponse {
+	var company models.CompanyInfo
+	if letter.CompanyInfo != "" {
+		_ = json.Unmarshal([]byte(letter.CompanyInfo), &company)
+	}
+	if company.Name == "" {
+		company.Name = letter.CompanyName
+	}
+
+	return models.LetterResponse{
+		Content:         letter.Content,
+		Type:            letter.LetterType,
+		Language:        letter.Language,
+		CompanyInfo:     company,
+		GeneratedAt:     letter.CreatedAt,
+		Provider:        letter.AIModel,
+		TokensUsed:      letter.TokensUsed,
+		QualityScore:    letter.QualityScore,
+		QualityFindings: letter.QualityFindings,
+		Regenerations:   letter.Regenerations,
+	}
+}
+
+// splitParagraphs découpe le texte d'une lettre en paragraphes (lignes vides) puis en lignes
+func splitParagraphs(content string) []LetterParagraph {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var paragraphs []LetterParagraph
+	for _, block := range paragraphSeparator.Split(content, -1) {
+		var lines LetterParagraph
+		for _, line := range strings.Split(block, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, lines)
+		}
+	}
+	return paragraphs
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"maicivy/internal/models"
+)
+
+// ExportFormat format d'export éditable d'une lettre (sans navigateur headless)
+type ExportFormat string
+
+const (
+	ExportFormatDOCX     ExportFormat = "docx"
+	ExportFormatODT      ExportFormat = "odt"
+	ExportFormatMarkdown ExportFormat = "md"
+	ExportFormatText     ExportFormat = "txt"
+)
+
+// ExportFormats formats d'export disponibles
+var ExportFormats = []ExportFormat{ExportFormatDOCX, ExportFormatODT, ExportFormatMarkdown, ExportFormatText}
+
+// ParseExportFormat valide un format d'export
+func ParseExportFormat(value string) (ExportFormat, error) {
+	format := ExportFormat(strings.ToLower(strings.TrimSpace(value)))
+	for _, supported := range ExportFormats {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format %q (available: %v)", value, ExportFormats)
+}
+
+// ContentType type MIME du format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatDOCX:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ExportFormatODT:
+		return "application/vnd.oasis.opendocument.text"
+	case ExportFormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension extension de fichier du format
+func (f ExportFormat) Extension() string {
+	return string(f)
+}
+
+// ExportLetter écrit une lettre dans le format demandé
+func ExportLetter(letter models.LetterResponse, format ExportFormat, w io.Writer) error {
+	doc := NewLetterDocument(letter)
+
+	switch format {
+	case ExportFormatDOCX:
+		return writeDOCX(doc, letter.Language.OrDefault(), w)
+	case ExportFormatODT:
+		return writeODT(doc, letter.Language.OrDefault(), w)
+	case ExportFormatMarkdown:
+		return writeMarkdown(doc, w)
+	case ExportFormatText:
+		return writeText(doc, w)
+	}
+	return fmt.Errorf("unsupported export format %q", format)
+}
+
+// writeMarkdown : titre, en-tête en emphase, paragraphes avec retours à la ligne forcés
+func writeMarkdown(doc *LetterDocument, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# %s\n\n", doc.Title)
+	if doc.Warning != "" {
+		fmt.Fprintf(bw, "> %s\n\n", doc.Warning)
+	}
+	fmt.Fprintf(bw, "*%s*\n\n", doc.Date)
+	if doc.Attention != "" {
+		fmt.Fprintf(bw, "**%s**\n\n", doc.Attention)
+	}
+
+	for _, paragraph := range doc.Paragraphs {
+		// Deux espaces en fin de ligne : retour à la ligne dans le même paragraphe
+		fmt.Fprintf(bw, "%s\n\n", strings.Join(paragraph, "  \n"))
+	}
+
+	bw.WriteString("---\n\n")
+	for _, line := range doc.Footer {
+		fmt.Fprintf(bw, "_%s_\n\n", line)
+	}
+
+	return bw.Flush()
+}
+
+// writeText : texte brut, titre souligné
+func writeText(doc *LetterDocument, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%s\n%s\n\n", doc.Title, strings.Repeat("=", len([]rune(doc.Title))))
+	if doc.Warning != "" {
+		fmt.Fprintf(bw, "%s\n\n", doc.Warning)
+	}
+	fmt.Fprintf(bw, "%s\n", doc.Date)
+	if doc.Attention != "" {
+		fmt.Fprintf(bw, "%s\n", doc.Attention)
+	}
+	bw.WriteString("\n")
+
+	for _, paragraph := range doc.Paragraphs {
+		fmt.Fprintf(bw, "%s\n\n", strings.Join(paragraph, "\n"))
+	}
+
+	bw.WriteString("--\n")
+	for _, line := range doc.Footer {
+		fmt.Fprintf(bw, "%s\n", line)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"maicivy/internal/models"
+)
+
+// ============================================
+// Documents bureautiques (DOCX, ODT) générés en Go pur
+// ============================================
+
+// officeStyle style de paragraphe partagé par les exports DOCX et ODT
+// Les noms correspondent aux classes CSS des templates PDF.
+type officeStyle string
+
+const (
+	officeStyleTitle     officeStyle = "Title"     // Titre centré en gras
+	officeStyleWarning   officeStyle = "Warning"   // Avertissement en italique
+	officeStyleDate      officeStyle = "Date"      // Date alignée à droite
+	officeStyleAttention officeStyle = "Attention" // Destinataire en gras
+	officeStyleBody      officeStyle = "Body"      // Paragraphe du corps
+	officeStyleFooter    officeStyle = "Footer"    // Pied de page discret et centré
+)
+
+// officeParagraph paragraphe à écrire ; les lignes sont séparées par un retour à la ligne forcé
+type officeParagraph struct {
+	style officeStyle
+	lines []string
+}
+
+// officeParagraphs met à plat un LetterDocument dans l'ordre du template PDF
+func officeParagraphs(doc *LetterDocument) []officeParagraph {
+	paragraphs := []officeParagraph{{style: officeStyleTitle, lines: []string{doc.Title}}}
+	if doc.Warning != "" {
+		paragraphs = append(paragraphs, officeParagraph{style: officeStyleWarning, lines: []string{doc.Warning}})
+	}
+	paragraphs = append(paragraphs, officeParagraph{style: officeStyleDate, lines: []string{doc.Date}})
+	if doc.Attention != "" {
+		paragraphs = append(paragraphs, officeParagraph{style: officeStyleAttention, lines: []string{doc.Attention}})
+	}
+	for _, paragraph := range doc.Paragraphs {
+		paragraphs = append(paragraphs, officeParagraph{style: officeStyleBody, lines: paragraph})
+	}
+	for _, line := range doc.Footer {
+		paragraphs = append(paragraphs, officeParagraph{style: officeStyleFooter, lines: []string{line}})
+	}
+	return paragraphs
+}
+
+// officeLocale code langue et pays d'une langue de lettre (correcteur orthographique)
+func officeLocale(lang models.Language) (language, country string) {
+	switch lang {
+	case models.LanguageEnglish:
+		return "en", "GB"
+	case models.LanguageGerman:
+		return "de", "DE"
+	case models.LanguageSpanish:
+		return "es", "ES"
+	}
+	return "fr", "FR"
+}
+
+// escapeXML échappe un texte pour un nœud XML
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// officePart fichier d'une archive bureautique
+type officePart struct {
+	name    string
+	content string
+	store   bool // Sans compression (obligatoire pour le "mimetype" ODT)
+}
+
+// writeOfficeArchive écrit les fichiers d'un document dans une archive ZIP, dans l'ordre donné
+func writeOfficeArchive(w io.Writer, parts []officePart) error {
+	zw := zip.NewWriter(w)
+
+	for _, part := range parts {
+		method := zip.Deflate
+		if part.store {
+			method = zip.Store
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: method})
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// ============================================
+// DOCX (Office Open XML)
+// ============================================
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// docxStyles styles Word (tailles en demi-points, espacements en vingtièmes de point)
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:cs="Arial"/><w:sz w:val="22"/><w:lang w:val="%s"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="200" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/><w:spacing w:after="400"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Warning"><w:name w:val="Warning"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:i/><w:color w:val="B45309"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Date"><w:name w:val="Date"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="right"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Attention"><w:name w:val="Attention"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="400"/></w:pPr><w:rPr><w:b/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Body"><w:name w:val="Body"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="both"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Footer"><w:name w:val="Footer"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/><w:spacing w:before="400" w:after="0"/></w:pPr><w:rPr><w:sz w:val="18"/><w:color w:val="6B7280"/></w:rPr></w:style>
+</w:styles>`
+
+// docxSection format A4 portrait, marges de 2 cm (en vingtièmes de point)
+const docxSection = `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr>`
+
+// writeDOCX écrit la lettre au format Word (.docx)
+func writeDOCX(doc *LetterDocument, lang models.Language, w io.Writer) error {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	body.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	for _, paragraph := range officeParagraphs(doc) {
+		fmt.Fprintf(&body, `<w:p><w:pPr><w:pStyle w:val="%s"/></w:pPr><w:r>`, paragraph.style)
+		for i, line := range paragraph.lines {
+			if i > 0 {
+				body.WriteString(`<w:br/>`)
+			}
+			fmt.Fprintf(&body, `<w:t xml:space="preserve">%s</w:t>`, escapeXML(line))
+		}
+		body.WriteString(`</w:r></w:p>`)
+	}
+
+	body.WriteString(docxSection)
+	body.WriteString(`</w:body></w:document>`)
+
+	language, country := officeLocale(lang)
+	return writeOfficeArchive(w, []officePart{
+		{name: "[Content_Types].xml", content: docxContentTypes},
+		{name: "_rels/.rels", content: docxRootRels},
+		{name: "word/_rels/document.xml.rels", content: docxDocumentRels},
+		{name: "word/styles.xml", content: fmt.Sprintf(docxStyles, language+"-"+country)},
+		{name: "word/document.xml", content: body.String()},
+	})
+}
+
+// ============================================
+// ODT (OpenDocument Text)
+// ============================================
+
+const odtMimetype = "application/vnd.oasis.opendocument.text"
+
+const odtManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.text"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+// odtStyles styles automatiques (même rendu que docxStyles) ; %[1]s langue, %[2]s pays
+const odtStyles = `<office:automatic-styles>
+<style:style style:name="Title" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:margin-bottom="0.7cm"/><style:text-properties style:font-name="Arial" fo:font-size="16pt" fo:font-weight="bold" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+<style:style style:name="Warning" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:margin-bottom="0.35cm"/><style:text-properties style:font-name="Arial" fo:font-size="11pt" fo:font-style="italic" fo:color="#b45309" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+<style:style style:name="Date" style:family="paragraph"><style:paragraph-properties fo:text-align="end" fo:margin-bottom="0.35cm"/><style:text-properties style:font-name="Arial" fo:font-size="11pt" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+<style:style style:name="Attention" style:family="paragraph"><style:paragraph-properties fo:margin-bottom="0.7cm"/><style:text-properties style:font-name="Arial" fo:font-size="11pt" fo:font-weight="bold" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+<style:style style:name="Body" style:family="paragraph"><style:paragraph-properties fo:text-align="justify" fo:margin-bottom="0.35cm"/><style:text-properties style:font-name="Arial" fo:font-size="11pt" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+<style:style style:name="Footer" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:margin-top="0.7cm"/><style:text-properties style:font-name="Arial" fo:font-size="9pt" fo:color="#6b7280" fo:language="%[1]s" fo:country="%[2]s"/></style:style>
+</office:automatic-styles>`
+
+// writeODT écrit la lettre au format OpenDocument (.odt)
+func writeODT(doc *LetterDocument, lang models.Language, w io.Writer) error {
+	language, country := officeLocale(lang)
+
+	var content strings.Builder
+	content.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	content.WriteString(`<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+		` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+		` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+		` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2">`)
+	fmt.Fprintf(&content, odtStyles, language, country)
+	content.WriteString(`<office:body><office:text>`)
+
+	for _, paragraph := range officeParagraphs(doc) {
+		fmt.Fprintf(&content, `<text:p text:style-name="%s">`, paragraph.style)
+		for i, line := range paragraph.lines {
+			if i > 0 {
+				content.WriteString(`<text:line-break/>`)
+			}
+			content.WriteString(escapeXML(line))
+		}
+		content.WriteString(`</text:p>`)
+	}
+
+	content.WriteString(`</office:text></office:body></office:document-content>`)
+
+	// Le fichier "mimetype" doit être le premier de l'archive, non compressé
+	return writeOfficeArchive(w, []officePart{
+		{name: "mimetype", content: odtMimetype, store: true},
+		{name: "META-INF/manifest.xml", content: odtManifest},
+		{name: "content.xml", content: content.String()},
+	})
+}
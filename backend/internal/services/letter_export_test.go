@@ -0,0 +1,153 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func newTestExportLetter(letterType models.LetterType) models.LetterResponse {
+	return models.LetterResponse{
+		Content:     "Jean Dupont\njean@example.com\n\nObjet : Candidature <SRE> & co\n\nMadame, Monsieur,\n\nJe postule chez Acme.\n\nCordialement,\nJean Dupont",
+		Type:        letterType,
+		Language:    models.LanguageFrench,
+		CompanyInfo: models.CompanyInfo{Name: "Acme"},
+		GeneratedAt: time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// readZip retourne les fichiers d'une archive dans l'ordre
+func readZip(t *testing.T, data []byte) ([]*zip.File, map[string]string) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	contents := make(map[string]string, len(reader.File))
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		contents[file.Name] = string(body)
+	}
+	return reader.File, contents
+}
+
+// assertWellFormedXML vérifie qu'un document XML se lit jusqu'au bout
+func assertWellFormedXML(t *testing.T, name, content string) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err, name)
+	}
+}
+
+func TestLetterDocument(t *testing.T) {
+	doc := NewLetterDocument(newTestExportLetter(models.LetterTypeMotivation))
+
+	assert.Equal(t, "Lettre de Motivation", doc.Title)
+	assert.Equal(t, "14 mars 2025", doc.Date)
+	assert.Contains(t, doc.Attention, "Acme")
+	assert.Empty(t, doc.Warning)
+	require.Len(t, doc.Paragraphs, 5)
+	assert.Equal(t, LetterParagraph{"Jean Dupont", "jean@example.com"}, doc.Paragraphs[0])
+	assert.Equal(t, LetterParagraph{"Cordialement,", "Jean Dupont"}, doc.Paragraphs[4])
+
+	anti := NewLetterDocument(newTestExportLetter(models.LetterTypeAntiMotivation))
+	assert.NotEmpty(t, anti.Warning)
+	assert.Empty(t, anti.Attention)
+	assert.Len(t, anti.Footer, 2)
+}
+
+func TestParseExportFormat(t *testing.T) {
+	format, err := ParseExportFormat(" DOCX ")
+	require.NoError(t, err)
+	assert.Equal(t, ExportFormatDOCX, format)
+	assert.Equal(t, "application/vnd.oasis.opendocument.text", ExportFormatODT.ContentType())
+
+	_, err = ParseExportFormat("pdf")
+	assert.ErrorContains(t, err, `unsupported export format "pdf"`)
+}
+
+func TestExportLetter_DOCX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportLetter(newTestExportLetter(models.LetterTypeMotivation), ExportFormatDOCX, &buf))
+
+	_, contents := readZip(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/_rels/document.xml.rels", "word/styles.xml", "word/document.xml"} {
+		require.Contains(t, contents, name)
+		assertWellFormedXML(t, name, contents[name])
+	}
+
+	document := contents["word/document.xml"]
+	assert.Contains(t, document, `<w:pStyle w:val="Title"/>`)
+	assert.Contains(t, document, "Candidature &lt;SRE&gt; &amp; co")
+	assert.Contains(t, document, `Jean Dupont</w:t><w:br/><w:t xml:space="preserve">jean@example.com`)
+	assert.Contains(t, contents["word/styles.xml"], `<w:lang w:val="fr-FR"/>`)
+}
+
+func TestExportLetter_ODT(t *testing.T) {
+	letter := newTestExportLetter(models.LetterTypeAntiMotivation)
+	letter.Language = models.LanguageGerman
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportLetter(letter, ExportFormatODT, &buf))
+
+	files, contents := readZip(t, buf.Bytes())
+	// Le mimetype doit être le premier fichier, non compressé
+	assert.Equal(t, "mimetype", files[0].Name)
+	assert.Equal(t, zip.Store, files[0].Method)
+	assert.Equal(t, "application/vnd.oasis.opendocument.text", contents["mimetype"])
+
+	assertWellFormedXML(t, "manifest", contents["META-INF/manifest.xml"])
+	assertWellFormedXML(t, "content", contents["content.xml"])
+
+	content := contents["content.xml"]
+	assert.Contains(t, content, `<text:p text:style-name="Warning">`)
+	assert.Contains(t, content, "Jean Dupont<text:line-break/>jean@example.com")
+	assert.Contains(t, content, `fo:language="de" fo:country="DE"`)
+}
+
+func TestExportLetter_TextFormats(t *testing.T) {
+	letter := newTestExportLetter(models.LetterTypeMotivation)
+
+	var md bytes.Buffer
+	require.NoError(t, ExportLetter(letter, ExportFormatMarkdown, &md))
+	assert.True(t, strings.HasPrefix(md.String(), "# Lettre de Motivation\n\n*14 mars 2025*\n\n**"))
+	assert.Contains(t, md.String(), "Jean Dupont  \njean@example.com\n\n")
+
+	var txt bytes.Buffer
+	require.NoError(t, ExportLetter(letter, ExportFormatText, &txt))
+	assert.True(t, strings.HasPrefix(txt.String(), "Lettre de Motivation\n====================\n\n"))
+	assert.Contains(t, txt.String(), "Je postule chez Acme.\n\n")
+	assert.NotContains(t, txt.String(), "**")
+}
+
+func TestLetterResponseFromModel(t *testing.T) {
+	letter := &models.GeneratedLetter{
+		CompanyName: "Acme",
+		LetterType:  models.LetterTypeMotivation,
+		Content:     "Bonjour",
+		Language:    models.LanguageEnglish,
+		CompanyInfo: `{"name":"Acme Corp","domain":"acme.com"}`,
+	}
+
+	response := LetterResponseFromModel(letter)
+	assert.Equal(t, "Acme Corp", response.CompanyInfo.Name)
+	assert.Equal(t, models.LanguageEnglish, response.Language)
+
+	letter.CompanyInfo = "not json"
+	assert.Equal(t, "Acme", LetterResponseFromModel(letter).CompanyInfo.Name)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -16,8 +17,18 @@ type LetterGenerator struct {
 	scraper       *CompanyScraper
 	pdfService    *PDFLetterService
 	promptBuilder *PromptBuilder
+
+	// Contrôle qualité : une lettre rejetée est régénérée au plus maxRegenerations fois
+	validator        *LetterValidationPipeline
+	maxRegenerations int
 }
 
+// DefaultMaxRegenerations nombre de régénérations par défaut d'une lettre rejetée
+const DefaultMaxRegenerations = 2
+
+// LetterRegenerationHandler : callback appelé avant chaque régénération d'une lettre rejetée par le contrôle qualité
+type LetterRegenerationHandler func(letterType models.LetterType, report LetterValidationReport)
+
 func NewLetterGenerator(
 	ai *AIService,
 	scraper *CompanyScraper,
@@ -25,22 +36,47 @@ func NewLetterGenerator(
 	profile models.UserProfile,
 ) *LetterGenerator {
 	return &LetterGenerator{
-		aiService:     ai,
-		scraper:       scraper,
-		pdfService:    pdf,
-		promptBuilder: NewPromptBuilder(profile),
+		aiService:        ai,
+		scraper:          scraper,
+		pdfService:       pdf,
+		promptBuilder:    NewPromptBuilder(profile),
+		validator:        NewLetterValidationPipeline(DefaultLetterValidators()...),
+		maxRegenerations: DefaultMaxRegenerations,
 	}
 }
 
+// SetMaxRegenerations configure le nombre maximum de régénérations (0 = contrôle sans régénération)
+func (lg *LetterGenerator) SetMaxRegenerations(n int) {
+	lg.maxRegenerations = max(n, 0)
+}
+
 // GenerateLetter : génère une lettre complète (IA)
 func (lg *LetterGenerator) GenerateLetter(ctx context.Context, req models.LetterRequest) (*models.LetterResponse, error) {
+	return lg.GenerateLetterStream(ctx, req, nil)
+}
+
+// GenerateLetterStream : génère une lettre en transmettant les fragments à onDelta au fil de l'eau
+// Si onDelta est nil, la génération est bloquante (équivalent à GenerateLetter)
+func (lg *LetterGenerator) GenerateLetterStream(ctx context.Context, req models.LetterRequest, onDelta StreamHandler) (*models.LetterResponse, error) {
+	return lg.generateLetter(ctx, req, onDelta, nil)
+}
+
+// generateLetter : génère une lettre contrôlée par le pipeline de validation
+// onRegenerate (optionnel) est appelé avant chaque régénération d'une lettre rejetée
+func (lg *LetterGenerator) generateLetter(ctx context.Context, req models.LetterRequest, onDelta StreamHandler, onRegenerate func(report LetterValidationReport)) (*models.LetterResponse, error) {
 	log.Info().
 		Str("company", req.CompanyName).
 		Str("type", string(req.LetterType)).
+		Str("job_title", req.JobTitle).
+		Str("theme", req.Theme).
+		Str("language", string(req.Language)).
 		Msg("Starting letter generation")
 
+	// Les appels IA de cette lettre sont imputés à son type dans le registre des dépenses
+	ctx = WithUsageLetterType(ctx, req.LetterType)
+
 	// 1. Get company info via scraper
-	companyInfo, err := lg.scraper.GetCompanyInfo(ctx, req.CompanyName)
+	companyInfo, err := lg.scraper.GetCompanyInfoForDomain(ctx, req.CompanyName, req.CompanyDomain)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get company info, using minimal data")
 		// Fallback avec données minimales
@@ -50,84 +86,220 @@ func (lg *LetterGenerator) GenerateLetter(ctx context.Context, req models.Letter
 		}
 	}
 
-	// 2. Build prompt based on type
-	var prompt string
-	switch req.LetterType {
-	case models.LetterTypeMotivation:
-		prompt = lg.promptBuilder.BuildMotivationPrompt(*companyInfo)
-	case models.LetterTypeAntiMotivation:
-		prompt = lg.promptBuilder.BuildAntiMotivationPrompt(*companyInfo)
-	default:
+	// 2. Build prompt from the letter type registry
+	// Un profil fourni dans la requête (ex: profil orienté thème) remplace le profil par défaut
+	promptBuilder := lg.promptBuilder
+	if req.UserProfile.Name != "" {
+		promptBuilder = NewPromptBuilder(req.UserProfile)
+	}
+	opts := PromptOptions{
+		JobTitle:   req.JobTitle,
+		Theme:      req.Theme,
+		JobPosting: req.JobPosting,
+		Language:   req.Language,
+	}
+
+	spec, ok := LetterTypeSpecFor(req.LetterType)
+	if !ok {
 		return nil, fmt.Errorf("unknown letter type: %s", req.LetterType)
 	}
+	prompt := promptBuilder.BuildLetterPrompt(spec, *companyInfo, opts)
+	subject := spec.Subject(opts)
 
-	// 3. Generate text via AI
-	content, metrics, err := lg.aiService.GenerateText(ctx, prompt)
+	// 3. Generate text via AI, checked by the quality gate
+	letter, err := lg.generateChecked(ctx, prompt, &LetterValidationInput{
+		LetterType:  req.LetterType,
+		Language:    req.Language.OrDefault(),
+		CompanyName: req.CompanyName,
+		Subject:     subject,
+		Profile:     promptBuilder.userProfile,
+		Company:     *companyInfo,
+		JobPosting:  req.JobPosting,
+	}, onDelta, onRegenerate)
 	if err != nil {
 		return nil, fmt.Errorf("AI generation failed: %w", err)
 	}
 
 	// 4. Build response
 	response := &models.LetterResponse{
-		Content:       content,
-		Type:          req.LetterType,
-		CompanyInfo:   *companyInfo,
-		GeneratedAt:   time.Now(),
-		Provider:      metrics.Provider,
-		TokensUsed:    metrics.TotalTokens,
-		EstimatedCost: metrics.EstimatedCost,
+		Content:         letter.content,
+		Type:            req.LetterType,
+		Language:        req.Language.OrDefault(),
+		CompanyInfo:     *companyInfo,
+		GeneratedAt:     time.Now(),
+		Provider:        letter.provider,
+		TokensUsed:      letter.tokens,
+		EstimatedCost:   letter.cost,
+		QualityScore:    letter.report.Score,
+		QualityFindings: letter.report.Findings,
+		Regenerations:   letter.regenerations,
 	}
 
 	log.Info().
 		Str("company", req.CompanyName).
 		Str("type", string(req.LetterType)).
-		Int("tokens", metrics.TotalTokens).
-		Float64("cost", metrics.EstimatedCost).
+		Int("tokens", letter.tokens).
+		Float64("cost", letter.cost).
+		Int("quality_score", letter.report.Score).
+		Int("regenerations", letter.regenerations).
 		Msg("Letter generated successfully")
 
 	return response, nil
 }
 
+// checkedLetter : lettre retenue après contrôle qualité (coûts cumulés sur toutes les tentatives)
+type checkedLetter struct {
+	content       string
+	provider      string
+	report        LetterValidationReport
+	tokens        int
+	cost          float64
+	regenerations int
+}
+
+// generateChecked : génère une lettre puis la soumet au contrôle qualité
+// Une lettre rejetée est régénérée avec la liste des corrections attendues, au plus
+// maxRegenerations fois. La meilleure tentative est retenue (validée d'abord, puis meilleur score).
+func (lg *LetterGenerator) generateChecked(ctx context.Context, prompt string, input *LetterValidationInput, onDelta StreamHandler, onRegenerate func(report LetterValidationReport)) (*checkedLetter, error) {
+	var best *checkedLetter
+	tokens, cost := 0, 0.0
+	attemptPrompt := prompt
+
+	for attempt := 0; ; attempt++ {
+		content, metrics, err := lg.aiService.GenerateTextStream(ctx, attemptPrompt, onDelta)
+		if err != nil {
+			// Une régénération échouée n'invalide pas la tentative précédente
+			if best == nil {
+				return nil, err
+			}
+			log.Warn().Err(err).Int("attempt", attempt).Msg("Letter regeneration failed, keeping previous attempt")
+			break
+		}
+		tokens += metrics.TotalTokens
+		cost += metrics.EstimatedCost
+
+		report := LetterValidationReport{Score: 100, Passed: true, Findings: models.LetterFindings{}}
+		if lg.validator != nil {
+			input.Content = content
+			report = lg.validator.Validate(input)
+		}
+
+		if best == nil || (report.Passed && !best.report.Passed) || (report.Passed == best.report.Passed && report.Score > best.report.Score) {
+			best = &checkedLetter{content: content, provider: metrics.Provider, report: report}
+		}
+		best.regenerations = attempt
+
+		if report.Passed || attempt >= lg.maxRegenerations {
+			break
+		}
+
+		log.Warn().
+			Str("type", string(input.LetterType)).
+			Int("quality_score", report.Score).
+			Int("attempt", attempt+1).
+			Interface("findings", report.Findings).
+			Msg("Letter rejected by quality gate, regenerating")
+
+		if onRegenerate != nil {
+			onRegenerate(report)
+		}
+		attemptPrompt = prompt + correctionSection(localeFor(input.Language), report)
+	}
+
+	best.tokens = tokens
+	best.cost = cost
+	return best, nil
+}
+
+// correctionSection : consignes ajoutées au prompt pour corriger une lettre rejetée
+func correctionSection(l *letterLocale, report LetterValidationReport) string {
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	sb.WriteString(l.qualityRetry)
+	sb.WriteString("\n")
+	for _, finding := range report.Findings {
+		sb.WriteString(fmt.Sprintf("- %s\n", finding.Message))
+	}
+	return sb.String()
+}
+
+// LetterDeltaHandler : callback de streaming identifiant la lettre d'origine du fragment
+type LetterDeltaHandler func(letterType models.LetterType, delta string)
+
 // GenerateDualLetters : génère les 2 lettres en parallèle
-func (lg *LetterGenerator) GenerateDualLetters(ctx context.Context, companyName string) (*models.LetterResponse, *models.LetterResponse, error) {
+// req porte l'entreprise, le poste, le thème et le profil communs (LetterType est ignoré).
+// onDelta (optionnel) reçoit les fragments des deux lettres au fil de la génération,
+// onRegenerate (optionnel) est appelé quand une lettre rejetée par le contrôle qualité est régénérée
+func (lg *LetterGenerator) GenerateDualLetters(ctx context.Context, req models.LetterRequest, onDelta LetterDeltaHandler, onRegenerate LetterRegenerationHandler) (*models.LetterResponse, *models.LetterResponse, error) {
+	letters, err := lg.GenerateLetters(ctx, req, DefaultLetterTypes, onDelta, onRegenerate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return letters[models.LetterTypeMotivation], letters[models.LetterTypeAntiMotivation], nil
+}
+
+// GenerateLetters : génère en parallèle une lettre par type demandé
+// req porte l'entreprise, le poste, le thème et le profil communs (LetterType est ignoré).
+// L'échec d'un type fait échouer l'ensemble (le job est relancé en entier).
+func (lg *LetterGenerator) GenerateLetters(ctx context.Context, req models.LetterRequest, letterTypes []models.LetterType, onDelta LetterDeltaHandler, onRegenerate LetterRegenerationHandler) (map[models.LetterType]*models.LetterResponse, error) {
+	if len(letterTypes) == 0 {
+		return nil, fmt.Errorf("no letter type requested")
+	}
+
 	type result struct {
 		letter *models.LetterResponse
 		err    error
 	}
 
-	motivationChan := make(chan result, 1)
-	antiMotivationChan := make(chan result, 1)
-
-	// Generate motivation letter
-	go func() {
-		letter, err := lg.GenerateLetter(ctx, models.LetterRequest{
-			CompanyName: companyName,
-			LetterType:  models.LetterTypeMotivation,
-		})
-		motivationChan <- result{letter, err}
-	}()
+	results := make([]chan result, len(letterTypes))
+	for i, letterType := range letterTypes {
+		results[i] = make(chan result, 1)
+		go func(letterType models.LetterType, out chan<- result) {
+			letterReq := req
+			letterReq.LetterType = letterType
+			letter, err := lg.generateLetter(ctx, letterReq, deltaFor(onDelta, letterType), regenerateFor(onRegenerate, letterType))
+			out <- result{letter, err}
+		}(letterType, results[i])
+	}
 
-	// Generate anti-motivation letter
-	go func() {
-		letter, err := lg.GenerateLetter(ctx, models.LetterRequest{
-			CompanyName: companyName,
-			LetterType:  models.LetterTypeAntiMotivation,
-		})
-		antiMotivationChan <- result{letter, err}
-	}()
+	// Attendre toutes les lettres, la première erreur (dans l'ordre demandé) est retournée
+	letters := make(map[models.LetterType]*models.LetterResponse, len(letterTypes))
+	var firstErr error
+	for i, letterType := range letterTypes {
+		res := <-results[i]
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s letter failed: %w", letterType, res.err)
+			}
+			continue
+		}
+		letters[letterType] = res.letter
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
 
-	// Wait for both
-	motivationResult := <-motivationChan
-	antiMotivationResult := <-antiMotivationChan
+	return letters, nil
+}
 
-	if motivationResult.err != nil {
-		return nil, nil, fmt.Errorf("motivation letter failed: %w", motivationResult.err)
+// deltaFor : adapte un LetterDeltaHandler en StreamHandler pour un type de lettre
+func deltaFor(onDelta LetterDeltaHandler, letterType models.LetterType) StreamHandler {
+	if onDelta == nil {
+		return nil
 	}
-	if antiMotivationResult.err != nil {
-		return nil, nil, fmt.Errorf("anti-motivation letter failed: %w", antiMotivationResult.err)
+	return func(delta string) {
+		onDelta(letterType, delta)
 	}
+}
 
-	return motivationResult.letter, antiMotivationResult.letter, nil
+// regenerateFor : adapte un LetterRegenerationHandler pour un type de lettre
+func regenerateFor(onRegenerate LetterRegenerationHandler, letterType models.LetterType) func(report LetterValidationReport) {
+	if onRegenerate == nil {
+		return nil
+	}
+	return func(report LetterValidationReport) {
+		onRegenerate(letterType, report)
+	}
 }
 
 // GenerateLetterPDF : génère le PDF d'une lettre
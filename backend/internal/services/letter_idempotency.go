@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Clés Redis de déduplication des requêtes de génération (par session)
+// letters:idempotency:<session>:<hash de la clé> : header Idempotency-Key
+// letters:dedupe:<session>:<empreinte>           : requêtes identiques sans clé
+const (
+	letterIdempotencyKeyPrefix  = "letters:idempotency"
+	letterDedupeKeyPrefix       = "letters:dedupe"
+	letterIdempotencyPendingTTL = 30 * time.Second      // Réservation d'une requête en cours d'enqueue
+	letterIdempotencyPoll       = 50 * time.Millisecond // Attente d'une requête identique concurrente
+)
+
+const (
+	// DefaultIdempotencyKeyTTL durée de validité d'une Idempotency-Key
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// DefaultLetterDedupeWindow fenêtre de déduplication automatique des requêtes identiques
+	DefaultLetterDedupeWindow = 10 * time.Minute
+	// DefaultIdempotencyWait attente maximale d'une requête identique encore en cours
+	DefaultIdempotencyWait = 5 * time.Second
+)
+
+// ErrIdempotencyKeyReused la clé a déjà servi pour une requête différente
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different request")
+
+// ErrIdempotencyInProgress une requête identique est toujours en cours de traitement
+var ErrIdempotencyInProgress = errors.New("identical request still in progress")
+
+// letterJobLookup lecture du statut d'un job (LetterQueueService)
+type letterJobLookup interface {
+	GetJobStatus(jobID string) (*LetterJob, error)
+}
+
+// letterIdempotencyRecord contenu d'une clé de déduplication (JobID vide : enqueue en cours)
+type letterIdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	JobID       string `json:"job_id,omitempty"`
+}
+
+// IdempotencyReservation requête réservée : Complete une fois le job créé, Release sinon
+type IdempotencyReservation struct {
+	key         string
+	fingerprint string
+	ttl         time.Duration
+}
+
+// LetterIdempotencyService déduplication des requêtes de génération de lettres
+// Une requête rejouée (double clic, retry client) retourne le job d'origine : ni nouvel
+// appel IA ni consommation du quota. Avec une Idempotency-Key, la clé seule fait foi ;
+// sans clé, les requêtes identiques d'une session sont regroupées dans une fenêtre glissante.
+type LetterIdempotencyService struct {
+	redis  *redis.Client
+	jobs   letterJobLookup
+	keyTTL time.Duration
+	window time.Duration
+	wait   time.Duration
+}
+
+// NewLetterIdempotencyService crée le service de déduplication
+func NewLetterIdempotencyService(redisClient *redis.Client, jobs letterJobLookup) *LetterIdempotencyService {
+	return &LetterIdempotencyService{
+		redis:  redisClient,
+		jobs:   jobs,
+		keyTTL: DefaultIdempotencyKeyTTL,
+		window: DefaultLetterDedupeWindow,
+		wait:   DefaultIdempotencyWait,
+	}
+}
+
+// SetKeyTTL modifie la durée de validité des Idempotency-Key
+func (s *LetterIdempotencyService) SetKeyTTL(ttl time.Duration) {
+	s.keyTTL = ttl
+}
+
+// SetDedupeWindow modifie la fenêtre de déduplication automatique (0 : désactivée)
+func (s *LetterIdempotencyService) SetDedupeWindow(window time.Duration) {
+	s.window = window
+}
+
+// SetWait modifie l'attente maximale d'une requête identique en cours
+func (s *LetterIdempotencyService) SetWait(wait time.Duration) {
+	s.wait = wait
+}
+
+// LetterRequestFingerprint empreinte d'une requête de génération (casse et espaces ignorés)
+func LetterRequestFingerprint(req LetterJobRequest) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+
+	letterTypes := make([]string, 0, len(req.LetterTypes))
+	for _, letterType := range req.LetterTypes {
+		letterTypes = append(letterTypes, string(letterType))
+	}
+	sort.Strings(letterTypes)
+
+	content, _ := json.Marshal([]string{
+		normalize(req.CompanyName),
+		normalize(req.JobTitle),
+		req.Theme,
+		req.Language,
+		strings.TrimSpace(req.JobPostingURL),
+		strings.TrimSpace(req.JobPostingText),
+		strings.Join(letterTypes, ","),
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16])
+}
+
+// letterIdempotencyKey clé Redis d'une Idempotency-Key (hachée : valeur libre du client)
+func letterIdempotencyKey(sessionID, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey))
+	return fmt.Sprintf("%s:%s:%s", letterIdempotencyKeyPrefix, sessionID, hex.EncodeToString(sum[:16]))
+}
+
+// letterDedupeKey clé Redis de déduplication automatique
+func letterDedupeKey(sessionID, fingerprint string) string {
+	return fmt.Sprintf("%s:%s:%s", letterDedupeKeyPrefix, sessionID, fingerprint)
+}
+
+// Reserve retourne le job d'origine d'une requête déjà acceptée, ou réserve la requête
+// Sans job existant ni réservation (déduplication désactivée), la requête est traitée normalement.
+// Les jobs expirés sont ignorés, ainsi que les jobs échoués ou annulés en déduplication
+// automatique (une Idempotency-Key rejoue toujours le même résultat).
+func (s *LetterIdempotencyService) Reserve(ctx context.Context, sessionID, idempotencyKey string, req LetterJobRequest) (*LetterJob, *IdempotencyReservation, error) {
+	explicit := idempotencyKey != ""
+	fingerprint := LetterRequestFingerprint(req)
+
+	reservation := &IdempotencyReservation{fingerprint: fingerprint}
+	if explicit {
+		reservation.key, reservation.ttl = letterIdempotencyKey(sessionID, idempotencyKey), s.keyTTL
+	} else {
+		if s.window <= 0 {
+			return nil, nil, nil
+		}
+		reservation.key, reservation.ttl = letterDedupeKey(sessionID, fingerprint), s.window
+	}
+
+	pending, _ := json.Marshal(letterIdempotencyRecord{Fingerprint: fingerprint})
+	deadline := time.Now().Add(s.wait)
+
+	for {
+		reserved, err := s.redis.SetNX(ctx, reservation.key, pending, letterIdempotencyPendingTTL).Result()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to reserve request: %w", err)
+		}
+		if reserved {
+			return nil, reservation, nil
+		}
+
+		raw, err := s.redis.Get(ctx, reservation.key).Bytes()
+		if err == redis.Nil {
+			continue // Expirée entre-temps
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read reservation: %w", err)
+		}
+
+		var record letterIdempotencyRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode reservation: %w", err)
+		}
+		if record.Fingerprint != fingerprint {
+			return nil, nil, ErrIdempotencyKeyReused
+		}
+
+		if record.JobID != "" {
+			job, err := s.jobs.GetJobStatus(record.JobID)
+			if err == nil && (explicit || (job.Status != JobStatusFailed && job.Status != JobStatusCancelled)) {
+				return job, nil, nil
+			}
+			// Job expiré, échoué ou annulé : la requête est traitée à nouveau
+			if err := s.redis.Del(ctx, reservation.key).Err(); err != nil {
+				return nil, nil, fmt.Errorf("failed to release reservation: %w", err)
+			}
+			continue
+		}
+
+		// Requête identique en cours d'enqueue : attendre son job
+		if time.Now().After(deadline) {
+			return nil, nil, ErrIdempotencyInProgress
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(letterIdempotencyPoll):
+		}
+	}
+}
+
+// Complete associe la requête réservée au job créé
+func (s *LetterIdempotencyService) Complete(ctx context.Context, reservation *IdempotencyReservation, jobID string) error {
+	record, _ := json.Marshal(letterIdempotencyRecord{Fingerprint: reservation.fingerprint, JobID: jobID})
+	return s.redis.Set(ctx, reservation.key, record, reservation.ttl).Err()
+}
+
+// Release libère une réservation sans job (requête refusée ou en échec)
+func (s *LetterIdempotencyService) Release(ctx context.Context, reservation *IdempotencyReservation) error {
+	return s.redis.Del(ctx, reservation.key).Err()
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func newTestLetterIdempotency(t *testing.T) (*LetterIdempotencyService, *LetterQueueService) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	queue := NewLetterQueueService(redisClient)
+	return NewLetterIdempotencyService(redisClient, queue), queue
+}
+
+func TestLetterRequestFingerprint(t *testing.T) {
+	req := LetterJobRequest{CompanyName: "Google", JobTitle: "Backend Engineer", Theme: "backend",
+		LetterTypes: []models.LetterType{models.LetterTypeMotivation, models.LetterTypeAntiMotivation}}
+	fingerprint := LetterRequestFingerprint(req)
+
+	same := req
+	same.CompanyName, same.JobTitle = "  google ", "backend  engineer"
+	same.LetterTypes = []models.LetterType{models.LetterTypeAntiMotivation, models.LetterTypeMotivation}
+	same.VisitorID, same.Priority = "other-session", JobPriorityHigh
+	assert.Equal(t, fingerprint, LetterRequestFingerprint(same))
+
+	other := req
+	other.Theme = "devops"
+	assert.NotEqual(t, fingerprint, LetterRequestFingerprint(other))
+}
+
+func TestLetterIdempotencyService_Dedupe(t *testing.T) {
+	service, queue := newTestLetterIdempotency(t)
+	ctx := context.Background()
+	req := LetterJobRequest{CompanyName: "Google", Theme: "backend"}
+
+	job, reservation, err := service.Reserve(ctx, "session-1", "", req)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	require.NotNil(t, reservation)
+
+	jobID, _ := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Google", Theme: "backend"})
+	require.NoError(t, service.Complete(ctx, reservation, jobID))
+
+	// Requête identique : job d'origine
+	job, reservation, err = service.Reserve(ctx, "session-1", "", LetterJobRequest{CompanyName: "GOOGLE", Theme: "backend"})
+	require.NoError(t, err)
+	assert.Nil(t, reservation)
+	require.NotNil(t, job)
+	assert.Equal(t, jobID, job.JobID)
+
+	// Autre session ou autre requête : pas de déduplication
+	_, reservation, _ = service.Reserve(ctx, "session-2", "", req)
+	assert.NotNil(t, reservation)
+	_, reservation, _ = service.Reserve(ctx, "session-1", "", LetterJobRequest{CompanyName: "Meta", Theme: "backend"})
+	assert.NotNil(t, reservation)
+
+	// Job échoué : la requête est traitée à nouveau
+	require.NoError(t, queue.FailJob(jobID, "AI provider unavailable"))
+	job, reservation, err = service.Reserve(ctx, "session-1", "", req)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NotNil(t, reservation)
+
+	service.SetDedupeWindow(0)
+	job, reservation, err = service.Reserve(ctx, "session-3", "", req)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.Nil(t, reservation)
+}
+
+func TestLetterIdempotencyService_IdempotencyKey(t *testing.T) {
+	service, queue := newTestLetterIdempotency(t)
+	ctx := context.Background()
+	req := LetterJobRequest{CompanyName: "Google", Theme: "backend"}
+
+	_, reservation, err := service.Reserve(ctx, "session-1", "key-1", req)
+	require.NoError(t, err)
+	require.NotNil(t, reservation)
+
+	jobID, _ := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Google", Theme: "backend"})
+	require.NoError(t, service.Complete(ctx, reservation, jobID))
+	require.NoError(t, queue.FailJob(jobID, "AI provider unavailable"))
+
+	// Même clé : même résultat, y compris en échec
+	job, _, err := service.Reserve(ctx, "session-1", "key-1", req)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, JobStatusFailed, job.Status)
+
+	_, _, err = service.Reserve(ctx, "session-1", "key-1", LetterJobRequest{CompanyName: "Meta", Theme: "backend"})
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// Nouvelle clé : nouvelle génération
+	job, reservation, err = service.Reserve(ctx, "session-1", "key-2", req)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NotNil(t, reservation)
+}
+
+func TestLetterIdempotencyService_ConcurrentRequest(t *testing.T) {
+	service, queue := newTestLetterIdempotency(t)
+	ctx := context.Background()
+	req := LetterJobRequest{CompanyName: "Google", Theme: "backend"}
+
+	_, reservation, err := service.Reserve(ctx, "session-1", "", req)
+	require.NoError(t, err)
+
+	// Double clic : la seconde requête attend le job de la première
+	jobID, _ := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Google", Theme: "backend"})
+	time.AfterFunc(100*time.Millisecond, func() { _ = service.Complete(ctx, reservation, jobID) })
+
+	job, _, err := service.Reserve(ctx, "session-1", "", req)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, jobID, job.JobID)
+
+	// Première requête bloquée au-delà de l'attente
+	service.SetWait(100 * time.Millisecond)
+	_, reservation, _ = service.Reserve(ctx, "session-2", "", req)
+	_, _, err = service.Reserve(ctx, "session-2", "", req)
+	assert.ErrorIs(t, err, ErrIdempotencyInProgress)
+
+	// Réservation libérée (requête refusée) : la suivante est traitée
+	require.NoError(t, service.Release(ctx, reservation))
+	_, reservation, err = service.Reserve(ctx, "session-2", "", req)
+	require.NoError(t, err)
+	assert.NotNil(t, reservation)
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"maicivy/internal/models"
+)
+
+// letterLocale : textes et conventions de rédaction d'une langue
+// (prompts, format de date et d'adresse, libellés des PDF)
+type letterLocale struct {
+	months    [12]string
+	dateFmt   string // jour, mois, année (ex: "%d %s %d")
+	placeDate string // lieu + date (ex: "%s, le %s"), vide = date seule
+
+	// Code postal après la ville (usage anglo-saxon)
+	postalCodeAfterCity bool
+
+	// Objet de la lettre
+	spontaneousSubject string
+	jobSubject         string // %s = poste
+	antiSubject        string // %s = jobSuffix ou vide
+	followUpSubject    string // %s = jobSuffix ou vide
+	thankYouSubject    string // %s = jobSuffix ou vide
+	jobSuffix          string // %s = poste
+	subjectLabel       string // Préfixe de la ligne d'objet (vide si l'usage n'en met pas)
+
+	// Section "cible de la candidature"
+	targetJob         string // %s = poste
+	targetNoJob       string
+	targetTheme       string // %s = nom du thème, %s = description
+	targetThemeNote   string
+	postingLabel      string
+	missionsLabel     string
+	requirementsLabel string
+	technologiesLine  string // %s = technologies
+
+	// Consignes additionnelles de la tâche
+	motivationJobFocus     string // %s = poste
+	motivationPostingFocus string
+	antiJobFocus           string // %s = poste
+	antiPostingFocus       string
+
+	// Consigne ajoutée au prompt quand une lettre est rejetée par le contrôle qualité
+	qualityRetry string
+
+	// Profil
+	noExperiences string
+	present       string // fin d'une expérience en cours
+	summary       string // %s = rôle, %d = années, %s = compétences
+
+	// Nom (pluriel) de chaque type de lettre, repris par les prompts de révision
+	typeNames map[models.LetterType]string
+
+	motivation      *template.Template
+	antiMotivation  *template.Template
+	followUpEmail   *template.Template
+	thankYouNote    *template.Template
+	linkedInMessage *template.Template
+	elevatorPitch   *template.Template
+	revision        *template.Template
+
+	pdf letterPDFLabels
+}
+
+// letterPDFLabels : libellés des templates PDF de lettres
+type letterPDFLabels struct {
+	MotivationTitle     string
+	AntiMotivationTitle string
+	Attention           string // %s = entreprise
+	Footer              string
+	AntiWarning         string
+	AntiFooter          string
+	AntiFooterTagline   string
+
+	// Titres des autres types de lettres (mise en page de la lettre de motivation)
+	Titles map[models.LetterType]string
+}
+
+// localeFor retourne la locale d'une langue (français si non supportée)
+func localeFor(lang models.Language) *letterLocale {
+	return letterLocales[lang.OrDefault()]
+}
+
+// formatDate formate une date en toutes lettres (ex: "5 janvier 2026")
+func (l *letterLocale) formatDate(t time.Time) string {
+	return fmt.Sprintf(l.dateFmt, t.Day(), l.months[t.Month()-1], t.Year())
+}
+
+// formatLetterDate formate la ligne de date d'une lettre (ex: "Tourtenay, le 5 janvier 2026")
+func (l *letterLocale) formatLetterDate(city string, t time.Time) string {
+	date := l.formatDate(t)
+	if city == "" || l.placeDate == "" {
+		return date
+	}
+	return fmt.Sprintf(l.placeDate, city, date)
+}
+
+// formatLocality formate la ligne code postal / ville de l'adresse
+func (l *letterLocale) formatLocality(postalCode, city string) string {
+	switch {
+	case postalCode == "":
+		return city
+	case city == "":
+		return postalCode
+	case l.postalCodeAfterCity:
+		return city + " " + postalCode
+	}
+	return postalCode + " " + city
+}
+
+// formatDuration formate la période d'une expérience (ex: "2021 - présent")
+func (l *letterLocale) formatDuration(start time.Time, end *time.Time) string {
+	if end == nil {
+		return fmt.Sprintf("%d - %s", start.Year(), l.present)
+	}
+	return fmt.Sprintf("%d - %d", start.Year(), end.Year())
+}
+
+var letterLocales = map[models.Language]*letterLocale{
+	models.LanguageFrench: {
+		months:    [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		dateFmt:   "%d %s %d",
+		placeDate: "%s, le %s",
+
+		spontaneousSubject: "Candidature spontanée",
+		jobSubject:         "Candidature au poste de %s",
+		antiSubject:        "Lettre d'anti-motivation%s (humour au second degré)",
+		followUpSubject:    "Relance de ma candidature%s",
+		thankYouSubject:    "Merci pour notre entretien%s",
+		jobSuffix:          " pour le poste de %s",
+		subjectLabel:       "Objet",
+
+		targetJob:         "- Poste visé: %s\n",
+		targetNoJob:       "- Poste visé: aucun (candidature spontanée)\n",
+		targetTheme:       "- Axe du profil: %s (%s)\n",
+		targetThemeNote:   "- Les expériences et compétences ci-dessus sont triées par pertinence pour cet axe\n",
+		postingLabel:      "- Offre d'emploi:\n",
+		missionsLabel:     "Missions",
+		requirementsLabel: "Exigences",
+		technologiesLine:  "  Technologies citées: %s\n",
+
+		motivationJobFocus:     " Le corps de la lettre doit expliquer pourquoi le candidat est fait pour le poste de %s.",
+		motivationPostingFocus: " Réponds aux missions et exigences principales de l'offre en t'appuyant sur des expériences concrètes du candidat, sans prétendre maîtriser ce qui n'apparaît pas dans son parcours.",
+		antiJobFocus:           " Tourne en dérision le poste de %s en particulier.",
+		antiPostingFocus:       " Détourne avec humour les exigences de l'offre.",
+
+		qualityRetry: "ATTENTION : une version précédente de cette lettre a été rejetée par le contrôle qualité. Corrige impérativement les points suivants :",
+
+		noExperiences: "Aucune expérience détaillée disponible.",
+		present:       "présent",
+		summary:       "%s avec %d ans d'expérience, spécialisé en %s",
+
+		typeNames: map[models.LetterType]string{
+			models.LetterTypeMotivation:     "lettres de motivation professionnelles",
+			models.LetterTypeAntiMotivation: "lettres d'anti-motivation humoristiques",
+			models.LetterTypeFollowUpEmail:  "e-mails de relance de candidature",
+			models.LetterTypeThankYouNote:   "messages de remerciement après entretien",
+			models.LetterTypeLinkedIn:       "messages d'approche LinkedIn",
+			models.LetterTypeElevatorPitch:  "pitchs de présentation à l'oral",
+		},
+
+		motivation:      template.Must(template.New("motivation_fr").Parse(motivationPromptFR)),
+		antiMotivation:  template.Must(template.New("anti_motivation_fr").Parse(antiMotivationPromptFR)),
+		followUpEmail:   template.Must(template.New("follow_up_email_fr").Parse(followUpEmailPromptFR)),
+		thankYouNote:    template.Must(template.New("thank_you_note_fr").Parse(thankYouNotePromptFR)),
+		linkedInMessage: template.Must(template.New("linkedin_message_fr").Parse(linkedInMessagePromptFR)),
+		elevatorPitch:   template.Must(template.New("elevator_pitch_fr").Parse(elevatorPitchPromptFR)),
+		revision:        template.Must(template.New("revision_fr").Parse(revisionPromptFR)),
+
+		pdf: letterPDFLabels{
+			MotivationTitle:     "Lettre de Motivation",
+			AntiMotivationTitle: "Lettre d'Anti-Motivation",
+			Attention:           "À l'attention de %s",
+			Footer:              "Générée automatiquement par maicivy - CV Intelligent",
+			AntiWarning:         "Humour et second degré - Ne pas prendre au sérieux !",
+			AntiFooter:          "Générée par l'IA avec beaucoup d'humour 🤖",
+			AntiFooterTagline:   "maicivy - Parce que l'auto-dérision, c'est la vie",
+			Titles: map[models.LetterType]string{
+				models.LetterTypeFollowUpEmail: "E-mail de relance",
+				models.LetterTypeThankYouNote:  "Message de remerciement",
+				models.LetterTypeLinkedIn:      "Message LinkedIn",
+				models.LetterTypeElevatorPitch: "Pitch de présentation",
+			},
+		},
+	},
+
+	models.LanguageEnglish: {
+		months:              [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		dateFmt:             "%d %s %d",
+		postalCodeAfterCity: true,
+
+		spontaneousSubject: "Speculative application",
+		jobSubject:         "Application for the position of %s",
+		antiSubject:        "Letter of anti-motivation%s (tongue in cheek)",
+		followUpSubject:    "Following up on my application%s",
+		thankYouSubject:    "Thank you for our interview%s",
+		jobSuffix:          " for the position of %s",
+		subjectLabel:       "Subject",
+
+		targetJob:         "- Target position: %s\n",
+		targetNoJob:       "- Target position: none (speculative application)\n",
+		targetTheme:       "- Profile focus: %s (%s)\n",
+		targetThemeNote:   "- The experiences and skills above are sorted by relevance for this focus\n",
+		postingLabel:      "- Job posting:\n",
+		missionsLabel:     "Responsibilities",
+		requirementsLabel: "Requirements",
+		technologiesLine:  "  Technologies mentioned: %s\n",
+
+		motivationJobFocus:     " The body of the letter must explain why the candidate is the right fit for the %s position.",
+		motivationPostingFocus: " Address the main responsibilities and requirements of the job posting with concrete experiences from the candidate's background, without claiming expertise that does not appear in it.",
+		antiJobFocus:           " Make fun of the %s position in particular.",
+		antiPostingFocus:       " Humorously twist the requirements of the job posting.",
+
+		qualityRetry: "IMPORTANT: a previous version of this letter was rejected by quality control. Make sure to fix the following issues:",
+
+		noExperiences: "No detailed experience available.",
+		present:       "present",
+		summary:       "%s with %d years of experience, specialised in %s",
+
+		typeNames: map[models.LetterType]string{
+			models.LetterTypeMotivation:     "professional cover letters",
+			models.LetterTypeAntiMotivation: "humorous anti-motivation letters",
+			models.LetterTypeFollowUpEmail:  "job application follow-up emails",
+			models.LetterTypeThankYouNote:   "post-interview thank-you notes",
+			models.LetterTypeLinkedIn:       "LinkedIn outreach messages",
+			models.LetterTypeElevatorPitch:  "spoken elevator pitches",
+		},
+
+		motivation:      template.Must(template.New("motivation_en").Parse(motivationPromptEN)),
+		antiMotivation:  template.Must(template.New("anti_motivation_en").Parse(antiMotivationPromptEN)),
+		followUpEmail:   template.Must(template.New("follow_up_email_en").Parse(followUpEmailPromptEN)),
+		thankYouNote:    template.Must(template.New("thank_you_note_en").Parse(thankYouNotePromptEN)),
+		linkedInMessage: template.Must(template.New("linkedin_message_en").Parse(linkedInMessagePromptEN)),
+		elevatorPitch:   template.Must(template.New("elevator_pitch_en").Parse(elevatorPitchPromptEN)),
+		revision:        template.Must(template.New("revision_en").Parse(revisionPromptEN)),
+
+		pdf: letterPDFLabels{
+			MotivationTitle:     "Cover Letter",
+			AntiMotivationTitle: "Anti-Motivation Letter",
+			Attention:           "For the attention of %s",
+			Footer:              "Automatically generated by maicivy - Smart CV",
+			AntiWarning:         "Humour and irony - Do not take seriously!",
+			AntiFooter:          "Generated by AI with plenty of humour 🤖",
+			AntiFooterTagline:   "maicivy - Because self-mockery is a way of life",
+			Titles: map[models.LetterType]string{
+				models.LetterTypeFollowUpEmail: "Follow-up Email",
+				models.LetterTypeThankYouNote:  "Thank-you Note",
+				models.LetterTypeLinkedIn:      "LinkedIn Message",
+				models.LetterTypeElevatorPitch: "Elevator Pitch",
+			},
+		},
+	},
+
+	models.LanguageGerman: {
+		months:    [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		dateFmt:   "%d. %s %d",
+		placeDate: "%s, %s",
+
+		spontaneousSubject: "Initiativbewerbung",
+		jobSubject:         "Bewerbung als %s",
+		antiSubject:        "Anti-Motivationsschreiben%s (mit einem Augenzwinkern)",
+		followUpSubject:    "Nachfrage zu meiner Bewerbung%s",
+		thankYouSubject:    "Vielen Dank für das Gespräch%s",
+		jobSuffix:          " für die Stelle als %s",
+		subjectLabel:       "", // Pas de préfixe "Betreff" en allemand
+
+		targetJob:         "- Angestrebte Stelle: %s\n",
+		targetNoJob:       "- Angestrebte Stelle: keine (Initiativbewerbung)\n",
+		targetTheme:       "- Profilschwerpunkt: %s (%s)\n",
+		targetThemeNote:   "- Die obigen Erfahrungen und Kompetenzen sind nach Relevanz für diesen Schwerpunkt sortiert\n",
+		postingLabel:      "- Stellenanzeige:\n",
+		missionsLabel:     "Aufgaben",
+		requirementsLabel: "Anforderungen",
+		technologiesLine:  "  Genannte Technologien: %s\n",
+
+		motivationJobFocus:     " Der Hauptteil des Schreibens muss erklären, warum der Kandidat ideal für die Stelle als %s geeignet ist.",
+		motivationPostingFocus: " Gehe auf die wichtigsten Aufgaben und Anforderungen der Stellenanzeige ein und stütze dich auf konkrete Erfahrungen des Kandidaten, ohne Kenntnisse zu behaupten, die nicht in seinem Werdegang vorkommen.",
+		antiJobFocus:           " Nimm insbesondere die Stelle als %s auf die Schippe.",
+		antiPostingFocus:       " Verdrehe die Anforderungen der Stellenanzeige mit Humor.",
+
+		qualityRetry: "WICHTIG: Eine frühere Version dieses Schreibens wurde von der Qualitätskontrolle abgelehnt. Behebe unbedingt folgende Punkte:",
+
+		noExperiences: "Keine detaillierten Erfahrungen verfügbar.",
+		present:       "heute",
+		summary:       "%s mit %d Jahren Berufserfahrung, spezialisiert auf %s",
+
+		typeNames: map[models.LetterType]string{
+			models.LetterTypeMotivation:     "professioneller Bewerbungsanschreiben",
+			models.LetterTypeAntiMotivation: "humorvoller Anti-Motivationsschreiben",
+			models.LetterTypeFollowUpEmail:  "von Nachfass-E-Mails zu Bewerbungen",
+			models.LetterTypeThankYouNote:   "von Dankesnachrichten nach Vorstellungsgesprächen",
+			models.LetterTypeLinkedIn:       "von LinkedIn-Kontaktnachrichten",
+			models.LetterTypeElevatorPitch:  "von mündlichen Elevator Pitches",
+		},
+
+		motivation:      template.Must(template.New("motivation_de").Parse(motivationPromptDE)),
+		antiMotivation:  template.Must(template.New("anti_motivation_de").Parse(antiMotivationPromptDE)),
+		followUpEmail:   template.Must(template.New("follow_up_email_de").Parse(followUpEmailPromptDE)),
+		thankYouNote:    template.Must(template.New("thank_you_note_de").Parse(thankYouNotePromptDE)),
+		linkedInMessage: template.Must(template.New("linkedin_message_de").Parse(linkedInMessagePromptDE)),
+		elevatorPitch:   template.Must(template.New("elevator_pitch_de").Parse(elevatorPitchPromptDE)),
+		revision:        template.Must(template.New("revision_de").Parse(revisionPromptDE)),
+
+		pdf: letterPDFLabels{
+			MotivationTitle:     "Anschreiben",
+			AntiMotivationTitle: "Anti-Motivationsschreiben",
+			Attention:           "Zu Händen von %s",
+			Footer:              "Automatisch erstellt von maicivy - Intelligenter Lebenslauf",
+			AntiWarning:         "Humor und Ironie - Bitte nicht ernst nehmen!",
+			AntiFooter:          "Von der KI mit viel Humor erstellt 🤖",
+			AntiFooterTagline:   "maicivy - Weil Selbstironie Lebensfreude ist",
+			Titles: map[models.LetterType]string{
+				models.LetterTypeFollowUpEmail: "Nachfass-E-Mail",
+				models.LetterTypeThankYouNote:  "Dankesnachricht",
+				models.LetterTypeLinkedIn:      "LinkedIn-Nachricht",
+				models.LetterTypeElevatorPitch: "Elevator Pitch",
+			},
+		},
+	},
+
+	models.LanguageSpanish: {
+		months:    [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		dateFmt:   "%d de %s de %d",
+		placeDate: "%s, %s",
+
+		spontaneousSubject: "Candidatura espontánea",
+		jobSubject:         "Candidatura al puesto de %s",
+		antiSubject:        "Carta de antimotivación%s (con humor)",
+		followUpSubject:    "Seguimiento de mi candidatura%s",
+		thankYouSubject:    "Gracias por la entrevista%s",
+		jobSuffix:          " para el puesto de %s",
+		subjectLabel:       "Asunto",
+
+		targetJob:         "- Puesto objetivo: %s\n",
+		targetNoJob:       "- Puesto objetivo: ninguno (candidatura espontánea)\n",
+		targetTheme:       "- Enfoque del perfil: %s (%s)\n",
+		targetThemeNote:   "- Las experiencias y competencias anteriores están ordenadas por relevancia para este enfoque\n",
+		postingLabel:      "- Oferta de empleo:\n",
+		missionsLabel:     "Funciones",
+		requirementsLabel: "Requisitos",
+		technologiesLine:  "  Tecnologías mencionadas: %s\n",
+
+		motivationJobFocus:     " El cuerpo de la carta debe explicar por qué el candidato es idóneo para el puesto de %s.",
+		motivationPostingFocus: " Responde a las principales funciones y requisitos de la oferta apoyándote en experiencias concretas del candidato, sin atribuirle competencias que no aparezcan en su trayectoria.",
+		antiJobFocus:           " Ridiculiza en particular el puesto de %s.",
+		antiPostingFocus:       " Dale la vuelta con humor a los requisitos de la oferta.",
+
+		qualityRetry: "IMPORTANTE: una versión anterior de esta carta fue rechazada por el control de calidad. Corrige obligatoriamente los siguientes puntos:",
+
+		noExperiences: "No hay experiencia detallada disponible.",
+		present:       "actualidad",
+		summary:       "%s con %d años de experiencia, especializado en %s",
+
+		typeNames: map[models.LetterType]string{
+			models.LetterTypeMotivation:     "cartas de presentación profesionales",
+			models.LetterTypeAntiMotivation: "cartas de antimotivación humorísticas",
+			models.LetterTypeFollowUpEmail:  "correos de seguimiento de candidatura",
+			models.LetterTypeThankYouNote:   "mensajes de agradecimiento tras una entrevista",
+			models.LetterTypeLinkedIn:       "mensajes de contacto en LinkedIn",
+			models.LetterTypeElevatorPitch:  "elevator pitches orales",
+		},
+
+		motivation:      template.Must(template.New("motivation_es").Parse(motivationPromptES)),
+		antiMotivation:  template.Must(template.New("anti_motivation_es").Parse(antiMotivationPromptES)),
+		followUpEmail:   template.Must(template.New("follow_up_email_es").Parse(followUpEmailPromptES)),
+		thankYouNote:    template.Must(template.New("thank_you_note_es").Parse(thankYouNotePromptES)),
+		linkedInMessage: template.Must(template.New("linkedin_message_es").Parse(linkedInMessagePromptES)),
+		elevatorPitch:   template.Must(template.New("elevator_pitch_es").Parse(elevatorPitchPromptES)),
+		revision:        template.Must(template.New("revision_es").Parse(revisionPromptES)),
+
+		pdf: letterPDFLabels{
+			MotivationTitle:     "Carta de Presentación",
+			AntiMotivationTitle: "Carta de Antimotivación",
+			Attention:           "A la atención de %s",
+			Footer:              "Generada automáticamente por maicivy - CV Inteligente",
+			AntiWarning:         "Humor e ironía - ¡No tomar en serio!",
+			AntiFooter:          "Generada por la IA con mucho humor 🤖",
+			AntiFooterTagline:   "maicivy - Porque reírse de uno mismo es un arte",
+			Titles: map[models.LetterType]string{
+				models.LetterTypeFollowUpEmail: "Correo de seguimiento",
+				models.LetterTypeThankYouNote:  "Mensaje de agradecimiento",
+				models.LetterTypeLinkedIn:      "Mensaje de LinkedIn",
+				models.LetterTypeElevatorPitch: "Elevator pitch",
+			},
+		},
+	},
+}
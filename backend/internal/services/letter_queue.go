@@ -3,13 +3,95 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"maicivy/internal/models"
+)
+
+// Clés Redis de la queue
+// Un job réclamé passe atomiquement (BLMOVE) de la file d'attente à la liste "processing"
+// du worker ; il n'en sort qu'une fois traité (AckJob). Un worker qui cesse d'émettre
+// ses heartbeats voit ses jobs remis en file par ReapStalledJobs.
+const (
+	letterQueueKey       = "queue:letters" // File normale (clé historique)
+	letterHighQueueKey   = "queue:letters:high"
+	letterBatchQueueKey  = "queue:letters:batch" // Campagnes (réclamées par les slots dédiés)
+	letterDeadKey        = "queue:letters:dead"
+	letterCancelChannel  = "queue:letters:cancel" // Pub/Sub : annulation des jobs en cours
+	letterWorkersKey     = "queue:letters:workers"
+	letterJobTTL         = 24 * time.Hour
+	letterClaimTimeout   = 1 * time.Second
+	letterJobRetention   = 30 * 24 * time.Hour // Historique Postgres des jobs terminés
+	defaultJobMaxRetries = 3
 )
 
+// DefaultJobVisibilityTimeout délai sans heartbeat au-delà duquel les jobs d'un worker sont repris
+const DefaultJobVisibilityTimeout = 90 * time.Second
+
+// ErrStalledJob message d'échec d'un job abandonné par ses workers successifs
+const ErrStalledJob = "worker stopped responding"
+
+// ErrJobNotFound job inconnu ou expiré
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobFinished le job est déjà terminé (complété, échoué ou annulé)
+var ErrJobFinished = errors.New("job already finished")
+
+// JobPriority file de priorité d'un job
+type JobPriority string
+
+const (
+	JobPriorityHigh   JobPriority = "high"   // Profils cibles détectés (recruteur, CTO...)
+	JobPriorityNormal JobPriority = "normal" // Défaut
+	JobPriorityBatch  JobPriority = "batch"  // Campagnes : file et budget de concurrence dédiés
+)
+
+// JobPriorities files des requêtes interactives dans l'ordre de consommation (ClaimJob)
+var JobPriorities = []JobPriority{JobPriorityHigh, JobPriorityNormal}
+
+// jobLanes toutes les files d'attente (interactives et campagnes)
+var jobLanes = []JobPriority{JobPriorityHigh, JobPriorityNormal, JobPriorityBatch}
+
+// JobPriorityForProfile file d'un job selon le profil détecté du visiteur
+func JobPriorityForProfile(profile models.ProfileType) JobPriority {
+	switch profile {
+	case models.ProfileTypeRecruiter, models.ProfileTypeCTO, models.ProfileTypeCEO, models.ProfileTypeTechLead:
+		return JobPriorityHigh
+	}
+	return JobPriorityNormal
+}
+
+// letterLaneKey clé Redis de la file d'une priorité
+func letterLaneKey(priority JobPriority) string {
+	switch priority {
+	case JobPriorityHigh:
+		return letterHighQueueKey
+	case JobPriorityBatch:
+		return letterBatchQueueKey
+	}
+	return letterQueueKey
+}
+
+func letterJobKey(jobID string) string {
+	return fmt.Sprintf("job:letter:%s", jobID)
+}
+
+func letterProcessingKey(workerID string) string {
+	return fmt.Sprintf("queue:letters:processing:%s", workerID)
+}
+
+func letterHeartbeatKey(workerID string) string {
+	return fmt.Sprintf("queue:letters:heartbeat:%s", workerID)
+}
+
 // JobStatus représente le status d'un job de génération
 type JobStatus string
 
@@ -18,21 +100,52 @@ const (
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelled  JobStatus = "cancelled"
+)
+
+// JobKind type de traitement d'un job de la queue
+type JobKind string
+
+const (
+	JobKindGeneration JobKind = "generation" // Lettres des types demandés (défaut)
+	JobKindRevision   JobKind = "revision"   // Révision d'une lettre existante
 )
 
 // LetterJob représente un job de génération de lettre
 type LetterJob struct {
-	JobID       string    `json:"job_id"`
-	VisitorID   string    `json:"visitor_id"`    // Session ID du visiteur
-	CompanyName string    `json:"company_name"`
-	JobTitle    string    `json:"job_title,omitempty"`
-	Theme       string    `json:"theme,omitempty"`
-	Status      JobStatus `json:"status"`
-	Progress    int       `json:"progress"` // 0-100
-
-	// Résultats (si completed)
-	LetterMotivationID     *uuid.UUID `json:"letter_motivation_id,omitempty"`
-	LetterAntiMotivationID *uuid.UUID `json:"letter_anti_motivation_id,omitempty"`
+	JobID       string  `json:"job_id"`
+	Kind        JobKind `json:"kind,omitempty"` // Vide = génération
+	VisitorID   string  `json:"visitor_id"`     // Session ID du visiteur
+	CompanyName string  `json:"company_name"`
+	// Domaine choisi par le visiteur pour cette entreprise (vide = domaine connu ou deviné)
+	CompanyDomain string      `json:"company_domain,omitempty"`
+	JobTitle      string      `json:"job_title,omitempty"`
+	Theme         string      `json:"theme,omitempty"`
+	Language      string      `json:"language,omitempty"` // Langue de rédaction (vide = fr)
+	Status        JobStatus   `json:"status"`
+	Progress      int         `json:"progress"`            // 0-100
+	Priority      JobPriority `json:"priority,omitempty"`  // Vide = normale
+	WorkerID      string      `json:"worker_id,omitempty"` // Worker ayant réclamé le job
+	BatchID       string      `json:"batch_id,omitempty"`  // Campagne d'origine
+
+	// Types de lettres à générer (vide = motivation + anti-motivation)
+	LetterTypes []models.LetterType `json:"letter_types,omitempty"`
+
+	// Offre d'emploi ciblée (URL à récupérer ou texte collé)
+	JobPostingURL  string     `json:"job_posting_url,omitempty"`
+	JobPostingText string     `json:"job_posting_text,omitempty"`
+	JobPostingID   *uuid.UUID `json:"job_posting_id,omitempty"` // Offre analysée et persistée
+
+	// Révision : lettre à réviser et retours du visiteur
+	LetterID *uuid.UUID `json:"letter_id,omitempty"`
+	Feedback string     `json:"feedback,omitempty"`
+
+	// Résultats (si completed) : lettre créée par type
+	// LetterMotivationID et LetterAntiMotivationID sont conservés pour les clients existants.
+	Letters                map[models.LetterType]uuid.UUID `json:"letters,omitempty"`
+	LetterMotivationID     *uuid.UUID                      `json:"letter_motivation_id,omitempty"`
+	LetterAntiMotivationID *uuid.UUID                      `json:"letter_anti_motivation_id,omitempty"`
+	LetterVersion          int                             `json:"letter_version,omitempty"` // Version créée par une révision
 
 	// Erreur (si failed)
 	Error *string `json:"error,omitempty"`
@@ -46,68 +159,160 @@ type LetterJob struct {
 	MaxRetries int `json:"max_retries"`
 }
 
+// LetterJobRequest paramètres d'un nouveau job de génération
+type LetterJobRequest struct {
+	VisitorID      string // Session ID du visiteur
+	CompanyName    string
+	CompanyDomain  string // Domaine choisi par le visiteur
+	JobTitle       string
+	Theme          string
+	Language       string
+	JobPostingURL  string
+	JobPostingText string
+	LetterTypes    []models.LetterType // Vide = DefaultLetterTypes
+	Priority       JobPriority         // Vide = normale
+	BatchID        string              // Campagne d'origine (file JobPriorityBatch)
+}
+
+// LetterRevisionRequest paramètres d'un nouveau job de révision
+type LetterRevisionRequest struct {
+	VisitorID string // Session ID du visiteur
+	LetterID  uuid.UUID
+	Feedback  string
+	Priority  JobPriority // Vide = normale
+}
+
+// IsRevision indique si le job révise une lettre existante
+func (job *LetterJob) IsRevision() bool {
+	return job.Kind == JobKindRevision
+}
+
+// RequestedLetterTypes retourne les types de lettres à générer
+// Les jobs créés avant l'ajout des types génèrent la paire motivation / anti-motivation.
+func (job *LetterJob) RequestedLetterTypes() []models.LetterType {
+	if len(job.LetterTypes) == 0 {
+		return DefaultLetterTypes
+	}
+	return job.LetterTypes
+}
+
+// IsTerminal indique si le job est terminé (complété, échoué ou annulé)
+func (job *LetterJob) IsTerminal() bool {
+	return job.Status == JobStatusCompleted || job.Status == JobStatusFailed || job.Status == JobStatusCancelled
+}
+
+// laneKey clé Redis de la file du job
+func (job *LetterJob) laneKey() string {
+	return letterLaneKey(job.Priority)
+}
+
 // LetterQueueService service de gestion de la queue de génération de lettres
 type LetterQueueService struct {
-	redis *redis.Client
-	ctx   context.Context
+	redis      *redis.Client
+	db         *gorm.DB // Copie persistante des jobs (optionnelle)
+	ctx        context.Context
+	visibility time.Duration
+	webhooks   WebhookPublisher // Événements du cycle de vie des jobs (optionnel)
 }
 
 // NewLetterQueueService crée une nouvelle instance du service
 func NewLetterQueueService(redis *redis.Client) *LetterQueueService {
 	return &LetterQueueService{
-		redis: redis,
-		ctx:   context.Background(),
+		redis:      redis,
+		ctx:        context.Background(),
+		visibility: DefaultJobVisibilityTimeout,
+	}
+}
+
+// SetDB active la copie des jobs dans Postgres (historique, reprise après perte de Redis)
+func (s *LetterQueueService) SetDB(db *gorm.DB) {
+	s.db = db
+}
+
+// SetWebhookPublisher active la diffusion des événements job.queued, job.completed et job.failed
+func (s *LetterQueueService) SetWebhookPublisher(publisher WebhookPublisher) {
+	s.webhooks = publisher
+}
+
+// SetVisibilityTimeout définit le délai sans heartbeat avant reprise des jobs d'un worker
+func (s *LetterQueueService) SetVisibilityTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.visibility = timeout
 	}
 }
 
+// VisibilityTimeout retourne le délai sans heartbeat avant reprise des jobs d'un worker
+func (s *LetterQueueService) VisibilityTimeout() time.Duration {
+	return s.visibility
+}
+
 // EnqueueJob ajoute un job dans la queue
-func (s *LetterQueueService) EnqueueJob(visitorID, companyName string, jobTitle, theme string) (string, error) {
+func (s *LetterQueueService) EnqueueJob(req LetterJobRequest) (string, error) {
+	return s.enqueue(&LetterJob{
+		Kind:           JobKindGeneration,
+		VisitorID:      req.VisitorID,
+		CompanyName:    req.CompanyName,
+		CompanyDomain:  req.CompanyDomain,
+		JobTitle:       req.JobTitle,
+		Theme:          req.Theme,
+		Language:       req.Language,
+		JobPostingURL:  req.JobPostingURL,
+		JobPostingText: req.JobPostingText,
+		LetterTypes:    req.LetterTypes,
+		Priority:       req.Priority,
+		BatchID:        req.BatchID,
+	})
+}
+
+// EnqueueRevision ajoute un job de révision d'une lettre dans la queue
+func (s *LetterQueueService) EnqueueRevision(req LetterRevisionRequest) (string, error) {
+	letterID := req.LetterID
+	return s.enqueue(&LetterJob{
+		Kind:      JobKindRevision,
+		VisitorID: req.VisitorID,
+		LetterID:  &letterID,
+		Feedback:  req.Feedback,
+		Priority:  req.Priority,
+	})
+}
+
+// enqueue initialise, stocke et met en queue un job
+func (s *LetterQueueService) enqueue(job *LetterJob) (string, error) {
 	jobID := uuid.New().String()
 
-	job := LetterJob{
-		JobID:       jobID,
-		VisitorID:   visitorID,
-		CompanyName: companyName,
-		JobTitle:    jobTitle,
-		Theme:       theme,
-		Status:      JobStatusQueued,
-		Progress:    0,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		RetryCount:  0,
-		MaxRetries:  3,
-	}
-
-	// Sérialiser le job
-	jobJSON, err := json.Marshal(job)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal job: %w", err)
-	}
+	job.JobID = jobID
+	job.Status = JobStatusQueued
+	job.Progress = 0
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+	job.RetryCount = 0
+	job.MaxRetries = defaultJobMaxRetries
 
-	// Stocker le job dans Redis (Hash)
-	jobKey := fmt.Sprintf("job:letter:%s", jobID)
-	err = s.redis.Set(s.ctx, jobKey, jobJSON, 24*time.Hour).Err() // TTL 24h
-	if err != nil {
+	// Stocker le job (Redis, TTL 24h, et copie Postgres)
+	if err := s.saveJob(job); err != nil {
 		return "", fmt.Errorf("failed to store job: %w", err)
 	}
 
-	// Ajouter à la queue (List FIFO)
-	queueKey := "queue:letters"
-	err = s.redis.RPush(s.ctx, queueKey, jobID).Err()
-	if err != nil {
+	// Ajouter à la file de sa priorité (List FIFO)
+	if err := s.redis.RPush(s.ctx, job.laneKey(), jobID).Err(); err != nil {
 		return "", fmt.Errorf("failed to enqueue job: %w", err)
 	}
 
+	s.publishJobEvent(WebhookEventJobQueued, job)
 	return jobID, nil
 }
 
 // GetJobStatus récupère le status d'un job
+// Un job absent de Redis (expiré, Redis redémarré) est relu depuis sa copie Postgres.
 func (s *LetterQueueService) GetJobStatus(jobID string) (*LetterJob, error) {
-	jobKey := fmt.Sprintf("job:letter:%s", jobID)
+	return s.readJob(s.redis, jobID)
+}
 
-	jobJSON, err := s.redis.Get(s.ctx, jobKey).Result()
+// readJob lit un job via c (client, ou transaction WATCH de updateJob)
+func (s *LetterQueueService) readJob(c redis.Cmdable, jobID string) (*LetterJob, error) {
+	jobJSON, err := c.Get(s.ctx, letterJobKey(jobID)).Result()
 	if err == redis.Nil {
-		return nil, fmt.Errorf("job not found")
+		return s.loadJobRecord(jobID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job: %w", err)
@@ -123,129 +328,653 @@ func (s *LetterQueueService) GetJobStatus(jobID string) (*LetterJob, error) {
 }
 
 // UpdateJobStatus met à jour le status d'un job
+// Un job terminé (annulé pendant le traitement notamment) n'est pas modifié.
 func (s *LetterQueueService) UpdateJobStatus(jobID string, status JobStatus, progress int) error {
-	job, err := s.GetJobStatus(jobID)
+	_, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = status
+		job.Progress = progress
+		return nil
+	}, nil)
+	if errors.Is(err, ErrJobFinished) {
+		return nil
+	}
+	return err
+}
+
+// CompleteJob marque un job comme complété avec la lettre créée pour chaque type
+// ErrJobFinished si le job est déjà terminé (annulé pendant la génération notamment).
+func (s *LetterQueueService) CompleteJob(jobID string, letters map[models.LetterType]uuid.UUID) error {
+	job, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = JobStatusCompleted
+		job.Progress = 100
+		job.Letters = letters
+		if id, ok := letters[models.LetterTypeMotivation]; ok {
+			job.LetterMotivationID = &id
+		}
+		if id, ok := letters[models.LetterTypeAntiMotivation]; ok {
+			job.LetterAntiMotivationID = &id
+		}
+		return nil
+	}, nil)
 	if err != nil {
 		return err
 	}
-
-	job.Status = status
-	job.Progress = progress
-	job.UpdatedAt = time.Now()
-
-	return s.saveJob(job)
+	s.publishJobEvent(WebhookEventJobCompleted, job)
+	return nil
 }
 
-// CompleteJob marque un job comme complété
-func (s *LetterQueueService) CompleteJob(jobID string, motivationID, antiMotivationID uuid.UUID) error {
-	job, err := s.GetJobStatus(jobID)
+// CompleteRevision marque un job de révision comme complété avec la version créée
+// ErrJobFinished si le job est déjà terminé.
+func (s *LetterQueueService) CompleteRevision(jobID string, version int) error {
+	job, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = JobStatusCompleted
+		job.Progress = 100
+		job.LetterVersion = version
+		return nil
+	}, nil)
 	if err != nil {
 		return err
 	}
+	s.publishJobEvent(WebhookEventJobCompleted, job)
+	return nil
+}
 
-	job.Status = JobStatusCompleted
-	job.Progress = 100
-	job.LetterMotivationID = &motivationID
-	job.LetterAntiMotivationID = &antiMotivationID
-	job.UpdatedAt = time.Now()
-
-	return s.saveJob(job)
+// AttachJobPosting associe l'offre analysée au job (évite de la récupérer à nouveau en cas de retry)
+func (s *LetterQueueService) AttachJobPosting(jobID string, postingID uuid.UUID) error {
+	_, err := s.updateJob(jobID, func(job *LetterJob) error {
+		job.JobPostingID = &postingID
+		return nil
+	}, nil)
+	return err
 }
 
 // FailJob marque un job comme échoué
+// ErrJobFinished si le job est déjà terminé : un job annulé le reste.
 func (s *LetterQueueService) FailJob(jobID string, errorMsg string) error {
+	job, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = JobStatusFailed
+		job.Error = &errorMsg
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	s.publishJobEvent(WebhookEventJobFailed, job)
+	return nil
+}
+
+// RetryJob incrémente le compteur de retry et re-enqueue si max pas atteint
+// Une copie encore en file (job failed jamais réclamé) est retirée dans la même transaction :
+// le job n'est jamais présent deux fois. ErrJobFinished si le job est complété ou annulé.
+func (s *LetterQueueService) RetryJob(jobID string) error {
+	_, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.Status == JobStatusCompleted || job.Status == JobStatusCancelled {
+			return ErrJobFinished
+		}
+		if job.RetryCount >= job.MaxRetries {
+			return fmt.Errorf("max retries reached")
+		}
+		job.RetryCount++
+		job.Status = JobStatusQueued
+		job.Progress = 0
+		job.WorkerID = ""
+		return nil
+	}, func(pipe redis.Pipeliner, job *LetterJob) {
+		pipe.LRem(s.ctx, job.laneKey(), 0, jobID)
+		pipe.RPush(s.ctx, job.laneKey(), jobID)
+	})
+	return err
+}
+
+// DeadLetterJob marque un job comme définitivement échoué et le place dans la dead-letter queue
+func (s *LetterQueueService) DeadLetterJob(jobID string, errorMsg string) error {
 	job, err := s.GetJobStatus(jobID)
 	if err != nil {
 		return err
 	}
+	return s.deadLetter(job, errorMsg)
+}
 
+// deadLetter retire le job de la file d'attente et le place dans la dead-letter queue
+func (s *LetterQueueService) deadLetter(job *LetterJob, errorMsg string) error {
 	job.Status = JobStatusFailed
 	job.Error = &errorMsg
+	job.WorkerID = ""
 	job.UpdatedAt = time.Now()
 
-	return s.saveJob(job)
+	if err := s.saveJob(job); err != nil {
+		return err
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.LRem(s.ctx, job.laneKey(), 0, job.JobID)
+	pipe.LRem(s.ctx, letterDeadKey, 0, job.JobID)
+	pipe.RPush(s.ctx, letterDeadKey, job.JobID)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+
+	s.mirrorDeadLetter(job.JobID)
+	s.publishJobEvent(WebhookEventJobFailed, job)
+	return nil
 }
 
-// RetryJob incrémente le compteur de retry et re-enqueue si max pas atteint
-func (s *LetterQueueService) RetryJob(jobID string) error {
-	job, err := s.GetJobStatus(jobID)
+// GetDeadLetterLength retourne le nombre de jobs dans la dead-letter queue
+func (s *LetterQueueService) GetDeadLetterLength() (int64, error) {
+	length, err := s.redis.LLen(s.ctx, letterDeadKey).Result()
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("failed to get dead-letter queue length: %w", err)
+	}
+	return length, nil
+}
+
+// Heartbeat signale qu'un worker est actif (à appeler plus souvent que VisibilityTimeout)
+func (s *LetterQueueService) Heartbeat(workerID string) error {
+	pipe := s.redis.TxPipeline()
+	pipe.SAdd(s.ctx, letterWorkersKey, workerID)
+	pipe.Set(s.ctx, letterHeartbeatKey(workerID), time.Now().Unix(), s.visibility)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
 	}
+	return nil
+}
 
-	if job.RetryCount >= job.MaxRetries {
-		return fmt.Errorf("max retries reached")
+// ClaimJob réclame le prochain job de la queue pour un worker
+// Le job est déplacé atomiquement dans la liste "processing" du worker et y reste jusqu'à AckJob.
+// Les files prioritaires sont consultées d'abord ; l'attente (1s) se fait sur la file normale,
+// un job prioritaire arrivé entre-temps attend donc au plus une seconde. Retourne "" si la queue est vide.
+func (s *LetterQueueService) ClaimJob(workerID string) (string, error) {
+	return s.claim(workerID, JobPriorities)
+}
+
+// ClaimBatchJob réclame le prochain job de campagne (slots dédiés des workers)
+// Les campagnes ne consomment ainsi pas la capacité réservée aux visiteurs.
+func (s *LetterQueueService) ClaimBatchJob(workerID string) (string, error) {
+	return s.claim(workerID, []JobPriority{JobPriorityBatch})
+}
+
+// claim réclame le premier job des files données (attente sur la dernière)
+func (s *LetterQueueService) claim(workerID string, priorities []JobPriority) (string, error) {
+	// Un worker qui réclame des jobs est vivant
+	if err := s.Heartbeat(workerID); err != nil {
+		return "", err
 	}
 
-	job.RetryCount++
-	job.Status = JobStatusQueued
-	job.Progress = 0
-	job.UpdatedAt = time.Now()
+	processingKey := letterProcessingKey(workerID)
+	jobID := ""
+	for _, priority := range priorities[:len(priorities)-1] {
+		id, err := s.redis.LMove(s.ctx, letterLaneKey(priority), processingKey, "LEFT", "RIGHT").Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to claim job: %w", err)
+		}
+		jobID = id
+		break
+	}
 
-	err = s.saveJob(job)
-	if err != nil {
-		return err
+	if jobID == "" {
+		lowest := letterLaneKey(priorities[len(priorities)-1])
+		id, err := s.redis.BLMove(s.ctx, lowest, processingKey, "LEFT", "RIGHT", letterClaimTimeout).Result()
+		if err == redis.Nil {
+			return "", nil // Queue vide (timeout)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to claim job: %w", err)
+		}
+		jobID = id
 	}
 
-	// Re-enqueue
-	queueKey := "queue:letters"
-	return s.redis.RPush(s.ctx, queueKey, jobID).Err()
+	_, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.WorkerID = workerID
+		return nil
+	}, nil)
+	if err != nil && !errors.Is(err, ErrJobFinished) && !errors.Is(err, ErrJobNotFound) {
+		log.Warn().Err(err).Str("job_id", jobID).Msg("Failed to record job worker")
+	}
+
+	return jobID, nil
 }
 
-// PopJob récupère le prochain job de la queue (pour worker)
-func (s *LetterQueueService) PopJob() (string, error) {
-	queueKey := "queue:letters"
+// AckJob retire un job traité (complété, échoué ou re-enqueué) de la liste "processing" du worker
+func (s *LetterQueueService) AckJob(workerID, jobID string) error {
+	if err := s.redis.LRem(s.ctx, letterProcessingKey(workerID), 1, jobID).Err(); err != nil {
+		return fmt.Errorf("failed to ack job: %w", err)
+	}
+	return nil
+}
 
-	// BLPOP avec timeout de 1 seconde (blocking)
-	result, err := s.redis.BLPop(s.ctx, 1*time.Second, queueKey).Result()
-	if err == redis.Nil {
-		return "", nil // Queue vide (timeout)
+// ReleaseJob rend un job inachevé à la queue (en tête, sans consommer de tentative)
+// Utilisé par un worker qui s'arrête avant d'avoir terminé le job ; un job terminé est seulement acquitté.
+func (s *LetterQueueService) ReleaseJob(workerID, jobID string) error {
+	_, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = JobStatusQueued
+		job.Progress = 0
+		job.WorkerID = ""
+		return nil
+	}, func(pipe redis.Pipeliner, job *LetterJob) {
+		pipe.LRem(s.ctx, letterProcessingKey(workerID), 1, jobID)
+		pipe.LPush(s.ctx, job.laneKey(), jobID)
+	})
+	if err == nil {
+		return nil
+	}
+	if _, readErr := s.GetJobStatus(jobID); readErr != nil || errors.Is(err, ErrJobFinished) {
+		return s.AckJob(workerID, jobID)
 	}
+	return fmt.Errorf("failed to release job: %w", err)
+}
+
+// ReapStalledJobs remet en file les jobs des workers sans heartbeat depuis VisibilityTimeout
+// Chaque reprise compte comme une tentative : au-delà de MaxRetries, le job part en dead-letter queue.
+// Retourne le nombre de jobs repris.
+func (s *LetterQueueService) ReapStalledJobs() (int, error) {
+	workers, err := s.redis.SMembers(s.ctx, letterWorkersKey).Result()
 	if err != nil {
-		return "", fmt.Errorf("failed to pop job: %w", err)
+		return 0, fmt.Errorf("failed to list workers: %w", err)
 	}
 
-	if len(result) < 2 {
-		return "", nil
+	reaped := 0
+	for _, workerID := range workers {
+		alive, err := s.redis.Exists(s.ctx, letterHeartbeatKey(workerID)).Result()
+		if err != nil {
+			return reaped, fmt.Errorf("failed to check worker heartbeat: %w", err)
+		}
+		if alive > 0 {
+			continue
+		}
+
+		// Déplacement atomique : un job n'est jamais hors d'une liste, même si le reaper s'arrête
+		for {
+			jobID, err := s.redis.LMove(s.ctx, letterProcessingKey(workerID), letterQueueKey, "RIGHT", "LEFT").Result()
+			if err == redis.Nil {
+				break
+			}
+			if err != nil {
+				return reaped, fmt.Errorf("failed to requeue stalled job: %w", err)
+			}
+
+			reaped++
+			s.requeueStalled(workerID, jobID)
+		}
+
+		if err := s.redis.SRem(s.ctx, letterWorkersKey, workerID).Err(); err != nil {
+			return reaped, fmt.Errorf("failed to unregister worker: %w", err)
+		}
 	}
 
-	return result[1], nil // result[0] est la clé, result[1] est la valeur
+	return reaped, nil
 }
 
-// GetQueueLength retourne le nombre de jobs en attente
+// requeueStalled met à jour un job repris à un worker (déjà replacé en tête de file)
+func (s *LetterQueueService) requeueStalled(workerID, jobID string) {
+	logger := log.With().Str("job_id", jobID).Str("worker_id", workerID).Logger()
+
+	job, err := s.GetJobStatus(jobID)
+	if err != nil || job.IsTerminal() {
+		// Job expiré, ou terminé avant que le worker ne s'arrête : rien à refaire
+		s.redis.LRem(s.ctx, letterQueueKey, 1, jobID)
+		return
+	}
+
+	// Le reaper replace les jobs dans la file normale : un job prioritaire rejoint la sienne
+	if lane := job.laneKey(); lane != letterQueueKey {
+		pipe := s.redis.TxPipeline()
+		pipe.LRem(s.ctx, letterQueueKey, 1, jobID)
+		pipe.LPush(s.ctx, lane, jobID)
+		if _, err := pipe.Exec(s.ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed to move stalled job to its priority lane")
+		}
+	}
+
+	if job.RetryCount >= job.MaxRetries {
+		logger.Warn().Int("retries", job.RetryCount).Msg("Stalled letter job exhausted its retries, moving to dead-letter queue")
+		if err := s.deadLetter(job, ErrStalledJob); err != nil {
+			logger.Error().Err(err).Msg("Failed to dead-letter stalled job")
+		}
+		return
+	}
+
+	job, err = s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.RetryCount++
+		job.Status = JobStatusQueued
+		job.Progress = 0
+		job.WorkerID = ""
+		return nil
+	}, nil)
+	if errors.Is(err, ErrJobFinished) {
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to update stalled job")
+		return
+	}
+
+	logger.Warn().Int("retry", job.RetryCount).Msg("Requeued letter job from unresponsive worker")
+}
+
+// GetQueueLength retourne le nombre de jobs en attente (toutes files, campagnes comprises)
 func (s *LetterQueueService) GetQueueLength() (int64, error) {
-	queueKey := "queue:letters"
-	length, err := s.redis.LLen(s.ctx, queueKey).Result()
+	var total int64
+	for _, priority := range jobLanes {
+		length, err := s.redis.LLen(s.ctx, letterLaneKey(priority)).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get queue length: %w", err)
+		}
+		total += length
+	}
+	return total, nil
+}
+
+// CancelJob annule un job en attente ou en cours
+// Un job en attente est retiré de sa file ; le worker d'un job en cours est notifié
+// (Pub/Sub) et annule ses appels en cours. ErrJobFinished si le job est déjà terminé.
+func (s *LetterQueueService) CancelJob(jobID string) (*LetterJob, error) {
+	errorMsg := "cancelled"
+	job, err := s.updateJob(jobID, func(job *LetterJob) error {
+		if job.IsTerminal() {
+			return ErrJobFinished
+		}
+		job.Status = JobStatusCancelled
+		job.Error = &errorMsg
+		return nil
+	}, func(pipe redis.Pipeliner, job *LetterJob) {
+		pipe.LRem(s.ctx, job.laneKey(), 0, jobID)
+	})
+	if errors.Is(err, ErrJobFinished) {
+		return job, err
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get queue length: %w", err)
+		return nil, err
 	}
-	return length, nil
+
+	if err := s.redis.Publish(s.ctx, letterCancelChannel, jobID).Err(); err != nil {
+		return nil, fmt.Errorf("failed to notify cancellation: %w", err)
+	}
+
+	return job, nil
+}
+
+// SubscribeCancellations diffuse les IDs des jobs annulés jusqu'à l'annulation de ctx
+func (s *LetterQueueService) SubscribeCancellations(ctx context.Context) <-chan string {
+	pubsub := s.redis.Subscribe(ctx, letterCancelChannel)
+	jobIDs := make(chan string)
+
+	go func() {
+		defer close(jobIDs)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case jobIDs <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return jobIDs
 }
 
-// CleanupOldJobs nettoie les jobs expirés (>24h)
+// CleanupOldJobs nettoie les jobs expirés
+// Retire des files les IDs dont le job a expiré dans Redis (TTL 24h) sans copie Postgres,
+// et supprime de Postgres les jobs terminés depuis plus de 30 jours.
 func (s *LetterQueueService) CleanupOldJobs() error {
-	// Cette fonction peut être appelée par un cronjob
-	// Les jobs ont déjà un TTL de 24h dans Redis, donc cleanup automatique
-	// Cette fonction est optionnelle pour cleanup manuel si nécessaire
+	for _, listKey := range []string{letterQueueKey, letterHighQueueKey, letterBatchQueueKey, letterDeadKey} {
+		jobIDs, err := s.redis.LRange(s.ctx, listKey, 0, -1).Result()
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", listKey, err)
+		}
+		for _, jobID := range jobIDs {
+			if _, err := s.GetJobStatus(jobID); err == nil {
+				continue
+			}
+			if err := s.redis.LRem(s.ctx, listKey, 0, jobID).Err(); err != nil {
+				return fmt.Errorf("failed to remove expired job: %w", err)
+			}
+		}
+	}
+
+	if s.db == nil {
+		return nil
+	}
+
+	result := s.db.
+		Where("status IN ? AND updated_at < ?", []JobStatus{JobStatusCompleted, JobStatusFailed, JobStatusCancelled}, time.Now().Add(-letterJobRetention)).
+		Delete(&models.LetterJobRecord{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete old job records: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Info().Int64("deleted", result.RowsAffected).Msg("Deleted old letter job records")
+	}
+
 	return nil
 }
 
-// saveJob sauvegarde un job dans Redis
+// maxJobUpdateAttempts transitions rejouées après une modification concurrente avant abandon
+const maxJobUpdateAttempts = 10
+
+// updateJob applique une transition à un job de façon atomique (WATCH/MULTI)
+// mutate reçoit la version courante et peut refuser la transition : le job lu est alors
+// retourné avec son erreur. queue ajoute des commandes à la même transaction (files).
+// Si le job change entre la lecture et l'écriture (annulation concurrente notamment),
+// la transition est rejouée sur la nouvelle version.
+func (s *LetterQueueService) updateJob(jobID string, mutate func(job *LetterJob) error, queue func(pipe redis.Pipeliner, job *LetterJob)) (*LetterJob, error) {
+	key := letterJobKey(jobID)
+
+	for attempt := 0; attempt < maxJobUpdateAttempts; attempt++ {
+		var job *LetterJob
+		var jobJSON []byte
+		err := s.redis.Watch(s.ctx, func(tx *redis.Tx) error {
+			var err error
+			if job, err = s.readJob(tx, jobID); err != nil {
+				return err
+			}
+			if err := mutate(job); err != nil {
+				return err
+			}
+			job.UpdatedAt = time.Now()
+			if jobJSON, err = json.Marshal(job); err != nil {
+				return fmt.Errorf("failed to marshal job: %w", err)
+			}
+
+			_, err = tx.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(s.ctx, key, jobJSON, letterJobTTL)
+				if queue != nil {
+					queue(pipe, job)
+				}
+				return nil
+			})
+			return err
+		}, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return job, err
+		}
+
+		s.mirrorJob(job, jobJSON)
+		return job, nil
+	}
+	return nil, fmt.Errorf("failed to update job %s: too many concurrent updates", jobID)
+}
+
+// saveJob sauvegarde un job dans Redis et, si configuré, sa copie Postgres
+// La copie est best-effort : Redis reste la source de vérité de la queue.
 func (s *LetterQueueService) saveJob(job *LetterJob) error {
 	jobJSON, err := json.Marshal(job)
 	if err != nil {
 		return fmt.Errorf("failed to marshal job: %w", err)
 	}
 
-	jobKey := fmt.Sprintf("job:letter:%s", job.JobID)
-	err = s.redis.Set(s.ctx, jobKey, jobJSON, 24*time.Hour).Err()
+	err = s.redis.Set(s.ctx, letterJobKey(job.JobID), jobJSON, letterJobTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to save job: %w", err)
 	}
 
+	s.mirrorJob(job, jobJSON)
 	return nil
 }
 
+// publishJobEvent diffuse un événement du cycle de vie d'un job (best-effort, comme la copie Postgres)
+func (s *LetterQueueService) publishJobEvent(event WebhookEvent, job *LetterJob) {
+	if s.webhooks == nil {
+		return
+	}
+	if err := s.webhooks.Publish(s.ctx, event, NewWebhookJobData(job)); err != nil {
+		log.Warn().Err(err).Str("job_id", job.JobID).Str("event", string(event)).Msg("Failed to publish letter job webhook")
+	}
+}
+
+// RestoreJobs remet en file les jobs non terminés présents en Postgres mais absents de Redis
+// (Redis redémarré sans persistance). À appeler au démarrage, avant les workers.
+func (s *LetterQueueService) RestoreJobs() (int, error) {
+	if s.db == nil {
+		return 0, nil
+	}
+
+	var records []models.LetterJobRecord
+	err := s.db.
+		Where("status IN ? AND updated_at > ?", []JobStatus{JobStatusQueued, JobStatusProcessing}, time.Now().Add(-letterJobTTL)).
+		Order("created_at ASC").
+		Find(&records).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list unfinished jobs: %w", err)
+	}
+
+	restored := 0
+	for _, record := range records {
+		exists, err := s.redis.Exists(s.ctx, letterJobKey(record.JobID)).Result()
+		if err != nil {
+			return restored, fmt.Errorf("failed to check job: %w", err)
+		}
+		if exists > 0 {
+			continue
+		}
+
+		var job LetterJob
+		if err := json.Unmarshal([]byte(record.Payload), &job); err != nil {
+			log.Warn().Err(err).Str("job_id", record.JobID).Msg("Skipping unreadable letter job record")
+			continue
+		}
+
+		job.Status = JobStatusQueued
+		job.Progress = 0
+		job.WorkerID = ""
+		job.UpdatedAt = time.Now()
+		if err := s.saveJob(&job); err != nil {
+			return restored, err
+		}
+		if err := s.redis.RPush(s.ctx, job.laneKey(), job.JobID).Err(); err != nil {
+			return restored, fmt.Errorf("failed to enqueue job: %w", err)
+		}
+		restored++
+	}
+
+	return restored, nil
+}
+
+// mirrorJob enregistre (upsert) la copie Postgres d'un job
+func (s *LetterQueueService) mirrorJob(job *LetterJob, payload []byte) {
+	if s.db == nil {
+		return
+	}
+
+	record := models.LetterJobRecord{
+		JobID:       job.JobID,
+		Kind:        string(job.Kind),
+		SessionID:   job.VisitorID,
+		CompanyName: job.CompanyName,
+		Status:      string(job.Status),
+		Progress:    job.Progress,
+		WorkerID:    job.WorkerID,
+		RetryCount:  job.RetryCount,
+		MaxRetries:  job.MaxRetries,
+		Payload:     string(payload),
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if record.Kind == "" {
+		record.Kind = string(JobKindGeneration)
+	}
+	if job.Error != nil {
+		record.Error = *job.Error
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "progress", "worker_id", "error", "retry_count", "payload", "updated_at"}),
+	}).Create(&record).Error
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.JobID).Msg("Failed to mirror letter job to database")
+	}
+}
+
+// mirrorDeadLetter marque la copie Postgres d'un job comme abandonnée
+func (s *LetterQueueService) mirrorDeadLetter(jobID string) {
+	if s.db == nil {
+		return
+	}
+	err := s.db.Model(&models.LetterJobRecord{}).Where("job_id = ?", jobID).Update("dead_letter", true).Error
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", jobID).Msg("Failed to mark letter job as dead-lettered")
+	}
+}
+
+// loadJobRecord relit un job depuis sa copie Postgres
+func (s *LetterQueueService) loadJobRecord(jobID string) (*LetterJob, error) {
+	if s.db == nil {
+		return nil, ErrJobNotFound
+	}
+
+	var record models.LetterJobRecord
+	if err := s.db.First(&record, "job_id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	var job LetterJob
+	if err := json.Unmarshal([]byte(record.Payload), &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return &job, nil
+}
+
 // EstimateRemainingTime estime le temps restant basé sur le progress
 func (job *LetterJob) EstimateRemainingTime() int {
 	// Temps total estimé: 30 secondes
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/models"
+)
+
+// JobListState catégorie de jobs listée par l'administration de la queue
+type JobListState string
+
+const (
+	JobListQueued     JobListState = "queued"     // En attente (files prioritaires d'abord)
+	JobListProcessing JobListState = "processing" // Réclamés par un worker
+	JobListFailed     JobListState = "failed"     // Échoués (dont dead-letter)
+	JobListDead       JobListState = "dead"       // Dead-letter queue
+)
+
+// JobListStates catégories listables
+var JobListStates = []JobListState{JobListQueued, JobListProcessing, JobListFailed, JobListDead}
+
+// ParseJobListState valide une catégorie de jobs
+func ParseJobListState(value string) (JobListState, error) {
+	for _, state := range JobListStates {
+		if JobListState(value) == state {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("unknown job state %q (available: queued, processing, failed, dead)", value)
+}
+
+// ErrJobActive le job est en attente ou en cours (pas de remise en file)
+var ErrJobActive = errors.New("job is queued or processing")
+
+// LetterQueueStats état de la queue
+type LetterQueueStats struct {
+	Queued     map[JobPriority]int64 `json:"queued"`     // Jobs en attente par priorité
+	Processing int64                 `json:"processing"` // Jobs réclamés par un worker
+	Dead       int64                 `json:"dead"`       // Jobs en dead-letter queue
+	Workers    []string              `json:"workers"`    // Workers enregistrés
+}
+
+// Stats retourne le nombre de jobs par file et les workers enregistrés
+func (s *LetterQueueService) Stats() (*LetterQueueStats, error) {
+	stats := &LetterQueueStats{Queued: make(map[JobPriority]int64, len(jobLanes))}
+
+	for _, priority := range jobLanes {
+		length, err := s.redis.LLen(s.ctx, letterLaneKey(priority)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue length: %w", err)
+		}
+		stats.Queued[priority] = length
+	}
+
+	workers, err := s.redis.SMembers(s.ctx, letterWorkersKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+	stats.Workers = workers
+	for _, workerID := range workers {
+		length, err := s.redis.LLen(s.ctx, letterProcessingKey(workerID)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get processing length: %w", err)
+		}
+		stats.Processing += length
+	}
+
+	if stats.Dead, err = s.GetDeadLetterLength(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListJobs liste les jobs d'une catégorie (au plus limit)
+// Les jobs échoués sont lus depuis Postgres si la copie est active, depuis Redis (24h) sinon.
+func (s *LetterQueueService) ListJobs(state JobListState, limit int) ([]LetterJob, error) {
+	var jobIDs []string
+
+	switch state {
+	case JobListQueued:
+		for _, priority := range jobLanes {
+			ids, err := s.redis.LRange(s.ctx, letterLaneKey(priority), 0, -1).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list queued jobs: %w", err)
+			}
+			jobIDs = append(jobIDs, ids...)
+		}
+
+	case JobListProcessing:
+		workers, err := s.redis.SMembers(s.ctx, letterWorkersKey).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workers: %w", err)
+		}
+		for _, workerID := range workers {
+			ids, err := s.redis.LRange(s.ctx, letterProcessingKey(workerID), 0, -1).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list processing jobs: %w", err)
+			}
+			jobIDs = append(jobIDs, ids...)
+		}
+
+	case JobListDead:
+		ids, err := s.redis.LRange(s.ctx, letterDeadKey, 0, -1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+		}
+		jobIDs = ids
+
+	case JobListFailed:
+		return s.listFailedJobs(limit)
+
+	default:
+		return nil, fmt.Errorf("unknown job state %q", state)
+	}
+
+	return s.loadJobs(jobIDs, limit), nil
+}
+
+// loadJobs charge les jobs des IDs donnés (jobs expirés ignorés)
+func (s *LetterQueueService) loadJobs(jobIDs []string, limit int) []LetterJob {
+	jobs := make([]LetterJob, 0, min(len(jobIDs), limit))
+	for _, jobID := range jobIDs {
+		if len(jobs) >= limit {
+			break
+		}
+		job, err := s.GetJobStatus(jobID)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// listFailedJobs jobs échoués, du plus récent au plus ancien
+func (s *LetterQueueService) listFailedJobs(limit int) ([]LetterJob, error) {
+	jobs := make([]LetterJob, 0)
+
+	if s.db != nil {
+		var records []models.LetterJobRecord
+		err := s.db.Where("status = ?", JobStatusFailed).Order("updated_at DESC").Limit(limit).Find(&records).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list failed jobs: %w", err)
+		}
+		for _, record := range records {
+			if job, err := s.GetJobStatus(record.JobID); err == nil {
+				jobs = append(jobs, *job)
+			}
+		}
+		return jobs, nil
+	}
+
+	iter := s.redis.Scan(s.ctx, 0, letterJobKey("*"), 100).Iterator()
+	for iter.Next(s.ctx) && len(jobs) < limit {
+		var job LetterJob
+		jobJSON, err := s.redis.Get(s.ctx, iter.Val()).Bytes()
+		if err != nil || json.Unmarshal(jobJSON, &job) != nil {
+			continue
+		}
+		if job.Status == JobStatusFailed {
+			jobs = append(jobs, job)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list failed jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RequeueJob remet en file un job échoué, dead-letter ou annulé avec un compteur de tentatives remis à zéro
+func (s *LetterQueueService) RequeueJob(jobID string) (*LetterJob, error) {
+	job, err := s.GetJobStatus(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == JobStatusQueued || job.Status == JobStatusProcessing {
+		return job, ErrJobActive
+	}
+	if job.Status == JobStatusCompleted {
+		return job, ErrJobFinished
+	}
+
+	job.Status = JobStatusQueued
+	job.Progress = 0
+	job.RetryCount = 0
+	job.Error = nil
+	job.WorkerID = ""
+	job.UpdatedAt = time.Now()
+	if err := s.saveJob(job); err != nil {
+		return nil, err
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.LRem(s.ctx, letterDeadKey, 0, jobID)
+	pipe.RPush(s.ctx, job.laneKey(), jobID)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	if s.db != nil {
+		s.db.Model(&models.LetterJobRecord{}).Where("job_id = ?", jobID).Update("dead_letter", false)
+	}
+
+	return job, nil
+}
+
+// PurgeJob supprime un job de toutes les files, de Redis et de Postgres
+// Un job en cours est annulé auprès de son worker.
+func (s *LetterQueueService) PurgeJob(jobID string) error {
+	job, err := s.GetJobStatus(jobID)
+	if err != nil {
+		return err
+	}
+
+	workers, err := s.redis.SMembers(s.ctx, letterWorkersKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list workers: %w", err)
+	}
+
+	pipe := s.redis.TxPipeline()
+	for _, priority := range jobLanes {
+		pipe.LRem(s.ctx, letterLaneKey(priority), 0, jobID)
+	}
+	for _, workerID := range workers {
+		pipe.LRem(s.ctx, letterProcessingKey(workerID), 0, jobID)
+	}
+	pipe.LRem(s.ctx, letterDeadKey, 0, jobID)
+	pipe.Del(s.ctx, letterJobKey(jobID))
+	if job.Status == JobStatusProcessing {
+		pipe.Publish(s.ctx, letterCancelChannel, jobID)
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to purge job: %w", err)
+	}
+
+	if s.db != nil {
+		if err := s.db.Where("job_id = ?", jobID).Delete(&models.LetterJobRecord{}).Error; err != nil {
+			return fmt.Errorf("failed to delete job record: %w", err)
+		}
+	}
+	return nil
+}
+
+// PurgeDeadJobs supprime tous les jobs de la dead-letter queue
+func (s *LetterQueueService) PurgeDeadJobs() (int, error) {
+	purged := 0
+	for {
+		jobID, err := s.redis.LIndex(s.ctx, letterDeadKey, 0).Result()
+		if err == redis.Nil {
+			break
+		}
+		if err != nil {
+			return purged, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+
+		if err := s.PurgeJob(jobID); err != nil {
+			// Job expiré : seul son ID reste à retirer
+			if err := s.redis.LRem(s.ctx, letterDeadKey, 0, jobID).Err(); err != nil {
+				return purged, fmt.Errorf("failed to purge job: %w", err)
+			}
+			log.Warn().Err(err).Str("job_id", jobID).Msg("Removed expired job from dead-letter queue")
+		}
+		purged++
+	}
+	return purged, nil
+}
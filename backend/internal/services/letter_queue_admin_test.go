@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func newTestLetterQueue(t *testing.T) (*LetterQueueService, *redis.Client) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewLetterQueueService(redisClient), redisClient
+}
+
+func TestJobPriorityForProfile(t *testing.T) {
+	for _, profile := range []models.ProfileType{models.ProfileTypeRecruiter, models.ProfileTypeCTO, models.ProfileTypeCEO, models.ProfileTypeTechLead} {
+		assert.Equal(t, JobPriorityHigh, JobPriorityForProfile(profile), profile)
+	}
+	for _, profile := range []models.ProfileType{"", models.ProfileTypeUnknown, models.ProfileTypeDeveloper, models.ProfileTypeOther} {
+		assert.Equal(t, JobPriorityNormal, JobPriorityForProfile(profile), profile)
+	}
+}
+
+func TestLetterQueueService_ClaimJobPriority(t *testing.T) {
+	service, _ := newTestLetterQueue(t)
+
+	anonymousID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	recruiterID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta", Priority: JobPriorityHigh})
+
+	length, _ := service.GetQueueLength()
+	assert.Equal(t, int64(2), length)
+
+	// Le recruteur passe devant le visiteur anonyme arrivé avant lui
+	claimedID, err := service.ClaimJob("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, recruiterID, claimedID)
+
+	claimedID, err = service.ClaimJob("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, anonymousID, claimedID)
+
+	// Reprise par le reaper : le job prioritaire retrouve sa file
+	service.SetVisibilityTimeout(time.Millisecond)
+	require.NoError(t, service.Heartbeat("worker-1"))
+	time.Sleep(5 * time.Millisecond)
+	service.redis.Del(context.Background(), letterHeartbeatKey("worker-1"))
+
+	reaped, err := service.ReapStalledJobs()
+	require.NoError(t, err)
+	assert.Equal(t, 2, reaped)
+
+	high, _ := service.redis.LRange(context.Background(), letterHighQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{recruiterID}, high)
+	normal, _ := service.redis.LRange(context.Background(), letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{anonymousID}, normal)
+}
+
+func TestLetterQueueService_CancelJob(t *testing.T) {
+	service, _ := newTestLetterQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancellations := service.SubscribeCancellations(ctx)
+	time.Sleep(20 * time.Millisecond) // Abonnement actif
+
+	queuedID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google", Priority: JobPriorityHigh})
+
+	job, err := service.CancelJob(queuedID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, job.Status)
+
+	length, _ := service.GetQueueLength()
+	assert.Zero(t, length, "job en attente retiré de sa file")
+
+	select {
+	case jobID := <-cancellations:
+		assert.Equal(t, queuedID, jobID)
+	case <-time.After(time.Second):
+		t.Fatal("cancellation not published")
+	}
+
+	// Un job annulé n'est plus mis à jour par le worker
+	require.NoError(t, service.UpdateJobStatus(queuedID, JobStatusProcessing, 40))
+	job, _ = service.GetJobStatus(queuedID)
+	assert.Equal(t, JobStatusCancelled, job.Status)
+	assert.Equal(t, StreamEventCancelled, job.TerminalStreamEvent().Type)
+
+	_, err = service.CancelJob(queuedID)
+	assert.ErrorIs(t, err, ErrJobFinished)
+
+	_, err = service.CancelJob("unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+// interleaveHook exécute fn juste avant la première écriture (SET) passant par le client
+type interleaveHook struct {
+	once sync.Once
+	fn   func()
+}
+
+func (h *interleaveHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *interleaveHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "set" {
+			h.once.Do(h.fn)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *interleaveHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if cmd.Name() == "set" {
+				h.once.Do(h.fn)
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestLetterQueueService_CancelDuringWorkerUpdate(t *testing.T) {
+	mr := miniredis.RunT(t)
+	api := NewLetterQueueService(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	workerClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	worker := NewLetterQueueService(workerClient)
+
+	jobID, err := api.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	require.NoError(t, err)
+	claimed, err := worker.ClaimJob("worker-1")
+	require.NoError(t, err)
+	require.Equal(t, jobID, claimed)
+
+	// Annulation entre la lecture du job par le worker et son écriture
+	hook := &interleaveHook{fn: func() {
+		_, err := api.CancelJob(jobID)
+		require.NoError(t, err)
+	}}
+	workerClient.AddHook(hook)
+
+	require.NoError(t, worker.UpdateJobStatus(jobID, JobStatusProcessing, 40))
+	job, err := api.GetJobStatus(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, job.Status, "progress update must not resurrect a cancelled job")
+	assert.Equal(t, 0, job.Progress)
+
+	// Même course sur la fin du job : l'annulation l'emporte
+	jobID, err = api.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+	require.NoError(t, err)
+	hook.once = sync.Once{}
+	hook.fn = func() {
+		_, err := api.CancelJob(jobID)
+		require.NoError(t, err)
+	}
+
+	err = worker.CompleteJob(jobID, map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: uuid.New()})
+	assert.ErrorIs(t, err, ErrJobFinished)
+	job, err = api.GetJobStatus(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, job.Status)
+	assert.Empty(t, job.Letters)
+}
+
+func TestLetterQueueService_ListJobs(t *testing.T) {
+	service, _ := newTestLetterQueue(t)
+
+	queuedID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	processingID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta", Priority: JobPriorityHigh})
+	_, _ = service.ClaimJob("worker-1")
+	deadID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-3", CompanyName: "Amazon"})
+	require.NoError(t, service.DeadLetterJob(deadID, "AI provider unavailable"))
+	failedID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-4", CompanyName: "Apple"})
+	require.NoError(t, service.FailJob(failedID, "revision limit reached"))
+
+	ids := func(state JobListState) []string {
+		jobs, err := service.ListJobs(state, 10)
+		require.NoError(t, err)
+		var jobIDs []string
+		for _, job := range jobs {
+			jobIDs = append(jobIDs, job.JobID)
+		}
+		return jobIDs
+	}
+
+	assert.Equal(t, []string{queuedID, failedID}, ids(JobListQueued), "FailJob ne retire pas le job de sa file")
+	assert.Equal(t, []string{processingID}, ids(JobListProcessing))
+	assert.Equal(t, []string{deadID}, ids(JobListDead))
+	assert.ElementsMatch(t, []string{deadID, failedID}, ids(JobListFailed))
+
+	jobs, _ := service.ListJobs(JobListQueued, 1)
+	assert.Len(t, jobs, 1)
+
+	stats, err := service.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Queued[JobPriorityNormal])
+	assert.Equal(t, int64(0), stats.Queued[JobPriorityHigh])
+	assert.Equal(t, int64(1), stats.Processing)
+	assert.Equal(t, int64(1), stats.Dead)
+	assert.Equal(t, []string{"worker-1"}, stats.Workers)
+
+	_, err = ParseJobListState("stuck")
+	assert.Error(t, err)
+}
+
+func TestLetterQueueService_RequeueAndPurge(t *testing.T) {
+	service, redisClient := newTestLetterQueue(t)
+	ctx := context.Background()
+
+	deadID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	require.NoError(t, service.DeadLetterJob(deadID, "AI provider unavailable"))
+
+	job, err := service.RequeueJob(deadID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusQueued, job.Status)
+	assert.Zero(t, job.RetryCount)
+	assert.Nil(t, job.Error)
+
+	dead, _ := service.GetDeadLetterLength()
+	assert.Zero(t, dead)
+	queue, _ := redisClient.LRange(ctx, letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{deadID}, queue)
+
+	_, err = service.RequeueJob(deadID)
+	assert.ErrorIs(t, err, ErrJobActive)
+
+	// Purge d'un job en cours : retiré de la liste du worker et supprimé
+	claimedID, _ := service.ClaimJob("worker-1")
+	require.Equal(t, deadID, claimedID)
+	require.NoError(t, service.UpdateJobStatus(deadID, JobStatusProcessing, 20))
+	require.NoError(t, service.PurgeJob(deadID))
+
+	processing, _ := redisClient.LLen(ctx, letterProcessingKey("worker-1")).Result()
+	assert.Zero(t, processing)
+	_, err = service.GetJobStatus(deadID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	// Vider la dead-letter queue
+	for i := 0; i < 3; i++ {
+		jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+		require.NoError(t, service.DeadLetterJob(jobID, "boom"))
+	}
+	purged, err := service.PurgeDeadJobs()
+	require.NoError(t, err)
+	assert.Equal(t, 3, purged)
+	dead, _ = service.GetDeadLetterLength()
+	assert.Zero(t, dead)
+}
@@ -1,16 +1,24 @@
 package services
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"maicivy/internal/models"
+)
 
 // LetterQueueServiceInterface defines the interface for letter queue operations
 type LetterQueueServiceInterface interface {
-	EnqueueJob(visitorID, companyName string, jobTitle, theme string) (string, error)
+	EnqueueJob(req LetterJobRequest) (string, error)
+	EnqueueRevision(req LetterRevisionRequest) (string, error)
 	GetJobStatus(jobID string) (*LetterJob, error)
 	UpdateJobStatus(jobID string, status JobStatus, progress int) error
-	CompleteJob(jobID string, motivationID, antiMotivationID uuid.UUID) error
+	CompleteJob(jobID string, letters map[models.LetterType]uuid.UUID) error
 	FailJob(jobID string, errorMsg string) error
 	RetryJob(jobID string) error
-	PopJob() (string, error)
+	DeadLetterJob(jobID string, errorMsg string) error
+	ClaimJob(workerID string) (string, error)
+	AckJob(workerID, jobID string) error
+	CancelJob(jobID string) (*LetterJob, error)
 	GetQueueLength() (int64, error)
 	CleanupOldJobs() error
 }
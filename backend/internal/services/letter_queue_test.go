@@ -1,12 +1,19 @@
 package services
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
 )
 
 func TestLetterQueueService_EnqueueJob(t *testing.T) {
@@ -19,7 +26,7 @@ func TestLetterQueueService_EnqueueJob(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, err := service.EnqueueJob("visitor-123", "Google", "Software Engineer", "backend")
+	jobID, err := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google", JobTitle: "Software Engineer", Theme: "backend"})
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, jobID)
@@ -39,7 +46,7 @@ func TestLetterQueueService_GetJobStatus(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	job, err := service.GetJobStatus(jobID)
 
@@ -61,7 +68,7 @@ func TestLetterQueueService_UpdateJobStatus(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	err := service.UpdateJobStatus(jobID, JobStatusProcessing, 50)
 	assert.NoError(t, err)
@@ -81,11 +88,14 @@ func TestLetterQueueService_CompleteJob(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	uuid1 := uuid.New()
 	uuid2 := uuid.New()
-	err := service.CompleteJob(jobID, uuid1, uuid2)
+	err := service.CompleteJob(jobID, map[models.LetterType]uuid.UUID{
+		models.LetterTypeMotivation:     uuid1,
+		models.LetterTypeAntiMotivation: uuid2,
+	})
 	assert.NoError(t, err)
 
 	job, _ := service.GetJobStatus(jobID)
@@ -95,6 +105,77 @@ func TestLetterQueueService_CompleteJob(t *testing.T) {
 	assert.NotNil(t, job.LetterAntiMotivationID)
 	assert.Equal(t, uuid1, *job.LetterMotivationID)
 	assert.Equal(t, uuid2, *job.LetterAntiMotivationID)
+	assert.Len(t, job.Letters, 2)
+}
+
+func TestLetterQueueService_LetterTypes(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+
+	// Sans type précisé : paire motivation / anti-motivation
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
+	job, _ := service.GetJobStatus(jobID)
+	assert.Equal(t, DefaultLetterTypes, job.RequestedLetterTypes())
+
+	types := []models.LetterType{models.LetterTypeFollowUpEmail, models.LetterTypeElevatorPitch}
+	jobID, _ = service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google", LetterTypes: types})
+	job, _ = service.GetJobStatus(jobID)
+	assert.Equal(t, types, job.RequestedLetterTypes())
+
+	emailID, pitchID := uuid.New(), uuid.New()
+	err := service.CompleteJob(jobID, map[models.LetterType]uuid.UUID{
+		models.LetterTypeFollowUpEmail: emailID,
+		models.LetterTypeElevatorPitch: pitchID,
+	})
+	assert.NoError(t, err)
+
+	job, _ = service.GetJobStatus(jobID)
+	assert.Equal(t, emailID, job.Letters[models.LetterTypeFollowUpEmail])
+	assert.Equal(t, pitchID, job.Letters[models.LetterTypeElevatorPitch])
+	assert.Nil(t, job.LetterMotivationID)
+
+	event := job.TerminalStreamEvent()
+	assert.Equal(t, pitchID.String(), event.Letters[models.LetterTypeElevatorPitch])
+}
+
+func TestLetterQueueService_EnqueueRevision(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+
+	letterID := uuid.New()
+	jobID, err := service.EnqueueRevision(LetterRevisionRequest{VisitorID: "visitor-123", LetterID: letterID, Feedback: "Plus concis"})
+	assert.NoError(t, err)
+
+	job, _ := service.GetJobStatus(jobID)
+	assert.True(t, job.IsRevision())
+	assert.Equal(t, letterID, *job.LetterID)
+	assert.Equal(t, "Plus concis", job.Feedback)
+	assert.Equal(t, JobStatusQueued, job.Status)
+
+	// Même queue que les générations
+	queueLength, _ := service.GetQueueLength()
+	assert.Equal(t, int64(1), queueLength)
+
+	err = service.CompleteRevision(jobID, 2)
+	assert.NoError(t, err)
+
+	job, _ = service.GetJobStatus(jobID)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+	assert.Equal(t, 100, job.Progress)
+	assert.Equal(t, 2, job.LetterVersion)
+	assert.Nil(t, job.LetterMotivationID)
 }
 
 func TestLetterQueueService_FailJob(t *testing.T) {
@@ -107,7 +188,7 @@ func TestLetterQueueService_FailJob(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	errMsg := "API timeout"
 	err := service.FailJob(jobID, errMsg)
@@ -119,7 +200,7 @@ func TestLetterQueueService_FailJob(t *testing.T) {
 	assert.Equal(t, errMsg, *job.Error)
 }
 
-func TestLetterQueueService_PopJob(t *testing.T) {
+func TestLetterQueueService_ClaimJob(t *testing.T) {
 	mr, _ := miniredis.Run()
 	defer mr.Close()
 
@@ -129,22 +210,258 @@ func TestLetterQueueService_PopJob(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID1, _ := service.EnqueueJob("visitor-123", "Google", "", "")
-	jobID2, _ := service.EnqueueJob("visitor-456", "Meta", "", "")
+	jobID1, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
+	jobID2, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-456", CompanyName: "Meta"})
 
-	// Pop first job (FIFO)
-	poppedID, err := service.PopJob()
+	// Claim first job (FIFO)
+	claimedID, err := service.ClaimJob("worker-1")
 	assert.NoError(t, err)
-	assert.Equal(t, jobID1, poppedID)
+	assert.Equal(t, jobID1, claimedID)
 
-	// Pop second job
-	poppedID, err = service.PopJob()
+	// Claim second job
+	claimedID, err = service.ClaimJob("worker-1")
 	assert.NoError(t, err)
-	assert.Equal(t, jobID2, poppedID)
+	assert.Equal(t, jobID2, claimedID)
 
-	// Queue should be empty now
+	// Queue should be empty now, jobs are held by the worker until acked
 	queueLength, _ := service.GetQueueLength()
 	assert.Equal(t, int64(0), queueLength)
+
+	processing, _ := redisClient.LRange(context.Background(), letterProcessingKey("worker-1"), 0, -1).Result()
+	assert.Equal(t, []string{jobID1, jobID2}, processing)
+
+	job, _ := service.GetJobStatus(jobID1)
+	assert.Equal(t, "worker-1", job.WorkerID)
+
+	assert.NoError(t, service.AckJob("worker-1", jobID1))
+	processing, _ = redisClient.LRange(context.Background(), letterProcessingKey("worker-1"), 0, -1).Result()
+	assert.Equal(t, []string{jobID2}, processing)
+
+	// Queue vide : pas de job
+	claimedID, err = service.ClaimJob("worker-1")
+	assert.NoError(t, err)
+	assert.Empty(t, claimedID)
+}
+
+func TestLetterQueueService_ReleaseJob(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	nextID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+
+	_, _ = service.ClaimJob("worker-1")
+	service.UpdateJobStatus(jobID, JobStatusProcessing, 60)
+
+	// Arrêt du worker : le job repart en tête, sans consommer de tentative
+	assert.NoError(t, service.ReleaseJob("worker-1", jobID))
+
+	queue, _ := redisClient.LRange(context.Background(), letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{jobID, nextID}, queue)
+	processing, _ := redisClient.LLen(context.Background(), letterProcessingKey("worker-1")).Result()
+	assert.Zero(t, processing)
+
+	job, _ := service.GetJobStatus(jobID)
+	assert.Equal(t, JobStatusQueued, job.Status)
+	assert.Equal(t, 0, job.Progress)
+	assert.Equal(t, 0, job.RetryCount)
+}
+
+func TestLetterQueueService_ReapStalledJobs(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	service.SetVisibilityTimeout(30 * time.Second)
+
+	stalledID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	aliveID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+
+	_, _ = service.ClaimJob("worker-dead")
+	service.UpdateJobStatus(stalledID, JobStatusProcessing, 40)
+
+	// worker-dead cesse d'émettre ses heartbeats, worker-alive continue
+	mr.FastForward(20 * time.Second)
+	_, _ = service.ClaimJob("worker-alive")
+	mr.FastForward(20 * time.Second)
+
+	reaped, err := service.ReapStalledJobs()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reaped)
+
+	queue, _ := redisClient.LRange(context.Background(), letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{stalledID}, queue)
+
+	job, _ := service.GetJobStatus(stalledID)
+	assert.Equal(t, JobStatusQueued, job.Status)
+	assert.Equal(t, 0, job.Progress)
+	assert.Equal(t, 1, job.RetryCount)
+	assert.Empty(t, job.WorkerID)
+
+	// Le job du worker actif n'est pas repris
+	processing, _ := redisClient.LRange(context.Background(), letterProcessingKey("worker-alive"), 0, -1).Result()
+	assert.Equal(t, []string{aliveID}, processing)
+
+	workers, _ := redisClient.SMembers(context.Background(), letterWorkersKey).Result()
+	assert.Equal(t, []string{"worker-alive"}, workers)
+}
+
+func TestLetterQueueService_ReapStalledJobs_DeadLetter(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+
+	job, _ := service.GetJobStatus(jobID)
+	job.RetryCount = job.MaxRetries
+	service.saveJob(job)
+
+	_, _ = service.ClaimJob("worker-dead")
+	mr.FastForward(service.VisibilityTimeout() + time.Second)
+
+	reaped, err := service.ReapStalledJobs()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reaped)
+
+	queueLength, _ := service.GetQueueLength()
+	assert.Equal(t, int64(0), queueLength)
+	deadLength, _ := service.GetDeadLetterLength()
+	assert.Equal(t, int64(1), deadLength)
+
+	job, _ = service.GetJobStatus(jobID)
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Equal(t, ErrStalledJob, *job.Error)
+}
+
+func TestLetterQueueService_ReapStalledJobs_SkipsFinishedJobs(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+
+	// Worker arrêté entre la fin du job et son ack
+	_, _ = service.ClaimJob("worker-dead")
+	service.CompleteJob(jobID, map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: uuid.New()})
+	mr.FastForward(service.VisibilityTimeout() + time.Second)
+
+	_, err := service.ReapStalledJobs()
+	assert.NoError(t, err)
+
+	queueLength, _ := service.GetQueueLength()
+	assert.Equal(t, int64(0), queueLength)
+	job, _ := service.GetJobStatus(jobID)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+}
+
+func TestLetterQueueService_DeadLetterJob(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+
+	assert.NoError(t, service.DeadLetterJob(jobID, "AI provider unavailable"))
+
+	dead, _ := redisClient.LRange(context.Background(), letterDeadKey, 0, -1).Result()
+	assert.Equal(t, []string{jobID}, dead)
+	queueLength, _ := service.GetQueueLength()
+	assert.Equal(t, int64(0), queueLength)
+
+	job, _ := service.GetJobStatus(jobID)
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Equal(t, "AI provider unavailable", *job.Error)
+}
+
+func TestLetterQueueService_CleanupOldJobs(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	service := NewLetterQueueService(redisClient)
+	expiredID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	mr.FastForward(25 * time.Hour)
+	liveID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+
+	assert.NoError(t, service.CleanupOldJobs())
+
+	queue, _ := redisClient.LRange(context.Background(), letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{liveID}, queue)
+	assert.NotContains(t, queue, expiredID)
+}
+
+func TestLetterQueueService_DatabaseMirror(t *testing.T) {
+	mr, _ := miniredis.Run()
+	defer mr.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.LetterJobRecord{}))
+
+	service := NewLetterQueueService(redisClient)
+	service.SetDB(db)
+
+	completedID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	letterID := uuid.New()
+	require.NoError(t, service.CompleteJob(completedID, map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: letterID}))
+	queuedID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+
+	var record models.LetterJobRecord
+	require.NoError(t, db.First(&record, "job_id = ?", completedID).Error)
+	assert.Equal(t, string(JobStatusCompleted), record.Status)
+	assert.Equal(t, "visitor-1", record.SessionID)
+
+	// Redis redémarré sans persistance : l'historique reste lisible, les jobs en attente sont remis en file
+	mr.FlushAll()
+
+	job, err := service.GetJobStatus(completedID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+	assert.Equal(t, letterID, *job.LetterMotivationID)
+
+	restored, err := service.RestoreJobs()
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	queue, _ := redisClient.LRange(context.Background(), letterQueueKey, 0, -1).Result()
+	assert.Equal(t, []string{queuedID}, queue)
+
+	// Jobs terminés depuis plus de 30 jours supprimés
+	db.Model(&models.LetterJobRecord{}).Where("job_id = ?", completedID).Update("updated_at", time.Now().Add(-31*24*time.Hour))
+	require.NoError(t, service.CleanupOldJobs())
+
+	var count int64
+	db.Model(&models.LetterJobRecord{}).Count(&count)
+	assert.Equal(t, int64(1), count)
 }
 
 func TestLetterQueueService_RetryJob(t *testing.T) {
@@ -157,7 +474,7 @@ func TestLetterQueueService_RetryJob(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	// Marquer comme failed
 	service.FailJob(jobID, "Temporary error")
@@ -185,7 +502,7 @@ func TestLetterQueueService_MaxRetriesReached(t *testing.T) {
 
 	service := NewLetterQueueService(redisClient)
 
-	jobID, _ := service.EnqueueJob("visitor-123", "Google", "", "")
+	jobID, _ := service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-123", CompanyName: "Google"})
 
 	job, _ := service.GetJobStatus(jobID)
 	job.RetryCount = 3 // Max retries
@@ -232,9 +549,9 @@ func TestLetterQueueService_GetQueueLength(t *testing.T) {
 	assert.Equal(t, int64(0), length)
 
 	// Enqueue 3 jobs
-	service.EnqueueJob("visitor-1", "Google", "", "")
-	service.EnqueueJob("visitor-2", "Meta", "", "")
-	service.EnqueueJob("visitor-3", "Amazon", "", "")
+	service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-1", CompanyName: "Google"})
+	service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-2", CompanyName: "Meta"})
+	service.EnqueueJob(LetterJobRequest{VisitorID: "visitor-3", CompanyName: "Amazon"})
 
 	length, err = service.GetQueueLength()
 	assert.NoError(t, err)
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+// MaxLetterVersions nombre maximum de versions d'une lettre (version initiale comprise)
+const MaxLetterVersions = 10
+
+var (
+	ErrRevisionLimitReached  = errors.New("revision limit reached")
+	ErrLetterVersionNotFound = errors.New("letter version not found")
+)
+
+// revisionPromptData : données injectées dans les templates de révision
+type revisionPromptData struct {
+	CompanyName string
+	Content     string
+	Feedback    string
+	Kind        string // Type de lettre dans la langue du prompt
+	Humorous    bool
+	Header      bool // La lettre comporte un en-tête complet
+}
+
+// LetterRevisionService révise les lettres générées et gère leur historique de versions
+// GeneratedLetter.Content reflète toujours la version courante : le PDF et les
+// endpoints existants servent donc la version choisie sans changement.
+type LetterRevisionService struct {
+	db        *gorm.DB
+	aiService *AIService
+}
+
+// NewLetterRevisionService crée une nouvelle instance du service
+func NewLetterRevisionService(db *gorm.DB, aiService *AIService) *LetterRevisionService {
+	return &LetterRevisionService{
+		db:        db,
+		aiService: aiService,
+	}
+}
+
+// BuildRevisionPrompt construit le prompt de révision d'une lettre dans sa langue
+func BuildRevisionPrompt(letter *models.GeneratedLetter, feedback string) string {
+	locale := localeFor(letter.Language)
+	spec, ok := LetterTypeSpecFor(letter.LetterType)
+	if !ok {
+		spec = letterTypeSpecs[models.LetterTypeMotivation]
+	}
+
+	return renderPrompt(locale.revision, revisionPromptData{
+		CompanyName: letter.CompanyName,
+		Content:     letter.Content,
+		Feedback:    feedback,
+		Kind:        locale.typeNames[spec.Type],
+		Humorous:    spec.Humorous,
+		Header:      spec.HasHeader(),
+	})
+}
+
+// CheckRevisionLimit vérifie qu'une nouvelle version peut encore être créée
+func (s *LetterRevisionService) CheckRevisionLimit(ctx context.Context, letter *models.GeneratedLetter) error {
+	latest, err := latestVersion(s.db.WithContext(ctx), letter.ID)
+	if err != nil {
+		return err
+	}
+	if latest >= MaxLetterVersions {
+		return ErrRevisionLimitReached
+	}
+	return nil
+}
+
+// Revise génère une nouvelle version de la lettre à partir des retours du visiteur
+// La nouvelle version devient la version courante. onDelta (optionnel) reçoit les fragments.
+func (s *LetterRevisionService) Revise(ctx context.Context, letterID uuid.UUID, feedback string, onDelta StreamHandler) (*models.LetterVersion, error) {
+	var letter models.GeneratedLetter
+	if err := s.db.WithContext(ctx).First(&letter, "id = ?", letterID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load letter: %w", err)
+	}
+
+	if err := s.CheckRevisionLimit(ctx, &letter); err != nil {
+		return nil, err
+	}
+	if s.aiService == nil {
+		return nil, fmt.Errorf("AI service unavailable")
+	}
+
+	log.Info().
+		Str("letter_id", letterID.String()).
+		Str("type", string(letter.LetterType)).
+		Str("language", string(letter.Language)).
+		Msg("Starting letter revision")
+
+	startTime := time.Now()
+	ctx = WithUsageLetterType(ctx, letter.LetterType)
+	content, metrics, err := s.aiService.GenerateTextStream(ctx, BuildRevisionPrompt(&letter, feedback), onDelta)
+	if err != nil {
+		return nil, fmt.Errorf("AI revision failed: %w", err)
+	}
+
+	version := models.LetterVersion{
+		LetterID:     letter.ID,
+		Content:      content,
+		Feedback:     feedback,
+		AIModel:      metrics.Provider,
+		TokensUsed:   metrics.TotalTokens,
+		GenerationMS: int(time.Since(startTime).Milliseconds()),
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureOriginalVersion(tx, &letter); err != nil {
+			return err
+		}
+
+		latest, err := latestVersion(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		if latest >= MaxLetterVersions {
+			return ErrRevisionLimitReached
+		}
+
+		version.Version = latest + 1
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to save letter version: %w", err)
+		}
+
+		return makeCurrent(tx, &letter, &version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("letter_id", letterID.String()).
+		Int("version", version.Version).
+		Int("tokens", metrics.TotalTokens).
+		Msg("Letter revised successfully")
+
+	return &version, nil
+}
+
+// ListVersions retourne les versions d'une lettre, de la plus ancienne à la plus récente
+func (s *LetterRevisionService) ListVersions(ctx context.Context, letter *models.GeneratedLetter) ([]models.LetterVersion, error) {
+	var versions []models.LetterVersion
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureOriginalVersion(tx, letter); err != nil {
+			return err
+		}
+		return tx.Where("letter_id = ?", letter.ID).Order("version ASC").Find(&versions).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list letter versions: %w", err)
+	}
+	return versions, nil
+}
+
+// GetVersion retourne une version d'une lettre
+func (s *LetterRevisionService) GetVersion(ctx context.Context, letter *models.GeneratedLetter, number int) (*models.LetterVersion, error) {
+	var version models.LetterVersion
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureOriginalVersion(tx, letter); err != nil {
+			return err
+		}
+		return tx.Where("letter_id = ? AND version = ?", letter.ID, number).First(&version).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLetterVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get letter version: %w", err)
+	}
+	return &version, nil
+}
+
+// DiffVersions compare deux versions d'une lettre
+func (s *LetterRevisionService) DiffVersions(ctx context.Context, letter *models.GeneratedLetter, from, to int) (*LetterDiff, error) {
+	fromVersion, err := s.GetVersion(ctx, letter, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.GetVersion(ctx, letter, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffLetters(fromVersion.Content, toVersion.Content)
+	diff.From = from
+	diff.To = to
+	return &diff, nil
+}
+
+// SelectVersion fait d'une version existante la version courante (servie par le PDF)
+func (s *LetterRevisionService) SelectVersion(ctx context.Context, letter *models.GeneratedLetter, number int) (*models.LetterVersion, error) {
+	version, err := s.GetVersion(ctx, letter, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := makeCurrent(s.db.WithContext(ctx), letter, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// ensureOriginalVersion crée la version 1 à partir du contenu initial de la lettre
+// Les lettres antérieures à l'historique n'ont pas encore de version.
+func ensureOriginalVersion(tx *gorm.DB, letter *models.GeneratedLetter) error {
+	var count int64
+	if err := tx.Model(&models.LetterVersion{}).Where("letter_id = ?", letter.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count letter versions: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	original := models.LetterVersion{
+		LetterID:     letter.ID,
+		Version:      1,
+		Content:      letter.Content,
+		AIModel:      letter.AIModel,
+		TokensUsed:   letter.TokensUsed,
+		GenerationMS: letter.GenerationMS,
+	}
+	if err := tx.Create(&original).Error; err != nil {
+		return fmt.Errorf("failed to save original letter version: %w", err)
+	}
+	return nil
+}
+
+// latestVersion retourne le numéro de la dernière version (0 si aucune)
+func latestVersion(tx *gorm.DB, letterID uuid.UUID) (int, error) {
+	var latest int
+	err := tx.Model(&models.LetterVersion{}).
+		Where("letter_id = ?", letterID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest letter version: %w", err)
+	}
+	return latest, nil
+}
+
+// makeCurrent recopie une version dans la lettre et la marque comme courante
+func makeCurrent(tx *gorm.DB, letter *models.GeneratedLetter, version *models.LetterVersion) error {
+	err := tx.Model(letter).Updates(map[string]interface{}{
+		"content":         version.Content,
+		"current_version": version.Version,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update current letter version: %w", err)
+	}
+	letter.Content = version.Content
+	letter.CurrentVersion = version.Version
+	return nil
+}
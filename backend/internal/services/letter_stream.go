@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"maicivy/internal/models"
+)
+
+// LetterStreamEventType type d'événement diffusé pendant la génération
+type LetterStreamEventType string
+
+const (
+	StreamEventStatus     LetterStreamEventType = "status"      // Progression du job
+	StreamEventDelta      LetterStreamEventType = "delta"       // Fragment de texte d'une lettre
+	StreamEventLetterDone LetterStreamEventType = "letter_done" // Lettre persistée en DB
+	StreamEventRetry      LetterStreamEventType = "retry"       // Nouvelle tentative, le texte reçu doit être effacé
+	StreamEventRegenerate LetterStreamEventType = "regenerate"  // Lettre rejetée par le contrôle qualité, le texte reçu pour ce type doit être effacé
+	StreamEventCompleted  LetterStreamEventType = "completed"   // Job terminé avec les IDs des lettres
+	StreamEventFailed     LetterStreamEventType = "failed"      // Job définitivement échoué
+	StreamEventCancelled  LetterStreamEventType = "cancelled"   // Job annulé par le visiteur ou l'administration
+)
+
+// LetterStreamEvent événement de génération diffusé aux clients SSE/WebSocket
+type LetterStreamEvent struct {
+	ID         string                `json:"id,omitempty"` // ID de l'entrée Redis Stream
+	Type       LetterStreamEventType `json:"type"`
+	LetterType models.LetterType     `json:"letter_type,omitempty"`
+	Delta      string                `json:"delta,omitempty"`
+	Progress   int                   `json:"progress,omitempty"`
+	LetterID   string                `json:"letter_id,omitempty"`
+
+	// Si completed
+	Letters                map[models.LetterType]string `json:"letters,omitempty"` // ID de la lettre créée par type
+	LetterMotivationID     string                       `json:"letter_motivation_id,omitempty"`
+	LetterAntiMotivationID string                       `json:"letter_anti_motivation_id,omitempty"`
+	Version                int                          `json:"version,omitempty"` // Révision : version créée (LetterID = lettre révisée)
+
+	// Si regenerate
+	Findings models.LetterFindings `json:"findings,omitempty"`
+
+	// Si failed
+	Error string `json:"error,omitempty"`
+}
+
+// IsTerminal indique si l'événement clôt le flux
+func (e LetterStreamEvent) IsTerminal() bool {
+	return e.Type == StreamEventCompleted || e.Type == StreamEventFailed || e.Type == StreamEventCancelled
+}
+
+// TerminalStreamEvent retourne l'événement final d'un job déjà terminé (nil si en cours)
+func (job *LetterJob) TerminalStreamEvent() *LetterStreamEvent {
+	switch job.Status {
+	case JobStatusCompleted:
+		event := LetterStreamEvent{
+			Type:     StreamEventCompleted,
+			Progress: 100,
+			Letters:  LetterIDStrings(job.Letters),
+		}
+		if job.LetterMotivationID != nil {
+			event.LetterMotivationID = job.LetterMotivationID.String()
+		}
+		if job.LetterAntiMotivationID != nil {
+			event.LetterAntiMotivationID = job.LetterAntiMotivationID.String()
+		}
+		if job.IsRevision() && job.LetterID != nil {
+			event.LetterID = job.LetterID.String()
+			event.Version = job.LetterVersion
+		}
+		return &event
+	case JobStatusFailed:
+		event := LetterStreamEvent{Type: StreamEventFailed}
+		if job.Error != nil {
+			event.Error = *job.Error
+		}
+		return &event
+	case JobStatusCancelled:
+		return &LetterStreamEvent{Type: StreamEventCancelled}
+	}
+	return nil
+}
+
+// LetterIDStrings convertit les IDs des lettres d'un job en chaînes (nil si aucune lettre)
+func LetterIDStrings(letters map[models.LetterType]uuid.UUID) map[models.LetterType]string {
+	if len(letters) == 0 {
+		return nil
+	}
+	ids := make(map[models.LetterType]string, len(letters))
+	for letterType, id := range letters {
+		ids[letterType] = id.String()
+	}
+	return ids
+}
+
+// LetterStreamService diffuse les événements de génération via Redis Streams
+// Redis Streams (plutôt que Pub/Sub) permet à un client connecté en retard
+// de rejouer les fragments déjà produits.
+type LetterStreamService struct {
+	redis  *redis.Client
+	ttl    time.Duration
+	maxLen int64
+}
+
+// NewLetterStreamService crée une nouvelle instance du service
+func NewLetterStreamService(redis *redis.Client) *LetterStreamService {
+	return &LetterStreamService{
+		redis:  redis,
+		ttl:    1 * time.Hour,
+		maxLen: 10000,
+	}
+}
+
+// Publish ajoute un événement au flux du job
+func (s *LetterStreamService) Publish(ctx context.Context, jobID string, event LetterStreamEvent) error {
+	event.ID = ""
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	key := streamKey(jobID)
+	pipe := s.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": payload},
+	})
+	pipe.Expire(ctx, key, s.ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+
+	return nil
+}
+
+// Read lit les événements postérieurs à lastID ("0" pour tout rejouer)
+// Bloque au plus block si aucun événement n'est disponible (retourne une slice vide).
+// Un block négatif rend la lecture non bloquante.
+func (s *LetterStreamService) Read(ctx context.Context, jobID, lastID string, block time.Duration) ([]LetterStreamEvent, error) {
+	if lastID == "" {
+		lastID = "0"
+	}
+
+	result, err := s.redis.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{streamKey(jobID), lastID},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	var events []LetterStreamEvent
+	for _, stream := range result {
+		for _, msg := range stream.Messages {
+			raw, ok := msg.Values["data"].(string)
+			if !ok {
+				continue
+			}
+
+			var event LetterStreamEvent
+			if err := json.Unmarshal([]byte(raw), &event); err != nil {
+				continue
+			}
+			event.ID = msg.ID
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// streamKey retourne la clé Redis du flux d'un job
+func streamKey(jobID string) string {
+	return fmt.Sprintf("job:letter:%s:stream", jobID)
+}
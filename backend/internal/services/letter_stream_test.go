@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func newTestStreamService(t *testing.T) (*LetterStreamService, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewLetterStreamService(redisClient), mr
+}
+
+func TestLetterStreamService_PublishAndReplay(t *testing.T) {
+	service, _ := newTestStreamService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.Publish(ctx, "job-1", LetterStreamEvent{Type: StreamEventStatus, Progress: 20}))
+	require.NoError(t, service.Publish(ctx, "job-1", LetterStreamEvent{
+		Type:       StreamEventDelta,
+		LetterType: models.LetterTypeMotivation,
+		Delta:      "Madame, Monsieur,",
+	}))
+
+	// Un client connecté en retard rejoue tout depuis "0"
+	events, err := service.Read(ctx, "job-1", "0", -1)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, StreamEventStatus, events[0].Type)
+	assert.Equal(t, 20, events[0].Progress)
+	assert.Equal(t, StreamEventDelta, events[1].Type)
+	assert.Equal(t, models.LetterTypeMotivation, events[1].LetterType)
+	assert.Equal(t, "Madame, Monsieur,", events[1].Delta)
+	assert.NotEmpty(t, events[1].ID)
+
+	// Lecture à partir du dernier ID : rien de nouveau
+	events, err = service.Read(ctx, "job-1", events[1].ID, -1)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestLetterStreamService_StreamExpires(t *testing.T) {
+	service, mr := newTestStreamService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.Publish(ctx, "job-2", LetterStreamEvent{Type: StreamEventRetry}))
+	assert.True(t, mr.Exists("job:letter:job-2:stream"))
+
+	mr.FastForward(2 * time.Hour)
+	assert.False(t, mr.Exists("job:letter:job-2:stream"))
+}
+
+func TestLetterStreamService_ReadUnknownJob(t *testing.T) {
+	service, _ := newTestStreamService(t)
+
+	events, err := service.Read(context.Background(), "unknown", "0", -1)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestLetterJob_TerminalStreamEvent(t *testing.T) {
+	motivationID := uuid.New()
+	antiMotivationID := uuid.New()
+	errorMsg := "AI generation failed"
+
+	completed := &LetterJob{
+		Status:                 JobStatusCompleted,
+		LetterMotivationID:     &motivationID,
+		LetterAntiMotivationID: &antiMotivationID,
+	}
+	event := completed.TerminalStreamEvent()
+	require.NotNil(t, event)
+	assert.Equal(t, StreamEventCompleted, event.Type)
+	assert.Equal(t, motivationID.String(), event.LetterMotivationID)
+	assert.Equal(t, antiMotivationID.String(), event.LetterAntiMotivationID)
+	assert.True(t, event.IsTerminal())
+
+	failed := &LetterJob{Status: JobStatusFailed, Error: &errorMsg}
+	event = failed.TerminalStreamEvent()
+	require.NotNil(t, event)
+	assert.Equal(t, StreamEventFailed, event.Type)
+	assert.Equal(t, errorMsg, event.Error)
+
+	processing := &LetterJob{Status: JobStatusProcessing}
+	assert.Nil(t, processing.TerminalStreamEvent())
+
+	letterID := uuid.New()
+	revised := &LetterJob{Kind: JobKindRevision, Status: JobStatusCompleted, LetterID: &letterID, LetterVersion: 3}
+	event = revised.TerminalStreamEvent()
+	require.NotNil(t, event)
+	assert.Equal(t, letterID.String(), event.LetterID)
+	assert.Equal(t, 3, event.Version)
+	assert.Empty(t, event.LetterMotivationID)
+}
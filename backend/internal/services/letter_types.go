@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"text/template"
+
+	"maicivy/internal/models"
+)
+
+// LetterFormat forme du texte produit pour un type de lettre
+type LetterFormat string
+
+const (
+	LetterFormatLetter  LetterFormat = "letter"  // En-tête complet, objet et signature
+	LetterFormatEmail   LetterFormat = "email"   // Ligne d'objet et signature, sans en-tête postal
+	LetterFormatMessage LetterFormat = "message" // Message court sans en-tête ni objet
+	LetterFormatPitch   LetterFormat = "pitch"   // Texte destiné à être dit à l'oral
+)
+
+// LetterTypeSpec description d'un type de lettre : prompt, longueur attendue et format
+type LetterTypeSpec struct {
+	Type     models.LetterType
+	Format   LetterFormat
+	MinWords int // Longueur attendue du corps (hors en-tête et objet)
+	MaxWords int
+	Humorous bool // Ton humoristique (révisions)
+
+	template func(l *letterLocale) *template.Template
+	subject  func(o PromptOptions) string // nil = pas de ligne d'objet
+	focus    func(o PromptOptions) string // Consignes supplémentaires de la tâche (optionnel)
+}
+
+// HasHeader indique si le texte commence par l'en-tête postal du candidat
+func (s *LetterTypeSpec) HasHeader() bool {
+	return s.Format == LetterFormatLetter
+}
+
+// HasSignature indique si le texte se termine par la signature du candidat
+func (s *LetterTypeSpec) HasSignature() bool {
+	return s.Format == LetterFormatLetter || s.Format == LetterFormatEmail
+}
+
+// Subject retourne la ligne d'objet demandée dans le prompt (vide si le format n'en a pas)
+func (s *LetterTypeSpec) Subject(opts PromptOptions) string {
+	if s.subject == nil {
+		return ""
+	}
+	return s.subject(opts)
+}
+
+// DefaultLetterTypes types générés quand un job n'en précise aucun
+var DefaultLetterTypes = []models.LetterType{models.LetterTypeMotivation, models.LetterTypeAntiMotivation}
+
+// letterTypeRegistry types de lettres disponibles, dans l'ordre de présentation
+var letterTypeRegistry = []*LetterTypeSpec{
+	{
+		Type:     models.LetterTypeMotivation,
+		Format:   LetterFormatLetter,
+		MinWords: 350,
+		MaxWords: 450,
+		template: func(l *letterLocale) *template.Template { return l.motivation },
+		subject:  PromptOptions.subject,
+		focus:    PromptOptions.motivationFocus,
+	},
+	{
+		Type:     models.LetterTypeAntiMotivation,
+		Format:   LetterFormatLetter,
+		MinWords: 300,
+		MaxWords: 400,
+		Humorous: true,
+		template: func(l *letterLocale) *template.Template { return l.antiMotivation },
+		subject:  PromptOptions.antiSubject,
+		focus:    PromptOptions.antiMotivationFocus,
+	},
+	{
+		Type:     models.LetterTypeFollowUpEmail,
+		Format:   LetterFormatEmail,
+		MinWords: 120,
+		MaxWords: 200,
+		template: func(l *letterLocale) *template.Template { return l.followUpEmail },
+		subject:  PromptOptions.followUpSubject,
+	},
+	{
+		Type:     models.LetterTypeThankYouNote,
+		Format:   LetterFormatEmail,
+		MinWords: 100,
+		MaxWords: 180,
+		template: func(l *letterLocale) *template.Template { return l.thankYouNote },
+		subject:  PromptOptions.thankYouSubject,
+	},
+	{
+		Type:     models.LetterTypeLinkedIn,
+		Format:   LetterFormatMessage,
+		MinWords: 40,
+		MaxWords: 100,
+		template: func(l *letterLocale) *template.Template { return l.linkedInMessage },
+	},
+	{
+		Type:     models.LetterTypeElevatorPitch,
+		Format:   LetterFormatPitch,
+		MinWords: 60,
+		MaxWords: 110,
+		template: func(l *letterLocale) *template.Template { return l.elevatorPitch },
+	},
+}
+
+// letterTypeSpecs index du registre par type
+var letterTypeSpecs = func() map[models.LetterType]*LetterTypeSpec {
+	specs := make(map[models.LetterType]*LetterTypeSpec, len(letterTypeRegistry))
+	for _, spec := range letterTypeRegistry {
+		specs[spec.Type] = spec
+	}
+	return specs
+}()
+
+// LetterTypeSpecFor retourne la description d'un type de lettre
+func LetterTypeSpecFor(letterType models.LetterType) (*LetterTypeSpec, bool) {
+	spec, ok := letterTypeSpecs[letterType]
+	return spec, ok
+}
+
+// LetterTypes retourne les types de lettres disponibles, dans l'ordre de présentation
+func LetterTypes() []models.LetterType {
+	types := make([]models.LetterType, len(letterTypeRegistry))
+	for i, spec := range letterTypeRegistry {
+		types[i] = spec.Type
+	}
+	return types
+}
+
+// ParseLetterTypes valide une liste de types demandés (doublons ignorés, ordre conservé)
+// Une liste vide retourne nil : le job génère alors DefaultLetterTypes.
+func ParseLetterTypes(values []string) ([]models.LetterType, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	types := make([]models.LetterType, 0, len(values))
+	seen := make(map[models.LetterType]bool, len(values))
+	for _, value := range values {
+		letterType := models.LetterType(value)
+		if _, ok := letterTypeSpecs[letterType]; !ok {
+			return nil, fmt.Errorf("unknown letter type %q (available: %v)", value, LetterTypes())
+		}
+		if !seen[letterType] {
+			seen[letterType] = true
+			types = append(types, letterType)
+		}
+	}
+	return types, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+func TestParseLetterTypes(t *testing.T) {
+	types, err := ParseLetterTypes(nil)
+	require.NoError(t, err)
+	assert.Nil(t, types)
+
+	types, err = ParseLetterTypes([]string{"elevator_pitch", "motivation", "elevator_pitch"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.LetterType{models.LetterTypeElevatorPitch, models.LetterTypeMotivation}, types)
+
+	_, err = ParseLetterTypes([]string{"motivation", "haiku"})
+	assert.ErrorContains(t, err, `unknown letter type "haiku"`)
+}
+
+func TestLetterTypeRegistry_PromptsForAllLanguages(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme Corp"}
+
+	for _, letterType := range LetterTypes() {
+		spec, ok := LetterTypeSpecFor(letterType)
+		require.True(t, ok)
+		assert.Less(t, spec.MinWords, spec.MaxWords, letterType)
+
+		for _, lang := range models.SupportedLanguages {
+			t.Run(string(letterType)+"_"+string(lang), func(t *testing.T) {
+				opts := PromptOptions{JobTitle: "SRE", Language: lang}
+				prompt := pb.BuildLetterPrompt(spec, company, opts)
+
+				assert.Contains(t, prompt, "Acme Corp")
+				assert.NotContains(t, prompt, "<no value>")
+				assert.NotContains(t, prompt, "%!")
+
+				// L'objet n'est demandé qu'aux formats qui en ont un
+				subject := spec.Subject(opts)
+				if spec.Format == LetterFormatLetter || spec.Format == LetterFormatEmail {
+					assert.Contains(t, subject, "SRE")
+					assert.Contains(t, prompt, subject)
+				} else {
+					assert.Empty(t, subject)
+				}
+
+				// Le fournisseur fake retrouve l'entreprise quelle que soit la langue
+				assert.Contains(t, fakeLetter(prompt), "Acme Corp")
+			})
+		}
+	}
+}
+
+func TestLetterValidation_ShortFormats(t *testing.T) {
+	pipeline := NewLetterValidationPipeline(DefaultLetterValidators()...)
+
+	// Message LinkedIn : ni en-tête, ni objet, ni signature complète
+	input := newTestValidationInput("Bonjour,\n\nChez Initech, j'ai conçu des APIs en Go pour 2 millions d'utilisateurs. " +
+		"L'équipe plateforme d'Acme et son usage de Kubernetes m'intéressent beaucoup. " +
+		"Je serais ravi d'échanger avec vous sur les enjeux de fiabilité de votre plateforme. " +
+		"Auriez-vous quelques minutes cette semaine pour en discuter ?\n\nJean")
+	input.LetterType = models.LetterTypeLinkedIn
+	input.Subject = ""
+
+	report := pipeline.Validate(input)
+	assert.True(t, report.Passed, report.Findings)
+	assert.Empty(t, report.Findings)
+
+	// Le même texte est bien trop court pour un e-mail de relance sans objet
+	input.LetterType = models.LetterTypeFollowUpEmail
+	input.Subject = "Relance de ma candidature"
+
+	report = pipeline.Validate(input)
+	assert.False(t, report.Passed)
+	checks := make([]string, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		checks = append(checks, finding.Check)
+	}
+	assert.ElementsMatch(t, []string{"structure", "structure", "word_count"}, checks)
+
+	// E-mail de relance valide : objet et signature, sans en-tête postal
+	body := strings.Repeat("Acme ", 150)
+	input.Content = "Objet : Relance de ma candidature\n\nBonjour,\n\n" + body + "\n\nCordialement,\nJean Dupont\njean@example.com"
+
+	report = pipeline.Validate(input)
+	assert.True(t, report.Passed, report.Findings)
+}
+
+func TestBuildRevisionPrompt_LetterTypes(t *testing.T) {
+	letter := &models.GeneratedLetter{CompanyName: "Acme", LetterType: models.LetterTypeElevatorPitch, Content: "Je suis Jean."}
+
+	prompt := BuildRevisionPrompt(letter, "Plus court")
+	assert.Contains(t, prompt, "pitchs de présentation à l'oral")
+	assert.Contains(t, prompt, "Conserve le format et une longueur comparable")
+	assert.NotContains(t, prompt, "en-tête complet")
+
+	letter.LetterType = models.LetterTypeFollowUpEmail
+	letter.Language = models.LanguageEnglish
+	prompt = BuildRevisionPrompt(letter, "Friendlier")
+	assert.Contains(t, prompt, "job application follow-up emails")
+	assert.Contains(t, prompt, "Keep the professional tone")
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"maicivy/internal/models"
+)
+
+// LetterValidationInput lettre générée et contexte de génération soumis au contrôle qualité
+type LetterValidationInput struct {
+	Content     string
+	LetterType  models.LetterType
+	Language    models.Language
+	CompanyName string
+	Subject     string // Objet demandé dans le prompt
+	Profile     models.UserProfile
+	Company     models.CompanyInfo
+	JobPosting  *models.JobPosting
+}
+
+// LetterValidator contrôle de qualité d'une lettre générée
+// Les validateurs sont indépendants : chacun retourne ses propres points relevés.
+type LetterValidator interface {
+	Name() string
+	Validate(input *LetterValidationInput) []models.LetterFinding
+}
+
+// LetterValidationReport résultat du contrôle qualité d'une lettre
+type LetterValidationReport struct {
+	Score    int                   `json:"score"`  // 0-100
+	Passed   bool                  `json:"passed"` // Aucun point bloquant
+	Findings models.LetterFindings `json:"findings"`
+}
+
+const (
+	findingErrorPenalty   = 30
+	findingWarningPenalty = 10
+)
+
+// LetterValidationPipeline exécute une suite de validateurs et agrège leurs résultats
+type LetterValidationPipeline struct {
+	validators []LetterValidator
+}
+
+// NewLetterValidationPipeline crée un pipeline avec les validateurs fournis
+func NewLetterValidationPipeline(validators ...LetterValidator) *LetterValidationPipeline {
+	return &LetterValidationPipeline{validators: validators}
+}
+
+// DefaultLetterValidators validateurs utilisés par défaut par le générateur de lettres
+func DefaultLetterValidators() []LetterValidator {
+	return []LetterValidator{
+		&structureValidator{},
+		newWordCountValidator(),
+		&companyNameValidator{},
+		&placeholderValidator{},
+		&claimsValidator{},
+	}
+}
+
+// Validate contrôle une lettre et calcule son score
+func (p *LetterValidationPipeline) Validate(input *LetterValidationInput) LetterValidationReport {
+	findings := models.LetterFindings{}
+	for _, validator := range p.validators {
+		for _, finding := range validator.Validate(input) {
+			if finding.Check == "" {
+				finding.Check = validator.Name()
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	score := 100
+	for _, finding := range findings {
+		if finding.Severity == models.FindingError {
+			score -= findingErrorPenalty
+		} else {
+			score -= findingWarningPenalty
+		}
+	}
+
+	return LetterValidationReport{
+		Score:    max(score, 0),
+		Passed:   !findings.HasErrors(),
+		Findings: findings,
+	}
+}
+
+// --- Structure ---
+
+// Nombre de lignes non vides examinées en début (en-tête) et fin (signature) de lettre
+const (
+	letterHeaderLines    = 8
+	letterSignatureLines = 3
+)
+
+// structureValidator vérifie l'en-tête, la ligne d'objet et la signature selon le format du type de lettre
+// Une signature absente signale le plus souvent une réponse tronquée.
+type structureValidator struct{}
+
+func (v *structureValidator) Name() string { return "structure" }
+
+func (v *structureValidator) Validate(input *LetterValidationInput) []models.LetterFinding {
+	var findings []models.LetterFinding
+	lines := nonEmptyLines(input.Content)
+	spec := validationSpec(input)
+
+	if name := strings.TrimSpace(input.Profile.Name); name != "" {
+		header := lines[:min(len(lines), letterHeaderLines)]
+		if spec.HasHeader() && !linesContain(header, name) {
+			findings = append(findings, models.LetterFinding{
+				Severity: models.FindingError,
+				Message:  fmt.Sprintf("header block missing: the letter must start with the candidate's contact details (%s)", name),
+			})
+		}
+
+		signature := lines[max(len(lines)-letterSignatureLines, 0):]
+		if spec.HasSignature() && !linesContain(signature, name) {
+			findings = append(findings, models.LetterFinding{
+				Severity: models.FindingError,
+				Message:  fmt.Sprintf("signature missing: the letter must end with the closing formula and the candidate's name (%s), it may be truncated", name),
+			})
+		}
+	}
+
+	if spec.subject != nil && subjectLineIndex(input) < 0 {
+		findings = append(findings, models.LetterFinding{
+			Severity: models.FindingError,
+			Message:  fmt.Sprintf("subject line missing: expected %q", input.Subject),
+		})
+	}
+
+	return findings
+}
+
+// validationSpec retourne la description du type de lettre contrôlé (lettre de motivation si inconnu)
+func validationSpec(input *LetterValidationInput) *LetterTypeSpec {
+	if spec, ok := LetterTypeSpecFor(input.LetterType); ok {
+		return spec
+	}
+	return letterTypeSpecs[models.LetterTypeMotivation]
+}
+
+// --- Longueur ---
+
+// wordRange longueur attendue du corps de la lettre (en mots, hors en-tête)
+type wordRange struct {
+	min, max int
+}
+
+// letterWordTolerance écart toléré (en proportion) avant de rejeter la lettre
+const letterWordTolerance = 0.2
+
+// wordCountValidator vérifie la longueur demandée dans le prompt
+type wordCountValidator struct {
+	ranges map[models.LetterType]wordRange
+}
+
+// newWordCountValidator reprend les longueurs du registre des types de lettres
+func newWordCountValidator() *wordCountValidator {
+	ranges := make(map[models.LetterType]wordRange, len(letterTypeRegistry))
+	for _, spec := range letterTypeRegistry {
+		ranges[spec.Type] = wordRange{min: spec.MinWords, max: spec.MaxWords}
+	}
+	return &wordCountValidator{ranges: ranges}
+}
+
+func (v *wordCountValidator) Name() string { return "word_count" }
+
+func (v *wordCountValidator) Validate(input *LetterValidationInput) []models.LetterFinding {
+	expected, ok := v.ranges[input.LetterType]
+	if !ok {
+		return nil
+	}
+
+	words := len(strings.Fields(letterBody(input)))
+	if words >= expected.min && words <= expected.max {
+		return nil
+	}
+
+	severity := models.FindingWarning
+	if float64(words) < float64(expected.min)*(1-letterWordTolerance) || float64(words) > float64(expected.max)*(1+letterWordTolerance) {
+		severity = models.FindingError
+	}
+
+	return []models.LetterFinding{{
+		Severity: severity,
+		Message:  fmt.Sprintf("body is %d words long, expected %d-%d words (excluding the header)", words, expected.min, expected.max),
+	}}
+}
+
+// --- Entreprise ---
+
+// companyNameValidator vérifie que la lettre s'adresse bien à l'entreprise ciblée
+type companyNameValidator struct{}
+
+func (v *companyNameValidator) Name() string { return "company_name" }
+
+func (v *companyNameValidator) Validate(input *LetterValidationInput) []models.LetterFinding {
+	company := strings.TrimSpace(input.CompanyName)
+	if company == "" || strings.Contains(strings.ToLower(input.Content), strings.ToLower(company)) {
+		return nil
+	}
+
+	return []models.LetterFinding{{
+		Severity: models.FindingError,
+		Message:  fmt.Sprintf("target company %q is never mentioned", company),
+	}}
+}
+
+// --- Placeholders ---
+
+// placeholderPattern : champs à compléter laissés par le modèle ("[Adresse]", "{{nom}}", "<Entreprise>", "XXX", "Lorem ipsum")
+var placeholderPattern = regexp.MustCompile(`\[[^\]\n]{1,60}\]|\{\{[^}\n]*\}\}|<\p{L}[\p{L} '_-]{1,30}>|\bX{3,}\b|(?i:\blorem ipsum\b)`)
+
+// placeholderValidator détecte les placeholders non remplacés
+type placeholderValidator struct{}
+
+func (v *placeholderValidator) Name() string { return "placeholder" }
+
+func (v *placeholderValidator) Validate(input *LetterValidationInput) []models.LetterFinding {
+	matches := uniqueStrings(placeholderPattern.FindAllString(input.Content, -1))
+	if len(matches) == 0 {
+		return nil
+	}
+
+	return []models.LetterFinding{{
+		Severity: models.FindingError,
+		Message:  fmt.Sprintf("unfilled placeholders: %s (remove them or use the provided information)", strings.Join(matches, ", ")),
+	}}
+}
+
+// --- Affirmations ---
+
+// employerName : nom d'organisation (jusqu'à 4 mots commençant par une majuscule)
+const employerName = `(\p{Lu}[\p{L}\d&'.-]*(?:\s+\p{Lu}[\p{L}\d&'.-]*){0,3})`
+
+// employerPatterns : tournures introduisant un employeur, par langue
+// En allemand tous les noms prennent une majuscule : seules les formes explicites sont retenues.
+var employerPatterns = map[models.Language]*regexp.Regexp{
+	models.LanguageFrench:  regexp.MustCompile(`\b(?i:chez|au sein d[e'])\s*` + employerName),
+	models.LanguageEnglish: regexp.MustCompile(`\b(?i:at|worked for|joined)\s+` + employerName),
+	models.LanguageGerman:  regexp.MustCompile(`\bbei der Firma\s+` + employerName + `|\bbei\s+` + employerName + `\s+(?:GmbH|AG|SE|KG)\b`),
+	models.LanguageSpanish: regexp.MustCompile(`\b(?i:en la empresa|trabajé en)\s+` + employerName),
+}
+
+// employerStopWords : pronoms de politesse pris à tort pour des noms ("chez Vous", "at You")
+var employerStopWords = map[string]bool{"vous": true, "nous": true, "you": true, "us": true, "usted": true, "ustedes": true}
+
+// claimsValidator détecte les employeurs et technologies cités qui n'apparaissent pas dans le profil
+// Les technologies de l'entreprise ou de l'offre peuvent être citées (intérêt pour la stack).
+type claimsValidator struct{}
+
+func (v *claimsValidator) Name() string { return "claims" }
+
+func (v *claimsValidator) Validate(input *LetterValidationInput) []models.LetterFinding {
+	var findings []models.LetterFinding
+
+	if employers := unknownEmployers(input); len(employers) > 0 {
+		findings = append(findings, models.LetterFinding{
+			Severity: models.FindingError,
+			Message:  fmt.Sprintf("employers not in the candidate's experience: %s (only cite real employers)", strings.Join(employers, ", ")),
+		})
+	}
+
+	if skills := unknownSkills(input); len(skills) > 0 {
+		findings = append(findings, models.LetterFinding{
+			Severity: models.FindingWarning,
+			Message:  fmt.Sprintf("technologies not in the candidate's profile: %s (do not claim experience with them)", strings.Join(skills, ", ")),
+		})
+	}
+
+	return findings
+}
+
+// unknownEmployers retourne les organisations citées comme employeur absentes du parcours
+func unknownEmployers(input *LetterValidationInput) []string {
+	known := []string{input.CompanyName, input.Company.Name, input.Profile.Name}
+	for _, exp := range input.Profile.Experiences {
+		known = append(known, exp.Company)
+	}
+
+	var unknown []string
+	for _, match := range employerPatterns[input.Language.OrDefault()].FindAllStringSubmatch(input.Content, -1) {
+		claimed := strings.TrimRight(firstNonEmpty(match[1:]...), ".'-")
+		first := strings.ToLower(strings.Fields(claimed)[0])
+
+		// Technologies ("expert at Kubernetes") : couvertes par le contrôle des compétences
+		if employerStopWords[first] || len(extractTechKeywords(claimed)) > 0 {
+			continue
+		}
+		if !matchesKnownName(claimed, known) {
+			unknown = append(unknown, claimed)
+		}
+	}
+	return uniqueStrings(unknown)
+}
+
+// unknownSkills retourne les technologies citées absentes du profil, de l'entreprise et de l'offre
+func unknownSkills(input *LetterValidationInput) []string {
+	profile := input.Profile
+	sources := []string{profile.CurrentRole, profile.Summary, strings.Join(profile.Skills, ", ")}
+	for _, exp := range profile.Experiences {
+		sources = append(sources, exp.Title, exp.Description, strings.Join(exp.Highlights, "\n"))
+	}
+	sources = append(sources, input.Company.Description, strings.Join(input.Company.Technologies, ", "))
+	if input.JobPosting != nil {
+		sources = append(sources, input.JobPosting.RawText, strings.Join(input.JobPosting.TechKeywords, ", "))
+	}
+
+	allowed := make(map[string]bool)
+	for _, keyword := range extractTechKeywords(strings.Join(sources, "\n")) {
+		allowed[keyword] = true
+	}
+
+	var unknown []string
+	for _, keyword := range extractTechKeywords(input.Content) {
+		if !allowed[keyword] {
+			unknown = append(unknown, keyword)
+		}
+	}
+	return unknown
+}
+
+// --- Helpers ---
+
+// nonEmptyLines retourne les lignes non vides, sans espaces de bord
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// linesContain indique si l'une des lignes contient le texte (insensible à la casse)
+func linesContain(lines []string, text string) bool {
+	text = strings.ToLower(text)
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// subjectLineIndex retourne l'index (lignes du contenu) de la ligne d'objet, -1 si absente
+// La ligne commence par le libellé de la langue ("Objet", "Subject"...) ou reprend l'objet demandé.
+func subjectLineIndex(input *LetterValidationInput) int {
+	label := strings.ToLower(localeFor(input.Language).subjectLabel)
+	subject := strings.ToLower(strings.TrimSpace(input.Subject))
+
+	for i, line := range strings.Split(input.Content, "\n") {
+		line = strings.ToLower(strings.Trim(strings.TrimSpace(line), `"*`))
+		if label != "" && strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(line, label)), ":") && strings.HasPrefix(line, label) {
+			return i
+		}
+		if subject != "" && strings.Contains(line, subject) {
+			return i
+		}
+	}
+	return -1
+}
+
+// letterBody retourne le corps de la lettre (après la ligne d'objet si elle est présente)
+func letterBody(input *LetterValidationInput) string {
+	if validationSpec(input).subject == nil {
+		return input.Content
+	}
+	i := subjectLineIndex(input)
+	if i < 0 {
+		return input.Content
+	}
+	return strings.Join(strings.Split(input.Content, "\n")[i+1:], "\n")
+}
+
+// matchesKnownName indique si un nom correspond (inclusion, insensible à la casse) à l'un des noms connus
+func matchesKnownName(name string, known []string) bool {
+	name = strings.ToLower(name)
+	for _, k := range known {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k != "" && (strings.Contains(name, k) || strings.Contains(k, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty retourne la première valeur non vide
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+)
+
+// buildTestLetter construit une lettre complète adressée à company, dont le corps compte environ bodyWords mots
+func buildTestLetter(company, body string, bodyWords int) string {
+	words := strings.Fields(body)
+	for len(words) < bodyWords {
+		words = append(words, "motivation")
+	}
+
+	return "Jean Dupont\n12 rue des Lilas\n79100 Tourtenay\njean@example.com\n\nTourtenay, le 5 mars 2026\n\n" + company + "\n\n" +
+		"Objet : Candidature spontanée\n\nMadame, Monsieur,\n\n" +
+		strings.Join(words[:bodyWords-4], " ") +
+		"\n\nCordialement,\nJean Dupont"
+}
+
+func newTestValidationInput(content string) *LetterValidationInput {
+	return &LetterValidationInput{
+		Content:     content,
+		LetterType:  models.LetterTypeMotivation,
+		Language:    models.LanguageFrench,
+		CompanyName: "Acme",
+		Subject:     "Candidature spontanée",
+		Profile: models.UserProfile{
+			Name:   "Jean Dupont",
+			Skills: []string{"Go", "PostgreSQL"},
+			Experiences: []models.ExperienceDetail{
+				{Title: "Développeur Backend", Company: "Initech", Description: "APIs en Go et Redis"},
+			},
+		},
+		Company: models.CompanyInfo{Name: "Acme", Technologies: []string{"Kubernetes"}},
+	}
+}
+
+func TestLetterValidationPipeline_ValidLetter(t *testing.T) {
+	content := buildTestLetter("Acme", "Chez Initech, j'ai conçu des APIs en Go et PostgreSQL. Votre usage de Kubernetes chez Acme m'intéresse.", 400)
+
+	report := NewLetterValidationPipeline(DefaultLetterValidators()...).Validate(newTestValidationInput(content))
+
+	assert.True(t, report.Passed)
+	assert.Equal(t, 100, report.Score)
+	assert.Empty(t, report.Findings)
+}
+
+func TestLetterValidationPipeline_Findings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		check    string
+		severity models.FindingSeverity
+		message  string
+	}{
+		{
+			name:     "Truncated reply",
+			content:  strings.TrimSuffix(buildTestLetter("Acme", "Acme", 400), "\n\nCordialement,\nJean Dupont"),
+			check:    "structure",
+			severity: models.FindingError,
+			message:  "signature missing",
+		},
+		{
+			name:     "Missing header",
+			content:  strings.Replace(buildTestLetter("Acme", "Acme", 400), "Jean Dupont\n", "", 1),
+			check:    "structure",
+			severity: models.FindingError,
+			message:  "header block missing",
+		},
+		{
+			name:     "Missing subject",
+			content:  strings.Replace(buildTestLetter("Acme", "Acme", 400), "Objet : Candidature spontanée", "", 1),
+			check:    "structure",
+			severity: models.FindingError,
+			message:  "subject line missing",
+		},
+		{
+			name:     "Slightly too long",
+			content:  buildTestLetter("Acme", "Acme", 480),
+			check:    "word_count",
+			severity: models.FindingWarning,
+			message:  "expected 350-450 words",
+		},
+		{
+			name:     "Far too short",
+			content:  buildTestLetter("Acme", "Acme", 120),
+			check:    "word_count",
+			severity: models.FindingError,
+			message:  "expected 350-450 words",
+		},
+		{
+			name:     "Wrong company",
+			content:  buildTestLetter("Globex", "Globex", 400),
+			check:    "company_name",
+			severity: models.FindingError,
+			message:  `"Acme"`,
+		},
+		{
+			name:     "Placeholder",
+			content:  buildTestLetter("Acme", "Acme [Adresse de l'entreprise]", 400),
+			check:    "placeholder",
+			severity: models.FindingError,
+			message:  "[Adresse de l'entreprise]",
+		},
+		{
+			name:     "Unknown employer",
+			content:  buildTestLetter("Acme", "Acme. Chez Google, j'ai dirigé une équipe.", 400),
+			check:    "claims",
+			severity: models.FindingError,
+			message:  "Google",
+		},
+		{
+			name:     "Unknown skill",
+			content:  buildTestLetter("Acme", "Acme. Mon expertise en Terraform vous sera utile.", 400),
+			check:    "claims",
+			severity: models.FindingWarning,
+			message:  "Terraform",
+		},
+	}
+
+	pipeline := NewLetterValidationPipeline(DefaultLetterValidators()...)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := pipeline.Validate(newTestValidationInput(tc.content))
+
+			require.NotEmpty(t, report.Findings)
+			finding := report.Findings[0]
+			assert.Equal(t, tc.check, finding.Check)
+			assert.Equal(t, tc.severity, finding.Severity)
+			assert.Contains(t, finding.Message, tc.message)
+			assert.Equal(t, tc.severity == models.FindingWarning, report.Passed)
+			assert.Less(t, report.Score, 100)
+		})
+	}
+}
+
+func TestClaimsValidator_KnownNames(t *testing.T) {
+	input := newTestValidationInput(buildTestLetter("Acme", "Chez Acme comme chez Initech, j'ai travaillé en Go. Chez Vous ?", 400))
+	assert.Empty(t, (&claimsValidator{}).Validate(input))
+
+	// Allemand : seules les formes explicites sont considérées comme des employeurs
+	input.Language = models.LanguageGerman
+	input.Content = "Bei Projekten mit Go habe ich bei der Firma Globex gearbeitet, danach bei Hooli GmbH."
+	findings := (&claimsValidator{}).Validate(input)
+	require.Len(t, findings, 1)
+	assert.Contains(t, findings[0].Message, "Globex, Hooli")
+	assert.NotContains(t, findings[0].Message, "Projekten")
+}
+
+func TestSubjectLine_German(t *testing.T) {
+	input := newTestValidationInput("Jean Dupont\n\nBewerbung als Backend Engineer\n\nSehr geehrte Damen und Herren,")
+	input.Language = models.LanguageGerman
+	input.Subject = "Bewerbung als Backend Engineer"
+
+	assert.Equal(t, 2, subjectLineIndex(input))
+	assert.Equal(t, "\nSehr geehrte Damen und Herren,", letterBody(input))
+}
+
+// sequenceProvider provider de test qui répond successivement les textes fournis
+type sequenceProvider struct {
+	texts   []string
+	prompts []string
+}
+
+func (p *sequenceProvider) Name() string  { return "sequence" }
+func (p *sequenceProvider) Model() string { return "sequence-model" }
+
+func (p *sequenceProvider) Generate(ctx context.Context, prompt string) (*GenerationResult, error) {
+	text := p.texts[min(len(p.prompts), len(p.texts)-1)]
+	p.prompts = append(p.prompts, prompt)
+	return &GenerationResult{Text: text, TokensInput: 10, TokensOutput: 20}, nil
+}
+
+func (p *sequenceProvider) GenerateStream(ctx context.Context, prompt string, onDelta StreamHandler) (*GenerationResult, error) {
+	result, err := p.Generate(ctx, prompt)
+	if err == nil && onDelta != nil {
+		onDelta(result.Text)
+	}
+	return result, err
+}
+
+func newTestCheckedGenerator(provider TextGenerator, maxRegenerations int) *LetterGenerator {
+	return &LetterGenerator{
+		aiService: &AIService{
+			config:          &config.AIConfig{MaxRequestsPerMinute: 6000},
+			providers:       []TextGenerator{provider},
+			rateLimiter:     newTestRateLimiter(),
+			metricsRecorder: &DefaultMetricsRecorder{},
+		},
+		validator:        NewLetterValidationPipeline(DefaultLetterValidators()...),
+		maxRegenerations: maxRegenerations,
+	}
+}
+
+func TestGenerateChecked_RegeneratesRejectedLetter(t *testing.T) {
+	provider := &sequenceProvider{texts: []string{
+		buildTestLetter("Acme", "Acme [Nom du recruteur]", 400),
+		buildTestLetter("Acme", "Acme", 400),
+	}}
+	lg := newTestCheckedGenerator(provider, 2)
+
+	var rejected []LetterValidationReport
+	letter, err := lg.generateChecked(context.Background(), "PROMPT", newTestValidationInput(""), nil, func(report LetterValidationReport) {
+		rejected = append(rejected, report)
+	})
+	require.NoError(t, err)
+
+	assert.True(t, letter.report.Passed)
+	assert.Equal(t, 1, letter.regenerations)
+	assert.NotContains(t, letter.content, "[Nom du recruteur]")
+	assert.Equal(t, 60, letter.tokens) // Tokens cumulés des deux tentatives
+	require.Len(t, rejected, 1)
+
+	// La régénération reprend le prompt avec les corrections attendues
+	require.Len(t, provider.prompts, 2)
+	assert.True(t, strings.HasPrefix(provider.prompts[1], "PROMPT\n\nATTENTION"))
+	assert.Contains(t, provider.prompts[1], "[Nom du recruteur]")
+}
+
+func TestGenerateChecked_BoundedRegenerations(t *testing.T) {
+	provider := &sequenceProvider{texts: []string{
+		buildTestLetter("Globex", "Globex", 400),          // Mauvaise entreprise
+		buildTestLetter("Globex", "Globex", 120),          // Pire : trop courte en plus
+		buildTestLetter("Globex", "Globex [Adresse]", 90), // Encore pire
+	}}
+	lg := newTestCheckedGenerator(provider, 2)
+
+	letter, err := lg.generateChecked(context.Background(), "PROMPT", newTestValidationInput(""), nil, nil)
+	require.NoError(t, err)
+
+	// Trois tentatives au total, la meilleure est conservée avec ses points relevés
+	assert.Len(t, provider.prompts, 3)
+	assert.Equal(t, 2, letter.regenerations)
+	assert.False(t, letter.report.Passed)
+	assert.Equal(t, provider.texts[0], letter.content)
+	assert.Equal(t, "company_name", letter.report.Findings[0].Check)
+
+	// Sans régénération : contrôle seul
+	provider = &sequenceProvider{texts: []string{buildTestLetter("Globex", "Globex", 400)}}
+	letter, err = newTestCheckedGenerator(provider, 0).generateChecked(context.Background(), "PROMPT", newTestValidationInput(""), nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, provider.prompts, 1)
+	assert.Zero(t, letter.regenerations)
+	assert.False(t, letter.report.Passed)
+}
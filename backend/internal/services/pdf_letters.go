@@ -6,10 +6,7 @@ import (
 	"html/template"
 	"io"
 	"strings"
-	"time"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/rs/zerolog/log"
 
 	"maicivy/internal/models"
@@ -17,6 +14,8 @@ import (
 
 type PDFLetterService struct {
 	templates *template.Template
+	renderer  *PDFRenderer
+	native    *NativePDFRenderer
 }
 
 func NewPDFLetterService(templatesPath string) (*PDFLetterService, error) {
@@ -31,16 +30,41 @@ func NewPDFLetterService(templatesPath string) (*PDFLetterService, error) {
 	}, nil
 }
 
-// GeneratePDF : génère PDF d'une lettre
+// SetRenderer partage un renderer PDF (renderer par défaut du processus sinon)
+func (s *PDFLetterService) SetRenderer(renderer *PDFRenderer) {
+	s.renderer = renderer
+}
+
+// SetNativeRenderer partage le rendu natif (rendu natif par défaut du processus sinon)
+func (s *PDFLetterService) SetNativeRenderer(native *NativePDFRenderer) {
+	s.native = native
+}
+
+// GeneratePDF : génère PDF d'une lettre (navigateur si disponible, rendu natif sinon)
 func (s *PDFLetterService) GeneratePDF(ctx context.Context, letter models.LetterResponse, writer io.Writer) error {
-	// 1. Render HTML from template
-	html, err := s.renderHTML(letter)
+	return s.GeneratePDFWithEngine(ctx, letter, PDFEngineAuto, writer)
+}
+
+// GeneratePDFWithEngine : génère PDF d'une lettre avec le moteur demandé
+func (s *PDFLetterService) GeneratePDFWithEngine(ctx context.Context, letter models.LetterResponse, engine PDFEngine, writer io.Writer) error {
+	pdfBuf, err := renderWithEngine(ctx, engine, rendererOrDefault(s.renderer), func() ([]byte, error) {
+		// 1. Render HTML from template
+		html, err := s.renderHTML(letter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render HTML: %w", err)
+		}
+
+		// 2. Convert HTML to PDF via chromedp
+		return s.renderPDF(ctx, html)
+	}, func() ([]byte, error) {
+		return nativeOrDefault(s.native).RenderLetter(ctx, letter)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to render HTML: %w", err)
+		return err
 	}
 
-	// 2. Convert HTML to PDF via chromedp
-	return s.htmlToPDF(ctx, html, writer)
+	_, err = writer.Write(pdfBuf)
+	return err
 }
 
 // renderHTML : génère HTML depuis template
@@ -50,17 +74,28 @@ func (s *PDFLetterService) renderHTML(letter models.LetterResponse) (string, err
 		templateName = "letter_anti_motivation.html"
 	}
 
+	// Libellés et date dans la langue de la lettre (structure partagée avec les exports)
+	lang := letter.Language.OrDefault()
+	locale := localeFor(lang)
+	doc := NewLetterDocument(letter)
+
 	var buf strings.Builder
 	data := struct {
 		Content     string
 		CompanyName string
 		Date        string
 		Type        string
+		Title       string
+		Lang        string
+		Labels      letterPDFLabels
 	}{
 		Content:     letter.Content,
 		CompanyName: letter.CompanyInfo.Name,
-		Date:        letter.GeneratedAt.Format("02 January 2006"),
+		Date:        doc.Date,
 		Type:        string(letter.Type),
+		Title:       doc.Title,
+		Lang:        string(lang),
+		Labels:      locale.pdf,
 	}
 
 	if err := s.templates.ExecuteTemplate(&buf, templateName, data); err != nil {
@@ -70,47 +105,25 @@ func (s *PDFLetterService) renderHTML(letter models.LetterResponse) (string, err
 	return buf.String(), nil
 }
 
-// htmlToPDF : convertit HTML en PDF via Chrome headless
-func (s *PDFLetterService) htmlToPDF(ctx context.Context, html string, writer io.Writer) error {
-	// Create chromedp context
-	allocCtx, cancel := chromedp.NewContext(ctx)
-	defer cancel()
-
-	// Timeout protection
-	allocCtx, cancel = context.WithTimeout(allocCtx, 30*time.Second)
-	defer cancel()
-
-	var pdfBuf []byte
-
-	// Execute chromedp tasks
-	err := chromedp.Run(allocCtx,
-		chromedp.Navigate("about:blank"),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Set HTML content
-			script := fmt.Sprintf(`
-				document.open();
-				document.write(%s);
-				document.close();
-			`, escapeJSString(html))
-
-			return chromedp.Evaluate(script, nil).Do(ctx)
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Wait for page load
-			time.Sleep(500 * time.Millisecond)
-			return nil
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Print to PDF
-			var err error
-			pdfBuf, _, err = page.PrintToPDF().Do(ctx)
-			return err
-		}),
-	)
+// pdfTitle : titre du document (les types sans template dédié reprennent la mise en page de la lettre de motivation)
+func pdfTitle(labels letterPDFLabels, letterType models.LetterType) string {
+	switch letterType {
+	case models.LetterTypeAntiMotivation:
+		return labels.AntiMotivationTitle
+	case models.LetterTypeMotivation:
+		return labels.MotivationTitle
+	}
+	if title, ok := labels.Titles[letterType]; ok {
+		return title
+	}
+	return labels.MotivationTitle
+}
 
+// htmlToPDF : convertit HTML en PDF via le navigateur headless partagé
+func (s *PDFLetterService) htmlToPDF(ctx context.Context, html string, writer io.Writer) error {
+	pdfBuf, err := s.renderPDF(ctx, html)
 	if err != nil {
-		log.Error().Err(err).Msg("chromedp error during PDF generation")
-		return fmt.Errorf("chromedp error: %w", err)
+		return err
 	}
 
 	// Write PDF to output
@@ -118,6 +131,16 @@ func (s *PDFLetterService) htmlToPDF(ctx context.Context, html string, writer io
 	return err
 }
 
+// renderPDF : rendu HTML -> PDF par le navigateur
+func (s *PDFLetterService) renderPDF(ctx context.Context, html string) ([]byte, error) {
+	pdfBuf, err := rendererOrDefault(s.renderer).Render(ctx, "letter", html)
+	if err != nil {
+		log.Error().Err(err).Msg("chromedp error during PDF generation")
+		return nil, fmt.Errorf("chromedp error: %w", err)
+	}
+	return pdfBuf, nil
+}
+
 // escapeJSString : escape string pour JS
 func escapeJSString(s string) string {
 	// Simple escaping for JavaScript template literal
@@ -73,7 +73,7 @@ func (suite *PDFLetterServiceTestSuite) TestRenderHTML_Motivation() {
 	assert.Contains(suite.T(), html, "<!DOCTYPE html>")
 	assert.Contains(suite.T(), html, "Lettre de Motivation")
 	assert.Contains(suite.T(), html, "TechCorp")
-	assert.Contains(suite.T(), html, "09 December 2025")
+	assert.Contains(suite.T(), html, "9 décembre 2025")
 	assert.Contains(suite.T(), html, "Je suis très motivé")
 }
 
@@ -95,11 +95,41 @@ func (suite *PDFLetterServiceTestSuite) TestRenderHTML_AntiMotivation() {
 	assert.Contains(suite.T(), html, "<!DOCTYPE html>")
 	assert.Contains(suite.T(), html, "Lettre Anti-Motivation")
 	assert.Contains(suite.T(), html, "BadCorp")
-	assert.Contains(suite.T(), html, "09 December 2025")
+	assert.Contains(suite.T(), html, "9 décembre 2025")
 	assert.Contains(suite.T(), html, "Pourquoi je ne veux PAS")
 	assert.Contains(suite.T(), html, "anti_motivation")
 }
 
+// Test renderHTML avec les templates réels : libellés et date dans la langue de la lettre
+func (suite *PDFLetterServiceTestSuite) TestRenderHTML_Language() {
+	service, err := NewPDFLetterService("../../templates/letters")
+	suite.Require().NoError(err)
+
+	letter := models.LetterResponse{
+		Content:     "Dear Hiring Manager,",
+		Type:        models.LetterTypeMotivation,
+		Language:    models.LanguageEnglish,
+		CompanyInfo: models.CompanyInfo{Name: "Acme"},
+		GeneratedAt: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	}
+
+	html, err := service.renderHTML(letter)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), html, `<html lang="en">`)
+	assert.Contains(suite.T(), html, "<h1>Cover Letter</h1>")
+	assert.Contains(suite.T(), html, "For the attention of Acme")
+	assert.Contains(suite.T(), html, "16 October 2026")
+
+	// Langue absente (lettres existantes) : français
+	letter.Type = models.LetterTypeAntiMotivation
+	letter.Language = ""
+	html, err = service.renderHTML(letter)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), html, `<html lang="fr">`)
+	assert.Contains(suite.T(), html, "Lettre d&#39;Anti-Motivation")
+	assert.Contains(suite.T(), html, "16 octobre 2026")
+}
+
 // Test renderHTML avec contenu vide
 func (suite *PDFLetterServiceTestSuite) TestRenderHTML_EmptyContent() {
 	letter := models.LetterResponse{
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/config"
+	"maicivy/internal/metrics"
+	"maicivy/internal/models"
+	"maicivy/internal/pdf"
+)
+
+// PDFEngine moteur de rendu PDF
+type PDFEngine string
+
+const (
+	PDFEngineAuto    PDFEngine = ""        // Navigateur si disponible, rendu natif sinon
+	PDFEngineBrowser PDFEngine = "browser" // Chrome headless (templates HTML)
+	PDFEngineNative  PDFEngine = "native"  // Mise en page Go pure
+)
+
+// PDFEngines moteurs sélectionnables explicitement
+var PDFEngines = []PDFEngine{PDFEngineBrowser, PDFEngineNative}
+
+// ParsePDFEngine valide un moteur de rendu ("" ou "auto" : sélection automatique)
+func ParsePDFEngine(value string) (PDFEngine, error) {
+	engine := PDFEngine(strings.ToLower(strings.TrimSpace(value)))
+	if engine == "auto" || engine == PDFEngineAuto {
+		return PDFEngineAuto, nil
+	}
+	for _, supported := range PDFEngines {
+		if engine == supported {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("unknown pdf renderer %q (available: auto, browser, native)", value)
+}
+
+// renderWithEngine rendu via le navigateur, ou via le rendu natif si demandé
+// ou si aucun navigateur n'est disponible en mode automatique
+func renderWithEngine(ctx context.Context, engine PDFEngine, renderer *PDFRenderer, browser func() ([]byte, error), native func() ([]byte, error)) ([]byte, error) {
+	if engine == PDFEngineNative || (engine == PDFEngineAuto && !renderer.Available()) {
+		return native()
+	}
+
+	pdfBytes, err := browser()
+	if err != nil && engine == PDFEngineAuto && errors.Is(err, ErrPDFBrowserUnavailable) && ctx.Err() == nil {
+		log.Warn().Err(err).Msg("Headless browser unavailable, falling back to native PDF rendering")
+		return native()
+	}
+	return pdfBytes, err
+}
+
+// nativeFontCandidates emplacements usuels des polices DejaVu (Debian/Ubuntu, Alpine, Arch)
+var nativeFontCandidates = [][2]string{
+	{"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", "/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf"},
+	{"/usr/share/fonts/dejavu/DejaVuSans.ttf", "/usr/share/fonts/dejavu/DejaVuSans-Bold.ttf"},
+	{"/usr/share/fonts/TTF/DejaVuSans.ttf", "/usr/share/fonts/TTF/DejaVuSans-Bold.ttf"},
+}
+
+// NativePDFRenderer rendu PDF sans navigateur : reproduit la mise en page des templates HTML
+// Les polices TrueType configurées (ou DejaVu) sont embarquées ; à défaut, Helvetica
+// (accents latins uniquement).
+type NativePDFRenderer struct {
+	regular pdf.Font
+	bold    pdf.Font
+}
+
+var (
+	defaultNativeOnce sync.Once
+	defaultNative     *NativePDFRenderer
+)
+
+// nativeOrDefault retourne le rendu natif injecté, ou celui partagé du processus
+func nativeOrDefault(native *NativePDFRenderer) *NativePDFRenderer {
+	if native != nil {
+		return native
+	}
+	defaultNativeOnce.Do(func() {
+		defaultNative = NewNativePDFRenderer(config.LoadPDFConfig())
+	})
+	return defaultNative
+}
+
+// NewNativePDFRenderer charge les polices du rendu natif
+func NewNativePDFRenderer(cfg *config.PDFConfig) *NativePDFRenderer {
+	r := &NativePDFRenderer{regular: pdf.Helvetica, bold: pdf.HelveticaBold}
+
+	regularPath, boldPath := cfg.FontRegular, cfg.FontBold
+	if regularPath == "" {
+		for _, candidate := range nativeFontCandidates {
+			if _, err := os.Stat(candidate[0]); err == nil {
+				regularPath = candidate[0]
+				if boldPath == "" {
+					boldPath = candidate[1]
+				}
+				break
+			}
+		}
+	}
+
+	if regularPath == "" {
+		log.Warn().Msg("No TrueType font found for native PDF rendering, using Helvetica (Latin-1 characters only)")
+		return r
+	}
+
+	regular, err := pdf.LoadTrueTypeFont(regularPath)
+	if err != nil {
+		log.Warn().Err(err).Str("path", regularPath).Msg("Failed to load PDF font, using Helvetica")
+		return r
+	}
+	r.regular, r.bold = regular, regular
+
+	if boldPath != "" {
+		if bold, err := pdf.LoadTrueTypeFont(boldPath); err == nil {
+			r.bold = bold
+		} else {
+			log.Warn().Err(err).Str("path", boldPath).Msg("Failed to load bold PDF font, using regular weight")
+		}
+	}
+
+	return r
+}
+
+// render compose et sérialise un document (métriques "native")
+func (r *NativePDFRenderer) render(ctx context.Context, document string, compose func() *pdf.Document) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("native pdf render aborted: %w", err)
+	}
+
+	start := time.Now()
+	var buf bytes.Buffer
+	_, err := compose().WriteTo(&buf)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.ObservePDFRender("native", document, status, time.Since(start).Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("native pdf render failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderLetter rend une lettre (mise en page de letter_motivation.html / letter_anti_motivation.html)
+func (r *NativePDFRenderer) RenderLetter(ctx context.Context, letter models.LetterResponse) ([]byte, error) {
+	return r.render(ctx, "letter", func() *pdf.Document {
+		letterDoc := NewLetterDocument(letter)
+
+		doc := pdf.New(pdf.A4, 56)
+		doc.SetInfo(letterDoc.Title+" - "+letter.CompanyInfo.Name, string(letter.Language.OrDefault()))
+
+		accent, titleSize := pdf.Hex("#3b82f6"), 18.0
+		if letter.Type == models.LetterTypeAntiMotivation {
+			accent, titleSize = pdf.Hex("#dc2626"), 20.0
+		}
+
+		// En-tête
+		doc.Paragraph(letterDoc.Title, pdf.Style{Font: r.bold, Size: titleSize, Color: accent, SpaceAfter: 6})
+		if letterDoc.Warning != "" {
+			doc.Paragraph(letterDoc.Warning, pdf.Style{Font: r.bold, Size: 12, Color: pdf.Hex("#991b1b"), SpaceAfter: 6})
+		}
+		doc.Paragraph(letterDoc.Date, pdf.Style{Font: r.regular, Size: 10, Color: pdf.Hex("#6b7280")})
+		if letterDoc.Attention != "" {
+			doc.Paragraph(letterDoc.Attention, pdf.Style{Font: r.regular, Size: 11, Color: pdf.Hex("#374151"), SpaceBefore: 3})
+		}
+		doc.Space(14)
+		doc.Rule(2, accent)
+		doc.Space(28)
+
+		// Corps
+		body := pdf.Style{Font: r.regular, Size: 11, Color: pdf.Hex("#1a1a1a"), Align: pdf.AlignJustify, LineHeight: 1.6, SpaceAfter: 12}
+		for _, paragraph := range letterDoc.Paragraphs {
+			doc.Paragraph(strings.Join(paragraph, "\n"), body)
+		}
+
+		// Pied de page
+		doc.Space(28)
+		doc.Rule(1, pdf.Hex("#e5e7eb"))
+		doc.Space(14)
+		doc.Paragraph(strings.Join(letterDoc.Footer, "\n"), pdf.Style{Font: r.regular, Size: 9, Color: pdf.Hex("#9ca3af"), Align: pdf.AlignCenter})
+
+		return doc
+	})
+}
+
+// RenderCV rend un CV (mise en page de templates/cv/cv_base.html)
+func (r *NativePDFRenderer) RenderCV(ctx context.Context, cv *AdaptiveCVResponse) ([]byte, error) {
+	return r.render(ctx, "cv", func() *pdf.Document {
+		var (
+			primary = pdf.Hex("#2563eb")
+			muted   = pdf.Hex("#64748b")
+			text    = pdf.Hex("#1a1a1a")
+			body    = pdf.Hex("#334155")
+			tag     = pdf.Hex("#1e40af")
+		)
+
+		doc := pdf.New(pdf.A4, 40)
+		doc.SetInfo("CV - "+cv.Theme.Name, "fr")
+
+		// En-tête
+		doc.Paragraph("Alexi - CV", pdf.Style{Font: r.bold, Size: 28, Color: primary, Align: pdf.AlignCenter, SpaceAfter: 4})
+		doc.Paragraph(cv.Theme.Name, pdf.Style{Font: r.regular, Size: 14, Color: muted, Align: pdf.AlignCenter})
+		doc.Space(14)
+		doc.Rule(2, primary)
+		doc.Space(22)
+
+		section := func(title string) {
+			// Le titre reste sur la même page que le premier élément
+			doc.EnsureSpace(80)
+			doc.Paragraph(strings.ToUpper(title), pdf.Style{Font: r.bold, Size: 16, Color: primary, SpaceAfter: 8})
+		}
+		tags := func(technologies []string) {
+			if len(technologies) > 0 {
+				doc.Paragraph(strings.Join(technologies, " · "), pdf.Style{Font: r.regular, Size: 9, Color: tag, Indent: 10, SpaceBefore: 3})
+			}
+		}
+
+		if len(cv.Experiences) > 0 {
+			section("Expériences Professionnelles")
+			for _, exp := range cv.Experiences {
+				end := "Présent"
+				if exp.EndDate != nil {
+					end = exp.EndDate.Format("Jan 2006")
+				}
+
+				doc.EnsureSpace(50)
+				doc.Paragraph(exp.Title, pdf.Style{Font: r.bold, Size: 13, Color: text, Indent: 10})
+				doc.Paragraph(exp.Company, pdf.Style{Font: r.regular, Size: 11, Color: primary, Indent: 10})
+				doc.Paragraph(exp.StartDate.Format("Jan 2006")+" - "+end, pdf.Style{Font: r.regular, Size: 10, Color: muted, Indent: 10, SpaceAfter: 3})
+				if exp.Description != "" {
+					doc.Paragraph(exp.Description, pdf.Style{Font: r.regular, Size: 10, Color: body, Align: pdf.AlignJustify, LineHeight: 1.4, Indent: 10})
+				}
+				tags(exp.Technologies)
+				doc.Space(12)
+			}
+			doc.Space(10)
+		}
+
+		if len(cv.Skills) > 0 {
+			section("Compétences")
+			for _, skill := range cv.Skills {
+				doc.EnsureSpace(28)
+				doc.Paragraph(skill.Name, pdf.Style{Font: r.bold, Size: 10, Color: text, Indent: 10})
+				doc.Paragraph(fmt.Sprintf("%s - %d ans", skill.Level, skill.YearsExperience), pdf.Style{Font: r.regular, Size: 9, Color: muted, Indent: 10, SpaceAfter: 6})
+			}
+			doc.Space(10)
+		}
+
+		if len(cv.Projects) > 0 {
+			section("Projets")
+			for _, project := range cv.Projects {
+				doc.EnsureSpace(40)
+				doc.Paragraph(project.Title, pdf.Style{Font: r.bold, Size: 12, Color: text, Indent: 10})
+				if project.Description != "" {
+					doc.Paragraph(project.Description, pdf.Style{Font: r.regular, Size: 10, Color: body, Align: pdf.AlignJustify, LineHeight: 1.4, Indent: 10, SpaceBefore: 2})
+				}
+				tags(project.Technologies)
+				doc.Space(10)
+			}
+		}
+
+		// Pied de page
+		doc.Space(20)
+		doc.Rule(1, pdf.Hex("#e2e8f0"))
+		doc.Space(10)
+		doc.Paragraph(fmt.Sprintf("Généré le %s - Thème: %s", cv.GeneratedAt.Format("02/01/2006 15:04"), cv.Theme.ID),
+			pdf.Style{Font: r.regular, Size: 9, Color: pdf.Hex("#94a3b8"), Align: pdf.AlignCenter})
+
+		return doc
+	})
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+	"maicivy/internal/models"
+	"maicivy/internal/pdf"
+)
+
+func TestParsePDFEngine(t *testing.T) {
+	for value, expected := range map[string]PDFEngine{"": PDFEngineAuto, "auto": PDFEngineAuto, " Native ": PDFEngineNative, "browser": PDFEngineBrowser} {
+		engine, err := ParsePDFEngine(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, engine)
+	}
+
+	_, err := ParsePDFEngine("wkhtmltopdf")
+	assert.ErrorContains(t, err, `unknown pdf renderer "wkhtmltopdf"`)
+}
+
+func TestNativePDFRenderer_Letter(t *testing.T) {
+	native := &NativePDFRenderer{regular: pdf.Helvetica, bold: pdf.HelveticaBold}
+	letter := models.LetterResponse{
+		Content:     "Madame, Monsieur,\n\nJe suis très motivé à l'idée de rejoindre l'équipe.",
+		Type:        models.LetterTypeMotivation,
+		CompanyInfo: models.CompanyInfo{Name: "Société Générale"},
+		GeneratedAt: time.Date(2025, 12, 9, 10, 0, 0, 0, time.UTC),
+	}
+
+	pdfBytes, err := native.RenderLetter(context.Background(), letter)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfBytes, []byte("%PDF-")))
+	assert.True(t, bytes.HasSuffix(pdfBytes, []byte("%%EOF\n")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = native.RenderLetter(ctx, letter)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNativePDFRenderer_CVEmbedsFonts(t *testing.T) {
+	native := NewNativePDFRenderer(&config.PDFConfig{})
+	if _, ok := native.regular.(*pdf.TrueTypeFont); !ok {
+		t.Skip("no TrueType font installed")
+	}
+
+	cv := &AdaptiveCVResponse{
+		Theme:       config.CVTheme{ID: "backend", Name: "Backend Développeur"},
+		GeneratedAt: time.Now(),
+	}
+	for i := 0; i < 12; i++ {
+		cv.Experiences = append(cv.Experiences, ScoredExperienceResponse{Experience: models.Experience{
+			Title:        fmt.Sprintf("Ingénieur n°%d", i),
+			Company:      "Crédit Agricole",
+			Description:  strings.Repeat("Conception d'APIs Go à forte charge, déploiement Kubernetes. ", 6),
+			StartDate:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Technologies: []string{"Go", "PostgreSQL", "Redis"},
+		}})
+	}
+
+	pdfBytes, err := native.RenderCV(context.Background(), cv)
+	require.NoError(t, err)
+	assert.Contains(t, string(pdfBytes), "/FontFile2")
+	assert.Greater(t, strings.Count(string(pdfBytes), "/Type /Page "), 1, "le CV s'étend sur plusieurs pages")
+}
+
+func TestRenderWithEngine(t *testing.T) {
+	browser := func() ([]byte, error) { return []byte("browser"), nil }
+	native := func() ([]byte, error) { return []byte("native"), nil }
+	ctx := context.Background()
+
+	available, _ := newFakeRenderer(config.PDFConfig{MaxTabs: 1}, nil)
+	unavailable, _ := newFakeRenderer(config.PDFConfig{MaxTabs: 1}, nil)
+	unavailable.available = func(config.PDFConfig) bool { return false }
+
+	out, _ := renderWithEngine(ctx, PDFEngineAuto, available, browser, native)
+	assert.Equal(t, "browser", string(out))
+	out, _ = renderWithEngine(ctx, PDFEngineAuto, unavailable, browser, native)
+	assert.Equal(t, "native", string(out))
+	out, _ = renderWithEngine(ctx, PDFEngineNative, available, browser, native)
+	assert.Equal(t, "native", string(out))
+	out, _ = renderWithEngine(ctx, PDFEngineBrowser, unavailable, browser, native)
+	assert.Equal(t, "browser", string(out))
+
+	// Lancement impossible : repli natif en mode automatique uniquement
+	failing := func() ([]byte, error) { return nil, fmt.Errorf("chromedp failed: %w", ErrPDFBrowserUnavailable) }
+	out, err := renderWithEngine(ctx, PDFEngineAuto, available, failing, native)
+	require.NoError(t, err)
+	assert.Equal(t, "native", string(out))
+	_, err = renderWithEngine(ctx, PDFEngineBrowser, available, failing, native)
+	assert.ErrorIs(t, err, ErrPDFBrowserUnavailable)
+
+	// Autres erreurs du navigateur : pas de repli
+	overloaded := func() ([]byte, error) { return nil, ErrPDFRendererOverloaded }
+	_, err = renderWithEngine(ctx, PDFEngineAuto, available, overloaded, native)
+	assert.True(t, errors.Is(err, ErrPDFRendererOverloaded))
+}
+
+func TestPDFRenderer_LaunchFailureIsUnavailable(t *testing.T) {
+	renderer := newPDFRenderer(config.PDFConfig{MaxTabs: 1}, func(config.PDFConfig) (pdfBrowser, error) {
+		return nil, errors.New(`exec: "chromium": executable file not found`)
+	})
+	defer renderer.Close()
+
+	_, err := renderer.Render(context.Background(), "cv", "x")
+	assert.ErrorIs(t, err, ErrPDFBrowserUnavailable)
+	assert.True(t, renderer.Available(), "renderer de test : navigateur supposé présent")
+
+	renderer.Close()
+	assert.False(t, renderer.Available())
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/config"
+	"maicivy/internal/metrics"
+)
+
+var (
+	// ErrPDFRendererOverloaded trop de rendus en cours et en attente (à convertir en 503)
+	ErrPDFRendererOverloaded = errors.New("pdf renderer overloaded")
+
+	// ErrPDFRendererClosed le renderer a été arrêté (shutdown)
+	ErrPDFRendererClosed = errors.New("pdf renderer closed")
+
+	// ErrPDFBrowserUnavailable aucun navigateur headless n'a pu être lancé
+	ErrPDFBrowserUnavailable = errors.New("headless browser unavailable")
+)
+
+// pdfBrowser navigateur headless lancé par le renderer
+type pdfBrowser interface {
+	NewTab() (pdfTab, error)
+	Done() <-chan struct{} // Fermé quand la connexion au navigateur est perdue (crash)
+	Close()
+}
+
+// pdfTab onglet réutilisable d'un navigateur
+type pdfTab interface {
+	PrintToPDF(ctx context.Context, html string) ([]byte, error)
+	Close()
+}
+
+// PDFRendererStats état courant du renderer
+type PDFRendererStats struct {
+	Running  bool `json:"running"`
+	Starts   int  `json:"starts"`
+	Active   int  `json:"active"`
+	Queued   int  `json:"queued"`
+	IdleTabs int  `json:"idle_tabs"`
+	MaxTabs  int  `json:"max_tabs"`
+}
+
+// PDFRenderer rendu HTML -> PDF partagé par les services PDF
+// Un navigateur longue durée (démarré au premier rendu) et au plus MaxTabs onglets réutilisés ;
+// au-delà, les rendus attendent dans une file bornée puis sont refusés (ErrPDFRendererOverloaded).
+// Un navigateur perdu est redémarré au rendu suivant.
+type PDFRenderer struct {
+	cfg       config.PDFConfig
+	launch    func(cfg config.PDFConfig) (pdfBrowser, error)
+	available func(cfg config.PDFConfig) bool // Présence d'un navigateur (toujours vrai si nil)
+
+	slots chan struct{} // Jetons d'onglets : un par rendu en cours
+
+	mu      sync.Mutex
+	browser pdfBrowser
+	idle    []pdfTab // Onglets libres du navigateur courant
+	starts  int
+	active  int
+	queued  int
+	closed  bool
+}
+
+var (
+	defaultRendererOnce sync.Once
+	defaultRenderer     *PDFRenderer
+)
+
+// rendererOrDefault retourne le renderer injecté, ou le renderer partagé du processus
+// (configuration d'environnement) pour les services construits sans SetRenderer.
+func rendererOrDefault(renderer *PDFRenderer) *PDFRenderer {
+	if renderer != nil {
+		return renderer
+	}
+	defaultRendererOnce.Do(func() {
+		defaultRenderer = NewPDFRenderer(config.LoadPDFConfig())
+	})
+	return defaultRenderer
+}
+
+// NewPDFRenderer crée le renderer (le navigateur n'est lancé qu'au premier rendu)
+func NewPDFRenderer(cfg *config.PDFConfig) *PDFRenderer {
+	r := newPDFRenderer(*cfg, launchChrome)
+	r.available = chromeAvailable
+	return r
+}
+
+func newPDFRenderer(cfg config.PDFConfig, launch func(config.PDFConfig) (pdfBrowser, error)) *PDFRenderer {
+	if cfg.MaxTabs <= 0 {
+		cfg.MaxTabs = 1
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	if cfg.RenderTimeout <= 0 {
+		cfg.RenderTimeout = 30 * time.Second
+	}
+
+	return &PDFRenderer{
+		cfg:    cfg,
+		launch: launch,
+		slots:  make(chan struct{}, cfg.MaxTabs),
+	}
+}
+
+// Render convertit un document HTML en PDF
+// document : type de document pour les métriques ("cv", "letter").
+func (r *PDFRenderer) Render(ctx context.Context, document, html string) ([]byte, error) {
+	if err := r.acquireSlot(ctx); err != nil {
+		return nil, err
+	}
+	defer r.releaseSlot()
+
+	start := time.Now()
+	pdf, err := r.render(ctx, html)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.ObservePDFRender("browser", document, status, time.Since(start).Seconds())
+
+	return pdf, err
+}
+
+// render exécute le rendu dans un onglet libre (ou un nouvel onglet)
+func (r *PDFRenderer) render(ctx context.Context, html string) ([]byte, error) {
+	browser, tab, err := r.acquireTab()
+	if err != nil {
+		return nil, err
+	}
+
+	renderCtx, cancel := context.WithTimeout(ctx, r.cfg.RenderTimeout)
+	defer cancel()
+
+	pdf, err := tab.PrintToPDF(renderCtx, html)
+	r.releaseTab(browser, tab, err == nil)
+	if err != nil {
+		return nil, fmt.Errorf("pdf render failed: %w", err)
+	}
+	return pdf, nil
+}
+
+// acquireSlot réserve un onglet, en attendant au plus QueueTimeout si tous sont occupés
+func (r *PDFRenderer) acquireSlot(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrPDFRendererClosed
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+		r.active++
+		r.updateGauges()
+		r.mu.Unlock()
+		metrics.ObservePDFQueueWait(0)
+		return nil
+	default:
+	}
+
+	if r.queued >= r.cfg.QueueSize {
+		r.mu.Unlock()
+		metrics.IncrementPDFRejected()
+		return ErrPDFRendererOverloaded
+	}
+	r.queued++
+	r.updateGauges()
+	r.mu.Unlock()
+
+	start := time.Now()
+	timer := time.NewTimer(r.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case r.slots <- struct{}{}:
+	case <-timer.C:
+		err = ErrPDFRendererOverloaded
+		metrics.IncrementPDFRejected()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	metrics.ObservePDFQueueWait(time.Since(start).Seconds())
+
+	r.mu.Lock()
+	r.queued--
+	if err == nil {
+		r.active++
+	}
+	r.updateGauges()
+	r.mu.Unlock()
+
+	return err
+}
+
+func (r *PDFRenderer) releaseSlot() {
+	r.mu.Lock()
+	r.active--
+	r.updateGauges()
+	r.mu.Unlock()
+
+	<-r.slots
+}
+
+// updateGauges publie les compteurs de rendus (mutex tenu)
+func (r *PDFRenderer) updateGauges() {
+	metrics.SetPDFInFlight(r.active, r.queued)
+}
+
+// acquireTab retourne un onglet libre, en lançant le navigateur si nécessaire
+func (r *PDFRenderer) acquireTab() (pdfBrowser, pdfTab, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, nil, ErrPDFRendererClosed
+	}
+
+	if r.browser == nil {
+		browser, err := r.launch(r.cfg)
+		if err != nil {
+			r.mu.Unlock()
+			return nil, nil, fmt.Errorf("%w: failed to start browser: %w", ErrPDFBrowserUnavailable, err)
+		}
+
+		reason := "initial"
+		if r.starts > 0 {
+			reason = "crash"
+		}
+		r.starts++
+		r.browser = browser
+		metrics.IncrementPDFBrowserStart(reason)
+		log.Info().Str("reason", reason).Int("max_tabs", r.cfg.MaxTabs).Msg("Headless browser started for PDF rendering")
+
+		go r.watch(browser)
+	}
+
+	browser := r.browser
+	if n := len(r.idle); n > 0 {
+		tab := r.idle[n-1]
+		r.idle = r.idle[:n-1]
+		r.mu.Unlock()
+		return browser, tab, nil
+	}
+	r.mu.Unlock()
+
+	// Ouverture hors verrou : les autres rendus peuvent reprendre leurs onglets libres
+	tab, err := browser.NewTab()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open tab: %w", err)
+	}
+	return browser, tab, nil
+}
+
+// releaseTab remet un onglet sain dans le pool, ferme les autres
+func (r *PDFRenderer) releaseTab(browser pdfBrowser, tab pdfTab, healthy bool) {
+	r.mu.Lock()
+	if healthy && !r.closed && browser == r.browser {
+		r.idle = append(r.idle, tab)
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	tab.Close()
+}
+
+// watch détecte la perte du navigateur : il sera relancé au rendu suivant
+func (r *PDFRenderer) watch(browser pdfBrowser) {
+	<-browser.Done()
+
+	r.mu.Lock()
+	if r.browser != browser {
+		// Arrêt volontaire (Close) : rien à faire
+		r.mu.Unlock()
+		return
+	}
+	idle := r.idle
+	r.browser = nil
+	r.idle = nil
+	r.mu.Unlock()
+
+	log.Warn().Msg("Headless browser lost, it will be restarted on next PDF render")
+
+	for _, tab := range idle {
+		tab.Close()
+	}
+	browser.Close()
+}
+
+// Available indique si un navigateur tourne ou peut être lancé (binaire présent)
+// Le rendu natif est utilisé à défaut en mode automatique.
+func (r *PDFRenderer) Available() bool {
+	r.mu.Lock()
+	running, closed := r.browser != nil, r.closed
+	r.mu.Unlock()
+
+	if closed {
+		return false
+	}
+	return running || r.available == nil || r.available(r.cfg)
+}
+
+// Stats retourne l'état courant du renderer
+func (r *PDFRenderer) Stats() PDFRendererStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return PDFRendererStats{
+		Running:  r.browser != nil,
+		Starts:   r.starts,
+		Active:   r.active,
+		Queued:   r.queued,
+		IdleTabs: len(r.idle),
+		MaxTabs:  r.cfg.MaxTabs,
+	}
+}
+
+// Close arrête le navigateur ; les rendus suivants retournent ErrPDFRendererClosed
+func (r *PDFRenderer) Close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	browser, idle := r.browser, r.idle
+	r.browser = nil
+	r.idle = nil
+	r.mu.Unlock()
+
+	for _, tab := range idle {
+		tab.Close()
+	}
+	if browser != nil {
+		browser.Close()
+	}
+}
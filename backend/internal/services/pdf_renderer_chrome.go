@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+	"maicivy/internal/config"
+)
+
+// chromeBrowser navigateur Chrome/Chromium piloté par chromedp
+type chromeBrowser struct {
+	ctx         context.Context // Contexte du navigateur (premier onglet)
+	cancel      context.CancelFunc
+	allocCancel context.CancelFunc
+	lost        <-chan struct{}
+}
+
+// chromeAllocatorOptions options de lancement adaptées aux conteneurs
+// Pas de "single-process" : plusieurs onglets partagent le même navigateur.
+func chromeAllocatorOptions(cfg config.PDFConfig) []chromedp.ExecAllocatorOption {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.DisableGPU,
+		chromedp.NoSandbox, // Required for running in container as non-root
+		chromedp.Headless,
+		chromedp.Flag("disable-dev-shm-usage", true), // Overcome limited /dev/shm in containers
+		chromedp.Flag("disable-setuid-sandbox", true),
+	)
+
+	// Check for custom Chrome path (Alpine uses chromium-browser)
+	if cfg.ChromePath != "" {
+		opts = append(opts, chromedp.ExecPath(cfg.ChromePath))
+	} else if _, err := os.Stat("/usr/bin/chromium-browser"); err == nil {
+		opts = append(opts, chromedp.ExecPath("/usr/bin/chromium-browser"))
+	}
+
+	return opts
+}
+
+// chromeExecutables noms de binaires recherchés dans le PATH (mêmes noms que chromedp)
+var chromeExecutables = []string{
+	"headless_shell",
+	"headless-shell",
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"google-chrome-beta",
+	"google-chrome-unstable",
+	"/usr/bin/google-chrome",
+}
+
+// chromeAvailable indique si un binaire Chrome/Chromium est présent
+func chromeAvailable(cfg config.PDFConfig) bool {
+	if cfg.ChromePath != "" {
+		_, err := os.Stat(cfg.ChromePath)
+		return err == nil
+	}
+	for _, name := range chromeExecutables {
+		if _, err := exec.LookPath(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// launchChrome démarre un navigateur headless
+func launchChrome(cfg config.PDFConfig) (pdfBrowser, error) {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), chromeAllocatorOptions(cfg)...)
+	ctx, cancel := chromedp.NewContext(allocCtx)
+
+	// Le premier Run lance le processus
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		allocCancel()
+		return nil, err
+	}
+
+	return &chromeBrowser{
+		ctx:         ctx,
+		cancel:      cancel,
+		allocCancel: allocCancel,
+		lost:        chromedp.FromContext(ctx).Browser.LostConnection,
+	}, nil
+}
+
+func (b *chromeBrowser) NewTab() (pdfTab, error) {
+	ctx, cancel := chromedp.NewContext(b.ctx)
+	if err := chromedp.Run(ctx, chromedp.Navigate("about:blank")); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &chromeTab{ctx: ctx, cancel: cancel}, nil
+}
+
+func (b *chromeBrowser) Done() <-chan struct{} {
+	return b.lost
+}
+
+func (b *chromeBrowser) Close() {
+	b.cancel()
+	b.allocCancel()
+}
+
+// chromeTab onglet du navigateur, réutilisé d'un rendu à l'autre
+type chromeTab struct {
+	ctx    context.Context
+	cancel context.CancelFunc // Ferme l'onglet
+}
+
+// PrintToPDF remplace le document de l'onglet puis l'imprime
+func (t *chromeTab) PrintToPDF(ctx context.Context, html string) ([]byte, error) {
+	// Les actions doivent dériver du contexte de l'onglet ; annuler runCtx n'interrompt
+	// que ce rendu (l'onglet reste ouvert).
+	runCtx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var pdf []byte
+	err := chromedp.Run(runCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(tree.Frame.ID, html).Do(ctx)
+		}),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		// Attendre le chargement des polices avant l'impression
+		chromedp.Evaluate(`document.fonts.ready.then(() => true)`, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			pdf, _, err = page.PrintToPDF().Do(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("%w: %v", ctxErr, err)
+		}
+		return nil, err
+	}
+	return pdf, nil
+}
+
+func (t *chromeTab) Close() {
+	t.cancel()
+}
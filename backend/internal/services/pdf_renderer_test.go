@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/config"
+)
+
+// fakeBrowser navigateur simulé : compte les onglets ouverts et simule un crash
+type fakeBrowser struct {
+	mu      sync.Mutex
+	tabs    int
+	printed int
+	closed  bool
+	done    chan struct{}
+	block   chan struct{} // Si non nil, chaque rendu attend sa fermeture
+	fail    bool
+}
+
+func (b *fakeBrowser) NewTab() (pdfTab, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tabs++
+	return &fakeTab{browser: b}, nil
+}
+
+func (b *fakeBrowser) Done() <-chan struct{} { return b.done }
+
+func (b *fakeBrowser) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+}
+
+func (b *fakeBrowser) crash() { close(b.done) }
+
+func (b *fakeBrowser) renders() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.printed
+}
+
+func (b *fakeBrowser) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+type fakeTab struct {
+	browser *fakeBrowser
+	closed  bool
+}
+
+func (t *fakeTab) PrintToPDF(ctx context.Context, html string) ([]byte, error) {
+	if t.browser.block != nil {
+		select {
+		case <-t.browser.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if t.browser.fail {
+		return nil, errors.New("target crashed")
+	}
+	t.browser.mu.Lock()
+	t.browser.printed++
+	t.browser.mu.Unlock()
+	return []byte("%PDF-" + html), nil
+}
+
+func (t *fakeTab) Close() { t.closed = true }
+
+// newFakeRenderer renderer dont chaque lancement crée un fakeBrowser
+func newFakeRenderer(cfg config.PDFConfig, setup func(b *fakeBrowser)) (*PDFRenderer, *[]*fakeBrowser) {
+	var (
+		mu       sync.Mutex
+		browsers []*fakeBrowser
+	)
+	renderer := newPDFRenderer(cfg, func(config.PDFConfig) (pdfBrowser, error) {
+		b := &fakeBrowser{done: make(chan struct{})}
+		if setup != nil {
+			setup(b)
+		}
+		mu.Lock()
+		browsers = append(browsers, b)
+		mu.Unlock()
+		return b, nil
+	})
+	return renderer, &browsers
+}
+
+func TestPDFRenderer_ReusesBrowserAndTabs(t *testing.T) {
+	renderer, browsers := newFakeRenderer(config.PDFConfig{MaxTabs: 2, QueueSize: 1, QueueTimeout: time.Second}, nil)
+	defer renderer.Close()
+
+	for i := 0; i < 3; i++ {
+		pdf, err := renderer.Render(context.Background(), "cv", "<p>cv</p>")
+		require.NoError(t, err)
+		assert.Equal(t, "%PDF-<p>cv</p>", string(pdf))
+	}
+
+	require.Len(t, *browsers, 1)
+	assert.Equal(t, 1, (*browsers)[0].tabs, "l'onglet doit être réutilisé")
+
+	stats := renderer.Stats()
+	assert.True(t, stats.Running)
+	assert.Equal(t, 1, stats.Starts)
+	assert.Equal(t, 1, stats.IdleTabs)
+	assert.Zero(t, stats.Active)
+}
+
+func TestPDFRenderer_Overload(t *testing.T) {
+	block := make(chan struct{})
+	renderer, _ := newFakeRenderer(config.PDFConfig{MaxTabs: 1, QueueSize: 1, QueueTimeout: 5 * time.Second},
+		func(b *fakeBrowser) { b.block = block })
+	defer renderer.Close()
+
+	results := make(chan error, 2)
+	render := func() {
+		_, err := renderer.Render(context.Background(), "letter", "x")
+		results <- err
+	}
+
+	// Un rendu en cours, un en file d'attente
+	go render()
+	require.Eventually(t, func() bool { return renderer.Stats().Active == 1 }, time.Second, 5*time.Millisecond)
+	go render()
+	require.Eventually(t, func() bool { return renderer.Stats().Queued == 1 }, time.Second, 5*time.Millisecond)
+
+	// File pleine : refus immédiat
+	_, err := renderer.Render(context.Background(), "letter", "x")
+	assert.ErrorIs(t, err, ErrPDFRendererOverloaded)
+
+	close(block)
+	assert.NoError(t, <-results)
+	assert.NoError(t, <-results)
+	assert.Zero(t, renderer.Stats().Queued)
+}
+
+func TestPDFRenderer_QueueTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	renderer, _ := newFakeRenderer(config.PDFConfig{MaxTabs: 1, QueueSize: 4, QueueTimeout: 20 * time.Millisecond},
+		func(b *fakeBrowser) { b.block = block })
+	defer renderer.Close()
+
+	go renderer.Render(context.Background(), "cv", "x")
+	require.Eventually(t, func() bool { return renderer.Stats().Active == 1 }, time.Second, 5*time.Millisecond)
+
+	start := time.Now()
+	_, err := renderer.Render(context.Background(), "cv", "x")
+	assert.ErrorIs(t, err, ErrPDFRendererOverloaded)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// L'appelant peut abandonner avant la fin de l'attente
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = renderer.Render(ctx, "cv", "x")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPDFRenderer_RestartsAfterCrash(t *testing.T) {
+	renderer, browsers := newFakeRenderer(config.PDFConfig{MaxTabs: 2, QueueSize: 1, QueueTimeout: time.Second}, nil)
+	defer renderer.Close()
+
+	_, err := renderer.Render(context.Background(), "cv", "x")
+	require.NoError(t, err)
+
+	first := (*browsers)[0]
+	first.crash()
+	require.Eventually(t, func() bool { return !renderer.Stats().Running }, time.Second, 5*time.Millisecond)
+	assert.True(t, first.isClosed())
+
+	_, err = renderer.Render(context.Background(), "cv", "x")
+	require.NoError(t, err)
+	assert.Len(t, *browsers, 2)
+	assert.Equal(t, 2, renderer.Stats().Starts)
+}
+
+func TestPDFRenderer_DiscardsFailedTab(t *testing.T) {
+	renderer, browsers := newFakeRenderer(config.PDFConfig{MaxTabs: 1}, func(b *fakeBrowser) { b.fail = true })
+	defer renderer.Close()
+
+	_, err := renderer.Render(context.Background(), "letter", "x")
+	assert.ErrorContains(t, err, "target crashed")
+	_, err = renderer.Render(context.Background(), "letter", "x")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, (*browsers)[0].tabs, "un onglet en échec n'est pas réutilisé")
+	assert.Zero(t, renderer.Stats().IdleTabs)
+}
+
+func TestPDFRenderer_Close(t *testing.T) {
+	renderer, browsers := newFakeRenderer(config.PDFConfig{MaxTabs: 1}, nil)
+
+	_, err := renderer.Render(context.Background(), "cv", "x")
+	require.NoError(t, err)
+
+	renderer.Close()
+	assert.True(t, (*browsers)[0].isClosed())
+
+	_, err = renderer.Render(context.Background(), "cv", "x")
+	assert.ErrorIs(t, err, ErrPDFRendererClosed)
+}
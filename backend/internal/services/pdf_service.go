@@ -2,20 +2,16 @@ package services
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"html/template"
-	"os"
 	"strings"
-	"time"
-
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 )
 
 // PDFService gère la génération de PDFs
 type PDFService struct {
 	templates *template.Template
+	renderer  *PDFRenderer
+	native    *NativePDFRenderer
 }
 
 // NewPDFService crée une nouvelle instance
@@ -23,8 +19,8 @@ func NewPDFService() *PDFService {
 	// Charger templates (à créer dans templates/cv/)
 	tmpl, err := template.ParseGlob("templates/cv/*.html")
 	if err != nil {
-		// Si templates pas encore créés, utiliser template par défaut
-		tmpl = template.New("cv_base.html")
+		// Si templates pas encore créés : ensemble vide, renderCVHTML utilise le HTML basique
+		tmpl = template.New("cv")
 	}
 
 	return &PDFService{
@@ -32,68 +28,41 @@ func NewPDFService() *PDFService {
 	}
 }
 
-// GenerateCVPDF génère un PDF du CV
-func (s *PDFService) GenerateCVPDF(cv *AdaptiveCVResponse) ([]byte, error) {
-	// 1. Générer HTML depuis template
-	html, err := s.renderCVHTML(cv)
-	if err != nil {
-		return nil, fmt.Errorf("failed to render HTML: %w", err)
-	}
+// SetRenderer partage un renderer PDF (renderer par défaut du processus sinon)
+func (s *PDFService) SetRenderer(renderer *PDFRenderer) {
+	s.renderer = renderer
+}
 
-	// 2. Configure chromedp allocator options for container environment
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.DisableGPU,
-		chromedp.NoSandbox, // Required for running in container as non-root
-		chromedp.Headless,
-		chromedp.Flag("disable-dev-shm-usage", true), // Overcome limited /dev/shm in containers
-		chromedp.Flag("disable-setuid-sandbox", true),
-		chromedp.Flag("single-process", true),
-	)
-
-	// Check for custom Chrome path (Alpine uses chromium-browser)
-	if chromePath := os.Getenv("CHROME_PATH"); chromePath != "" {
-		opts = append(opts, chromedp.ExecPath(chromePath))
-	} else if _, err := os.Stat("/usr/bin/chromium-browser"); err == nil {
-		opts = append(opts, chromedp.ExecPath("/usr/bin/chromium-browser"))
-	}
+// SetNativeRenderer partage le rendu natif (rendu natif par défaut du processus sinon)
+func (s *PDFService) SetNativeRenderer(native *NativePDFRenderer) {
+	s.native = native
+}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer allocCancel()
-
-	// Create browser context
-	ctx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
-
-	// Timeout pour génération PDF
-	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	var pdfBuffer []byte
-
-	if err := chromedp.Run(ctx,
-		chromedp.Navigate("about:blank"),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Injecter HTML encodé en base64
-			encoded := base64.StdEncoding.EncodeToString([]byte(html))
-			script := fmt.Sprintf(`
-				const html = atob('%s');
-				document.open();
-				document.write(html);
-				document.close();
-			`, encoded)
-			return chromedp.Evaluate(script, nil).Do(ctx)
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Générer PDF
-			var err error
-			pdfBuffer, _, err = page.PrintToPDF().Do(ctx)
-			return err
-		}),
-	); err != nil {
-		return nil, fmt.Errorf("chromedp failed: %w", err)
-	}
+// GenerateCVPDF génère un PDF du CV (navigateur si disponible, rendu natif sinon)
+func (s *PDFService) GenerateCVPDF(ctx context.Context, cv *AdaptiveCVResponse) ([]byte, error) {
+	return s.GenerateCVPDFWithEngine(ctx, cv, PDFEngineAuto)
+}
+
+// GenerateCVPDFWithEngine génère un PDF du CV avec le moteur demandé
+func (s *PDFService) GenerateCVPDFWithEngine(ctx context.Context, cv *AdaptiveCVResponse, engine PDFEngine) ([]byte, error) {
+	renderer := rendererOrDefault(s.renderer)
 
-	return pdfBuffer, nil
+	return renderWithEngine(ctx, engine, renderer, func() ([]byte, error) {
+		// 1. Générer HTML depuis template
+		html, err := s.renderCVHTML(cv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render HTML: %w", err)
+		}
+
+		// 2. Convertir en PDF via le navigateur headless partagé
+		pdfBuffer, err := renderer.Render(ctx, "cv", html)
+		if err != nil {
+			return nil, fmt.Errorf("chromedp failed: %w", err)
+		}
+		return pdfBuffer, nil
+	}, func() ([]byte, error) {
+		return nativeOrDefault(s.native).RenderCV(ctx, cv)
+	})
 }
 
 // renderCVHTML génère le HTML du CV depuis template
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"html/template"
 	"strings"
 	"testing"
@@ -42,27 +43,27 @@ func (suite *PDFServiceTestSuite) TestRenderBasicHTML() {
 			Name:        "Backend Developer",
 			Description: "Expert in backend development",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{
 				Title:       "Senior Backend Developer",
 				Company:     "TechCorp",
 				Description: "Building scalable APIs",
 				StartDate:   now.AddDate(-2, 0, 0),
 			},
-		},
-		Skills: []models.Skill{
+		}),
+		Skills: scoredSkills([]models.Skill{
 			{
 				Name:            "Go",
 				Level:           models.SkillLevelExpert,
 				YearsExperience: 5,
 			},
-		},
-		Projects: []models.Project{
+		}),
+		Projects: scoredProjects([]models.Project{
 			{
 				Title:       "maicivy",
 				Description: "Interactive CV with AI",
 			},
-		},
+		}),
 		GeneratedAt: now,
 	}
 
@@ -86,9 +87,9 @@ func (suite *PDFServiceTestSuite) TestRenderBasicHTML_EmptyCV() {
 			Name:        "Full-Stack Developer",
 			Description: "Full-stack expertise",
 		},
-		Experiences: []models.Experience{},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		Experiences: scoredExperiences([]models.Experience{}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -107,16 +108,16 @@ func (suite *PDFServiceTestSuite) TestRenderBasicHTML_CurrentJob() {
 			ID:   "backend",
 			Name: "Backend",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{
 				Title:     "Current Job",
 				Company:   "CurrentCorp",
 				StartDate: time.Now(),
 				EndDate:   nil, // Emploi actuel
 			},
-		},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -133,9 +134,9 @@ func (suite *PDFServiceTestSuite) TestRenderCVHTML_NoTemplate() {
 			ID:   "backend",
 			Name: "Backend Developer",
 		},
-		Experiences: []models.Experience{},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		Experiences: scoredExperiences([]models.Experience{}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -169,11 +170,11 @@ func (suite *PDFServiceTestSuite) TestRenderCVHTML_CustomTemplate() {
 			ID:   "backend",
 			Name: "Backend Dev",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{Title: "Dev", Company: "Corp"},
-		},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -199,7 +200,7 @@ func (suite *PDFServiceTestSuite) TestGenerateCVPDF_Integration() {
 			Name:        "Backend Developer",
 			Description: "Backend expertise",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{
 				Title:        "Backend Dev",
 				Company:      "TechCorp",
@@ -208,27 +209,27 @@ func (suite *PDFServiceTestSuite) TestGenerateCVPDF_Integration() {
 				Technologies: pq.StringArray{"go", "postgresql"},
 				StartDate:    time.Now().AddDate(-2, 0, 0),
 			},
-		},
-		Skills: []models.Skill{
+		}),
+		Skills: scoredSkills([]models.Skill{
 			{
 				Name:            "Go",
 				Level:           models.SkillLevelExpert,
 				Category:        "backend",
 				YearsExperience: 5,
 			},
-		},
-		Projects: []models.Project{
+		}),
+		Projects: scoredProjects([]models.Project{
 			{
 				Title:        "maicivy",
 				Description:  "Interactive CV",
 				Technologies: pq.StringArray{"go", "react"},
 				Category:     "fullstack",
 			},
-		},
+		}),
 		GeneratedAt: time.Now(),
 	}
 
-	pdfBytes, err := suite.service.GenerateCVPDF(cv)
+	pdfBytes, err := suite.service.GenerateCVPDF(context.Background(), cv)
 
 	// Si chromedp n'est pas disponible, erreur attendue
 	if err != nil {
@@ -259,13 +260,13 @@ func (suite *PDFServiceTestSuite) TestGenerateCVPDF_EmptyCV() {
 			ID:   "fullstack",
 			Name: "Full-Stack",
 		},
-		Experiences: []models.Experience{},
-		Skills:      []models.Skill{},
-		Projects:    []models.Project{},
+		Experiences: scoredExperiences([]models.Experience{}),
+		Skills:      scoredSkills([]models.Skill{}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
-	pdfBytes, err := suite.service.GenerateCVPDF(cv)
+	pdfBytes, err := suite.service.GenerateCVPDF(context.Background(), cv)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "chromedp") {
@@ -293,13 +294,13 @@ func BenchmarkRenderBasicHTML(b *testing.B) {
 			ID:   "backend",
 			Name: "Backend Developer",
 		},
-		Experiences: []models.Experience{
+		Experiences: scoredExperiences([]models.Experience{
 			{Title: "Dev", Company: "Corp", StartDate: time.Now()},
-		},
-		Skills: []models.Skill{
+		}),
+		Skills: scoredSkills([]models.Skill{
 			{Name: "Go", Level: models.SkillLevelExpert},
-		},
-		Projects:    []models.Project{},
+		}),
+		Projects:    scoredProjects([]models.Project{}),
 		GeneratedAt: time.Now(),
 	}
 
@@ -308,3 +309,30 @@ func BenchmarkRenderBasicHTML(b *testing.B) {
 		service.renderBasicHTML(cv)
 	}
 }
+
+// scoredExperiences enveloppe des expériences sans score (réponse du CV adaptatif)
+func scoredExperiences(experiences []models.Experience) []ScoredExperienceResponse {
+	scored := make([]ScoredExperienceResponse, 0, len(experiences))
+	for _, experience := range experiences {
+		scored = append(scored, ScoredExperienceResponse{Experience: experience})
+	}
+	return scored
+}
+
+// scoredSkills enveloppe des compétences sans score
+func scoredSkills(skills []models.Skill) []ScoredSkillResponse {
+	scored := make([]ScoredSkillResponse, 0, len(skills))
+	for _, skill := range skills {
+		scored = append(scored, ScoredSkillResponse{Skill: skill})
+	}
+	return scored
+}
+
+// scoredProjects enveloppe des projets sans score
+func scoredProjects(projects []models.Project) []ScoredProjectResponse {
+	scored := make([]ScoredProjectResponse, 0, len(projects))
+	for _, project := range projects {
+		scored = append(scored, ScoredProjectResponse{Project: project})
+	}
+	return scored
+}
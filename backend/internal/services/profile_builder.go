@@ -9,43 +9,58 @@ import (
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 
+	"maicivy/internal/config"
 	"maicivy/internal/models"
 )
 
+const (
+	profileMaxSkills      = 10
+	profileMaxExperiences = 5
+)
+
 // ProfileBuilder construit le profil utilisateur à partir de la base de données
 type ProfileBuilder struct {
-	db *gorm.DB
+	db      *gorm.DB
+	scoring *CVScoringService
 }
 
 // NewProfileBuilder crée une nouvelle instance de ProfileBuilder
 func NewProfileBuilder(db *gorm.DB) *ProfileBuilder {
 	return &ProfileBuilder{
-		db: db,
+		db:      db,
+		scoring: NewCVScoringService(),
 	}
 }
 
 // BuildProfile construit le UserProfile à partir des données en BDD
 func (pb *ProfileBuilder) BuildProfile(ctx context.Context) models.UserProfile {
+	return pb.BuildProfileForTheme(ctx, "")
+}
+
+// BuildProfileForTheme construit le UserProfile en priorisant les expériences et
+// compétences pertinentes pour un thème (même scoring que le CV adaptatif).
+// Un thème vide ou inconnu donne le profil par défaut (skills featured, expériences récentes).
+func (pb *ProfileBuilder) BuildProfileForTheme(ctx context.Context, themeID string) models.UserProfile {
+	return pb.BuildLocalizedProfile(ctx, themeID, models.DefaultLanguage)
+}
+
+// BuildLocalizedProfile construit le UserProfile d'un thème dans la langue de la lettre
+// (traductions des expériences et compétences, résumé et durées localisés)
+func (pb *ProfileBuilder) BuildLocalizedProfile(ctx context.Context, themeID string, lang models.Language) models.UserProfile {
+	theme := config.GetTheme(themeID)
+	locale := localeFor(lang)
+
 	// 1. Récupérer l'expérience la plus récente pour le CurrentRole
 	var latestExperience models.Experience
 	result := pb.db.Order("start_date DESC").First(&latestExperience)
 
 	currentRole := "Développeur Full-Stack" // Fallback
 	if result.Error == nil {
-		currentRole = latestExperience.Title
+		currentRole = latestExperience.Localize(lang).Title
 	}
 
-	// 2. Récupérer les skills featured (max 10)
-	var skills []models.Skill
-	pb.db.Where("featured = ?", true).
-		Order("years_experience DESC").
-		Limit(10).
-		Find(&skills)
-
-	skillNames := make([]string, len(skills))
-	for i, skill := range skills {
-		skillNames[i] = skill.Name
-	}
+	// 2. Récupérer les skills (featured par défaut, triées par pertinence si thème)
+	skillNames := pb.selectSkills(theme, lang)
 
 	// Fallback si pas de skills
 	if len(skillNames) == 0 {
@@ -77,13 +92,13 @@ func (pb *ProfileBuilder) BuildProfile(ctx context.Context) models.UserProfile {
 		}
 	}
 
-	// 4. Récupérer les expériences détaillées (max 5 plus récentes)
-	var experiences []models.Experience
-	pb.db.Order("start_date DESC").Limit(5).Find(&experiences)
+	// 4. Récupérer les expériences détaillées (max 5, les plus pertinentes en premier)
+	experiences := pb.selectExperiences(theme)
 
 	experienceDetails := make([]models.ExperienceDetail, 0, len(experiences))
 	for _, exp := range experiences {
-		duration := formatDuration(exp.StartDate, exp.EndDate)
+		exp = exp.Localize(lang)
+		duration := locale.formatDuration(exp.StartDate, exp.EndDate)
 
 		// Extraire les highlights de la description (phrases séparées par des points)
 		highlights := extractHighlights(exp.Description)
@@ -98,7 +113,7 @@ func (pb *ProfileBuilder) BuildProfile(ctx context.Context) models.UserProfile {
 	}
 
 	// 5. Construire un résumé professionnel
-	summary := pb.buildSummary(currentRole, yearsOfExperience, skillNames)
+	summary := pb.buildSummary(locale, currentRole, yearsOfExperience, skillNames)
 
 	profile := models.UserProfile{
 		Name:        "Alexis Trouve",
@@ -116,6 +131,8 @@ func (pb *ProfileBuilder) BuildProfile(ctx context.Context) models.UserProfile {
 
 	log.Info().
 		Str("name", profile.Name).
+		Str("theme", themeID).
+		Str("language", string(lang)).
 		Str("role", profile.CurrentRole).
 		Int("skills_count", len(profile.Skills)).
 		Int("experience_years", profile.Experience).
@@ -125,13 +142,74 @@ func (pb *ProfileBuilder) BuildProfile(ctx context.Context) models.UserProfile {
 	return profile
 }
 
-// formatDuration formate la durée d'une expérience
-func formatDuration(start time.Time, end *time.Time) string {
-	startYear := start.Year()
-	if end == nil {
-		return fmt.Sprintf("%d - présent", startYear)
+// selectSkills retourne les noms (traduits) des skills à mettre en avant
+func (pb *ProfileBuilder) selectSkills(theme *config.CVTheme, lang models.Language) []string {
+	var featured []models.Skill
+	pb.db.Where("featured = ?", true).
+		Order("years_experience DESC").
+		Limit(profileMaxSkills).
+		Find(&featured)
+
+	if theme == nil {
+		names := make([]string, len(featured))
+		for i, skill := range featured {
+			names[i] = skill.Localize(lang).Name
+		}
+		return names
+	}
+
+	// Skills pertinentes pour le thème d'abord, complétées par les featured
+	var all []models.Skill
+	pb.db.Find(&all)
+
+	names := make([]string, 0, profileMaxSkills)
+	seen := make(map[string]bool)
+	add := func(skill models.Skill) {
+		if len(names) < profileMaxSkills && !seen[skill.Name] {
+			seen[skill.Name] = true
+			names = append(names, skill.Localize(lang).Name)
+		}
+	}
+
+	for _, scored := range pb.scoring.ScoreSkills(all, theme) {
+		add(scored.Skill)
+	}
+	for _, skill := range featured {
+		add(skill)
+	}
+
+	return names
+}
+
+// selectExperiences retourne les expériences à détailler dans le prompt
+func (pb *ProfileBuilder) selectExperiences(theme *config.CVTheme) []models.Experience {
+	if theme == nil {
+		var experiences []models.Experience
+		pb.db.Order("start_date DESC").Limit(profileMaxExperiences).Find(&experiences)
+		return experiences
 	}
-	return fmt.Sprintf("%d - %d", startYear, end.Year())
+
+	var all []models.Experience
+	pb.db.Order("start_date DESC").Find(&all)
+
+	// Expériences pertinentes pour le thème d'abord, puis les plus récentes
+	selected := make([]models.Experience, 0, profileMaxExperiences)
+	seen := make(map[string]bool)
+	add := func(exp models.Experience) {
+		if len(selected) < profileMaxExperiences && !seen[exp.ID.String()] {
+			seen[exp.ID.String()] = true
+			selected = append(selected, exp)
+		}
+	}
+
+	for _, scored := range pb.scoring.ScoreExperiences(all, theme) {
+		add(scored.Experience)
+	}
+	for _, exp := range all {
+		add(exp)
+	}
+
+	return selected
 }
 
 // extractHighlights extrait les points clés d'une description
@@ -162,14 +240,14 @@ func extractHighlights(description string) []string {
 }
 
 // buildSummary construit un résumé professionnel
-func (pb *ProfileBuilder) buildSummary(role string, years int, skills []string) string {
+func (pb *ProfileBuilder) buildSummary(locale *letterLocale, role string, years int, skills []string) string {
 	topSkills := skills
 	if len(topSkills) > 5 {
 		topSkills = topSkills[:5]
 	}
 
 	return fmt.Sprintf(
-		"%s avec %d ans d'expérience, spécialisé en %s",
+		locale.summary,
 		role,
 		years,
 		strings.Join(topSkills, ", "),
@@ -3,11 +3,128 @@ package services
 import (
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
+	"maicivy/internal/config"
 	"maicivy/internal/models"
 )
 
+// PromptOptions : ciblage optionnel de la lettre
+type PromptOptions struct {
+	JobTitle   string             // Poste visé (vide = candidature spontanée)
+	Theme      string             // Thème CV (backend, devops...) mis en avant
+	JobPosting *models.JobPosting // Offre d'emploi analysée (optionnel)
+	Language   models.Language    // Langue de rédaction (vide = français)
+}
+
+// locale retourne les textes de la langue de rédaction
+func (o PromptOptions) locale() *letterLocale {
+	return localeFor(o.Language)
+}
+
+// subject retourne la ligne d'objet de la lettre de motivation
+func (o PromptOptions) subject() string {
+	if o.JobTitle == "" {
+		return o.locale().spontaneousSubject
+	}
+	return fmt.Sprintf(o.locale().jobSubject, o.JobTitle)
+}
+
+// antiSubject retourne la ligne d'objet de la lettre d'anti-motivation
+func (o PromptOptions) antiSubject() string {
+	return fmt.Sprintf(o.locale().antiSubject, jobSentence(o.JobTitle, o.locale().jobSuffix))
+}
+
+// followUpSubject retourne l'objet de l'e-mail de relance
+func (o PromptOptions) followUpSubject() string {
+	return fmt.Sprintf(o.locale().followUpSubject, jobSentence(o.JobTitle, o.locale().jobSuffix))
+}
+
+// thankYouSubject retourne l'objet du message de remerciement
+func (o PromptOptions) thankYouSubject() string {
+	return fmt.Sprintf(o.locale().thankYouSubject, jobSentence(o.JobTitle, o.locale().jobSuffix))
+}
+
+// targetSection décrit le poste et l'axe du profil à privilégier
+func (o PromptOptions) targetSection() string {
+	l := o.locale()
+
+	var sb strings.Builder
+	if o.JobTitle != "" {
+		sb.WriteString(fmt.Sprintf(l.targetJob, o.JobTitle))
+	} else {
+		sb.WriteString(l.targetNoJob)
+	}
+	if theme := config.GetTheme(o.Theme); theme != nil {
+		sb.WriteString(fmt.Sprintf(l.targetTheme, theme.Name, theme.Description))
+		sb.WriteString(l.targetThemeNote)
+	}
+	if o.hasPosting() {
+		sb.WriteString(l.postingLabel)
+		writePromptList(&sb, l.missionsLabel, o.JobPosting.Responsibilities)
+		writePromptList(&sb, l.requirementsLabel, o.JobPosting.Requirements)
+		if len(o.JobPosting.TechKeywords) > 0 {
+			sb.WriteString(fmt.Sprintf(l.technologiesLine, strings.Join(o.JobPosting.TechKeywords, ", ")))
+		}
+	}
+	return sb.String()
+}
+
+// hasPosting indique si une offre exploitable est disponible
+func (o PromptOptions) hasPosting() bool {
+	return o.JobPosting != nil && !o.JobPosting.IsEmpty()
+}
+
+// motivationFocus : consignes supplémentaires de la tâche (lettre de motivation)
+func (o PromptOptions) motivationFocus() string {
+	focus := jobSentence(o.JobTitle, o.locale().motivationJobFocus)
+	if o.hasPosting() {
+		focus += o.locale().motivationPostingFocus
+	}
+	return focus
+}
+
+// antiMotivationFocus : consignes supplémentaires de la tâche (lettre d'anti-motivation)
+func (o PromptOptions) antiMotivationFocus() string {
+	focus := jobSentence(o.JobTitle, o.locale().antiJobFocus)
+	if o.hasPosting() {
+		focus += o.locale().antiPostingFocus
+	}
+	return focus
+}
+
+// writePromptList ajoute une liste à puces indentée au prompt
+func writePromptList(sb *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("  %s:\n", label))
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("   • %s\n", item))
+	}
+}
+
+// promptData : données injectées dans les templates de prompts
+type promptData struct {
+	Name         string
+	Address      string
+	Locality     string // Code postal et ville dans l'ordre de la langue
+	Email        string
+	Phone        string
+	Summary      string
+	CurrentRole  string
+	Years        int
+	Skills       string
+	Experiences  string
+	Company      models.CompanyInfo
+	Technologies string
+	Target       string
+	Date         string
+	Focus        string
+	Subject      string
+}
+
 type PromptBuilder struct {
 	userProfile models.UserProfile
 }
@@ -17,102 +134,73 @@ func NewPromptBuilder(profile models.UserProfile) *PromptBuilder {
 }
 
 // BuildMotivationPrompt : prompt pour lettre de motivation professionnelle
-func (pb *PromptBuilder) BuildMotivationPrompt(company models.CompanyInfo) string {
-	// Construire la section expériences détaillées
-	experiencesSection := pb.buildExperiencesSection()
-
-	// Formater la date en français (ex: "Tourtenay, le 5 janvier 2026")
-	currentDate := formatFrenchDate(time.Now())
-
-	template := `Tu es un expert en rédaction de lettres de motivation professionnelles.
-
-PROFIL DU CANDIDAT:
-- Nom: %s
-- Adresse: %s
-- Code postal, Ville: %s %s
-- Email: %s
-- Téléphone: %s
-- Résumé: %s
-- Poste actuel: %s
-- Années d'expérience: %d ans
-- Compétences clés: %s
-
-PARCOURS PROFESSIONNEL DÉTAILLÉ:
-%s
-
-ENTREPRISE CIBLE:
-- Nom: %s
-- Secteur: %s
-- Description: %s
-- Technologies utilisées: %s
-- Taille: %s
-
-DATE DU JOUR (pour la lettre):
-%s
-
-TÂCHE:
-Rédige une lettre de motivation professionnelle, convaincante et authentique pour postuler chez %s.
-
-INSTRUCTIONS:
-1. COMMENCE OBLIGATOIREMENT par l'en-tête complet au format français classique (aligné à gauche):
-   - Nom complet du candidat
-   - Adresse
-   - Code postal et ville
-   - Email
-   - Téléphone
-   - Ligne vide
-   - Date du jour (utilise celle fournie ci-dessus)
-   - Ligne vide
-   - Nom de l'entreprise
-   - [Adresse si connue, sinon laisser vide]
-   - Ligne vide
-   - "Objet : Candidature spontanée"
-   - Ligne vide
-
-2. Structure classique ensuite (introduction, corps, conclusion)
-3. Ton professionnel mais pas rigide
-4. UTILISE des exemples CONCRETS du parcours du candidat (projets, achievements, métriques)
-5. Mets en avant l'alignement entre les compétences du candidat et les besoins probables de l'entreprise
-6. Montre un intérêt sincère pour l'entreprise (culture, projets, technologies)
-7. Cite des réalisations spécifiques avec des chiffres quand disponibles
-8. Longueur: 350-450 mots (sans compter l'en-tête)
-9. Format: paragraphes bien structurés (pas de bullet points)
-10. TERMINE par "Cordialement," suivi du nom du candidat
-
-EXEMPLES DE BON STYLE:
-- "Chez [entreprise précédente], j'ai [réalisation concrète avec métrique], ce qui m'a préparé à..."
-- "Mon expérience en [technologie] où j'ai [achievement] correspond parfaitement à vos besoins en..."
-
-N'invente PAS de faits sur l'entreprise. Utilise les informations du parcours du candidat.
-
-Génère la lettre maintenant (AVEC l'en-tête complet):`
-
-	return fmt.Sprintf(
-		template,
-		pb.userProfile.Name,
-		pb.userProfile.Address,
-		pb.userProfile.PostalCode, pb.userProfile.City,
-		pb.userProfile.Email,
-		pb.userProfile.Phone,
-		pb.userProfile.Summary,
-		pb.userProfile.CurrentRole,
-		pb.userProfile.Experience,
-		strings.Join(pb.userProfile.Skills, ", "),
-		experiencesSection,
-		company.Name,
-		company.Industry,
-		company.Description,
-		strings.Join(company.Technologies, ", "),
-		company.Size,
-		currentDate,
-		company.Name,
-	)
+func (pb *PromptBuilder) BuildMotivationPrompt(company models.CompanyInfo, opts PromptOptions) string {
+	return pb.BuildLetterPrompt(letterTypeSpecs[models.LetterTypeMotivation], company, opts)
+}
+
+// BuildLetterPrompt : prompt d'un type de lettre du registre, dans la langue de opts
+func (pb *PromptBuilder) BuildLetterPrompt(spec *LetterTypeSpec, company models.CompanyInfo, opts PromptOptions) string {
+	data := pb.promptData(company, opts)
+	if spec.focus != nil {
+		data.Focus = spec.focus(opts)
+	}
+	data.Subject = spec.Subject(opts)
+	return renderPrompt(spec.template(opts.locale()), data)
+}
+
+// promptData rassemble le profil, l'entreprise et la cible dans la langue de la lettre
+func (pb *PromptBuilder) promptData(company models.CompanyInfo, opts PromptOptions) promptData {
+	l := opts.locale()
+	return promptData{
+		Name:         pb.userProfile.Name,
+		Address:      pb.userProfile.Address,
+		Locality:     l.formatLocality(pb.userProfile.PostalCode, pb.userProfile.City),
+		Email:        pb.userProfile.Email,
+		Phone:        pb.userProfile.Phone,
+		Summary:      pb.userProfile.Summary,
+		CurrentRole:  pb.userProfile.CurrentRole,
+		Years:        pb.userProfile.Experience,
+		Skills:       strings.Join(pb.userProfile.Skills, ", "),
+		Experiences:  pb.buildExperiencesSection(l),
+		Company:      company,
+		Technologies: companyTechnologies(company),
+		Target:       opts.targetSection(),
+		Date:         l.formatLetterDate(pb.userProfile.City, time.Now()),
+	}
+}
+
+// companyTechnologies technologies de l'entreprise, avec leur catégorie quand elles ont été
+// détectées sur le site ("React (JavaScript frameworks), Nginx (Web servers)")
+func companyTechnologies(company models.CompanyInfo) string {
+	categories := make(map[string][]string, len(company.TechStack))
+	for _, tech := range company.TechStack {
+		categories[tech.Name] = tech.Categories
+	}
+
+	technologies := make([]string, len(company.Technologies))
+	for i, name := range company.Technologies {
+		technologies[i] = name
+		if cats := categories[name]; len(cats) > 0 {
+			technologies[i] = fmt.Sprintf("%s (%s)", name, strings.Join(cats, ", "))
+		}
+	}
+	return strings.Join(technologies, ", ")
+}
+
+// renderPrompt exécute un template de prompt
+// Les templates sont compilés au démarrage : une erreur ici est un bug de template.
+func renderPrompt(tmpl *template.Template, data interface{}) string {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		panic(fmt.Sprintf("prompt template %s: %v", tmpl.Name(), err))
+	}
+	return sb.String()
 }
 
 // buildExperiencesSection construit la section des expériences pour le prompt
-func (pb *PromptBuilder) buildExperiencesSection() string {
+func (pb *PromptBuilder) buildExperiencesSection(l *letterLocale) string {
 	if len(pb.userProfile.Experiences) == 0 {
-		return "Aucune expérience détaillée disponible."
+		return l.noExperiences
 	}
 
 	var sb strings.Builder
@@ -129,122 +217,14 @@ func (pb *PromptBuilder) buildExperiencesSection() string {
 }
 
 // BuildAntiMotivationPrompt : prompt pour lettre d'anti-motivation humoristique
-func (pb *PromptBuilder) BuildAntiMotivationPrompt(company models.CompanyInfo) string {
-	// Construire la section expériences pour l'humour
-	experiencesSection := pb.buildExperiencesSection()
-
-	// Formater la date en français
-	currentDate := formatFrenchDate(time.Now())
-
-	template := `Tu es un humoriste spécialisé en rédaction de lettres d'anti-motivation créatives et absurdes.
-
-PROFIL DU CANDIDAT (à détourner avec humour):
-- Nom: %s
-- Adresse: %s
-- Code postal, Ville: %s %s
-- Email: %s
-- Téléphone: %s
-- Poste actuel: %s
-- Années d'expérience: %d ans
-- Compétences clés: %s
-
-VRAI PARCOURS (à parodier):
-%s
-
-ENTREPRISE CIBLE:
-- Nom: %s
-- Secteur: %s
-- Description: %s
-
-DATE DU JOUR (pour la lettre):
-%s
-
-TÂCHE:
-Rédige une lettre d'ANTI-MOTIVATION humoristique expliquant pourquoi %s ne devrait SURTOUT PAS être embauché chez %s.
-
-STYLE ET TON:
-- Humour absurde et auto-dérision
-- Deuxième degré évident (personne ne doit prendre ça au sérieux)
-- DÉTOURNE les vraies compétences/expériences du candidat de manière comique
-- Références pop culture, jeux de mots, exagérations comiques
-- Ton léger, jamais méchant ou offensant envers l'entreprise
-
-INSTRUCTIONS:
-1. COMMENCE OBLIGATOIREMENT par l'en-tête complet au format français classique - MÊME POUR L'ANTI-MOTIVATION:
-   - Nom complet du candidat
-   - Adresse
-   - Code postal et ville
-   - Email
-   - Téléphone
-   - Ligne vide
-   - Date du jour (utilise celle fournie ci-dessus)
-   - Ligne vide
-   - Nom de l'entreprise
-   - [Adresse si connue, sinon laisser vide]
-   - Ligne vide
-   - "Objet : Lettre d'anti-motivation (humour au second degré)"
-   - Ligne vide
-
-2. Structure libre ensuite (sois créatif !)
-3. PARODIE les vraies expériences du candidat (ex: "J'ai réduit la latence de 70%%... en supprimant les features")
-4. Transforme les achievements en "anti-achievements" hilarants
-5. Fausses compétences inutiles basées sur les vraies
-6. Anecdotes absurdes liées au vrai parcours
-7. Conclusion ironique inversée
-8. Longueur: 300-400 mots (sans l'en-tête)
-9. Évite l'humour vulgaire ou offensant
-10. TERMINE de façon absurde mais avec "Cordialement (ou pas)," + nom
-
-EXEMPLES DE STYLE BASÉS SUR LE VRAI PARCOURS:
-- "Mon expertise en 'high-performance REST APIs' signifie que je sais faire crasher 100K requêtes/jour avec style..."
-- "J'ai 'mentoré 4 développeurs juniors'... dans l'art subtil de la procrastination professionnelle..."
-- "Mon '99.9%% uptime SLA' cache les 0.1%% où j'ai paniqué devant mon écran..."
-
-RAPPEL: C'est de l'humour ! Utilise le VRAI parcours pour créer des parodies personnalisées.
-
-Génère la lettre maintenant (AVEC l'en-tête complet):`
-
-	return fmt.Sprintf(
-		template,
-		pb.userProfile.Name,
-		pb.userProfile.Address,
-		pb.userProfile.PostalCode, pb.userProfile.City,
-		pb.userProfile.Email,
-		pb.userProfile.Phone,
-		pb.userProfile.CurrentRole,
-		pb.userProfile.Experience,
-		strings.Join(pb.userProfile.Skills, ", "),
-		experiencesSection,
-		company.Name,
-		company.Industry,
-		company.Description,
-		currentDate,
-		pb.userProfile.Name,
-		company.Name,
-	)
-}
-
-// formatFrenchDate formate une date au format français pour les lettres
-// Ex: "Tourtenay, le 5 janvier 2026"
-func formatFrenchDate(t time.Time) string {
-	frenchMonths := map[time.Month]string{
-		time.January:   "janvier",
-		time.February:  "février",
-		time.March:     "mars",
-		time.April:     "avril",
-		time.May:       "mai",
-		time.June:      "juin",
-		time.July:      "juillet",
-		time.August:    "août",
-		time.September: "septembre",
-		time.October:   "octobre",
-		time.November:  "novembre",
-		time.December:  "décembre",
-	}
-
-	return fmt.Sprintf("Tourtenay, le %d %s %d",
-		t.Day(),
-		frenchMonths[t.Month()],
-		t.Year(),
-	)
+func (pb *PromptBuilder) BuildAntiMotivationPrompt(company models.CompanyInfo, opts PromptOptions) string {
+	return pb.BuildLetterPrompt(letterTypeSpecs[models.LetterTypeAntiMotivation], company, opts)
+}
+
+// jobSentence insère le poste dans format, ou rien si aucun poste n'est visé
+func jobSentence(jobTitle, format string) string {
+	if jobTitle == "" {
+		return ""
+	}
+	return fmt.Sprintf(format, jobTitle)
 }
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"maicivy/internal/models"
+)
+
+func newTestPromptBuilder() *PromptBuilder {
+	return NewPromptBuilder(models.UserProfile{
+		Name:        "Jean Dupont",
+		CurrentRole: "Développeur Backend",
+		Skills:      []string{"Go", "PostgreSQL"},
+		Experience:  5,
+	})
+}
+
+func TestBuildMotivationPrompt_JobTitle(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme"}
+
+	prompt := pb.BuildMotivationPrompt(company, PromptOptions{JobTitle: "Ingénieur Backend Go"})
+	assert.Contains(t, prompt, `"Objet : Candidature au poste de Ingénieur Backend Go"`)
+	assert.Contains(t, prompt, "- Poste visé: Ingénieur Backend Go")
+	assert.Contains(t, prompt, "fait pour le poste de Ingénieur Backend Go")
+	assert.NotContains(t, prompt, "Candidature spontanée")
+
+	// Sans poste : candidature spontanée
+	prompt = pb.BuildMotivationPrompt(company, PromptOptions{})
+	assert.Contains(t, prompt, `"Objet : Candidature spontanée"`)
+	assert.NotContains(t, prompt, "%!")
+}
+
+func TestBuildMotivationPrompt_Theme(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme"}
+
+	prompt := pb.BuildMotivationPrompt(company, PromptOptions{Theme: "backend"})
+	assert.Contains(t, prompt, "- Axe du profil: Backend Developer")
+
+	// Thème inconnu ignoré
+	prompt = pb.BuildMotivationPrompt(company, PromptOptions{Theme: "unknown"})
+	assert.NotContains(t, prompt, "Axe du profil")
+}
+
+func TestBuildAntiMotivationPrompt_JobTitle(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme"}
+
+	prompt := pb.BuildAntiMotivationPrompt(company, PromptOptions{JobTitle: "SRE"})
+	assert.Contains(t, prompt, `"Objet : Lettre d'anti-motivation pour le poste de SRE (humour au second degré)"`)
+	assert.Contains(t, prompt, "Tourne en dérision le poste de SRE")
+
+	prompt = pb.BuildAntiMotivationPrompt(company, PromptOptions{})
+	assert.Contains(t, prompt, `"Objet : Lettre d'anti-motivation (humour au second degré)"`)
+	assert.NotContains(t, prompt, "%!")
+}
+
+func TestBuildMotivationPrompt_JobPosting(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme"}
+	posting := &models.JobPosting{
+		Responsibilities: []string{"Concevoir des APIs"},
+		Requirements:     []string{"3 ans d'expérience en Go"},
+		TechKeywords:     []string{"Go", "PostgreSQL"},
+	}
+
+	prompt := pb.BuildMotivationPrompt(company, PromptOptions{JobTitle: "Développeur Go", JobPosting: posting})
+	assert.Contains(t, prompt, "- Offre d'emploi:")
+	assert.Contains(t, prompt, "   • Concevoir des APIs")
+	assert.Contains(t, prompt, "   • 3 ans d'expérience en Go")
+	assert.Contains(t, prompt, "Technologies citées: Go, PostgreSQL")
+	assert.Contains(t, prompt, "Réponds aux missions et exigences principales de l'offre")
+
+	// Offre vide : aucune section
+	prompt = pb.BuildMotivationPrompt(company, PromptOptions{JobPosting: &models.JobPosting{}})
+	assert.NotContains(t, prompt, "Offre d'emploi")
+}
+
+func TestBuildPrompts_AllLanguagesRender(t *testing.T) {
+	pb := newTestPromptBuilder()
+	company := models.CompanyInfo{Name: "Acme"}
+	opts := PromptOptions{JobTitle: "SRE", Theme: "backend", JobPosting: &models.JobPosting{Requirements: []string{"Linux"}}}
+
+	for _, lang := range models.SupportedLanguages {
+		t.Run(string(lang), func(t *testing.T) {
+			opts.Language = lang
+			for _, prompt := range []string{
+				pb.BuildMotivationPrompt(company, opts),
+				pb.BuildAntiMotivationPrompt(company, opts),
+			} {
+				assert.Contains(t, prompt, "Jean Dupont")
+				assert.Contains(t, prompt, "Acme")
+				assert.Contains(t, prompt, "Linux")
+				assert.NotContains(t, prompt, "<no value>")
+				assert.NotContains(t, prompt, "%!")
+			}
+		})
+	}
+}
+
+func TestBuildMotivationPrompt_English(t *testing.T) {
+	pb := NewPromptBuilder(models.UserProfile{
+		Name:       "Jean Dupont",
+		PostalCode: "79100",
+		City:       "Tourtenay",
+	})
+	company := models.CompanyInfo{Name: "Acme"}
+
+	prompt := pb.BuildMotivationPrompt(company, PromptOptions{JobTitle: "Backend Engineer", Language: models.LanguageEnglish})
+	assert.Contains(t, prompt, `"Subject: Application for the position of Backend Engineer"`)
+	assert.Contains(t, prompt, "- Town, Postcode: Tourtenay 79100")
+	assert.Contains(t, prompt, "TARGET COMPANY:\n- Name: Acme")
+	assert.Contains(t, prompt, `"Kind regards,"`)
+	assert.NotContains(t, prompt, "Candidature")
+
+	prompt = pb.BuildAntiMotivationPrompt(company, PromptOptions{Language: models.LanguageGerman})
+	assert.Contains(t, prompt, `"Anti-Motivationsschreiben (mit einem Augenzwinkern)"`)
+	assert.Contains(t, prompt, "- PLZ, Ort: 79100 Tourtenay")
+}
+
+func TestLetterLocale_Dates(t *testing.T) {
+	date := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		lang     models.Language
+		expected string
+	}{
+		{models.LanguageFrench, "Tourtenay, le 5 mars 2026"},
+		{models.LanguageEnglish, "5 March 2026"},
+		{models.LanguageGerman, "Tourtenay, 5. März 2026"},
+		{models.LanguageSpanish, "Tourtenay, 5 de marzo de 2026"},
+		{"it", "Tourtenay, le 5 mars 2026"}, // Non supportée : français
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.lang), func(t *testing.T) {
+			assert.Equal(t, tc.expected, localeFor(tc.lang).formatLetterDate("Tourtenay", date))
+		})
+	}
+
+	// Sans ville : date seule
+	assert.Equal(t, "5 mars 2026", localeFor(models.LanguageFrench).formatLetterDate("", date))
+}
+
+func TestBuildRevisionPrompt_AllLanguages(t *testing.T) {
+	for _, lang := range models.SupportedLanguages {
+		t.Run(string(lang), func(t *testing.T) {
+			letter := &models.GeneratedLetter{
+				CompanyName: "Acme",
+				LetterType:  models.LetterTypeMotivation,
+				Content:     "Madame, Monsieur,\n\nJe postule chez Acme.",
+				Language:    lang,
+			}
+
+			prompt := BuildRevisionPrompt(letter, "Mentionner Kubernetes")
+			assert.Contains(t, prompt, letter.Content)
+			assert.Contains(t, prompt, "Mentionner Kubernetes")
+			assert.NotContains(t, prompt, "<no value>")
+
+			// Le fournisseur fake retrouve l'entreprise quelle que soit la langue
+			assert.Contains(t, fakeLetter(prompt), "Acme")
+		})
+	}
+}
+
+func TestBuildRevisionPrompt_Tone(t *testing.T) {
+	letter := &models.GeneratedLetter{CompanyName: "Acme", LetterType: models.LetterTypeAntiMotivation}
+
+	prompt := BuildRevisionPrompt(letter, "Plus absurde")
+	assert.Contains(t, prompt, "d'anti-motivation humoristiques")
+	assert.Contains(t, prompt, "Garde le ton humoristique et absurde")
+
+	letter.LetterType = models.LetterTypeMotivation
+	letter.Language = models.LanguageEnglish
+	prompt = BuildRevisionPrompt(letter, "Shorter")
+	assert.Contains(t, prompt, "professional cover letters")
+	assert.Contains(t, prompt, "Keep the professional tone")
+}
@@ -7,11 +7,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
@@ -23,189 +23,123 @@ type CompanyScraper struct {
 	config      *config.ScraperConfig
 	redisClient *redis.Client
 	httpClient  *http.Client
+	sources     []companySourceEntry // Triées par priorité
+	directory   CompanyDirectory     // Résolution des noms et fiches curées (optionnel)
+
+	fingerprinter *TechFingerprinter // Détection des technologies du site (optionnel)
+
+	allowPrivateHosts bool // Tests uniquement : autorise les URLs locales (httptest)
 }
 
 func NewCompanyScraper(cfg *config.ScraperConfig, redis *redis.Client) *CompanyScraper {
-	return &CompanyScraper{
+	s := &CompanyScraper{
 		config:      cfg,
 		redisClient: redis,
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
 	}
-}
+	s.registerDefaultSources()
 
-// GetCompanyInfo : point d'entrée principal - multi-sources pour résilience
-func (s *CompanyScraper) GetCompanyInfo(ctx context.Context, companyName string) (*models.CompanyInfo, error) {
-	// 1. Check cache Redis
-	cacheKey := fmt.Sprintf("company_info:%s", strings.ToLower(companyName))
-	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
-	if err == nil {
-		var info models.CompanyInfo
-		if json.Unmarshal([]byte(cached), &info) == nil {
-			log.Info().Str("company", companyName).Msg("Company info found in cache")
-			return &info, nil
+	if cfg.TechFingerprintsPath != "" {
+		fingerprinter, err := LoadTechFingerprints(cfg.TechFingerprintsPath)
+		if err != nil {
+			log.Warn().Err(err).Str("path", cfg.TechFingerprintsPath).Msg("Tech fingerprinting disabled")
+		} else {
+			s.fingerprinter = fingerprinter
 		}
 	}
+	return s
+}
 
-	// 2. Lancer toutes les sources en parallèle
-	info := &models.CompanyInfo{
-		Name:   companyName,
-		Domain: s.guessDomainFromName(companyName),
-	}
+// SetTechFingerprinter remplace les règles de détection des technologies (nil = désactivée)
+func (s *CompanyScraper) SetTechFingerprinter(fingerprinter *TechFingerprinter) {
+	s.fingerprinter = fingerprinter
+}
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+// CompanyDirectory base des entreprises (services.CompanyService)
+type CompanyDirectory interface {
+	ResolveOrCreateCompany(ctx context.Context, name string) (*models.Company, error)
+}
 
-	// Source 1: Wikipedia
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		wikiInfo, err := s.fetchFromWikipedia(ctx, companyName)
-		if err != nil {
-			log.Debug().Err(err).Str("company", companyName).Msg("Wikipedia fetch failed")
-			return
-		}
-		mu.Lock()
-		defer mu.Unlock()
-		if info.Description == "" && wikiInfo.Description != "" {
-			info.Description = wikiInfo.Description
-			log.Info().Str("company", companyName).Str("source", "wikipedia").Msg("Got description")
-		}
-		if info.Industry == "" && wikiInfo.Industry != "" {
-			info.Industry = wikiInfo.Industry
-		}
-		if info.Size == "" && wikiInfo.Size != "" {
-			info.Size = wikiInfo.Size
-		}
-	}()
+// SetCompanyDirectory active la résolution des noms : variantes d'un même nom partagent
+// une entreprise canonique (cache, domaine confirmé) et les fiches curées priment sur le scraping
+func (s *CompanyScraper) SetCompanyDirectory(directory CompanyDirectory) {
+	s.directory = directory
+}
 
-	// Source 2: DuckDuckGo
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		ddgDesc, err := s.fetchFromDuckDuckGo(ctx, companyName)
-		if err != nil {
-			log.Debug().Err(err).Str("company", companyName).Msg("DuckDuckGo fetch failed")
-			return
-		}
-		mu.Lock()
-		defer mu.Unlock()
-		if info.Description == "" && ddgDesc != "" {
-			info.Description = ddgDesc
-			log.Info().Str("company", companyName).Str("source", "duckduckgo").Msg("Got description")
-		}
-	}()
+// InvalidateCompanyInfo supprime du cache les données scrapées d'une entreprise canonique
+// À appeler quand son domaine change (fiche curée, domaine confirmé).
+func (s *CompanyScraper) InvalidateCompanyInfo(ctx context.Context, companyID uuid.UUID) error {
+	return s.redisClient.Del(ctx, companyInfoCacheKey(companyID.String())).Err()
+}
 
-	// Source 3: Scraping website
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		scrapedInfo, err := s.scrapeCompanyWebsite(ctx, companyName)
-		if err != nil {
-			log.Debug().Err(err).Str("company", companyName).Msg("Website scraping failed")
-			return
-		}
-		mu.Lock()
-		defer mu.Unlock()
-		if info.Description == "" && scrapedInfo.Description != "" {
-			info.Description = scrapedInfo.Description
-			log.Info().Str("company", companyName).Str("source", "scraping").Msg("Got description")
-		}
-		if len(info.Technologies) == 0 && len(scrapedInfo.Technologies) > 0 {
-			info.Technologies = scrapedInfo.Technologies
-		}
-	}()
+// companyInfoCacheKey clé de cache : identifiant canonique, ou nom sans résolution
+func companyInfoCacheKey(key string) string {
+	return fmt.Sprintf("company_info:%s", strings.ToLower(key))
+}
 
-	// Source 4: GitHub - projets open-source
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		repos, err := s.fetchFromGitHub(ctx, companyName)
-		if err != nil {
-			log.Debug().Err(err).Str("company", companyName).Msg("GitHub fetch failed")
-			return
-		}
-		mu.Lock()
-		defer mu.Unlock()
-		if repos != "" {
-			info.RecentNews = repos // On utilise RecentNews pour les projets GitHub
-			log.Info().Str("company", companyName).Str("source", "github").Msg("Got open-source projects")
-		}
-	}()
+// GetCompanyInfo : point d'entrée principal - multi-sources pour résilience
+// Chaque champ est pris à la source la plus fiable et sa provenance est conservée.
+// Le nom est d'abord résolu en entreprise canonique, dont l'identifiant sert de clé de cache.
+// Une fiche curée prime sur les données scrapées ; seules ces dernières sont mises en cache,
+// pour qu'une modification de la fiche s'applique immédiatement.
+func (s *CompanyScraper) GetCompanyInfo(ctx context.Context, companyName string) (*models.CompanyInfo, error) {
+	return s.GetCompanyInfoForDomain(ctx, companyName, "")
+}
 
-	// Source 5: Blog/Newsroom RSS
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		news, err := s.fetchRecentNews(ctx, companyName)
-		if err != nil {
-			log.Debug().Err(err).Str("company", companyName).Msg("News fetch failed")
-			return
-		}
-		mu.Lock()
-		defer mu.Unlock()
-		if news != "" {
-			if info.RecentNews != "" {
-				info.RecentNews += "\n\n" + news
-			} else {
-				info.RecentNews = news
+// GetCompanyInfoForDomain comme GetCompanyInfo, avec le domaine choisi par le visiteur
+// Ce domaine ne remplace que le domaine deviné : le domaine connu de l'entreprise reste prioritaire.
+// Les données scrapées avec un domaine choisi sont mises en cache à part.
+func (s *CompanyScraper) GetCompanyInfoForDomain(ctx context.Context, companyName, domain string) (*models.CompanyInfo, error) {
+	// 1. Entreprise canonique (son domaine confirmé ou curé remplace le domaine deviné)
+	company := s.resolveCompany(ctx, companyName)
+	query := CompanyQuery{Name: companyName}
+	cacheID := companyName
+	if company != nil {
+		query.Name = company.Name
+		query.Domain = company.Domain
+		cacheID = company.ID.String()
+	}
+	if query.Domain == "" && domain != "" {
+		query.Domain = domain
+		cacheID += ":" + domain
+	}
+	cacheKey := companyInfoCacheKey(cacheID)
+	if query.Domain == "" {
+		query.Domain = s.guessDomainFromName(query.Name)
+	}
+
+	// 2. Check cache Redis
+	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var info models.CompanyInfo
+		if json.Unmarshal([]byte(cached), &info) == nil {
+			log.Info().Str("company", companyName).Msg("Company info found in cache")
+			if company != nil {
+				ApplyCompanyRecord(&info, company)
 			}
-			log.Info().Str("company", companyName).Str("source", "news").Msg("Got recent news")
+			return &info, nil
 		}
-	}()
-
-	// Source 6: Clearbit API (si clé dispo)
-	if s.config.ClearbitAPIKey != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			clearbitInfo, err := s.fetchFromClearbit(ctx, companyName)
-			if err != nil {
-				log.Debug().Err(err).Str("company", companyName).Msg("Clearbit fetch failed")
-				return
-			}
-			mu.Lock()
-			defer mu.Unlock()
-			if len(clearbitInfo.Technologies) > 0 {
-				info.Technologies = clearbitInfo.Technologies
-			}
-			if info.Size == "" && clearbitInfo.Size != "" {
-				info.Size = clearbitInfo.Size
-			}
-			if info.Industry == "" && clearbitInfo.Industry != "" {
-				info.Industry = clearbitInfo.Industry
-			}
-			log.Info().Str("company", companyName).Str("source", "clearbit").Msg("Got enrichment data")
-		}()
 	}
 
-	// Attendre toutes les sources (avec timeout)
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		// Toutes les sources ont répondu
-	case <-time.After(12 * time.Second):
-		log.Warn().Str("company", companyName).Msg("Timeout waiting for all sources")
-	}
-
-	// 3. Fallback minimal si tout échoue
-	if info.Description == "" {
-		info.Description = fmt.Sprintf("%s est une entreprise.", companyName)
-	}
+	// 3. Interroger toutes les sources actives en parallèle et fusionner
+	info := s.collectCompanyInfo(ctx, query)
 
 	// 4. Cache résultat (7 jours)
 	data, _ := json.Marshal(info)
 	s.redisClient.Set(ctx, cacheKey, data, s.config.CacheTTL)
 
+	// 5. L'entreprise canonique (fiche curée, domaine confirmé) remplace les champs scrapés
+	if company != nil {
+		ApplyCompanyRecord(info, company)
+	}
+
 	log.Info().
 		Str("company", companyName).
-		Bool("has_description", info.Description != "").
+		Str("company_id", info.CompanyID).
+		Bool("curated", company != nil && company.Curated).
+		Str("description_source", info.Provenance[CompanyFieldDescription].Source).
 		Bool("has_industry", info.Industry != "").
 		Bool("has_size", info.Size != "").
 		Bool("has_news", info.RecentNews != "").
@@ -215,8 +149,21 @@ func (s *CompanyScraper) GetCompanyInfo(ctx context.Context, companyName string)
 	return info, nil
 }
 
-// fetchFromGitHub récupère les repos open-source populaires
-func (s *CompanyScraper) fetchFromGitHub(ctx context.Context, companyName string) (string, error) {
+// resolveCompany retourne l'entreprise canonique du nom (nil sans base ou en cas d'erreur)
+func (s *CompanyScraper) resolveCompany(ctx context.Context, companyName string) *models.Company {
+	if s.directory == nil {
+		return nil
+	}
+	company, err := s.directory.ResolveOrCreateCompany(ctx, companyName)
+	if err != nil {
+		log.Warn().Err(err).Str("company", companyName).Msg("Company resolution failed")
+		return nil
+	}
+	return company
+}
+
+// fetchFromGitHub récupère les repos open-source populaires (et l'URL de l'organisation)
+func (s *CompanyScraper) fetchFromGitHub(ctx context.Context, companyName string) (string, string, error) {
 	// GitHub API - recherche des repos de l'organisation
 	orgName := strings.ToLower(strings.ReplaceAll(companyName, " ", ""))
 
@@ -238,19 +185,19 @@ func (s *CompanyScraper) fetchFromGitHub(ctx context.Context, companyName string
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	req.Header.Set("User-Agent", s.config.UserAgent)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("github returned %d", resp.StatusCode)
+		return "", "", fmt.Errorf("github returned %d", resp.StatusCode)
 	}
 
 	var repos []struct {
@@ -262,11 +209,11 @@ func (s *CompanyScraper) fetchFromGitHub(ctx context.Context, companyName string
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	if len(repos) == 0 {
-		return "", fmt.Errorf("no repos found")
+		return "", "", fmt.Errorf("no repos found")
 	}
 
 	// Formater les projets
@@ -283,14 +230,11 @@ func (s *CompanyScraper) fetchFromGitHub(ctx context.Context, companyName string
 		sb.WriteString(fmt.Sprintf("• %s (%s, %d★): %s\n", repo.Name, repo.Language, repo.Stars, desc))
 	}
 
-	return sb.String(), nil
+	return sb.String(), "https://github.com/" + orgName, nil
 }
 
-// fetchRecentNews récupère les actualités récentes via recherche
-func (s *CompanyScraper) fetchRecentNews(ctx context.Context, companyName string) (string, error) {
-	// Utiliser DuckDuckGo News ou scraper le newsroom
-	domain := s.guessDomainFromName(companyName)
-
+// fetchRecentNews récupère les actualités récentes du blog/newsroom (et l'URL consultée)
+func (s *CompanyScraper) fetchRecentNews(ctx context.Context, domain string) (string, string, error) {
 	// Essayer de scraper le blog/newsroom
 	newsURLs := []string{
 		fmt.Sprintf("https://%s/blog", domain),
@@ -300,11 +244,13 @@ func (s *CompanyScraper) fetchRecentNews(ctx context.Context, companyName string
 	}
 
 	var titles []string
+	var sourceURL string
 
 	for _, newsURL := range newsURLs {
 		c := colly.NewCollector(
 			colly.UserAgent(s.config.UserAgent),
 			colly.MaxDepth(1),
+			colly.StdlibContext(ctx),
 		)
 
 		c.OnHTML("article h2, article h3, .post-title, .blog-title, h2.title, h3.title", func(e *colly.HTMLElement) {
@@ -321,15 +267,19 @@ func (s *CompanyScraper) fetchRecentNews(ctx context.Context, companyName string
 			}
 		})
 
+		found := len(titles)
 		_ = c.Visit(newsURL)
+		if sourceURL == "" && len(titles) > found {
+			sourceURL = newsURL
+		}
 
-		if len(titles) >= 3 {
+		if len(titles) >= 3 || ctx.Err() != nil {
 			break
 		}
 	}
 
 	if len(titles) == 0 {
-		return "", fmt.Errorf("no news found")
+		return "", "", fmt.Errorf("no news found")
 	}
 
 	// Dédupliquer et formater
@@ -344,11 +294,11 @@ func (s *CompanyScraper) fetchRecentNews(ctx context.Context, companyName string
 		sb.WriteString(fmt.Sprintf("• %s\n", title))
 	}
 
-	return sb.String(), nil
+	return sb.String(), sourceURL, nil
 }
 
-// fetchFromWikipedia récupère les infos depuis l'API Wikipedia
-func (s *CompanyScraper) fetchFromWikipedia(ctx context.Context, companyName string) (*models.CompanyInfo, error) {
+// fetchFromWikipedia récupère les infos depuis l'API Wikipedia (et l'URL de l'article)
+func (s *CompanyScraper) fetchFromWikipedia(ctx context.Context, companyName string) (*models.CompanyInfo, string, error) {
 	// Essayer plusieurs variantes du nom
 	variants := []string{
 		companyName,
@@ -379,6 +329,11 @@ func (s *CompanyScraper) fetchFromWikipedia(ctx context.Context, companyName str
 				Title       string `json:"title"`
 				Extract     string `json:"extract"`
 				Description string `json:"description"`
+				ContentURLs struct {
+					Desktop struct {
+						Page string `json:"page"`
+					} `json:"desktop"`
+				} `json:"content_urls"`
 			}
 
 			if err := json.NewDecoder(resp.Body).Decode(&wikiData); err != nil {
@@ -394,17 +349,21 @@ func (s *CompanyScraper) fetchFromWikipedia(ctx context.Context, companyName str
 					Industry:    extractIndustryFromText(wikiData.Extract),
 					Size:        extractSizeFromText(wikiData.Extract),
 				}
-				return info, nil
+				pageURL := wikiData.ContentURLs.Desktop.Page
+				if pageURL == "" {
+					pageURL = searchURL
+				}
+				return info, pageURL, nil
 			}
 		}
 		resp.Body.Close()
 	}
 
-	return nil, fmt.Errorf("no wikipedia article found for %s", companyName)
+	return nil, "", fmt.Errorf("no wikipedia article found for %s", companyName)
 }
 
-// fetchFromDuckDuckGo récupère un résumé via DuckDuckGo Instant Answer
-func (s *CompanyScraper) fetchFromDuckDuckGo(ctx context.Context, companyName string) (string, error) {
+// fetchFromDuckDuckGo récupère un résumé via DuckDuckGo Instant Answer (et l'URL de sa source)
+func (s *CompanyScraper) fetchFromDuckDuckGo(ctx context.Context, companyName string) (string, string, error) {
 	ddgURL := fmt.Sprintf(
 		"https://api.duckduckgo.com/?q=%s&format=json&no_html=1&skip_disambig=1",
 		url.QueryEscape(companyName+" company"),
@@ -412,58 +371,74 @@ func (s *CompanyScraper) fetchFromDuckDuckGo(ctx context.Context, companyName st
 
 	req, err := http.NewRequestWithContext(ctx, "GET", ddgURL, nil)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	req.Header.Set("User-Agent", s.config.UserAgent)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	var ddgData struct {
 		Abstract     string `json:"Abstract"`
 		AbstractText string `json:"AbstractText"`
+		AbstractURL  string `json:"AbstractURL"`
 		Heading      string `json:"Heading"`
 	}
 
 	if err := json.Unmarshal(body, &ddgData); err != nil {
-		return "", err
+		return "", "", err
 	}
 
+	sourceURL := ddgData.AbstractURL
+	if sourceURL == "" {
+		sourceURL = ddgURL
+	}
 	if ddgData.AbstractText != "" {
-		return ddgData.AbstractText, nil
+		return ddgData.AbstractText, sourceURL, nil
 	}
 	if ddgData.Abstract != "" {
-		return ddgData.Abstract, nil
+		return ddgData.Abstract, sourceURL, nil
 	}
-	return "", fmt.Errorf("no duckduckgo result")
+	return "", "", fmt.Errorf("no duckduckgo result")
 }
 
-// scrapeCompanyWebsite : scraping du site officiel
-func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, companyName string) (*models.CompanyInfo, error) {
-	domain := s.guessDomainFromName(companyName)
+// companyWebsiteURLFormats adresses essayées pour la page d'accueil (remplacées dans les tests)
+var companyWebsiteURLFormats = []string{"https://www.%s", "https://%s"}
 
+// scrapeCompanyWebsite : scraping du site officiel (retourne aussi l'URL visitée)
+func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, domain string) (*models.CompanyInfo, string, error) {
 	info := &models.CompanyInfo{
-		Name:   companyName,
 		Domain: domain,
 	}
+	onCareersPage := false // La description vient de la page d'accueil uniquement
 
 	c := colly.NewCollector(
 		colly.UserAgent(s.config.UserAgent),
 		colly.AllowedDomains(domain, "www."+domain),
 		colly.MaxDepth(1),
+		colly.StdlibContext(ctx),
 	)
 
+	// Chaque page visitée et chaque redirection sont vérifiées (pas de rebond vers le réseau interne)
+	c.SetRedirectHandler(s.checkPublicRedirect)
+	c.OnRequest(func(r *colly.Request) {
+		if err := s.checkPublicURL(r.URL); err != nil {
+			log.Debug().Err(err).Str("url", r.URL.String()).Msg("Website page not scraped")
+			r.Abort()
+		}
+	})
+
 	// Meta description
 	c.OnHTML("meta[name=description]", func(e *colly.HTMLElement) {
-		if info.Description == "" {
+		if info.Description == "" && !onCareersPage {
 			content := e.Attr("content")
 			if len(content) > 50 {
 				info.Description = content
@@ -473,7 +448,7 @@ func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, companyName s
 
 	// OG description (souvent meilleure)
 	c.OnHTML("meta[property='og:description']", func(e *colly.HTMLElement) {
-		if info.Description == "" {
+		if info.Description == "" && !onCareersPage {
 			content := e.Attr("content")
 			if len(content) > 50 {
 				info.Description = content
@@ -483,7 +458,7 @@ func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, companyName s
 
 	// Twitter description
 	c.OnHTML("meta[name='twitter:description']", func(e *colly.HTMLElement) {
-		if info.Description == "" {
+		if info.Description == "" && !onCareersPage {
 			content := e.Attr("content")
 			if len(content) > 50 {
 				info.Description = content
@@ -491,27 +466,23 @@ func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, companyName s
 		}
 	})
 
-	// Détecter les technos via scripts et meta
-	var detectedTechs []string
-	c.OnHTML("script[src]", func(e *colly.HTMLElement) {
-		src := strings.ToLower(e.Attr("src"))
-		if strings.Contains(src, "react") {
-			detectedTechs = append(detectedTechs, "React")
-		}
-		if strings.Contains(src, "angular") {
-			detectedTechs = append(detectedTechs, "Angular")
-		}
-		if strings.Contains(src, "vue") {
-			detectedTechs = append(detectedTechs, "Vue.js")
-		}
-		if strings.Contains(src, "jquery") {
-			detectedTechs = append(detectedTechs, "jQuery")
+	// Pages analysées par la détection des technologies (en-têtes, cookies, scripts, meta, HTML)
+	var pages []FingerprintPage
+	c.OnResponse(func(r *colly.Response) {
+		if r.Headers == nil {
+			return
 		}
+		pages = append(pages, FingerprintPage{URL: r.Request.URL.String(), Headers: *r.Headers, Body: r.Body})
 	})
 
-	c.OnScraped(func(r *colly.Response) {
-		if len(detectedTechs) > 0 {
-			info.Technologies = uniqueStrings(detectedTechs)
+	// Page carrières liée depuis l'accueil (outils de recrutement, stack des offres)
+	var careersURL string
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		if careersURL != "" || onCareersPage {
+			return
+		}
+		if careersLinkPattern.MatchString(e.Attr("href")) || careersLinkPattern.MatchString(strings.TrimSpace(e.Text)) {
+			careersURL = e.Request.AbsoluteURL(e.Attr("href"))
 		}
 	})
 
@@ -519,49 +490,64 @@ func (s *CompanyScraper) scrapeCompanyWebsite(ctx context.Context, companyName s
 		log.Debug().Err(err).Str("url", r.Request.URL.String()).Msg("Scraping error")
 	})
 
-	// Visiter le site
-	urls := []string{
-		fmt.Sprintf("https://www.%s", domain),
-		fmt.Sprintf("https://%s", domain),
+	// Visiter le site (une page refusée par OnRequest n'est pas considérée comme visitée)
+	var visitedURL string
+	for _, format := range companyWebsiteURLFormats {
+		siteURL := fmt.Sprintf(format, domain)
+		u, err := url.Parse(siteURL)
+		if err != nil || s.checkPublicURL(u) != nil {
+			continue
+		}
+		if err := c.Visit(siteURL); err == nil {
+			visitedURL = siteURL
+			break
+		}
 	}
 
-	for _, siteURL := range urls {
-		err := c.Visit(siteURL)
-		if err == nil {
-			break
+	if visitedURL != "" && careersURL != "" {
+		onCareersPage = true
+		if err := c.Visit(careersURL); err != nil {
+			log.Debug().Err(err).Str("url", careersURL).Msg("Careers page not scraped")
 		}
 	}
 
+	if s.fingerprinter != nil && len(pages) > 0 {
+		info.TechStack = s.fingerprinter.Analyze(pages...)
+		info.Technologies, _ = techStackSummary(info.TechStack)
+	}
+
 	if info.Description == "" && len(info.Technologies) == 0 {
-		return nil, fmt.Errorf("no useful data scraped from %s", domain)
+		return nil, "", fmt.Errorf("no useful data scraped from %s", domain)
 	}
 
-	return info, nil
+	return info, visitedURL, nil
 }
 
-// fetchFromClearbit : enrichissement via Clearbit API
-func (s *CompanyScraper) fetchFromClearbit(ctx context.Context, companyName string) (*models.CompanyInfo, error) {
+// careersLinkPattern lien vers la page carrières d'un site (URL ou texte du lien)
+var careersLinkPattern = regexp.MustCompile(`(?i)\b(?:careers?|jobs|carri[eè]res?|recrutement|emplois?|join[- ]us|rejoignez[- ]nous|nous[- ]rejoindre)\b`)
+
+// fetchFromClearbit : enrichissement via Clearbit API (retourne aussi l'URL interrogée)
+func (s *CompanyScraper) fetchFromClearbit(ctx context.Context, domain string) (*models.CompanyInfo, string, error) {
 	if s.config.ClearbitAPIKey == "" {
-		return nil, fmt.Errorf("no clearbit API key")
+		return nil, "", fmt.Errorf("no clearbit API key")
 	}
 
-	domain := s.guessDomainFromName(companyName)
 	apiURL := fmt.Sprintf("https://company.clearbit.com/v2/companies/find?domain=%s", domain)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+s.config.ClearbitAPIKey)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("clearbit returned %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("clearbit returned %d", resp.StatusCode)
 	}
 
 	var data struct {
@@ -578,7 +564,7 @@ func (s *CompanyScraper) fetchFromClearbit(ctx context.Context, companyName stri
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	return &models.CompanyInfo{
@@ -588,14 +574,17 @@ func (s *CompanyScraper) fetchFromClearbit(ctx context.Context, companyName stri
 		Industry:     data.Category.Industry,
 		Size:         data.Metrics.Employees,
 		Technologies: data.Tech,
-	}, nil
+	}, apiURL, nil
 }
 
 // guessDomainFromName devine le domaine depuis le nom
 func (s *CompanyScraper) guessDomainFromName(name string) string {
-	domain := strings.ToLower(name)
-	domain = strings.ReplaceAll(domain, " ", "")
-	domain = strings.ReplaceAll(domain, ".", "")
+	return guessCompanyDomain(name)
+}
+
+// guessCompanyDomain devine le domaine depuis le nom normalisé (sans forme juridique ni accents)
+func guessCompanyDomain(name string) string {
+	domain := strings.ReplaceAll(NormalizeCompanyName(name), " ", "")
 
 	// Cas spéciaux connus
 	knownDomains := map[string]string{
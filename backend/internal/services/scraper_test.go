@@ -3,6 +3,12 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -127,7 +133,7 @@ func TestCompanyScraper_GuessDomainFromName(t *testing.T) {
 	}
 }
 
-func TestCompanyScraper_EnrichViaAPIs_NoAPIKey(t *testing.T) {
+func TestCompanyScraper_FetchFromClearbit_NoAPIKey(t *testing.T) {
 	cfg := &config.ScraperConfig{
 		// No API key
 		Timeout: 10 * time.Second,
@@ -136,7 +142,56 @@ func TestCompanyScraper_EnrichViaAPIs_NoAPIKey(t *testing.T) {
 	scraper := NewCompanyScraper(cfg, nil)
 	ctx := context.Background()
 
-	_, err := scraper.enrichViaAPIs(ctx, "TestCompany")
+	_, _, err := scraper.fetchFromClearbit(ctx, "testcompany.com")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "no API key configured")
+	assert.Contains(t, err.Error(), "no clearbit API key")
+}
+
+func TestScrapeCompanyWebsite_RejectsInternalRedirects(t *testing.T) {
+	// "localhost" passe pour un hôte public jusqu'à la première page servie, puis résout
+	// vers une adresse interne (rebond DNS) : redirections et page carrières sont refusées
+	var rebound atomic.Bool
+	var internalHits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			internalHits.Add(1)
+			return
+		}
+		defer rebound.Store(true)
+		if r.URL.Query().Get("redirect") != "" {
+			http.Redirect(w, r, "/admin", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, `<html><head><meta name="description" content="Acme conçoit des fusées réutilisables pour les missions orbitales."></head>
+			<body><a href="/carrieres">Nous rejoindre</a></body></html>`)
+	})
+	site := httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+	siteURL, err := url.Parse(site.URL)
+	require.NoError(t, err)
+
+	previousLookup, previousFormats := lookupIP, companyWebsiteURLFormats
+	lookupIP = func(host string) ([]net.IP, error) {
+		if rebound.Load() {
+			return []net.IP{net.ParseIP("127.0.0.1")}, nil
+		}
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	}
+	t.Cleanup(func() { lookupIP, companyWebsiteURLFormats = previousLookup, previousFormats })
+
+	scraper := NewCompanyScraper(&config.ScraperConfig{UserAgent: "maicivy-test", Timeout: 5 * time.Second}, nil)
+
+	companyWebsiteURLFormats = []string{"http://%s:" + siteURL.Port() + "/"}
+	info, visitedURL, err := scraper.scrapeCompanyWebsite(t.Context(), "localhost")
+	require.NoError(t, err)
+	assert.Contains(t, info.Description, "Acme")
+	assert.NotEmpty(t, visitedURL)
+	assert.Equal(t, int32(0), internalHits.Load(), "careers page fetched after rebinding")
+
+	rebound.Store(false)
+	companyWebsiteURLFormats = []string{"http://%s:" + siteURL.Port() + "/?redirect=1"}
+	_, _, err = scraper.scrapeCompanyWebsite(t.Context(), "localhost")
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), internalHits.Load(), "redirect followed to an internal address")
 }
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"maicivy/internal/models"
+)
+
+// Détection des technologies d'un site par empreintes (règles au format Wappalyzer)
+const (
+	minTechConfidence   = 0.5        // Seuil pour figurer dans CompanyInfo.Technologies
+	maxTechEvidence     = 3          // Indices conservés par technologie
+	maxFingerprintHTML  = 512 * 1024 // Octets de HTML soumis aux motifs "html"
+	maxTechnologiesKept = 15         // Technologies retenues pour le prompt
+)
+
+// FingerprintPage page analysée : réponse HTTP d'une page du site
+type FingerprintPage struct {
+	URL     string
+	Headers http.Header
+	Body    []byte
+}
+
+// TechFingerprinter moteur de détection, construit à partir d'un fichier de règles
+type TechFingerprinter struct {
+	technologies []techRule
+}
+
+type techRule struct {
+	name       string
+	categories []string
+	headers    map[string][]techPattern // Clé en minuscules
+	cookies    map[string][]techPattern
+	meta       map[string][]techPattern
+	scriptSrc  []techPattern
+	html       []techPattern
+	implies    []techImplication
+}
+
+// techPattern motif "regex\;version:\1\;confidence:50" (nil = simple présence)
+type techPattern struct {
+	regex      *regexp.Regexp
+	version    string
+	confidence int
+}
+
+type techImplication struct {
+	name       string
+	confidence int
+}
+
+// techRulesFile fichier de règles : catégories numérotées et technologies
+type techRulesFile struct {
+	Categories   map[string]struct{ Name string } `json:"categories"`
+	Technologies map[string]struct {
+		Cats      []int                  `json:"cats"`
+		Headers   map[string]patternList `json:"headers"`
+		Cookies   map[string]patternList `json:"cookies"`
+		Meta      map[string]patternList `json:"meta"`
+		ScriptSrc patternList            `json:"scriptSrc"`
+		HTML      patternList            `json:"html"`
+		Implies   patternList            `json:"implies"`
+	} `json:"technologies"`
+}
+
+// patternList motif unique ou liste de motifs (les deux formes existent dans les règles Wappalyzer)
+type patternList []string
+
+func (p *patternList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = patternList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*p = list
+	return nil
+}
+
+// LoadTechFingerprints charge les règles de détection depuis un fichier JSON local
+func LoadTechFingerprints(path string) (*TechFingerprinter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tech fingerprints: %w", err)
+	}
+	return ParseTechFingerprints(data)
+}
+
+// ParseTechFingerprints compile les règles ; un motif invalide est une erreur (pas d'ignorance silencieuse)
+func ParseTechFingerprints(data []byte) (*TechFingerprinter, error) {
+	var file techRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid tech fingerprints: %w", err)
+	}
+
+	f := &TechFingerprinter{}
+	for name, tech := range file.Technologies {
+		rule := techRule{name: name}
+		for _, cat := range tech.Cats {
+			if category, ok := file.Categories[strconv.Itoa(cat)]; ok {
+				rule.categories = append(rule.categories, category.Name)
+			}
+		}
+
+		var err error
+		if rule.headers, err = compilePatternMap(tech.Headers, true); err != nil {
+			return nil, fmt.Errorf("technology %s: headers: %w", name, err)
+		}
+		if rule.cookies, err = compilePatternMap(tech.Cookies, false); err != nil {
+			return nil, fmt.Errorf("technology %s: cookies: %w", name, err)
+		}
+		if rule.meta, err = compilePatternMap(tech.Meta, true); err != nil {
+			return nil, fmt.Errorf("technology %s: meta: %w", name, err)
+		}
+		if rule.scriptSrc, err = compilePatterns(tech.ScriptSrc); err != nil {
+			return nil, fmt.Errorf("technology %s: scriptSrc: %w", name, err)
+		}
+		if rule.html, err = compilePatterns(tech.HTML); err != nil {
+			return nil, fmt.Errorf("technology %s: html: %w", name, err)
+		}
+		for _, implied := range tech.Implies {
+			parts := strings.Split(implied, `\;`)
+			var attrs techPattern
+			if err := parseTechAttributes(&attrs, parts[1:]); err != nil {
+				return nil, fmt.Errorf("technology %s: implies: %w", name, err)
+			}
+			rule.implies = append(rule.implies, techImplication{name: parts[0], confidence: attrs.confidence})
+		}
+		f.technologies = append(f.technologies, rule)
+	}
+
+	// Ordre stable des résultats
+	sort.Slice(f.technologies, func(i, j int) bool { return f.technologies[i].name < f.technologies[j].name })
+	return f, nil
+}
+
+func compilePatternMap(raw map[string]patternList, lowerKeys bool) (map[string][]techPattern, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	compiled := make(map[string][]techPattern, len(raw))
+	for key, patterns := range raw {
+		list, err := compilePatterns(patterns)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if lowerKeys {
+			key = strings.ToLower(key)
+		}
+		compiled[key] = list
+	}
+	return compiled, nil
+}
+
+func compilePatterns(raw patternList) ([]techPattern, error) {
+	patterns := make([]techPattern, 0, len(raw))
+	for _, value := range raw {
+		pattern, err := parseTechPattern(value)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// parseTechPattern motif Wappalyzer : regex (insensible à la casse) puis attributs séparés par "\;"
+func parseTechPattern(value string) (techPattern, error) {
+	parts := strings.Split(value, `\;`)
+	var pattern techPattern
+	if parts[0] != "" {
+		regex, err := regexp.Compile("(?i)" + parts[0])
+		if err != nil {
+			return pattern, fmt.Errorf("invalid pattern %q: %w", parts[0], err)
+		}
+		pattern.regex = regex
+	}
+
+	return pattern, parseTechAttributes(&pattern, parts[1:])
+}
+
+// parseTechAttributes attributs "version:\1" et "confidence:50" (confiance 100 par défaut)
+func parseTechAttributes(pattern *techPattern, attrs []string) error {
+	pattern.confidence = 100
+	for _, attr := range attrs {
+		key, val, _ := strings.Cut(attr, ":")
+		switch key {
+		case "version":
+			pattern.version = val
+		case "confidence":
+			confidence, err := strconv.Atoi(val)
+			if err != nil || confidence < 0 || confidence > 100 {
+				return fmt.Errorf("invalid confidence %q", val)
+			}
+			pattern.confidence = confidence
+		}
+	}
+	return nil
+}
+
+// match teste une valeur ; retourne la version extraite éventuelle
+func (p techPattern) match(value string) (bool, string) {
+	if p.regex == nil {
+		return true, ""
+	}
+	groups := p.regex.FindStringSubmatch(value)
+	if groups == nil {
+		return false, ""
+	}
+	if p.version == "" {
+		return true, ""
+	}
+
+	version := p.version
+	for i := len(groups) - 1; i >= 1; i-- {
+		version = strings.ReplaceAll(version, `\`+strconv.Itoa(i), groups[i])
+	}
+	return true, strings.TrimSpace(version)
+}
+
+// techDetection détection en cours de cumul (confiance sur 100)
+type techDetection struct {
+	confidence int
+	version    string
+	evidence   []string
+}
+
+func (d *techDetection) add(confidence int, version, evidence string) {
+	d.confidence = min(d.confidence+confidence, 100)
+	if d.version == "" {
+		d.version = version
+	}
+	if len(d.evidence) < maxTechEvidence && evidence != "" {
+		d.evidence = append(d.evidence, truncateRunes(evidence, 200))
+	}
+}
+
+// Analyze détecte les technologies d'un ensemble de pages (accueil, carrières...)
+// La confiance d'une technologie est la meilleure obtenue sur une page, les indices sont cumulés.
+func (f *TechFingerprinter) Analyze(pages ...FingerprintPage) []models.DetectedTechnology {
+	merged := map[string]*techDetection{}
+	for _, page := range pages {
+		for name, detection := range f.analyzePage(page) {
+			current, ok := merged[name]
+			if !ok {
+				merged[name] = detection
+				continue
+			}
+			current.confidence = max(current.confidence, detection.confidence)
+			if current.version == "" {
+				current.version = detection.version
+			}
+			for _, evidence := range detection.evidence {
+				if len(current.evidence) < maxTechEvidence {
+					current.evidence = append(current.evidence, evidence)
+				}
+			}
+		}
+	}
+	f.applyImplications(merged)
+
+	detected := make([]models.DetectedTechnology, 0, len(merged))
+	for _, rule := range f.technologies {
+		detection, ok := merged[rule.name]
+		if !ok {
+			continue
+		}
+		detected = append(detected, models.DetectedTechnology{
+			Name:       rule.name,
+			Categories: rule.categories,
+			Version:    detection.version,
+			Confidence: float64(detection.confidence) / 100,
+			Evidence:   detection.evidence,
+		})
+	}
+	sort.SliceStable(detected, func(i, j int) bool { return detected[i].Confidence > detected[j].Confidence })
+	return detected
+}
+
+func (f *TechFingerprinter) analyzePage(page FingerprintPage) map[string]*techDetection {
+	detections := map[string]*techDetection{}
+	detect := func(name string, pattern techPattern, value, evidence string) {
+		ok, version := pattern.match(value)
+		if !ok {
+			return
+		}
+		detection, exists := detections[name]
+		if !exists {
+			detection = &techDetection{}
+			detections[name] = detection
+		}
+		detection.add(pattern.confidence, version, evidence)
+	}
+
+	cookies := map[string]string{}
+	for _, cookie := range (&http.Response{Header: page.Headers}).Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	html := page.Body
+	if len(html) > maxFingerprintHTML {
+		html = html[:maxFingerprintHTML]
+	}
+	scripts, meta := extractFingerprintTags(page.Body)
+
+	for _, rule := range f.technologies {
+		for header, patterns := range rule.headers {
+			values := page.Headers.Values(header)
+			for _, value := range values {
+				for _, pattern := range patterns {
+					detect(rule.name, pattern, value, fmt.Sprintf("header %s: %s", header, value))
+				}
+			}
+		}
+		for name, patterns := range rule.cookies {
+			value, ok := cookies[name]
+			if !ok {
+				continue
+			}
+			for _, pattern := range patterns {
+				detect(rule.name, pattern, value, "cookie: "+name)
+			}
+		}
+		for name, patterns := range rule.meta {
+			for _, content := range meta[name] {
+				for _, pattern := range patterns {
+					detect(rule.name, pattern, content, fmt.Sprintf("meta %s: %s", name, content))
+				}
+			}
+		}
+		for _, pattern := range rule.scriptSrc {
+			for _, src := range scripts {
+				detect(rule.name, pattern, src, "script: "+src)
+			}
+		}
+		for _, pattern := range rule.html {
+			if pattern.regex == nil {
+				continue
+			}
+			if loc := pattern.regex.FindIndex(html); loc != nil {
+				detect(rule.name, pattern, string(html[loc[0]:loc[1]]), "html: "+string(html[loc[0]:loc[1]]))
+			}
+		}
+	}
+	return detections
+}
+
+// applyImplications ajoute les technologies impliquées ("Next.js" implique "React")
+// La confiance d'une technologie impliquée est celle de la technologie qui l'implique.
+func (f *TechFingerprinter) applyImplications(detections map[string]*techDetection) {
+	rules := make(map[string]*techRule, len(f.technologies))
+	for i := range f.technologies {
+		rules[f.technologies[i].name] = &f.technologies[i]
+	}
+
+	// Chaînes d'implications bornées par le nombre de technologies
+	for range len(f.technologies) {
+		changed := false
+		for name, detection := range detections {
+			rule, ok := rules[name]
+			if !ok {
+				continue
+			}
+			for _, implied := range rule.implies {
+				if _, known := rules[implied.name]; !known {
+					continue
+				}
+				confidence := detection.confidence * implied.confidence / 100
+				current, exists := detections[implied.name]
+				if exists && current.confidence >= confidence {
+					continue
+				}
+				if !exists {
+					current = &techDetection{}
+					detections[implied.name] = current
+				}
+				current.confidence = confidence
+				if len(current.evidence) < maxTechEvidence {
+					current.evidence = append(current.evidence, "implied by "+name)
+				}
+				changed = true
+			}
+		}
+		if !changed {
+			return
+		}
+	}
+}
+
+// extractFingerprintTags sources des scripts et contenus des balises meta (par name ou property)
+func extractFingerprintTags(body []byte) ([]string, map[string][]string) {
+	meta := map[string][]string{}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, meta
+	}
+
+	var scripts []string
+	doc.Find("script[src]").Each(func(_ int, s *goquery.Selection) {
+		if src := strings.TrimSpace(s.AttrOr("src", "")); src != "" {
+			scripts = append(scripts, src)
+		}
+	})
+	doc.Find("meta[content]").Each(func(_ int, s *goquery.Selection) {
+		name := s.AttrOr("name", s.AttrOr("property", ""))
+		if name == "" {
+			return
+		}
+		name = strings.ToLower(name)
+		meta[name] = append(meta[name], s.AttrOr("content", ""))
+	})
+	return scripts, meta
+}
+
+// techStackSummary technologies retenues pour CompanyInfo.Technologies et fiabilité moyenne
+func techStackSummary(stack []models.DetectedTechnology) ([]string, float64) {
+	var names []string
+	total := 0.0
+	for _, tech := range stack {
+		if tech.Confidence < minTechConfidence || len(names) >= maxTechnologiesKept {
+			continue
+		}
+		names = append(names, tech.Name)
+		total += tech.Confidence
+	}
+	if len(names) == 0 {
+		return nil, 0
+	}
+	return names, math.Round(total/float64(len(names))*100) / 100
+}
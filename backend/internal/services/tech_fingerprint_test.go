@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/models"
+)
+
+// loadFingerprintFixture page HTML enregistrée (testdata/fingerprints) et ses en-têtes de réponse
+func loadFingerprintFixture(t *testing.T, name string, headers map[string][]string) FingerprintPage {
+	body, err := os.ReadFile(filepath.Join("testdata", "fingerprints", name))
+	require.NoError(t, err)
+	header := http.Header{}
+	for key, values := range headers {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return FingerprintPage{URL: "https://www.acme.com/" + name, Headers: header, Body: body}
+}
+
+func loadDefaultFingerprints(t *testing.T) *TechFingerprinter {
+	fingerprinter, err := LoadTechFingerprints(filepath.Join("..", "..", "data", "tech_fingerprints.json"))
+	require.NoError(t, err)
+	return fingerprinter
+}
+
+func techByName(stack []models.DetectedTechnology) map[string]models.DetectedTechnology {
+	byName := make(map[string]models.DetectedTechnology, len(stack))
+	for _, tech := range stack {
+		byName[tech.Name] = tech
+	}
+	return byName
+}
+
+func TestTechFingerprinter_Fixtures(t *testing.T) {
+	fingerprinter := loadDefaultFingerprints(t)
+
+	testCases := []struct {
+		fixture  string
+		headers  map[string][]string
+		expected map[string]string // Technologie -> version attendue
+		absent   []string
+	}{
+		{
+			fixture: "homepage_nextjs.html",
+			headers: map[string][]string{
+				"Server":       {"cloudflare"},
+				"Cf-Ray":       {"84a1b2c3d4e5f6a7-CDG"},
+				"X-Powered-By": {"Next.js 14.1.0"},
+				"Set-Cookie":   {"__cf_bm=abc123; path=/; HttpOnly"},
+			},
+			expected: map[string]string{
+				"Next.js": "14.1.0", "React": "", "Node.js": "", "Cloudflare": "",
+				"Google Tag Manager": "", "Segment": "", "Stripe": "",
+			},
+			absent: []string{"WordPress", "Vue.js", "jQuery", "Nginx"},
+		},
+		{
+			fixture: "careers_wordpress.html",
+			headers: map[string][]string{
+				"Server":     {"nginx/1.25.3"},
+				"Set-Cookie": {"PHPSESSID=9f8e7d; path=/"},
+			},
+			expected: map[string]string{
+				"WordPress": "6.4.2", "PHP": "", "jQuery": "3.7.1", "Nginx": "1.25.3",
+				"Lever": "", "Welcome to the Jungle": "",
+			},
+			absent: []string{"React", "Next.js", "Cloudflare"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			detected := techByName(fingerprinter.Analyze(loadFingerprintFixture(t, tc.fixture, tc.headers)))
+			for name, version := range tc.expected {
+				tech, ok := detected[name]
+				if !assert.True(t, ok, "%s not detected", name) {
+					continue
+				}
+				assert.Equal(t, version, tech.Version, name)
+				assert.Equal(t, 1.0, tech.Confidence, name)
+				assert.NotEmpty(t, tech.Categories, name)
+				assert.NotEmpty(t, tech.Evidence, name)
+			}
+			for _, name := range tc.absent {
+				assert.NotContains(t, detected, name)
+			}
+		})
+	}
+}
+
+func TestTechFingerprinter_CategoriesAndEvidence(t *testing.T) {
+	fingerprinter := loadDefaultFingerprints(t)
+
+	homepage := loadFingerprintFixture(t, "homepage_nextjs.html", map[string][]string{"Server": {"cloudflare"}})
+	careers := loadFingerprintFixture(t, "careers_wordpress.html", map[string][]string{"Server": {"nginx/1.25.3"}})
+	detected := techByName(fingerprinter.Analyze(homepage, careers))
+
+	assert.Equal(t, []string{"JavaScript frameworks"}, detected["React"].Categories)
+	assert.Equal(t, []string{"Recruitment & staffing"}, detected["Lever"].Categories)
+	assert.Equal(t, []string{"Web servers"}, detected["Nginx"].Categories)
+	assert.Contains(t, detected["React"].Evidence, "implied by Next.js")
+	assert.Contains(t, detected["Nginx"].Evidence, "header server: nginx/1.25.3")
+	assert.Contains(t, detected["WordPress"].Evidence, "meta generator: WordPress 6.4.2")
+	assert.LessOrEqual(t, len(detected["WordPress"].Evidence), maxTechEvidence)
+}
+
+func TestTechFingerprinter_ConfidenceAndImplies(t *testing.T) {
+	fingerprinter, err := ParseTechFingerprints([]byte(`{
+		"categories": {"1": {"name": "Web frameworks"}, "2": {"name": "Programming languages"}},
+		"technologies": {
+			"Framework": {
+				"cats": [1],
+				"cookies": {"fw_session": "\\;confidence:25"},
+				"html": "<meta name=\"fw-csrf\"\\;confidence:50",
+				"implies": "Language\\;confidence:50"
+			},
+			"Language": {"cats": [2]},
+			"C++": {"cats": [2], "scriptSrc": "cpp\\.js"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	// Un seul indice faible : sous le seuil de CompanyInfo.Technologies
+	page := FingerprintPage{Headers: http.Header{"Set-Cookie": {"fw_session=1"}}, Body: []byte("<html></html>")}
+	stack := fingerprinter.Analyze(page)
+	require.Len(t, stack, 2)
+	assert.Equal(t, "Framework", stack[0].Name)
+	assert.Equal(t, 0.25, stack[0].Confidence)
+	assert.Equal(t, 0.12, stack[1].Confidence, "implied confidence is scaled by the implier")
+	names, _ := techStackSummary(stack)
+	assert.Empty(t, names)
+
+	// Les indices se cumulent
+	page.Body = []byte(`<html><head><meta name="fw-csrf" content="x"></head></html>`)
+	detected := techByName(fingerprinter.Analyze(page))
+	assert.Equal(t, 0.75, detected["Framework"].Confidence)
+	names, confidence := techStackSummary(fingerprinter.Analyze(page))
+	assert.Equal(t, []string{"Framework"}, names)
+	assert.Equal(t, 0.75, confidence)
+}
+
+func TestParseTechFingerprints_Invalid(t *testing.T) {
+	testCases := []string{
+		`not json`,
+		`{"technologies": {"Broken": {"html": "(unclosed"}}}`,
+		`{"technologies": {"Broken": {"scriptSrc": "broken\\;confidence:150"}}}`,
+		`{"technologies": {"Broken": {"implies": "Other\\;confidence:high"}}}`,
+	}
+	for _, rules := range testCases {
+		_, err := ParseTechFingerprints([]byte(rules))
+		assert.Error(t, err, rules)
+	}
+
+	_, err := LoadTechFingerprints(filepath.Join("testdata", "fingerprints", "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestCompanyTechnologies_WithCategories(t *testing.T) {
+	company := models.CompanyInfo{
+		Technologies: []string{"React", "Kafka"},
+		TechStack: []models.DetectedTechnology{
+			{Name: "React", Categories: []string{"JavaScript frameworks"}, Confidence: 1},
+		},
+	}
+	assert.Equal(t, "React (JavaScript frameworks), Kafka", companyTechnologies(company))
+	assert.Equal(t, "", companyTechnologies(models.CompanyInfo{}))
+}
+
+func TestCareersLinkPattern(t *testing.T) {
+	for _, link := range []string{"/carrieres", "/fr/carrières/", "https://acme.com/careers", "/jobs/", "Nous rejoindre", "Join us"} {
+		assert.True(t, careersLinkPattern.MatchString(link), link)
+	}
+	for _, link := range []string{"/produit", "/jobsite", "/recrutements-clients"} {
+		assert.False(t, careersLinkPattern.MatchString(link), link)
+	}
+}
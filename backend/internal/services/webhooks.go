@@ -0,0 +1,674 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+// WebhookEvent événement du cycle de vie des lettres diffusé aux abonnés
+type WebhookEvent string
+
+const (
+	WebhookEventJobQueued        WebhookEvent = "job.queued"        // Job mis en file (génération, révision, campagne)
+	WebhookEventJobCompleted     WebhookEvent = "job.completed"     // Lettres créées
+	WebhookEventJobFailed        WebhookEvent = "job.failed"        // Échec définitif (tentatives épuisées)
+	WebhookEventLetterDownloaded WebhookEvent = "letter.downloaded" // PDF d'une lettre téléchargé
+)
+
+// WebhookEvents événements disponibles
+var WebhookEvents = []WebhookEvent{WebhookEventJobQueued, WebhookEventJobCompleted, WebhookEventJobFailed, WebhookEventLetterDownloaded}
+
+// Headers des livraisons
+// La signature porte sur "<timestamp>.<corps>" : X-Maicivy-Signature: t=<unix>,v1=<hex HMAC-SHA256>.
+const (
+	WebhookSignatureHeader = "X-Maicivy-Signature"
+	WebhookEventHeader     = "X-Maicivy-Event"
+	WebhookDeliveryHeader  = "X-Maicivy-Delivery"
+)
+
+const (
+	// DefaultWebhookMaxAttempts tentatives par livraison avant abandon
+	DefaultWebhookMaxAttempts = 8
+	// DefaultWebhookRetryBackoff délai avant la 2e tentative (doublé à chaque échec)
+	DefaultWebhookRetryBackoff = 30 * time.Second
+	// DefaultWebhookTimeout délai de réponse d'un abonné
+	DefaultWebhookTimeout = 10 * time.Second
+
+	webhookMaxBackoff        = 6 * time.Hour
+	webhookDeliveryBatch     = 50   // Livraisons traitées par passe
+	webhookConcurrency       = 4    // Livraisons simultanées
+	webhookResponseBodyLimit = 1024 // Octets de réponse conservés dans le journal
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+	webhookUserAgent         = "maicivy-webhooks/1.0"
+)
+
+// ErrWebhookNotFound abonnement inconnu
+var ErrWebhookNotFound = errors.New("webhook subscription not found")
+
+// ErrWebhookDeliveryNotFound livraison inconnue
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+// ErrInvalidWebhook abonnement invalide (URL, événements)
+var ErrInvalidWebhook = errors.New("invalid webhook subscription")
+
+// ErrInvalidWebhookSignature signature absente, invalide ou expirée
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookPublisher émetteur d'événements webhook (WebhookService)
+type WebhookPublisher interface {
+	Publish(ctx context.Context, event WebhookEvent, data any) error
+}
+
+// WebhookPayload corps JSON d'une livraison
+type WebhookPayload struct {
+	ID        string       `json:"id"` // ID de l'événement (identique pour tous les abonnés et les re-livraisons)
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      any          `json:"data"`
+}
+
+// WebhookJobData données des événements job.*
+// La session du visiteur n'est pas transmise aux intégrations.
+type WebhookJobData struct {
+	JobID       string            `json:"job_id"`
+	Kind        JobKind           `json:"kind"`
+	Status      JobStatus         `json:"status"`
+	CompanyName string            `json:"company_name,omitempty"`
+	JobTitle    string            `json:"job_title,omitempty"`
+	BatchID     string            `json:"batch_id,omitempty"`
+	Letters     map[string]string `json:"letters,omitempty"`        // Si completed : ID de la lettre créée par type
+	LetterID    string            `json:"letter_id,omitempty"`      // Révision : lettre révisée
+	Version     int               `json:"letter_version,omitempty"` // Révision : version créée
+	Error       string            `json:"error,omitempty"`
+	RetryCount  int               `json:"retry_count"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// NewWebhookJobData données webhook d'un job
+func NewWebhookJobData(job *LetterJob) WebhookJobData {
+	data := WebhookJobData{
+		JobID:       job.JobID,
+		Kind:        job.Kind,
+		Status:      job.Status,
+		CompanyName: job.CompanyName,
+		JobTitle:    job.JobTitle,
+		BatchID:     job.BatchID,
+		Version:     job.LetterVersion,
+		RetryCount:  job.RetryCount,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if data.Kind == "" {
+		data.Kind = JobKindGeneration
+	}
+	if len(job.Letters) > 0 {
+		data.Letters = make(map[string]string, len(job.Letters))
+		for letterType, id := range job.Letters {
+			data.Letters[string(letterType)] = id.String()
+		}
+	}
+	if job.LetterID != nil {
+		data.LetterID = job.LetterID.String()
+	}
+	if job.Error != nil {
+		data.Error = *job.Error
+	}
+	return data
+}
+
+// WebhookLetterData données de l'événement letter.downloaded
+type WebhookLetterData struct {
+	LetterID     string            `json:"letter_id"`
+	LetterType   models.LetterType `json:"letter_type"`
+	CompanyName  string            `json:"company_name"`
+	Format       string            `json:"format"` // "pdf" (ou "txt" sans service PDF), ou format d'export : "docx", "odt", "md", "txt"
+	FirstTime    bool              `json:"first_download"`
+	DownloadedAt time.Time         `json:"downloaded_at"`
+}
+
+// WebhookSubscriptionRequest paramètres d'un nouvel abonnement
+type WebhookSubscriptionRequest struct {
+	URL         string
+	Events      []WebhookEvent // Vide = tous
+	Description string
+	Secret      string // Vide = généré
+}
+
+// WebhookSubscriptionUpdate modification d'un abonnement (champs nil inchangés)
+type WebhookSubscriptionUpdate struct {
+	URL         *string
+	Events      *[]WebhookEvent
+	Description *string
+	Active      *bool
+}
+
+// WebhookService abonnements, publication et livraison signée des webhooks
+// Publish enregistre une livraison par abonné ; DeliverDue (job de fond) les envoie
+// et replanifie les échecs avec un backoff exponentiel.
+type WebhookService struct {
+	db          *gorm.DB
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	wake        chan struct{}
+}
+
+// NewWebhookService crée une nouvelle instance du service
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db: db,
+		client: &http.Client{
+			Timeout: DefaultWebhookTimeout,
+			// Le corps signé n'est envoyé qu'à l'URL de l'abonnement
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts: DefaultWebhookMaxAttempts,
+		backoff:     DefaultWebhookRetryBackoff,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// SetMaxAttempts définit le nombre de tentatives par livraison
+func (s *WebhookService) SetMaxAttempts(attempts int) {
+	if attempts > 0 {
+		s.maxAttempts = attempts
+	}
+}
+
+// SetRetryBackoff définit le délai avant la 2e tentative
+func (s *WebhookService) SetRetryBackoff(backoff time.Duration) {
+	if backoff > 0 {
+		s.backoff = backoff
+	}
+}
+
+// SetTimeout définit le délai de réponse d'un abonné
+func (s *WebhookService) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.client.Timeout = timeout
+	}
+}
+
+// Wake signale les livraisons publiées depuis la dernière passe
+func (s *WebhookService) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// ParseWebhookEvents valide une liste d'événements (vide = tous)
+func ParseWebhookEvents(values []string) ([]WebhookEvent, error) {
+	events := make([]WebhookEvent, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		known := false
+		for _, event := range WebhookEvents {
+			if string(event) == value {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, value)
+		}
+		events = append(events, WebhookEvent(value))
+	}
+	return events, nil
+}
+
+// validateWebhookURL vérifie l'URL de livraison (http/https absolue)
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	return nil
+}
+
+func joinWebhookEvents(events []WebhookEvent) string {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = string(event)
+	}
+	return strings.Join(values, ",")
+}
+
+// generateWebhookSecret secret de signature aléatoire
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// CreateSubscription crée un abonnement actif (secret généré si absent)
+func (s *WebhookService) CreateSubscription(ctx context.Context, req WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	target := strings.TrimSpace(req.URL)
+	if err := validateWebhookURL(target); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	subscription := &models.WebhookSubscription{
+		ID:          uuid.New(),
+		URL:         target,
+		Secret:      secret,
+		Events:      joinWebhookEvents(req.Events),
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+	}
+	if err := s.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions liste les abonnements (plus récents d'abord)
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetSubscription retourne un abonnement
+func (s *WebhookService) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := s.db.WithContext(ctx).First(&subscription, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscription modifie un abonnement
+// Les livraisons en attente d'un abonnement désactivé sont abandonnées à leur prochaine tentative.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id uuid.UUID, update WebhookSubscriptionUpdate) (*models.WebhookSubscription, error) {
+	subscription, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.URL != nil {
+		target := strings.TrimSpace(*update.URL)
+		if err := validateWebhookURL(target); err != nil {
+			return nil, err
+		}
+		subscription.URL = target
+	}
+	if update.Events != nil {
+		subscription.Events = joinWebhookEvents(*update.Events)
+	}
+	if update.Description != nil {
+		subscription.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.Active != nil {
+		subscription.Active = *update.Active
+	}
+
+	if err := s.db.WithContext(ctx).Save(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription supprime un abonnement et son journal de livraisons
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+		result := tx.Delete(&models.WebhookSubscription{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webhook subscription: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+// Publish enregistre la livraison d'un événement à chaque abonné actif concerné
+// L'envoi est asynchrone (DeliverDue) : un abonné lent ou injoignable ne ralentit pas l'appelant.
+func (s *WebhookService) Publish(ctx context.Context, event WebhookEvent, data any) error {
+	var subscriptions []models.WebhookSubscription
+	if err := s.db.WithContext(ctx).Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	eventID := uuid.New()
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(string(event)) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(WebhookPayload{ID: eventID.String(), Event: event, CreatedAt: time.Now().UTC(), Data: data})
+			if err != nil {
+				return fmt.Errorf("failed to marshal webhook payload: %w", err)
+			}
+		}
+
+		now := time.Now()
+		deliveries = append(deliveries, models.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			Event:          string(event),
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("failed to record webhook deliveries: %w", err)
+	}
+	s.notify()
+	return nil
+}
+
+// notify réveille le job de livraison sans bloquer
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ListDeliveries journal des livraisons d'un abonnement (plus récentes d'abord)
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver planifie une nouvelle livraison d'un événement déjà livré (ou abandonné)
+// Le corps est identique ; la signature est recalculée à l'envoi.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := s.db.WithContext(ctx).First(&original, "id = ?", deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if _, err := s.GetSubscription(ctx, original.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		RedeliveryOf:   &original.ID,
+		NextAttemptAt:  &now,
+	}
+	if err := s.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	s.notify()
+	return delivery, nil
+}
+
+// DeliverDue envoie les livraisons dont la tentative est due
+// Plusieurs instances peuvent l'exécuter : une livraison est réservée (tentative comptée)
+// par une mise à jour conditionnelle avant l'envoi. Retourne le nombre de livraisons réussies.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(webhookDeliveryBatch).
+		Find(&deliveries).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	subscriptions := make(map[uuid.UUID]*models.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+		subscription, err := s.GetSubscription(ctx, delivery.SubscriptionID)
+		if err != nil && !errors.Is(err, ErrWebhookNotFound) {
+			return 0, err
+		}
+		subscriptions[delivery.SubscriptionID] = subscription // nil si supprimé
+	}
+
+	results := make([]bool, len(deliveries))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(webhookConcurrency)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if !s.reserve(groupCtx, delivery) {
+			continue
+		}
+		group.Go(func() error {
+			results[i] = s.attempt(groupCtx, delivery, subscriptions[delivery.SubscriptionID])
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	delivered := 0
+	for _, ok := range results {
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// reserve compte la tentative et repousse la suivante le temps de l'envoi
+// Retourne false si une autre instance a réservé la livraison entre-temps.
+func (s *WebhookService) reserve(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	lease := time.Now().Add(2 * s.client.Timeout)
+	result := s.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.WebhookDeliveryPending, delivery.Attempts).
+		Updates(map[string]any{"attempts": delivery.Attempts + 1, "next_attempt_at": lease})
+	if result.Error != nil {
+		log.Warn().Err(result.Error).Str("delivery_id", delivery.ID.String()).Msg("Failed to reserve webhook delivery")
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+	delivery.Attempts++
+	return true
+}
+
+// attempt envoie une livraison et enregistre son résultat
+func (s *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) bool {
+	logger := log.With().Str("delivery_id", delivery.ID.String()).Str("event", delivery.Event).Logger()
+	now := time.Now()
+	updates := map[string]any{}
+
+	if subscription == nil || !subscription.Active {
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = "subscription disabled"
+		updates["next_attempt_at"] = nil
+		s.saveAttempt(ctx, delivery, updates)
+		return false
+	}
+
+	status, body, err := s.send(ctx, delivery, subscription)
+	updates["response_status"] = status
+	updates["response_body"] = body
+
+	if err == nil && status >= 200 && status < 300 {
+		updates["status"] = models.WebhookDeliveryDelivered
+		updates["last_error"] = ""
+		updates["next_attempt_at"] = nil
+		updates["delivered_at"] = now
+		s.saveAttempt(ctx, delivery, updates)
+		return true
+	}
+
+	if err != nil {
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_error"] = fmt.Sprintf("unexpected status %d", status)
+	}
+
+	if delivery.Attempts >= s.maxAttempts {
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["next_attempt_at"] = nil
+		logger.Warn().Int("attempts", delivery.Attempts).Interface("error", updates["last_error"]).Msg("Webhook delivery abandoned")
+	} else {
+		updates["next_attempt_at"] = now.Add(s.retryDelay(delivery.Attempts))
+	}
+	s.saveAttempt(ctx, delivery, updates)
+	return false
+}
+
+// retryDelay délai avant la tentative suivant la n-ième (backoff exponentiel plafonné)
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
+
+// send POST signé de la livraison ; retourne le statut et le début de la réponse
+func (s *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhookPayload(subscription.Secret, timestamp, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	content, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	return resp.StatusCode, string(content), nil
+}
+
+// saveAttempt enregistre le résultat d'une tentative
+func (s *WebhookService) saveAttempt(ctx context.Context, delivery *models.WebhookDelivery, updates map[string]any) {
+	err := s.db.WithContext(context.WithoutCancel(ctx)).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(updates).Error
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", delivery.ID.String()).Msg("Failed to record webhook delivery attempt")
+	}
+}
+
+// CleanupDeliveries supprime du journal les livraisons terminées depuis plus de 30 jours
+func (s *WebhookService) CleanupDeliveries(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("status <> ? AND updated_at < ?", models.WebhookDeliveryPending, time.Now().Add(-webhookDeliveryRetention)).
+		Delete(&models.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete old webhook deliveries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// SignWebhookPayload signature HMAC-SHA256 (hex) de "<timestamp>.<corps>"
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature vérifie le header X-Maicivy-Signature d'une livraison reçue
+// tolerance borne l'âge de la signature (protection contre le rejeu, 0 = pas de limite).
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidWebhookSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+
+	expected := SignWebhookPayload(secret, timestamp, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"maicivy/internal/models"
+)
+
+func newTestWebhookService(t *testing.T) (*WebhookService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+	return NewWebhookService(db), db
+}
+
+// webhookReceiver abonné de test : vérifie la signature et répond avec le statut courant
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   atomic.Int32
+	payloads []WebhookPayload
+	headers  []http.Header
+	invalid  int
+}
+
+func newWebhookReceiver(t *testing.T, secret string) (*webhookReceiver, *httptest.Server) {
+	receiver := &webhookReceiver{secret: secret}
+	receiver.status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		if err := VerifyWebhookSignature(receiver.secret, r.Header.Get(WebhookSignatureHeader), body, time.Minute); err != nil {
+			receiver.invalid++
+		}
+		var payload WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		receiver.payloads = append(receiver.payloads, payload)
+		receiver.headers = append(receiver.headers, r.Header.Clone())
+
+		w.WriteHeader(int(receiver.status.Load()))
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"job.completed"}`)
+	now := time.Now().Unix()
+	header := "t=" + strconv.FormatInt(now, 10) + ",v1=" + SignWebhookPayload("secret", now, body)
+
+	assert.NoError(t, VerifyWebhookSignature("secret", header, body, time.Minute))
+	assert.ErrorIs(t, VerifyWebhookSignature("other", header, body, time.Minute), ErrInvalidWebhookSignature)
+	assert.ErrorIs(t, VerifyWebhookSignature("secret", header, []byte(`{}`), time.Minute), ErrInvalidWebhookSignature)
+	assert.ErrorIs(t, VerifyWebhookSignature("secret", "v1=abc", body, 0), ErrInvalidWebhookSignature)
+
+	// Signature rejouée après la tolérance
+	old := now - 3600
+	header = "t=" + strconv.FormatInt(old, 10) + ",v1=" + SignWebhookPayload("secret", old, body)
+	assert.ErrorIs(t, VerifyWebhookSignature("secret", header, body, 5*time.Minute), ErrInvalidWebhookSignature)
+	assert.NoError(t, VerifyWebhookSignature("secret", header, body, 0))
+}
+
+func TestWebhookService_PublishAndDeliver(t *testing.T) {
+	service, db := newTestWebhookService(t)
+	ctx := context.Background()
+
+	receiver, server := newWebhookReceiver(t, "whsec_test_secret_value")
+	all, err := service.CreateSubscription(ctx, WebhookSubscriptionRequest{URL: server.URL, Secret: "whsec_test_secret_value"})
+	require.NoError(t, err)
+	completedOnly, err := service.CreateSubscription(ctx, WebhookSubscriptionRequest{URL: server.URL + "/ats", Events: []WebhookEvent{WebhookEventJobCompleted}})
+	require.NoError(t, err)
+	assert.Contains(t, completedOnly.Secret, "whsec_")
+
+	_, err = service.CreateSubscription(ctx, WebhookSubscriptionRequest{URL: "ftp://example.com"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	job := &LetterJob{JobID: "job-1", VisitorID: "session-secret", CompanyName: "Google", Status: JobStatusQueued}
+	require.NoError(t, service.Publish(ctx, WebhookEventJobQueued, NewWebhookJobData(job)))
+
+	var deliveries []models.WebhookDelivery
+	db.Find(&deliveries)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, all.ID, deliveries[0].SubscriptionID)
+	assert.NotContains(t, deliveries[0].Payload, "session-secret")
+
+	select {
+	case <-service.Wake():
+	default:
+		t.Fatal("publish should wake the dispatcher")
+	}
+
+	delivered, err := service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	require.Len(t, receiver.payloads, 1)
+	assert.Zero(t, receiver.invalid)
+	assert.Equal(t, WebhookEventJobQueued, receiver.payloads[0].Event)
+	assert.Equal(t, "job.queued", receiver.headers[0].Get(WebhookEventHeader))
+	assert.Equal(t, deliveries[0].ID.String(), receiver.headers[0].Get(WebhookDeliveryHeader))
+
+	var delivery models.WebhookDelivery
+	db.First(&delivery, "id = ?", deliveries[0].ID)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.NextAttemptAt)
+
+	// Rien de plus à livrer
+	delivered, _ = service.DeliverDue(ctx)
+	assert.Zero(t, delivered)
+}
+
+func TestWebhookService_RetryAndRedeliver(t *testing.T) {
+	service, db := newTestWebhookService(t)
+	service.SetMaxAttempts(2)
+	service.SetRetryBackoff(time.Millisecond)
+	ctx := context.Background()
+
+	receiver, server := newWebhookReceiver(t, "whsec_test_secret_value")
+	receiver.status.Store(http.StatusInternalServerError)
+	subscription, err := service.CreateSubscription(ctx, WebhookSubscriptionRequest{URL: server.URL, Secret: "whsec_test_secret_value"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.Publish(ctx, WebhookEventJobFailed, map[string]string{"job_id": "job-1"}))
+
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, "unexpected status 500", delivery.LastError)
+	require.NotNil(t, delivery.NextAttemptAt)
+
+	// Seconde tentative : abandon
+	time.Sleep(5 * time.Millisecond)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	var abandoned models.WebhookDelivery
+	db.First(&abandoned, "id = ?", delivery.ID)
+	assert.Equal(t, models.WebhookDeliveryFailed, abandoned.Status)
+	assert.Equal(t, 2, abandoned.Attempts)
+	assert.Nil(t, abandoned.NextAttemptAt)
+
+	// Re-livraison manuelle : même événement, nouvelle livraison
+	receiver.status.Store(http.StatusNoContent)
+	redelivery, err := service.Redeliver(ctx, abandoned.ID)
+	require.NoError(t, err)
+	assert.Equal(t, abandoned.EventID, redelivery.EventID)
+	assert.Equal(t, abandoned.ID, *redelivery.RedeliveryOf)
+
+	delivered, err := service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, receiver.payloads, 3)
+	assert.Equal(t, receiver.payloads[0].ID, receiver.payloads[2].ID)
+
+	history, err := service.ListDeliveries(ctx, subscription.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	_, err = service.Redeliver(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookService_DisabledSubscription(t *testing.T) {
+	service, db := newTestWebhookService(t)
+	ctx := context.Background()
+
+	receiver, server := newWebhookReceiver(t, "whsec_test_secret_value")
+	subscription, err := service.CreateSubscription(ctx, WebhookSubscriptionRequest{URL: server.URL})
+	require.NoError(t, err)
+	require.NoError(t, service.Publish(ctx, WebhookEventJobCompleted, map[string]string{"job_id": "job-1"}))
+
+	// Désactivé avant la livraison : abandonnée sans envoi
+	active := false
+	_, err = service.UpdateSubscription(ctx, subscription.ID, WebhookSubscriptionUpdate{Active: &active})
+	require.NoError(t, err)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, "subscription disabled", delivery.LastError)
+	assert.Empty(t, receiver.payloads)
+
+	// Abonnement inactif : plus aucune livraison enregistrée
+	require.NoError(t, service.Publish(ctx, WebhookEventJobCompleted, map[string]string{"job_id": "job-2"}))
+	var count int64
+	db.Model(&models.WebhookDelivery{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	require.NoError(t, service.DeleteSubscription(ctx, subscription.ID))
+	db.Model(&models.WebhookDelivery{}).Count(&count)
+	assert.Zero(t, count)
+	assert.ErrorIs(t, service.DeleteSubscription(ctx, subscription.ID), ErrWebhookNotFound)
+}
+
+func TestWebhookService_RetryDelay(t *testing.T) {
+	service := NewWebhookService(nil)
+	assert.Equal(t, 30*time.Second, service.retryDelay(1))
+	assert.Equal(t, 60*time.Second, service.retryDelay(2))
+	assert.Equal(t, 4*time.Minute, service.retryDelay(4))
+	assert.Equal(t, webhookMaxBackoff, service.retryDelay(50))
+}
+
+// recordingPublisher publisher de test : événements reçus dans l'ordre
+type recordingPublisher struct {
+	events []WebhookEvent
+	data   []WebhookJobData
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event WebhookEvent, data any) error {
+	p.events = append(p.events, event)
+	p.data = append(p.data, data.(WebhookJobData))
+	return nil
+}
+
+func TestLetterQueueService_WebhookEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	queue := NewLetterQueueService(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	publisher := &recordingPublisher{}
+	queue.SetWebhookPublisher(publisher)
+
+	completed, err := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Google"})
+	require.NoError(t, err)
+	failed, _ := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Meta"})
+	dead, _ := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Apple"})
+
+	letterID := uuid.New()
+	require.NoError(t, queue.CompleteJob(completed, map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: letterID}))
+	require.NoError(t, queue.FailJob(failed, "AI provider unavailable"))
+	require.NoError(t, queue.DeadLetterJob(dead, "max retries reached"))
+
+	// Progression et retry : pas d'événement
+	require.NoError(t, queue.UpdateJobStatus(dead, JobStatusProcessing, 50))
+
+	assert.Equal(t, []WebhookEvent{
+		WebhookEventJobQueued, WebhookEventJobQueued, WebhookEventJobQueued,
+		WebhookEventJobCompleted, WebhookEventJobFailed, WebhookEventJobFailed,
+	}, publisher.events)
+	assert.Equal(t, letterID.String(), publisher.data[3].Letters["motivation"])
+	assert.Equal(t, "AI provider unavailable", publisher.data[4].Error)
+	assert.Equal(t, JobKindGeneration, publisher.data[5].Kind)
+}
+
+func TestLetterQueueService_CancelledJobNotOverwritten(t *testing.T) {
+	mr := miniredis.RunT(t)
+	queue := NewLetterQueueService(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	publisher := &recordingPublisher{}
+	queue.SetWebhookPublisher(publisher)
+
+	jobID, err := queue.EnqueueJob(LetterJobRequest{VisitorID: "session-1", CompanyName: "Google"})
+	require.NoError(t, err)
+	require.NoError(t, queue.UpdateJobStatus(jobID, JobStatusProcessing, 60))
+	_, err = queue.CancelJob(jobID)
+	require.NoError(t, err)
+	events := len(publisher.events)
+
+	// Le worker termine l'appel IA après l'annulation
+	err = queue.CompleteJob(jobID, map[models.LetterType]uuid.UUID{models.LetterTypeMotivation: uuid.New()})
+	assert.ErrorIs(t, err, ErrJobFinished)
+	assert.ErrorIs(t, queue.CompleteRevision(jobID, 2), ErrJobFinished)
+	assert.ErrorIs(t, queue.FailJob(jobID, "AI provider unavailable"), ErrJobFinished)
+
+	job, err := queue.GetJobStatus(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, job.Status)
+	assert.Empty(t, job.Letters)
+	require.NotNil(t, job.Error)
+	assert.Equal(t, "cancelled", *job.Error)
+	assert.Len(t, publisher.events, events, "no webhook for a cancelled job")
+	assert.NotContains(t, publisher.events, WebhookEventJobCompleted)
+	assert.NotContains(t, publisher.events, WebhookEventJobFailed)
+}
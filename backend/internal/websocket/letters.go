@@ -0,0 +1,120 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"maicivy/internal/services"
+)
+
+// LetterStreamWSHandler diffuse la génération d'un job de lettres via WebSocket
+type LetterStreamWSHandler struct {
+	queueService  services.LetterQueueServiceInterface
+	streamService *services.LetterStreamService
+}
+
+// NewLetterStreamWSHandler crée un nouveau handler WebSocket de streaming de lettres
+func NewLetterStreamWSHandler(queueService services.LetterQueueServiceInterface, streamService *services.LetterStreamService) *LetterStreamWSHandler {
+	return &LetterStreamWSHandler{
+		queueService:  queueService,
+		streamService: streamService,
+	}
+}
+
+// RegisterRoutes enregistre la route WebSocket
+func (h *LetterStreamWSHandler) RegisterRoutes(app *fiber.App) {
+	// Middleware pour upgrade HTTP -> WebSocket
+	app.Use("/ws/letters", func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return c.Next()
+		}
+		return fiber.ErrUpgradeRequired
+	})
+
+	// Route WebSocket
+	app.Get("/ws/letters/:jobId", websocket.New(h.HandleConnection))
+}
+
+// HandleConnection rejoue puis transmet les événements du job jusqu'à sa fin
+func (h *LetterStreamWSHandler) HandleConnection(c *websocket.Conn) {
+	defer c.Close()
+
+	jobID := c.Params("jobId")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	job, err := h.queueService.GetJobStatus(jobID)
+	if err != nil {
+		h.writeJSON(c, map[string]interface{}{
+			"type":  "error",
+			"error": "job not found",
+		})
+		return
+	}
+
+	// Job déjà terminé : envoyer directement l'événement final
+	if final := job.TerminalStreamEvent(); final != nil {
+		h.writeJSON(c, final)
+		return
+	}
+
+	// Détecter la déconnexion du client (la lecture échoue à la fermeture)
+	go func() {
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	lastID := "0"
+	for {
+		events, err := h.streamService.Read(ctx, jobID, lastID, 10*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Str("job_id", jobID).Msg("Failed to read letter stream")
+			}
+			return
+		}
+
+		for _, event := range events {
+			if !h.writeJSON(c, event) {
+				return
+			}
+			lastID = event.ID
+			if event.IsTerminal() {
+				return
+			}
+		}
+
+		// Le flux a pu expirer alors que le job s'est terminé entre-temps
+		if len(events) == 0 {
+			job, err := h.queueService.GetJobStatus(jobID)
+			if err == nil {
+				if final := job.TerminalStreamEvent(); final != nil {
+					h.writeJSON(c, final)
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeJSON envoie un message JSON, retourne false si l'envoi a échoué
+func (h *LetterStreamWSHandler) writeJSON(c *websocket.Conn, payload interface{}) bool {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	if err := c.WriteMessage(websocket.TextMessage, data); err != nil {
+		log.Debug().Err(err).Msg("Failed to send letter stream event")
+		return false
+	}
+	return true
+}
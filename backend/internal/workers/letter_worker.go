@@ -3,8 +3,12 @@ package workers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,20 +18,69 @@ import (
 	"maicivy/internal/services"
 )
 
-// LetterWorker worker pour traiter les jobs de génération de lettres
+// Valeurs par défaut du pool
+const (
+	DefaultConcurrency      = 2
+	DefaultBatchConcurrency = 1 // Slots réservés aux campagnes (file dédiée)
+	DefaultShutdownTimeout  = 30 * time.Second
+)
+
+// State état du pool de workers
+type State string
+
+const (
+	StateStopped  State = "stopped"
+	StateRunning  State = "running"
+	StateDraining State = "draining" // Plus de nouveaux jobs, fin des jobs en cours
+)
+
+// Status état du pool exposé par /health/deep
+type Status struct {
+	ID               string     `json:"id"`
+	State            State      `json:"state"`
+	Concurrency      int        `json:"concurrency"`
+	BatchConcurrency int        `json:"batch_concurrency"` // Slots des campagnes (en plus de Concurrency)
+	Active           int        `json:"active"`            // Jobs en cours
+	Processed        int64      `json:"processed"`         // Jobs complétés depuis le démarrage
+	Failed           int64      `json:"failed"`            // Jobs définitivement échoués depuis le démarrage
+	LastHeartbeat    *time.Time `json:"last_heartbeat,omitempty"`
+	Healthy          bool       `json:"healthy"` // En marche et heartbeat récent
+}
+
+// LetterWorker pool de workers traitant les jobs de génération de lettres
+// Les jobs réclamés restent dans la liste "processing" du pool jusqu'à leur fin ;
+// ses heartbeats empêchent le reaper de les remettre en file. À l'arrêt, les jobs
+// en cours disposent d'un délai pour se terminer, puis sont annulés et rendus à la queue.
 type LetterWorker struct {
+	id              string
 	db              *gorm.DB
 	queueService    *services.LetterQueueService
 	aiService       *services.AIService
 	scraper         *services.CompanyScraper
 	letterGenerator *services.LetterGenerator
 	profileBuilder  *services.ProfileBuilder
-
-	stopChan chan bool
-	running  bool
+	streamService   *services.LetterStreamService
+	revisionService *services.LetterRevisionService
+
+	concurrency      int
+	batchConcurrency int
+	shutdownTimeout  time.Duration
+
+	mu           sync.Mutex
+	state        State
+	stopClaiming context.CancelFunc // Arrête la réclamation de nouveaux jobs
+	cancelJobs   context.CancelFunc // Annule les jobs en cours (délai d'arrêt dépassé)
+	slots        sync.WaitGroup
+	heartbeat    sync.WaitGroup
+	inFlight     map[string]context.CancelFunc // Jobs en cours → annulation (DELETE du job)
+
+	active        atomic.Int32
+	processed     atomic.Int64
+	failed        atomic.Int64
+	lastHeartbeat atomic.Int64 // UnixNano, 0 si aucun
 }
 
-// NewLetterWorker crée une nouvelle instance du worker
+// NewLetterWorker crée une nouvelle instance du pool
 func NewLetterWorker(
 	db *gorm.DB,
 	queueService *services.LetterQueueService,
@@ -35,73 +88,292 @@ func NewLetterWorker(
 	scraper *services.CompanyScraper,
 	letterGenerator *services.LetterGenerator,
 	profileBuilder *services.ProfileBuilder,
+	streamService *services.LetterStreamService,
+	revisionService *services.LetterRevisionService,
 ) *LetterWorker {
 	return &LetterWorker{
-		db:              db,
-		queueService:    queueService,
-		aiService:       aiService,
-		scraper:         scraper,
-		letterGenerator: letterGenerator,
-		profileBuilder:  profileBuilder,
-		stopChan:        make(chan bool),
-		running:         false,
+		id:               newWorkerID(),
+		db:               db,
+		queueService:     queueService,
+		aiService:        aiService,
+		scraper:          scraper,
+		letterGenerator:  letterGenerator,
+		profileBuilder:   profileBuilder,
+		streamService:    streamService,
+		revisionService:  revisionService,
+		concurrency:      DefaultConcurrency,
+		batchConcurrency: DefaultBatchConcurrency,
+		shutdownTimeout:  DefaultShutdownTimeout,
+		state:            StateStopped,
+		inFlight:         make(map[string]context.CancelFunc),
+	}
+}
+
+// newWorkerID identifiant unique du pool (hôte, processus, instance)
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
 	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}
+
+// SetConcurrency définit le nombre de jobs traités simultanément (avant Start)
+func (w *LetterWorker) SetConcurrency(concurrency int) {
+	if concurrency > 0 {
+		w.concurrency = concurrency
+	}
+}
+
+// SetBatchConcurrency définit le nombre de jobs de campagne traités simultanément (avant Start)
+// Ces slots ne consomment que la file des campagnes ; 0 désactive les campagnes sur cette instance.
+func (w *LetterWorker) SetBatchConcurrency(concurrency int) {
+	if concurrency >= 0 {
+		w.batchConcurrency = concurrency
+	}
+}
+
+// SetShutdownTimeout définit le délai laissé aux jobs en cours lors de l'arrêt
+func (w *LetterWorker) SetShutdownTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		w.shutdownTimeout = timeout
+	}
+}
+
+// ID retourne l'identifiant du pool dans la queue
+func (w *LetterWorker) ID() string {
+	return w.id
 }
 
-// Start démarre le worker
-func (w *LetterWorker) Start() {
-	if w.running {
+// Start démarre le pool (non bloquant)
+// L'annulation de ctx arrête la réclamation de nouveaux jobs ; Shutdown attend les jobs en cours.
+func (w *LetterWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.state != StateStopped {
 		log.Println("[LetterWorker] Already running")
 		return
 	}
 
-	w.running = true
-	log.Println("[LetterWorker] Starting...")
+	claimCtx, stopClaiming := context.WithCancel(ctx)
+	// Les jobs en cours survivent à l'annulation de ctx jusqu'au délai d'arrêt
+	jobsCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	w.stopClaiming, w.cancelJobs = stopClaiming, cancelJobs
+	w.state = StateRunning
+
+	log.Printf("[LetterWorker] Starting %d workers and %d batch workers (id %s)...", w.concurrency, w.batchConcurrency, w.id)
+
+	heartbeatDone := make(chan struct{})
+	w.heartbeat.Add(1)
+	go w.heartbeatLoop(heartbeatDone)
+
+	for i := 0; i < w.concurrency; i++ {
+		w.slots.Add(1)
+		go w.run(claimCtx, jobsCtx, w.queueService.ClaimJob)
+	}
+	// Budget propre aux campagnes : les visiteurs ne patientent pas derrière 50 entreprises
+	for i := 0; i < w.batchConcurrency; i++ {
+		w.slots.Add(1)
+		go w.run(claimCtx, jobsCtx, w.queueService.ClaimBatchJob)
+	}
+
+	// Annulations des jobs en cours (toutes instances)
+	go w.watchCancellations(jobsCtx)
+
+	// Heartbeats maintenus tant que des jobs sont en cours
+	go func() {
+		w.slots.Wait()
+		close(heartbeatDone)
+	}()
+}
+
+// Shutdown arrête le pool : plus de nouveaux jobs, attente des jobs en cours jusqu'à
+// l'échéance de ctx, puis annulation de ceux qui restent (rendus à la queue)
+func (w *LetterWorker) Shutdown(ctx context.Context) error {
+	w.mu.Lock()
+	if w.state != StateRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	w.state = StateDraining
+	w.stopClaiming()
+	w.mu.Unlock()
+
+	log.Printf("[LetterWorker] Draining %d in-flight jobs...", w.active.Load())
+
+	drained := make(chan struct{})
+	go func() {
+		w.slots.Wait()
+		w.heartbeat.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		log.Printf("[LetterWorker] Shutdown deadline reached, re-queueing %d unfinished jobs", w.active.Load())
+		w.cancelJobs()
+		<-drained
+		err = ctx.Err()
+	}
+	w.cancelJobs()
+
+	w.mu.Lock()
+	w.state = StateStopped
+	w.mu.Unlock()
+
+	log.Println("[LetterWorker] Stopped")
+	return err
+}
+
+// Stop arrête le pool avec le délai d'arrêt configuré
+func (w *LetterWorker) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.shutdownTimeout)
+	defer cancel()
+	return w.Shutdown(ctx)
+}
+
+// IsRunning retourne true si le pool traite (ou termine) des jobs
+func (w *LetterWorker) IsRunning() bool {
+	return w.State() != StateStopped
+}
+
+// State retourne l'état du pool
+func (w *LetterWorker) State() State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Status retourne l'état du pool et de ses heartbeats
+func (w *LetterWorker) Status() Status {
+	status := Status{
+		ID:               w.id,
+		State:            w.State(),
+		Concurrency:      w.concurrency,
+		BatchConcurrency: w.batchConcurrency,
+		Active:           int(w.active.Load()),
+		Processed:        w.processed.Load(),
+		Failed:           w.failed.Load(),
+	}
+
+	if nanos := w.lastHeartbeat.Load(); nanos > 0 {
+		last := time.Unix(0, nanos)
+		status.LastHeartbeat = &last
+		status.Healthy = status.State == StateRunning && time.Since(last) < w.queueService.VisibilityTimeout()
+	}
+
+	return status
+}
+
+// run boucle d'un slot du pool : réclame (via claim) et traite les jobs jusqu'à l'arrêt
+func (w *LetterWorker) run(claimCtx, jobsCtx context.Context, claim func(workerID string) (string, error)) {
+	defer w.slots.Done()
+
+	for claimCtx.Err() == nil {
+		jobID, err := claim(w.id)
+		if err != nil {
+			log.Printf("[LetterWorker] Error claiming job: %v", err)
+			sleepContext(claimCtx, 2*time.Second) // Attendre avant retry
+			continue
+		}
+		if jobID == "" {
+			continue // Queue vide (ClaimJob a attendu)
+		}
+
+		w.processJob(jobsCtx, jobID)
+	}
+}
+
+// sleepContext attend la durée donnée ou l'annulation du contexte
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// heartbeatLoop signale le pool comme actif jusqu'à la fin du dernier job, y compris
+// pendant le traitement des jobs
+func (w *LetterWorker) heartbeatLoop(done <-chan struct{}) {
+	defer w.heartbeat.Done()
+
+	ticker := time.NewTicker(w.queueService.VisibilityTimeout() / 3)
+	defer ticker.Stop()
 
 	for {
+		w.sendHeartbeat()
 		select {
-		case <-w.stopChan:
-			log.Println("[LetterWorker] Stopped")
-			w.running = false
+		case <-done:
 			return
-		default:
-			w.processNextJob()
+		case <-ticker.C:
 		}
 	}
 }
 
-// Stop arrête le worker
-func (w *LetterWorker) Stop() {
-	if !w.running {
+// sendHeartbeat émet un heartbeat et mémorise sa date
+func (w *LetterWorker) sendHeartbeat() {
+	if err := w.queueService.Heartbeat(w.id); err != nil {
+		log.Printf("[LetterWorker] Error sending heartbeat: %v", err)
 		return
 	}
-
-	log.Println("[LetterWorker] Stopping...")
-	w.stopChan <- true
+	w.lastHeartbeat.Store(time.Now().UnixNano())
 }
 
-// IsRunning retourne true si le worker est en cours d'exécution
-func (w *LetterWorker) IsRunning() bool {
-	return w.running
-}
+// watchCancellations annule le contexte des jobs en cours annulés via la queue
+func (w *LetterWorker) watchCancellations(ctx context.Context) {
+	for jobID := range w.queueService.SubscribeCancellations(ctx) {
+		w.mu.Lock()
+		cancel, ok := w.inFlight[jobID]
+		w.mu.Unlock()
 
-// processNextJob traite le prochain job dans la queue
-func (w *LetterWorker) processNextJob() {
-	jobID, err := w.queueService.PopJob()
-	if err != nil {
-		log.Printf("[LetterWorker] Error popping job: %v", err)
-		time.Sleep(2 * time.Second) // Attendre avant retry
-		return
+		if ok {
+			log.Printf("[LetterWorker] Cancelling job %s", jobID)
+			cancel()
+		}
 	}
+}
 
-	if jobID == "" {
-		// Queue vide, attendre un peu
-		time.Sleep(1 * time.Second)
-		return
+// trackJob enregistre un job en cours et retourne son contexte annulable
+func (w *LetterWorker) trackJob(ctx context.Context, jobID string) (context.Context, func()) {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	w.mu.Lock()
+	w.inFlight[jobID] = cancel
+	w.mu.Unlock()
+
+	return jobCtx, func() {
+		w.mu.Lock()
+		delete(w.inFlight, jobID)
+		w.mu.Unlock()
+		cancel()
 	}
+}
+
+// processJob traite un job réclamé
+// Un job interrompu (arrêt du pool ou annulation) est rendu à la queue s'il n'est pas terminé,
+// les autres quittent la liste "processing".
+func (w *LetterWorker) processJob(ctx context.Context, jobID string) {
+	w.active.Add(1)
+	defer w.active.Add(-1)
 
 	log.Printf("[LetterWorker] Processing job: %s", jobID)
 
+	ctx, untrack := w.trackJob(ctx, jobID)
+	defer untrack()
+
+	defer func() {
+		if ctx.Err() != nil {
+			w.release(jobID)
+			return
+		}
+		w.ack(jobID)
+	}()
+
 	// Récupérer les détails du job
 	job, err := w.queueService.GetJobStatus(jobID)
 	if err != nil {
@@ -109,57 +381,247 @@ func (w *LetterWorker) processNextJob() {
 		return
 	}
 
+	// Doublon d'un job déjà terminé (worker arrêté entre la fin du job et son ack)
+	if job.IsTerminal() {
+		log.Printf("[LetterWorker] Skipping job %s already %s", jobID, job.Status)
+		return
+	}
+
 	// Marquer comme en cours
 	err = w.queueService.UpdateJobStatus(jobID, services.JobStatusProcessing, 10)
 	if err != nil {
 		log.Printf("[LetterWorker] Error updating job status: %v", err)
 	}
+	w.publish(jobID, services.LetterStreamEvent{Type: services.StreamEventStatus, Progress: 10})
+
+	if job.IsRevision() {
+		w.processRevision(ctx, job)
+		return
+	}
 
 	// Exécuter la génération
-	motivationID, antiMotivationID, err := w.generateLetters(job)
+	letterIDs, err := w.generateLetters(ctx, job)
 	if err != nil {
 		log.Printf("[LetterWorker] Error generating letters: %v", err)
+		w.handleJobError(ctx, job, err)
+		return
+	}
 
-		// Retry logic
-		if job.RetryCount < job.MaxRetries {
-			log.Printf("[LetterWorker] Retrying job %s (attempt %d/%d)", jobID, job.RetryCount+1, job.MaxRetries)
-			w.queueService.RetryJob(jobID)
-		} else {
-			log.Printf("[LetterWorker] Max retries reached for job %s", jobID)
-			w.queueService.FailJob(jobID, err.Error())
+	// Marquer comme complété
+	err = w.queueService.CompleteJob(jobID, letterIDs)
+	if err != nil {
+		if errors.Is(err, services.ErrJobFinished) {
+			log.Printf("[LetterWorker] Job finished before completion (cancelled)")
+			return
 		}
+		log.Printf("[LetterWorker] Error completing job: %v", err)
 		return
 	}
 
-	// Marquer comme complété
-	err = w.queueService.CompleteJob(jobID, motivationID, antiMotivationID)
+	// Les IDs motivation / anti-motivation restent diffusés pour les clients existants
+	completed := services.LetterStreamEvent{
+		Type:     services.StreamEventCompleted,
+		Progress: 100,
+		Letters:  services.LetterIDStrings(letterIDs),
+	}
+	if id, ok := letterIDs[models.LetterTypeMotivation]; ok {
+		completed.LetterMotivationID = id.String()
+	}
+	if id, ok := letterIDs[models.LetterTypeAntiMotivation]; ok {
+		completed.LetterAntiMotivationID = id.String()
+	}
+	w.publish(jobID, completed)
+	w.processed.Add(1)
+
+	log.Printf("[LetterWorker] Job %s completed. Letters: %v", jobID, letterIDs)
+}
+
+// processRevision révise une lettre existante et crée une nouvelle version
+func (w *LetterWorker) processRevision(ctx context.Context, job *services.LetterJob) {
+	if w.revisionService == nil || job.LetterID == nil {
+		w.failJob(job.JobID, "revision unavailable")
+		return
+	}
+
+	var letter models.GeneratedLetter
+	if err := w.db.WithContext(ctx).Select("id", "letter_type").First(&letter, "id = ?", *job.LetterID).Error; err != nil {
+		w.failJob(job.JobID, fmt.Sprintf("letter not found: %v", err))
+		return
+	}
+
+	w.updateProgress(job.JobID, 20)
+
+	onDelta := func(delta string) {
+		w.publish(job.JobID, services.LetterStreamEvent{
+			Type:       services.StreamEventDelta,
+			LetterType: letter.LetterType,
+			Delta:      delta,
+		})
+	}
+
+	version, err := w.revisionService.Revise(ctx, letter.ID, job.Feedback, onDelta)
+	if errors.Is(err, services.ErrRevisionLimitReached) {
+		// Inutile de réessayer
+		w.failJob(job.JobID, err.Error())
+		return
+	}
 	if err != nil {
+		log.Printf("[LetterWorker] Error revising letter %s: %v", letter.ID, err)
+		w.handleJobError(ctx, job, err)
+		return
+	}
+
+	if err := w.queueService.CompleteRevision(job.JobID, version.Version); err != nil {
+		if errors.Is(err, services.ErrJobFinished) {
+			log.Printf("[LetterWorker] Job finished before completion (cancelled)")
+			return
+		}
 		log.Printf("[LetterWorker] Error completing job: %v", err)
 		return
 	}
 
-	log.Printf("[LetterWorker] Job %s completed. Letters: %s, %s", jobID, motivationID, antiMotivationID)
+	w.publish(job.JobID, services.LetterStreamEvent{
+		Type:     services.StreamEventCompleted,
+		Progress: 100,
+		LetterID: letter.ID.String(),
+		Version:  version.Version,
+	})
+	w.processed.Add(1)
+
+	log.Printf("[LetterWorker] Job %s completed. Letter %s revised (version %d)", job.JobID, letter.ID, version.Version)
+}
+
+// handleJobError re-enqueue le job si des tentatives restent, le marque comme échoué sinon
+// Une erreur due à l'arrêt du pool ou à l'annulation du job ne consomme pas de tentative.
+func (w *LetterWorker) handleJobError(ctx context.Context, job *services.LetterJob, err error) {
+	if ctx.Err() != nil {
+		log.Printf("[LetterWorker] Job %s interrupted", job.JobID)
+		return
+	}
+
+	if job.RetryCount < job.MaxRetries {
+		log.Printf("[LetterWorker] Retrying job %s (attempt %d/%d)", job.JobID, job.RetryCount+1, job.MaxRetries)
+		w.queueService.RetryJob(job.JobID)
+		w.publish(job.JobID, services.LetterStreamEvent{Type: services.StreamEventRetry})
+		return
+	}
+
+	log.Printf("[LetterWorker] Max retries reached for job %s, moving to dead-letter queue", job.JobID)
+	if dlqErr := w.queueService.DeadLetterJob(job.JobID, err.Error()); dlqErr != nil {
+		log.Printf("[LetterWorker] Error dead-lettering job %s: %v", job.JobID, dlqErr)
+	}
+	w.failed.Add(1)
+	w.publish(job.JobID, services.LetterStreamEvent{
+		Type:  services.StreamEventFailed,
+		Error: err.Error(),
+	})
+}
+
+// release rend un job interrompu à la queue
+func (w *LetterWorker) release(jobID string) {
+	if err := w.queueService.ReleaseJob(w.id, jobID); err != nil {
+		log.Printf("[LetterWorker] Error releasing job %s: %v", jobID, err)
+		return
+	}
+	log.Printf("[LetterWorker] Job %s re-queued", jobID)
+}
+
+// ack retire le job de la liste "processing" du worker
+func (w *LetterWorker) ack(jobID string) {
+	if err := w.queueService.AckJob(w.id, jobID); err != nil {
+		log.Printf("[LetterWorker] Error acknowledging job %s: %v", jobID, err)
+	}
+}
+
+// failJob marque le job comme définitivement échoué et le diffuse
+func (w *LetterWorker) failJob(jobID string, errorMsg string) {
+	if err := w.queueService.FailJob(jobID, errorMsg); errors.Is(err, services.ErrJobFinished) {
+		// Annulé entre-temps : le statut annulé est conservé
+		return
+	}
+	w.failed.Add(1)
+	w.publish(jobID, services.LetterStreamEvent{
+		Type:  services.StreamEventFailed,
+		Error: errorMsg,
+	})
+}
+
+// updateProgress met à jour la progression du job et la diffuse aux clients en streaming
+func (w *LetterWorker) updateProgress(jobID string, progress int) {
+	w.queueService.UpdateJobStatus(jobID, services.JobStatusProcessing, progress)
+	w.publish(jobID, services.LetterStreamEvent{
+		Type:     services.StreamEventStatus,
+		Progress: progress,
+	})
+}
+
+// publish diffuse un événement de génération (no-op si le streaming n'est pas configuré)
+func (w *LetterWorker) publish(jobID string, event services.LetterStreamEvent) {
+	if w.streamService == nil {
+		return
+	}
+	if err := w.streamService.Publish(context.Background(), jobID, event); err != nil {
+		log.Printf("[LetterWorker] Error publishing stream event for job %s: %v", jobID, err)
+	}
 }
 
-// generateLetters génère les deux lettres (motivation + anti-motivation)
-func (w *LetterWorker) generateLetters(job *services.LetterJob) (uuid.UUID, uuid.UUID, error) {
+// generateLetters génère et sauvegarde une lettre par type demandé
+func (w *LetterWorker) generateLetters(ctx context.Context, job *services.LetterJob) (map[models.LetterType]uuid.UUID, error) {
 	startTime := time.Now()
-	ctx := context.Background()
+	letterTypes := job.RequestedLetterTypes()
+
+	// 1. Générer les lettres en parallèle (20-80% progress)
+	w.updateProgress(job.JobID, 20)
+
+	// Diffuser les fragments au fil de la génération
+	onDelta := func(letterType models.LetterType, delta string) {
+		w.publish(job.JobID, services.LetterStreamEvent{
+			Type:       services.StreamEventDelta,
+			LetterType: letterType,
+			Delta:      delta,
+		})
+	}
 
-	// 1. Générer les deux lettres en parallèle (20-80% progress)
-	w.queueService.UpdateJobStatus(job.JobID, services.JobStatusProcessing, 20)
+	// Une lettre rejetée par le contrôle qualité est régénérée : le client efface le texte reçu
+	onRegenerate := func(letterType models.LetterType, report services.LetterValidationReport) {
+		w.publish(job.JobID, services.LetterStreamEvent{
+			Type:       services.StreamEventRegenerate,
+			LetterType: letterType,
+			Findings:   report.Findings,
+		})
+	}
+
+	letterReq := models.LetterRequest{
+		CompanyName:   job.CompanyName,
+		CompanyDomain: job.CompanyDomain,
+		JobTitle:      job.JobTitle,
+		Theme:         job.Theme,
+		JobPosting:    w.resolveJobPosting(ctx, job),
+		Language:      models.Language(job.Language).OrDefault(),
+	}
 
-	motivationLetter, antiMotivationLetter, err := w.letterGenerator.GenerateDualLetters(ctx, job.CompanyName)
+	// Sans poste explicite, reprendre l'intitulé de l'offre
+	if letterReq.JobTitle == "" && letterReq.JobPosting != nil {
+		letterReq.JobTitle = letterReq.JobPosting.Title
+	}
+
+	// Profil orienté thème (expériences et compétences triées par pertinence)
+	// et traduit dans la langue de la lettre
+	if (job.Theme != "" || letterReq.Language != models.DefaultLanguage) && w.profileBuilder != nil {
+		letterReq.UserProfile = w.profileBuilder.BuildLocalizedProfile(ctx, job.Theme, letterReq.Language)
+	}
+
+	letters, err := w.letterGenerator.GenerateLetters(ctx, letterReq, letterTypes, onDelta, onRegenerate)
 	if err != nil {
-		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to generate letters: %w", err)
+		return nil, fmt.Errorf("failed to generate letters: %w", err)
 	}
 
-	// 2. Sauvegarder en DB (80% progress)
-	w.queueService.UpdateJobStatus(job.JobID, services.JobStatusProcessing, 80)
+	// 2. Sauvegarder en DB (80-100% progress)
+	w.updateProgress(job.JobID, 80)
 
 	// Récupérer ou créer le visitor
 	var visitor models.Visitor
-	result := w.db.Where("session_id = ?", job.VisitorID).First(&visitor)
+	result := w.db.WithContext(ctx).Where("session_id = ?", job.VisitorID).First(&visitor)
 	if result.Error != nil {
 		// Créer le visiteur s'il n'existe pas (pour les appels API directs)
 		now := time.Now()
@@ -170,60 +632,128 @@ func (w *LetterWorker) generateLetters(job *services.LetterJob) (uuid.UUID, uuid
 			LastVisit:  now,
 		}
 		if err := w.db.Create(&visitor).Error; err != nil {
-			return uuid.Nil, uuid.Nil, fmt.Errorf("failed to create visitor: %w", err)
+			return nil, fmt.Errorf("failed to create visitor: %w", err)
 		}
 	}
 
-	// Marshaller CompanyInfo en JSON
-	companyInfoJSON, err := json.Marshal(motivationLetter.CompanyInfo)
+	// Marshaller CompanyInfo en JSON (commune à toutes les lettres)
+	companyInfoJSON, err := json.Marshal(letters[letterTypes[0]].CompanyInfo)
 	if err != nil {
-		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to marshal company info: %w", err)
+		return nil, fmt.Errorf("failed to marshal company info: %w", err)
 	}
 
-	// Sauvegarder lettre de motivation
-	motivationDB := models.GeneratedLetter{
-		VisitorID:    visitor.ID,
-		CompanyName:  job.CompanyName,
-		LetterType:   models.LetterTypeMotivation,
-		Content:      motivationLetter.Content,
-		AIModel:      motivationLetter.Provider,
-		TokensUsed:   motivationLetter.TokensUsed,
-		GenerationMS: int(time.Since(startTime).Milliseconds()),
-		CompanyInfo:  string(companyInfoJSON),
+	letterIDs := make(map[models.LetterType]uuid.UUID, len(letterTypes))
+	totalTokens, totalCost := 0, 0.0
+	for i, letterType := range letterTypes {
+		letter := letters[letterType]
+
+		letterDB := models.GeneratedLetter{
+			VisitorID:    visitor.ID,
+			CompanyID:    companyID(letter.CompanyInfo),
+			CompanyName:  job.CompanyName,
+			LetterType:   letterType,
+			Content:      letter.Content,
+			Language:     letterReq.Language,
+			AIModel:      letter.Provider,
+			TokensUsed:   letter.TokensUsed,
+			GenerationMS: int(time.Since(startTime).Milliseconds()),
+			CompanyInfo:  string(companyInfoJSON),
+			JobPostingID: jobPostingID(letterReq.JobPosting),
+
+			QualityScore:    letter.QualityScore,
+			QualityFindings: letter.QualityFindings,
+			Regenerations:   letter.Regenerations,
+		}
+
+		if err := w.db.Create(&letterDB).Error; err != nil {
+			return nil, fmt.Errorf("failed to save %s letter: %w", letterType, err)
+		}
+		letterIDs[letterType] = letterDB.ID
+		totalTokens += letter.TokensUsed
+		totalCost += letter.EstimatedCost
+
+		w.publish(job.JobID, services.LetterStreamEvent{
+			Type:       services.StreamEventLetterDone,
+			LetterType: letterType,
+			LetterID:   letterDB.ID.String(),
+		})
+
+		w.updateProgress(job.JobID, 80+20*(i+1)/len(letterTypes))
 	}
 
-	result = w.db.Create(&motivationDB)
-	if result.Error != nil {
-		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to save motivation letter: %w", result.Error)
+	log.Printf("[LetterWorker] %d letters generated in %dms (total tokens: %d, total cost: $%.4f)",
+		len(letterIDs),
+		time.Since(startTime).Milliseconds(),
+		totalTokens,
+		totalCost,
+	)
+
+	return letterIDs, nil
+}
+
+// resolveJobPosting récupère ou analyse l'offre d'emploi du job puis la persiste
+// Une offre introuvable n'empêche pas la génération (lettre non ciblée).
+func (w *LetterWorker) resolveJobPosting(ctx context.Context, job *services.LetterJob) *models.JobPosting {
+	// Retry : l'offre a déjà été analysée
+	if job.JobPostingID != nil {
+		var posting models.JobPosting
+		if err := w.db.WithContext(ctx).First(&posting, "id = ?", *job.JobPostingID).Error; err == nil {
+			return &posting
+		}
 	}
 
-	// 3. Sauvegarder lettre anti-motivation (90% progress)
-	w.queueService.UpdateJobStatus(job.JobID, services.JobStatusProcessing, 90)
+	if job.JobPostingURL == "" && job.JobPostingText == "" {
+		return nil
+	}
 
-	antiMotivationDB := models.GeneratedLetter{
-		VisitorID:    visitor.ID,
-		CompanyName:  job.CompanyName,
-		LetterType:   models.LetterTypeAntiMotivation,
-		Content:      antiMotivationLetter.Content,
-		AIModel:      antiMotivationLetter.Provider,
-		TokensUsed:   antiMotivationLetter.TokensUsed,
-		GenerationMS: int(time.Since(startTime).Milliseconds()),
-		CompanyInfo:  string(companyInfoJSON),
+	var posting *models.JobPosting
+	if job.JobPostingURL != "" && w.scraper != nil {
+		fetched, err := w.scraper.FetchJobPosting(ctx, job.JobPostingURL)
+		if err != nil {
+			log.Printf("[LetterWorker] Error fetching job posting %s: %v", job.JobPostingURL, err)
+		} else {
+			posting = fetched
+		}
 	}
 
-	result = w.db.Create(&antiMotivationDB)
-	if result.Error != nil {
-		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to save anti-motivation letter: %w", result.Error)
+	// Texte collé : fallback si l'URL est inexploitable
+	if posting == nil && job.JobPostingText != "" {
+		posting = services.ParseJobPosting(job.JobPostingText)
+		posting.SourceURL = job.JobPostingURL
+	}
+
+	if posting == nil {
+		return nil
+	}
+	if posting.CompanyName == "" {
+		posting.CompanyName = job.CompanyName
 	}
 
-	// 4. Terminé (100% progress)
-	w.queueService.UpdateJobStatus(job.JobID, services.JobStatusProcessing, 100)
+	if err := w.db.Create(posting).Error; err != nil {
+		log.Printf("[LetterWorker] Error saving job posting: %v", err)
+		return posting
+	}
+	if err := w.queueService.AttachJobPosting(job.JobID, posting.ID); err != nil {
+		log.Printf("[LetterWorker] Error attaching job posting to job %s: %v", job.JobID, err)
+	}
 
-	log.Printf("[LetterWorker] Letters generated in %dms (total tokens: %d, total cost: $%.4f)",
-		time.Since(startTime).Milliseconds(),
-		motivationLetter.TokensUsed+antiMotivationLetter.TokensUsed,
-		motivationLetter.EstimatedCost+antiMotivationLetter.EstimatedCost,
-	)
+	return posting
+}
 
-	return motivationDB.ID, antiMotivationDB.ID, nil
+// companyID entreprise canonique résolue par le scraper (nil si la résolution a échoué)
+func companyID(info models.CompanyInfo) *uuid.UUID {
+	id, err := uuid.Parse(info.CompanyID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// jobPostingID retourne l'ID de l'offre si elle a été persistée
+func jobPostingID(posting *models.JobPosting) *uuid.UUID {
+	if posting == nil || posting.ID == uuid.Nil {
+		return nil
+	}
+	id := posting.ID
+	return &id
 }
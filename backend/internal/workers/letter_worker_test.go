@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"maicivy/internal/services"
+)
+
+func TestLetterWorker_Lifecycle(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	queue := services.NewLetterQueueService(redisClient)
+
+	// Révision sans service de révision : échec immédiat, sans accès IA ni base
+	jobID, err := queue.EnqueueRevision(services.LetterRevisionRequest{VisitorID: "visitor-1", LetterID: uuid.New(), Feedback: "Plus court"})
+	require.NoError(t, err)
+
+	worker := NewLetterWorker(nil, queue, nil, nil, nil, nil, nil, nil)
+	worker.SetConcurrency(3)
+	assert.Equal(t, StateStopped, worker.Status().State)
+
+	worker.Start(context.Background())
+	assert.True(t, worker.IsRunning())
+
+	require.Eventually(t, func() bool {
+		job, err := queue.GetJobStatus(jobID)
+		return err == nil && job.Status == services.JobStatusFailed && worker.Status().Failed == 1
+	}, 3*time.Second, 20*time.Millisecond)
+
+	status := worker.Status()
+	assert.Equal(t, StateRunning, status.State)
+	assert.Equal(t, 3, status.Concurrency)
+	assert.True(t, status.Healthy)
+	assert.NotNil(t, status.LastHeartbeat)
+
+	// Job acquitté : plus dans la liste "processing"
+	processing, _ := redisClient.LLen(context.Background(), "queue:letters:processing:"+worker.ID()).Result()
+	assert.Zero(t, processing)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, worker.Shutdown(ctx))
+
+	status = worker.Status()
+	assert.Equal(t, StateStopped, status.State)
+	assert.False(t, status.Healthy)
+	assert.False(t, worker.IsRunning())
+}
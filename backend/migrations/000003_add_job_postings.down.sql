@@ -0,0 +1,8 @@
+-- Rollback: Remove job postings
+-- Date: 2026-10-16
+
+DROP INDEX IF EXISTS idx_generated_letters_job_posting_id;
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS job_posting_id;
+
+DROP INDEX IF EXISTS idx_job_postings_deleted_at;
+DROP TABLE IF EXISTS job_postings;
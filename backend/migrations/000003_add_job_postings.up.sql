@@ -0,0 +1,25 @@
+-- Migration: Add job postings for targeted letters
+-- Date: 2026-10-16
+-- Description: Stores parsed job postings and links generated letters to them
+
+-- Table: job_postings
+CREATE TABLE IF NOT EXISTS job_postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source VARCHAR(10) NOT NULL,
+    source_url VARCHAR(2000),
+    title VARCHAR(255),
+    company_name VARCHAR(255),
+    raw_text TEXT,
+    requirements TEXT[],
+    responsibilities TEXT[],
+    tech_keywords TEXT[],
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_postings_deleted_at ON job_postings(deleted_at);
+
+-- Link generated letters to the posting they target
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS job_posting_id UUID REFERENCES job_postings(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_generated_letters_job_posting_id ON generated_letters(job_posting_id);
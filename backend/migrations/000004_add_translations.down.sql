@@ -0,0 +1,8 @@
+-- Rollback: Remove multilingual content
+-- Date: 2026-10-16
+
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS language;
+
+ALTER TABLE skills DROP COLUMN IF EXISTS translations;
+ALTER TABLE projects DROP COLUMN IF EXISTS translations;
+ALTER TABLE experiences DROP COLUMN IF EXISTS translations;
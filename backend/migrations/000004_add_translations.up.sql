@@ -0,0 +1,11 @@
+-- Migration: Add multilingual content
+-- Date: 2026-10-16
+-- Description: Adds per-language translations to CV content and the language of generated letters
+
+-- Translations: {"en": {"title": "...", "description": "..."}, "de": {...}}
+ALTER TABLE experiences ADD COLUMN IF NOT EXISTS translations JSONB;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS translations JSONB;
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS translations JSONB;
+
+-- Language of generated letters (ISO 639-1)
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT 'fr';
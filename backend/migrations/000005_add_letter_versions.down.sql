@@ -0,0 +1,8 @@
+-- Rollback: Remove letter versions
+-- Date: 2026-10-16
+
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS current_version;
+
+DROP INDEX IF EXISTS idx_letter_versions_deleted_at;
+DROP INDEX IF EXISTS idx_letter_versions_letter_version;
+DROP TABLE IF EXISTS letter_versions;
@@ -0,0 +1,24 @@
+-- Migration: Add letter versions
+-- Date: 2026-10-16
+-- Description: Stores successive revisions of generated letters and the version currently served
+
+-- Table: letter_versions
+CREATE TABLE IF NOT EXISTS letter_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    letter_id UUID NOT NULL REFERENCES generated_letters(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    content TEXT NOT NULL,
+    feedback TEXT,
+    ai_model VARCHAR(50),
+    tokens_used INT DEFAULT 0,
+    generation_ms INT DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_letter_versions_letter_version ON letter_versions(letter_id, version);
+CREATE INDEX IF NOT EXISTS idx_letter_versions_deleted_at ON letter_versions(deleted_at);
+
+-- Version currently served by generated_letters.content
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS current_version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+-- Rollback: Remove letter quality checks
+-- Date: 2026-10-16
+
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS regenerations;
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS quality_findings;
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS quality_score;
@@ -0,0 +1,7 @@
+-- Migration: Add letter quality checks
+-- Date: 2026-10-16
+-- Description: Records the quality gate score, findings and regeneration count of generated letters
+
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS quality_score INT DEFAULT 0;
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS quality_findings JSONB;
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS regenerations INT DEFAULT 0;
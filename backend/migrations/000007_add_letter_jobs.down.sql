@@ -0,0 +1,7 @@
+-- Rollback: Remove letter jobs
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_letter_job_records_created_at;
+DROP INDEX IF EXISTS idx_letter_job_records_status;
+DROP INDEX IF EXISTS idx_letter_job_records_session_id;
+DROP TABLE IF EXISTS letter_jobs;
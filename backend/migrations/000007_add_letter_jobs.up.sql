@@ -0,0 +1,25 @@
+-- Migration: Add letter jobs
+-- Date: 2026-10-17
+-- Description: Mirrors letter generation jobs from the Redis queue so their history survives Redis restarts
+
+-- Table: letter_jobs
+CREATE TABLE IF NOT EXISTS letter_jobs (
+    job_id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    session_id VARCHAR(255),
+    company_name VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    progress INT DEFAULT 0,
+    worker_id VARCHAR(255),
+    error TEXT,
+    retry_count INT DEFAULT 0,
+    max_retries INT DEFAULT 0,
+    dead_letter BOOLEAN DEFAULT FALSE,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_letter_job_records_session_id ON letter_jobs(session_id);
+CREATE INDEX IF NOT EXISTS idx_letter_job_records_status ON letter_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_letter_job_records_created_at ON letter_jobs(created_at);
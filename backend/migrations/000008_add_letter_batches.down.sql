@@ -0,0 +1,8 @@
+-- Rollback: Remove letter batches
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_letter_batch_items_job_id;
+DROP INDEX IF EXISTS idx_letter_batch_items_batch_id;
+DROP TABLE IF EXISTS letter_batch_items;
+DROP INDEX IF EXISTS idx_letter_batches_created_at;
+DROP TABLE IF EXISTS letter_batches;
@@ -0,0 +1,32 @@
+-- Migration: Add letter batches
+-- Date: 2026-10-17
+-- Description: Letter generation campaigns (one queued job per target company)
+
+-- Table: letter_batches
+CREATE TABLE IF NOT EXISTS letter_batches (
+    id UUID PRIMARY KEY,
+    name VARCHAR(200),
+    language VARCHAR(5),
+    letter_types VARCHAR(200),
+    item_count INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_letter_batches_created_at ON letter_batches(created_at);
+
+-- Table: letter_batch_items
+CREATE TABLE IF NOT EXISTS letter_batch_items (
+    id UUID PRIMARY KEY,
+    batch_id UUID NOT NULL REFERENCES letter_batches(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    company_name VARCHAR(255) NOT NULL,
+    job_title VARCHAR(255),
+    theme VARCHAR(50),
+    job_posting_url TEXT,
+    job_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_letter_batch_items_batch_id ON letter_batch_items(batch_id);
+CREATE INDEX IF NOT EXISTS idx_letter_batch_items_job_id ON letter_batch_items(job_id);
@@ -0,0 +1,11 @@
+-- Rollback: Remove webhooks
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_created_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_next_attempt_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_status;
+DROP INDEX IF EXISTS idx_webhook_deliveries_event_id;
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription_id;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_created_at;
+DROP TABLE IF EXISTS webhook_subscriptions;
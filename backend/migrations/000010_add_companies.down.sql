@@ -0,0 +1,7 @@
+-- Rollback: Remove curated companies
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_company_aliases_company_id;
+DROP TABLE IF EXISTS company_aliases;
+DROP INDEX IF EXISTS idx_companies_created_at;
+DROP TABLE IF EXISTS companies;
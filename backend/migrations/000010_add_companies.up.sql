@@ -0,0 +1,30 @@
+-- Migration: Add curated companies
+-- Date: 2026-10-17
+-- Description: Admin-curated company records that override scraped company info, with normalised alias lookup
+
+-- Table: companies
+CREATE TABLE IF NOT EXISTS companies (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    domain VARCHAR(255),
+    description TEXT,
+    industry VARCHAR(255),
+    size VARCHAR(100),
+    technologies TEXT[],
+    "values" TEXT[],
+    culture TEXT,
+    notes TEXT,
+    aliases TEXT[],
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_companies_created_at ON companies(created_at);
+
+-- Table: company_aliases (nom et alias normalisés)
+CREATE TABLE IF NOT EXISTS company_aliases (
+    alias VARCHAR(255) PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_aliases_company_id ON company_aliases(company_id);
@@ -0,0 +1,11 @@
+-- Rollback: Remove company name resolution
+
+DROP INDEX IF EXISTS idx_generated_letters_company_id;
+ALTER TABLE generated_letters DROP COLUMN IF EXISTS company_id;
+
+DROP INDEX IF EXISTS idx_company_aliases_alias_trgm;
+
+-- Les entreprises créées par la résolution des noms ne sont pas des fiches curées
+DELETE FROM companies WHERE curated = FALSE;
+ALTER TABLE companies DROP COLUMN IF EXISTS domain_confirmed;
+ALTER TABLE companies DROP COLUMN IF EXISTS curated;
@@ -0,0 +1,19 @@
+-- Migration: Add company name resolution
+-- Date: 2026-10-17
+-- Description: Canonical companies for every name used in letters, trigram fuzzy matching on aliases and user-confirmed domains
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- companies : entreprises canoniques, curées ou créées par la résolution des noms
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS curated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS domain_confirmed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Les fiches existantes ont toutes été créées par l'administrateur
+UPDATE companies SET curated = TRUE, domain_confirmed = (domain IS NOT NULL AND domain <> '');
+
+-- Recherche par similarité (similarity())
+CREATE INDEX IF NOT EXISTS idx_company_aliases_alias_trgm ON company_aliases USING GIN (alias gin_trgm_ops);
+
+-- generated_letters : entreprise canonique de la lettre
+ALTER TABLE generated_letters ADD COLUMN IF NOT EXISTS company_id UUID REFERENCES companies(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_generated_letters_company_id ON generated_letters(company_id);
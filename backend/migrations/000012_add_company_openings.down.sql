@@ -0,0 +1,6 @@
+-- Rollback: Remove company openings
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_company_openings_company_url;
+DROP TABLE IF EXISTS company_openings;
+ALTER TABLE companies DROP COLUMN IF EXISTS openings_crawled_at;
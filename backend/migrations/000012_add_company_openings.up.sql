@@ -0,0 +1,22 @@
+-- Migration: Add company openings
+-- Date: 2026-10-17
+-- Description: Open positions found by the careers page crawler, stored against the canonical company
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS openings_crawled_at TIMESTAMP;
+
+-- Table: company_openings
+CREATE TABLE IF NOT EXISTS company_openings (
+    id UUID PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    location VARCHAR(255),
+    url VARCHAR(2000) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    department VARCHAR(255),
+    employment_type VARCHAR(100),
+    posted_at TIMESTAMP,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_company_openings_company_url ON company_openings(company_id, url);
@@ -0,0 +1,9 @@
+-- Rollback: Remove AI usage ledger
+-- Date: 2026-10-17
+
+DROP INDEX IF EXISTS idx_ai_usage_created_at;
+DROP INDEX IF EXISTS idx_ai_usage_provider_created_at;
+DROP INDEX IF EXISTS idx_ai_usage_deleted_at;
+DROP INDEX IF EXISTS idx_ai_usage_letter_type;
+DROP INDEX IF EXISTS idx_ai_usage_provider;
+DROP TABLE IF EXISTS ai_usage;
@@ -0,0 +1,28 @@
+-- Migration: Add AI usage ledger
+-- Date: 2026-10-17
+-- Description: One row per AI call (tokens, cost, latency) for spend reporting and provider budgets
+
+-- Table: ai_usage
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(20) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    letter_type VARCHAR(50),
+    tokens_input INT DEFAULT 0,
+    tokens_output INT DEFAULT 0,
+    total_tokens INT DEFAULT 0,
+    cost NUMERIC(12,6) DEFAULT 0,
+    response_time_ms BIGINT DEFAULT 0,
+    success BOOLEAN DEFAULT TRUE,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_provider ON ai_usage(provider);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_letter_type ON ai_usage(letter_type);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_deleted_at ON ai_usage(deleted_at);
+-- Provider budgets (ProviderSpend) and per-period reports
+CREATE INDEX IF NOT EXISTS idx_ai_usage_provider_created_at ON ai_usage(provider, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage(created_at);
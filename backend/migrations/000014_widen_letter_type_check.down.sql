@@ -0,0 +1,8 @@
+-- Rollback: Restore the motivation-only letter_type check
+-- Date: 2026-10-17
+
+-- NOT VALID : les lettres des autres types déjà générées sont conservées,
+-- seules les nouvelles lignes sont contrôlées
+ALTER TABLE generated_letters DROP CONSTRAINT IF EXISTS generated_letters_letter_type_check;
+ALTER TABLE generated_letters ADD CONSTRAINT generated_letters_letter_type_check
+    CHECK (letter_type IN ('motivation', 'anti_motivation')) NOT VALID;
@@ -0,0 +1,7 @@
+-- Migration: Widen generated_letters.letter_type check
+-- Date: 2026-10-17
+-- Description: Allows every letter type of the registry (services/letter_types.go), not only the motivation pair
+
+ALTER TABLE generated_letters DROP CONSTRAINT IF EXISTS generated_letters_letter_type_check;
+ALTER TABLE generated_letters ADD CONSTRAINT generated_letters_letter_type_check
+    CHECK (letter_type IN ('motivation', 'anti_motivation', 'follow_up_email', 'thank_you_note', 'linkedin_message', 'elevator_pitch'));